@@ -19,7 +19,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/trillian"
@@ -37,6 +39,9 @@ type LogClient struct {
 	root       types.LogRootV1
 	rootLock   sync.Mutex
 	updateLock sync.Mutex
+	// noWatch is set to 1 once the server has been found not to implement
+	// WatchSignedLogRoots, after which roots are polled for instead.
+	noWatch int32
 }
 
 // New returns a new LogClient.
@@ -122,8 +127,10 @@ func (c *LogClient) ListByIndex(ctx context.Context, start, count int64) ([]*tri
 	return resp.Leaves, nil
 }
 
-// WaitForRootUpdate repeatedly fetches the latest root until there is an
-// update, which it then applies, or until ctx times out.
+// WaitForRootUpdate waits for the log to publish a root newer than the
+// currently trusted root, which it then verifies and applies, or until ctx
+// times out. New roots are watched for with WatchSignedLogRoots, or if the
+// server doesn't implement it, the latest root is repeatedly fetched.
 func (c *LogClient) WaitForRootUpdate(ctx context.Context) (*types.LogRootV1, error) {
 	b := &backoff.Backoff{
 		Min:    100 * time.Millisecond,
@@ -133,7 +140,17 @@ func (c *LogClient) WaitForRootUpdate(ctx context.Context) (*types.LogRootV1, er
 	}
 
 	for {
-		newTrusted, err := c.UpdateRoot(ctx)
+		var newTrusted *types.LogRootV1
+		var err error
+		if atomic.LoadInt32(&c.noWatch) == 0 {
+			newTrusted, err = c.watchForRootUpdate(ctx)
+			if status.Code(err) == codes.Unimplemented {
+				atomic.StoreInt32(&c.noWatch, 1)
+				continue
+			}
+		} else {
+			newTrusted, err = c.UpdateRoot(ctx)
+		}
 		switch status.Code(err) {
 		case codes.OK:
 			if newTrusted != nil {
@@ -153,6 +170,37 @@ func (c *LogClient) WaitForRootUpdate(ctx context.Context) (*types.LogRootV1, er
 	}
 }
 
+// watchForRootUpdate watches the log's roots, verifying each one against the
+// trusted root, until one is newer than the trusted root. It returns that root
+// once it's been applied, or nil if the server ends the stream first.
+func (c *LogClient) watchForRootUpdate(ctx context.Context) (*types.LogRootV1, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.WatchSignedLogRoots(ctx,
+		&trillian.WatchSignedLogRootsRequest{LogId: c.LogID})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		newTrusted, err := c.updateRoot(func(trusted *types.LogRootV1) (*types.LogRootV1, error) {
+			return c.verifyRoot(ctx, trusted, resp.GetSignedLogRoot())
+		})
+		if err != nil {
+			return nil, err
+		}
+		if newTrusted != nil {
+			return newTrusted, nil
+		}
+	}
+}
+
 // getAndVerifyLatestRoot fetches and verifies the latest root against a trusted root, seen in the past.
 // Pass nil for trusted if this is the first time querying this log.
 func (c *LogClient) getAndVerifyLatestRoot(ctx context.Context, trusted *types.LogRootV1) (*types.LogRootV1, error) {
@@ -161,7 +209,12 @@ func (c *LogClient) getAndVerifyLatestRoot(ctx context.Context, trusted *types.L
 	if err != nil {
 		return nil, err
 	}
+	return c.verifyRoot(ctx, trusted, resp.GetSignedLogRoot())
+}
 
+// verifyRoot verifies a root received from the log against a trusted root,
+// seen in the past, fetching a consistency proof between them if needed.
+func (c *LogClient) verifyRoot(ctx context.Context, trusted *types.LogRootV1, slr *trillian.SignedLogRoot) (*types.LogRootV1, error) {
 	// TODO(gbelvin): Turn on root verification.
 	/*
 		logRoot, err := c.VerifyRoot(&types.LogRootV1{}, resp.GetSignedLogRoot(), nil)
@@ -171,7 +224,7 @@ func (c *LogClient) getAndVerifyLatestRoot(ctx context.Context, trusted *types.L
 	*/
 	// TODO(gbelvin): Remove this hack when all implementations store digital signatures.
 	var logRoot types.LogRootV1
-	if err := logRoot.UnmarshalBinary(resp.GetSignedLogRoot().LogRoot); err != nil {
+	if err := logRoot.UnmarshalBinary(slr.GetLogRoot()); err != nil {
 		return nil, err
 	}
 
@@ -185,6 +238,7 @@ func (c *LogClient) getAndVerifyLatestRoot(ctx context.Context, trusted *types.L
 	var consistency *trillian.GetConsistencyProofResponse
 	if trusted.TreeSize > 0 {
 		// Get consistency proof.
+		var err error
 		consistency, err = c.client.GetConsistencyProof(ctx,
 			&trillian.GetConsistencyProofRequest{
 				LogId:          c.LogID,
@@ -198,7 +252,7 @@ func (c *LogClient) getAndVerifyLatestRoot(ctx context.Context, trusted *types.L
 
 	// Verify root update if the tree / the latest signed log root isn't empty.
 	if logRoot.TreeSize > 0 {
-		if _, err := c.VerifyRoot(trusted, resp.GetSignedLogRoot(),
+		if _, err := c.VerifyRoot(trusted, slr,
 			consistency.GetProof().GetHashes()); err != nil {
 			return nil, err
 		}
@@ -220,6 +274,15 @@ func (c *LogClient) GetRoot() *types.LogRootV1 {
 // seen in the past, and updating the currently trusted root if the new root verifies, and is
 // newer than the currently trusted root.
 func (c *LogClient) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
+	return c.updateRoot(func(trusted *types.LogRootV1) (*types.LogRootV1, error) {
+		return c.getAndVerifyLatestRoot(ctx, trusted)
+	})
+}
+
+// updateRoot obtains a new root which verify has verified against the
+// currently trusted root, and updates the trusted root if the new root is
+// newer.
+func (c *LogClient) updateRoot(verify func(trusted *types.LogRootV1) (*types.LogRootV1, error)) (*types.LogRootV1, error) {
 	// Only one root update should be running at any point in time.  This is
 	// because the consistency proof has to be requested against the currently
 	// trusted root, and allowing the current root to be updated during an
@@ -232,7 +295,7 @@ func (c *LogClient) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
 	defer c.updateLock.Unlock()
 
 	currentlyTrusted := c.GetRoot()
-	newTrusted, err := c.getAndVerifyLatestRoot(ctx, currentlyTrusted)
+	newTrusted, err := verify(currentlyTrusted)
 	if err != nil {
 		return nil, err
 	}
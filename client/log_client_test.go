@@ -310,3 +310,54 @@ func TestUpdateRoot(t *testing.T) {
 		t.Errorf("Tree size after add Leaf: %v, want > %v", got, want)
 	}
 }
+
+func TestWaitForRootUpdate(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+	ctx := context.Background()
+	env, err := integration.NewLogEnv(ctx, 0, "unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+	tree, err := CreateAndInitTree(ctx,
+		&trillian.CreateTreeRequest{Tree: stestonly.LogTree},
+		env.Admin, nil, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	for _, test := range []struct {
+		desc   string
+		leaf   []byte
+		client trillian.TrillianLogClient
+	}{
+		{desc: "watch", leaf: []byte("A"), client: env.Log},
+		{desc: "poll", leaf: []byte("B"), client: &MockLogClient{c: env.Log, unimplementedWatch: true}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			client, err := NewFromTree(test.client, tree, types.LogRootV1{})
+			if err != nil {
+				t.Fatalf("NewFromTree(): %v", err)
+			}
+			before, err := client.UpdateRoot(ctx)
+			if err != nil {
+				t.Fatalf("UpdateRoot(): %v", err)
+			}
+
+			if err := client.QueueLeaf(ctx, test.leaf); err != nil {
+				t.Fatalf("QueueLeaf(): %v", err)
+			}
+			env.Sequencer.OperationSingle(ctx)
+
+			cctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			root, err := client.WaitForRootUpdate(cctx)
+			if err != nil {
+				t.Fatalf("WaitForRootUpdate(): %v", err)
+			}
+			if got, want := root.TreeSize, before.TreeSize+1; got != want {
+				t.Errorf("WaitForRootUpdate().TreeSize: %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -33,7 +33,8 @@ import (
 type LogVerifier struct {
 	// Hasher is the hash strategy used to compute nodes in the Merkle tree.
 	Hasher hashers.LogHasher
-	// PubKey verifies the signature on the digest of LogRoot.
+	// PubKey verifies the signature on the digest of LogRoot. It's a
+	// tcrypto.LogKeys if the log's key has been rotated.
 	PubKey crypto.PublicKey
 	// SigHash computes the digest of LogRoot for signing.
 	SigHash crypto.Hash
@@ -67,6 +68,17 @@ func NewLogVerifierFromTree(config *trillian.Tree) (*LogVerifier, error) {
 	if err != nil {
 		return nil, fmt.Errorf("client: NewLogVerifierFromTree(): Failed parsing Log public key: %v", err)
 	}
+	if len(config.GetKeyHistory()) > 0 {
+		logKeys := tcrypto.LogKeys{{KeyID: config.GetTreeId(), PublicKey: logPubKey}}
+		for _, key := range config.GetKeyHistory() {
+			pubKey, err := der.UnmarshalPublicKey(key.GetPublicKey().GetDer())
+			if err != nil {
+				return nil, fmt.Errorf("client: NewLogVerifierFromTree(): Failed parsing public key %v: %v", key.GetKeyId(), err)
+			}
+			logKeys = append(logKeys, tcrypto.LogKey{KeyID: key.GetKeyId(), PublicKey: pubKey, StartTreeSize: uint64(key.GetStartTreeSize())})
+		}
+		logPubKey = logKeys
+	}
 
 	sigHash, err := trees.Hash(config)
 	if err != nil {
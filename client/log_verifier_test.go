@@ -16,10 +16,15 @@ package client
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"testing"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/merkle/rfc6962"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
@@ -90,3 +95,58 @@ func TestVerifyInclusionByHashErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestNewLogVerifierFromTree_KeyHistory(t *testing.T) {
+	oldKey, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	oldPubKey, err := der.ToPublicProto(oldKey.Public())
+	if err != nil {
+		t.Fatalf("ToPublicProto(): %v", err)
+	}
+	newPubKey, err := der.ToPublicProto(newKey.Public())
+	if err != nil {
+		t.Fatalf("ToPublicProto(): %v", err)
+	}
+	tree := &trillian.Tree{
+		TreeId:             12345,
+		TreeType:           trillian.TreeType_LOG,
+		HashStrategy:       trillian.HashStrategy_RFC6962_SHA256,
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+		PublicKey:          oldPubKey,
+		KeyHistory:         []*trillian.SigningKey{{KeyId: 678, PublicKey: newPubKey, StartTreeSize: 10}},
+	}
+	logVerifier, err := NewLogVerifierFromTree(tree)
+	if err != nil {
+		t.Fatalf("NewLogVerifierFromTree(): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc     string
+		signer   *tcrypto.Signer
+		treeSize uint64
+		wantErr  bool
+	}{
+		{desc: "oldKey", signer: tcrypto.NewSigner(12345, oldKey, crypto.SHA256), treeSize: 9},
+		{desc: "newKey", signer: tcrypto.NewSigner(678, newKey, crypto.SHA256), treeSize: 10},
+		{desc: "oldKeyAfterRotation", signer: tcrypto.NewSigner(12345, oldKey, crypto.SHA256), treeSize: 10, wantErr: true},
+		{desc: "unknownKey", signer: tcrypto.NewSigner(678, oldKey, crypto.SHA256), treeSize: 10, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			root, err := test.signer.SignLogRoot(&types.LogRootV1{TreeSize: test.treeSize})
+			if err != nil {
+				t.Fatalf("SignLogRoot(): %v", err)
+			}
+			_, err = logVerifier.VerifyRoot(&types.LogRootV1{}, root, nil)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("VerifyRoot(): %v, want err? %t", err, test.wantErr)
+			}
+		})
+	}
+}
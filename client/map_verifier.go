@@ -78,13 +78,65 @@ func (m *MapVerifier) VerifyMapLeafInclusion(smr *trillian.SignedMapRoot, leafPr
 }
 
 // VerifyMapLeafInclusionHash verifies a MapLeafInclusion response against a root hash.
+// The response may be for an absent leaf, see VerifyMapLeafNonInclusionHash.
 func (m *MapVerifier) VerifyMapLeafInclusionHash(rootHash []byte, leafProof *trillian.MapLeafInclusion) error {
 	index := leafProof.GetLeaf().GetIndex()
 	leaf := leafProof.GetLeaf().GetLeafValue()
 	proof := leafProof.GetInclusion()
+	if leafProof.GetAbsent() && len(leaf) != 0 {
+		return fmt.Errorf("leaf %x is marked absent but has a value", index)
+	}
 	return merkle.VerifyMapInclusionProof(m.MapID, index, leaf, rootHash, proof, m.Hasher)
 }
 
+// VerifyMapLeafNonInclusion verifies that a MapLeafInclusion response proves
+// its index is absent from the map with the given signed map root.
+func (m *MapVerifier) VerifyMapLeafNonInclusion(smr *trillian.SignedMapRoot, leafProof *trillian.MapLeafInclusion) error {
+	root, err := m.VerifySignedMapRoot(smr)
+	if err != nil {
+		return err
+	}
+	return m.VerifyMapLeafNonInclusionHash(root.RootHash, leafProof)
+}
+
+// VerifyMapLeafNonInclusionHash verifies that a MapLeafInclusion response
+// proves its index is absent from the map with the given root hash.
+func (m *MapVerifier) VerifyMapLeafNonInclusionHash(rootHash []byte, leafProof *trillian.MapLeafInclusion) error {
+	index := leafProof.GetLeaf().GetIndex()
+	if !leafProof.GetAbsent() || len(leafProof.GetLeaf().GetLeafValue()) != 0 {
+		return fmt.Errorf("leaf %x is present", index)
+	}
+	return merkle.VerifyMapNonInclusionProof(m.MapID, index, rootHash, leafProof.GetInclusion(), m.Hasher)
+}
+
+// VerifyMapLeavesBatchInclusion verifies the MapLeafInclusions of a
+// GetMapLeavesResponse with a batch inclusion proof against a signed map root.
+func (m *MapVerifier) VerifyMapLeavesBatchInclusion(smr *trillian.SignedMapRoot, leafProofs []*trillian.MapLeafInclusion, batchProof [][]byte) error {
+	root, err := m.VerifySignedMapRoot(smr)
+	if err != nil {
+		return err
+	}
+	return m.VerifyMapLeavesBatchInclusionHash(root.RootHash, leafProofs, batchProof)
+}
+
+// VerifyMapLeavesBatchInclusionHash verifies the MapLeafInclusions of a
+// GetMapLeavesResponse with a batch inclusion proof against a root hash.
+// Leaves may be absent, as in VerifyMapLeafInclusionHash.
+func (m *MapVerifier) VerifyMapLeavesBatchInclusionHash(rootHash []byte, leafProofs []*trillian.MapLeafInclusion, batchProof [][]byte) error {
+	indexes := make([][]byte, 0, len(leafProofs))
+	leaves := make([][]byte, 0, len(leafProofs))
+	for _, leafProof := range leafProofs {
+		index := leafProof.GetLeaf().GetIndex()
+		leaf := leafProof.GetLeaf().GetLeafValue()
+		if leafProof.GetAbsent() && len(leaf) != 0 {
+			return fmt.Errorf("leaf %x is marked absent but has a value", index)
+		}
+		indexes = append(indexes, index)
+		leaves = append(leaves, leaf)
+	}
+	return merkle.VerifyMapBatchInclusionProof(m.MapID, indexes, leaves, rootHash, batchProof, m.Hasher)
+}
+
 // VerifySignedMapRoot verifies the signature on the SignedMapRoot.
 func (m *MapVerifier) VerifySignedMapRoot(smr *trillian.SignedMapRoot) (*types.MapRootV1, error) {
 	return tcrypto.VerifySignedMapRoot(m.PubKey, m.SigHash, smr)
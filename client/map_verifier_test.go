@@ -0,0 +1,172 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/maphasher"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+func TestVerifyMapLeafNonInclusion(t *testing.T) {
+	const mapID = 7
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("Failed to open test key, err=%v", err)
+	}
+	pk, err := pem.UnmarshalPublicKey(testonly.DemoPublicKey)
+	if err != nil {
+		t.Fatalf("Failed to load public key, err=%v", err)
+	}
+	h := maphasher.Default
+	v := &MapVerifier{MapID: mapID, Hasher: h, PubKey: pk, SigHash: crypto.SHA256}
+
+	// Every index is absent from an empty map, with a proof of all empty nodes.
+	emptyRoot := h.HashEmpty(mapID, make([]byte, h.Size()), h.BitLen())
+	smr, err := tcrypto.NewSigner(mapID, key, crypto.SHA256).SignMapRoot(&types.MapRootV1{RootHash: emptyRoot})
+	if err != nil {
+		t.Fatalf("SignMapRoot(): %v", err)
+	}
+	index := testonly.HashKey("key")
+	proof := make([][]byte, h.BitLen())
+
+	for _, test := range []struct {
+		desc          string
+		incl          *trillian.MapLeafInclusion
+		wantAbsent    bool
+		wantInclusion bool
+	}{
+		{
+			desc:          "absent",
+			incl:          &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{Index: index}, Inclusion: proof, Absent: true},
+			wantAbsent:    true,
+			wantInclusion: true,
+		},
+		{
+			desc:          "not marked absent",
+			incl:          &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{Index: index}, Inclusion: proof},
+			wantInclusion: true,
+		},
+		{
+			desc: "absent with value",
+			incl: &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{Index: index, LeafValue: []byte("value")}, Inclusion: proof, Absent: true},
+		},
+		{
+			desc: "present",
+			incl: &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{Index: index, LeafValue: []byte("value")}, Inclusion: proof},
+		},
+		{
+			desc: "short proof",
+			incl: &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{Index: index}, Inclusion: proof[1:], Absent: true},
+		},
+	} {
+		err := v.VerifyMapLeafNonInclusion(smr, test.incl)
+		if got := err == nil; got != test.wantAbsent {
+			t.Errorf("%v: VerifyMapLeafNonInclusion(): %v, want success: %v", test.desc, err, test.wantAbsent)
+		}
+		err = v.VerifyMapLeafInclusion(smr, test.incl)
+		if got := err == nil; got != test.wantInclusion {
+			t.Errorf("%v: VerifyMapLeafInclusion(): %v, want success: %v", test.desc, err, test.wantInclusion)
+		}
+	}
+}
+
+func TestVerifyMapLeavesBatchInclusion(t *testing.T) {
+	const mapID = 7
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("Failed to open test key, err=%v", err)
+	}
+	pk, err := pem.UnmarshalPublicKey(testonly.DemoPublicKey)
+	if err != nil {
+		t.Fatalf("Failed to load public key, err=%v", err)
+	}
+	h := maphasher.Default
+	v := &MapVerifier{MapID: mapID, Hasher: h, PubKey: pk, SigHash: crypto.SHA256}
+
+	// Every index is absent from an empty map, with a proof of all empty nodes.
+	emptyRoot := h.HashEmpty(mapID, make([]byte, h.Size()), h.BitLen())
+	smr, err := tcrypto.NewSigner(mapID, key, crypto.SHA256).SignMapRoot(&types.MapRootV1{RootHash: emptyRoot})
+	if err != nil {
+		t.Fatalf("SignMapRoot(): %v", err)
+	}
+	index1, index2 := testonly.HashKey("key1"), testonly.HashKey("key2")
+	proof := make([][]byte, h.BitLen())
+	batch, err := merkle.CompressMapInclusionProofs([][]byte{index1, index2}, [][][]byte{proof, proof}, h)
+	if err != nil {
+		t.Fatalf("CompressMapInclusionProofs(): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc   string
+		leaves []*trillian.MapLeafInclusion
+		batch  [][]byte
+		want   bool
+	}{
+		{
+			desc: "absent",
+			leaves: []*trillian.MapLeafInclusion{
+				{Leaf: &trillian.MapLeaf{Index: index1}, Absent: true},
+				{Leaf: &trillian.MapLeaf{Index: index2}, Absent: true},
+			},
+			batch: batch,
+			want:  true,
+		},
+		{
+			desc: "absent with value",
+			leaves: []*trillian.MapLeafInclusion{
+				{Leaf: &trillian.MapLeaf{Index: index1}, Absent: true},
+				{Leaf: &trillian.MapLeaf{Index: index2, LeafValue: []byte("value")}, Absent: true},
+			},
+			batch: batch,
+		},
+		{
+			desc: "present",
+			leaves: []*trillian.MapLeafInclusion{
+				{Leaf: &trillian.MapLeaf{Index: index1}, Absent: true},
+				{Leaf: &trillian.MapLeaf{Index: index2, LeafValue: []byte("value")}},
+			},
+			batch: batch,
+		},
+		{
+			desc: "missing leaf",
+			leaves: []*trillian.MapLeafInclusion{
+				{Leaf: &trillian.MapLeaf{Index: index1}, Absent: true},
+			},
+			batch: batch,
+		},
+		{
+			desc: "short proof",
+			leaves: []*trillian.MapLeafInclusion{
+				{Leaf: &trillian.MapLeaf{Index: index1}, Absent: true},
+				{Leaf: &trillian.MapLeaf{Index: index2}, Absent: true},
+			},
+			batch: batch[1:],
+		},
+	} {
+		err := v.VerifyMapLeavesBatchInclusion(smr, test.leaves, test.batch)
+		if got := err == nil; got != test.want {
+			t.Errorf("%v: VerifyMapLeavesBatchInclusion(): %v, want success: %v", test.desc, err, test.want)
+		}
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/golang/glog"
 	"github.com/google/trillian"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // MockLogClient supports applying mutations to the return values of the TrillianLogClient
@@ -28,6 +30,9 @@ type MockLogClient struct {
 	c                    trillian.TrillianLogClient
 	mGetInclusionProof   bool
 	mGetConsistencyProof bool
+	// unimplementedWatch makes WatchSignedLogRoots behave as if the server
+	// doesn't support it.
+	unimplementedWatch bool
 }
 
 // QueueLeaf forwards requests.
@@ -123,6 +128,20 @@ func (c *MockLogClient) GetLeavesByHash(ctx context.Context, in *trillian.GetLea
 	return c.c.GetLeavesByHash(ctx, in)
 }
 
+// StreamLeaves forwards requests.
+func (c *MockLogClient) StreamLeaves(ctx context.Context, in *trillian.StreamLeavesRequest, opts ...grpc.CallOption) (trillian.TrillianLog_StreamLeavesClient, error) {
+	return c.c.StreamLeaves(ctx, in)
+}
+
+// WatchSignedLogRoots forwards requests, or optionally fails them as
+// unimplemented.
+func (c *MockLogClient) WatchSignedLogRoots(ctx context.Context, in *trillian.WatchSignedLogRootsRequest, opts ...grpc.CallOption) (trillian.TrillianLog_WatchSignedLogRootsClient, error) {
+	if c.unimplementedWatch {
+		return nil, status.Errorf(codes.Unimplemented, "WatchSignedLogRoots not implemented")
+	}
+	return c.c.WatchSignedLogRoots(ctx, in)
+}
+
 // GetEntryAndProof forwards requests.
 func (c *MockLogClient) GetEntryAndProof(ctx context.Context, in *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
 	return c.c.GetEntryAndProof(ctx, in)
@@ -54,7 +54,7 @@ var (
 	treeType           = flag.String("tree_type", trillian.TreeType_LOG.String(), "Type of the new tree")
 	hashStrategy       = flag.String("hash_strategy", trillian.HashStrategy_RFC6962_SHA256.String(), "Hash strategy (aka preimage protection) of the new tree")
 	hashAlgorithm      = flag.String("hash_algorithm", sigpb.DigitallySigned_SHA256.String(), "Hash algorithm of the new tree")
-	signatureAlgorithm = flag.String("signature_algorithm", sigpb.DigitallySigned_ECDSA.String(), "Signature algorithm of the new tree")
+	signatureAlgorithm = flag.String("signature_algorithm", sigpb.DigitallySigned_ECDSA.String(), "Signature algorithm of the new tree (ECDSA, RSA or ED25519)")
 	displayName        = flag.String("display_name", "", "Display name of the new tree")
 	description        = flag.String("description", "", "Description of the new tree")
 	maxRootDuration    = flag.Duration("max_root_duration", 0, "Interval after which a new signed root is produced despite no submissions; zero means never")
@@ -145,6 +145,10 @@ func newRequest() (*trillian.CreateTreeRequest, error) {
 			ctr.KeySpec.Params = &keyspb.Specification_RsaParams{
 				RsaParams: &keyspb.Specification_RSA{},
 			}
+		case sigpb.DigitallySigned_ED25519:
+			ctr.KeySpec.Params = &keyspb.Specification_Ed25519Params{
+				Ed25519Params: &keyspb.Specification_Ed25519{},
+			}
 		default:
 			return nil, fmt.Errorf("unsupported signature algorithm: %v", sa)
 		}
@@ -68,6 +68,9 @@ func TestCreateTree(t *testing.T) {
 	nonDefaultTree.DisplayName = "Llamas Map"
 	nonDefaultTree.Description = "For all your digital llama needs!"
 
+	ed25519Tree := *defaultTree
+	ed25519Tree.SignatureAlgorithm = sigpb.DigitallySigned_ED25519
+
 	runTest(t, []*testCase{
 		{
 			desc: "validOpts",
@@ -84,6 +87,11 @@ func TestCreateTree(t *testing.T) {
 			},
 			wantTree: &nonDefaultTree,
 		},
+		{
+			desc:     "ed25519",
+			setFlags: func() { *signatureAlgorithm = ed25519Tree.SignatureAlgorithm.String() },
+			wantTree: &ed25519Tree,
+		},
 		{
 			desc: "mandatoryOptsNotSet",
 			// Undo the flags set by runTest, so that mandatory options are no longer set.
@@ -17,6 +17,7 @@
 //
 // Example usage:
 // $ ./updatetree --admin_server=host:port --tree_id=123456789 --tree_state=FROZEN
+// $ ./updatetree --admin_server=host:port --tree_id=123456789 --sequencer_paused=true
 //
 // The output is minimal to allow for easy usage in automated scripts.
 package main
@@ -26,10 +27,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc"
@@ -42,6 +45,12 @@ var (
 	rpcDeadline     = flag.Duration("rpc_deadline", time.Second*10, "Deadline for RPC requests")
 	treeID          = flag.Int64("tree_id", 0, "The ID of the tree to be set updated")
 	treeState       = flag.String("tree_state", "", "If set the tree state will be updated")
+
+	sequencerPaused          = flag.String("sequencer_paused", "", "If set to true or false, sequencing of the log is paused or resumed")
+	sequencerBatchSize       = flag.String("sequencer_batch_size", "", "If set the sequencer batch size of the log will be updated (0 uses the log signer's default)")
+	sequencerGuardWindow     = flag.String("sequencer_guard_window", "", "If set the sequencer guard window of the log will be updated (0s uses the log signer's default)")
+	sequencerMaxRootDuration = flag.String("sequencer_max_root_duration", "", "If set the sequencer max root duration of the log will be updated (0s uses the tree's max_root_duration)")
+	sequencerPriority        = flag.String("sequencer_priority", "", "If set the sequencing priority of the log will be updated")
 )
 
 // TODO(Martin2112): Pass everything needed into this and don't refer to flags.
@@ -50,27 +59,9 @@ func updateTree(ctx context.Context) (*trillian.Tree, error) {
 		return nil, errors.New("empty --admin_server, please provide the Admin server host:port")
 	}
 
-	m := proto.EnumValueMap("trillian.TreeState")
-	if m == nil {
-		return nil, fmt.Errorf("can't find enum value map for states")
-	}
-	newState, ok := m[*treeState]
-	if !ok {
-		return nil, fmt.Errorf("invalid tree state: %v", *treeState)
-	}
-
-	// We only want to update the state of the tree, which means we need a field
-	// mask on the request.
-	treeStateMask := &field_mask.FieldMask{
-		Paths: []string{"tree_state"},
-	}
-
-	req := &trillian.UpdateTreeRequest{
-		Tree: &trillian.Tree{
-			TreeId:    *treeID,
-			TreeState: trillian.TreeState(newState),
-		},
-		UpdateMask: treeStateMask,
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
 	}
 
 	conn, err := grpc.Dial(*adminServerAddr, grpc.WithInsecure())
@@ -94,6 +85,77 @@ func updateTree(ctx context.Context) (*trillian.Tree, error) {
 	}
 }
 
+// newRequest returns an UpdateTreeRequest which only updates the fields whose
+// flags are set.
+func newRequest() (*trillian.UpdateTreeRequest, error) {
+	tree := &trillian.Tree{TreeId: *treeID, SequencerConfig: &trillian.SequencerConfig{}}
+	var paths []string
+
+	if *treeState != "" {
+		m := proto.EnumValueMap("trillian.TreeState")
+		if m == nil {
+			return nil, fmt.Errorf("can't find enum value map for states")
+		}
+		newState, ok := m[*treeState]
+		if !ok {
+			return nil, fmt.Errorf("invalid tree state: %v", *treeState)
+		}
+		tree.TreeState = trillian.TreeState(newState)
+		paths = append(paths, "tree_state")
+	}
+
+	if *sequencerPaused != "" {
+		paused, err := strconv.ParseBool(*sequencerPaused)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequencer_paused: %v", err)
+		}
+		tree.SequencerConfig.Paused = paused
+		paths = append(paths, "sequencer_config.paused")
+	}
+	if *sequencerBatchSize != "" {
+		batchSize, err := strconv.ParseInt(*sequencerBatchSize, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequencer_batch_size: %v", err)
+		}
+		tree.SequencerConfig.BatchSize = int32(batchSize)
+		paths = append(paths, "sequencer_config.batch_size")
+	}
+	if *sequencerGuardWindow != "" {
+		guardWindow, err := time.ParseDuration(*sequencerGuardWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequencer_guard_window: %v", err)
+		}
+		if guardWindow != 0 {
+			tree.SequencerConfig.GuardWindow = ptypes.DurationProto(guardWindow)
+		}
+		paths = append(paths, "sequencer_config.guard_window")
+	}
+	if *sequencerMaxRootDuration != "" {
+		maxRootDuration, err := time.ParseDuration(*sequencerMaxRootDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequencer_max_root_duration: %v", err)
+		}
+		if maxRootDuration != 0 {
+			tree.SequencerConfig.MaxRootDuration = ptypes.DurationProto(maxRootDuration)
+		}
+		paths = append(paths, "sequencer_config.max_root_duration")
+	}
+	if *sequencerPriority != "" {
+		priority, err := strconv.ParseInt(*sequencerPriority, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequencer_priority: %v", err)
+		}
+		tree.SequencerConfig.Priority = int32(priority)
+		paths = append(paths, "sequencer_config.priority")
+	}
+
+	if len(paths) == 0 {
+		return nil, errors.New("nothing to update, please set --tree_state or a --sequencer_* flag")
+	}
+	// Only the fields in the mask are updated.
+	return &trillian.UpdateTreeRequest{Tree: tree, UpdateMask: &field_mask.FieldMask{Paths: paths}}, nil
+}
+
 func main() {
 	flag.Parse()
 	defer glog.Flush()
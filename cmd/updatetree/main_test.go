@@ -22,9 +22,12 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/util/flagsaver"
+	"google.golang.org/genproto/protobuf/field_mask"
 )
 
 type testCase struct {
@@ -35,6 +38,8 @@ type testCase struct {
 	updateTree *trillian.Tree
 	wantErr    bool
 	wantState  trillian.TreeState
+	// wantReq, if set, is the request expected by the admin server.
+	wantReq *trillian.UpdateTreeRequest
 }
 
 func TestFreezeTree(t *testing.T) {
@@ -103,6 +108,87 @@ func TestFreezeTree(t *testing.T) {
 	})
 }
 
+func TestUpdateSequencerConfig(t *testing.T) {
+	runTest(t, []*testCase{
+		{
+			desc: "pause",
+			setFlags: func() {
+				*treeID = 12345
+				*sequencerPaused = "true"
+			},
+			wantRPC:    true,
+			updateTree: &trillian.Tree{TreeId: 12345, TreeState: trillian.TreeState_ACTIVE},
+			wantState:  trillian.TreeState_ACTIVE,
+			wantReq: &trillian.UpdateTreeRequest{
+				Tree: &trillian.Tree{
+					TreeId:          12345,
+					SequencerConfig: &trillian.SequencerConfig{Paused: true},
+				},
+				UpdateMask: &field_mask.FieldMask{Paths: []string{"sequencer_config.paused"}},
+			},
+		},
+		{
+			desc: "allFields",
+			setFlags: func() {
+				*treeID = 12345
+				*treeState = "FROZEN"
+				*sequencerPaused = "false"
+				*sequencerBatchSize = "100"
+				*sequencerGuardWindow = "5s"
+				*sequencerMaxRootDuration = "1h"
+				*sequencerPriority = "-2"
+			},
+			wantRPC:    true,
+			updateTree: &trillian.Tree{TreeId: 12345, TreeState: trillian.TreeState_FROZEN},
+			wantState:  trillian.TreeState_FROZEN,
+			wantReq: &trillian.UpdateTreeRequest{
+				Tree: &trillian.Tree{
+					TreeId:    12345,
+					TreeState: trillian.TreeState_FROZEN,
+					SequencerConfig: &trillian.SequencerConfig{
+						BatchSize:       100,
+						GuardWindow:     ptypes.DurationProto(5 * time.Second),
+						MaxRootDuration: ptypes.DurationProto(time.Hour),
+						Priority:        -2,
+					},
+				},
+				UpdateMask: &field_mask.FieldMask{Paths: []string{
+					"tree_state",
+					"sequencer_config.paused",
+					"sequencer_config.batch_size",
+					"sequencer_config.guard_window",
+					"sequencer_config.max_root_duration",
+					"sequencer_config.priority",
+				}},
+			},
+		},
+		{
+			desc: "invalidPaused",
+			setFlags: func() {
+				*treeID = 12345
+				*sequencerPaused = "maybe"
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalidBatchSize",
+			setFlags: func() {
+				*treeID = 12345
+				*sequencerBatchSize = "lots"
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalidGuardWindow",
+			setFlags: func() {
+				*treeID = 12345
+				*sequencerGuardWindow = "5 seconds"
+			},
+			wantErr: true,
+		},
+	})
+}
+
 // runTest executes the updateTree command against a fake TrillianAdminServer
 // for each of the provided tests, and checks that the tree in the request is
 // as expected, or an expected error occurs.
@@ -130,6 +216,13 @@ func runTest(t *testing.T, tests []*testCase) {
 			// We might not get as far as updating the tree on the admin server.
 			if tc.wantRPC {
 				call := s.Admin.EXPECT().UpdateTree(gomock.Any(), gomock.Any()).Return(tc.updateTree, tc.updateErr)
+				if tc.wantReq != nil {
+					call = call.Do(func(_ context.Context, req *trillian.UpdateTreeRequest) {
+						if !proto.Equal(req, tc.wantReq) {
+							t.Errorf("UpdateTree() got request %v, want %v", req, tc.wantReq)
+						}
+					})
+				}
 				expectCalls(call, tc.updateErr)
 			}
 
@@ -17,6 +17,7 @@ package der
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
@@ -75,6 +76,8 @@ func UnmarshalPrivateKey(keyDER []byte) (crypto.Signer, error) {
 			return key2, nil
 		case *rsa.PrivateKey:
 			return key2, nil
+		case ed25519.PrivateKey:
+			return key2, nil
 		}
 		return nil, fmt.Errorf("der: unsupported private key type: %T", key2)
 	}
@@ -97,7 +100,7 @@ func UnmarshalPublicKey(keyDER []byte) (crypto.PublicKey, error) {
 	return key, nil
 }
 
-// MarshalPublicKey serializes an RSA or ECDSA public key as DER.
+// MarshalPublicKey serializes an RSA, ECDSA or Ed25519 public key as DER.
 func MarshalPublicKey(pubKey crypto.PublicKey) ([]byte, error) {
 	der, err := x509.MarshalPKIXPublicKey(pubKey)
 	if err != nil {
@@ -107,13 +110,16 @@ func MarshalPublicKey(pubKey crypto.PublicKey) ([]byte, error) {
 	return der, nil
 }
 
-// MarshalPrivateKey serializes an RSA or ECDSA private key as DER.
+// MarshalPrivateKey serializes an RSA, ECDSA or Ed25519 private key as DER.
+// Ed25519 keys are serialized in PKCS8 form, since they have no other.
 func MarshalPrivateKey(key crypto.Signer) ([]byte, error) {
 	switch key := key.(type) {
 	case *ecdsa.PrivateKey:
 		return x509.MarshalECPrivateKey(key)
 	case *rsa.PrivateKey:
 		return x509.MarshalPKCS1PrivateKey(key), nil
+	case ed25519.PrivateKey:
+		return x509.MarshalPKCS8PrivateKey(key)
 	}
 
 	return nil, fmt.Errorf("der: unsupported key type: %T", key)
@@ -94,6 +94,12 @@ func TestNewProtoFromSpec(t *testing.T) {
 				Params: &keyspb.Specification_RsaParams{},
 			},
 		},
+		{
+			desc: "Ed25519",
+			keySpec: &keyspb.Specification{
+				Params: &keyspb.Specification_Ed25519Params{},
+			},
+		},
 		{
 			desc:    "No params",
 			keySpec: &keyspb.Specification{},
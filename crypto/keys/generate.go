@@ -18,6 +18,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -60,6 +61,9 @@ func NewFromSpec(spec *keyspb.Specification) (crypto.Signer, error) {
 		}
 
 		return rsa.GenerateKey(rand.Reader, bits)
+	case *keyspb.Specification_Ed25519Params:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
 	default:
 		return nil, fmt.Errorf("unsupported keygen params type: %T", params)
 	}
@@ -142,6 +142,10 @@ func TestLoadPrivateKeyAndSign(t *testing.T) {
 			desc:   "RSA",
 			keyPEM: rsaPrivateKey,
 		},
+		{
+			desc:   "Ed25519",
+			keyPEM: testonly.DemoEd25519PrivateKey,
+		},
 		{
 			desc:   "ECDSA with leading junk",
 			keyPEM: "foobar\n" + ecdsaPrivateKey,
@@ -17,6 +17,7 @@ package testonly
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -65,6 +66,19 @@ func MustMarshalPrivatePEMToDER(keyPEM, password string) []byte {
 // SignAndVerify exercises a signer by using it to generate a signature, and
 // then verifies that this signature is correct.
 func SignAndVerify(signer crypto.Signer, pubKey crypto.PublicKey) error {
+	if pubKey, ok := pubKey.(ed25519.PublicKey); ok {
+		// Ed25519 signs messages, rather than their digests.
+		message := []byte("test")
+		signature, err := signer.Sign(rand.Reader, message, crypto.Hash(0))
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(pubKey, message, signature) {
+			return errors.New("Ed25519 signature failed verification")
+		}
+		return nil
+	}
+
 	hasher := crypto.SHA256
 	digest := sha256.Sum256([]byte("test"))
 	signature, err := signer.Sign(rand.Reader, digest[:], hasher)
@@ -122,6 +136,11 @@ func CheckKeyMatchesSpec(key crypto.PrivateKey, spec *keyspb.Specification) erro
 			return checkRsaKeyMatchesParams(key, params.RsaParams)
 		}
 		return fmt.Errorf("%T, want *rsa.PrivateKey", key)
+	case *keyspb.Specification_Ed25519Params:
+		if _, ok := key.(ed25519.PrivateKey); ok {
+			return nil
+		}
+		return fmt.Errorf("%T, want ed25519.PrivateKey", key)
 	}
 
 	return fmt.Errorf("%T is not a supported keyspb.Specification.Params type", spec.Params)
@@ -55,6 +55,7 @@ type Specification struct {
 	// Types that are valid to be assigned to Params:
 	//	*Specification_EcdsaParams
 	//	*Specification_RsaParams
+	//	*Specification_Ed25519Params
 	Params               isSpecification_Params `protobuf_oneof:"params"`
 	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
 	XXX_unrecognized     []byte                 `json:"-"`
@@ -95,9 +96,13 @@ type Specification_EcdsaParams struct {
 type Specification_RsaParams struct {
 	RsaParams *Specification_RSA `protobuf:"bytes,2,opt,name=rsa_params,json=rsaParams,oneof"`
 }
+type Specification_Ed25519Params struct {
+	Ed25519Params *Specification_Ed25519 `protobuf:"bytes,3,opt,name=ed25519_params,json=ed25519Params,oneof"`
+}
 
-func (*Specification_EcdsaParams) isSpecification_Params() {}
-func (*Specification_RsaParams) isSpecification_Params()   {}
+func (*Specification_EcdsaParams) isSpecification_Params()   {}
+func (*Specification_RsaParams) isSpecification_Params()     {}
+func (*Specification_Ed25519Params) isSpecification_Params() {}
 
 func (m *Specification) GetParams() isSpecification_Params {
 	if m != nil {
@@ -120,11 +125,19 @@ func (m *Specification) GetRsaParams() *Specification_RSA {
 	return nil
 }
 
+func (m *Specification) GetEd25519Params() *Specification_Ed25519 {
+	if x, ok := m.GetParams().(*Specification_Ed25519Params); ok {
+		return x.Ed25519Params
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Specification) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Specification_OneofMarshaler, _Specification_OneofUnmarshaler, _Specification_OneofSizer, []interface{}{
 		(*Specification_EcdsaParams)(nil),
 		(*Specification_RsaParams)(nil),
+		(*Specification_Ed25519Params)(nil),
 	}
 }
 
@@ -142,6 +155,11 @@ func _Specification_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.RsaParams); err != nil {
 			return err
 		}
+	case *Specification_Ed25519Params:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ed25519Params); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Specification.Params has unexpected type %T", x)
@@ -168,6 +186,14 @@ func _Specification_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.
 		err := b.DecodeMessage(msg)
 		m.Params = &Specification_RsaParams{msg}
 		return true, err
+	case 3: // params.ed25519_params
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Specification_Ed25519)
+		err := b.DecodeMessage(msg)
+		m.Params = &Specification_Ed25519Params{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -187,6 +213,11 @@ func _Specification_OneofSizer(msg proto.Message) (n int) {
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Specification_Ed25519Params:
+		s := proto.Size(x.Ed25519Params)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -277,6 +308,38 @@ func (m *Specification_RSA) GetBits() int32 {
 	return 0
 }
 
+// Ed25519 defines parameters for an Ed25519 key. Ed25519 keys have a fixed
+// size, so there are none at present.
+type Specification_Ed25519 struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Specification_Ed25519) Reset()         { *m = Specification_Ed25519{} }
+func (m *Specification_Ed25519) String() string { return proto.CompactTextString(m) }
+func (*Specification_Ed25519) ProtoMessage()    {}
+func (*Specification_Ed25519) Descriptor() ([]byte, []int) {
+	return fileDescriptor_keyspb_30dfedc26d40c907, []int{0, 2}
+}
+func (m *Specification_Ed25519) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Specification_Ed25519.Unmarshal(m, b)
+}
+func (m *Specification_Ed25519) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Specification_Ed25519.Marshal(b, m, deterministic)
+}
+func (dst *Specification_Ed25519) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Specification_Ed25519.Merge(dst, src)
+}
+func (m *Specification_Ed25519) XXX_Size() int {
+	return xxx_messageInfo_Specification_Ed25519.Size(m)
+}
+func (m *Specification_Ed25519) XXX_DiscardUnknown() {
+	xxx_messageInfo_Specification_Ed25519.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Specification_Ed25519 proto.InternalMessageInfo
+
 // PEMKeyFile identifies a private key stored in a PEM-encoded file.
 type PEMKeyFile struct {
 	// File path of the private key.
@@ -470,6 +533,7 @@ func init() {
 	proto.RegisterType((*Specification)(nil), "keyspb.Specification")
 	proto.RegisterType((*Specification_ECDSA)(nil), "keyspb.Specification.ECDSA")
 	proto.RegisterType((*Specification_RSA)(nil), "keyspb.Specification.RSA")
+	proto.RegisterType((*Specification_Ed25519)(nil), "keyspb.Specification.Ed25519")
 	proto.RegisterType((*PEMKeyFile)(nil), "keyspb.PEMKeyFile")
 	proto.RegisterType((*PrivateKey)(nil), "keyspb.PrivateKey")
 	proto.RegisterType((*PublicKey)(nil), "keyspb.PublicKey")
@@ -480,31 +544,32 @@ func init() {
 func init() { proto.RegisterFile("crypto/keyspb/keyspb.proto", fileDescriptor_keyspb_30dfedc26d40c907) }
 
 var fileDescriptor_keyspb_30dfedc26d40c907 = []byte{
-	// 403 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0x5f, 0x6f, 0xd3, 0x30,
-	0x14, 0xc5, 0xd7, 0x76, 0xad, 0x9a, 0xdb, 0x0e, 0x05, 0x3f, 0x6d, 0x41, 0xe3, 0x4f, 0x9e, 0x26,
-	0x1e, 0x12, 0x35, 0x63, 0x30, 0x21, 0x1e, 0xe8, 0xb2, 0x4c, 0x48, 0x1d, 0x52, 0xe4, 0x30, 0x1e,
-	0x78, 0x09, 0x4e, 0xea, 0x65, 0x56, 0xb3, 0xd8, 0x72, 0xdc, 0xa1, 0xf0, 0xa1, 0xf8, 0x8c, 0x28,
-	0x37, 0x19, 0x68, 0xd2, 0xb4, 0x27, 0x9f, 0x6b, 0x9f, 0x9f, 0xcf, 0xbd, 0xd2, 0x05, 0x27, 0xd7,
-	0x8d, 0x32, 0xd2, 0xdf, 0xf0, 0xa6, 0x56, 0x59, 0x7f, 0x78, 0x4a, 0x4b, 0x23, 0xc9, 0xa4, 0xab,
-	0xdc, 0x3f, 0x43, 0xd8, 0x4b, 0x14, 0xcf, 0xc5, 0xb5, 0xc8, 0x99, 0x11, 0xb2, 0x22, 0x9f, 0x61,
-	0xce, 0xf3, 0x75, 0xcd, 0x52, 0xc5, 0x34, 0xbb, 0xad, 0xf7, 0x07, 0xaf, 0x07, 0x47, 0xb3, 0xe0,
-	0x85, 0xd7, 0xe3, 0x0f, 0xcc, 0x5e, 0x14, 0x9e, 0x27, 0xcb, 0x2f, 0x3b, 0x74, 0x86, 0x48, 0x8c,
-	0x04, 0xf9, 0x08, 0xa0, 0xff, 0xf3, 0x43, 0xe4, 0x0f, 0x1e, 0xe7, 0x29, 0xd2, 0x96, 0xbe, 0x67,
-	0x9d, 0xdf, 0x30, 0xc6, 0x3f, 0xc9, 0x07, 0x18, 0xe7, 0x5b, 0x7d, 0xc7, 0x31, 0xff, 0x59, 0xf0,
-	0xe6, 0x89, 0x7c, 0x2f, 0x6c, 0x8d, 0xb4, 0xf3, 0xbb, 0xa7, 0x30, 0xc6, 0x9a, 0x3c, 0x87, 0xbd,
-	0xf3, 0xe8, 0x62, 0x79, 0x75, 0xf9, 0x2d, 0x0d, 0xaf, 0xe8, 0xf7, 0xc8, 0xde, 0x21, 0x53, 0xd8,
-	0x8d, 0x83, 0x93, 0xf7, 0xf6, 0x00, 0xd5, 0xf1, 0xe9, 0x3b, 0x7b, 0x88, 0xea, 0x24, 0x58, 0xd8,
-	0x23, 0xe7, 0x00, 0x46, 0x34, 0x59, 0x12, 0x02, 0xbb, 0x99, 0x30, 0xdd, 0xe0, 0x63, 0x8a, 0xfa,
-	0x6c, 0x0a, 0x93, 0x6e, 0x1c, 0xf7, 0x13, 0x40, 0x1c, 0x7d, 0x5d, 0xf1, 0xe6, 0x42, 0x94, 0xbc,
-	0xf5, 0x2a, 0x66, 0x6e, 0xd0, 0x6b, 0x51, 0xd4, 0xc4, 0x81, 0xa9, 0x62, 0x75, 0xfd, 0x4b, 0xea,
-	0x35, 0x0e, 0x6f, 0xd1, 0x7f, 0xb5, 0xfb, 0x12, 0x20, 0xd6, 0xe2, 0x8e, 0x19, 0xbe, 0xe2, 0x0d,
-	0xb1, 0x61, 0xb4, 0xe6, 0x1a, 0xe1, 0x39, 0x6d, 0xa5, 0x7b, 0x08, 0x56, 0xbc, 0xcd, 0x4a, 0x91,
-	0x3f, 0xfe, 0xfc, 0x13, 0xe6, 0xf1, 0x2a, 0x4c, 0x16, 0x8b, 0x50, 0x56, 0xd7, 0xa2, 0x20, 0xaf,
-	0x60, 0x66, 0xe4, 0x86, 0x57, 0x69, 0xc9, 0x32, 0x5e, 0xf6, 0x5d, 0x00, 0x5e, 0x5d, 0xb6, 0x37,
-	0xed, 0x17, 0x4a, 0x54, 0x7d, 0x1b, 0xad, 0x24, 0x87, 0x00, 0x0a, 0x13, 0xd2, 0x0d, 0x6f, 0xf6,
-	0x47, 0xf8, 0x60, 0xa9, 0xfb, 0xcc, 0xb3, 0xb7, 0x3f, 0x8e, 0x0a, 0x61, 0x6e, 0xb6, 0x99, 0x97,
-	0xcb, 0x5b, 0xbf, 0x90, 0xb2, 0x28, 0xb9, 0x6f, 0xb4, 0x28, 0x4b, 0xc1, 0x2a, 0xff, 0xc1, 0x42,
-	0x65, 0x13, 0x5c, 0xa5, 0xe3, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0xf1, 0x64, 0xdf, 0x9a, 0x68,
-	0x02, 0x00, 0x00,
+	// 429 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0xcf, 0x6f, 0xd3, 0x30,
+	0x14, 0xc7, 0xdb, 0x65, 0x2d, 0xcd, 0x6b, 0x3b, 0x05, 0x9f, 0x58, 0x50, 0xf9, 0x91, 0xd3, 0xc4,
+	0x21, 0x55, 0x33, 0x0a, 0x03, 0x71, 0xa0, 0xcb, 0x5a, 0x4d, 0xea, 0x90, 0x22, 0x97, 0x71, 0xe0,
+	0x52, 0x9c, 0xd4, 0xcb, 0xac, 0x66, 0xb1, 0xe5, 0xb8, 0x43, 0xe1, 0xc6, 0x7f, 0x8e, 0xf2, 0x92,
+	0x0d, 0x4d, 0x1a, 0x9c, 0xf2, 0x7d, 0xce, 0xf7, 0xe3, 0xf7, 0x7d, 0xb6, 0xc1, 0x4d, 0x74, 0xa9,
+	0x8c, 0x1c, 0x6f, 0x79, 0x59, 0xa8, 0xb8, 0xf9, 0xf8, 0x4a, 0x4b, 0x23, 0x49, 0xb7, 0xae, 0xbc,
+	0xdf, 0x16, 0x0c, 0x57, 0x8a, 0x27, 0xe2, 0x4a, 0x24, 0xcc, 0x08, 0x99, 0x93, 0xcf, 0x30, 0xe0,
+	0xc9, 0xa6, 0x60, 0x6b, 0xc5, 0x34, 0xbb, 0x29, 0x9e, 0xb5, 0x5f, 0xb5, 0x8f, 0xfa, 0xc1, 0x73,
+	0xbf, 0xc1, 0x1f, 0x98, 0xfd, 0x79, 0x78, 0xb6, 0x9a, 0x9d, 0xb7, 0x68, 0x1f, 0x91, 0x08, 0x09,
+	0xf2, 0x11, 0x40, 0xff, 0xe5, 0xf7, 0x90, 0x3f, 0x7c, 0x9c, 0xa7, 0x48, 0xdb, 0xfa, 0x9e, 0x5d,
+	0xc0, 0x01, 0xdf, 0x04, 0xd3, 0xe9, 0xe4, 0xc3, 0x1d, 0x6f, 0x21, 0x3f, 0xfa, 0x47, 0xff, 0xda,
+	0x7b, 0xde, 0xa2, 0xc3, 0x06, 0xab, 0xf7, 0x71, 0x7f, 0x41, 0x07, 0xb3, 0x91, 0xf7, 0xd0, 0x49,
+	0x76, 0xfa, 0x96, 0xe3, 0x1c, 0x07, 0xc1, 0xeb, 0xff, 0xcc, 0xe1, 0x87, 0x95, 0x91, 0xd6, 0x7e,
+	0xef, 0x04, 0x3a, 0x58, 0x93, 0xa7, 0x30, 0x3c, 0x9b, 0x2f, 0x66, 0x97, 0x17, 0x5f, 0xd7, 0xe1,
+	0x25, 0xfd, 0x36, 0x77, 0x5a, 0xa4, 0x07, 0xfb, 0x51, 0x30, 0x7d, 0xe7, 0xb4, 0x51, 0x1d, 0x9f,
+	0xbc, 0x75, 0xf6, 0x50, 0x4d, 0x83, 0x89, 0x63, 0xb9, 0x87, 0x60, 0xd1, 0xd5, 0x8c, 0x10, 0xd8,
+	0x8f, 0x85, 0xa9, 0x0f, 0xb0, 0x43, 0x51, 0xbb, 0x36, 0x3c, 0x69, 0x22, 0x9f, 0xf6, 0xa0, 0x5b,
+	0x4f, 0xe8, 0x7d, 0x02, 0x88, 0xe6, 0x5f, 0x96, 0xbc, 0x5c, 0x88, 0x8c, 0x57, 0x98, 0x62, 0xe6,
+	0x1a, 0x31, 0x9b, 0xa2, 0x26, 0x2e, 0xf4, 0x14, 0x2b, 0x8a, 0x9f, 0x52, 0x6f, 0xf0, 0x3c, 0x6d,
+	0x7a, 0x5f, 0x7b, 0x2f, 0x00, 0x22, 0x2d, 0x6e, 0x99, 0xe1, 0x4b, 0x5e, 0x12, 0x07, 0xac, 0x0d,
+	0xd7, 0x08, 0x0f, 0x68, 0x25, 0xbd, 0x11, 0xd8, 0xd1, 0x2e, 0xce, 0x44, 0xf2, 0xf8, 0xef, 0x1f,
+	0x30, 0x88, 0x96, 0xe1, 0x6a, 0x32, 0x09, 0x65, 0x7e, 0x25, 0x52, 0xf2, 0x12, 0xfa, 0x46, 0x6e,
+	0x79, 0xbe, 0xce, 0x58, 0xcc, 0xb3, 0x26, 0x05, 0xe0, 0xd2, 0x45, 0xb5, 0x52, 0x6d, 0xa1, 0x44,
+	0xde, 0xc4, 0xa8, 0x24, 0x19, 0x01, 0x28, 0xec, 0xb0, 0xde, 0xf2, 0x12, 0xef, 0xcb, 0xa6, 0xb6,
+	0xba, 0xeb, 0x79, 0xfa, 0xe6, 0xfb, 0x51, 0x2a, 0xcc, 0xf5, 0x2e, 0xf6, 0x13, 0x79, 0x33, 0x4e,
+	0xa5, 0x4c, 0x33, 0x3e, 0x36, 0x5a, 0x64, 0x99, 0x60, 0xf9, 0xf8, 0xc1, 0x1b, 0x8d, 0xbb, 0xf8,
+	0x3a, 0x8f, 0xff, 0x0c, 0x00, 0x3a, 0x60, 0x6c, 0x48, 0xbb, 0x02, 0x00, 0x00,
 }
@@ -19,6 +19,7 @@ import (
 
 	"github.com/google/trillian/crypto/keys/pem"
 	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/testonly"
 )
 
 const (
@@ -54,6 +55,11 @@ func TestSignatureAlgorithm(t *testing.T) {
 			keyPEM: rsaPublicKey,
 			want:   sigpb.DigitallySigned_RSA,
 		},
+		{
+			name:   "Ed25519",
+			keyPEM: testonly.DemoEd25519PublicKey,
+			want:   sigpb.DigitallySigned_ED25519,
+		},
 		{
 			name:   "DSA",
 			keyPEM: dsaPublicKey,
@@ -17,6 +17,7 @@ package crypto
 
 import (
 	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
 
 	"github.com/golang/glog"
@@ -56,7 +57,12 @@ func (s *Signer) Public() crypto.PublicKey {
 }
 
 // Sign obtains a signature after first hashing the input data.
+// Ed25519 keys sign the data itself, as Ed25519 hashes it internally.
 func (s *Signer) Sign(data []byte) ([]byte, error) {
+	if _, ok := s.Signer.Public().(ed25519.PublicKey); ok {
+		return s.Signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
 	h := s.Hash.New()
 	h.Write(data)
 	digest := h.Sum(nil)
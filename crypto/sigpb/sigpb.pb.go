@@ -30,15 +30,23 @@ const (
 	DigitallySigned_NONE DigitallySigned_HashAlgorithm = 0
 	// SHA256 is used.
 	DigitallySigned_SHA256 DigitallySigned_HashAlgorithm = 4
+	// SHA384 is used.
+	DigitallySigned_SHA384 DigitallySigned_HashAlgorithm = 5
+	// SHA512 is used.
+	DigitallySigned_SHA512 DigitallySigned_HashAlgorithm = 6
 )
 
 var DigitallySigned_HashAlgorithm_name = map[int32]string{
 	0: "NONE",
 	4: "SHA256",
+	5: "SHA384",
+	6: "SHA512",
 }
 var DigitallySigned_HashAlgorithm_value = map[string]int32{
 	"NONE":   0,
 	"SHA256": 4,
+	"SHA384": 5,
+	"SHA512": 6,
 }
 
 func (x DigitallySigned_HashAlgorithm) String() string {
@@ -62,17 +70,21 @@ const (
 	DigitallySigned_RSA DigitallySigned_SignatureAlgorithm = 1
 	// ECDSA signature scheme.
 	DigitallySigned_ECDSA DigitallySigned_SignatureAlgorithm = 3
+	// Ed25519 signature scheme.
+	DigitallySigned_ED25519 DigitallySigned_SignatureAlgorithm = 7
 )
 
 var DigitallySigned_SignatureAlgorithm_name = map[int32]string{
 	0: "ANONYMOUS",
 	1: "RSA",
 	3: "ECDSA",
+	7: "ED25519",
 }
 var DigitallySigned_SignatureAlgorithm_value = map[string]int32{
 	"ANONYMOUS": 0,
 	"RSA":       1,
 	"ECDSA":     3,
+	"ED25519":   7,
 }
 
 func (x DigitallySigned_SignatureAlgorithm) String() string {
@@ -149,22 +161,24 @@ func init() {
 func init() { proto.RegisterFile("crypto/sigpb/sigpb.proto", fileDescriptor_sigpb_bab25d6b084f0db0) }
 
 var fileDescriptor_sigpb_bab25d6b084f0db0 = []byte{
-	// 267 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x90, 0x41, 0x4b, 0xc3, 0x40,
-	0x10, 0x85, 0x9b, 0xa6, 0xad, 0x66, 0x30, 0x35, 0x8c, 0x97, 0x1c, 0x3c, 0x94, 0xa0, 0xd8, 0x5e,
-	0x12, 0xa8, 0xa8, 0xe7, 0x68, 0x0b, 0x05, 0x31, 0x81, 0x2c, 0x1e, 0xec, 0x45, 0x36, 0x35, 0xec,
-	0x2e, 0x6c, 0xb3, 0x21, 0xbb, 0x3d, 0xf4, 0x9f, 0xf9, 0xf3, 0x84, 0x94, 0xda, 0x68, 0xe9, 0x65,
-	0xe0, 0x3d, 0xde, 0x7c, 0x33, 0x3c, 0xf0, 0x57, 0xf5, 0xb6, 0x32, 0x2a, 0xd2, 0x82, 0x55, 0xf9,
-	0x6e, 0x86, 0x55, 0xad, 0x8c, 0xc2, 0x7e, 0x23, 0x82, 0xef, 0x2e, 0x5c, 0xce, 0x04, 0x13, 0x86,
-	0x4a, 0xb9, 0x25, 0x82, 0x95, 0xc5, 0x17, 0xbe, 0xc2, 0x90, 0x53, 0xcd, 0x3f, 0xa9, 0x64, 0xaa,
-	0x16, 0x86, 0xaf, 0x7d, 0x6b, 0x64, 0x8d, 0x87, 0xd3, 0x9b, 0x70, 0x07, 0xf8, 0x97, 0x0f, 0x17,
-	0x54, 0xf3, 0x78, 0x9f, 0xcd, 0x5c, 0xde, 0x96, 0xb8, 0x84, 0x2b, 0x2d, 0x58, 0x49, 0xcd, 0xa6,
-	0x2e, 0x5a, 0xc4, 0x6e, 0x43, 0x9c, 0x9c, 0x20, 0x92, 0xfd, 0xc6, 0x01, 0x8b, 0xfa, 0xc8, 0xc3,
-	0x6b, 0x70, 0x7e, 0x5d, 0xdf, 0x1e, 0x59, 0xe3, 0x8b, 0xec, 0x60, 0x04, 0xb7, 0xe0, 0xfe, 0xf9,
-	0x0c, 0xcf, 0xa1, 0x97, 0xa4, 0xc9, 0xdc, 0xeb, 0x20, 0xc0, 0x80, 0x2c, 0xe2, 0xe9, 0xc3, 0xa3,
-	0xd7, 0x0b, 0x9e, 0x00, 0x8f, 0xcf, 0xa1, 0x0b, 0x4e, 0x9c, 0xa4, 0xc9, 0xc7, 0x5b, 0xfa, 0x4e,
-	0xbc, 0x0e, 0x9e, 0x81, 0x9d, 0x91, 0xd8, 0xb3, 0xd0, 0x81, 0xfe, 0xfc, 0x65, 0x46, 0x62, 0xcf,
-	0x7e, 0x9e, 0x2c, 0xef, 0x98, 0x30, 0x7c, 0x93, 0x87, 0x2b, 0xb5, 0x8e, 0x98, 0x52, 0x4c, 0x16,
-	0x91, 0xa9, 0x85, 0x94, 0x82, 0x96, 0x51, 0xbb, 0xf8, 0x7c, 0xd0, 0x74, 0x7e, 0xff, 0x13, 0x00,
-	0x00, 0xff, 0xff, 0x44, 0x04, 0xa5, 0x73, 0x8f, 0x01, 0x00, 0x00,
+	// 291 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0x4f, 0x6b, 0xf2, 0x40,
+	0x18, 0xc4, 0x8d, 0x7f, 0x5f, 0x9f, 0xb7, 0xda, 0xe5, 0xe9, 0xc5, 0x43, 0x0f, 0x22, 0x85, 0xea,
+	0x25, 0xc1, 0xd8, 0x94, 0xf6, 0xd0, 0x43, 0xda, 0x08, 0x42, 0x69, 0x04, 0x97, 0x1e, 0xea, 0xa5,
+	0x6c, 0x6c, 0xd8, 0x5d, 0x58, 0xb3, 0x21, 0x59, 0x0f, 0x7e, 0xd8, 0x7e, 0x97, 0x42, 0xd4, 0x9a,
+	0x56, 0x7a, 0x59, 0x9e, 0x19, 0x66, 0x7f, 0x0c, 0x0c, 0xf4, 0x56, 0xd9, 0x36, 0x35, 0xda, 0xc9,
+	0x25, 0x4f, 0xa3, 0xdd, 0x6b, 0xa7, 0x99, 0x36, 0x1a, 0x1b, 0x85, 0x18, 0x7c, 0x56, 0xe1, 0x3c,
+	0x90, 0x5c, 0x1a, 0xa6, 0xd4, 0x96, 0x4a, 0x9e, 0xc4, 0x1f, 0xf8, 0x0c, 0x5d, 0xc1, 0x72, 0xf1,
+	0xce, 0x14, 0xd7, 0x99, 0x34, 0x62, 0xdd, 0xb3, 0xfa, 0xd6, 0xb0, 0xeb, 0x5e, 0xd9, 0x3b, 0xc0,
+	0xaf, 0xbc, 0x3d, 0x63, 0xb9, 0xf0, 0x0f, 0xd9, 0x45, 0x47, 0x94, 0x25, 0x2e, 0xe1, 0x22, 0x97,
+	0x3c, 0x61, 0x66, 0x93, 0xc5, 0x25, 0x62, 0xb5, 0x20, 0x8e, 0xfe, 0x20, 0xd2, 0xc3, 0x8f, 0x23,
+	0x16, 0xf3, 0x13, 0x0f, 0x2f, 0xa1, 0xfd, 0xed, 0xf6, 0x6a, 0x7d, 0x6b, 0x78, 0xb6, 0x38, 0x1a,
+	0x83, 0x07, 0xe8, 0xfc, 0x68, 0x86, 0xff, 0xa0, 0x1e, 0xce, 0xc3, 0x29, 0xa9, 0x20, 0x40, 0x93,
+	0xce, 0x7c, 0xd7, 0xbb, 0x25, 0xf5, 0xfd, 0x3d, 0xb9, 0xbb, 0x21, 0x8d, 0xfd, 0xed, 0x8d, 0x5d,
+	0xd2, 0x1c, 0x04, 0x80, 0xa7, 0x35, 0xb0, 0x03, 0x6d, 0x3f, 0x9c, 0x87, 0x6f, 0x2f, 0xf3, 0x57,
+	0x4a, 0x2a, 0xd8, 0x82, 0xda, 0x82, 0xfa, 0xc4, 0xc2, 0x36, 0x34, 0xa6, 0x4f, 0x01, 0xf5, 0x49,
+	0x0d, 0xff, 0x43, 0x6b, 0x1a, 0xb8, 0x9e, 0x37, 0xbe, 0x27, 0xad, 0xc7, 0xd1, 0xf2, 0x9a, 0x4b,
+	0x23, 0x36, 0x91, 0xbd, 0xd2, 0x6b, 0x87, 0x6b, 0xcd, 0x55, 0xec, 0x98, 0x4c, 0x2a, 0x25, 0x59,
+	0xe2, 0x94, 0xd7, 0x89, 0x9a, 0xc5, 0x30, 0x93, 0xaf, 0x01, 0x00, 0xda, 0xb4, 0x14, 0x5c, 0xb4,
+	0x01, 0x00, 0x00,
 }
@@ -17,6 +17,7 @@ package crypto
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"encoding/asn1"
 	"errors"
@@ -29,13 +30,73 @@ import (
 
 var errVerify = errors.New("signature verification failed")
 
+// LogKey is a public key which signs the roots of a log from a given tree
+// size onwards.
+type LogKey struct {
+	// KeyID identifies the key in the key_hint of the roots it signs (see
+	// types.SerializeKeyHint). The ID of the original key of a log is the
+	// log ID.
+	KeyID     int64
+	PublicKey crypto.PublicKey
+	// StartTreeSize is the size of the smallest root signed by the key.
+	StartTreeSize uint64
+}
+
+// LogKeys holds the keys which sign the roots of a log whose key has been
+// rotated, ordered by StartTreeSize. Each key signs the roots from its
+// StartTreeSize up to the StartTreeSize of the next key.
+// LogKeys may be passed to VerifySignedLogRoot in place of a public key.
+type LogKeys []LogKey
+
+// keyFor returns the key which verifies the root of treeSize. The key named by
+// hint is chosen if there is one, otherwise it's the key for treeSize. Either
+// way, treeSize must be in the range of roots signed by the key.
+func (k LogKeys) keyFor(hint []byte, treeSize uint64) (crypto.PublicKey, error) {
+	i := -1
+	if id, err := types.ParseKeyHint(hint); err == nil {
+		for j, key := range k {
+			if key.KeyID == id {
+				i = j
+				break
+			}
+		}
+	}
+	if i < 0 {
+		for j, key := range k {
+			if key.StartTreeSize <= treeSize {
+				i = j
+			}
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("no key signs roots of tree size %d", treeSize)
+		}
+	}
+	if treeSize < k[i].StartTreeSize || i+1 < len(k) && treeSize >= k[i+1].StartTreeSize {
+		return nil, fmt.Errorf("key %d doesn't sign roots of tree size %d", k[i].KeyID, treeSize)
+	}
+	return k[i].PublicKey, nil
+}
+
 // VerifySignedLogRoot verifies the SignedLogRoot and returns its contents.
+// If pub is a LogKeys, the key which signed the root is picked using its key
+// hint and tree size.
 func VerifySignedLogRoot(pub crypto.PublicKey, hash crypto.Hash, r *trillian.SignedLogRoot) (*types.LogRootV1, error) {
+	var logRoot types.LogRootV1
+	if keys, ok := pub.(LogKeys); ok {
+		// The root is only trusted to pick the key, and verified below.
+		if err := logRoot.UnmarshalBinary(r.LogRoot); err != nil {
+			return nil, err
+		}
+		var err error
+		if pub, err = keys.keyFor(r.KeyHint, logRoot.TreeSize); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := Verify(pub, hash, r.LogRoot, r.LogRootSignature); err != nil {
 		return nil, err
 	}
 
-	var logRoot types.LogRootV1
 	if err := logRoot.UnmarshalBinary(r.LogRoot); err != nil {
 		return nil, err
 	}
@@ -61,6 +122,9 @@ func Verify(pub crypto.PublicKey, hasher crypto.Hash, data, sig []byte) error {
 	if sig == nil {
 		return errors.New("signature is nil")
 	}
+	if pub, ok := pub.(ed25519.PublicKey); ok {
+		return verifyEd25519(pub, data, sig)
+	}
 
 	h := hasher.New()
 	h.Write(data)
@@ -83,6 +147,13 @@ func verifyRSA(pub *rsa.PublicKey, hashed, sig []byte, hasher crypto.Hash, opts
 	return rsa.VerifyPKCS1v15(pub, hasher, hashed, sig)
 }
 
+func verifyEd25519(pub ed25519.PublicKey, data, sig []byte) error {
+	if !ed25519.Verify(pub, data, sig) {
+		return errVerify
+	}
+	return nil
+}
+
 func verifyECDSA(pub *ecdsa.PublicKey, hashed, sig []byte) error {
 	var ecdsaSig struct {
 		R, S *big.Int
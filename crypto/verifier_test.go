@@ -20,6 +20,7 @@ import (
 
 	"github.com/google/trillian/crypto/keys/pem"
 	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
 )
 
 const (
@@ -49,6 +50,10 @@ func TestSignVerify(t *testing.T) {
 			pem:      testonly.DemoPrivateKey,
 			password: testonly.DemoPrivateKeyPass,
 		},
+		{
+			name: "Ed25519 key",
+			pem:  testonly.DemoEd25519PrivateKey,
+		},
 		{
 			name:          "Nil signature",
 			pem:           testonly.DemoPrivateKey,
@@ -81,3 +86,50 @@ func TestSignVerify(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifySignedLogRoot_LogKeys(t *testing.T) {
+	oldKey, err := pem.UnmarshalPrivateKey(privPEM, "")
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	newKey, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	keys := LogKeys{
+		{KeyID: 1, PublicKey: oldKey.Public()},
+		{KeyID: 2, PublicKey: newKey.Public(), StartTreeSize: 10},
+	}
+
+	for _, test := range []struct {
+		desc     string
+		signer   *Signer
+		treeSize uint64
+		hint     []byte // Overrides the signer's hint if set.
+		wantErr  bool
+	}{
+		{desc: "oldKey", signer: NewSigner(1, oldKey, crypto.SHA256), treeSize: 9},
+		{desc: "newKey", signer: NewSigner(2, newKey, crypto.SHA256), treeSize: 10},
+		{desc: "noHint", signer: NewSigner(2, newKey, crypto.SHA256), treeSize: 12, hint: []byte{}},
+		{desc: "oldKeyAfterRotation", signer: NewSigner(1, oldKey, crypto.SHA256), treeSize: 10, wantErr: true},
+		{desc: "newKeyBeforeRotation", signer: NewSigner(2, newKey, crypto.SHA256), treeSize: 9, wantErr: true},
+		{desc: "wrongHint", signer: NewSigner(1, oldKey, crypto.SHA256), treeSize: 12, hint: types.SerializeKeyHint(2), wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			slr, err := test.signer.SignLogRoot(&types.LogRootV1{TreeSize: test.treeSize})
+			if err != nil {
+				t.Fatalf("SignLogRoot(): %v", err)
+			}
+			if test.hint != nil {
+				slr.KeyHint = test.hint
+			}
+			root, err := VerifySignedLogRoot(keys, crypto.SHA256, slr)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("VerifySignedLogRoot(): %v, want err? %t", err, test.wantErr)
+			}
+			if err == nil && root.TreeSize != test.treeSize {
+				t.Errorf("VerifySignedLogRoot(): TreeSize %v, want %v", root.TreeSize, test.treeSize)
+			}
+		})
+	}
+}
@@ -41,7 +41,8 @@ var mapServer = flag.String("map_server", "", "host:port for the map server")
 var mapID = flag.Int("map_id", -1, "Map ID to write to")
 var logBatchSize = flag.Int("log_batch_size", 256, "Max number of entries to process at a time from the CT Log")
 
-//TODO(al): factor this out into a reusable thing.
+// The github.com/google/trillian/mapper package provides this loop for
+// personalities which map a Trillian Log, rather than a CT Log, into a map.
 
 // CTMapper converts between a certificate transparency Log and a Trillian Map.
 type CTMapper struct {
@@ -1,7 +1,7 @@
 #!/usr/bin/env bash
 # Script assumptions:
 # - Cluster has already been created & configured using the create.sh script
-# - Go 1.10 is installed
+# - Go 1.13 is installed
 
 set -e
 
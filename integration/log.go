@@ -20,11 +20,13 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"strings"
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	"github.com/google/trillian"
 	"github.com/google/trillian/client/backoff"
 	"github.com/google/trillian/merkle"
@@ -135,6 +137,12 @@ func RunLogIntegration(client trillian.TrillianLogClient, params TestParameters)
 		return fmt.Errorf("could not read back log entries: %v", err)
 	}
 
+	// Step 3b - Stream the same leaves back and check they match
+	glog.Infof("Streaming back leaves from log ...")
+	if err := checkStreamedLeaves(params.treeID, client, params, leafMap); err != nil {
+		return fmt.Errorf("could not stream back log entries: %v", err)
+	}
+
 	// Step 4 - Cross validation between log and memory tree root hashes
 	glog.Infof("Checking log STH with our constructed in-memory tree ...")
 	tree, err := buildMemoryMerkleTree(leafMap, params)
@@ -365,6 +373,45 @@ func readbackLogEntries(logID int64, client trillian.TrillianLogClient, params T
 	return leafMap, nil
 }
 
+// checkStreamedLeaves streams the leaves read back by readbackLogEntries, and
+// checks the log returns the same leaves, in order.
+func checkStreamedLeaves(logID int64, client trillian.TrillianLogClient, params TestParameters, leafMap map[int64]*trillian.LogLeaf) error {
+	ctx, cancel := getRPCDeadlineContext(params)
+	defer cancel()
+	stream, err := client.StreamLeaves(ctx, &trillian.StreamLeavesRequest{
+		LogId:      logID,
+		StartIndex: params.startLeaf,
+		Count:      params.leafCount,
+	})
+	if err != nil {
+		return err
+	}
+
+	next := params.startLeaf
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for _, leaf := range resp.Leaves {
+			if leaf.LeafIndex != next {
+				return fmt.Errorf("streamed leaf at index %d, want %d", leaf.LeafIndex, next)
+			}
+			if want := leafMap[next]; !proto.Equal(leaf, want) {
+				return fmt.Errorf("streamed leaf %d = %v, want %v", next, leaf, want)
+			}
+			next++
+		}
+	}
+	if got, want := next-params.startLeaf, params.leafCount; got != want {
+		return fmt.Errorf("streamed %d leaves, want %d", got, want)
+	}
+	return nil
+}
+
 func checkLogRootHashMatches(tree *merkle.InMemoryMerkleTree, client trillian.TrillianLogClient, params TestParameters) error {
 	// Check the STH against the hash we got from our tree
 	resp, err := getLatestSignedLogRoot(client, params)
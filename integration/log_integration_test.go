@@ -17,6 +17,9 @@ package integration
 import (
 	"context"
 	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -26,7 +29,10 @@ import (
 	"github.com/google/trillian/client"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage/bolt"
+	"github.com/google/trillian/storage/commitlog"
 	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/postgres"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/testonly/integration"
 
@@ -114,6 +120,120 @@ func TestInProcessLogIntegration(t *testing.T) {
 	}
 }
 
+func TestInProcessLogIntegrationBolt(t *testing.T) {
+	ctx := context.Background()
+	const numSequencers = 2
+	dir, err := ioutil.TempDir("", "integration")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := bolt.OpenDB(filepath.Join(dir, "trillian.db"))
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	defer db.Close()
+
+	reggie := extension.Registry{
+		AdminStorage: bolt.NewAdminStorage(db),
+		LogStorage:   bolt.NewLogStorage(db, nil),
+		QuotaManager: quota.Noop(),
+	}
+
+	env, err := integration.NewLogEnvWithRegistry(ctx, numSequencers, reggie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	tree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{
+		Tree: stestonly.LogTree,
+	}, env.Admin, nil, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	params := DefaultTestParameters(tree.TreeId)
+	if err := RunLogIntegration(env.Log, params); err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+}
+
+func TestInProcessLogIntegrationPostgres(t *testing.T) {
+	testdb.SkipIfNoPostgreSQL(t)
+	ctx := context.Background()
+	const numSequencers = 2
+	db, err := testdb.NewTrillianPostgresDB(ctx)
+	if err != nil {
+		t.Fatalf("NewTrillianPostgresDB(): %v", err)
+	}
+	defer db.Close()
+
+	reggie := extension.Registry{
+		AdminStorage: postgres.NewAdminStorage(db),
+		LogStorage:   postgres.NewLogStorage(db, nil),
+		QuotaManager: quota.Noop(),
+	}
+
+	env, err := integration.NewLogEnvWithRegistry(ctx, numSequencers, reggie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	tree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{
+		Tree: stestonly.LogTree,
+	}, env.Admin, nil, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	params := DefaultTestParameters(tree.TreeId)
+	if err := RunLogIntegration(env.Log, params); err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+}
+
+func TestInProcessLogIntegrationCommitLog(t *testing.T) {
+	ctx := context.Background()
+	const numSequencers = 2
+	dir, err := ioutil.TempDir("", "integration")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cl, err := commitlog.NewFileLog(dir)
+	if err != nil {
+		t.Fatalf("NewFileLog(): %v", err)
+	}
+	defer cl.Close()
+	ms := memory.NewLogStorage(nil)
+
+	reggie := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ms),
+		LogStorage:   commitlog.NewLogStorage(cl, ms, nil),
+		QuotaManager: quota.Noop(),
+	}
+
+	env, err := integration.NewLogEnvWithRegistry(ctx, numSequencers, reggie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	tree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{
+		Tree: stestonly.LogTree,
+	}, env.Admin, nil, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	params := DefaultTestParameters(tree.TreeId)
+	if err := RunLogIntegration(env.Log, params); err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+}
+
 func TestInProcessLogIntegrationDuplicateLeaves(t *testing.T) {
 	ctx := context.Background()
 	const numSequencers = 2
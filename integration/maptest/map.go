@@ -23,6 +23,8 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/client"
@@ -50,6 +52,7 @@ var AllTests = TestTable{
 	{"MapRevisionZero", RunMapRevisionZero},
 	{"MapRevisionInvalid", RunMapRevisionInvalid},
 	{"LeafHistory", RunLeafHistory},
+	{"SetLeavesRevision", RunSetLeavesRevision},
 	{"Inclusion", RunInclusion},
 	{"InclusionBatch", RunInclusionBatch},
 }
@@ -116,6 +119,15 @@ func verifyGetMapLeavesResponse(mapVerifier *client.MapVerifier, getResp *trilli
 		if got, want := leafHash, wantLeafHash; !bytes.Equal(got, want) {
 			return fmt.Errorf("HashLeaf(%s): %x, want %x", leaf, got, want)
 		}
+		if got, want := incl.GetAbsent(), len(leaf) == 0; got != want {
+			return fmt.Errorf("Absent(%x): %v, want %v", index, got, want)
+		}
+		if incl.GetAbsent() {
+			if err := mapVerifier.VerifyMapLeafNonInclusion(getResp.GetMapRoot(), incl); err != nil {
+				return fmt.Errorf("VerifyMapLeafNonInclusion(%x): %v", index, err)
+			}
+			continue
+		}
 		if err := mapVerifier.VerifyMapLeafInclusion(getResp.GetMapRoot(), incl); err != nil {
 			return fmt.Errorf("VerifyMapLeafInclusion(%x): %v", index, err)
 		}
@@ -279,6 +291,13 @@ func RunLeafHistory(ctx context.Context, t *testing.T, tadmin trillian.TrillianA
 			Index     []byte
 			LeafValue []byte
 		}
+		// history holds the changes to the leaf at historyIndex, over all of
+		// the revisions written.
+		historyIndex []byte
+		history      []struct {
+			revision  int64
+			LeafValue []byte
+		}
 	}{
 		{
 			desc:         "single leaf update",
@@ -308,6 +327,15 @@ func RunLeafHistory(ctx context.Context, t *testing.T, tadmin trillian.TrillianA
 				{revision: 4, Index: h2b("0000000000000000000000000000000000000000000000000000000000000000"), LeafValue: []byte("B")},
 				{revision: 5, Index: h2b("0000000000000000000000000000000000000000000000000000000000000000"), LeafValue: []byte("C")},
 			},
+			historyIndex: h2b("0000000000000000000000000000000000000000000000000000000000000000"),
+			history: []struct {
+				revision  int64
+				LeafValue []byte
+			}{
+				{revision: 2, LeafValue: []byte("A")},
+				{revision: 4, LeafValue: []byte("B")},
+				{revision: 5, LeafValue: []byte("C")},
+			},
 		},
 	} {
 		for _, hashStrategy := range tc.HashStrategy {
@@ -354,11 +382,92 @@ func RunLeafHistory(ctx context.Context, t *testing.T, tadmin trillian.TrillianA
 						t.Errorf("verifyGetMapLeavesResponse(rev %v): %v", batch.revision, err)
 					}
 				}
+
+				histResp, err := tmap.GetLeafHistory(ctx, &trillian.GetMapLeafHistoryRequest{
+					MapId:         tree.TreeId,
+					Index:         tc.historyIndex,
+					StartRevision: 0,
+					EndRevision:   int64(len(tc.set)),
+				})
+				if err != nil {
+					t.Fatalf("GetLeafHistory(): %v", err)
+				}
+				if got, want := len(histResp.GetEntries()), len(tc.history); got != want {
+					t.Fatalf("GetLeafHistory() returned %d entries, want %d", got, want)
+				}
+				for i, entry := range histResp.GetEntries() {
+					want := tc.history[i]
+					if got := entry.GetRevision(); got != want.revision {
+						t.Errorf("GetLeafHistory().Entries[%d].Revision: %v, want %v", i, got, want.revision)
+					}
+					if got := entry.GetLeafInclusion().GetLeaf().GetLeafValue(); !bytes.Equal(got, want.LeafValue) {
+						t.Errorf("GetLeafHistory().Entries[%d].LeafValue: %s, want %s", i, got, want.LeafValue)
+					}
+					if err := verifyGetSignedMapRootResponse(mapVerifier, entry.GetMapRoot(), want.revision); err != nil {
+						t.Errorf("GetLeafHistory().Entries[%d]: verifyGetSignedMapRootResponse(rev %v): %v", i, want.revision, err)
+					}
+					if err := mapVerifier.VerifyMapLeafInclusion(entry.GetMapRoot(), entry.GetLeafInclusion()); err != nil {
+						t.Errorf("GetLeafHistory().Entries[%d]: VerifyMapLeafInclusion(): %v", i, err)
+					}
+				}
 			})
 		}
 	}
 }
 
+// RunSetLeavesRevision checks that SetLeaves only writes to the expected
+// revision, if one is given.
+func RunSetLeavesRevision(ctx context.Context, t *testing.T, tadmin trillian.TrillianAdminClient, tmap trillian.TrillianMapClient) {
+	const indexHex = "0000000000000000000000000000000000000000000000000000000000000001"
+	tree, err := newTreeWithHasher(ctx, tadmin, tmap, trillian.HashStrategy_TEST_MAP_HASHER)
+	if err != nil {
+		t.Fatalf("newTreeWithHasher(): %v", err)
+	}
+	mapVerifier, err := client.NewMapVerifierFromTree(tree)
+	if err != nil {
+		t.Fatalf("NewMapVerifierFromTree(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		revision int64
+		wantCode codes.Code
+		wantRev  int64
+	}{
+		{desc: "next revision", revision: 1, wantRev: 1},
+		{desc: "existing revision", revision: 1, wantCode: codes.FailedPrecondition},
+		{desc: "future revision", revision: 3, wantCode: codes.FailedPrecondition},
+		{desc: "negative revision", revision: -1, wantCode: codes.InvalidArgument},
+		{desc: "any revision", revision: 0, wantRev: 2},
+		{desc: "next revision again", revision: 3, wantRev: 3},
+	} {
+		resp, err := tmap.SetLeaves(ctx, &trillian.SetMapLeavesRequest{
+			MapId:    tree.TreeId,
+			Leaves:   []*trillian.MapLeaf{{Index: h2b(indexHex), LeafValue: []byte(tc.desc)}},
+			Revision: tc.revision,
+		})
+		if got := status.Code(err); got != tc.wantCode {
+			t.Errorf("%v: SetLeaves(revision: %d): %v, want code %v", tc.desc, tc.revision, err, tc.wantCode)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if err := verifyGetSignedMapRootResponse(mapVerifier, resp.GetMapRoot(), tc.wantRev); err != nil {
+			t.Errorf("%v: verifyGetSignedMapRootResponse(rev %v): %v", tc.desc, tc.wantRev, err)
+		}
+	}
+
+	// Failed writes must not have left anything behind.
+	getResp, err := tmap.GetSignedMapRoot(ctx, &trillian.GetSignedMapRootRequest{MapId: tree.TreeId})
+	if err != nil {
+		t.Fatalf("GetSignedMapRoot(): %v", err)
+	}
+	if err := verifyGetSignedMapRootResponse(mapVerifier, getResp.GetMapRoot(), 3); err != nil {
+		t.Errorf("verifyGetSignedMapRootResponse(rev 3): %v", err)
+	}
+}
+
 // RunInclusion performs checks on Trillian Map inclusion proofs after setting and getting leafs,
 // for a variety of hash strategies.
 func RunInclusion(ctx context.Context, t *testing.T, tadmin trillian.TrillianAdminClient, tmap trillian.TrillianMapClient) {
@@ -428,6 +537,32 @@ func RunInclusion(ctx context.Context, t *testing.T, tadmin trillian.TrillianAdm
 				if err := verifyGetMapLeavesResponse(mapVerifier, getResp, indexes, 1); err != nil {
 					t.Errorf("verifyGetMapLeavesResponse(): %v", err)
 				}
+
+				batchResp, err := tmap.GetLeaves(ctx, &trillian.GetMapLeavesRequest{
+					MapId:      tree.TreeId,
+					Index:      indexes,
+					BatchProof: true,
+				})
+				if err != nil {
+					t.Fatalf("GetLeaves(batch proof): %v", err)
+				}
+				for _, incl := range batchResp.GetMapLeafInclusion() {
+					if got := len(incl.GetInclusion()); got != 0 {
+						t.Errorf("GetLeaves(batch proof): leaf %x has %d inclusion nodes, want 0", incl.GetLeaf().GetIndex(), got)
+					}
+				}
+				if err := mapVerifier.VerifyMapLeavesBatchInclusion(batchResp.GetMapRoot(), batchResp.GetMapLeafInclusion(), batchResp.GetBatchInclusion()); err != nil {
+					t.Errorf("VerifyMapLeavesBatchInclusion(): %v", err)
+				}
+
+				_, err = tmap.GetLeaves(ctx, &trillian.GetMapLeavesRequest{
+					MapId:      tree.TreeId,
+					Index:      append(indexes, indexes[0]),
+					BatchProof: true,
+				})
+				if got, want := status.Code(err), codes.InvalidArgument; got != want {
+					t.Errorf("GetLeaves(batch proof, duplicate index): %v, want code %v", err, want)
+				}
 			})
 		}
 	}
@@ -17,9 +17,21 @@ package maptest
 import (
 	"context"
 	"flag"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage/bolt"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/postgres"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/testonly/integration"
 
@@ -53,3 +65,91 @@ func TestMapIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestBoltMapIntegration(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "maptest")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := bolt.OpenDB(filepath.Join(dir, "trillian.db"))
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	defer db.Close()
+
+	env, err := integration.NewMapEnvWithRegistry(extension.Registry{
+		AdminStorage:  bolt.NewAdminStorage(db),
+		MapStorage:    bolt.NewMapStorage(db),
+		QuotaManager:  quota.Noop(),
+		MetricFactory: monitoring.InertMetricFactory{},
+		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+			return der.NewProtoFromSpec(spec)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Could not create MapEnv: %v", err)
+	}
+	defer env.Close()
+
+	for _, test := range AllTests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.Fn(ctx, t, env.Admin, env.Map)
+		})
+	}
+}
+
+func TestPostgresMapIntegration(t *testing.T) {
+	testdb.SkipIfNoPostgreSQL(t)
+	ctx := context.Background()
+	db, err := testdb.NewTrillianPostgresDB(ctx)
+	if err != nil {
+		t.Fatalf("NewTrillianPostgresDB(): %v", err)
+	}
+	defer db.Close()
+
+	env, err := integration.NewMapEnvWithRegistry(extension.Registry{
+		AdminStorage:  postgres.NewAdminStorage(db),
+		MapStorage:    postgres.NewMapStorage(db),
+		QuotaManager:  quota.Noop(),
+		MetricFactory: monitoring.InertMetricFactory{},
+		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+			return der.NewProtoFromSpec(spec)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Could not create MapEnv: %v", err)
+	}
+	defer env.Close()
+
+	for _, test := range AllTests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.Fn(ctx, t, env.Admin, env.Map)
+		})
+	}
+}
+
+func TestInMemoryMapIntegration(t *testing.T) {
+	ctx := context.Background()
+	ls := memory.NewLogStorage(nil)
+	env, err := integration.NewMapEnvWithRegistry(extension.Registry{
+		AdminStorage:  memory.NewAdminStorage(ls),
+		MapStorage:    memory.NewMapStorage(ls),
+		QuotaManager:  quota.Noop(),
+		MetricFactory: monitoring.InertMetricFactory{},
+		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+			return der.NewProtoFromSpec(spec)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Could not create MapEnv: %v", err)
+	}
+	defer env.Close()
+
+	for _, test := range AllTests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.Fn(ctx, t, env.Admin, env.Map)
+		})
+	}
+}
@@ -17,6 +17,7 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strconv"
@@ -51,6 +52,12 @@ var (
 	seqUpdateLeavesLatency monitoring.Histogram
 	seqSetNodesLatency     monitoring.Histogram
 	seqStoreRootLatency    monitoring.Histogram
+	seqSignLatency         monitoring.Histogram
+	seqPrepareWaitLatency  monitoring.Histogram
+	seqPeekLatency         monitoring.Histogram
+	seqCommitLatency       monitoring.Histogram
+	seqTreeCacheLookups    monitoring.Counter
+	seqSpeculativeBatches  monitoring.Counter
 	seqCounter             monitoring.Counter
 	seqMergeDelay          monitoring.Histogram
 
@@ -88,6 +95,12 @@ func createMetrics(mf monitoring.MetricFactory) {
 	seqUpdateLeavesLatency = mf.NewHistogram("sequencer_latency_update_leaves", "Latency of update-leaves part of sequencer batch operation in seconds", logIDLabel)
 	seqSetNodesLatency = mf.NewHistogram("sequencer_latency_set_nodes", "Latency of set-nodes part of sequencer batch operation in seconds", logIDLabel)
 	seqStoreRootLatency = mf.NewHistogram("sequencer_latency_store_root", "Latency of store-root part of sequencer batch operation in seconds", logIDLabel)
+	seqSignLatency = mf.NewHistogram("sequencer_latency_sign", "Latency of sign-root part of sequencer batch operation in seconds", logIDLabel)
+	seqPrepareWaitLatency = mf.NewHistogram("sequencer_latency_prepare_wait", "Time sequencer batch operation spent waiting for its leaves to finish hashing ahead of time in seconds", logIDLabel)
+	seqPeekLatency = mf.NewHistogram("sequencer_latency_peek", "Latency of peek-next-batch part of sequencer batch operation in seconds", logIDLabel)
+	seqCommitLatency = mf.NewHistogram("sequencer_latency_commit", "Latency of commit part of sequencer batch operation in seconds", logIDLabel)
+	seqTreeCacheLookups = mf.NewCounter("sequencer_tree_cache_lookups", "Number of compact tree cache lookups by the sequencer", logIDLabel, "hit")
+	seqSpeculativeBatches = mf.NewCounter("sequencer_speculative_batches", "Number of batches hashed ahead of time by the sequencer, by whether the hashes were used", logIDLabel, "hit")
 	seqCounter = mf.NewCounter("sequencer_sequenced", "Number of leaves sequenced", logIDLabel)
 	seqMergeDelay = mf.NewHistogram("sequencer_merge_delay", "Delay between queuing and integration of leaves", logIDLabel)
 }
@@ -101,9 +114,23 @@ type Sequencer struct {
 	timeSource util.TimeSource
 	logStorage storage.LogStorage
 	signer     *tcrypto.Signer
+	successors SuccessorSignersFunc
 	qm         quota.Manager
+	treeCache  *CompactTreeCache
 }
 
+// SuccessorSigner is a signer which takes over signing the roots of a log from
+// a given tree size onwards, after the log's key has been rotated.
+type SuccessorSigner struct {
+	// StartTreeSize is the size of the smallest root signed by Signer.
+	StartTreeSize uint64
+	Signer        *tcrypto.Signer
+}
+
+// SuccessorSignersFunc returns the successor signers of a log as currently
+// stored, ordered by StartTreeSize.
+type SuccessorSignersFunc func(ctx context.Context) ([]SuccessorSigner, error)
+
 // maxTreeDepth sets an upper limit on the size of Log trees.
 // Note: We actually can't go beyond 2^63 entries because we use int64s,
 // but we need to calculate tree depths from a multiple of 8 due to the
@@ -130,31 +157,92 @@ func NewSequencer(
 	}
 }
 
-// TODO: This currently doesn't use the batch api for fetching the required nodes. This
-// would be more efficient but requires refactoring.
+// SetCompactTreeCache makes the Sequencer keep the compact Merkle tree of
+// each log it integrates batches into in cache, and start from the cached
+// tree in the next batch when it's still current. It also enables hashing the
+// next batch of a log ahead of time, see IntegrateBatch. The cache may be
+// shared by Sequencers for different logs.
+func (s *Sequencer) SetCompactTreeCache(cache *CompactTreeCache) {
+	s.treeCache = cache
+}
+
+// SetSuccessorSigners makes the Sequencer sign the roots of the log's
+// successor keys, from their start tree sizes onwards, with their signers
+// rather than its own. successors is called before every transaction which
+// signs a root, so that a key added to the log since the Sequencer was created
+// takes over from its start tree size, rather than the predecessor key
+// signing roots it's not allowed to. It's called outside of the transaction,
+// as the log's storage may not allow the tree to be read while it's open.
+func (s *Sequencer) SetSuccessorSigners(successors SuccessorSignersFunc) {
+	s.successors = successors
+}
+
+// successorSigners returns the log's successor signers, if it has any.
+func (s Sequencer) successorSigners(ctx context.Context) ([]SuccessorSigner, error) {
+	if s.successors == nil {
+		return nil, nil
+	}
+	successors, err := s.successors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get successor signers: %v", err)
+	}
+	return successors, nil
+}
+
+// signerFor returns the signer of the log root of the given tree size, given
+// the log's successor signers.
+func (s Sequencer) signerFor(successors []SuccessorSigner, treeSize uint64) *tcrypto.Signer {
+	signer := s.signer
+	for _, successor := range successors {
+		if successor.StartTreeSize > treeSize {
+			break
+		}
+		signer = successor.Signer
+	}
+	return signer
+}
+
+// buildMerkleTreeFromStorageAtRoot resumes the compact Merkle tree at root,
+// reading all the nodes it needs from storage in a single batch.
 func (s Sequencer) buildMerkleTreeFromStorageAtRoot(ctx context.Context, root *types.LogRootV1, tx storage.TreeTX) (*merkle.CompactMerkleTree, error) {
-	mt, err := merkle.NewCompactMerkleTreeWithState(s.hasher, int64(root.TreeSize), func(depth int, index int64) ([]byte, error) {
-		nodeID, err := storage.NewNodeIDForTreeCoords(int64(depth), index, maxTreeDepth)
+	fetches, err := merkle.CalcCompactTreeNodeAddresses(int64(root.TreeSize), maxTreeDepth)
+	if err != nil {
+		glog.Warningf("%x: Failed to calculate compact tree nodes: %v", s.signer.KeyHint, err)
+		return nil, err
+	}
+
+	hashes := make(map[string][]byte, len(fetches))
+	if len(fetches) > 0 {
+		nodeIDs := make([]storage.NodeID, 0, len(fetches))
+		for _, f := range fetches {
+			nodeIDs = append(nodeIDs, f.NodeID)
+		}
+		nodes, err := tx.GetMerkleNodes(ctx, int64(root.Revision), nodeIDs)
 		if err != nil {
-			glog.Warningf("%x: Failed to create nodeID: %v", s.signer.KeyHint, err)
+			glog.Warningf("%x: Failed to get Merkle nodes: %v", s.signer.KeyHint, err)
 			return nil, err
 		}
-		nodes, err := tx.GetMerkleNodes(ctx, int64(root.Revision), []storage.NodeID{nodeID})
+		// We expect to get exactly the nodes we asked for, in any order.
+		if len(nodes) != len(nodeIDs) {
+			return nil, fmt.Errorf("%x: Did not retrieve %d nodes while loading CompactMerkleTree, got %#v @%v", s.signer.KeyHint, len(nodeIDs), nodes, root.Revision)
+		}
+		for _, node := range nodes {
+			hashes[node.NodeID.String()] = node.Hash
+		}
+	}
 
+	return merkle.NewCompactMerkleTreeWithState(s.hasher, int64(root.TreeSize), func(depth int, index int64) ([]byte, error) {
+		nodeID, err := storage.NewNodeIDForTreeCoords(int64(depth), index, maxTreeDepth)
 		if err != nil {
-			glog.Warningf("%x: Failed to get Merkle nodes: %v", s.signer.KeyHint, err)
+			glog.Warningf("%x: Failed to create nodeID: %v", s.signer.KeyHint, err)
 			return nil, err
 		}
-
-		// We expect to get exactly one node here
-		if nodes == nil || len(nodes) != 1 {
-			return nil, fmt.Errorf("%x: Did not retrieve one node while loading CompactMerkleTree, got %#v for ID %v@%v", s.signer.KeyHint, nodes, nodeID.String(), root.Revision)
+		hash, ok := hashes[nodeID.String()]
+		if !ok {
+			return nil, fmt.Errorf("%x: Did not retrieve node while loading CompactMerkleTree for ID %v@%v", s.signer.KeyHint, nodeID.String(), root.Revision)
 		}
-
-		return nodes[0].Hash, nil
+		return hash, nil
 	}, root.RootHash)
-
-	return mt, err
 }
 
 func (s Sequencer) buildNodesFromNodeMap(nodeMap map[string]storage.Node, newVersion int64) ([]storage.Node, error) {
@@ -168,7 +256,30 @@ func (s Sequencer) buildNodesFromNodeMap(nodeMap map[string]storage.Node, newVer
 	return targetNodes, nil
 }
 
-func (s Sequencer) updateCompactTree(mt *merkle.CompactMerkleTree, leaves []*trillian.LogLeaf, label string) (map[string]storage.Node, error) {
+// setIntegrateTimestamps sets the IntegrateTimestamp of each of the leaves.
+func (s Sequencer) setIntegrateTimestamps(leaves []*trillian.LogLeaf, label string) error {
+	for _, leaf := range leaves {
+		integrateTS := s.timeSource.Now()
+		var err error
+		leaf.IntegrateTimestamp, err = ptypes.TimestampProto(integrateTS)
+		if err != nil {
+			return fmt.Errorf("got invalid integrate timestamp: %v", err)
+		}
+
+		// Old leaves might not have a QueueTimestamp, only calculate the merge delay if this one does.
+		if leaf.QueueTimestamp != nil && leaf.QueueTimestamp.Seconds != 0 {
+			queueTS, err := ptypes.Timestamp(leaf.QueueTimestamp)
+			if err != nil {
+				return fmt.Errorf("got invalid queue timestamp: %v", queueTS)
+			}
+			mergeDelay := integrateTS.Sub(queueTS)
+			seqMergeDelay.Observe(mergeDelay.Seconds(), label)
+		}
+	}
+	return nil
+}
+
+func (s Sequencer) updateCompactTree(mt *merkle.CompactMerkleTree, leaves []*trillian.LogLeaf) (map[string]storage.Node, error) {
 	nodeMap := make(map[string]storage.Node)
 	// Update the tree state by integrating the leaves one by one.
 	for _, leaf := range leaves {
@@ -190,21 +301,6 @@ func (s Sequencer) updateCompactTree(mt *merkle.CompactMerkleTree, leaves []*tri
 		if leaf.LeafIndex != seq {
 			return nil, fmt.Errorf("got invalid leaf index: %v, want: %v", leaf.LeafIndex, seq)
 		}
-		integrateTS := s.timeSource.Now()
-		leaf.IntegrateTimestamp, err = ptypes.TimestampProto(integrateTS)
-		if err != nil {
-			return nil, fmt.Errorf("got invalid integrate timestamp: %v", err)
-		}
-
-		// Old leaves might not have a QueueTimestamp, only calculate the merge delay if this one does.
-		if leaf.QueueTimestamp != nil && leaf.QueueTimestamp.Seconds != 0 {
-			queueTS, err := ptypes.Timestamp(leaf.QueueTimestamp)
-			if err != nil {
-				return nil, fmt.Errorf("got invalid queue timestamp: %v", queueTS)
-			}
-			mergeDelay := integrateTS.Sub(queueTS)
-			seqMergeDelay.Observe(mergeDelay.Seconds(), label)
-		}
 
 		// Store leaf hash in the Merkle tree too:
 		leafNodeID, err := storage.NewNodeIDForTreeCoords(0, seq, maxTreeDepth)
@@ -229,6 +325,120 @@ func (s Sequencer) initMerkleTreeFromStorage(ctx context.Context, currentRoot *t
 	return s.buildMerkleTreeFromStorageAtRoot(ctx, currentRoot, tx)
 }
 
+// loadCompactTree returns the compact Merkle tree at currentRoot, from the
+// cache if possible, and otherwise from storage. If the tree comes from the
+// cache, the batch hashed ahead of time on top of it is returned too.
+func (s Sequencer) loadCompactTree(ctx context.Context, treeID int64, currentRoot *types.LogRootV1, tx storage.LogTreeTX, label string) (*merkle.CompactMerkleTree, *speculativeBatch, error) {
+	if s.treeCache != nil {
+		if mt, next := s.treeCache.take(treeID, currentRoot); mt != nil {
+			seqTreeCacheLookups.Inc(label, "true")
+			return mt, next, nil
+		}
+		seqTreeCacheLookups.Inc(label, "false")
+	}
+	mt, err := s.initMerkleTreeFromStorage(ctx, currentRoot, tx)
+	return mt, nil, err
+}
+
+// speculativeBatch holds the leaves expected to make up the next batch of a
+// log, hashed into a copy of its compact tree ahead of the transaction which
+// integrates them.
+type speculativeBatch struct {
+	// leaves hold the hashes of the leaves, and the indices they're expected
+	// to be sequenced at.
+	leaves []*trillian.LogLeaf
+	// done is closed once the fields below have been set.
+	done  chan struct{}
+	tree  *merkle.CompactMerkleTree
+	nodes map[string]storage.Node
+	err   error
+}
+
+// speculate starts hashing leaves into a copy of mt in a new goroutine, as if
+// they were the next batch to be integrated. The goroutine only needs CPU, and
+// doesn't use any transaction, so it runs concurrently with committing the
+// current batch and reading the next one.
+func (s Sequencer) speculate(mt *merkle.CompactMerkleTree, leaves []*trillian.LogLeaf) (*speculativeBatch, error) {
+	tree, err := s.copyCompactTree(mt)
+	if err != nil {
+		return nil, err
+	}
+	next := &speculativeBatch{
+		leaves: make([]*trillian.LogLeaf, len(leaves)),
+		done:   make(chan struct{}),
+	}
+	// The leaves are copied, as storage may share them with the next
+	// transaction, which assigns their indices.
+	for i, leaf := range leaves {
+		next.leaves[i] = &trillian.LogLeaf{
+			LeafIdentityHash: leaf.LeafIdentityHash,
+			MerkleLeafHash:   leaf.MerkleLeafHash,
+			LeafIndex:        mt.Size() + int64(i),
+		}
+	}
+	go func() {
+		defer close(next.done)
+		next.nodes, next.err = s.updateCompactTree(tree, next.leaves)
+		next.tree = tree
+	}()
+	return next, nil
+}
+
+// matches returns whether the leaves of the batch hashed ahead of time are the
+// first ones of leaves.
+func (b *speculativeBatch) matches(leaves []*trillian.LogLeaf) bool {
+	if len(b.leaves) > len(leaves) {
+		return false
+	}
+	for i, leaf := range b.leaves {
+		if leaves[i].LeafIndex != leaf.LeafIndex ||
+			!bytes.Equal(leaves[i].LeafIdentityHash, leaf.LeafIdentityHash) ||
+			!bytes.Equal(leaves[i].MerkleLeafHash, leaf.MerkleLeafHash) {
+			return false
+		}
+	}
+	return true
+}
+
+// copyCompactTree returns a copy of mt which can be updated independently.
+func (s Sequencer) copyCompactTree(mt *merkle.CompactMerkleTree) (*merkle.CompactMerkleTree, error) {
+	if mt.Size() == 0 {
+		return merkle.NewCompactMerkleTree(s.hasher), nil
+	}
+	hashes := mt.Hashes()
+	return merkle.NewCompactMerkleTreeWithState(s.hasher, mt.Size(), func(depth int, index int64) ([]byte, error) {
+		return hashes[depth], nil
+	}, mt.CurrentRoot())
+}
+
+// hashBatch integrates leaves into mt, and returns the resulting tree and the
+// nodes it updated. If next is a batch hashed ahead of time on top of mt whose
+// leaves are the first ones of leaves, its tree is used instead, and only the
+// rest of leaves are hashed.
+func (s Sequencer) hashBatch(mt *merkle.CompactMerkleTree, next *speculativeBatch, leaves []*trillian.LogLeaf, label string) (*merkle.CompactMerkleTree, map[string]storage.Node, error) {
+	if next != nil {
+		if next.err == nil && next.matches(leaves) {
+			seqSpeculativeBatches.Inc(label, "true")
+			nodeMap, err := s.updateCompactTree(next.tree, leaves[len(next.leaves):])
+			if err != nil {
+				return nil, nil, err
+			}
+			// Nodes updated by the later leaves take precedence.
+			for id, node := range nodeMap {
+				next.nodes[id] = node
+			}
+			return next.tree, next.nodes, nil
+		}
+		glog.V(1).Infof("%v: Batch hashed ahead of time not used (err: %v)", label, next.err)
+		seqSpeculativeBatches.Inc(label, "false")
+	}
+	nodeMap, err := s.updateCompactTree(mt, leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mt, nodeMap, nil
+}
+
 // sequencingTask provides sequenced LogLeaf entries, and updates storage
 // according to their ordering if needed.
 type sequencingTask interface {
@@ -239,6 +449,11 @@ type sequencingTask interface {
 
 	// update makes sequencing persisted in storage, if not yet.
 	update(ctx context.Context, leaves []*trillian.LogLeaf) error
+
+	// peek returns the entries that fetch is expected to return in the next
+	// transaction, once the tree has grown to treeSize, without changing
+	// storage. It returns no entries if the storage doesn't support that.
+	peek(ctx context.Context, treeSize int64, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error)
 }
 
 type sequencingTaskData struct {
@@ -281,6 +496,21 @@ func (s *logSequencingTask) update(ctx context.Context, leaves []*trillian.LogLe
 	return nil
 }
 
+func (s *logSequencingTask) peek(ctx context.Context, treeSize int64, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	// Leaves are dequeued within the transaction, so they can only be read
+	// ahead of time if the storage can read the queue without dequeuing.
+	qp, ok := s.tx.(storage.QueuePeeker)
+	if !ok {
+		return nil, nil
+	}
+	leaves, err := qp.PeekQueuedLeaves(ctx, limit, cutoff)
+	if err != nil {
+		glog.Warningf("%v: Sequencer failed to peek queued leaves: %v", s.label, err)
+		return nil, err
+	}
+	return leaves, nil
+}
+
 // preorderedLogSequencingTask is a sequencingTask implementation for
 // Pre-ordered Log mode. It reads sequenced entries past the tree size which
 // are already in the storage.
@@ -301,8 +531,25 @@ func (s *preorderedLogSequencingTask) update(ctx context.Context, leaves []*tril
 	return nil
 }
 
+func (s *preorderedLogSequencingTask) peek(ctx context.Context, treeSize int64, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	leaves, err := s.tx.GetLeavesByRange(ctx, treeSize, int64(limit))
+	if err != nil {
+		glog.Warningf("%v: Sequencer failed to load next sequenced leaves: %v", s.label, err)
+		return nil, err
+	}
+	return leaves, nil
+}
+
 // IntegrateBatch wraps up all the operations needed to take a batch of queued
 // or sequenced leaves and integrate them into the tree.
+//
+// Each batch is integrated in a single transaction, so batches of a log are
+// committed one at a time, in order. If a full batch is integrated by a
+// Sequencer with a CompactTreeCache, the transaction also reads the leaves
+// expected to make up the next batch, and starts hashing them into a copy of
+// the updated tree before committing. That hashing overlaps with the commit
+// and the next transaction's reads, and the next batch only uses it if it
+// starts with the same leaves on top of the same root.
 func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWindow, maxRootDurationInterval time.Duration) (int, error) {
 	start := s.timeSource.Now()
 	label := strconv.FormatInt(tree.TreeId, 10)
@@ -310,7 +557,15 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 	numLeaves := 0
 	var newLogRoot *types.LogRootV1
 	var newSLR *trillian.SignedLogRoot
-	err := s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+	var newTree *merkle.CompactMerkleTree
+	var newNext *speculativeBatch
+	var txDone time.Time
+	successors, err := s.successorSigners(ctx)
+	if err != nil {
+		glog.Warningf("%v: %v", tree.TreeId, err)
+		return 0, err
+	}
+	err = s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
 		stageStart := s.timeSource.Now()
 		defer seqBatches.Inc(label)
 		defer func() { seqLatency.Observe(util.SecondsSince(s.timeSource, start), label) }()
@@ -373,12 +628,11 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		}
 
 		stageStart = s.timeSource.Now()
-		merkleTree, err := s.initMerkleTreeFromStorage(ctx, &currentRoot, tx)
+		merkleTree, next, err := s.loadCompactTree(ctx, tree.TreeId, &currentRoot, tx, label)
 		if err != nil {
 			return err
 		}
 		seqInitTreeLatency.Observe(util.SecondsSince(s.timeSource, stageStart), label)
-		stageStart = s.timeSource.Now()
 
 		// We've done all the reads, can now do the updates in the same transaction.
 		// The schema should prevent multiple STHs being inserted with the same
@@ -389,8 +643,19 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 			return fmt.Errorf("%v: got writeRevision of %v, but expected %v", tree.TreeId, got, want)
 		}
 
+		if err := s.setIntegrateTimestamps(sequencedLeaves, label); err != nil {
+			return err
+		}
+
+		if next != nil {
+			stageStart = s.timeSource.Now()
+			<-next.done
+			seqPrepareWaitLatency.Observe(util.SecondsSince(s.timeSource, stageStart), label)
+		}
+		stageStart = s.timeSource.Now()
+
 		// Collate node updates.
-		nodeMap, err := s.updateCompactTree(merkleTree, sequencedLeaves, label)
+		merkleTree, nodeMap, err := s.hashBatch(merkleTree, next, sequencedLeaves, label)
 		if err != nil {
 			return err
 		}
@@ -423,37 +688,66 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 
 		// Create the log root ready for signing
 		seqTreeSize.Set(float64(merkleTree.Size()), label)
-		newLogRoot = &types.LogRootV1{
+		root := &types.LogRootV1{
 			RootHash:       merkleTree.CurrentRoot(),
 			TimestampNanos: uint64(s.timeSource.Now().UnixNano()),
 			TreeSize:       uint64(merkleTree.Size()),
 			Revision:       uint64(newVersion),
 		}
 
-		if newLogRoot.TimestampNanos <= currentRoot.TimestampNanos {
-			err := fmt.Errorf("refusing to sign root with timestamp earlier than previous root (%d <= %d)", newLogRoot.TimestampNanos, currentRoot.TimestampNanos)
+		if root.TimestampNanos <= currentRoot.TimestampNanos {
+			err := fmt.Errorf("refusing to sign root with timestamp earlier than previous root (%d <= %d)", root.TimestampNanos, currentRoot.TimestampNanos)
 			glog.Warningf("%v: %s", tree.TreeId, err)
 			return err
 		}
 
-		newSLR, err = s.signer.SignLogRoot(newLogRoot)
+		slr, err := s.signerFor(successors, root.TreeSize).SignLogRoot(root)
 		if err != nil {
 			glog.Warningf("%v: signer failed to sign root: %v", tree.TreeId, err)
 			return err
 		}
+		seqSignLatency.Observe(util.SecondsSince(s.timeSource, stageStart), label)
+		stageStart = s.timeSource.Now()
 
-		if err := tx.StoreSignedLogRoot(ctx, *newSLR); err != nil {
+		if err := tx.StoreSignedLogRoot(ctx, *slr); err != nil {
 			glog.Warningf("%v: failed to write updated tree root: %v", tree.TreeId, err)
 			return err
 		}
 		seqStoreRootLatency.Observe(util.SecondsSince(s.timeSource, stageStart), label)
-		stageStart = s.timeSource.Now()
+
+		// A full batch suggests there are more leaves to integrate, so start
+		// hashing the next batch while this one is committed. The hashing is only
+		// kept in the cache, so there's no point without one.
+		var nextBatch *speculativeBatch
+		if s.treeCache != nil && numLeaves == limit {
+			stageStart = s.timeSource.Now()
+			nextLeaves, err := st.peek(ctx, int64(root.TreeSize), limit, start.Add(-guardWindow))
+			if err != nil {
+				return err
+			}
+			seqPeekLatency.Observe(util.SecondsSince(s.timeSource, stageStart), label)
+			if len(nextLeaves) > 0 {
+				if nextBatch, err = s.speculate(merkleTree, nextLeaves); err != nil {
+					glog.Warningf("%v: Sequencer failed to copy compact tree: %v", tree.TreeId, err)
+					return err
+				}
+			}
+		}
+		newLogRoot, newSLR, newTree, newNext = root, slr, merkleTree, nextBatch
+		txDone = s.timeSource.Now()
 
 		return nil
 	})
 	if err != nil {
 		return 0, err
 	}
+	if newSLR != nil {
+		seqCommitLatency.Observe(util.SecondsSince(s.timeSource, txDone), label)
+		// The tree is only cached once the root it matches is committed.
+		if s.treeCache != nil {
+			s.treeCache.put(tree.TreeId, newLogRoot, newTree, newNext)
+		}
+	}
 
 	// Let quota.Manager know about newly-sequenced entries.
 	// All possibly influenced quotas are replenished: {Tree/Global, Read/Write}.
@@ -486,6 +780,11 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 
 // SignRoot wraps up all the operations for creating a new log signed root.
 func (s Sequencer) SignRoot(ctx context.Context, tree *trillian.Tree) error {
+	successors, err := s.successorSigners(ctx)
+	if err != nil {
+		glog.Warningf("%v: %v", tree.TreeId, err)
+		return err
+	}
 	return s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
 		// Get the latest known root from storage
 		sth, err := tx.LatestSignedLogRoot(ctx)
@@ -511,7 +810,7 @@ func (s Sequencer) SignRoot(ctx context.Context, tree *trillian.Tree) error {
 			TreeSize:       uint64(merkleTree.Size()),
 			Revision:       currentRoot.Revision + 1,
 		}
-		newSLR, err := s.signer.SignLogRoot(newLogRoot)
+		newSLR, err := s.signerFor(successors, newLogRoot.TreeSize).SignLogRoot(newLogRoot)
 		if err != nil {
 			glog.Warningf("%v: signer failed to sign root: %v", tree.TreeId, err)
 			return err
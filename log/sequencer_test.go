@@ -15,10 +15,12 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/merkle/rfc6962"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
@@ -401,6 +404,7 @@ func TestIntegrateBatch(t *testing.T) {
 				updatedLeaves:       &leaves16,
 				updatedLeavesError:  errors.New("unsequenced"),
 				skipStoreSignedRoot: true,
+				signer:              fixedSigner,
 			},
 			errStr: "unsequenced",
 		},
@@ -416,6 +420,7 @@ func TestIntegrateBatch(t *testing.T) {
 				merkleNodesSet:      &updatedNodes,
 				merkleNodesSetError: errors.New("setmerklenodes"),
 				skipStoreSignedRoot: true,
+				signer:              fixedSigner,
 			},
 			errStr: "setmerklenodes",
 		},
@@ -779,3 +784,360 @@ func TestSignRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestIntegrateBatch_CompactTreeCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	hasher := rfc6962.DefaultHasher
+	ts := util.NewFakeTimeSource(fakeTimeForTest)
+	signer := tcrypto.NewSigner(0, newSignerWithFixedSig(testSignedRoot.LogRootSignature), crypto.SHA256)
+	tree := &trillian.Tree{TreeId: 154035, TreeType: trillian.TreeType_LOG}
+	logStorage := &stestonly.FakeLogStorage{}
+	sequencer := NewSequencer(hasher, ts, logStorage, signer, nil, quota.Noop())
+	sequencer.SetCompactTreeCache(NewCompactTreeCache())
+
+	leafHashes := make([][]byte, 4)
+	for i := range leafHashes {
+		var err error
+		if leafHashes[i], err = hasher.HashLeaf([]byte(fmt.Sprintf("leaf-%d", i))); err != nil {
+			t.Fatalf("HashLeaf(): %v", err)
+		}
+	}
+	// The nodes read to resume the compact tree of size 3.
+	var storedIDs []storage.NodeID
+	var storedNodes []storage.Node
+	for _, n := range []struct {
+		depth, index int64
+		hash         []byte
+	}{
+		{depth: 0, index: 2, hash: leafHashes[2]},
+		{depth: 1, index: 0, hash: hasher.HashChildren(leafHashes[0], leafHashes[1])},
+	} {
+		id, err := storage.NewNodeIDForTreeCoords(n.depth, n.index, maxTreeDepth)
+		if err != nil {
+			t.Fatalf("NewNodeIDForTreeCoords(): %v", err)
+		}
+		storedIDs = append(storedIDs, id)
+		storedNodes = append(storedNodes, storage.Node{NodeID: id, Hash: n.hash})
+	}
+
+	// integrate sequences count more leaves on top of latest, and returns the
+	// root it stores. If readNodes is set the compact tree is expected to be
+	// read from storage, otherwise it must come from the cache.
+	integrate := func(latest trillian.SignedLogRoot, count int, readNodes bool) trillian.SignedLogRoot {
+		t.Helper()
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(latest.LogRoot); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		ts.Set(ts.Now().Add(time.Second))
+		var leaves []*trillian.LogLeaf
+		for i := 0; i < count; i++ {
+			leaves = append(leaves, &trillian.LogLeaf{MerkleLeafHash: leafHashes[int(root.TreeSize)+i]})
+		}
+
+		var stored trillian.SignedLogRoot
+		tx := storage.NewMockLogTreeTX(ctrl)
+		tx.EXPECT().LatestSignedLogRoot(any).Return(latest, nil)
+		tx.EXPECT().DequeueLeaves(any, any, any).Return(leaves, nil)
+		tx.EXPECT().WriteRevision().AnyTimes().Return(int64(root.Revision + 1))
+		if readNodes {
+			tx.EXPECT().GetMerkleNodes(any, int64(root.Revision), storedIDs).Return(storedNodes, nil)
+		}
+		tx.EXPECT().UpdateSequencedLeaves(any, any).Return(nil)
+		tx.EXPECT().SetMerkleNodes(any, any).Return(nil)
+		tx.EXPECT().StoreSignedLogRoot(any, any).Do(func(_ context.Context, slr trillian.SignedLogRoot) { stored = slr }).Return(nil)
+		tx.EXPECT().Commit().Return(nil)
+		tx.EXPECT().Close().Return(nil)
+		logStorage.TX = tx
+
+		if _, err := sequencer.IntegrateBatch(ctx, tree, count, 0, 0); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		return stored
+	}
+
+	empty, err := signer.SignLogRoot(&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: uint64(ts.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	root3 := integrate(*empty, 3, false)
+	// The tree at root3 is cached, so no nodes are read.
+	root4 := integrate(root3, 1, false)
+	var got types.LogRootV1
+	if err := got.UnmarshalBinary(root4.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	want := hasher.HashChildren(hasher.HashChildren(leafHashes[0], leafHashes[1]), hasher.HashChildren(leafHashes[2], leafHashes[3]))
+	if got.TreeSize != 4 || !bytes.Equal(got.RootHash, want) {
+		t.Errorf("IntegrateBatch() stored root of size %d with hash %x, want size 4 with hash %x", got.TreeSize, got.RootHash, want)
+	}
+
+	// The cache now holds the tree at root4, so integrating on top of root3
+	// again reads the tree from storage.
+	integrate(root3, 1, true)
+}
+
+func TestIntegrateBatch_SuccessorSigner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	hasher := rfc6962.DefaultHasher
+	ts := util.NewFakeTimeSource(fakeTimeForTest)
+	signer := tcrypto.NewSigner(154035, newSignerWithFixedSig(testSignedRoot.LogRootSignature), crypto.SHA256)
+	successor := tcrypto.NewSigner(7, newSignerWithFixedSig(testSignedRoot.LogRootSignature), crypto.SHA256)
+	tree := &trillian.Tree{TreeId: 154035, TreeType: trillian.TreeType_LOG}
+	logStorage := &stestonly.FakeLogStorage{}
+	sequencer := NewSequencer(hasher, ts, logStorage, signer, nil, quota.Noop())
+	sequencer.SetCompactTreeCache(NewCompactTreeCache())
+	var successors []SuccessorSigner
+	sequencer.SetSuccessorSigners(func(context.Context) ([]SuccessorSigner, error) { return successors, nil })
+
+	// integrate sequences count more leaves on top of latest, and returns the
+	// root it stores.
+	integrate := func(latest trillian.SignedLogRoot, count int) trillian.SignedLogRoot {
+		t.Helper()
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(latest.LogRoot); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		ts.Set(ts.Now().Add(time.Second))
+		var leaves []*trillian.LogLeaf
+		for i := 0; i < count; i++ {
+			hash, err := hasher.HashLeaf([]byte(fmt.Sprintf("leaf-%d", int(root.TreeSize)+i)))
+			if err != nil {
+				t.Fatalf("HashLeaf(): %v", err)
+			}
+			leaves = append(leaves, &trillian.LogLeaf{MerkleLeafHash: hash})
+		}
+
+		var stored trillian.SignedLogRoot
+		tx := storage.NewMockLogTreeTX(ctrl)
+		tx.EXPECT().LatestSignedLogRoot(any).Return(latest, nil)
+		tx.EXPECT().DequeueLeaves(any, any, any).Return(leaves, nil)
+		tx.EXPECT().WriteRevision().AnyTimes().Return(int64(root.Revision + 1))
+		tx.EXPECT().UpdateSequencedLeaves(any, any).Return(nil)
+		tx.EXPECT().SetMerkleNodes(any, any).Return(nil)
+		tx.EXPECT().StoreSignedLogRoot(any, any).Do(func(_ context.Context, slr trillian.SignedLogRoot) { stored = slr }).Return(nil)
+		tx.EXPECT().Commit().Return(nil)
+		tx.EXPECT().Close().Return(nil)
+		logStorage.TX = tx
+
+		if _, err := sequencer.IntegrateBatch(ctx, tree, count, 0, 0); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		return stored
+	}
+
+	empty, err := signer.SignLogRoot(&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: uint64(ts.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	root2 := integrate(*empty, 2)
+	if want := types.SerializeKeyHint(154035); !bytes.Equal(root2.KeyHint, want) {
+		t.Errorf("root of size 2 has key hint %x, want %x", root2.KeyHint, want)
+	}
+	// A key added after the Sequencer was created takes over from its start
+	// tree size. Roots below it are still signed by the original key.
+	successors = []SuccessorSigner{{StartTreeSize: 4, Signer: successor}}
+	root3 := integrate(root2, 1)
+	if want := types.SerializeKeyHint(154035); !bytes.Equal(root3.KeyHint, want) {
+		t.Errorf("root of size 3 has key hint %x, want %x", root3.KeyHint, want)
+	}
+	root5 := integrate(root3, 2)
+	if want := types.SerializeKeyHint(7); !bytes.Equal(root5.KeyHint, want) {
+		t.Errorf("root of size 5 has key hint %x, want %x", root5.KeyHint, want)
+	}
+}
+
+func TestIntegrateBatch_WriteOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	hasher := rfc6962.DefaultHasher
+	ts := util.NewFakeTimeSource(fakeTimeForTest)
+	signer := tcrypto.NewSigner(0, newSignerWithFixedSig(testSignedRoot.LogRootSignature), crypto.SHA256)
+	tree := &trillian.Tree{TreeId: 154035, TreeType: trillian.TreeType_LOG}
+	logStorage := &stestonly.FakeLogStorage{}
+	sequencer := NewSequencer(hasher, ts, logStorage, signer, nil, quota.Noop())
+
+	empty, err := signer.SignLogRoot(&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: uint64(ts.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	ts.Set(ts.Now().Add(time.Second))
+	var leaves []*trillian.LogLeaf
+	for i := 0; i < 3; i++ {
+		hash, err := hasher.HashLeaf([]byte(fmt.Sprintf("leaf-%d", i)))
+		if err != nil {
+			t.Fatalf("HashLeaf(): %v", err)
+		}
+		leaves = append(leaves, &trillian.LogLeaf{MerkleLeafHash: hash})
+	}
+
+	// The writes must be made in order, before the transaction is committed.
+	tx := storage.NewMockLogTreeTX(ctrl)
+	tx.EXPECT().LatestSignedLogRoot(any).Return(*empty, nil)
+	tx.EXPECT().DequeueLeaves(any, any, any).Return(leaves, nil)
+	tx.EXPECT().WriteRevision().AnyTimes().Return(int64(1))
+	gomock.InOrder(
+		tx.EXPECT().UpdateSequencedLeaves(any, any).Return(nil),
+		tx.EXPECT().SetMerkleNodes(any, any).Return(nil),
+		tx.EXPECT().StoreSignedLogRoot(any, any).Return(nil),
+		tx.EXPECT().Commit().Return(nil),
+	)
+	tx.EXPECT().Close().Return(nil)
+	logStorage.TX = tx
+
+	if got, err := sequencer.IntegrateBatch(ctx, tree, len(leaves), 0, 0); got != len(leaves) || err != nil {
+		t.Errorf("IntegrateBatch() = (%v, %v), want (%v, nil)", got, err, len(leaves))
+	}
+}
+
+// peekingLogTreeTX is a LogTreeTX which can peek at the queue.
+type peekingLogTreeTX struct {
+	*storage.MockLogTreeTX
+	queued []*trillian.LogLeaf
+	peeked bool
+}
+
+func (tx *peekingLogTreeTX) PeekQueuedLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	tx.peeked = true
+	if limit < len(tx.queued) {
+		return tx.queued[:limit], nil
+	}
+	return tx.queued, nil
+}
+
+func TestIntegrateBatch_NextBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	hasher := rfc6962.DefaultHasher
+	ts := util.NewFakeTimeSource(fakeTimeForTest)
+	signer := tcrypto.NewSigner(0, newSignerWithFixedSig(testSignedRoot.LogRootSignature), crypto.SHA256)
+	tree := &trillian.Tree{TreeId: 154035, TreeType: trillian.TreeType_LOG}
+	logStorage := &stestonly.FakeLogStorage{}
+	cache := NewCompactTreeCache()
+	sequencer := NewSequencer(hasher, ts, logStorage, signer, nil, quota.Noop())
+	sequencer.SetCompactTreeCache(cache)
+
+	leafHashes := make([][]byte, 6)
+	for i := range leafHashes {
+		var err error
+		if leafHashes[i], err = hasher.HashLeaf([]byte(fmt.Sprintf("leaf-%d", i))); err != nil {
+			t.Fatalf("HashLeaf(): %v", err)
+		}
+	}
+	newLeaves := func(hashes ...[]byte) []*trillian.LogLeaf {
+		var leaves []*trillian.LogLeaf
+		for _, hash := range hashes {
+			leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: hash, MerkleLeafHash: hash})
+		}
+		return leaves
+	}
+	// The serially computed tree, and the nodes set by each batch.
+	want := merkle.NewCompactMerkleTree(hasher)
+	wantNodes := func(hashes ...[]byte) []storage.Node {
+		t.Helper()
+		leaves := newLeaves(hashes...)
+		for i, leaf := range leaves {
+			leaf.LeafIndex = want.Size() + int64(i)
+		}
+		nodeMap, err := sequencer.updateCompactTree(want, leaves)
+		if err != nil {
+			t.Fatalf("updateCompactTree(): %v", err)
+		}
+		var nodes []storage.Node
+		for _, node := range nodeMap {
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	// integrate sequences the dequeued leaves on top of latest, with queued
+	// left in the queue, and returns the root it stores.
+	integrate := func(latest trillian.SignedLogRoot, dequeued, queued []*trillian.LogLeaf, nodes []storage.Node) trillian.SignedLogRoot {
+		t.Helper()
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(latest.LogRoot); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		for i := range nodes {
+			nodes[i].NodeRevision = int64(root.Revision + 1)
+		}
+		ts.Set(ts.Now().Add(time.Second))
+
+		var stored trillian.SignedLogRoot
+		tx := &peekingLogTreeTX{MockLogTreeTX: storage.NewMockLogTreeTX(ctrl), queued: queued}
+		tx.EXPECT().LatestSignedLogRoot(any).Return(latest, nil)
+		tx.EXPECT().DequeueLeaves(any, any, any).Return(dequeued, nil)
+		tx.EXPECT().WriteRevision().AnyTimes().Return(int64(root.Revision + 1))
+		tx.EXPECT().UpdateSequencedLeaves(any, any).Return(nil)
+		tx.EXPECT().SetMerkleNodes(any, any).Do(func(_ context.Context, got []storage.Node) {
+			byID := make(map[string]storage.Node)
+			for _, node := range got {
+				byID[node.NodeID.String()] = node
+			}
+			if len(got) != len(nodes) {
+				t.Errorf("SetMerkleNodes() got %d nodes, want %d", len(got), len(nodes))
+			}
+			for _, node := range nodes {
+				if !reflect.DeepEqual(byID[node.NodeID.String()], node) {
+					t.Errorf("SetMerkleNodes() got node %+v, want %+v", byID[node.NodeID.String()], node)
+				}
+			}
+		}).Return(nil)
+		tx.EXPECT().StoreSignedLogRoot(any, any).Do(func(_ context.Context, slr trillian.SignedLogRoot) {
+			if tx.peeked {
+				t.Error("PeekQueuedLeaves() called before StoreSignedLogRoot()")
+			}
+			stored = slr
+		}).Return(nil)
+		tx.EXPECT().Commit().Do(func() {
+			if !tx.peeked {
+				t.Error("PeekQueuedLeaves() not called before Commit()")
+			}
+		}).Return(nil)
+		tx.EXPECT().Close().Return(nil)
+		logStorage.TX = tx
+
+		if _, err := sequencer.IntegrateBatch(ctx, tree, len(dequeued), 0, 0); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		if got := stored.RootHash; !bytes.Equal(got, want.CurrentRoot()) {
+			t.Errorf("IntegrateBatch() stored root hash %x, want %x", got, want.CurrentRoot())
+		}
+		return stored
+	}
+
+	empty, err := signer.SignLogRoot(&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: uint64(ts.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	root2 := integrate(*empty, newLeaves(leafHashes[0], leafHashes[1]), newLeaves(leafHashes[2], leafHashes[3]),
+		wantNodes(leafHashes[0], leafHashes[1]))
+	if next := cache.trees[tree.TreeId].next; next == nil || len(next.leaves) != 2 {
+		t.Fatalf("IntegrateBatch() cached next batch %+v, want 2 leaves", next)
+	}
+
+	// The next batch starts with the leaves hashed ahead of time.
+	root5 := integrate(root2, newLeaves(leafHashes[2], leafHashes[3], leafHashes[4]), newLeaves(leafHashes[0]),
+		wantNodes(leafHashes[2], leafHashes[3], leafHashes[4]))
+
+	// The next batch doesn't start with the leaves hashed ahead of time, so
+	// they're discarded.
+	integrate(root5, newLeaves(leafHashes[5]), nil, wantNodes(leafHashes[5]))
+	if next := cache.trees[tree.TreeId].next; next != nil {
+		t.Errorf("IntegrateBatch() cached next batch %+v, want none", next)
+	}
+}
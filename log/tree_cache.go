@@ -0,0 +1,80 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/types"
+)
+
+// CompactTreeCache holds the compact Merkle tree of each log as of the last
+// batch a Sequencer integrated into it, so that the next batch can start
+// hashing without reading the tree state back from storage. Along with the
+// tree it holds the hashing of the leaves expected to make up the next batch,
+// if the Sequencer started it ahead of time.
+//
+// Entries are only used if they match the latest root read in the
+// sequencing transaction, so a stale entry (e.g. because another instance
+// sequenced the log in the meantime) just costs a storage read.
+type CompactTreeCache struct {
+	mu    sync.Mutex
+	trees map[int64]cachedTree
+}
+
+type cachedTree struct {
+	root types.LogRootV1
+	tree *merkle.CompactMerkleTree
+	next *speculativeBatch
+}
+
+// NewCompactTreeCache returns an empty CompactTreeCache.
+func NewCompactTreeCache() *CompactTreeCache {
+	return &CompactTreeCache{trees: make(map[int64]cachedTree)}
+}
+
+// take removes the compact tree cached for treeID from the cache and returns
+// it, along with the next batch hashed on top of it (which may be nil), if
+// it's the tree at root. Otherwise it returns nils. The caller owns the
+// returned tree, and may put it back once it's updated.
+func (c *CompactTreeCache) take(treeID int64, root *types.LogRootV1) (*merkle.CompactMerkleTree, *speculativeBatch) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.trees[treeID]
+	if !ok {
+		return nil, nil
+	}
+	delete(c.trees, treeID)
+	if entry.root.TreeSize != root.TreeSize || entry.root.Revision != root.Revision || !bytes.Equal(entry.root.RootHash, root.RootHash) {
+		return nil, nil
+	}
+	return entry.tree, entry.next
+}
+
+// put caches tree as the compact tree of treeID at root, and next as the
+// next batch hashed on top of it. next may be nil.
+func (c *CompactTreeCache) put(treeID int64, root *types.LogRootV1, tree *merkle.CompactMerkleTree, next *speculativeBatch) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trees[treeID] = cachedTree{root: *root, tree: tree, next: next}
+}
@@ -0,0 +1,289 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mapper maps the contents of a Trillian Log into a Trillian Map.
+//
+// A personality supplies a MapFunc which derives map entries from each log
+// leaf, and optionally a ReduceFunc which combines them with the existing
+// values in the map. The Mapper reads the log in batches, verifying each leaf
+// against a trusted log root, and writes one map revision per batch. The
+// position in the log that has been mapped up to is stored in the metadata of
+// each map root, as a mapperpb.Checkpoint, so a Mapper resumes from where the
+// last one stopped.
+package mapper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/mapper/mapperpb"
+	"github.com/google/trillian/types"
+)
+
+const (
+	// DefaultBatchSize is the default maximum number of log leaves mapped into
+	// each map revision.
+	DefaultBatchSize = 256
+	// DefaultPollInterval is the default interval between checks for new log
+	// leaves, once all the log leaves have been mapped.
+	DefaultPollInterval = 5 * time.Second
+)
+
+// Entry is a value which a log leaf contributes to a map leaf.
+type Entry struct {
+	Index []byte
+	Value []byte
+}
+
+// MapFunc returns the entries which a log leaf contributes to the map. A leaf
+// may contribute no entries, or several to the same index.
+type MapFunc func(leaf *trillian.LogLeaf) ([]Entry, error)
+
+// ReduceFunc returns the new value of the map leaf at index, given its current
+// value, which is nil if the leaf is empty, and the values contributed to it
+// by a batch of log leaves, in log order.
+type ReduceFunc func(index, current []byte, values [][]byte) ([]byte, error)
+
+// Options holds the optional parameters of a Mapper.
+type Options struct {
+	// BatchSize is the maximum number of log leaves mapped into each map
+	// revision. If zero, DefaultBatchSize is used.
+	BatchSize int64
+	// PollInterval is how long Run waits before checking for new log leaves
+	// once all the log leaves have been mapped, or after a failure. If zero,
+	// DefaultPollInterval is used.
+	PollInterval time.Duration
+	// Reduce combines the values contributed to a map leaf with its current
+	// value. If nil, the last value contributed to a map leaf replaces its
+	// current value, and current values aren't read.
+	Reduce ReduceFunc
+}
+
+// Mapper maps the leaves of a log into a map.
+//
+// Only one Mapper should write to a map at a time. Each map revision is
+// written with the expected revision set, so a concurrent writer makes the
+// Mapper fail rather than map the same log leaves twice.
+type Mapper struct {
+	log         *client.LogClient
+	tmap        trillian.TrillianMapClient
+	mapVerifier *client.MapVerifier
+	mapFn       MapFunc
+	opts        Options
+}
+
+// New returns a Mapper which maps the log read through log into the map
+// identified by mapVerifier.MapID, using mapFn.
+func New(log *client.LogClient, tmap trillian.TrillianMapClient, mapVerifier *client.MapVerifier, mapFn MapFunc, opts Options) *Mapper {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	return &Mapper{
+		log:         log,
+		tmap:        tmap,
+		mapVerifier: mapVerifier,
+		mapFn:       mapFn,
+		opts:        opts,
+	}
+}
+
+// Run maps log leaves until ctx is done. Failures are logged and retried
+// after the poll interval.
+func (m *Mapper) Run(ctx context.Context) error {
+	for {
+		n, err := m.RunOnce(ctx)
+		if err != nil {
+			glog.Warningf("%v: mapper run failed: %v", m.mapVerifier.MapID, err)
+		}
+		if err == nil && n > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.opts.PollInterval):
+		}
+	}
+}
+
+// RunOnce maps the next batch of log leaves into a new map revision, and
+// returns the number of leaves mapped. If all the leaves of the latest log
+// root have already been mapped, nothing is written and it returns zero.
+func (m *Mapper) RunOnce(ctx context.Context) (int, error) {
+	mapRoot, checkpoint, err := m.latestCheckpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	start := checkpoint.NextLogIndex
+
+	logRoot := m.log.GetRoot()
+	if int64(logRoot.TreeSize) <= start {
+		if _, err := m.log.UpdateRoot(ctx); err != nil {
+			return 0, fmt.Errorf("failed to update log root: %v", err)
+		}
+		logRoot = m.log.GetRoot()
+	}
+	if int64(logRoot.TreeSize) <= start {
+		return 0, nil
+	}
+
+	count := int64(logRoot.TreeSize) - start
+	if count > m.opts.BatchSize {
+		count = m.opts.BatchSize
+	}
+	leaves, err := m.readLeaves(ctx, logRoot, start, count)
+	if err != nil {
+		return 0, err
+	}
+
+	// Gather the values contributed to each index, keeping the order the
+	// indexes were first seen in.
+	var indexes [][]byte
+	values := make(map[string][][]byte)
+	for _, leaf := range leaves {
+		entries, err := m.mapFn(leaf)
+		if err != nil {
+			return 0, fmt.Errorf("failed to map log leaf %d: %v", leaf.LeafIndex, err)
+		}
+		for _, e := range entries {
+			k := string(e.Index)
+			if _, ok := values[k]; !ok {
+				indexes = append(indexes, e.Index)
+			}
+			values[k] = append(values[k], e.Value)
+		}
+	}
+
+	current := make(map[string][]byte)
+	if m.opts.Reduce != nil && len(indexes) > 0 {
+		if current, err = m.readValues(ctx, mapRoot, indexes); err != nil {
+			return 0, err
+		}
+	}
+	mapLeaves := make([]*trillian.MapLeaf, 0, len(indexes))
+	for _, index := range indexes {
+		vs := values[string(index)]
+		value := vs[len(vs)-1]
+		if m.opts.Reduce != nil {
+			if value, err = m.opts.Reduce(index, current[string(index)], vs); err != nil {
+				return 0, fmt.Errorf("failed to reduce values for index %x: %v", index, err)
+			}
+		}
+		mapLeaves = append(mapLeaves, &trillian.MapLeaf{Index: index, LeafValue: value})
+	}
+
+	logRootBytes, err := logRoot.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	metadata, err := proto.Marshal(&mapperpb.Checkpoint{
+		NextLogIndex: start + count,
+		LogRoot:      logRootBytes,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal checkpoint: %v", err)
+	}
+	resp, err := m.tmap.SetLeaves(ctx, &trillian.SetMapLeavesRequest{
+		MapId:    m.mapVerifier.MapID,
+		Leaves:   mapLeaves,
+		Metadata: metadata,
+		Revision: int64(mapRoot.Revision) + 1,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to set map leaves: %v", err)
+	}
+	newRoot, err := m.mapVerifier.VerifySignedMapRoot(resp.GetMapRoot())
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify new map root: %v", err)
+	}
+	if !bytes.Equal(newRoot.Metadata, metadata) {
+		return 0, fmt.Errorf("new map root has metadata %x, want %x", newRoot.Metadata, metadata)
+	}
+	glog.V(1).Infof("%v: mapped log leaves [%d, %d) into %d map leaves at revision %d", m.mapVerifier.MapID, start, start+count, len(mapLeaves), newRoot.Revision)
+	return int(count), nil
+}
+
+// Checkpoint returns the checkpoint stored in the latest map root, which
+// records how much of the log has been mapped.
+func (m *Mapper) Checkpoint(ctx context.Context) (*mapperpb.Checkpoint, error) {
+	_, checkpoint, err := m.latestCheckpoint(ctx)
+	return checkpoint, err
+}
+
+// latestCheckpoint returns the latest map root, and the checkpoint stored in
+// it. A map root without metadata has a checkpoint at the start of the log.
+func (m *Mapper) latestCheckpoint(ctx context.Context) (*types.MapRootV1, *mapperpb.Checkpoint, error) {
+	resp, err := m.tmap.GetSignedMapRoot(ctx, &trillian.GetSignedMapRootRequest{MapId: m.mapVerifier.MapID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get map root: %v", err)
+	}
+	mapRoot, err := m.mapVerifier.VerifySignedMapRoot(resp.GetMapRoot())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify map root: %v", err)
+	}
+	var checkpoint mapperpb.Checkpoint
+	if err := proto.Unmarshal(mapRoot.Metadata, &checkpoint); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal checkpoint from map root metadata: %v", err)
+	}
+	return mapRoot, &checkpoint, nil
+}
+
+// readLeaves returns count log leaves starting at start, each verified to be
+// included in the log at logRoot.
+func (m *Mapper) readLeaves(ctx context.Context, logRoot *types.LogRootV1, start, count int64) ([]*trillian.LogLeaf, error) {
+	leaves, err := m.log.ListByIndex(ctx, start, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log leaves [%d, %d): %v", start, start+count, err)
+	}
+	leaves = leaves[:count]
+	for _, leaf := range leaves {
+		if err := m.log.GetAndVerifyInclusionAtIndex(ctx, leaf.LeafValue, leaf.LeafIndex, logRoot); err != nil {
+			return nil, fmt.Errorf("failed to verify inclusion of log leaf %d: %v", leaf.LeafIndex, err)
+		}
+	}
+	return leaves, nil
+}
+
+// readValues returns the values of the map leaves at indexes in the map at
+// mapRoot, keyed by index, each verified against the map root.
+func (m *Mapper) readValues(ctx context.Context, mapRoot *types.MapRootV1, indexes [][]byte) (map[string][]byte, error) {
+	resp, err := m.tmap.GetLeavesByRevision(ctx, &trillian.GetMapLeavesByRevisionRequest{
+		MapId:    m.mapVerifier.MapID,
+		Index:    indexes,
+		Revision: int64(mapRoot.Revision),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get map leaves: %v", err)
+	}
+	if got, want := len(resp.GetMapLeafInclusion()), len(indexes); got != want {
+		return nil, fmt.Errorf("got %d map leaves, want %d", got, want)
+	}
+	values := make(map[string][]byte)
+	for _, incl := range resp.GetMapLeafInclusion() {
+		if err := m.mapVerifier.VerifyMapLeafInclusionHash(mapRoot.RootHash, incl); err != nil {
+			return nil, fmt.Errorf("failed to verify map leaf %x: %v", incl.GetLeaf().GetIndex(), err)
+		}
+		values[string(incl.GetLeaf().GetIndex())] = incl.GetLeaf().GetLeafValue()
+	}
+	return values, nil
+}
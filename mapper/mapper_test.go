@@ -0,0 +1,239 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/testonly/integration"
+	"github.com/google/trillian/types"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// mapKeyValue maps log leaves of the form "key=value" to value at the index of
+// key.
+func mapKeyValue(leaf *trillian.LogLeaf) ([]Entry, error) {
+	parts := strings.SplitN(string(leaf.LeafValue), "=", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed leaf %q", leaf.LeafValue)
+	}
+	return []Entry{{Index: testonly.HashKey(parts[0]), Value: []byte(parts[1])}}, nil
+}
+
+// concat reduces values by appending them to the current value.
+func concat(index, current []byte, values [][]byte) ([]byte, error) {
+	return bytes.Join(append([][]byte{current}, values...), nil), nil
+}
+
+type testEnv struct {
+	logEnv      *integration.LogEnv
+	mapEnv      *integration.MapEnv
+	log         *client.LogClient
+	mapVerifier *client.MapVerifier
+}
+
+func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
+	t.Helper()
+	ls := memory.NewLogStorage(nil)
+	logEnv, err := integration.NewLogEnvWithRegistry(ctx, 1, extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ls),
+		LogStorage:   ls,
+		QuotaManager: quota.Noop(),
+	})
+	if err != nil {
+		t.Fatalf("NewLogEnvWithRegistry(): %v", err)
+	}
+	ms := memory.NewLogStorage(nil)
+	mapEnv, err := integration.NewMapEnvWithRegistry(extension.Registry{
+		AdminStorage:  memory.NewAdminStorage(ms),
+		MapStorage:    memory.NewMapStorage(ms),
+		QuotaManager:  quota.Noop(),
+		MetricFactory: monitoring.InertMetricFactory{},
+		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+			return der.NewProtoFromSpec(spec)
+		},
+	})
+	if err != nil {
+		logEnv.Close()
+		t.Fatalf("NewMapEnvWithRegistry(): %v", err)
+	}
+	env := &testEnv{logEnv: logEnv, mapEnv: mapEnv}
+
+	logTree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{Tree: stestonly.LogTree}, logEnv.Admin, nil, logEnv.Log)
+	if err != nil {
+		env.Close()
+		t.Fatalf("CreateAndInitTree(log): %v", err)
+	}
+	if env.log, err = client.NewFromTree(logEnv.Log, logTree, types.LogRootV1{}); err != nil {
+		env.Close()
+		t.Fatalf("NewFromTree(): %v", err)
+	}
+	mapTree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{Tree: stestonly.MapTree}, mapEnv.Admin, mapEnv.Map, nil)
+	if err != nil {
+		env.Close()
+		t.Fatalf("CreateAndInitTree(map): %v", err)
+	}
+	if env.mapVerifier, err = client.NewMapVerifierFromTree(mapTree); err != nil {
+		env.Close()
+		t.Fatalf("NewMapVerifierFromTree(): %v", err)
+	}
+	return env
+}
+
+func (env *testEnv) Close() {
+	env.mapEnv.Close()
+	env.logEnv.Close()
+}
+
+// addLeaves appends leaves to the log one at a time, so they're integrated in
+// order, and waits for each to be integrated.
+func (env *testEnv) addLeaves(ctx context.Context, t *testing.T, leaves ...string) {
+	t.Helper()
+	for _, l := range leaves {
+		if err := env.log.AddLeaf(ctx, []byte(l)); err != nil {
+			t.Fatalf("AddLeaf(%q): %v", l, err)
+		}
+	}
+}
+
+// checkValues checks the latest values of the map leaves at the given keys.
+func (env *testEnv) checkValues(ctx context.Context, t *testing.T, want map[string]string) {
+	t.Helper()
+	var indexes [][]byte
+	for k := range want {
+		indexes = append(indexes, testonly.HashKey(k))
+	}
+	resp, err := env.mapEnv.Map.GetLeaves(ctx, &trillian.GetMapLeavesRequest{
+		MapId: env.mapVerifier.MapID,
+		Index: indexes,
+	})
+	if err != nil {
+		t.Fatalf("GetLeaves(): %v", err)
+	}
+	got := make(map[string]string)
+	for _, incl := range resp.MapLeafInclusion {
+		got[string(incl.Leaf.Index)] = string(incl.Leaf.LeafValue)
+	}
+	for k, v := range want {
+		if got := got[string(testonly.HashKey(k))]; got != v {
+			t.Errorf("map value of %q: %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestMapper(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+
+	m := New(env.log, env.mapEnv.Map, env.mapVerifier, mapKeyValue, Options{BatchSize: 3})
+	if n, err := m.RunOnce(ctx); n != 0 || err != nil {
+		t.Fatalf("RunOnce(empty log): (%v, %v), want (0, nil)", n, err)
+	}
+
+	env.addLeaves(ctx, t, "a=1", "b=1", "a=2", "c=1", "b=2")
+	for _, want := range []int{3, 2, 0} {
+		if n, err := m.RunOnce(ctx); n != want || err != nil {
+			t.Fatalf("RunOnce(): (%v, %v), want (%v, nil)", n, err, want)
+		}
+	}
+	env.checkValues(ctx, t, map[string]string{"a": "2", "b": "2", "c": "1", "d": ""})
+
+	checkpoint, err := m.Checkpoint(ctx)
+	if err != nil {
+		t.Fatalf("Checkpoint(): %v", err)
+	}
+	if got, want := checkpoint.NextLogIndex, int64(5); got != want {
+		t.Errorf("Checkpoint().NextLogIndex: %v, want %v", got, want)
+	}
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(checkpoint.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(Checkpoint().LogRoot): %v", err)
+	}
+	if got, want := logRoot.TreeSize, uint64(5); got != want {
+		t.Errorf("Checkpoint().LogRoot.TreeSize: %v, want %v", got, want)
+	}
+
+	// A new Mapper resumes from the checkpoint of the last one.
+	env.addLeaves(ctx, t, "d=1", "a=3")
+	m = New(env.log, env.mapEnv.Map, env.mapVerifier, mapKeyValue, Options{})
+	for _, want := range []int{2, 0} {
+		if n, err := m.RunOnce(ctx); n != want || err != nil {
+			t.Fatalf("RunOnce(resumed): (%v, %v), want (%v, nil)", n, err, want)
+		}
+	}
+	env.checkValues(ctx, t, map[string]string{"a": "3", "b": "2", "c": "1", "d": "1"})
+}
+
+func TestMapperReduce(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+
+	env.addLeaves(ctx, t, "a=1", "b=1", "a=2", "c=1", "b=2")
+	m := New(env.log, env.mapEnv.Map, env.mapVerifier, mapKeyValue, Options{BatchSize: 2, Reduce: concat})
+	for _, want := range []int{2, 2, 1, 0} {
+		if n, err := m.RunOnce(ctx); n != want || err != nil {
+			t.Fatalf("RunOnce(): (%v, %v), want (%v, nil)", n, err, want)
+		}
+	}
+	env.checkValues(ctx, t, map[string]string{"a": "12", "b": "12", "c": "1"})
+}
+
+func TestMapperErrors(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+
+	env.addLeaves(ctx, t, "a=1", "malformed", "b=1")
+	m := New(env.log, env.mapEnv.Map, env.mapVerifier, mapKeyValue, Options{})
+	if _, err := m.RunOnce(ctx); err == nil {
+		t.Fatal("RunOnce(malformed leaf): succeeded, want error")
+	}
+	// Nothing was written, so the mapper retries from the start.
+	checkpoint, err := m.Checkpoint(ctx)
+	if err != nil {
+		t.Fatalf("Checkpoint(): %v", err)
+	}
+	if got, want := checkpoint.NextLogIndex, int64(0); got != want {
+		t.Errorf("Checkpoint().NextLogIndex: %v, want %v", got, want)
+	}
+
+	// A map which was written by something else can't be resumed from.
+	if _, err := env.mapEnv.Map.SetLeaves(ctx, &trillian.SetMapLeavesRequest{
+		MapId:    env.mapVerifier.MapID,
+		Metadata: []byte("not a checkpoint"),
+	}); err != nil {
+		t.Fatalf("SetLeaves(): %v", err)
+	}
+	if _, err := m.RunOnce(ctx); err == nil {
+		t.Fatal("RunOnce(bad checkpoint): succeeded, want error")
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+)
+
+// A batch map inclusion proof proves the values of a set of leaves of a
+// sparse Merkle tree at once. It holds the nodes needed to compute the root
+// from the leaves which aren't themselves computable from the leaves, so a
+// node shared by the inclusion proofs of several leaves is only included once.
+//
+// The nodes are ordered as they are needed by a depth first, left to right
+// traversal of the union of the paths from the root to each leaf: whenever a
+// node on those paths has a child which is not on any of them, the next node
+// of the proof is the hash of that child. As in single leaf proofs, empty
+// nodes are represented by empty values.
+
+// CompressMapInclusionProofs combines the inclusion proofs for a set of
+// distinct indexes into a batch map inclusion proof. proofs[i] must be the
+// inclusion proof for indexes[i], as returned by
+// SparseMerkleTreeReader.InclusionProof.
+func CompressMapInclusionProofs(indexes [][]byte, proofs [][][]byte, h hashers.MapHasher) ([][]byte, error) {
+	if got, want := len(proofs), len(indexes); got != want {
+		return nil, fmt.Errorf("got %d proofs for %d indexes", got, want)
+	}
+	for i, proof := range proofs {
+		if got, want := len(proof), h.BitLen(); got != want {
+			return nil, fmt.Errorf("proof[%d] len: %d, want %d", i, got, want)
+		}
+	}
+	order, err := sortIndexes(indexes, h)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := make([][]byte, 0)
+	var walk func(depth int, leaves []int)
+	walk = func(depth int, leaves []int) {
+		if depth == h.BitLen() {
+			return
+		}
+		// The height of the children of this node.
+		height := h.BitLen() - depth - 1
+		split := splitIndexes(indexes, leaves, depth)
+		left, right := leaves[:split], leaves[split:]
+		if len(left) == 0 {
+			batch = append(batch, proofs[right[0]][height])
+		} else {
+			walk(depth+1, left)
+		}
+		if len(right) == 0 {
+			batch = append(batch, proofs[left[0]][height])
+		} else {
+			walk(depth+1, right)
+		}
+	}
+	if len(order) > 0 {
+		walk(0, order)
+	}
+	return batch, nil
+}
+
+// VerifyMapBatchInclusionProof verifies that the passed in expectedRoot can be
+// reconstructed from the leaf values at a set of distinct indexes, and a batch
+// map inclusion proof for them. leaves[i] is the value at indexes[i]; empty
+// values are treated as absent from the map, as in VerifyMapInclusionProof.
+//
+// Returns nil on a successful verification, and an error otherwise.
+func VerifyMapBatchInclusionProof(treeID int64, indexes, leaves [][]byte, expectedRoot []byte, proof [][]byte, h hashers.MapHasher) error {
+	if got, want := len(leaves), len(indexes); got != want {
+		return fmt.Errorf("got %d leaves for %d indexes", got, want)
+	}
+	for i, element := range proof {
+		if got, wanta, wantb := len(element), 0, h.Size(); got != wanta && got != wantb {
+			return fmt.Errorf("proof[%d] len: %d, want %d or %d", i, got, wanta, wantb)
+		}
+	}
+	order, err := sortIndexes(indexes, h)
+	if err != nil {
+		return err
+	}
+	if len(order) == 0 {
+		return fmt.Errorf("no leaves to verify")
+	}
+
+	next := 0
+	// walk returns the hash of the node at depth containing the given leaves,
+	// or nil if the node is empty.
+	var walk func(depth int, sub []int) ([]byte, error)
+	walk = func(depth int, sub []int) ([]byte, error) {
+		if depth == h.BitLen() {
+			i := sub[0]
+			if len(leaves[i]) == 0 {
+				return nil, nil
+			}
+			return h.HashLeaf(treeID, indexes[i], leaves[i])
+		}
+
+		// The height of the children of this node.
+		height := h.BitLen() - depth - 1
+		split := splitIndexes(indexes, sub, depth)
+		var children [2][]byte
+		for side, sideLeaves := range [][]int{sub[:split], sub[split:]} {
+			if len(sideLeaves) == 0 {
+				if next >= len(proof) {
+					return nil, fmt.Errorf("proof too short: %d nodes", len(proof))
+				}
+				children[side] = proof[next]
+				next++
+				continue
+			}
+			hash, err := walk(depth+1, sideLeaves)
+			if err != nil {
+				return nil, err
+			}
+			children[side] = hash
+		}
+
+		// As in VerifyMapInclusionProof, a node with two empty children is
+		// empty, and HashEmpty is only used for the empty child of a node with
+		// a non-empty one.
+		if len(children[0]) == 0 && len(children[1]) == 0 {
+			return nil, nil
+		}
+		for side := range children {
+			if len(children[side]) == 0 {
+				nID := storage.NewNodeIDFromHash(indexes[sub[0]])
+				nID.MaskLeft(depth + 1)
+				if uint(side) != indexBit(indexes[sub[0]], depth) {
+					nID.Neighbor()
+				}
+				children[side] = h.HashEmpty(treeID, nID.Path, height)
+			}
+		}
+		return h.HashChildren(children[0], children[1]), nil
+	}
+
+	root, err := walk(0, order)
+	if err != nil {
+		return err
+	}
+	if next != len(proof) {
+		return fmt.Errorf("proof too long: %d nodes, used %d", len(proof), next)
+	}
+	if len(root) == 0 {
+		nID := storage.NewNodeIDFromHash(indexes[0])
+		root = h.HashEmpty(treeID, nID.MaskLeft(0).Path, h.BitLen())
+	}
+
+	if got, want := root, expectedRoot; !bytes.Equal(got, want) {
+		return fmt.Errorf("calculated root: %x, want \n%x", got, want)
+	}
+	return nil
+}
+
+// sortIndexes checks the sizes of indexes, and returns the positions of
+// indexes in ascending index order. Returns an error if there are duplicate
+// indexes.
+func sortIndexes(indexes [][]byte, h hashers.MapHasher) ([]int, error) {
+	order := make([]int, len(indexes))
+	for i, index := range indexes {
+		if got, want := len(index)*8, h.BitLen(); got != want {
+			return nil, fmt.Errorf("index[%d] len: %d, want %d", i, got, want)
+		}
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return bytes.Compare(indexes[order[a]], indexes[order[b]]) < 0
+	})
+	for i := 1; i < len(order); i++ {
+		if bytes.Equal(indexes[order[i-1]], indexes[order[i]]) {
+			return nil, fmt.Errorf("duplicate index %x", indexes[order[i]])
+		}
+	}
+	return order, nil
+}
+
+// splitIndexes returns the position in leaves, which is sorted by index and
+// whose indexes share their first depth bits, of the first index whose bit at
+// depth is set.
+func splitIndexes(indexes [][]byte, leaves []int, depth int) int {
+	return sort.Search(len(leaves), func(i int) bool {
+		return indexBit(indexes[leaves[i]], depth) == 1
+	})
+}
+
+// indexBit returns the bit of index at depth, counting from the most
+// significant bit.
+func indexBit(index []byte, depth int) uint {
+	return uint(index[depth/8]>>uint(7-depth%8)) & 1
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/trillian/merkle/coniks"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/maphasher"
+	"github.com/google/trillian/testonly"
+)
+
+func TestMapBatchInclusionProof(t *testing.T) {
+	for _, h := range []hashers.MapHasher{maphasher.Default, coniks.Default} {
+		leaves := make(map[string][]byte)
+		for i := 0; i < 20; i++ {
+			leaves[string(testonly.HashKey(fmt.Sprintf("key-%d", i)))] = []byte(fmt.Sprintf("value-%d", i))
+		}
+		root, inclusionProof := buildSparseTree(t, treeID, h, leaves)
+		emptyRoot, emptyInclusionProof := buildSparseTree(t, treeID, h, nil)
+
+		for _, test := range []struct {
+			desc string
+			keys []string
+		}{
+			{desc: "one present", keys: []string{"key-3"}},
+			{desc: "one absent", keys: []string{"absent-1"}},
+			{desc: "several present", keys: []string{"key-7", "key-1", "key-12", "key-19"}},
+			{desc: "all present", keys: []string{
+				"key-0", "key-1", "key-2", "key-3", "key-4", "key-5", "key-6", "key-7", "key-8", "key-9",
+				"key-10", "key-11", "key-12", "key-13", "key-14", "key-15", "key-16", "key-17", "key-18", "key-19",
+			}},
+			{desc: "present and absent", keys: []string{"absent-1", "key-5", "absent-2", "key-6"}},
+		} {
+			t.Run(fmt.Sprintf("%T/%v", h, test.desc), func(t *testing.T) {
+				var indexes, values [][]byte
+				var proofList [][][]byte
+				size := 0
+				for _, key := range test.keys {
+					index := testonly.HashKey(key)
+					proof := inclusionProof(index)
+					indexes = append(indexes, index)
+					values = append(values, leaves[string(index)])
+					proofList = append(proofList, proof)
+					size += len(proof)
+				}
+
+				batch, err := CompressMapInclusionProofs(indexes, proofList, h)
+				if err != nil {
+					t.Fatalf("CompressMapInclusionProofs(): %v", err)
+				}
+				if len(indexes) > 1 && len(batch) >= size {
+					t.Errorf("CompressMapInclusionProofs(): %d nodes, want fewer than %d", len(batch), size)
+				}
+				if err := VerifyMapBatchInclusionProof(treeID, indexes, values, root, batch, h); err != nil {
+					t.Errorf("VerifyMapBatchInclusionProof(): %v", err)
+				}
+
+				// All the leaves are proved absent from an empty map.
+				var emptyProofs [][][]byte
+				for _, index := range indexes {
+					emptyProofs = append(emptyProofs, emptyInclusionProof(index))
+				}
+				emptyBatch, err := CompressMapInclusionProofs(indexes, emptyProofs, h)
+				if err != nil {
+					t.Fatalf("CompressMapInclusionProofs(empty): %v", err)
+				}
+				if err := VerifyMapBatchInclusionProof(treeID, indexes, make([][]byte, len(indexes)), emptyRoot, emptyBatch, h); err != nil {
+					t.Errorf("VerifyMapBatchInclusionProof(empty): %v", err)
+				}
+
+				// Every non-empty node of the batch proof is needed.
+				for i := range batch {
+					if len(batch[i]) == 0 {
+						continue
+					}
+					bad := append([][]byte(nil), batch...)
+					bad[i] = nil
+					if err := VerifyMapBatchInclusionProof(treeID, indexes, values, root, bad, h); err == nil {
+						t.Errorf("VerifyMapBatchInclusionProof(): succeeded with node %d modified", i)
+					}
+				}
+				if err := VerifyMapBatchInclusionProof(treeID, indexes, values, root, batch[1:], h); err == nil {
+					t.Errorf("VerifyMapBatchInclusionProof(): succeeded with a short proof")
+				}
+				if err := VerifyMapBatchInclusionProof(treeID, indexes, values, root, append(batch, nil), h); err == nil {
+					t.Errorf("VerifyMapBatchInclusionProof(): succeeded with a long proof")
+				}
+
+				// Each leaf's value is proved.
+				for i := range values {
+					bad := append([][]byte(nil), values...)
+					bad[i] = []byte("wrong")
+					if err := VerifyMapBatchInclusionProof(treeID, indexes, bad, root, batch, h); err == nil {
+						t.Errorf("VerifyMapBatchInclusionProof(): succeeded with wrong value for %s", test.keys[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestMapBatchInclusionProofErrors(t *testing.T) {
+	h := maphasher.Default
+	index := testonly.HashKey("key")
+	proof := make([][]byte, h.BitLen())
+	root, _ := buildSparseTree(t, treeID, h, nil)
+
+	for _, test := range []struct {
+		desc       string
+		indexes    [][]byte
+		proofs     [][][]byte
+		wantVerify bool
+	}{
+		{desc: "duplicate index", indexes: [][]byte{index, index}, proofs: [][][]byte{proof, proof}},
+		{desc: "short index", indexes: [][]byte{index[1:]}, proofs: [][][]byte{proof}},
+		{desc: "short proof", indexes: [][]byte{index}, proofs: [][][]byte{proof[1:]}, wantVerify: true},
+		{desc: "missing proof", indexes: [][]byte{index}, wantVerify: true},
+	} {
+		if _, err := CompressMapInclusionProofs(test.indexes, test.proofs, h); err == nil {
+			t.Errorf("%v: CompressMapInclusionProofs(): succeeded, want error", test.desc)
+		}
+		// The batch proof of a single index is its inclusion proof.
+		values := make([][]byte, len(test.indexes))
+		if err := VerifyMapBatchInclusionProof(treeID, test.indexes, values, root, proof, h); (err == nil) != test.wantVerify {
+			t.Errorf("%v: VerifyMapBatchInclusionProof(): %v, want success: %v", test.desc, err, test.wantVerify)
+		}
+	}
+
+	if err := VerifyMapBatchInclusionProof(treeID, nil, nil, root, nil, h); err == nil {
+		t.Errorf("VerifyMapBatchInclusionProof(no leaves): succeeded, want error")
+	}
+}
@@ -27,6 +27,8 @@ import (
 //
 // The process is essentially the same as the inclusion proof checking for
 // append-only logs, but adds support for nil/"default" proof nodes.
+// An empty leaf is treated as absent from the map, see
+// VerifyMapNonInclusionProof.
 //
 // Returns nil on a successful verification, and an error otherwise.
 func VerifyMapInclusionProof(treeID int64, index, leaf, expectedRoot []byte, proof [][]byte, h hashers.MapHasher) error {
@@ -92,3 +94,12 @@ func VerifyMapInclusionProof(treeID int64, index, leaf, expectedRoot []byte, pro
 	}
 	return nil
 }
+
+// VerifyMapNonInclusionProof verifies that there is no value at index in the
+// map with the passed in expectedRoot, i.e. that the root can be reconstructed
+// from proof with an empty leaf at index.
+//
+// Returns nil on a successful verification, and an error otherwise.
+func VerifyMapNonInclusionProof(treeID int64, index, expectedRoot []byte, proof [][]byte, h hashers.MapHasher) error {
+	return VerifyMapInclusionProof(treeID, index, nil, expectedRoot, proof, h)
+}
@@ -15,9 +15,12 @@
 package merkle
 
 import (
+	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/google/trillian/merkle/coniks"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/merkle/maphasher"
 	"github.com/google/trillian/testonly"
 )
@@ -149,3 +152,98 @@ func TestMapHasherTestVectors(t *testing.T) {
 		}
 	}
 }
+
+// buildSparseTree returns the root of the sparse Merkle tree holding leaves,
+// which maps indexes to values, and a function returning inclusion proofs
+// from that tree.
+func buildSparseTree(t *testing.T, treeID int64, h hashers.MapHasher, leaves map[string][]byte) ([]byte, func(index []byte) [][]byte) {
+	t.Helper()
+	nodes := make(map[string][]byte)
+	key := func(depth int, index *big.Int) string {
+		return fmt.Sprintf("%d/%x", depth, index)
+	}
+
+	var values []HStar2LeafHash
+	for index, value := range leaves {
+		leafHash, err := h.HashLeaf(treeID, []byte(index), value)
+		if err != nil {
+			t.Fatalf("HashLeaf(%x): %v", index, err)
+		}
+		i := new(big.Int).SetBytes([]byte(index))
+		nodes[key(h.BitLen(), i)] = leafHash
+		values = append(values, HStar2LeafHash{Index: i, LeafHash: leafHash})
+	}
+	hs := NewHStar2(treeID, h)
+	root, err := hs.HStar2Nodes(nil, h.BitLen(), values, nil, func(depth int, index *big.Int, hash []byte) error {
+		nodes[key(depth, index)] = hash
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HStar2Nodes(): %v", err)
+	}
+
+	proof := func(index []byte) [][]byte {
+		i := new(big.Int).SetBytes(index)
+		p := make([][]byte, h.BitLen())
+		for height := range p {
+			// Empty siblings aren't stored, and are left nil in the proof.
+			sib := new(big.Int).Rsh(i, uint(height))
+			sib.SetBit(sib, 0, sib.Bit(0)^1)
+			sib.Lsh(sib, uint(height))
+			p[height] = nodes[key(h.BitLen()-height, sib)]
+		}
+		return p
+	}
+	return root, proof
+}
+
+func TestVerifyMapNonInclusionProof(t *testing.T) {
+	const treeID = 42
+	present := [][]byte{testonly.HashKey("key-a"), testonly.HashKey("key-b"), testonly.HashKey("key-c")}
+	absent := testonly.HashKey("key-d")
+
+	for _, hc := range []struct {
+		name string
+		h    hashers.MapHasher
+	}{
+		{name: "maphasher", h: maphasher.Default},
+		{name: "coniks", h: coniks.Default},
+	} {
+		h := hc.h
+		t.Run(hc.name, func(t *testing.T) {
+			emptyRoot, emptyProof := buildSparseTree(t, treeID, h, nil)
+			leaves := make(map[string][]byte)
+			for i, index := range present {
+				leaves[string(index)] = []byte(fmt.Sprintf("value-%d", i))
+			}
+			root, proof := buildSparseTree(t, treeID, h, leaves)
+
+			for _, tc := range []struct {
+				desc  string
+				index []byte
+				root  []byte
+				proof [][]byte
+				want  bool
+			}{
+				{desc: "empty map", index: absent, root: emptyRoot, proof: emptyProof(absent), want: true},
+				{desc: "absent", index: absent, root: root, proof: proof(absent), want: true},
+				{desc: "present", index: present[1], root: root, proof: proof(present[1])},
+				{desc: "wrong root", index: absent, root: emptyRoot, proof: proof(absent)},
+				{desc: "wrong proof", index: absent, root: root, proof: proof(present[0])},
+				{desc: "short proof", index: absent, root: root, proof: proof(absent)[1:]},
+			} {
+				err := VerifyMapNonInclusionProof(treeID, tc.index, tc.root, tc.proof, h)
+				if got := err == nil; got != tc.want {
+					t.Errorf("%v: VerifyMapNonInclusionProof(): %v, want success: %v", tc.desc, err, tc.want)
+				}
+			}
+
+			// The same proofs show the present leaves are included.
+			for index, value := range leaves {
+				if err := VerifyMapInclusionProof(treeID, []byte(index), value, root, proof([]byte(index)), h); err != nil {
+					t.Errorf("VerifyMapInclusionProof(%x): %v", index, err)
+				}
+			}
+		})
+	}
+}
@@ -100,6 +100,36 @@ func CalcConsistencyProofNodeAddresses(snapshot1, snapshot2, treeSize int64, max
 	return snapshotConsistency(snapshot1, snapshot2, treeSize, maxBitLen)
 }
 
+// CalcCompactTreeNodeAddresses returns the tree node IDs needed to resume a
+// CompactMerkleTree of the specified size, in the order that
+// NewCompactMerkleTreeWithState requests them. No nodes are needed for empty
+// or perfect trees, because their state is just the root hash. The maxBitLen
+// parameter is copied into all the returned nodeIDs.
+func CalcCompactTreeNodeAddresses(treeSize int64, maxBitLen int) ([]NodeFetch, error) {
+	if treeSize < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid parameter for compact tree: treeSize %d < 0", treeSize)
+	}
+	if maxBitLen <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid parameter for compact tree: maxBitLen %d <= 0", maxBitLen)
+	}
+	if treeSize == 0 || isPerfectTree(treeSize) {
+		return nil, nil
+	}
+
+	var fetches []NodeFetch
+	for depth, size := 0, treeSize; size > 0; depth, size = depth+1, size>>1 {
+		if size&1 == 0 {
+			continue
+		}
+		nodeID, err := storage.NewNodeIDForTreeCoords(int64(depth), size-1, maxBitLen)
+		if err != nil {
+			return nil, err
+		}
+		fetches = append(fetches, NodeFetch{NodeID: nodeID})
+	}
+	return fetches, nil
+}
+
 // snapshotConsistency does the calculation of consistency proof node addresses between
 // two snapshots. Based on the C++ code used by CT but adjusted to fit our situation.
 func snapshotConsistency(snapshot1, snapshot2, treeSize int64, maxBitLen int) ([]NodeFetch, error) {
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/google/trillian/merkle/rfc6962"
 	"github.com/google/trillian/storage"
 )
 
@@ -301,6 +302,36 @@ func TestCalcConsistencyProofNodeAddressesRejectsBadBitLen(t *testing.T) {
 	}
 }
 
+func TestCalcCompactTreeNodeAddresses(t *testing.T) {
+	for size := int64(0); size <= 70; size++ {
+		// The nodes requested when resuming a compact tree are the ones needed.
+		var expected []NodeFetch
+		NewCompactMerkleTreeWithState(rfc6962.DefaultHasher, size, func(depth int, index int64) ([]byte, error) {
+			nodeID, err := storage.NewNodeIDForTreeCoords(int64(depth), index, 64)
+			if err != nil {
+				t.Fatalf("NewNodeIDForTreeCoords(%d, %d): %v", depth, index, err)
+			}
+			expected = append(expected, NodeFetch{NodeID: nodeID})
+			return []byte("hash"), nil
+		}, []byte("root"))
+
+		got, err := CalcCompactTreeNodeAddresses(size, 64)
+		if err != nil {
+			t.Fatalf("CalcCompactTreeNodeAddresses(%d): %v", size, err)
+		}
+		comparePaths(t, fmt.Sprintf("compact tree of size %d", size), got, expected)
+	}
+}
+
+func TestCalcCompactTreeNodeAddressesBadInputs(t *testing.T) {
+	if _, err := CalcCompactTreeNodeAddresses(-1, 64); err == nil {
+		t.Error("compact tree calculation accepted negative tree size")
+	}
+	if _, err := CalcCompactTreeNodeAddresses(7, 0); err == nil {
+		t.Error("compact tree calculation accepted bad bitlen")
+	}
+}
+
 func comparePaths(t *testing.T, desc string, got, expected []NodeFetch) {
 	if len(expected) != len(got) {
 		t.Fatalf("%s: expected %d nodes in path but got %d: %v", desc, len(expected), len(got), got)
@@ -18,10 +18,13 @@ import (
 	"bytes"
 	"crypto"
 	"encoding/hex"
+	"math/big"
 	"testing"
 
+	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/merkle/maphasher"
 	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/testonly"
 )
 
 const treeID = int64(0)
@@ -79,3 +82,52 @@ func TestHashChildren(t *testing.T) {
 		}
 	}
 }
+
+func TestMapNonInclusionProof(t *testing.T) {
+	h := NewMapHasher(maphasher.New(crypto.SHA256))
+	hs := merkle.NewHStar2(treeID, h)
+
+	// present and absent are siblings, so each proof holds just the other's
+	// leaf hash, or nothing if it's empty.
+	present := testonly.HashKey("key")
+	absent := append([]byte(nil), present...)
+	absent[len(absent)-1] ^= 1
+	value := []byte(`{"k":"v"}`)
+	leafHash, err := h.HashLeaf(treeID, present, value)
+	if err != nil {
+		t.Fatalf("HashLeaf(): %v", err)
+	}
+	root, err := hs.HStar2Root(h.BitLen(), []merkle.HStar2LeafHash{
+		{Index: new(big.Int).SetBytes(present), LeafHash: leafHash},
+	})
+	if err != nil {
+		t.Fatalf("HStar2Root(): %v", err)
+	}
+	emptyRoot, err := hs.HStar2Root(h.BitLen(), nil)
+	if err != nil {
+		t.Fatalf("HStar2Root(empty): %v", err)
+	}
+	absentProof := make([][]byte, h.BitLen())
+	absentProof[0] = leafHash
+
+	for _, tc := range []struct {
+		desc  string
+		index []byte
+		root  []byte
+		proof [][]byte
+		want  bool
+	}{
+		{desc: "empty map", index: absent, root: emptyRoot, proof: make([][]byte, h.BitLen()), want: true},
+		{desc: "absent", index: absent, root: root, proof: absentProof, want: true},
+		{desc: "present", index: present, root: root, proof: make([][]byte, h.BitLen())},
+		{desc: "wrong root", index: absent, root: emptyRoot, proof: absentProof},
+	} {
+		err := merkle.VerifyMapNonInclusionProof(treeID, tc.index, tc.root, tc.proof, h)
+		if got := err == nil; got != tc.want {
+			t.Errorf("%v: VerifyMapNonInclusionProof(): %v, want success: %v", tc.desc, err, tc.want)
+		}
+	}
+	if err := merkle.VerifyMapInclusionProof(treeID, present, value, root, make([][]byte, h.BitLen()), h); err != nil {
+		t.Errorf("VerifyMapInclusionProof(): %v", err)
+	}
+}
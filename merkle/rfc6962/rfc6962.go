@@ -18,6 +18,7 @@ package rfc6962
 import (
 	"crypto"
 	_ "crypto/sha256" // SHA256 is the default algorithm.
+	_ "crypto/sha512" // For the SHA384 and SHA512 hash strategies.
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/hashers"
@@ -25,6 +26,8 @@ import (
 
 func init() {
 	hashers.RegisterLogHasher(trillian.HashStrategy_RFC6962_SHA256, New(crypto.SHA256))
+	hashers.RegisterLogHasher(trillian.HashStrategy_RFC6962_SHA384, New(crypto.SHA384))
+	hashers.RegisterLogHasher(trillian.HashStrategy_RFC6962_SHA512, New(crypto.SHA512))
 }
 
 // Domain separation prefixes
@@ -18,6 +18,9 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+
 	_ "github.com/golang/glog"
 )
 
@@ -76,6 +79,56 @@ func TestRFC6962Hasher(t *testing.T) {
 	}
 }
 
+func TestRegisteredHashers(t *testing.T) {
+	for _, tc := range []struct {
+		strategy      trillian.HashStrategy
+		wantEmptyRoot string
+		wantLeafHash  string
+	}{
+		// echo -n | sha256sum
+		// echo -n 004C313233343536 | xxd -r -p | sha256sum
+		{
+			strategy:      trillian.HashStrategy_RFC6962_SHA256,
+			wantEmptyRoot: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			wantLeafHash:  "395aa064aa4c29f7010acfe3f25db9485bbd4b91897b6ad7ad547639252b4d56",
+		},
+		// echo -n | sha384sum
+		// echo -n 004C313233343536 | xxd -r -p | sha384sum
+		{
+			strategy:      trillian.HashStrategy_RFC6962_SHA384,
+			wantEmptyRoot: "38b060a751ac96384cd9327eb1b1e36a21fdb71114be07434c0cc7bf63f6e1da274edebfe76f65fbd51ad2f14898b95b",
+			wantLeafHash:  "ccb7911ca505cd6a55f5cef873a4e39ad0dcae284062e045232658d0bee5c812f84df991f41909471b24043c4c0a7911",
+		},
+		// echo -n | sha512sum
+		// echo -n 004C313233343536 | xxd -r -p | sha512sum
+		{
+			strategy:      trillian.HashStrategy_RFC6962_SHA512,
+			wantEmptyRoot: "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e",
+			wantLeafHash:  "58bda2e1433d5c4c5bbfcbd2ffb04ea4c5fdb682f805df99b02dcda887f3b173217f8068089cacbc6fc3d19c06ad8ca49ae9406c675610c9056e8c4091c87385",
+		},
+	} {
+		t.Run(tc.strategy.String(), func(t *testing.T) {
+			hasher, err := hashers.NewLogHasher(tc.strategy)
+			if err != nil {
+				t.Fatalf("NewLogHasher(%v): %v", tc.strategy, err)
+			}
+			if got, want := hex.EncodeToString(hasher.EmptyRoot()), tc.wantEmptyRoot; got != want {
+				t.Errorf("EmptyRoot(): %v, want %v", got, want)
+			}
+			leafHash, err := hasher.HashLeaf([]byte("L123456"))
+			if err != nil {
+				t.Fatalf("HashLeaf(): %v", err)
+			}
+			if got, want := hex.EncodeToString(leafHash), tc.wantLeafHash; got != want {
+				t.Errorf("HashLeaf(): %v, want %v", got, want)
+			}
+			if got, want := hasher.Size(), len(leafHash); got != want {
+				t.Errorf("Size(): %v, want %v", got, want)
+			}
+		})
+	}
+}
+
 // TODO(pavelkalinnikov): Apply this test to all LogHasher implementations.
 func TestRFC6962HasherCollisions(t *testing.T) {
 	hasher := DefaultHasher
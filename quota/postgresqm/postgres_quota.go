@@ -0,0 +1,138 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postgresqm defines a PostgreSQL-based quota.Manager implementation.
+package postgresqm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/trillian/quota"
+)
+
+const (
+	// DefaultMaxUnsequenced is a suggested value for MaxUnsequencedRows.
+	// Note that this is a Global/Write quota suggestion, so it applies across trees.
+	DefaultMaxUnsequenced = 500000 // About 2h of non-stop signing at 70QPS.
+
+	// Unquoted identifiers are folded to lower case by PostgreSQL, so that's
+	// how the Unsequenced table is named in the statistics views.
+	countFromStatisticsQuery = `
+		SELECT n_live_tup
+		FROM pg_stat_user_tables
+		WHERE schemaname = current_schema()
+			AND relname = $1`
+	countFromUnsequencedQuery = "SELECT COUNT(*) FROM Unsequenced"
+)
+
+var (
+	// ErrTooManyUnsequencedRows is returned when tokens are requested but Unsequenced has grown
+	// beyond the configured limit.
+	ErrTooManyUnsequencedRows = errors.New("too many unsequenced rows")
+)
+
+// QuotaManager is a PostgreSQL-based quota.Manager implementation.
+//
+// It has two working modes: one queries the statistics collector for the number of Unsequenced
+// rows, the other does a select count(*) on the Unsequenced table. Statistics queries are
+// default, even though they are approximate and lag behind recent writes, as they're constant
+// time (select count(*) on PostgreSQL needs to scan the table and may take quite a while to
+// complete).
+//
+// QuotaManager only implements Global/Write quotas, which is based on the number of Unsequenced
+// rows (to be exact, tokens = MaxUnsequencedRows - actualUnsequencedRows).
+// Other quotas are considered infinite.
+type QuotaManager struct {
+	DB                 *sql.DB
+	MaxUnsequencedRows int
+	UseSelectCount     bool
+}
+
+// GetTokens implements quota.Manager.GetTokens.
+// It doesn't actually reserve or retrieve tokens, instead it allows access based on the number of
+// rows in the Unsequenced table.
+func (m *QuotaManager) GetTokens(ctx context.Context, numTokens int, specs []quota.Spec) error {
+	for _, spec := range specs {
+		if spec.Group != quota.Global || spec.Kind != quota.Write {
+			continue
+		}
+		// Only allow global writes if Unsequenced is under the expected limit
+		count, err := m.countUnsequenced(ctx)
+		if err != nil {
+			return err
+		}
+		if count+numTokens > m.MaxUnsequencedRows {
+			return ErrTooManyUnsequencedRows
+		}
+	}
+	return nil
+}
+
+// PeekTokens implements quota.Manager.PeekTokens.
+// Global/Write tokens reflect the number of rows in the Unsequenced tables, other specs are
+// considered infinite.
+func (m *QuotaManager) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+	tokens := make(map[quota.Spec]int)
+	for _, spec := range specs {
+		var num int
+		if spec.Group == quota.Global && spec.Kind == quota.Write {
+			count, err := m.countUnsequenced(ctx)
+			if err != nil {
+				return nil, err
+			}
+			num = m.MaxUnsequencedRows - count
+		} else {
+			num = quota.MaxTokens
+		}
+		tokens[spec] = num
+	}
+	return tokens, nil
+}
+
+// PutTokens implements quota.Manager.PutTokens.
+// It's a noop for QuotaManager.
+func (m *QuotaManager) PutTokens(ctx context.Context, numTokens int, specs []quota.Spec) error {
+	return nil
+}
+
+// ResetQuota implements quota.Manager.ResetQuota.
+// It's a noop for QuotaManager.
+func (m *QuotaManager) ResetQuota(ctx context.Context, specs []quota.Spec) error {
+	return nil
+}
+
+func (m *QuotaManager) countUnsequenced(ctx context.Context) (int, error) {
+	if m.UseSelectCount {
+		return countFromTable(ctx, m.DB)
+	}
+	return countFromStatistics(ctx, m.DB)
+}
+
+func countFromStatistics(ctx context.Context, db *sql.DB) (int, error) {
+	var count int
+	if err := db.QueryRowContext(ctx, countFromStatisticsQuery, "unsequenced").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func countFromTable(ctx context.Context, db *sql.DB) (int, error) {
+	var count int
+	if err := db.QueryRowContext(ctx, countFromUnsequencedQuery).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -18,15 +18,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/server/interceptor"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -34,10 +38,15 @@ import (
 	_ "github.com/google/trillian/merkle/rfc6962" // Make hashers available
 )
 
+// getTreeMethod is the RPC a caller must be authorized to make on a tree for
+// it to be returned by ListTrees.
+const getTreeMethod = "/trillian.TrillianAdmin/GetTree"
+
 // Server is an implementation of trillian.TrillianAdminServer.
 type Server struct {
 	registry         extension.Registry
 	allowedTreeTypes []trillian.TreeType
+	authz            interceptor.Authorizer
 }
 
 // New returns a trillian.TrillianAdminServer implementation.
@@ -51,6 +60,12 @@ func New(registry extension.Registry, allowedTreeTypes []trillian.TreeType) *Ser
 	}
 }
 
+// SetAuthorizer makes ListTrees return only the trees authz allows callers to
+// get. If no Authorizer is set all trees are returned.
+func (s *Server) SetAuthorizer(authz interceptor.Authorizer) {
+	s.authz = authz
+}
+
 // IsHealthy returns nil if the server is healthy, error otherwise.
 // TODO(Martin2112): This method (and the one in the log server) should probably have ctx as a param
 func (s *Server) IsHealthy() error {
@@ -59,15 +74,61 @@ func (s *Server) IsHealthy() error {
 
 // ListTrees implements trillian.TrillianAdminServer.ListTrees.
 func (s *Server) ListTrees(ctx context.Context, req *trillian.ListTreesRequest) (*trillian.ListTreesResponse, error) {
-	// TODO(codingllama): This needs access control
-	resp, err := storage.ListTrees(ctx, s.registry.AdminStorage, req.GetShowDeleted())
-	if err != nil {
-		return nil, err
+	if req.GetPageSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must not be negative: %v", req.GetPageSize())
+	}
+	opts := storage.ListTreesOptions{
+		IncludeDeleted: req.GetShowDeleted(),
+		Labels:         req.GetLabelSelector(),
+		PageSize:       int(req.GetPageSize()),
+	}
+	if token := req.GetPageToken(); token != "" {
+		after, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || after <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page_token: %q", token)
+		}
+		opts.AfterTreeID = after
+	}
+
+	var trees []*trillian.Tree
+	for {
+		page, err := storage.ListTrees(ctx, s.registry.AdminStorage, opts)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, s.authorizedTrees(ctx, page)...)
+		// Pages filtered down by the Authorizer are topped up from the next
+		// storage page, so that next_page_token is always a tree the caller
+		// may get.
+		if opts.PageSize == 0 || len(page) < opts.PageSize || len(trees) >= opts.PageSize {
+			break
+		}
+		opts.AfterTreeID = page[len(page)-1].TreeId
+	}
+	resp := &trillian.ListTreesResponse{Tree: trees}
+	if opts.PageSize > 0 && len(trees) >= opts.PageSize {
+		resp.Tree = trees[:opts.PageSize]
+		resp.NextPageToken = strconv.FormatInt(resp.Tree[opts.PageSize-1].TreeId, 10)
 	}
-	for _, tree := range resp {
+	for _, tree := range resp.Tree {
 		redact(tree)
 	}
-	return &trillian.ListTreesResponse{Tree: resp}, nil
+	return resp, nil
+}
+
+// authorizedTrees returns the trees the caller may get, or all trees if no
+// Authorizer is set.
+func (s *Server) authorizedTrees(ctx context.Context, trees []*trillian.Tree) []*trillian.Tree {
+	if s.authz == nil {
+		return trees
+	}
+	allowed := trees[:0]
+	for _, tree := range trees {
+		if err := s.authz.Authorize(ctx, getTreeMethod, tree.GetTreeId()); err == nil {
+			allowed = append(allowed, tree)
+		}
+	}
+	return allowed
 }
 
 // GetTree implements trillian.TrillianAdminServer.GetTree.
@@ -188,6 +249,13 @@ func (s *Server) UpdateTree(ctx context.Context, req *trillian.UpdateTreeRequest
 	if err := applyUpdateMask(&trillian.Tree{}, &trillian.Tree{}, mask); err != nil {
 		return nil, err
 	}
+	for _, path := range mask.Paths {
+		if path == "key_history" {
+			if err := s.prepareSuccessorKeys(ctx, tree); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	updatedTree, err := storage.UpdateTree(ctx, s.registry.AdminStorage, tree.TreeId, func(other *trillian.Tree) {
 		if err := applyUpdateMask(tree, other, mask); err != nil {
@@ -221,6 +289,22 @@ func applyUpdateMask(from, to *trillian.Tree, mask *field_mask.FieldMask) error
 			to.MaxRootDuration = from.MaxRootDuration
 		case "private_key":
 			to.PrivateKey = from.PrivateKey
+		case "labels":
+			to.Labels = from.Labels
+		case "key_history":
+			to.KeyHistory = keyHistoryUpdate(from.KeyHistory, to.KeyHistory)
+		case "sequencer_config":
+			to.SequencerConfig = from.SequencerConfig
+		case "sequencer_config.paused":
+			sequencerConfig(to).Paused = from.GetSequencerConfig().GetPaused()
+		case "sequencer_config.batch_size":
+			sequencerConfig(to).BatchSize = from.GetSequencerConfig().GetBatchSize()
+		case "sequencer_config.guard_window":
+			sequencerConfig(to).GuardWindow = from.GetSequencerConfig().GetGuardWindow()
+		case "sequencer_config.max_root_duration":
+			sequencerConfig(to).MaxRootDuration = from.GetSequencerConfig().GetMaxRootDuration()
+		case "sequencer_config.priority":
+			sequencerConfig(to).Priority = from.GetSequencerConfig().GetPriority()
 		default:
 			return status.Errorf(codes.InvalidArgument, "invalid update_mask path: %q", path)
 		}
@@ -228,6 +312,15 @@ func applyUpdateMask(from, to *trillian.Tree, mask *field_mask.FieldMask) error
 	return nil
 }
 
+// sequencerConfig returns the sequencer config of tree, adding an empty one if
+// the tree doesn't have one yet.
+func sequencerConfig(tree *trillian.Tree) *trillian.SequencerConfig {
+	if tree.SequencerConfig == nil {
+		tree.SequencerConfig = &trillian.SequencerConfig{}
+	}
+	return tree.SequencerConfig
+}
+
 // DeleteTree implements trillian.TrillianAdminServer.DeleteTree.
 func (s *Server) DeleteTree(ctx context.Context, req *trillian.DeleteTreeRequest) (*trillian.Tree, error) {
 	tree, err := storage.SoftDeleteTree(ctx, s.registry.AdminStorage, req.GetTreeId())
@@ -246,8 +339,111 @@ func (s *Server) UndeleteTree(ctx context.Context, req *trillian.UndeleteTreeReq
 	return redact(tree), nil
 }
 
+// prepareSuccessorKeys sets up the keys added to the key_history of tree by
+// an update, i.e. those without a key ID: it checks that they can sign the
+// tree's roots from their start_tree_size, derives their public keys and
+// assigns their IDs.
+func (s *Server) prepareSuccessorKeys(ctx context.Context, tree *trillian.Tree) error {
+	storedTree, err := storage.GetTree(ctx, s.registry.AdminStorage, tree.TreeId)
+	if err != nil {
+		return err
+	}
+	logSize := int64(-1)
+	for i, key := range tree.KeyHistory {
+		if key.KeyId != 0 {
+			continue
+		}
+		if key.PrivateKey == nil {
+			return status.Errorf(codes.InvalidArgument, "key_history[%v].private_key is required for new keys", i)
+		}
+		if logSize < 0 {
+			if logSize, err = s.logSize(ctx, storedTree); err != nil {
+				return err
+			}
+		}
+		// Roots which have already been signed by another key can't be
+		// signed by this one.
+		if key.StartTreeSize <= logSize {
+			return status.Errorf(codes.FailedPrecondition, "key_history[%v].start_tree_size must be greater than the log size %v, got %v", i, logSize, key.StartTreeSize)
+		}
+		if key.KeyId, err = storage.NewTreeID(); err != nil {
+			return status.Errorf(codes.Internal, "failed to generate key ID: %v", err)
+		}
+
+		signer, err := trees.KeySigner(ctx, storedTree, key)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to create signer for key_history[%v]: %v", i, err)
+		}
+		publicKey, err := der.ToPublicProto(signer.Public())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to marshal public key: %v", err)
+		}
+		if key.PublicKey != nil && !bytes.Equal(key.PublicKey.Der, publicKey.Der) {
+			return status.Errorf(codes.InvalidArgument, "key_history[%v] public and private keys are not a pair", i)
+		}
+		key.PublicKey = publicKey
+	}
+	return nil
+}
+
+// logSize returns the size of the latest root of the log tree.
+func (s *Server) logSize(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	if s.registry.LogStorage == nil {
+		// Without the log size, a new key may be set to start at roots which
+		// have already been signed.
+		return 0, status.Errorf(codes.FailedPrecondition, "log storage is required to add keys to tree %v", tree.TreeId)
+	}
+	tx, err := s.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if tx != nil {
+		defer tx.Close()
+	}
+	if err == nil {
+		var slr trillian.SignedLogRoot
+		slr, err = tx.LatestSignedLogRoot(ctx)
+		if err == nil {
+			var root types.LogRootV1
+			if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+				return 0, status.Errorf(codes.Internal, "failed to unmarshal log root: %v", err)
+			}
+			return int64(root.TreeSize), tx.Commit()
+		}
+	}
+	// Logs which haven't been initialized yet are empty.
+	if err == storage.ErrTreeNeedsInit {
+		return 0, nil
+	}
+	return 0, err
+}
+
+// keyHistoryUpdate returns the key_history requested by an update, with the
+// private keys of stored keys, which are redacted from responses, filled in.
+func keyHistoryUpdate(requested, stored []*trillian.SigningKey) []*trillian.SigningKey {
+	storedKeys := make(map[int64]*trillian.SigningKey)
+	for _, key := range stored {
+		storedKeys[key.KeyId] = key
+	}
+	keys := make([]*trillian.SigningKey, 0, len(requested))
+	for _, key := range requested {
+		if storedKey, ok := storedKeys[key.KeyId]; ok && key.PrivateKey == nil {
+			key = proto.Clone(key).(*trillian.SigningKey)
+			key.PrivateKey = storedKey.PrivateKey
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 // redact removes sensitive information from t. Returns t for convenience.
 func redact(t *trillian.Tree) *trillian.Tree {
 	t.PrivateKey = nil
+	if len(t.KeyHistory) > 0 {
+		keys := make([]*trillian.SigningKey, 0, len(t.KeyHistory))
+		for _, key := range t.KeyHistory {
+			key = proto.Clone(key).(*trillian.SigningKey)
+			key.PrivateKey = nil
+			keys = append(keys, key)
+		}
+		t.KeyHistory = keys
+	}
 	return t
 }
@@ -15,6 +15,7 @@
 package admin
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/ecdsa"
@@ -39,13 +40,17 @@ import (
 	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/storage/testonly"
 	"github.com/kylelemons/godebug/pretty"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	ktestonly "github.com/google/trillian/crypto/keys/testonly"
 	ttestonly "github.com/google/trillian/testonly"
+
+	_ "github.com/google/trillian/crypto/keys/der/proto"
 )
 
 func TestServer_BeginError(t *testing.T) {
@@ -147,17 +152,43 @@ func TestServer_ListTrees(t *testing.T) {
 	allTrees := []*trillian.Tree{activeLog, frozenLog, deletedLog, activeMap, deletedMap}
 
 	tests := []struct {
-		desc  string
-		req   *trillian.ListTreesRequest
-		trees []*trillian.Tree
+		desc      string
+		req       *trillian.ListTreesRequest
+		wantOpts  storage.ListTreesOptions
+		trees     []*trillian.Tree
+		wantToken string
 	}{
 		{desc: "emptyNonDeleted", req: &trillian.ListTreesRequest{}},
-		{desc: "empty", req: &trillian.ListTreesRequest{ShowDeleted: true}},
+		{
+			desc:     "empty",
+			req:      &trillian.ListTreesRequest{ShowDeleted: true},
+			wantOpts: storage.ListTreesOptions{IncludeDeleted: true},
+		},
 		{desc: "nonDeleted", req: &trillian.ListTreesRequest{}, trees: nonDeletedTrees},
 		{
-			desc:  "allTreesDeleted",
-			req:   &trillian.ListTreesRequest{ShowDeleted: true},
-			trees: allTrees,
+			desc:     "allTreesDeleted",
+			req:      &trillian.ListTreesRequest{ShowDeleted: true},
+			wantOpts: storage.ListTreesOptions{IncludeDeleted: true},
+			trees:    allTrees,
+		},
+		{
+			desc:     "labelSelector",
+			req:      &trillian.ListTreesRequest{LabelSelector: map[string]string{"shard": "2019"}},
+			wantOpts: storage.ListTreesOptions{Labels: map[string]string{"shard": "2019"}},
+			trees:    []*trillian.Tree{frozenLog},
+		},
+		{
+			desc:      "firstPage",
+			req:       &trillian.ListTreesRequest{PageSize: 2},
+			wantOpts:  storage.ListTreesOptions{PageSize: 2},
+			trees:     []*trillian.Tree{activeLog, frozenLog},
+			wantToken: "18",
+		},
+		{
+			desc:     "lastPage",
+			req:      &trillian.ListTreesRequest{PageSize: 2, PageToken: "18"},
+			wantOpts: storage.ListTreesOptions{PageSize: 2, AfterTreeID: 18},
+			trees:    []*trillian.Tree{activeMap},
 		},
 	}
 
@@ -171,7 +202,7 @@ func TestServer_ListTrees(t *testing.T) {
 			false /* commitErr */)
 
 		tx := setup.snapshotTX
-		tx.EXPECT().ListTrees(gomock.Any(), test.req.ShowDeleted).Return(test.trees, nil)
+		tx.EXPECT().ListTrees(gomock.Any(), test.wantOpts).Return(test.trees, nil)
 
 		s := setup.server
 		resp, err := s.ListTrees(ctx, test.req)
@@ -191,6 +222,120 @@ func TestServer_ListTrees(t *testing.T) {
 				break
 			}
 		}
+		if got := resp.NextPageToken; got != test.wantToken {
+			t.Errorf("%v: ListTrees() next_page_token = %q, want %q", test.desc, got, test.wantToken)
+		}
+	}
+}
+
+// fakeAuthorizer allows callers to get the trees in treeIDs.
+type fakeAuthorizer struct {
+	treeIDs map[int64]bool
+}
+
+func (f fakeAuthorizer) Authorize(ctx context.Context, method string, treeID int64) error {
+	if method == getTreeMethod && f.treeIDs[treeID] {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%v denied on tree %v", method, treeID)
+}
+
+func TestServer_ListTreesAuthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	treesByID := make(map[int64]*trillian.Tree)
+	for id := int64(1); id <= 6; id++ {
+		tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+		tree.TreeId = id
+		treesByID[id] = tree
+	}
+	treesOf := func(ids ...int64) []*trillian.Tree {
+		trees := []*trillian.Tree{}
+		for _, id := range ids {
+			trees = append(trees, treesByID[id])
+		}
+		return trees
+	}
+	authz := fakeAuthorizer{treeIDs: map[int64]bool{1: true, 3: true, 4: true, 6: true}}
+
+	// storagePage is a ListTrees call expected by a test, and its result.
+	type storagePage struct {
+		opts  storage.ListTreesOptions
+		trees []*trillian.Tree
+	}
+	tests := []struct {
+		desc      string
+		req       *trillian.ListTreesRequest
+		pages     []storagePage
+		wantIDs   []int64
+		wantToken string
+	}{
+		{
+			desc:    "unpaged",
+			req:     &trillian.ListTreesRequest{},
+			pages:   []storagePage{{trees: treesOf(1, 2, 3, 4, 5, 6)}},
+			wantIDs: []int64{1, 3, 4, 6},
+		},
+		{
+			desc: "toppedUp",
+			req:  &trillian.ListTreesRequest{PageSize: 2},
+			pages: []storagePage{
+				{opts: storage.ListTreesOptions{PageSize: 2}, trees: treesOf(1, 2)},
+				{opts: storage.ListTreesOptions{PageSize: 2, AfterTreeID: 2}, trees: treesOf(3, 4)},
+			},
+			wantIDs:   []int64{1, 3},
+			wantToken: "3",
+		},
+		{
+			desc: "hiddenPageSkipped",
+			req:  &trillian.ListTreesRequest{PageSize: 1, PageToken: "1"},
+			pages: []storagePage{
+				{opts: storage.ListTreesOptions{PageSize: 1, AfterTreeID: 1}, trees: treesOf(2)},
+				{opts: storage.ListTreesOptions{PageSize: 1, AfterTreeID: 2}, trees: treesOf(3)},
+			},
+			wantIDs:   []int64{3},
+			wantToken: "3",
+		},
+		{
+			desc: "lastPage",
+			req:  &trillian.ListTreesRequest{PageSize: 2, PageToken: "4"},
+			pages: []storagePage{
+				{opts: storage.ListTreesOptions{PageSize: 2, AfterTreeID: 4}, trees: treesOf(5, 6)},
+				{opts: storage.ListTreesOptions{PageSize: 2, AfterTreeID: 6}},
+			},
+			wantIDs: []int64{6},
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		as := &testonly.FakeAdminStorage{}
+		for _, page := range test.pages {
+			tx := storage.NewMockReadOnlyAdminTX(ctrl)
+			tx.EXPECT().ListTrees(gomock.Any(), page.opts).Return(page.trees, nil)
+			tx.EXPECT().Commit().Return(nil)
+			tx.EXPECT().Close().MaxTimes(1).Return(nil)
+			as.ReadOnlyTX = append(as.ReadOnlyTX, tx)
+		}
+		s := New(extension.Registry{AdminStorage: as}, nil /* allowedTreeTypes */)
+		s.SetAuthorizer(authz)
+
+		resp, err := s.ListTrees(ctx, test.req)
+		if err != nil {
+			t.Errorf("%v: ListTrees() returned err = %v", test.desc, err)
+			continue
+		}
+		var gotIDs []int64
+		for _, tree := range resp.Tree {
+			gotIDs = append(gotIDs, tree.TreeId)
+		}
+		if diff := pretty.Compare(gotIDs, test.wantIDs); diff != "" {
+			t.Errorf("%v: post-ListTrees() tree IDs diff (-got +want):\n%v", test.desc, diff)
+		}
+		if got := resp.NextPageToken; got != test.wantToken {
+			t.Errorf("%v: ListTrees() next_page_token = %q, want %q", test.desc, got, test.wantToken)
+		}
 	}
 }
 
@@ -217,7 +362,7 @@ func TestServer_ListTreesErrors(t *testing.T) {
 			test.commitErr /* commitErr */)
 
 		tx := setup.snapshotTX
-		tx.EXPECT().ListTrees(gomock.Any(), false).Return(nil, test.listErr)
+		tx.EXPECT().ListTrees(gomock.Any(), storage.ListTreesOptions{}).Return(nil, test.listErr)
 
 		s := setup.server
 		if _, err := s.ListTrees(ctx, &trillian.ListTreesRequest{}); err == nil {
@@ -226,6 +371,35 @@ func TestServer_ListTreesErrors(t *testing.T) {
 	}
 }
 
+func TestServer_ListTreesInvalidArgument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		desc string
+		req  *trillian.ListTreesRequest
+	}{
+		{desc: "negativePageSize", req: &trillian.ListTreesRequest{PageSize: -1}},
+		{desc: "malformedPageToken", req: &trillian.ListTreesRequest{PageToken: "abc"}},
+		{desc: "negativePageToken", req: &trillian.ListTreesRequest{PageToken: "-5"}},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		setup := setupAdminServer(
+			ctrl,
+			nil,   /* keygen */
+			true,  /* snapshot */
+			false, /* shouldCommit */
+			false /* commitErr */)
+
+		_, err := setup.server.ListTrees(ctx, test.req)
+		if got, want := status.Code(err), codes.InvalidArgument; got != want {
+			t.Errorf("%v: ListTrees() returned err = %v, want code %v", test.desc, err, want)
+		}
+	}
+}
+
 func TestServer_GetTree(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -640,9 +814,11 @@ func TestServer_UpdateTree(t *testing.T) {
 		StorageSettings: settings,
 		MaxRootDuration: ptypes.DurationProto(2 * time.Nanosecond),
 		PrivateKey:      ttestonly.MustMarshalAny(t, &empty.Empty{}),
+		SequencerConfig: &trillian.SequencerConfig{BatchSize: 10, Priority: 1},
+		Labels:          map[string]string{"shard": "2019"},
 	}
 	successMask := &field_mask.FieldMask{
-		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key"},
+		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key", "sequencer_config", "labels"},
 	}
 
 	successWant := existingTree
@@ -652,6 +828,20 @@ func TestServer_UpdateTree(t *testing.T) {
 	successWant.StorageSettings = successTree.StorageSettings
 	successWant.PrivateKey = nil // redacted on responses
 	successWant.MaxRootDuration = successTree.MaxRootDuration
+	successWant.SequencerConfig = successTree.SequencerConfig
+	successWant.Labels = successTree.Labels
+
+	// Sequencer config fields may be updated individually.
+	configuredTree := existingTree
+	configuredTree.SequencerConfig = &trillian.SequencerConfig{BatchSize: 10, Priority: 3}
+	pauseTree := &trillian.Tree{
+		TreeId:          configuredTree.TreeId,
+		SequencerConfig: &trillian.SequencerConfig{Paused: true, BatchSize: 99},
+	}
+	pauseMask := &field_mask.FieldMask{Paths: []string{"sequencer_config.paused"}}
+	pauseWant := configuredTree
+	pauseWant.PrivateKey = nil // redacted on responses
+	pauseWant.SequencerConfig = &trillian.SequencerConfig{Paused: true, BatchSize: 10, Priority: 3}
 
 	tests := []struct {
 		desc                           string
@@ -667,6 +857,13 @@ func TestServer_UpdateTree(t *testing.T) {
 			wantTree:    &successWant,
 			wantCommit:  true,
 		},
+		{
+			desc:        "sequencerConfigField",
+			req:         &trillian.UpdateTreeRequest{Tree: pauseTree, UpdateMask: pauseMask},
+			currentTree: &configuredTree,
+			wantTree:    &pauseWant,
+			wantCommit:  true,
+		},
 		{
 			desc:    "nilTree",
 			req:     &trillian.UpdateTreeRequest{},
@@ -744,6 +941,100 @@ func TestServer_UpdateTree(t *testing.T) {
 	}
 }
 
+func TestServer_UpdateTree_KeyHistory(t *testing.T) {
+	ctx := context.Background()
+	ls := memory.NewLogStorage(nil)
+	registry := extension.Registry{AdminStorage: memory.NewAdminStorage(ls), LogStorage: ls}
+	s := New(registry, nil /* allowedTreeTypes */)
+	tree, err := storage.CreateTree(ctx, registry.AdminStorage, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	mask := &field_mask.FieldMask{Paths: []string{"key_history"}}
+	newKey := func(startTreeSize int64) *trillian.SigningKey {
+		return &trillian.SigningKey{
+			PrivateKey: ttestonly.MustMarshalAny(t, &keyspb.PrivateKey{
+				Der: ktestonly.MustMarshalPrivatePEMToDER(ttestonly.DemoPrivateKey, ttestonly.DemoPrivateKeyPass),
+			}),
+			StartTreeSize: startTreeSize,
+		}
+	}
+	update := func(keys ...*trillian.SigningKey) (*trillian.Tree, error) {
+		return s.UpdateTree(ctx, &trillian.UpdateTreeRequest{
+			Tree:       &trillian.Tree{TreeId: tree.TreeId, KeyHistory: keys},
+			UpdateMask: mask,
+		})
+	}
+
+	// The log is empty, so a successor key can't sign its roots from size 0.
+	if _, err := update(newKey(0)); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UpdateTree() with start_tree_size 0: %v, want code %v", err, codes.FailedPrecondition)
+	}
+
+	got, err := update(newKey(10))
+	if err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	if len(got.KeyHistory) != 1 {
+		t.Fatalf("UpdateTree(): got %v keys, want 1", len(got.KeyHistory))
+	}
+	key := got.KeyHistory[0]
+	wantPublicKey := ktestonly.MustMarshalPublicPEMToDER(ttestonly.DemoPublicKey)
+	if key.KeyId == 0 || key.PrivateKey != nil || !bytes.Equal(key.PublicKey.GetDer(), wantPublicKey) {
+		t.Errorf("UpdateTree(): got key %+v, want a key ID, the derived public key and no private key", key)
+	}
+
+	// The redacted key is echoed back, so its private key comes from storage.
+	if _, err := update(key, newKey(5)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdateTree() with decreasing start_tree_size: %v, want code %v", err, codes.InvalidArgument)
+	}
+	if _, err := update(newKey(20)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdateTree() removing a key: %v, want code %v", err, codes.InvalidArgument)
+	}
+	if got, err = update(key, newKey(20)); err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	if len(got.KeyHistory) != 2 || !proto.Equal(got.KeyHistory[0], key) {
+		t.Errorf("UpdateTree(): got keys %+v, want %+v followed by a new key", got.KeyHistory, key)
+	}
+
+	stored, err := storage.GetTree(ctx, registry.AdminStorage, tree.TreeId)
+	if err != nil {
+		t.Fatalf("GetTree(): %v", err)
+	}
+	for i, key := range stored.KeyHistory {
+		if key.PrivateKey == nil {
+			t.Errorf("stored key_history[%v] has no private key", i)
+		}
+	}
+}
+
+func TestServer_UpdateTree_KeyHistoryWithoutLogStorage(t *testing.T) {
+	ctx := context.Background()
+	registry := extension.Registry{AdminStorage: memory.NewAdminStorage(memory.NewLogStorage(nil))}
+	s := New(registry, nil /* allowedTreeTypes */)
+	tree, err := storage.CreateTree(ctx, registry.AdminStorage, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	key := &trillian.SigningKey{
+		PrivateKey: ttestonly.MustMarshalAny(t, &keyspb.PrivateKey{
+			Der: ktestonly.MustMarshalPrivatePEMToDER(ttestonly.DemoPrivateKey, ttestonly.DemoPrivateKeyPass),
+		}),
+		StartTreeSize: 10,
+	}
+
+	// Without log storage the server can't tell whether roots of size 10 have
+	// already been signed.
+	_, err = s.UpdateTree(ctx, &trillian.UpdateTreeRequest{
+		Tree:       &trillian.Tree{TreeId: tree.TreeId, KeyHistory: []*trillian.SigningKey{key}},
+		UpdateMask: &field_mask.FieldMask{Paths: []string{"key_history"}},
+	})
+	if got, want := status.Code(err), codes.FailedPrecondition; got != want {
+		t.Errorf("UpdateTree() returned err = %v, want code %v", err, want)
+	}
+}
+
 func TestServer_DeleteTree(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
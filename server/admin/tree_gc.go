@@ -117,7 +117,7 @@ func (gc *DeletedTreeGC) RunOnce(ctx context.Context) (int, error) {
 	// each delete should be in its own transaction as well.
 	// It's OK to list and delete separately because HardDelete does its own state checking, plus
 	// deleted trees are unlikely to change, specially those deleted for a while.
-	trees, err := storage.ListTrees(ctx, gc.admin, true /* includeDeleted */)
+	trees, err := storage.ListTrees(ctx, gc.admin, storage.ListTreesOptions{IncludeDeleted: true})
 	if err != nil {
 		return 0, fmt.Errorf("error listing trees: %v", err)
 	}
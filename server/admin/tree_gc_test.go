@@ -62,7 +62,7 @@ func TestDeletedTreeGC_Run(t *testing.T) {
 	// * 2nd loop: Snapshot()/ListTrees() only.
 
 	// 1st loop
-	listTX1.EXPECT().ListTrees(gomock.Any(), true /* includeDeleted */).Return([]*trillian.Tree{tree1}, nil)
+	listTX1.EXPECT().ListTrees(gomock.Any(), storage.ListTreesOptions{IncludeDeleted: true}).Return([]*trillian.Tree{tree1}, nil)
 	listTX1.EXPECT().Close().Return(nil)
 	listTX1.EXPECT().Commit().Return(nil)
 	deleteTX1.EXPECT().HardDeleteTree(gomock.Any(), tree1.TreeId).Return(nil)
@@ -70,7 +70,7 @@ func TestDeletedTreeGC_Run(t *testing.T) {
 	deleteTX1.EXPECT().Commit().Return(nil)
 
 	// 2nd loop
-	listTX2.EXPECT().ListTrees(gomock.Any(), true /* includeDeleted */).Return(nil, nil)
+	listTX2.EXPECT().ListTrees(gomock.Any(), storage.ListTreesOptions{IncludeDeleted: true}).Return(nil, nil)
 	listTX2.EXPECT().Close().Return(nil)
 	listTX2.EXPECT().Commit().Return(nil)
 
@@ -162,7 +162,7 @@ func TestDeletedTreeGC_RunOnce(t *testing.T) {
 		listTX := storage.NewMockReadOnlyAdminTX(ctrl)
 		as := &testonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{listTX}}
 
-		listTX.EXPECT().ListTrees(gomock.Any(), true /* includeDeleted */).Return(allTrees, nil)
+		listTX.EXPECT().ListTrees(gomock.Any(), storage.ListTreesOptions{IncludeDeleted: true}).Return(allTrees, nil)
 		listTX.EXPECT().Close().Return(nil)
 		listTX.EXPECT().Commit().Return(nil)
 
@@ -321,7 +321,7 @@ func TestDeletedTreeGC_RunOnceErrors(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
 			listTX := storage.NewMockReadOnlyAdminTX(ctrl)
-			listTX.EXPECT().ListTrees(gomock.Any(), true /* includeDeleted */).AnyTimes().Return(test.listTrees.trees, test.listTrees.listErr)
+			listTX.EXPECT().ListTrees(gomock.Any(), storage.ListTreesOptions{IncludeDeleted: true}).AnyTimes().Return(test.listTrees.trees, test.listTrees.listErr)
 			listTX.EXPECT().Close().AnyTimes().Return(nil)
 			listTX.EXPECT().Commit().AnyTimes().Return(test.listTrees.commitErr)
 
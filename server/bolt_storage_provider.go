@@ -0,0 +1,81 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"flag"
+	"sync"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+
+	boltstorage "github.com/google/trillian/storage/bolt"
+)
+
+var (
+	boltDBPath = flag.String("bolt_db_path", "trillian.db", "Path to the BoltDB database file. The file is locked, so it can only be used by a single server process")
+
+	boltOnce            sync.Once
+	boltStorageInstance *boltProvider
+)
+
+func init() {
+	if err := RegisterStorageProvider("bolt", newBoltStorageProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider bolt: %v", err)
+	}
+}
+
+type boltProvider struct {
+	db *bolt.DB
+	mf monitoring.MetricFactory
+}
+
+func newBoltStorageProvider(mf monitoring.MetricFactory) (StorageProvider, error) {
+	var err error
+
+	boltOnce.Do(func() {
+		var db *bolt.DB
+		db, err = boltstorage.OpenDB(*boltDBPath)
+		if err != nil {
+			return
+		}
+		boltStorageInstance = &boltProvider{
+			db: db,
+			mf: mf,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return boltStorageInstance, nil
+}
+
+func (s *boltProvider) LogStorage() storage.LogStorage {
+	return boltstorage.NewLogStorage(s.db, s.mf)
+}
+
+func (s *boltProvider) MapStorage() storage.MapStorage {
+	return boltstorage.NewMapStorage(s.db)
+}
+
+func (s *boltProvider) AdminStorage() storage.AdminStorage {
+	return boltstorage.NewAdminStorage(s.db)
+}
+
+func (s *boltProvider) Close() error {
+	return s.db.Close()
+}
@@ -62,7 +62,7 @@ func warn() {
 	r, _ := gzip.NewReader(b)
 	r.Close()
 	t, _ := ioutil.ReadAll(r)
-	glog.Warningf("WARNING\n%s\nCloudspanner is an experimental storage implementation, and only supports Logs currently.", string(t))
+	glog.Warningf("WARNING\n%s\nCloudspanner is an experimental storage implementation.", string(t))
 }
 
 type cloudSpannerProvider struct {
@@ -119,7 +119,11 @@ func (s *cloudSpannerProvider) LogStorage() storage.LogStorage {
 
 func (s *cloudSpannerProvider) MapStorage() storage.MapStorage {
 	warn()
-	return nil
+	opts := cloudspanner.MapStorageOptions{}
+	if *csReadOnlyStaleness > 0 {
+		opts.ReadOnlyStaleness = *csReadOnlyStaleness
+	}
+	return cloudspanner.NewMapStorageWithOpts(s.client, opts)
 }
 
 func (s *cloudSpannerProvider) AdminStorage() storage.AdminStorage {
@@ -0,0 +1,103 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"flag"
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/commitlog"
+)
+
+var (
+	commitLogDir            = flag.String("commitlog_dir", "", "Directory holding the file-backed commit log used by the commitlog storage system. If empty, an in-process commit log is used which is lost on exit")
+	commitLogServingStorage = flag.String("commitlog_serving_storage", "memory", "Storage system used by the commitlog storage system to serve queries. Its log data can be dropped and rebuilt from the commit log")
+
+	commitLogOnce            sync.Once
+	commitLogStorageInstance *commitLogProvider
+)
+
+func init() {
+	if err := RegisterStorageProvider("commitlog", newCommitLogStorageProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider commitlog: %v", err)
+	}
+}
+
+type commitLogProvider struct {
+	cl      commitlog.Log
+	serving StorageProvider
+	ls      storage.LogStorage
+}
+
+func newCommitLogStorageProvider(mf monitoring.MetricFactory) (StorageProvider, error) {
+	var err error
+
+	commitLogOnce.Do(func() {
+		if *commitLogServingStorage == "commitlog" {
+			err = fmt.Errorf("commitlog can't serve from itself")
+			return
+		}
+		var serving StorageProvider
+		serving, err = NewStorageProvider(*commitLogServingStorage, mf)
+		if err != nil {
+			return
+		}
+
+		var cl commitlog.Log
+		if *commitLogDir == "" {
+			cl = commitlog.NewMemoryLog()
+		} else if cl, err = commitlog.NewFileLog(*commitLogDir); err != nil {
+			serving.Close()
+			return
+		}
+
+		commitLogStorageInstance = &commitLogProvider{
+			cl:      cl,
+			serving: serving,
+			ls:      commitlog.NewLogStorage(cl, serving.LogStorage(), mf),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commitLogStorageInstance, nil
+}
+
+func (s *commitLogProvider) LogStorage() storage.LogStorage {
+	return s.ls
+}
+
+// MapStorage returns the serving storage's MapStorage, maps aren't kept in the
+// commit log.
+func (s *commitLogProvider) MapStorage() storage.MapStorage {
+	return s.serving.MapStorage()
+}
+
+// AdminStorage returns the serving storage's AdminStorage.
+func (s *commitLogProvider) AdminStorage() storage.AdminStorage {
+	return s.serving.AdminStorage()
+}
+
+func (s *commitLogProvider) Close() error {
+	if err := s.cl.Close(); err != nil {
+		s.serving.Close()
+		return err
+	}
+	return s.serving.Close()
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import "context"
+
+// AnyTree is the treeID passed to Authorize for RPCs which return any number
+// of trees, such as ListTrees. The server filters their results down to the
+// trees the caller may get, so it suffices that the caller may call the method
+// on some tree.
+const AnyTree int64 = -1
+
+// Authorizer decides whether callers may make the RPCs intercepted by a
+// TrillianInterceptor.
+type Authorizer interface {
+	// Authorize returns nil if the caller of the RPC in ctx may call method,
+	// the full gRPC method name (e.g. "/trillian.TrillianLog/QueueLeaf"), on
+	// the tree treeID. treeID is zero for RPCs which don't address an existing
+	// tree, such as CreateTree, and AnyTree for RPCs which list trees.
+	// Errors should have the Unauthenticated or PermissionDenied status codes.
+	Authorize(ctx context.Context, method string, treeID int64) error
+}
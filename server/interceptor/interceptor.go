@@ -18,6 +18,7 @@ package interceptor
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,9 +40,14 @@ const (
 	badInfoReason            = "bad_info"
 	badTreeReason            = "bad_tree"
 	insufficientTokensReason = "insufficient_tokens"
+	unauthorizedReason       = "unauthorized"
 	getTreeStage             = "get_tree"
 	getTokensStage           = "get_tokens"
 	traceSpanRoot            = "github/com/google/trillian/server/interceptor"
+
+	// trillianServicePrefix prefixes the full gRPC method names of the Trillian
+	// services.
+	trillianServicePrefix = "/trillian."
 )
 
 var (
@@ -73,11 +79,12 @@ type RequestProcessor interface {
 
 // TrillianInterceptor checks that:
 // * Requests addressing a tree have the correct tree type and tree state;
-// * TODO(codingllama): Requests are properly authenticated / authorized ; and
+// * Requests are authorized, if an Authorizer is set; and
 // * Requests are rate limited appropriately.
 type TrillianInterceptor struct {
 	admin storage.AdminStorage
 	qm    quota.Manager
+	authz Authorizer
 
 	// quotaDryRun controls whether lack of tokens actually blocks requests (if set to true, no
 	// requests are blocked by lack of tokens).
@@ -94,6 +101,13 @@ func New(admin storage.AdminStorage, qm quota.Manager, quotaDryRun bool, mf moni
 	}
 }
 
+// SetAuthorizer makes the interceptor check that callers are authorized by
+// authz to make their requests. If no Authorizer is set all requests are
+// allowed. Trees returned by ListTrees are filtered by the admin server.
+func (i *TrillianInterceptor) SetAuthorizer(authz Authorizer) {
+	i.authz = authz
+}
+
 func initMetrics(mf monitoring.MetricFactory) {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
@@ -118,10 +132,10 @@ func incRequestDeniedCounter(reason string, treeID int64, quotaUser string) {
 }
 
 // UnaryInterceptor executes the TrillianInterceptor logic for unary RPCs.
-func (i *TrillianInterceptor) UnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+func (i *TrillianInterceptor) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	// Implement UnaryInterceptor using a RequestProcessor, so we 1. exercise it and 2. make it
 	// easier to port this logic to non-gRPC implementations.
-	rp := i.NewProcessor()
+	rp := &trillianProcessor{parent: i, method: info.FullMethod}
 	var err error
 	ctx, err = rp.Before(ctx, req)
 	if err != nil {
@@ -132,6 +146,69 @@ func (i *TrillianInterceptor) UnaryInterceptor(ctx context.Context, req interfac
 	return resp, err
 }
 
+// StreamInterceptor executes the TrillianInterceptor logic for streaming RPCs.
+// The RequestProcessor runs Before on the first message received from the
+// client and After once the handler returns. Additionally, quota is charged
+// for every message sent to the client according to its contents, e.g., one
+// token per leaf in a StreamLeavesResponse.
+// Streams of other services, such as gRPC server reflection, and streams whose
+// requests aren't known to the interceptor are passed through untouched.
+func (i *TrillianInterceptor) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !strings.HasPrefix(info.FullMethod, trillianServicePrefix) {
+		return handler(srv, ss)
+	}
+	tp := &trillianProcessor{parent: i, method: info.FullMethod}
+	ps := &processedStream{ServerStream: ss, tp: tp, ctx: ss.Context()}
+	err := handler(srv, ps)
+	if ps.received && !ps.unmapped && ps.beforeErr == nil {
+		ps.tp.After(ps.ctx, nil, err)
+	}
+	return err
+}
+
+// processedStream is a grpc.ServerStream which passes the messages it handles
+// through a trillianProcessor.
+type processedStream struct {
+	grpc.ServerStream
+	tp *trillianProcessor
+	// ctx is the context returned by tp.Before, if it has been called.
+	ctx context.Context
+	// received is true once the request has been received and passed to
+	// tp.Before, in which case beforeErr holds the error it returned.
+	// unmapped is true if the request wasn't passed to tp.Before because the
+	// interceptor doesn't know its type.
+	received  bool
+	unmapped  bool
+	beforeErr error
+}
+
+func (ps *processedStream) Context() context.Context {
+	return ps.ctx
+}
+
+func (ps *processedStream) RecvMsg(m interface{}) error {
+	if err := ps.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if ps.received {
+		return nil
+	}
+	ps.received = true
+	if _, err := newRPCInfoForRequest(m); err != nil {
+		ps.unmapped = true
+		return nil
+	}
+	ps.ctx, ps.beforeErr = ps.tp.Before(ps.ctx, m)
+	return ps.beforeErr
+}
+
+func (ps *processedStream) SendMsg(m interface{}) error {
+	if err := ps.tp.chargeResponse(ps.ctx, m); err != nil {
+		return err
+	}
+	return ps.ServerStream.SendMsg(m)
+}
+
 // NewProcessor returns a RequestProcessor for the TrillianInterceptor logic.
 func (i *TrillianInterceptor) NewProcessor() RequestProcessor {
 	return &trillianProcessor{parent: i}
@@ -139,6 +216,9 @@ func (i *TrillianInterceptor) NewProcessor() RequestProcessor {
 
 type trillianProcessor struct {
 	parent *TrillianInterceptor
+	// method is the full gRPC name of the RPC being processed. If empty, it's
+	// read from the request context.
+	method string
 	info   *rpcInfo
 }
 
@@ -154,7 +234,20 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}) (conte
 	tp.info = info
 	requestCounter.Inc(fmt.Sprint(info.treeID))
 
-	// TODO(codingllama): Add auth interception
+	if authz := tp.parent.authz; authz != nil {
+		method := tp.method
+		if method == "" {
+			method, _ = grpc.Method(ctx)
+		}
+		treeID := info.treeID
+		if info.anyTree {
+			treeID = AnyTree
+		}
+		if err := authz.Authorize(ctx, method, treeID); err != nil {
+			incRequestDeniedCounter(unauthorizedReason, info.treeID, info.quotaUsers)
+			return ctx, err
+		}
+	}
 
 	if info.getTree {
 		tree, err := trees.GetTree(
@@ -171,17 +264,7 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}) (conte
 	}
 
 	if info.tokens > 0 && len(info.specs) > 0 {
-		err := tp.parent.qm.GetTokens(ctx, info.tokens, info.specs)
-		if err != nil {
-			if !tp.parent.quotaDryRun {
-				incRequestDeniedCounter(insufficientTokensReason, info.treeID, info.quotaUsers)
-				return ctx, status.Errorf(codes.ResourceExhausted, "quota exhausted: %v", err)
-			}
-			glog.Warningf("(quotaDryRun) Request %+v not denied due to dry run mode: %v", req, err)
-		}
-		quota.Metrics.IncAcquired(info.tokens, info.specs, err == nil)
-		if err = ctx.Err(); err != nil {
-			contextErrCounter.Inc(getTokensStage)
+		if err := tp.getTokens(ctx, info.tokens, req); err != nil {
 			return ctx, err
 		}
 	}
@@ -189,15 +272,56 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}) (conte
 	return ctx, nil
 }
 
+// getTokens acquires tokens from the quota specs of the request being
+// processed, req is only used for logging.
+func (tp *trillianProcessor) getTokens(ctx context.Context, tokens int, req interface{}) error {
+	info := tp.info
+	err := tp.parent.qm.GetTokens(ctx, tokens, info.specs)
+	if err != nil {
+		if !tp.parent.quotaDryRun {
+			incRequestDeniedCounter(insufficientTokensReason, info.treeID, info.quotaUsers)
+			return status.Errorf(codes.ResourceExhausted, "quota exhausted: %v", err)
+		}
+		glog.Warningf("(quotaDryRun) Request %+v not denied due to dry run mode: %v", req, err)
+	}
+	quota.Metrics.IncAcquired(tokens, info.specs, err == nil)
+	if err = ctx.Err(); err != nil {
+		contextErrCounter.Inc(getTokensStage)
+		return err
+	}
+	return nil
+}
+
+// chargeResponse acquires the tokens needed to send resp, a message streamed
+// back to the client, if any.
+func (tp *trillianProcessor) chargeResponse(ctx context.Context, resp interface{}) error {
+	if tp.info == nil || len(tp.info.specs) == 0 {
+		return nil
+	}
+	tokens := 0
+	switch resp := resp.(type) {
+	case *trillian.StreamLeavesResponse:
+		tokens = len(resp.GetLeaves())
+	case *trillian.StreamMapLeavesResponse:
+		tokens = len(resp.GetLeaves())
+	case *trillian.WatchSignedLogRootsResponse:
+		tokens = 1
+	}
+	if tokens == 0 {
+		return nil
+	}
+	return tp.getTokens(ctx, tokens, resp)
+}
+
 func (tp *trillianProcessor) After(ctx context.Context, resp interface{}, handlerErr error) {
 	_, span := spanFor(ctx, "After")
 	defer span.End()
-	switch {
-	case tp.info == nil:
+	if tp.info == nil {
 		glog.Warningf("After called with nil rpcInfo, resp = [%+v], handlerErr = [%v]", resp, handlerErr)
 		return
-	case tp.info.tokens == 0:
-		// After() currently only does quota processing
+	}
+	if tp.info.tokens == 0 {
+		// The rest of After() only does quota processing
 		return
 	}
 
@@ -261,6 +385,9 @@ func isLeafOK(leaf *trillian.QueuedLogLeaf) bool {
 type rpcInfo struct {
 	// getTree indicates whether the interceptor should populate treeID.
 	getTree bool
+	// anyTree indicates that the RPC returns any number of trees, which are
+	// filtered by the server, so that it's authorized against AnyTree.
+	anyTree bool
 
 	readonly  bool
 	treeID    int64
@@ -322,6 +449,7 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 	// Admin list
 	case *trillian.ListTreesRequest:
 		info.getTree = false // Zero to many trees
+		info.anyTree = true
 
 	// Admin / readonly
 	case *trillian.GetTreeRequest:
@@ -356,6 +484,14 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		}
 	case *trillian.GetSequencedLeafCountRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+	case *trillian.StreamLeavesRequest:
+		// Leaves are charged as they're sent, see chargeResponse.
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+		info.tokens = 1
+	case *trillian.WatchSignedLogRootsRequest:
+		// Roots are charged as they're sent, see chargeResponse.
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+		info.tokens = 1
 
 	// Log / readwrite
 	case *trillian.QueueLeafRequest:
@@ -390,16 +526,26 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 	case *trillian.GetMapLeavesRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = len(req.GetIndex())
-	case *trillian.GetSignedMapRootByRevisionRequest,
+	case *trillian.GetMapLeafHistoryRequest,
+		*trillian.GetMapStatusRequest,
+		*trillian.GetSignedMapRootByRevisionRequest,
 		*trillian.GetSignedMapRootRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = 1
+	case *trillian.StreamMapLeavesRequest:
+		// Leaves are charged as they're sent, see chargeResponse.
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
+		info.tokens = 1
 
 	// Map / readwrite
 	case *trillian.SetMapLeavesRequest:
 		info.readonly = false
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = len(req.GetLeaves())
+	case *trillian.QueueMapLeavesRequest:
+		info.readonly = false
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
+		info.tokens = len(req.GetLeaves())
 	case *trillian.InitMapRequest:
 		info.readonly = false
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
@@ -418,17 +564,23 @@ func newRPCInfo(req interface{}) (*rpcInfo, error) {
 		return nil, err
 	}
 
-	if info.getTree || info.tokens > 0 {
-		switch req := req.(type) {
-		case logIDRequest:
-			info.treeID = req.GetLogId()
-		case mapIDRequest:
-			info.treeID = req.GetMapId()
-		case treeIDRequest:
-			info.treeID = req.GetTreeId()
-		case treeRequest:
-			info.treeID = req.GetTree().GetTreeId()
-		default:
+	// Tree IDs are read even if the tree isn't, so that requests can be
+	// authorized per tree.
+	switch req := req.(type) {
+	case *trillian.CreateTreeRequest:
+		// The tree doesn't exist yet, and the ID in the request is ignored, so
+		// it mustn't be used to authorize the request.
+		info.treeID = 0
+	case logIDRequest:
+		info.treeID = req.GetLogId()
+	case mapIDRequest:
+		info.treeID = req.GetMapId()
+	case treeIDRequest:
+		info.treeID = req.GetTreeId()
+	case treeRequest:
+		info.treeID = req.GetTree().GetTreeId()
+	default:
+		if info.getTree || info.tokens > 0 {
 			return nil, status.Errorf(codes.Internal, "cannot retrieve treeID from request: %T", req)
 		}
 	}
@@ -486,6 +638,21 @@ func Combine(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterc
 	}
 }
 
+// CombineStream combines stream interceptors.
+// They are nested in order, so interceptor[0] calls on to (and sees the result of) interceptor[1], etc.
+func CombineStream(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			intercept := interceptors[i]
+			baseHandler := handler
+			handler = func(srv interface{}, ss grpc.ServerStream) error {
+				return intercept(srv, ss, info, baseHandler)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
 // ErrorWrapper is a grpc.UnaryServerInterceptor that wraps the errors emitted by the underlying handler.
 func ErrorWrapper(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	ctx, span := spanFor(ctx, "ErrorWrapper")
@@ -494,6 +661,11 @@ func ErrorWrapper(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
 	return rsp, errors.WrapError(err)
 }
 
+// StreamErrorWrapper is a grpc.StreamServerInterceptor that wraps the errors emitted by the underlying handler.
+func StreamErrorWrapper(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return errors.WrapError(handler(srv, ss))
+}
+
 func spanFor(ctx context.Context, name string) (context.Context, *trace.Span) {
 	return trace.StartSpan(ctx, fmt.Sprintf("%s.%s", traceSpanRoot, name))
 }
@@ -17,6 +17,8 @@ package interceptor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -35,6 +37,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	serrors "github.com/google/trillian/server/errors"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 )
 
 func TestTrillianInterceptor_TreeInterception(t *testing.T) {
@@ -80,6 +83,11 @@ func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 			req:      &trillian.GetSignedMapRootRequest{MapId: mapTree.TreeId},
 			wantTree: mapTree,
 		},
+		{
+			desc:     "mapStreamRPC",
+			req:      &trillian.StreamMapLeavesRequest{MapId: mapTree.TreeId},
+			wantTree: mapTree,
+		},
 		{
 			desc:    "unknownRequest",
 			req:     "not-a-request",
@@ -324,6 +332,18 @@ func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 			},
 			wantTokens: 5,
 		},
+		{
+			desc: "queueMapLeavesRequest",
+			req: &trillian.QueueMapLeavesRequest{
+				MapId:  mapTree.TreeId,
+				Leaves: []*trillian.MapLeaf{{}, {}},
+			},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Write, TreeID: mapTree.TreeId},
+				{Group: quota.Global, Kind: quota.Write},
+			},
+			wantTokens: 2,
+		},
 		{
 			desc: "quotaError",
 			req:  &trillian.GetLatestSignedLogRootRequest{LogId: logTree.TreeId},
@@ -564,6 +584,181 @@ func TestTrillianInterceptor_NotIntercepted(t *testing.T) {
 	}
 }
 
+// fakeAuthorizer allows the RPCs in allowed, keyed by "<method> <treeID>".
+type fakeAuthorizer struct {
+	allowed map[string]bool
+}
+
+func (f fakeAuthorizer) Authorize(ctx context.Context, method string, treeID int64) error {
+	if !f.allowed[fmt.Sprintf("%v %v", method, treeID)] {
+		return status.Errorf(codes.PermissionDenied, "%v on %v denied", method, treeID)
+	}
+	return nil
+}
+
+func TestTrillianInterceptor_Authorization(t *testing.T) {
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 10
+
+	authz := fakeAuthorizer{allowed: map[string]bool{
+		"/trillian.TrillianLog/GetLatestSignedLogRoot 10": true,
+		"/trillian.TrillianAdmin/CreateTree 0":            true,
+		"/trillian.TrillianAdmin/DeleteTree 10":           true,
+	}}
+
+	tests := []struct {
+		desc     string
+		method   string
+		req      interface{}
+		wantCode codes.Code
+	}{
+		{
+			desc:   "logRPC",
+			method: "/trillian.TrillianLog/GetLatestSignedLogRoot",
+			req:    &trillian.GetLatestSignedLogRootRequest{LogId: logTree.TreeId},
+		},
+		{
+			desc:     "logRPCOtherTree",
+			method:   "/trillian.TrillianLog/GetLatestSignedLogRoot",
+			req:      &trillian.GetLatestSignedLogRootRequest{LogId: 11},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			desc:     "logRPCOtherMethod",
+			method:   "/trillian.TrillianLog/QueueLeaf",
+			req:      &trillian.QueueLeafRequest{LogId: logTree.TreeId},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			desc:   "adminCreate",
+			method: "/trillian.TrillianAdmin/CreateTree",
+			req:    &trillian.CreateTreeRequest{Tree: &trillian.Tree{}},
+		},
+		{
+			desc:   "adminWriteByID",
+			method: "/trillian.TrillianAdmin/DeleteTree",
+			req:    &trillian.DeleteTreeRequest{TreeId: logTree.TreeId},
+		},
+		{
+			desc:     "adminWriteByTree",
+			method:   "/trillian.TrillianAdmin/UpdateTree",
+			req:      &trillian.UpdateTreeRequest{Tree: &trillian.Tree{TreeId: logTree.TreeId}},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			admin := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			intercept := New(admin, quota.Noop(), false /* quotaDryRun */, nil /* mf */)
+			intercept.SetAuthorizer(authz)
+			handler := &fakeHandler{resp: "handler response"}
+
+			_, err := intercept.UnaryInterceptor(ctx, test.req, &grpc.UnaryServerInfo{FullMethod: test.method}, handler.run)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("UnaryInterceptor() returned err = %v, wantCode = %v", err, test.wantCode)
+			}
+			if handler.called != (test.wantCode == codes.OK) {
+				t.Errorf("UnaryInterceptor(): handler called = %v, want %v", handler.called, test.wantCode == codes.OK)
+			}
+		})
+	}
+}
+
+func TestTrillianInterceptor_Policy(t *testing.T) {
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 10
+
+	policy, err := ParsePolicy([]byte(`{
+  "rules": [
+    {"identities": ["admin"], "methods": ["*"]},
+    {"identities": ["frontend"], "methods": ["*"], "tree_ids": [10]}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("ParsePolicy(): %v", err)
+	}
+
+	tests := []struct {
+		desc     string
+		ctx      context.Context
+		method   string
+		req      interface{}
+		wantCode codes.Code
+	}{
+		{
+			desc:   "adminCreate",
+			ctx:    peerContext("admin"),
+			method: "/trillian.TrillianAdmin/CreateTree",
+			req:    &trillian.CreateTreeRequest{Tree: &trillian.Tree{TreeId: logTree.TreeId}},
+		},
+		{
+			// The tree ID in the request is ignored, so it mustn't allow callers
+			// scoped to that tree to create trees.
+			desc:     "frontendCreate",
+			ctx:      peerContext("frontend"),
+			method:   "/trillian.TrillianAdmin/CreateTree",
+			req:      &trillian.CreateTreeRequest{Tree: &trillian.Tree{TreeId: logTree.TreeId}},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			// Callers scoped to some trees may list trees, as ListTrees only
+			// returns the trees they may get.
+			desc:   "frontendList",
+			ctx:    peerContext("frontend"),
+			method: "/trillian.TrillianAdmin/ListTrees",
+			req:    &trillian.ListTreesRequest{},
+		},
+		{
+			desc:     "otherList",
+			ctx:      peerContext("other"),
+			method:   "/trillian.TrillianAdmin/ListTrees",
+			req:      &trillian.ListTreesRequest{},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			desc:   "frontendLog",
+			ctx:    peerContext("frontend"),
+			method: "/trillian.TrillianLog/GetLatestSignedLogRoot",
+			req:    &trillian.GetLatestSignedLogRootRequest{LogId: logTree.TreeId},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			admin := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			intercept := New(admin, quota.Noop(), false /* quotaDryRun */, nil /* mf */)
+			intercept.SetAuthorizer(policy)
+			handler := &fakeHandler{resp: "handler response"}
+
+			_, err := intercept.UnaryInterceptor(test.ctx, test.req, &grpc.UnaryServerInfo{FullMethod: test.method}, handler.run)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("UnaryInterceptor() returned err = %v, wantCode = %v", err, test.wantCode)
+			}
+			if handler.called != (test.wantCode == codes.OK) {
+				t.Errorf("UnaryInterceptor(): handler called = %v, want %v", handler.called, test.wantCode == codes.OK)
+			}
+		})
+	}
+}
+
 // TestTrillianInterceptor_BeforeAfter tests a few Before/After interactions that are
 // difficult/impossible to get unless the methods are called separately (i.e., not via
 // UnaryInterceptor()).
@@ -620,6 +815,189 @@ func TestTrillianInterceptor_BeforeAfter(t *testing.T) {
 	}
 }
 
+func TestTrillianInterceptor_StreamInterceptor(t *testing.T) {
+	logTree := *testonly.LogTree
+	logTree.TreeId = 10
+
+	specs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Read, TreeID: logTree.TreeId},
+		{Group: quota.Global, Kind: quota.Read},
+	}
+	leaves := func(n int) proto.Message {
+		return &trillian.StreamLeavesResponse{Leaves: make([]*trillian.LogLeaf, n)}
+	}
+	root := &trillian.WatchSignedLogRootsResponse{SignedLogRoot: &trillian.SignedLogRoot{}}
+
+	tests := []struct {
+		desc   string
+		dryRun bool
+		method string
+		req    proto.Message
+		resps  []proto.Message
+		// getTokensErrs are returned by GetTokens for the request, followed by
+		// each response.
+		getTokensErrs []error
+		wantTokens    []int
+		wantSent      int
+		wantCode      codes.Code
+	}{
+		{
+			desc:       "ok",
+			method:     "/trillian.TrillianLog/StreamLeaves",
+			req:        &trillian.StreamLeavesRequest{LogId: logTree.TreeId},
+			resps:      []proto.Message{leaves(3), leaves(2)},
+			wantTokens: []int{1, 3, 2},
+			wantSent:   2,
+		},
+		{
+			desc:       "watchRoots",
+			method:     "/trillian.TrillianLog/WatchSignedLogRoots",
+			req:        &trillian.WatchSignedLogRootsRequest{LogId: logTree.TreeId},
+			resps:      []proto.Message{root, root},
+			wantTokens: []int{1, 1, 1},
+			wantSent:   2,
+		},
+		{
+			desc:          "requestQuotaExhausted",
+			method:        "/trillian.TrillianLog/StreamLeaves",
+			req:           &trillian.StreamLeavesRequest{LogId: logTree.TreeId},
+			resps:         []proto.Message{leaves(3)},
+			getTokensErrs: []error{errors.New("not enough tokens")},
+			wantTokens:    []int{1},
+			wantCode:      codes.ResourceExhausted,
+		},
+		{
+			desc:          "responseQuotaExhausted",
+			method:        "/trillian.TrillianLog/StreamLeaves",
+			req:           &trillian.StreamLeavesRequest{LogId: logTree.TreeId},
+			resps:         []proto.Message{leaves(3), leaves(2)},
+			getTokensErrs: []error{nil, nil, errors.New("not enough tokens")},
+			wantTokens:    []int{1, 3, 2},
+			wantSent:      1,
+			wantCode:      codes.ResourceExhausted,
+		},
+		{
+			desc:          "quotaDryRun",
+			dryRun:        true,
+			method:        "/trillian.TrillianLog/StreamLeaves",
+			req:           &trillian.StreamLeavesRequest{LogId: logTree.TreeId},
+			resps:         []proto.Message{leaves(3)},
+			getTokensErrs: []error{nil, errors.New("not enough tokens")},
+			wantTokens:    []int{1, 3},
+			wantSent:      1,
+		},
+		{
+			desc:     "unknownTree",
+			method:   "/trillian.TrillianLog/StreamLeaves",
+			req:      &trillian.StreamLeavesRequest{LogId: 11},
+			resps:    []proto.Message{leaves(3)},
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			admin := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(&logTree, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), gomock.Not(logTree.TreeId)).AnyTimes().Return(nil, status.Error(codes.NotFound, "not found"))
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			qm := quota.NewMockManager(ctrl)
+			var calls []*gomock.Call
+			for i, tokens := range test.wantTokens {
+				var err error
+				if i < len(test.getTokensErrs) {
+					err = test.getTokensErrs[i]
+				}
+				calls = append(calls, qm.EXPECT().GetTokens(gomock.Any(), tokens, specs).Return(err))
+			}
+			gomock.InOrder(calls...)
+			qm.EXPECT().PutTokens(gomock.Any(), gomock.Any(), specs).AnyTimes().Return(nil)
+
+			stream := &fakeServerStream{ctx: context.Background(), req: test.req}
+			var gotTree *trillian.Tree
+			handler := func(_ interface{}, ss grpc.ServerStream) error {
+				req := proto.Clone(test.req)
+				req.Reset()
+				if err := ss.RecvMsg(req); err != nil {
+					return err
+				}
+				gotTree, _ = trees.FromContext(ss.Context())
+				for _, resp := range test.resps {
+					if err := ss.SendMsg(resp); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			intercept := New(admin, qm, test.dryRun, nil /* mf */)
+			err := intercept.StreamInterceptor(nil /* srv */, stream, &grpc.StreamServerInfo{FullMethod: test.method}, handler)
+			if got := status.Code(err); got != test.wantCode {
+				t.Errorf("StreamInterceptor() returned err = %v, wantCode = %v", err, test.wantCode)
+			}
+			if got := len(stream.sent); got != test.wantSent {
+				t.Errorf("StreamInterceptor() sent %v responses, want %v", got, test.wantSent)
+			}
+			if test.wantCode == codes.OK && !proto.Equal(gotTree, &logTree) {
+				t.Errorf("handler ctx tree = %v, want %v", gotTree, &logTree)
+			}
+		})
+	}
+}
+
+func TestTrillianInterceptor_StreamPassThrough(t *testing.T) {
+	tests := []struct {
+		desc   string
+		method string
+		req    proto.Message
+	}{
+		{
+			desc:   "reflection",
+			method: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+			req:    &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}},
+		},
+		{
+			desc:   "unmappedRequest",
+			method: "/trillian.TrillianLog/StreamUnknown",
+			req:    &rpb.ServerReflectionRequest{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			// Neither storage nor quota may be used, and every RPC is denied if
+			// authorized.
+			intercept := New(storage.NewMockAdminStorage(ctrl), quota.NewMockManager(ctrl), false /* quotaDryRun */, nil /* mf */)
+			intercept.SetAuthorizer(fakeAuthorizer{})
+
+			stream := &fakeServerStream{ctx: context.Background(), req: test.req}
+			resp := &rpb.ServerReflectionResponse{}
+			handler := func(_ interface{}, ss grpc.ServerStream) error {
+				req := &rpb.ServerReflectionRequest{}
+				if err := ss.RecvMsg(req); err != nil {
+					return err
+				}
+				return ss.SendMsg(resp)
+			}
+
+			if err := intercept.StreamInterceptor(nil /* srv */, stream, &grpc.StreamServerInfo{FullMethod: test.method}, handler); err != nil {
+				t.Fatalf("StreamInterceptor() returned err = %v", err)
+			}
+			if got, want := stream.sent, []interface{}{resp}; !reflect.DeepEqual(got, want) {
+				t.Errorf("StreamInterceptor() sent %v, want %v", got, want)
+			}
+		})
+	}
+}
+
 func TestCombine(t *testing.T) {
 	i1 := &fakeInterceptor{key: "key1", val: "foo"}
 	i2 := &fakeInterceptor{key: "key2", val: "bar"}
@@ -788,6 +1166,29 @@ func (f *fakeHandler) run(ctx context.Context, req interface{}) (interface{}, er
 	return f.resp, f.err
 }
 
+// fakeServerStream is a grpc.ServerStream which receives req and records the
+// messages sent.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	req  proto.Message
+	sent []interface{}
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeServerStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), f.req)
+	return nil
+}
+
+func (f *fakeServerStream) SendMsg(m interface{}) error {
+	f.sent = append(f.sent, m)
+	return nil
+}
+
 type fakeInterceptor struct {
 	key    interface{}
 	val    interface{}
@@ -0,0 +1,161 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Policy is an Authorizer which allows the RPCs granted by its rules, and
+// denies all others. Callers are identified by the subject common name of the
+// client certificate they presented over mutual TLS. Servers don't start the
+// REST gateway under mutual TLS, so every request is made by such a caller.
+//
+// Policies are usually read from a JSON file, e.g.:
+//
+//   {
+//     "rules": [
+//       {"identities": ["admin"], "methods": ["*"]},
+//       {"identities": ["ct-frontend"], "methods": ["/trillian.TrillianLog/*"], "tree_ids": [123, 456]},
+//       {"identities": ["*"], "methods": ["/trillian.TrillianLog/GetLatestSignedLogRoot"]}
+//     ]
+//   }
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule allows a set of callers to make a set of RPCs on a set of trees.
+type PolicyRule struct {
+	// Identities are the callers the rule applies to. "*" matches any
+	// authenticated caller.
+	Identities []string `json:"identities"`
+	// Methods are the full gRPC names of the RPCs the rule allows. A name
+	// ending in "/*" matches all the RPCs of a service, and "*" matches any RPC.
+	Methods []string `json:"methods"`
+	// TreeIDs are the trees the rule applies to. If empty, the rule applies to
+	// all trees, as well as to RPCs which don't address a tree (e.g.
+	// CreateTree). RPCs which list trees (e.g. ListTrees) are allowed by any
+	// rule, as only the trees the caller may get are returned.
+	TreeIDs []int64 `json:"tree_ids,omitempty"`
+}
+
+// LoadPolicy reads a Policy from the JSON file at path.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %v", err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy parses and validates a JSON-encoded Policy.
+func ParsePolicy(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %v", err)
+	}
+	for i, rule := range p.Rules {
+		if len(rule.Identities) == 0 {
+			return nil, fmt.Errorf("policy rule %d: no identities", i)
+		}
+		if len(rule.Methods) == 0 {
+			return nil, fmt.Errorf("policy rule %d: no methods", i)
+		}
+		for _, m := range rule.Methods {
+			if m != "*" && !strings.HasPrefix(m, "/") {
+				return nil, fmt.Errorf("policy rule %d: method %q is not a full gRPC method name", i, m)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Authorize implements Authorizer.Authorize.
+func (p *Policy) Authorize(ctx context.Context, method string, treeID int64) error {
+	identity, err := PeerIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rule := range p.Rules {
+		if rule.allows(identity, method, treeID) {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "%q may not call %v on tree %v", identity, method, treeID)
+}
+
+func (r *PolicyRule) allows(identity, method string, treeID int64) bool {
+	return r.matchesIdentity(identity) && r.matchesMethod(method) && r.matchesTree(treeID)
+}
+
+func (r *PolicyRule) matchesIdentity(identity string) bool {
+	for _, id := range r.Identities {
+		if id == "*" || id == identity {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PolicyRule) matchesMethod(method string) bool {
+	for _, m := range r.Methods {
+		switch {
+		case m == "*", m == method:
+			return true
+		case strings.HasSuffix(m, "/*") && strings.HasPrefix(method, m[:len(m)-1]):
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PolicyRule) matchesTree(treeID int64) bool {
+	if len(r.TreeIDs) == 0 || treeID == AnyTree {
+		return true
+	}
+	for _, id := range r.TreeIDs {
+		if id == treeID {
+			return true
+		}
+	}
+	return false
+}
+
+// PeerIdentity returns the identity of the caller of the RPC in ctx: the
+// subject common name of its verified mutual TLS client certificate.
+func PeerIdentity(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "no peer information")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "connection is not using TLS")
+	}
+	chains := tlsInfo.State.VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return "", status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+	return chains[0][0].Subject.CommonName, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `{
+  "rules": [
+    {"identities": ["admin"], "methods": ["*"]},
+    {"identities": ["frontend"], "methods": ["/trillian.TrillianLog/*"], "tree_ids": [10, 11]},
+    {"identities": ["*"], "methods": ["/trillian.TrillianLog/GetLatestSignedLogRoot"]}
+  ]
+}`
+
+// peerContext returns a context for an RPC from a client which presented a
+// verified certificate for cn over mutual TLS.
+func peerContext(cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy(): %v", err)
+	}
+
+	tests := []struct {
+		desc     string
+		ctx      context.Context
+		method   string
+		treeID   int64
+		wantCode codes.Code
+	}{
+		{desc: "adminCreate", ctx: peerContext("admin"), method: "/trillian.TrillianAdmin/CreateTree"},
+		{desc: "adminLog", ctx: peerContext("admin"), method: "/trillian.TrillianLog/QueueLeaf", treeID: 12},
+		{desc: "frontendLog", ctx: peerContext("frontend"), method: "/trillian.TrillianLog/QueueLeaf", treeID: 10},
+		{desc: "frontendOtherLog", ctx: peerContext("frontend"), method: "/trillian.TrillianLog/QueueLeaf", treeID: 12, wantCode: codes.PermissionDenied},
+		{desc: "frontendOtherService", ctx: peerContext("frontend"), method: "/trillian.TrillianMap/GetLeaves", treeID: 10, wantCode: codes.PermissionDenied},
+		{desc: "frontendNoTree", ctx: peerContext("frontend"), method: "/trillian.TrillianLog/QueueLeaf", wantCode: codes.PermissionDenied},
+		{desc: "frontendList", ctx: peerContext("frontend"), method: "/trillian.TrillianLog/ListLogs", treeID: AnyTree},
+		{desc: "frontendListOtherService", ctx: peerContext("frontend"), method: "/trillian.TrillianAdmin/ListTrees", treeID: AnyTree, wantCode: codes.PermissionDenied},
+		{desc: "anyoneGetRoot", ctx: peerContext("someone"), method: "/trillian.TrillianLog/GetLatestSignedLogRoot", treeID: 12},
+		{desc: "anyoneQueue", ctx: peerContext("someone"), method: "/trillian.TrillianLog/QueueLeaf", treeID: 12, wantCode: codes.PermissionDenied},
+		{desc: "servicePrefix", ctx: peerContext("frontend"), method: "/trillian.TrillianLogX/QueueLeaf", treeID: 10, wantCode: codes.PermissionDenied},
+		{desc: "noPeer", ctx: context.Background(), method: "/trillian.TrillianLog/GetLatestSignedLogRoot", treeID: 10, wantCode: codes.Unauthenticated},
+		{desc: "noTLS", ctx: peer.NewContext(context.Background(), &peer.Peer{}), method: "/trillian.TrillianLog/GetLatestSignedLogRoot", treeID: 10, wantCode: codes.Unauthenticated},
+		{desc: "unverified", ctx: peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}), method: "/trillian.TrillianLog/GetLatestSignedLogRoot", treeID: 10, wantCode: codes.Unauthenticated},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := policy.Authorize(test.ctx, test.method, test.treeID)
+			if got := status.Code(err); got != test.wantCode {
+				t.Errorf("Authorize(%v, %v) = %v, want code %v", test.method, test.treeID, err, test.wantCode)
+			}
+		})
+	}
+}
+
+func TestParsePolicy_Errors(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		policy string
+	}{
+		{desc: "notJSON", policy: "rules"},
+		{desc: "noIdentities", policy: `{"rules": [{"methods": ["*"]}]}`},
+		{desc: "noMethods", policy: `{"rules": [{"identities": ["*"]}]}`},
+		{desc: "shortMethod", policy: `{"rules": [{"identities": ["*"], "methods": ["QueueLeaf"]}]}`},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := ParsePolicy([]byte(test.policy)); err == nil {
+				t.Errorf("ParsePolicy(%q) returned nil error", test.policy)
+			}
+		})
+	}
+}
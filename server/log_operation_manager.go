@@ -25,9 +25,11 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
 	"github.com/google/trillian/util"
 	"github.com/google/trillian/util/election"
 )
@@ -250,6 +252,43 @@ func (l *LogOperationManager) updateHeldIDs(ctx context.Context, logIDs, allIDs
 	}
 }
 
+// scheduleLogs returns the logIDs to process in this pass, in the order they
+// should be processed: highest sequencer_config.priority first, keeping the
+// existing order among logs of equal priority. Logs whose sequencing is paused
+// are left out. A log whose tree can't be read is kept, at the default
+// priority, so that its operation reports the error.
+//
+// The trees read are also returned, keyed by log ID, so that they can be
+// passed on to the operations rather than read again in the same pass.
+func (l *LogOperationManager) scheduleLogs(ctx context.Context, logIDs []int64) ([]int64, map[int64]*trillian.Tree) {
+	if l.info.Registry.AdminStorage == nil {
+		return logIDs, nil
+	}
+	scheduled := make([]int64, 0, len(logIDs))
+	logTrees := make(map[int64]*trillian.Tree)
+	priority := make(map[int64]int32)
+	for _, logID := range logIDs {
+		tree, err := storage.GetTree(ctx, l.info.Registry.AdminStorage, logID)
+		if err != nil {
+			glog.Warningf("%v: failed to get sequencer config: %v", logID, err)
+			scheduled = append(scheduled, logID)
+			continue
+		}
+		cfg := tree.GetSequencerConfig()
+		if cfg.GetPaused() {
+			glog.V(1).Infof("%v: sequencing paused", logID)
+			continue
+		}
+		logTrees[logID] = tree
+		priority[logID] = cfg.GetPriority()
+		scheduled = append(scheduled, logID)
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return priority[scheduled[i]] > priority[scheduled[j]]
+	})
+	return scheduled, logTrees
+}
+
 func (l *LogOperationManager) getLogsAndExecutePass(ctx context.Context) error {
 	allIDs, err := l.getLogIDs(ctx)
 	if err != nil {
@@ -260,11 +299,13 @@ func (l *LogOperationManager) getLogsAndExecutePass(ctx context.Context) error {
 		return fmt.Errorf("failed to determine log IDs we're master for: %v", err)
 	}
 	l.updateHeldIDs(ctx, logIDs, allIDs)
+	logIDs, logTrees := l.scheduleLogs(ctx, logIDs)
 	glog.V(1).Infof("Beginning run for %v active log(s)", len(logIDs))
 
 	// TODO(pavelkalinnikov): Run executor once instead of doing it on each pass.
 	// This will be also needed when factoring out per-log operation loop.
 	ex := newExecutor(l.logOperation, &l.info, len(logIDs))
+	ex.trees = logTrees
 	// Put logIDs that need to be processed to the executor's channel.
 	for _, logID := range logIDs {
 		ex.jobs <- logID
@@ -358,6 +399,9 @@ type logOperationExecutor struct {
 	// auto-cancelable when mastership is lost.
 	// TODO(pavelkalinnikov): Report job completion status back.
 	jobs chan int64
+	// trees holds the trees of the logs, if they've already been read in this
+	// pass. They're passed to the log operation in its context.
+	trees map[int64]*trillian.Tree
 }
 
 func newExecutor(op LogOperation, info *LogOperationInfo, jobs int) *logOperationExecutor {
@@ -394,7 +438,11 @@ func (e *logOperationExecutor) run(ctx context.Context) {
 
 				label := strconv.FormatInt(logID, 10)
 				start := e.info.TimeSource.Now()
-				count, err := e.op.ExecutePass(ctx, logID, e.info)
+				jobCtx := ctx
+				if tree := e.trees[logID]; tree != nil {
+					jobCtx = trees.NewContext(ctx, tree)
+				}
+				count, err := e.op.ExecutePass(jobCtx, logID, e.info)
 				if err != nil {
 					glog.Errorf("ExecutePass(%v) failed: %v", logID, err)
 					failedSigningRuns.Inc(label)
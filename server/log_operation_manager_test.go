@@ -27,6 +27,7 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
 	"github.com/google/trillian/util"
 	"github.com/google/trillian/util/election"
 	"github.com/google/trillian/util/election/stub"
@@ -179,6 +180,35 @@ func TestLogOperationManagerPassesIDs(t *testing.T) {
 	lom.OperationSingle(ctx)
 }
 
+func TestLogOperationManagerPassesTrees(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{451: "LogID1", 145: "LogID2"})
+	registry := extension.Registry{
+		LogStorage:   fakeStorage,
+		AdminStorage: mockAdmin,
+	}
+
+	// The trees read to schedule the pass are passed to the operation, so it
+	// doesn't need to read them again.
+	mockLogOp := NewMockLogOperation(ctrl)
+	for _, logID := range []int64{451, 145} {
+		logID := logID
+		mockLogOp.EXPECT().ExecutePass(gomock.Any(), logID, gomock.Any()).Do(func(ctx context.Context, _ int64, _ *LogOperationInfo) {
+			if tree, ok := trees.FromContext(ctx); !ok || tree.TreeId != logID {
+				t.Errorf("ExecutePass(%v): got tree %v in context, want tree %v", logID, tree, logID)
+			}
+		})
+	}
+
+	info := defaultLogOperationInfo(registry)
+	lom := NewLogOperationManager(info, mockLogOp)
+
+	lom.OperationSingle(ctx)
+}
+
 func TestHeldInfo(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -208,6 +238,49 @@ func TestHeldInfo(t *testing.T) {
 	}
 }
 
+func TestScheduleLogs(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	configs := map[int64]*trillian.SequencerConfig{
+		1: nil,
+		2: {Priority: 10},
+		3: {Priority: 10, Paused: true},
+		4: {Priority: -1},
+		5: {Priority: 10},
+	}
+	mockAdmin := storage.NewMockAdminStorage(ctrl)
+	mockAdminTx := storage.NewMockReadOnlyAdminTX(ctrl)
+	for id, cfg := range configs {
+		mockAdminTx.EXPECT().GetTree(gomock.Any(), id).AnyTimes().Return(&trillian.Tree{TreeId: id, SequencerConfig: cfg}, nil)
+	}
+	mockAdminTx.EXPECT().GetTree(gomock.Any(), int64(98)).AnyTimes().Return(nil, errors.New("failedGetTree"))
+	mockAdminTx.EXPECT().Commit().AnyTimes().Return(nil)
+	mockAdminTx.EXPECT().Close().AnyTimes().Return(nil)
+	mockAdmin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(mockAdminTx, nil)
+
+	info := defaultLogOperationInfo(extension.Registry{AdminStorage: mockAdmin})
+	lom := NewLogOperationManager(info, NewMockLogOperation(ctrl))
+
+	var tests = []struct {
+		in   []int64
+		want []int64
+	}{
+		{in: []int64{}, want: []int64{}},
+		{in: []int64{1, 2}, want: []int64{2, 1}},
+		{in: []int64{4, 1, 5, 2}, want: []int64{5, 2, 1, 4}},
+		{in: []int64{3}, want: []int64{}},
+		{in: []int64{1, 3, 4, 2}, want: []int64{2, 1, 4}},
+		{in: []int64{4, 98, 2}, want: []int64{2, 98, 4}},
+	}
+	for _, test := range tests {
+		if got, _ := lom.scheduleLogs(ctx, test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("lom.scheduleLogs(%v)=%v; want %v", test.in, got, test.want)
+		}
+	}
+}
+
 func TestMasterFor(t *testing.T) {
 	ctx := context.Background()
 	firstIDs := []int64{1, 2, 3, 4}
@@ -16,7 +16,11 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian"
@@ -33,8 +37,8 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// TODO: There is no access control in the server yet and clients could easily modify
-// any tree.
+// Access control is left to the interceptor.Authorizer configured for the
+// gRPC server, if any; without one, clients can modify any tree.
 
 // Pass this as a fixed value to proof calculations. It's used as the max depth of the tree
 const (
@@ -42,6 +46,22 @@ const (
 	traceSpanRoot  = "github.com/google/trillian/server"
 )
 
+var (
+	// StreamLeavesBatchSize is the maximum number of leaves sent in each
+	// StreamLeavesResponse.
+	StreamLeavesBatchSize int64 = 1000
+	// StreamLeavesPollInterval is how often a StreamLeaves call that follows the
+	// tree checks for newly integrated leaves.
+	StreamLeavesPollInterval = 1 * time.Second
+	// WatchSignedLogRootsPollInterval is how often a WatchSignedLogRoots call
+	// checks storage for new roots.
+	WatchSignedLogRootsPollInterval = 1 * time.Second
+	// MaxByHashResults is the maximum number of leaves or proofs returned by a
+	// GetLeavesByHash or GetInclusionProofByHash call. Requests may ask for
+	// fewer, and page through the rest.
+	MaxByHashResults int64 = 1000
+)
+
 var (
 	optsLogInit            = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
 	optsLogRead            = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
@@ -270,6 +290,11 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 	if err := validateGetInclusionProofByHashRequest(req); err != nil {
 		return nil, err
 	}
+	start, err := decodeByHashPageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	limit := byHashLimit(req.MaxResults)
 	logID := req.LogId
 
 	tree, hasher, err := t.getTreeAndHasher(ctx, logID, optsLogRead)
@@ -286,9 +311,10 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 	}
 	defer tx.Close()
 
-	// Find the leaf index of the supplied hash
+	// Find the leaf index of the supplied hash. One more leaf than needed is
+	// read, to tell whether there's another page.
 	leafHashes := [][]byte{req.LeafHash}
-	leaves, err := tx.GetLeavesByHash(ctx, leafHashes, req.OrderBySequence)
+	leaves, err := tx.GetLeavesByHash(ctx, leafHashes, req.OrderBySequence, start, limit+1)
 	if err != nil {
 		return nil, err
 	}
@@ -302,12 +328,17 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
 
-	// TODO(Martin2112): Need to define a limit on number of results or some form of paging etc.
 	proofs := make([]*trillian.Proof, 0, len(leaves))
+	var nextPageToken string
 	for _, leaf := range leaves {
-		// Don't include leaves that aren't in the requested TreeSize.
+		// Don't include leaves that aren't in the requested TreeSize. The
+		// leaves are in sequence order, so none of the rest are either.
 		if leaf.LeafIndex >= req.TreeSize {
-			continue
+			break
+		}
+		if int64(len(proofs)) == limit {
+			nextPageToken = encodeByHashPageToken(leaf.LeafIndex)
+			break
 		}
 		proof, err := getInclusionProofForLeafIndex(ctx, tx, hasher, req.TreeSize, leaf.LeafIndex, int64(root.TreeSize))
 		if err != nil {
@@ -328,9 +359,43 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 	return &trillian.GetInclusionProofByHashResponse{
 		SignedLogRoot: &slr,
 		Proof:         proofs,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
+// byHashLimit returns the number of results to return for a by-hash request
+// asking for at most maxResults.
+func byHashLimit(maxResults int64) int64 {
+	if maxResults <= 0 || maxResults > MaxByHashResults {
+		return MaxByHashResults
+	}
+	return maxResults
+}
+
+// encodeByHashPageToken returns a page token for by-hash requests, which
+// continues from the leaf with sequence number next.
+func encodeByHashPageToken(next int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(next, 10)))
+}
+
+// decodeByHashPageToken returns the sequence number a by-hash request with
+// the given page token continues from. An empty token starts from the first
+// leaf.
+func decodeByHashPageToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid page token %q", token)
+	}
+	next, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || next < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid page token %q", token)
+	}
+	return next, nil
+}
+
 // GetConsistencyProof obtains a proof that two versions of the tree are consistent with each
 // other and that the later tree includes all the entries of the prior one. For more details
 // see the example trees in RFC 6962.
@@ -536,6 +601,11 @@ func (t *TrillianLogRPCServer) GetLeavesByHash(ctx context.Context, req *trillia
 	if err := validateGetLeavesByHashRequest(req); err != nil {
 		return nil, err
 	}
+	start, err := decodeByHashPageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	limit := byHashLimit(req.MaxResults)
 
 	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
 	if err != nil {
@@ -547,10 +617,16 @@ func (t *TrillianLogRPCServer) GetLeavesByHash(ctx context.Context, req *trillia
 	}
 	defer tx.Close()
 
-	leaves, err := tx.GetLeavesByHash(ctx, req.LeafHash, req.OrderBySequence)
+	// Read one more leaf than needed, to tell whether there's another page.
+	leaves, err := tx.GetLeavesByHash(ctx, req.LeafHash, req.OrderBySequence, start, limit+1)
 	if err != nil {
 		return nil, err
 	}
+	var nextPageToken string
+	if int64(len(leaves)) > limit {
+		nextPageToken = encodeByHashPageToken(leaves[limit].LeafIndex)
+		leaves = leaves[:limit]
+	}
 
 	root, err := tx.LatestSignedLogRoot(ctx)
 	if err != nil {
@@ -564,9 +640,202 @@ func (t *TrillianLogRPCServer) GetLeavesByHash(ctx context.Context, req *trillia
 	return &trillian.GetLeavesByHashResponse{
 		Leaves:        leaves,
 		SignedLogRoot: &root,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
+// StreamLeaves streams sequenced leaves starting from the requested index, in
+// batches of at most StreamLeavesBatchSize leaves. Each batch is read in its
+// own snapshot, so the stream doesn't hold a storage transaction open while
+// waiting for a slow client. If the request asks to follow the tree the call
+// polls for newly integrated leaves every StreamLeavesPollInterval once it has
+// caught up, until the requested count has been sent or the call is cancelled.
+func (t *TrillianLogRPCServer) StreamLeaves(req *trillian.StreamLeavesRequest, stream trillian.TrillianLog_StreamLeavesServer) error {
+	ctx, span := spanFor(stream.Context(), "StreamLeaves")
+	defer span.End()
+	if err := validateStreamLeavesRequest(req); err != nil {
+		return err
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return err
+	}
+
+	next, end := req.StartIndex, int64(math.MaxInt64)
+	if req.Count > 0 && req.Count <= end-next {
+		end = next + req.Count
+	}
+	for next < end {
+		r, err := t.getStreamLeavesBatch(ctx, tree, req.LogId, next, end)
+		if err != nil {
+			return err
+		}
+		if len(r.Leaves) == 0 {
+			if !req.Follow {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return status.FromContextError(ctx.Err()).Err()
+			case <-time.After(StreamLeavesPollInterval):
+			}
+			continue
+		}
+		if err := stream.Send(r); err != nil {
+			return err
+		}
+		next += int64(len(r.Leaves))
+	}
+	return nil
+}
+
+// getStreamLeavesBatch returns the next batch of leaves in [start, end) which
+// have been integrated into the tree, if any, along with the log root they're
+// read at.
+func (t *TrillianLogRPCServer) getStreamLeavesBatch(ctx context.Context, tree *trillian.Tree, logID, start, end int64) (*trillian.StreamLeavesResponse, error) {
+	tx, err := t.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+
+	r := &trillian.StreamLeavesResponse{SignedLogRoot: &slr}
+
+	if treeSize := int64(root.TreeSize); start < treeSize {
+		if treeSize < end {
+			end = treeSize
+		}
+		count := end - start
+		if count > StreamLeavesBatchSize {
+			count = StreamLeavesBatchSize
+		}
+		leaves, err := tx.GetLeavesByRange(ctx, start, count)
+		if err != nil {
+			return nil, err
+		}
+		// Storage may return fewer leaves than requested, but must make progress
+		// or the stream would never end.
+		if len(leaves) == 0 {
+			return nil, status.Errorf(codes.Internal, "no leaves returned at index %d with tree size %d", start, treeSize)
+		}
+		for i, leaf := range leaves {
+			if want := start + int64(i); leaf.LeafIndex != want {
+				return nil, status.Errorf(codes.Internal, "got leaf at index %d, want %d", leaf.LeafIndex, want)
+			}
+		}
+		r.Leaves = leaves
+	}
+
+	if err := t.commitAndLog(ctx, logID, tx, "StreamLeaves"); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// rootSubscriber is implemented by LogStorage implementations which can push
+// the roots stored through them, see storage/notify.
+type rootSubscriber interface {
+	Subscribe(treeID int64) (<-chan trillian.SignedLogRoot, func())
+}
+
+// WatchSignedLogRoots streams the latest signed log root of a tree, followed
+// by every newer root until the call is cancelled. If the server's LogStorage
+// supports subscriptions roots are pushed as soon as they're stored, in
+// addition storage is polled every WatchSignedLogRootsPollInterval to pick up
+// roots stored by other processes, such as a separate log signer.
+func (t *TrillianLogRPCServer) WatchSignedLogRoots(req *trillian.WatchSignedLogRootsRequest, stream trillian.TrillianLog_WatchSignedLogRootsServer) error {
+	ctx, span := spanFor(stream.Context(), "WatchSignedLogRoots")
+	defer span.End()
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before reading the latest root, so no root stored in between is
+	// missed.
+	var pushed <-chan trillian.SignedLogRoot
+	if rs, ok := t.registry.LogStorage.(rootSubscriber); ok {
+		var cancel func()
+		pushed, cancel = rs.Subscribe(tree.TreeId)
+		defer cancel()
+	}
+
+	var sent *types.LogRootV1
+	send := func(slr trillian.SignedLogRoot) error {
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return status.Errorf(codes.Internal, "Could not read log root: %v", err)
+		}
+		// Storage is polled, and pushed roots may be read again later, so only
+		// send roots which are newer than those already sent.
+		if sent != nil && root.Revision <= sent.Revision {
+			return nil
+		}
+		sent = &root
+		return stream.Send(&trillian.WatchSignedLogRootsResponse{SignedLogRoot: &slr})
+	}
+
+	slr, err := t.latestSignedLogRoot(ctx, tree, req.LogId)
+	if err != nil {
+		return err
+	}
+	if err := send(slr); err != nil {
+		return err
+	}
+
+	poll := time.NewTicker(WatchSignedLogRootsPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case slr := <-pushed:
+			if err := send(slr); err != nil {
+				return err
+			}
+		case <-poll.C:
+			slr, err := t.latestSignedLogRoot(ctx, tree, req.LogId)
+			if err != nil {
+				return err
+			}
+			if err := send(slr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// latestSignedLogRoot reads the latest signed log root of tree in a snapshot.
+func (t *TrillianLogRPCServer) latestSignedLogRoot(ctx context.Context, tree *trillian.Tree, logID int64) (trillian.SignedLogRoot, error) {
+	tx, err := t.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return trillian.SignedLogRoot{}, err
+	}
+	defer tx.Close()
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return trillian.SignedLogRoot{}, err
+	}
+
+	if err := t.commitAndLog(ctx, logID, tx, "WatchSignedLogRoots"); err != nil {
+		return trillian.SignedLogRoot{}, err
+	}
+	return slr, nil
+}
+
 // GetEntryAndProof returns both a Merkle Leaf entry and an inclusion proof for a given index
 // and tree size.
 func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trillian.GetEntryAndProofRequest) (*trillian.GetEntryAndProofResponse, error) {
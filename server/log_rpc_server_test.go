@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto"
@@ -32,6 +33,7 @@ import (
 	"github.com/google/trillian/types"
 	"github.com/kylelemons/godebug/pretty"
 	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -358,6 +360,317 @@ func TestGetLeavesByRange(t *testing.T) {
 	}
 }
 
+// fakeStreamLeavesServer records the responses sent on a StreamLeaves call.
+type fakeStreamLeavesServer struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*trillian.StreamLeavesResponse
+}
+
+func (s *fakeStreamLeavesServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStreamLeavesServer) Send(r *trillian.StreamLeavesResponse) error {
+	s.sent = append(s.sent, r)
+	return nil
+}
+
+func TestStreamLeaves(t *testing.T) {
+	defer func(size int64, interval time.Duration) {
+		StreamLeavesBatchSize, StreamLeavesPollInterval = size, interval
+	}(StreamLeavesBatchSize, StreamLeavesPollInterval)
+	StreamLeavesBatchSize = 3
+	StreamLeavesPollInterval = time.Millisecond
+
+	// signedRoot1 has a tree size of 7.
+	signedRoot9, err := fixedSigner.SignLogRoot(&types.LogRootV1{TimestampNanos: 987654322, RootHash: []byte("A NICER HASH"), TreeSize: 9, Revision: 6})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	var leaves []*trillian.LogLeaf
+	for i := int64(0); i < 9; i++ {
+		leaves = append(leaves, newTestLeaf([]byte(fmt.Sprintf("value%d", i)), nil, i))
+	}
+
+	// batch describes a snapshot read by StreamLeaves, and the
+	// GetLeavesByRange call expected in it, if any.
+	type batch struct {
+		root         *trillian.SignedLogRoot
+		start, count int64
+		leaves       []*trillian.LogLeaf
+		getErr       error
+	}
+
+	for _, test := range []struct {
+		desc    string
+		req     trillian.StreamLeavesRequest
+		batches []batch
+		want    [][]*trillian.LogLeaf
+		wantErr string
+	}{
+		{
+			desc: "to end of tree",
+			req:  trillian.StreamLeavesRequest{StartIndex: 1},
+			batches: []batch{
+				{root: signedRoot1, start: 1, count: 3, leaves: leaves[1:4]},
+				{root: signedRoot1, start: 4, count: 3, leaves: leaves[4:7]},
+				{root: signedRoot1},
+			},
+			want: [][]*trillian.LogLeaf{leaves[1:4], leaves[4:7]},
+		},
+		{
+			desc: "count",
+			req:  trillian.StreamLeavesRequest{StartIndex: 2, Count: 2},
+			batches: []batch{
+				{root: signedRoot1, start: 2, count: 2, leaves: leaves[2:4]},
+			},
+			want: [][]*trillian.LogLeaf{leaves[2:4]},
+		},
+		{
+			desc: "storage truncates range",
+			req:  trillian.StreamLeavesRequest{StartIndex: 4},
+			batches: []batch{
+				{root: signedRoot1, start: 4, count: 3, leaves: leaves[4:5]},
+				{root: signedRoot1, start: 5, count: 2, leaves: leaves[5:7]},
+				{root: signedRoot1},
+			},
+			want: [][]*trillian.LogLeaf{leaves[4:5], leaves[5:7]},
+		},
+		{
+			desc:    "beyond tree size",
+			req:     trillian.StreamLeavesRequest{StartIndex: 7},
+			batches: []batch{{root: signedRoot1}},
+		},
+		{
+			desc: "follow",
+			req:  trillian.StreamLeavesRequest{StartIndex: 5, Count: 4, Follow: true},
+			batches: []batch{
+				{root: signedRoot1, start: 5, count: 2, leaves: leaves[5:7]},
+				{root: signedRoot1},
+				{root: signedRoot9, start: 7, count: 2, leaves: leaves[7:9]},
+			},
+			want: [][]*trillian.LogLeaf{leaves[5:7], leaves[7:9]},
+		},
+		{
+			desc:    "negative start",
+			req:     trillian.StreamLeavesRequest{StartIndex: -1},
+			wantErr: "want >= 0",
+		},
+		{
+			desc:    "negative count",
+			req:     trillian.StreamLeavesRequest{Count: -1},
+			wantErr: "want >= 0",
+		},
+		{
+			desc: "storage error",
+			req:  trillian.StreamLeavesRequest{StartIndex: 1},
+			batches: []batch{
+				{root: signedRoot1, start: 1, count: 3, getErr: errors.New("test error plugh")},
+			},
+			wantErr: "test error plugh",
+		},
+		{
+			desc: "wrong leaf index",
+			req:  trillian.StreamLeavesRequest{StartIndex: 1},
+			batches: []batch{
+				{root: signedRoot1, start: 1, count: 3, leaves: leaves[2:4]},
+			},
+			wantErr: "want 1",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fakeStorage := storage.NewMockLogStorage(ctrl)
+			var calls []*gomock.Call
+			for _, b := range test.batches {
+				mockTX := storage.NewMockLogTreeTX(ctrl)
+				calls = append(calls, fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTX, nil))
+				mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*b.root, nil)
+				if b.count > 0 {
+					mockTX.EXPECT().GetLeavesByRange(gomock.Any(), b.start, b.count).Return(b.leaves, b.getErr)
+				}
+				if b.getErr == nil && (len(b.leaves) == 0 || b.leaves[0].LeafIndex == b.start) {
+					mockTX.EXPECT().Commit().Return(nil)
+				}
+				mockTX.EXPECT().Close().Return(nil)
+			}
+			gomock.InOrder(calls...)
+
+			registry := extension.Registry{
+				AdminStorage: fakeAdminStorage(ctrl, storageParams{logID1, false, 1}),
+				LogStorage:   fakeStorage,
+			}
+			server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+			test.req.LogId = logID1
+			stream := &fakeStreamLeavesServer{ctx: context.Background()}
+			err := server.StreamLeaves(&test.req, stream)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("StreamLeaves()=%v, want err containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StreamLeaves()=%v, want nil", err)
+			}
+			var got [][]*trillian.LogLeaf
+			for _, r := range stream.sent {
+				got = append(got, r.Leaves)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("StreamLeaves() sent %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStreamLeavesFollowCancelled(t *testing.T) {
+	defer func(interval time.Duration) { StreamLeavesPollInterval = interval }(StreamLeavesPollInterval)
+	StreamLeavesPollInterval = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fakeStorage := storage.NewMockLogStorage(ctrl)
+	mockTX := storage.NewMockLogTreeTX(ctrl)
+	fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).MinTimes(1).Return(mockTX, nil)
+	// Nothing new is ever integrated, so the caller gives up.
+	mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).MinTimes(1).Do(func(context.Context) { cancel() }).Return(*signedRoot1, nil)
+	mockTX.EXPECT().Commit().MinTimes(1).Return(nil)
+	mockTX.EXPECT().Close().MinTimes(1).Return(nil)
+
+	registry := extension.Registry{
+		AdminStorage: fakeAdminStorage(ctrl, storageParams{logID1, false, 1}),
+		LogStorage:   fakeStorage,
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	req := &trillian.StreamLeavesRequest{LogId: logID1, StartIndex: 7, Follow: true}
+	err := server.StreamLeaves(req, &fakeStreamLeavesServer{ctx: ctx})
+	if got, want := status.Code(err), codes.Canceled; got != want {
+		t.Errorf("StreamLeaves()=%v, want code %v", err, want)
+	}
+}
+
+// fakeWatchSignedLogRootsServer records the roots sent on a
+// WatchSignedLogRoots call, and cancels the call once it has sent want roots.
+type fakeWatchSignedLogRootsServer struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cancel func()
+	want   int
+	sent   []*trillian.SignedLogRoot
+}
+
+func (s *fakeWatchSignedLogRootsServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchSignedLogRootsServer) Send(r *trillian.WatchSignedLogRootsResponse) error {
+	s.sent = append(s.sent, r.SignedLogRoot)
+	if len(s.sent) >= s.want {
+		s.cancel()
+	}
+	return nil
+}
+
+// subscribingLogStorage is a LogStorage which pushes roots to subscribers.
+type subscribingLogStorage struct {
+	*storage.MockLogStorage
+	pushed chan trillian.SignedLogRoot
+}
+
+func (s *subscribingLogStorage) Subscribe(treeID int64) (<-chan trillian.SignedLogRoot, func()) {
+	return s.pushed, func() {}
+}
+
+func TestWatchSignedLogRoots(t *testing.T) {
+	defer func(interval time.Duration) { WatchSignedLogRootsPollInterval = interval }(WatchSignedLogRootsPollInterval)
+
+	signedRoot9, err := fixedSigner.SignLogRoot(&types.LogRootV1{TimestampNanos: 987654322, RootHash: []byte("A NICER HASH"), TreeSize: 9, Revision: uint64(revision1 + 1)})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc     string
+		interval time.Duration
+		// polled are the roots read from storage, the last is repeated.
+		polled []*trillian.SignedLogRoot
+		// pushed are the roots pushed by storage, if it supports subscriptions.
+		pushed []*trillian.SignedLogRoot
+		want   []*trillian.SignedLogRoot
+	}{
+		{
+			desc:     "poll",
+			interval: time.Millisecond,
+			polled:   []*trillian.SignedLogRoot{signedRoot1, signedRoot1, signedRoot9},
+			want:     []*trillian.SignedLogRoot{signedRoot1, signedRoot9},
+		},
+		{
+			desc:     "push",
+			interval: time.Hour,
+			polled:   []*trillian.SignedLogRoot{signedRoot1},
+			pushed:   []*trillian.SignedLogRoot{signedRoot1, signedRoot9},
+			want:     []*trillian.SignedLogRoot{signedRoot1, signedRoot9},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			WatchSignedLogRootsPollInterval = test.interval
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storage.NewMockLogStorage(ctrl)
+			mockTX := storage.NewMockLogTreeTX(ctrl)
+			mockStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).MinTimes(1).Return(mockTX, nil)
+			for i, root := range test.polled {
+				call := mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*root, nil)
+				if i == len(test.polled)-1 {
+					call.AnyTimes()
+				}
+			}
+			mockTX.EXPECT().Commit().MinTimes(1).Return(nil)
+			mockTX.EXPECT().Close().MinTimes(1).Return(nil)
+
+			var ls storage.LogStorage = mockStorage
+			if test.pushed != nil {
+				pushed := make(chan trillian.SignedLogRoot, len(test.pushed))
+				for _, root := range test.pushed {
+					pushed <- *root
+				}
+				ls = &subscribingLogStorage{MockLogStorage: mockStorage, pushed: pushed}
+			}
+			registry := extension.Registry{
+				AdminStorage: fakeAdminStorage(ctrl, storageParams{logID1, false, 1}),
+				LogStorage:   ls,
+			}
+			server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream := &fakeWatchSignedLogRootsServer{ctx: ctx, cancel: cancel, want: len(test.want)}
+			err := server.WatchSignedLogRoots(&trillian.WatchSignedLogRootsRequest{LogId: logID1}, stream)
+			if got, want := status.Code(err), codes.Canceled; got != want {
+				t.Errorf("WatchSignedLogRoots()=%v, want code %v", err, want)
+			}
+			if got := len(stream.sent); got != len(test.want) {
+				t.Fatalf("WatchSignedLogRoots() sent %d roots, want %d", got, len(test.want))
+			}
+			for i, root := range stream.sent {
+				if !proto.Equal(root, test.want[i]) {
+					t.Errorf("WatchSignedLogRoots() sent root %d: %v, want %v", i, root, test.want[i])
+				}
+			}
+		})
+	}
+}
+
 func TestQueueLeavesStorageError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -629,7 +942,7 @@ func TestGetLeavesByHashStorageFails(t *testing.T) {
 
 	test := newParameterizedTest(ctrl, "GetLeavesByHash", readOnly, nopStorage,
 		func(t *storage.MockLogTreeTX) {
-			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("test"), []byte("data")}, false).Return(nil, errors.New("STORAGE"))
+			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("test"), []byte("data")}, false, int64(0), MaxByHashResults+1).Return(nil, errors.New("STORAGE"))
 		},
 		func(s *TrillianLogRPCServer) error {
 			_, err := s.GetLeavesByHash(context.Background(), &getByHashRequest1)
@@ -645,7 +958,7 @@ func TestLeavesByHashCommitFails(t *testing.T) {
 
 	test := newParameterizedTest(ctrl, "GetLeavesByHash", readOnly, nopStorage,
 		func(t *storage.MockLogTreeTX) {
-			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("test"), []byte("data")}, false).Return(nil, nil)
+			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("test"), []byte("data")}, false, int64(0), MaxByHashResults+1).Return(nil, nil)
 			t.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
 		},
 		func(s *TrillianLogRPCServer) error {
@@ -677,7 +990,7 @@ func TestGetLeavesByHash(t *testing.T) {
 	fakeStorage := storage.NewMockLogStorage(ctrl)
 	mockTX := storage.NewMockLogTreeTX(ctrl)
 	fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), tree1).Return(mockTX, nil)
-	mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("test"), []byte("data")}, false).Return([]*trillian.LogLeaf{leaf1, leaf3}, nil)
+	mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("test"), []byte("data")}, false, int64(0), MaxByHashResults+1).Return([]*trillian.LogLeaf{leaf1, leaf3}, nil)
 	mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
 	mockTX.EXPECT().Commit().Return(nil)
 	mockTX.EXPECT().Close().Return(nil)
@@ -698,6 +1011,117 @@ func TestGetLeavesByHash(t *testing.T) {
 	}
 }
 
+func TestGetLeavesByHashPaging(t *testing.T) {
+	defer func(max int64) { MaxByHashResults = max }(MaxByHashResults)
+	MaxByHashResults = 3
+
+	var leaves []*trillian.LogLeaf
+	for i := int64(0); i < 5; i++ {
+		leaves = append(leaves, newTestLeaf([]byte("dup"), nil, i))
+	}
+
+	for _, test := range []struct {
+		desc         string
+		maxResults   int64
+		pageToken    string
+		start, limit int64
+		stored       []*trillian.LogLeaf
+		want         []*trillian.LogLeaf
+		wantToken    string
+		wantCode     codes.Code
+	}{
+		{
+			desc:       "first page",
+			maxResults: 2,
+			limit:      3,
+			stored:     leaves[0:3],
+			want:       leaves[0:2],
+			wantToken:  encodeByHashPageToken(2),
+		},
+		{
+			desc:       "last page",
+			maxResults: 2,
+			pageToken:  encodeByHashPageToken(4),
+			start:      4,
+			limit:      3,
+			stored:     leaves[4:],
+			want:       leaves[4:],
+		},
+		{
+			desc:      "server limit",
+			limit:     4,
+			stored:    leaves[0:4],
+			want:      leaves[0:3],
+			wantToken: encodeByHashPageToken(3),
+		},
+		{
+			desc:       "over server limit",
+			maxResults: 10,
+			start:      3,
+			limit:      4,
+			pageToken:  encodeByHashPageToken(3),
+			stored:     leaves[3:],
+			want:       leaves[3:],
+		},
+		{
+			desc:      "invalid token",
+			pageToken: "not a token",
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			desc:      "negative token",
+			pageToken: encodeByHashPageToken(-1),
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			desc:       "negative max results",
+			maxResults: -1,
+			wantCode:   codes.InvalidArgument,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fakeStorage := storage.NewMockLogStorage(ctrl)
+			numSnapshots := 0
+			if test.wantCode == codes.OK {
+				numSnapshots = 1
+				mockTX := storage.NewMockLogTreeTX(ctrl)
+				fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), tree1).Return(mockTX, nil)
+				mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("dup")}, false, test.start, test.limit).Return(test.stored, nil)
+				mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
+				mockTX.EXPECT().Commit().Return(nil)
+				mockTX.EXPECT().Close().Return(nil)
+			}
+			registry := extension.Registry{
+				AdminStorage: fakeAdminStorage(ctrl, storageParams{treeID: logID1, numSnapshots: numSnapshots}),
+				LogStorage:   fakeStorage,
+			}
+			server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+			resp, err := server.GetLeavesByHash(context.Background(), &trillian.GetLeavesByHashRequest{
+				LogId:      logID1,
+				LeafHash:   [][]byte{[]byte("dup")},
+				MaxResults: test.maxResults,
+				PageToken:  test.pageToken,
+			})
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("GetLeavesByHash()=%v, want code %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			if got := resp.Leaves; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeavesByHash().Leaves=%v, want %v", got, test.want)
+			}
+			if got, want := resp.NextPageToken, test.wantToken; got != want {
+				t.Errorf("GetLeavesByHash().NextPageToken=%q, want %q", got, want)
+			}
+		})
+	}
+}
+
 func TestGetProofByHashBeginTXFails(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -718,7 +1142,7 @@ func TestGetProofByHashNoLeafForHash(t *testing.T) {
 
 	test := newParameterizedTest(ctrl, "GetInclusionProofByHash", readOnly, nopStorage,
 		func(t *storage.MockLogTreeTX) {
-			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false).Return(nil, errors.New("STORAGE"))
+			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false, int64(0), MaxByHashResults+1).Return(nil, errors.New("STORAGE"))
 		},
 		func(s *TrillianLogRPCServer) error {
 			_, err := s.GetInclusionProofByHash(context.Background(), &getInclusionProofByHashRequest25)
@@ -736,7 +1160,7 @@ func TestGetProofByHashGetNodesFails(t *testing.T) {
 		func(t *storage.MockLogTreeTX) {
 			t.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
 			t.EXPECT().ReadRevision().Return(int64(root1.Revision))
-			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
+			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false, int64(0), MaxByHashResults+1).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
 			t.EXPECT().GetMerkleNodes(gomock.Any(), revision1, nodeIdsInclusionSize7Index2).Return([]storage.Node{}, errors.New("STORAGE"))
 		},
 		func(s *TrillianLogRPCServer) error {
@@ -757,7 +1181,7 @@ func TestGetProofByHashWrongNodeCountFetched(t *testing.T) {
 
 	mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
 	mockTX.EXPECT().ReadRevision().Return(int64(root1.Revision))
-	mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
+	mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false, int64(0), MaxByHashResults+1).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
 	// The server expects three nodes from storage but we return only two
 	mockTX.EXPECT().GetMerkleNodes(gomock.Any(), revision1, nodeIdsInclusionSize7Index2).Return([]storage.Node{{NodeRevision: 3}, {NodeRevision: 2}}, nil)
 	mockTX.EXPECT().Close().Return(nil)
@@ -784,7 +1208,7 @@ func TestGetProofByHashWrongNodeReturned(t *testing.T) {
 
 	mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
 	mockTX.EXPECT().ReadRevision().Return(int64(root1.Revision))
-	mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
+	mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false, int64(0), MaxByHashResults+1).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
 	// We set this up so one of the returned nodes has the wrong ID
 	mockTX.EXPECT().GetMerkleNodes(gomock.Any(), revision1, nodeIdsInclusionSize7Index2).Return([]storage.Node{{NodeID: nodeIdsInclusionSize7Index2[0], NodeRevision: 3}, {NodeID: stestonly.MustCreateNodeIDForTreeCoords(4, 5, 64), NodeRevision: 2}, {NodeID: nodeIdsInclusionSize7Index2[2], NodeRevision: 3}}, nil)
 	mockTX.EXPECT().Close().Return(nil)
@@ -809,7 +1233,7 @@ func TestGetProofByHashCommitFails(t *testing.T) {
 		func(t *storage.MockLogTreeTX) {
 			t.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
 			t.EXPECT().ReadRevision().Return(int64(root1.Revision))
-			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
+			t.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false, int64(0), MaxByHashResults+1).Return([]*trillian.LogLeaf{{LeafIndex: 2}}, nil)
 			t.EXPECT().GetMerkleNodes(gomock.Any(), revision1, nodeIdsInclusionSize7Index2).Return([]storage.Node{{NodeID: nodeIdsInclusionSize7Index2[0], NodeRevision: 3}, {NodeID: nodeIdsInclusionSize7Index2[1], NodeRevision: 2}, {NodeID: nodeIdsInclusionSize7Index2[2], NodeRevision: 3}}, nil)
 		},
 		func(s *TrillianLogRPCServer) error {
@@ -824,11 +1248,31 @@ func TestGetProofByHash(t *testing.T) {
 	ctx := context.Background()
 	for _, tc := range []struct {
 		desc            string
+		maxResults      int64
+		pageToken       string
 		wantCode        codes.Code
+		wantStart       int64
+		wantLimit       int64
 		leavesByHashVal []*trillian.LogLeaf
+		wantNextToken   string
 	}{
-		{desc: "OK", leavesByHashVal: []*trillian.LogLeaf{{LeafIndex: 2}}},
-		{desc: "NotFoundTreeSize", wantCode: codes.NotFound, leavesByHashVal: []*trillian.LogLeaf{{LeafIndex: 7}}},
+		{desc: "OK", wantLimit: MaxByHashResults + 1, leavesByHashVal: []*trillian.LogLeaf{{LeafIndex: 2}}},
+		{desc: "NotFoundTreeSize", wantCode: codes.NotFound, wantLimit: MaxByHashResults + 1, leavesByHashVal: []*trillian.LogLeaf{{LeafIndex: 7}}},
+		{
+			desc:            "MorePages",
+			maxResults:      1,
+			wantLimit:       2,
+			leavesByHashVal: []*trillian.LogLeaf{{LeafIndex: 2}, {LeafIndex: 3}},
+			wantNextToken:   encodeByHashPageToken(3),
+		},
+		{
+			desc:            "LastPageInTreeSize",
+			maxResults:      1,
+			pageToken:       encodeByHashPageToken(2),
+			wantStart:       2,
+			wantLimit:       2,
+			leavesByHashVal: []*trillian.LogLeaf{{LeafIndex: 2}, {LeafIndex: 9}},
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
@@ -839,7 +1283,7 @@ func TestGetProofByHash(t *testing.T) {
 			fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), tree1).Return(mockTX, nil)
 
 			mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*signedRoot1, nil)
-			mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false).Return(tc.leavesByHashVal, nil)
+			mockTX.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{[]byte("ahash")}, false, tc.wantStart, tc.wantLimit).Return(tc.leavesByHashVal, nil)
 			mockTX.EXPECT().ReadRevision().Return(int64(root1.Revision)).AnyTimes()
 			mockTX.EXPECT().GetMerkleNodes(gomock.Any(), revision1, nodeIdsInclusionSize7Index2).Return([]storage.Node{
 				{NodeID: nodeIdsInclusionSize7Index2[0], NodeRevision: 3, Hash: []byte("nodehash0")},
@@ -859,9 +1303,11 @@ func TestGetProofByHash(t *testing.T) {
 
 			proofResponse, err := server.GetInclusionProofByHash(ctx,
 				&trillian.GetInclusionProofByHashRequest{
-					LogId:    logID1,
-					TreeSize: 7,
-					LeafHash: []byte("ahash"),
+					LogId:      logID1,
+					TreeSize:   7,
+					LeafHash:   []byte("ahash"),
+					MaxResults: tc.maxResults,
+					PageToken:  tc.pageToken,
 				})
 			if got, want := status.Code(err), tc.wantCode; got != want {
 				t.Fatalf("GetInclusionProofByHash(): %v, want %v", err, want)
@@ -886,6 +1332,12 @@ func TestGetProofByHash(t *testing.T) {
 			if !proto.Equal(proofResponse.Proof[0], &expectedProof) {
 				t.Fatalf("expected proof: %v but got: %v", expectedProof, proofResponse.Proof[0])
 			}
+			if got, want := len(proofResponse.Proof), 1; got != want {
+				t.Errorf("got %d proofs, want %d", got, want)
+			}
+			if got, want := proofResponse.NextPageToken, tc.wantNextToken; got != want {
+				t.Errorf("NextPageToken=%q, want %q", got, want)
+			}
 		})
 	}
 }
@@ -1711,6 +2163,24 @@ func TestTrillianLogRPCServer_GetInclusionProofByHashErrors(t *testing.T) {
 				TreeSize: -20,
 			},
 		},
+		{
+			desc: "badMaxResults",
+			req: &trillian.GetInclusionProofByHashRequest{
+				LogId:      1,
+				LeafHash:   []byte("32.bytes.hash..................."),
+				TreeSize:   20,
+				MaxResults: -1,
+			},
+		},
+		{
+			desc: "badPageToken",
+			req: &trillian.GetInclusionProofByHashRequest{
+				LogId:     1,
+				LeafHash:  []byte("32.bytes.hash..................."),
+				TreeSize:  20,
+				PageToken: "?",
+			},
+		},
 	}
 
 	logServer := NewTrillianLogRPCServer(extension.Registry{}, fakeTimeSource)
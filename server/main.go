@@ -16,6 +16,11 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"time"
@@ -48,6 +53,12 @@ const (
 	// hard-deleting them.
 	// Actual runs happen randomly between [minInterval,2*minInterval).
 	DefaultTreeDeleteMinInterval = 4 * time.Hour
+
+	// DefaultMapRevisionGCMinInterval is the suggested min interval between map revision GC
+	// sweeps. A map revision GC sweep consists of pruning the revisions of each map which are not
+	// kept by the MapRetentionPolicy.
+	// Actual runs happen randomly between [minInterval,2*minInterval).
+	DefaultMapRevisionGCMinInterval = 1 * time.Hour
 )
 
 // Main encapsulates the data and logic to start a Trillian server (Log or Map).
@@ -58,6 +69,15 @@ type Main struct {
 
 	// TLS Certificate and Key files for the server.
 	TLSCertFile, TLSKeyFile string
+	// TLSClientCAFile is the file of CA certificates used to verify client
+	// certificates. If set, clients must authenticate with mutual TLS, and
+	// the REST gateway is not started: it would have to call the RPC server
+	// under a single identity of its own, hiding who the REST caller is.
+	// HTTPEndpoint then only serves metrics and health checks.
+	TLSClientCAFile string
+
+	// Authorizer, if set, decides which RPCs callers may make.
+	Authorizer interceptor.Authorizer
 
 	DBClose func() error
 
@@ -87,6 +107,15 @@ type Main struct {
 	TreeDeleteThreshold   time.Duration
 	TreeDeleteMinInterval time.Duration
 
+	// MapRetention determines which map revisions are garbage collected. The zero
+	// value keeps all revisions, and map revision GC doesn't run.
+	MapRetention             MapRetentionPolicy
+	MapRevisionGCMinInterval time.Duration
+
+	// MapSigner configures the signer which writes the leaves queued by the
+	// QueueLeaves RPC to maps. The signer only runs if MapSigner.RunInterval > 0.
+	MapSigner MapSignerOptions
+
 	// These will be added to the GRPC server options.
 	ExtraOptions []grpc.ServerOption
 }
@@ -123,20 +152,28 @@ func (m *Main) Run(ctx context.Context) error {
 	if err := m.RegisterServerFn(srv, m.Registry); err != nil {
 		return err
 	}
-	trillian.RegisterTrillianAdminServer(srv, admin.New(m.Registry, m.AllowedTreeTypes))
+	adminServer := admin.New(m.Registry, m.AllowedTreeTypes)
+	if m.Authorizer != nil {
+		adminServer.SetAuthorizer(m.Authorizer)
+	}
+	trillian.RegisterTrillianAdminServer(srv, adminServer)
 	reflection.Register(srv)
 
 	if endpoint := m.HTTPEndpoint; endpoint != "" {
-		gatewayMux := runtime.NewServeMux()
-		opts := []grpc.DialOption{grpc.WithInsecure()}
-		if err := m.RegisterHandlerFn(ctx, gatewayMux, m.RPCEndpoint, opts); err != nil {
-			return err
-		}
-		if err := trillian.RegisterTrillianAdminHandlerFromEndpoint(ctx, gatewayMux, m.RPCEndpoint, opts); err != nil {
-			return err
+		if m.TLSClientCAFile == "" {
+			gatewayMux := runtime.NewServeMux()
+			opts := []grpc.DialOption{grpc.WithInsecure()}
+			if err := m.RegisterHandlerFn(ctx, gatewayMux, m.RPCEndpoint, opts); err != nil {
+				return err
+			}
+			if err := trillian.RegisterTrillianAdminHandlerFromEndpoint(ctx, gatewayMux, m.RPCEndpoint, opts); err != nil {
+				return err
+			}
+			http.Handle("/", gatewayMux)
+		} else {
+			glog.Warningf("REST gateway disabled: clients must use mutual TLS over gRPC")
 		}
 
-		http.Handle("/", gatewayMux)
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/healthz", m.healthz)
 
@@ -176,6 +213,27 @@ func (m *Main) Run(ctx context.Context) error {
 		}()
 	}
 
+	if m.MapRetention.KeepRevisions > 0 || m.MapRetention.KeepDuration > 0 {
+		go func() {
+			glog.Info("Map revision GC started")
+			gc := NewMapRevisionGC(
+				m.Registry.AdminStorage,
+				m.Registry.MapStorage,
+				m.MapRetention,
+				m.MapRevisionGCMinInterval,
+				util.SystemTimeSource{},
+				m.Registry.MetricFactory)
+			gc.Run(ctx)
+		}()
+	}
+
+	if m.MapSigner.RunInterval > 0 {
+		go func() {
+			glog.Info("Map signer started")
+			NewMapSigner(m.Registry, m.MapSigner).Run(ctx)
+		}()
+	}
+
 	if err := srv.Serve(lis); err != nil {
 		glog.Errorf("RPC server terminated: %v", err)
 	}
@@ -195,10 +253,15 @@ func (m *Main) newGRPCServer() (*grpc.Server, error) {
 	stats := monitoring.NewRPCStatsInterceptor(ts, m.StatsPrefix, m.Registry.MetricFactory)
 	ti := interceptor.New(
 		m.Registry.AdminStorage, m.Registry.QuotaManager, m.QuotaDryRun, m.Registry.MetricFactory)
+	if m.Authorizer != nil {
+		ti.SetAuthorizer(m.Authorizer)
+	}
 	netInterceptor := interceptor.Combine(stats.Interceptor(), interceptor.ErrorWrapper, ti.UnaryInterceptor)
+	streamInterceptor := interceptor.CombineStream(interceptor.StreamErrorWrapper, ti.StreamInterceptor)
 
 	serverOpts := []grpc.ServerOption{
 		grpc.UnaryInterceptor(netInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
 	}
 	serverOpts = append(serverOpts, m.ExtraOptions...)
 
@@ -208,7 +271,14 @@ func (m *Main) newGRPCServer() (*grpc.Server, error) {
 		if err != nil {
 			return nil, err
 		}
+		if m.TLSClientCAFile != "" {
+			if serverCreds, err = m.mutualTLSCreds(); err != nil {
+				return nil, err
+			}
+		}
 		serverOpts = append(serverOpts, grpc.Creds(serverCreds))
+	} else if m.TLSClientCAFile != "" {
+		return nil, errors.New("client CAs require a TLS certificate and key")
 	}
 
 	s := grpc.NewServer(serverOpts...)
@@ -216,6 +286,28 @@ func (m *Main) newGRPCServer() (*grpc.Server, error) {
 	return s, nil
 }
 
+// mutualTLSCreds returns server credentials which require clients to present
+// a certificate issued by one of the CAs in m.TLSClientCAFile.
+func (m *Main) mutualTLSCreds() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(m.TLSCertFile, m.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	pem, err := ioutil.ReadFile(m.TLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CAs: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %v", m.TLSClientCAFile)
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}), nil
+}
+
 // AnnounceSelf announces this binary's presence to etcd.  Returns a function that
 // should be called on process exit.
 // AnnounceSelf does nothing if client is nil.
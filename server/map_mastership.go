@@ -0,0 +1,353 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util"
+	"github.com/google/trillian/util/election"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MapMasterMetadataKey is the gRPC trailer set by map writes which are
+// rejected because the serving instance isn't master for the map. Its value is
+// the ID of the current master, if known, so clients can redirect the write.
+const MapMasterMetadataKey = "trillian-map-master"
+
+var (
+	// MapElectionWait is how long writes to a map wait for this instance to
+	// win the map's master election, if the election has only just started.
+	MapElectionWait = 5 * time.Second
+
+	mapMastershipMetricsOnce sync.Once
+	mapIsMaster              monitoring.Gauge
+	mapResignations          monitoring.Counter
+)
+
+// MapMastership holds a master election for each map written through this
+// instance, so that only one instance writes to each map at a time. Elections
+// are started for the active maps found by Run, or else the first time
+// mastership of a map is checked, and are ended once their maps are no longer
+// active.
+//
+// A MapMastership with a nil election factory is master for every map.
+type MapMastership struct {
+	factory election.Factory
+	cfg     election.RunnerConfig
+
+	// ctx is the context elections run in, it's cancelled by Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	maps     map[string]*mapElection
+	tracker  *election.MasterTracker
+	runnerWG sync.WaitGroup
+
+	pendingResignations chan election.Resignation
+}
+
+// mapElection holds the election for a single map.
+type mapElection struct {
+	runner   *election.Runner
+	election election.MasterElection
+	// started is when the election started. won is closed once this instance
+	// first wins it, and done once the runner has returned.
+	started time.Time
+	won     chan struct{}
+	wonOnce sync.Once
+	done    chan struct{}
+
+	// writeMu is held for reading by writes to the map in progress, and for
+	// writing while resigning mastership of it, so that no writes are in
+	// progress when another instance becomes master.
+	writeMu sync.RWMutex
+}
+
+// NewMapMastership returns a new MapMastership which holds elections created
+// by factory, configured by cfg. Close must be called to end the elections.
+func NewMapMastership(factory election.Factory, cfg election.RunnerConfig, mf monitoring.MetricFactory) *MapMastership {
+	mapMastershipMetricsOnce.Do(func() {
+		if mf == nil {
+			mf = monitoring.InertMetricFactory{}
+		}
+		mapIsMaster = mf.NewGauge("map_is_master", "Whether this instance is master for the map (0/1)", monitoring.TreeIDLabel)
+		mapResignations = mf.NewCounter("map_master_resignations", "Number of map mastership resignations", monitoring.TreeIDLabel)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &MapMastership{
+		factory: factory,
+		cfg:     cfg,
+		ctx:     ctx,
+		cancel:  cancel,
+		maps:    make(map[string]*mapElection),
+
+		pendingResignations: make(chan election.Resignation, 100),
+	}
+	// The tracker is notified by the runners, so that writes waiting for a
+	// new election can go ahead as soon as it's won.
+	m.tracker = election.NewMasterTracker(nil, func(id string, v bool) {
+		val := 0.0
+		if v {
+			val = 1.0
+			m.mu.Lock()
+			if me := m.maps[id]; me != nil {
+				me.wonOnce.Do(func() { close(me.won) })
+			}
+			m.mu.Unlock()
+		}
+		mapIsMaster.Set(val, id)
+	})
+	if factory != nil {
+		go m.resign()
+	}
+	return m
+}
+
+// resign executes resignations once no writes to their maps are in progress.
+func (m *MapMastership) resign() {
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case r := <-m.pendingResignations:
+			m.mu.Lock()
+			me := m.maps[r.ID]
+			m.mu.Unlock()
+			if me == nil {
+				// The election has been ended, but its runner still waits for
+				// the resignation to be executed.
+				r.Execute(m.ctx)
+				continue
+			}
+			me.writeMu.Lock()
+			mapResignations.Inc(r.ID)
+			r.Execute(m.ctx)
+			me.writeMu.Unlock()
+		}
+	}
+}
+
+// election returns the election for mapID, starting it if needed.
+func (m *MapMastership) election(mapID int64) (*mapElection, error) {
+	id := strconv.FormatInt(mapID, 10)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if me := m.maps[id]; me != nil {
+		return me, nil
+	}
+
+	glog.Infof("create master election goroutine for map %v", id)
+	innerCtx, cancel := context.WithCancel(m.ctx)
+	el, err := m.factory.NewElection(innerCtx, id)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create election for %v: %v", id, err)
+	}
+	r := election.NewRunner(id, &m.cfg, m.tracker, cancel, el)
+	me := &mapElection{
+		runner:   r,
+		election: el,
+		started:  time.Now(),
+		won:      make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	m.maps[id] = me
+	m.runnerWG.Add(1)
+	go func() {
+		defer m.runnerWG.Done()
+		defer close(me.done)
+		r.Run(innerCtx, m.pendingResignations)
+	}()
+	return me, nil
+}
+
+// Run keeps the elections in step with the maps in admin, until ctx is
+// cancelled: every interval it starts elections for new active maps, so that
+// they have a master by the time they're first written to, and ends the
+// elections of maps which have been deleted or frozen.
+func (m *MapMastership) Run(ctx context.Context, admin storage.AdminStorage, interval time.Duration) {
+	if m == nil || m.factory == nil {
+		return
+	}
+	for {
+		trees, err := storage.ListTrees(ctx, admin, storage.ListTreesOptions{})
+		if err != nil {
+			glog.Errorf("MapMastership.Run: error listing trees: %v", err)
+		} else {
+			m.updateMaps(trees)
+		}
+		if err := util.SleepContext(ctx, interval); err != nil {
+			return
+		}
+	}
+}
+
+// updateMaps starts elections for the active maps in trees, and ends all
+// other elections.
+func (m *MapMastership) updateMaps(trees []*trillian.Tree) {
+	active := make(map[string]bool)
+	for _, tree := range trees {
+		if tree.TreeType != trillian.TreeType_MAP || tree.TreeState != trillian.TreeState_ACTIVE {
+			continue
+		}
+		active[strconv.FormatInt(tree.TreeId, 10)] = true
+		if _, err := m.election(tree.TreeId); err != nil {
+			glog.Errorf("MapMastership: %v", err)
+		}
+	}
+
+	ended := make(map[string]*mapElection)
+	m.mu.Lock()
+	for id, me := range m.maps {
+		if !active[id] {
+			glog.Infof("end master election for inactive map %v", id)
+			me.runner.Cancel()
+			delete(m.maps, id)
+			ended[id] = me
+		}
+	}
+	m.mu.Unlock()
+
+	// Once their runners have returned, the ended elections can no longer
+	// be won, so mastership of their maps is given up.
+	for id, me := range ended {
+		<-me.done
+		if mapID, err := strconv.ParseInt(id, 10, 64); err == nil && m.isMaster(mapID) {
+			m.tracker.Set(id, false)
+		}
+	}
+}
+
+// IsMaster returns whether this instance is master for mapID.
+func (m *MapMastership) IsMaster(mapID int64) (bool, error) {
+	if m == nil || m.factory == nil {
+		return true, nil
+	}
+	if _, err := m.election(mapID); err != nil {
+		return false, err
+	}
+	return m.isMaster(mapID), nil
+}
+
+// isMaster returns whether this instance is master for mapID, whose election
+// must have been started.
+func (m *MapMastership) isMaster(mapID int64) bool {
+	id := strconv.FormatInt(mapID, 10)
+	for _, h := range m.tracker.Held() {
+		if h == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Master returns the ID of the instance which is master for mapID. It returns
+// an empty ID if elections aren't held.
+func (m *MapMastership) Master(ctx context.Context, mapID int64) (string, error) {
+	if m == nil || m.factory == nil {
+		return "", nil
+	}
+	me, err := m.election(mapID)
+	if err != nil {
+		return "", err
+	}
+	return me.election.GetCurrentMaster(ctx)
+}
+
+// acquire returns whether this instance is master for mapID, and if so
+// prevents it from resigning until release is called.
+func (m *MapMastership) acquire(mapID int64) (release func(), isMaster bool, err error) {
+	if m == nil || m.factory == nil {
+		return func() {}, true, nil
+	}
+	me, err := m.election(mapID)
+	if err != nil {
+		return nil, false, err
+	}
+	me.writeMu.RLock()
+	if !m.isMaster(mapID) {
+		me.writeMu.RUnlock()
+		return nil, false, nil
+	}
+	return me.writeMu.RUnlock, true, nil
+}
+
+// AcquireWrite checks that this instance is master for mapID, and if so
+// prevents it from resigning until the returned release func is called. If
+// the map's election has only just started, it waits up to MapElectionWait
+// from the start for this instance to win it. If this instance isn't master
+// it returns an Unavailable error naming the master, which is also set in the
+// MapMasterMetadataKey trailer.
+func (m *MapMastership) AcquireWrite(ctx context.Context, mapID int64) (func(), error) {
+	release, isMaster, err := m.acquire(mapID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMaster {
+		me, err := m.election(mapID)
+		if err != nil {
+			return nil, err
+		}
+		if wait := MapElectionWait - time.Since(me.started); wait > 0 {
+			select {
+			case <-me.won:
+				release, isMaster, err = m.acquire(mapID)
+				if err != nil {
+					return nil, err
+				}
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+		}
+	}
+	if isMaster {
+		return release, nil
+	}
+
+	master, err := m.Master(ctx, mapID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "not master for map %v, and the master is unknown: %v", mapID, err)
+	}
+	// Outside of a gRPC server there's no trailer to set, so errors are ignored.
+	grpc.SetTrailer(ctx, metadata.Pairs(MapMasterMetadataKey, master))
+	return nil, status.Errorf(codes.Unavailable, "not master for map %v, master is %q", mapID, master)
+}
+
+// Close ends the elections.
+func (m *MapMastership) Close() {
+	m.mu.Lock()
+	for _, me := range m.maps {
+		me.runner.Cancel()
+	}
+	m.mu.Unlock()
+	// Runners may be waiting for their resignations to be executed, so only
+	// stop executing them once the runners have returned.
+	m.runnerWG.Wait()
+	m.cancel()
+}
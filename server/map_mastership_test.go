@@ -0,0 +1,316 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/util"
+	"github.com/google/trillian/util/election"
+	"github.com/google/trillian/util/election/stub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+const otherMaster = "other.example.com:8090"
+
+// otherMasterElection is an election which another instance has won.
+type otherMasterElection struct {
+	*stub.MasterElection
+}
+
+func (otherMasterElection) GetCurrentMaster(context.Context) (string, error) {
+	return otherMaster, nil
+}
+
+type otherMasterFactory struct{}
+
+func (otherMasterFactory) NewElection(ctx context.Context, treeID string) (election.MasterElection, error) {
+	return otherMasterElection{stub.NewMasterElection(false, nil)}, nil
+}
+
+type selfMasterFactory struct{}
+
+func (selfMasterFactory) NewElection(ctx context.Context, treeID string) (election.MasterElection, error) {
+	return stub.NewMasterElection(true, nil), nil
+}
+
+// waitForMaster waits until m is master for mapID, as elections complete
+// asynchronously.
+func waitForMaster(t *testing.T, m *MapMastership, mapID int64) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		isMaster, err := m.IsMaster(mapID)
+		if err != nil {
+			t.Fatalf("IsMaster(): %v", err)
+		}
+		if isMaster {
+			return
+		}
+	}
+	t.Fatalf("IsMaster(): not master for map %v after 5s", mapID)
+}
+
+func TestMapMastership_NoElection(t *testing.T) {
+	ctx := context.Background()
+	for _, m := range []*MapMastership{nil, NewMapMastership(nil, election.RunnerConfig{}, nil)} {
+		if isMaster, err := m.IsMaster(mapID1); !isMaster || err != nil {
+			t.Errorf("IsMaster(): (%v, %v), want (true, nil)", isMaster, err)
+		}
+		if master, err := m.Master(ctx, mapID1); master != "" || err != nil {
+			t.Errorf("Master(): (%q, %v), want (\"\", nil)", master, err)
+		}
+		release, err := m.AcquireWrite(ctx, mapID1)
+		if err != nil {
+			t.Fatalf("AcquireWrite(): %v", err)
+		}
+		release()
+	}
+}
+
+func TestMapMastership_Master(t *testing.T) {
+	ctx := context.Background()
+	m := NewMapMastership(selfMasterFactory{}, election.RunnerConfig{}, nil)
+	defer m.Close()
+
+	waitForMaster(t, m, mapID1)
+	release, err := m.AcquireWrite(ctx, mapID1)
+	if err != nil {
+		t.Fatalf("AcquireWrite(): %v", err)
+	}
+	release()
+}
+
+func TestMapMastership_NotMaster(t *testing.T) {
+	defer func(wait time.Duration) {
+		MapElectionWait = wait
+	}(MapElectionWait)
+	MapElectionWait = 100 * time.Millisecond
+
+	ctx := context.Background()
+	m := NewMapMastership(otherMasterFactory{}, election.RunnerConfig{}, nil)
+	defer m.Close()
+
+	if isMaster, err := m.IsMaster(mapID1); isMaster || err != nil {
+		t.Errorf("IsMaster(): (%v, %v), want (false, nil)", isMaster, err)
+	}
+	if master, err := m.Master(ctx, mapID1); master != otherMaster || err != nil {
+		t.Errorf("Master(): (%q, %v), want (%q, nil)", master, err, otherMaster)
+	}
+	_, err := m.AcquireWrite(ctx, mapID1)
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("AcquireWrite(): %v, want code %v", err, codes.Unavailable)
+	}
+	if err == nil || !strings.Contains(err.Error(), otherMaster) {
+		t.Errorf("AcquireWrite(): %v, want error naming master %q", err, otherMaster)
+	}
+}
+
+func TestMapMastership_NewElection(t *testing.T) {
+	ctx := context.Background()
+	m := NewMapMastership(selfMasterFactory{}, election.RunnerConfig{PreElectionPause: time.Second}, nil)
+	defer m.Close()
+
+	// The first write starts the election, and waits for it to be won.
+	release, err := m.AcquireWrite(ctx, mapID1)
+	if err != nil {
+		t.Fatalf("AcquireWrite(): %v", err)
+	}
+	release()
+}
+
+func TestMapMastership_Run(t *testing.T) {
+	ctx := context.Background()
+	admin := memory.NewAdminStorage(memory.NewLogStorage(nil))
+	var mapIDs []int64
+	for i := 0; i < 2; i++ {
+		tree, err := storage.CreateTree(ctx, admin, stestonly.MapTree)
+		if err != nil {
+			t.Fatalf("CreateTree(): %v", err)
+		}
+		mapIDs = append(mapIDs, tree.TreeId)
+	}
+	logTree, err := storage.CreateTree(ctx, admin, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	m := NewMapMastership(selfMasterFactory{}, election.RunnerConfig{}, nil)
+	defer m.Close()
+	// runOnce makes a single pass of m.Run, which returns once its context is
+	// cancelled.
+	runOnce := func() {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		m.Run(ctx, admin, time.Hour)
+	}
+
+	// waitFor waits until the elections held are as wanted, without checking
+	// mastership through m, which would start elections itself.
+	waitFor := func(desc string, want map[int64]bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			m.mu.Lock()
+			held := len(m.maps) == len(want)
+			for id := range want {
+				held = held && m.maps[strconv.FormatInt(id, 10)] != nil
+			}
+			m.mu.Unlock()
+			for id, isMaster := range want {
+				held = held && m.isMaster(id) == isMaster
+			}
+			if held {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%v: elections not as wanted after 5s: %v", desc, m.tracker)
+			}
+		}
+	}
+
+	// Elections start for the maps before they're written to.
+	runOnce()
+	waitFor("started", map[int64]bool{mapIDs[0]: true, mapIDs[1]: true})
+	if m.isMaster(logTree.TreeId) {
+		t.Errorf("isMaster(%v): true for a log", logTree.TreeId)
+	}
+
+	// The election of a frozen map ends, and this instance stops being its
+	// master.
+	if _, err := storage.UpdateTree(ctx, admin, mapIDs[1], func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_FROZEN
+	}); err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	runOnce()
+	waitFor("frozen", map[int64]bool{mapIDs[0]: true})
+	if m.isMaster(mapIDs[1]) {
+		t.Errorf("isMaster(%v): true for a frozen map", mapIDs[1])
+	}
+
+	// As does the election of a deleted map. Memory storage doesn't support
+	// deleting trees, but deleted trees aren't listed anyway.
+	m.updateMaps(nil)
+	waitFor("deleted", map[int64]bool{})
+	if m.isMaster(mapIDs[0]) {
+		t.Errorf("isMaster(%v): true for a deleted map", mapIDs[0])
+	}
+}
+
+func TestMapMastership_ResignOneMap(t *testing.T) {
+	const mapID2 = mapID1 + 1
+	ctx := context.Background()
+	ts := util.NewFakeTimeSource(time.Now())
+	m := NewMapMastership(selfMasterFactory{}, election.RunnerConfig{ResignOdds: 1, TimeSource: ts}, nil)
+	defer m.Close()
+
+	// Hold a write to map 1 while its mastership is due to be resigned.
+	waitForMaster(t, m, mapID1)
+	release1, err := m.AcquireWrite(ctx, mapID1)
+	if err != nil {
+		t.Fatalf("AcquireWrite(%v): %v", mapID1, err)
+	}
+	defer release1()
+	ts.Set(ts.Now().Add(time.Hour))
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if isMaster, err := m.IsMaster(mapID1); err != nil || !isMaster {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("IsMaster(%v): still master after 5s", mapID1)
+		}
+	}
+
+	// The pending resignation only waits for writes to map 1, so writes to
+	// map 2 can go ahead.
+	waitForMaster(t, m, mapID2)
+	done := make(chan error, 1)
+	go func() {
+		release2, err := m.AcquireWrite(ctx, mapID2)
+		if err == nil {
+			release2()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("AcquireWrite(%v): %v", mapID2, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("AcquireWrite(%v): blocked by the resignation of map %v", mapID2, mapID1)
+	}
+}
+
+func TestSetLeaves_NotMaster(t *testing.T) {
+	defer func(wait time.Duration) {
+		MapElectionWait = wait
+	}(MapElectionWait)
+	MapElectionWait = 100 * time.Millisecond
+
+	ctx := context.Background()
+	_, registry, mapID := newQueueingMap(ctx, t)
+	m := NewMapMastership(otherMasterFactory{}, election.RunnerConfig{}, nil)
+	defer m.Close()
+	server := NewTrillianMapServerWithMastership(registry, m)
+
+	leaves := []*trillian.MapLeaf{{Index: bytes.Repeat([]byte{0xa}, 32), LeafValue: []byte("A")}}
+	if _, err := server.SetLeaves(ctx, &trillian.SetMapLeavesRequest{MapId: mapID, Leaves: leaves}); status.Code(err) != codes.Unavailable {
+		t.Errorf("SetLeaves(): %v, want code %v", err, codes.Unavailable)
+	}
+	// Queued writes are accepted by any instance.
+	if _, err := server.QueueLeaves(ctx, &trillian.QueueMapLeavesRequest{MapId: mapID, Leaves: leaves}); err != nil {
+		t.Errorf("QueueLeaves(): %v", err)
+	}
+
+	resp, err := server.GetMapStatus(ctx, &trillian.GetMapStatusRequest{MapId: mapID})
+	if err != nil {
+		t.Fatalf("GetMapStatus(): %v", err)
+	}
+	if resp.IsMaster || resp.Master != otherMaster {
+		t.Errorf("GetMapStatus(): %+v, want master %q", resp, otherMaster)
+	}
+}
+
+func TestSetLeaves_Master(t *testing.T) {
+	ctx := context.Background()
+	_, registry, mapID := newQueueingMap(ctx, t)
+	m := NewMapMastership(selfMasterFactory{}, election.RunnerConfig{}, nil)
+	defer m.Close()
+	server := NewTrillianMapServerWithMastership(registry, m)
+	waitForMaster(t, m, mapID)
+
+	leaves := []*trillian.MapLeaf{{Index: bytes.Repeat([]byte{0xa}, 32), LeafValue: []byte("A")}}
+	if _, err := server.SetLeaves(ctx, &trillian.SetMapLeavesRequest{MapId: mapID, Leaves: leaves}); err != nil {
+		t.Errorf("SetLeaves(): %v", err)
+	}
+	resp, err := server.GetMapStatus(ctx, &trillian.GetMapStatusRequest{MapId: mapID})
+	if err != nil {
+		t.Fatalf("GetMapStatus(): %v", err)
+	}
+	if !resp.IsMaster || resp.Master != "self" {
+		t.Errorf("GetMapStatus(): %+v, want master self", resp)
+	}
+}
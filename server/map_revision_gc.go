@@ -0,0 +1,240 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	mapPruneCounter  monitoring.Counter
+	mapGCMetricsOnce sync.Once
+)
+
+// MapRetentionPolicy determines which revisions of a map are kept by
+// MapRevisionGC. A revision is kept if either condition holds, and the latest
+// revision of a map is always kept. If neither is set, all revisions are kept.
+type MapRetentionPolicy struct {
+	// KeepRevisions is the number of most recent revisions kept, if > 0.
+	KeepRevisions int64
+	// KeepDuration is the age of the oldest revisions kept, if > 0. The age of
+	// a revision is taken from the timestamp of its root.
+	KeepDuration time.Duration
+}
+
+// MapRevisionGC garbage collects the revisions of maps which are no longer
+// kept by a MapRetentionPolicy. Once garbage collected, a revision's root and
+// the leaf and subtree data only needed to read it are deleted, and reads at
+// that revision fail with NotFound.
+//
+// It requires a MapStorage which implements storage.MapRevisionPruner.
+type MapRevisionGC struct {
+	admin      storage.AdminStorage
+	maps       storage.MapStorage
+	policy     MapRetentionPolicy
+	timeSource util.TimeSource
+
+	// minRunInterval defines how frequently sweeps for old revisions are
+	// performed. Actual runs happen randomly between [minInterval,2*minInterval).
+	minRunInterval time.Duration
+
+	// pruned holds the revision each map was last pruned up to, so maps that
+	// haven't advanced since aren't pruned again.
+	pruned map[int64]int64
+}
+
+// NewMapRevisionGC returns a new MapRevisionGC.
+func NewMapRevisionGC(admin storage.AdminStorage, maps storage.MapStorage, policy MapRetentionPolicy, minRunInterval time.Duration, ts util.TimeSource, mf monitoring.MetricFactory) *MapRevisionGC {
+	mapGCMetricsOnce.Do(func() {
+		if mf == nil {
+			mf = monitoring.InertMetricFactory{}
+		}
+		mapPruneCounter = mf.NewCounter("map_revision_prune_counter", "Counter of map revision garbage collection runs", monitoring.TreeIDLabel, "success")
+	})
+	return &MapRevisionGC{
+		admin:          admin,
+		maps:           maps,
+		policy:         policy,
+		timeSource:     ts,
+		minRunInterval: minRunInterval,
+		pruned:         make(map[int64]int64),
+	}
+}
+
+// Run starts the map revision garbage collection process. It runs until ctx
+// is cancelled.
+func (gc *MapRevisionGC) Run(ctx context.Context) {
+	for {
+		count, err := gc.RunOnce(ctx)
+		if err != nil {
+			glog.Errorf("MapRevisionGC.Run: %v", err)
+		}
+		if count > 0 {
+			glog.Infof("MapRevisionGC.Run: successfully pruned %v maps", count)
+		}
+
+		d := gc.minRunInterval + time.Duration(rand.Int63n(gc.minRunInterval.Nanoseconds()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d):
+		}
+	}
+}
+
+// RunOnce performs a single map revision garbage collection sweep. Returns
+// the number of maps which were pruned.
+//
+// It attempts to prune as many maps as possible, regardless of failures. If it
+// encounters any failures the resulting error is non-nil.
+func (gc *MapRevisionGC) RunOnce(ctx context.Context) (int, error) {
+	if gc.policy.KeepRevisions <= 0 && gc.policy.KeepDuration <= 0 {
+		return 0, nil
+	}
+	pruner, ok := gc.maps.(storage.MapRevisionPruner)
+	if !ok {
+		return 0, fmt.Errorf("map storage %T doesn't support pruning revisions", gc.maps)
+	}
+
+	trees, err := storage.ListTrees(ctx, gc.admin, storage.ListTreesOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("error listing trees: %v", err)
+	}
+
+	count := 0
+	var errs []error
+	for _, tree := range trees {
+		if tree.TreeType != trillian.TreeType_MAP {
+			continue
+		}
+		revision, err := gc.keepFrom(ctx, tree)
+		if err == storage.ErrTreeNeedsInit {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("error applying retention policy to map %v: %v", tree.TreeId, err))
+			incMapPruneCounter(tree.TreeId, false)
+			continue
+		}
+		if revision <= gc.pruned[tree.TreeId] {
+			continue
+		}
+
+		glog.V(1).Infof("MapRevisionGC.RunOnce: Pruning map %v before revision %v", tree.TreeId, revision)
+		if err := pruner.PruneMapRevisions(ctx, tree, revision); err != nil {
+			errs = append(errs, fmt.Errorf("error pruning map %v: %v", tree.TreeId, err))
+			incMapPruneCounter(tree.TreeId, false)
+			continue
+		}
+		gc.pruned[tree.TreeId] = revision
+		count++
+		incMapPruneCounter(tree.TreeId, true)
+	}
+
+	if len(errs) == 0 {
+		return count, nil
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("encountered errors pruning maps:")
+	for _, err := range errs {
+		buf.WriteString("\n\t")
+		buf.WriteString(err.Error())
+	}
+	return count, errors.New(buf.String())
+}
+
+func incMapPruneCounter(treeID int64, success bool) {
+	mapPruneCounter.Inc(fmt.Sprint(treeID), fmt.Sprint(success))
+}
+
+// keepFrom returns the earliest revision of tree kept by the retention policy.
+func (gc *MapRevisionGC) keepFrom(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	tx, err := gc.maps.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+
+	latest, err := tx.LatestSignedMapRoot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var mapRoot types.MapRootV1
+	if err := mapRoot.UnmarshalBinary(latest.MapRoot); err != nil {
+		return 0, err
+	}
+
+	latestRev := int64(mapRoot.Revision)
+	keepFrom := latestRev
+	if n := gc.policy.KeepRevisions; n > 0 && latestRev-n+1 < keepFrom {
+		keepFrom = latestRev - n + 1
+	}
+	if d := gc.policy.KeepDuration; d > 0 {
+		rev, err := firstRevisionSince(ctx, tx, latestRev, gc.timeSource.Now().Add(-d))
+		if err != nil {
+			return 0, err
+		}
+		if rev < keepFrom {
+			keepFrom = rev
+		}
+	}
+	if keepFrom < 0 {
+		keepFrom = 0
+	}
+	return keepFrom, tx.Commit()
+}
+
+// firstRevisionSince returns the first revision of the map, up to latestRev,
+// whose root was created at or after t. Revisions without a root, which have
+// already been pruned, count as created before t. The timestamps of roots are
+// assumed to increase with their revisions.
+func firstRevisionSince(ctx context.Context, tx storage.ReadOnlyMapTreeTX, latestRev int64, t time.Time) (int64, error) {
+	var searchErr error
+	rev := sort.Search(int(latestRev), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		root, err := tx.GetSignedMapRoot(ctx, int64(i))
+		if status.Code(err) == codes.NotFound {
+			return false
+		} else if err != nil {
+			searchErr = err
+			return true
+		}
+		var mapRoot types.MapRootV1
+		if err := mapRoot.UnmarshalBinary(root.MapRoot); err != nil {
+			searchErr = err
+			return true
+		}
+		return int64(mapRoot.TimestampNanos) >= t.UnixNano()
+	})
+	return int64(rev), searchErr
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+func TestMapRevisionGC_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	const latestRev = 4
+	// Each revision is an hour older than the next.
+	revTime := func(rev int64) time.Time {
+		return fakeTime.Add(-time.Duration(latestRev-rev) * time.Hour)
+	}
+	// indexA is only written at revision 0, indexB is written at every
+	// revision.
+	indexA := bytes.Repeat([]byte{0xa}, 32)
+	indexB := bytes.Repeat([]byte{0xb}, 32)
+
+	for _, test := range []struct {
+		desc         string
+		policy       MapRetentionPolicy
+		wantKeepFrom int64
+	}{
+		{desc: "no policy", wantKeepFrom: 0},
+		{desc: "keep revisions", policy: MapRetentionPolicy{KeepRevisions: 2}, wantKeepFrom: 3},
+		{desc: "keep more revisions than exist", policy: MapRetentionPolicy{KeepRevisions: 10}, wantKeepFrom: 0},
+		{desc: "keep duration", policy: MapRetentionPolicy{KeepDuration: 90 * time.Minute}, wantKeepFrom: 3},
+		{desc: "keep short duration", policy: MapRetentionPolicy{KeepDuration: time.Minute}, wantKeepFrom: 4},
+		{desc: "keep either", policy: MapRetentionPolicy{KeepRevisions: 1, KeepDuration: 150 * time.Minute}, wantKeepFrom: 2},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ls := memory.NewLogStorage(nil)
+			as := memory.NewAdminStorage(ls)
+			ms := memory.NewMapStorage(ls)
+
+			tree, err := storage.CreateTree(ctx, as, stestonly.MapTree)
+			if err != nil {
+				t.Fatalf("CreateTree(): %v", err)
+			}
+			for rev := int64(0); rev <= latestRev; rev++ {
+				if err := ms.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+					if rev == 0 {
+						if err := tx.Set(ctx, indexA, trillian.MapLeaf{Index: indexA, LeafValue: []byte("A")}); err != nil {
+							return err
+						}
+					}
+					if err := tx.Set(ctx, indexB, trillian.MapLeaf{Index: indexB, LeafValue: []byte(fmt.Sprintf("B%d", rev))}); err != nil {
+						return err
+					}
+					root, err := (&types.MapRootV1{
+						Revision:       uint64(rev),
+						TimestampNanos: uint64(revTime(rev).UnixNano()),
+					}).MarshalBinary()
+					if err != nil {
+						return err
+					}
+					return tx.StoreSignedMapRoot(ctx, trillian.SignedMapRoot{MapRoot: root})
+				}); err != nil {
+					t.Fatalf("ReadWriteTransaction(rev=%d): %v", rev, err)
+				}
+			}
+
+			gc := NewMapRevisionGC(as, ms, test.policy, DefaultMapRevisionGCMinInterval, util.NewFakeTimeSource(fakeTime), monitoring.InertMetricFactory{})
+			count, err := gc.RunOnce(ctx)
+			if err != nil {
+				t.Fatalf("RunOnce(): %v", err)
+			}
+			want := 0
+			if test.wantKeepFrom > 0 {
+				want = 1
+			}
+			if count != want {
+				t.Errorf("RunOnce(): %v maps pruned, want %v", count, want)
+			}
+			// A second run has nothing more to prune.
+			if count, err := gc.RunOnce(ctx); count != 0 || err != nil {
+				t.Errorf("RunOnce(): (%v, %v), want (0, nil)", count, err)
+			}
+
+			server := NewTrillianMapServer(extension.Registry{
+				AdminStorage: as,
+				MapStorage:   ms,
+				QuotaManager: quota.Noop(),
+			})
+			for rev := int64(0); rev <= latestRev+1; rev++ {
+				want := codes.OK
+				switch {
+				case rev > latestRev:
+					want = codes.OutOfRange
+				case rev < test.wantKeepFrom:
+					want = codes.NotFound
+				}
+				_, err := server.GetSignedMapRootByRevision(ctx, &trillian.GetSignedMapRootByRevisionRequest{MapId: tree.TreeId, Revision: rev})
+				if got := status.Code(err); got != want {
+					t.Errorf("GetSignedMapRootByRevision(%d): %v, want code %v", rev, err, want)
+				}
+				if rev <= latestRev {
+					// indexB changes at every revision after the start of its history,
+					// which can't start before the pruned revisions.
+					resp, err := server.GetLeafHistory(ctx, &trillian.GetMapLeafHistoryRequest{MapId: tree.TreeId, Index: indexB, StartRevision: rev, EndRevision: latestRev})
+					if got := status.Code(err); got != want {
+						t.Errorf("GetLeafHistory(%d, %d): %v, want code %v", rev, latestRev, err, want)
+					} else if want == codes.OK && len(resp.Entries) != int(latestRev-rev) {
+						t.Errorf("GetLeafHistory(%d, %d): %v entries, want %v", rev, latestRev, len(resp.Entries), latestRev-rev)
+					}
+				}
+				if want != codes.OK {
+					continue
+				}
+
+				tx, err := ms.SnapshotForTree(ctx, tree)
+				if err != nil {
+					t.Fatalf("SnapshotForTree(): %v", err)
+				}
+				leaves, err := tx.Get(ctx, rev, [][]byte{indexA, indexB})
+				tx.Close()
+				if err != nil {
+					t.Fatalf("Get(%d): %v", rev, err)
+				}
+				if got, want := len(leaves), 2; got != want {
+					t.Fatalf("Get(%d): %v leaves, want %v", rev, got, want)
+				}
+				for _, l := range leaves {
+					want := []byte("A")
+					if bytes.Equal(l.Index, indexB) {
+						want = []byte(fmt.Sprintf("B%d", rev))
+					}
+					if !bytes.Equal(l.LeafValue, want) {
+						t.Errorf("Get(%d): leaf %x = %s, want %s", rev, l.Index, l.LeafValue, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMapRevisionGC_RunOnceNoMapRoots(t *testing.T) {
+	ctx := context.Background()
+	ls := memory.NewLogStorage(nil)
+	as := memory.NewAdminStorage(ls)
+	ms := memory.NewMapStorage(ls)
+	if _, err := storage.CreateTree(ctx, as, stestonly.MapTree); err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if _, err := storage.CreateTree(ctx, as, stestonly.LogTree); err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	gc := NewMapRevisionGC(as, ms, MapRetentionPolicy{KeepRevisions: 1}, DefaultMapRevisionGCMinInterval, util.SystemTimeSource{}, nil)
+	if count, err := gc.RunOnce(ctx); count != 0 || err != nil {
+		t.Errorf("RunOnce(): (%v, %v), want (0, nil)", count, err)
+	}
+}
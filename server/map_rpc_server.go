@@ -15,6 +15,7 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"time"
@@ -41,6 +42,10 @@ var (
 	optsMapInit  = trees.NewGetOpts(trees.Admin, trillian.TreeType_MAP)
 	optsMapRead  = trees.NewGetOpts(trees.Query, trillian.TreeType_MAP)
 	optsMapWrite = trees.NewGetOpts(trees.UpdateMap, trillian.TreeType_MAP)
+
+	// StreamMapLeavesBatchSize is the maximum number of leaves sent in each
+	// StreamMapLeavesResponse.
+	StreamMapLeavesBatchSize = 1000
 )
 
 // TODO(codingllama): There is no access control in the server yet and clients could easily modify
@@ -48,12 +53,23 @@ var (
 
 // TrillianMapServer implements the RPC API defined in the proto
 type TrillianMapServer struct {
-	registry extension.Registry
+	registry   extension.Registry
+	mastership *MapMastership
 }
 
 // NewTrillianMapServer creates a new RPC server backed by registry
 func NewTrillianMapServer(registry extension.Registry) *TrillianMapServer {
-	return &TrillianMapServer{registry}
+	return NewTrillianMapServerWithMastership(registry, nil)
+}
+
+// NewTrillianMapServerWithMastership creates a new RPC server backed by
+// registry, which only accepts SetLeaves requests for the maps it's master for
+// according to mastership. A nil mastership accepts writes to all maps.
+//
+// Leaves queued by QueueLeaves are accepted regardless, as they're written by
+// the MapSigner of the master.
+func NewTrillianMapServerWithMastership(registry extension.Registry, mastership *MapMastership) *TrillianMapServer {
+	return &TrillianMapServer{registry: registry, mastership: mastership}
 }
 
 // IsHealthy returns nil if the server is healthy, error otherwise.
@@ -69,7 +85,7 @@ func (t *TrillianMapServer) IsHealthy() error {
 func (t *TrillianMapServer) GetLeaves(ctx context.Context, req *trillian.GetMapLeavesRequest) (*trillian.GetMapLeavesResponse, error) {
 	ctx, span := spanFor(ctx, "GetLeaves")
 	defer span.End()
-	return t.getLeavesByRevision(ctx, req.MapId, req.Index, mostRecentRevision)
+	return t.getLeavesByRevision(ctx, req.MapId, req.Index, mostRecentRevision, req.BatchProof)
 }
 
 // GetLeavesByRevision implements the GetLeavesByRevision RPC method.
@@ -79,10 +95,19 @@ func (t *TrillianMapServer) GetLeavesByRevision(ctx context.Context, req *trilli
 	if req.Revision < 0 {
 		return nil, fmt.Errorf("map revision %d must be >= 0", req.Revision)
 	}
-	return t.getLeavesByRevision(ctx, req.MapId, req.Index, req.Revision)
+	return t.getLeavesByRevision(ctx, req.MapId, req.Index, req.Revision, req.BatchProof)
 }
 
-func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64, indices [][]byte, revision int64) (*trillian.GetMapLeavesResponse, error) {
+func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64, indices [][]byte, revision int64, batchProof bool) (*trillian.GetMapLeavesResponse, error) {
+	if batchProof {
+		seen := make(map[string]bool)
+		for _, index := range indices {
+			if seen[string(index)] {
+				return nil, status.Errorf(codes.InvalidArgument, "duplicate index %x in batch proof request", index)
+			}
+			seen[string(index)] = true
+		}
+	}
 	tree, hasher, err := t.getTreeAndHasher(ctx, mapID, optsMapRead)
 	if err != nil {
 		return nil, fmt.Errorf("could not get map %v: %v", mapID, err)
@@ -104,9 +129,9 @@ func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64
 		}
 		root = &r
 	} else {
-		r, err := tx.GetSignedMapRoot(ctx, revision)
+		r, err := getSignedMapRoot(ctx, tx, revision)
 		if err != nil {
-			return nil, fmt.Errorf("could not fetch SignedMapRoot %v: %v", revision, err)
+			return nil, err
 		}
 		root = &r
 	}
@@ -130,6 +155,7 @@ func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64
 			return nil, fmt.Errorf("could not fetch leaf %x: %v", index, err)
 		}
 		var leaf *trillian.MapLeaf
+		absent := false
 		if len(leaves) == 1 {
 			leaf = &leaves[0]
 			found++
@@ -144,6 +170,7 @@ func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64
 				LeafValue: nil,
 				LeafHash:  leafHash,
 			}
+			absent = true
 		}
 
 		// Fetch the proof regardless of whether the leaf exists.
@@ -155,6 +182,7 @@ func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64
 		inclusions = append(inclusions, &trillian.MapLeafInclusion{
 			Leaf:      leaf,
 			Inclusion: proof,
+			Absent:    absent,
 		})
 	}
 	glog.V(1).Infof("%v: wanted %v leaves, found %v", mapID, len(indices), found)
@@ -163,10 +191,139 @@ func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64
 		return nil, fmt.Errorf("could not commit db transaction: %v", err)
 	}
 
-	return &trillian.GetMapLeavesResponse{
+	resp := &trillian.GetMapLeavesResponse{
 		MapLeafInclusion: inclusions,
 		MapRoot:          root,
-	}, nil
+	}
+	if batchProof {
+		proofs := make([][][]byte, 0, len(inclusions))
+		for _, incl := range inclusions {
+			proofs = append(proofs, incl.Inclusion)
+			incl.Inclusion = nil
+		}
+		batch, err := merkle.CompressMapInclusionProofs(indices, proofs, hasher)
+		if err != nil {
+			return nil, fmt.Errorf("could not compress inclusion proofs: %v", err)
+		}
+		resp.BatchInclusion = batch
+	}
+	return resp, nil
+}
+
+// GetLeafHistory implements the GetLeafHistory RPC method.
+func (t *TrillianMapServer) GetLeafHistory(ctx context.Context, req *trillian.GetMapLeafHistoryRequest) (*trillian.GetMapLeafHistoryResponse, error) {
+	ctx, span := spanFor(ctx, "GetLeafHistory")
+	defer span.End()
+	if req.StartRevision < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "start revision %d must be >= 0", req.StartRevision)
+	}
+	if req.EndRevision < req.StartRevision {
+		return nil, status.Errorf(codes.InvalidArgument, "end revision %d must be >= start revision %d", req.EndRevision, req.StartRevision)
+	}
+	mapID := req.MapId
+	tree, hasher, err := t.getTreeAndHasher(ctx, mapID, optsMapRead)
+	if err != nil {
+		return nil, fmt.Errorf("could not get map %v: %v", mapID, err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+	if err := checkIndexSize(req.Index, hasher); err != nil {
+		return nil, err
+	}
+
+	tx, err := t.registry.MapStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, fmt.Errorf("could not create database snapshot: %v", err)
+	}
+	defer tx.Close()
+
+	// The whole range must have been written, or later changes could be missed.
+	if _, err := getSignedMapRoot(ctx, tx, req.EndRevision); err != nil {
+		return nil, err
+	}
+	// The start revision mustn't have been garbage collected, or the leaf would
+	// be read as of a later revision, and the changes after it would be wrong.
+	if _, err := getSignedMapRoot(ctx, tx, req.StartRevision); err != nil {
+		return nil, err
+	}
+	// Changes are relative to the value of the leaf at the start revision.
+	var value []byte
+	leaves, err := tx.Get(ctx, req.StartRevision, [][]byte{req.Index})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch leaf %x: %v", req.Index, err)
+	}
+	if len(leaves) == 1 {
+		value = leaves[0].LeafValue
+	}
+	writes, err := tx.GetLeafHistory(ctx, req.Index, req.StartRevision+1, req.EndRevision)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch history of leaf %x: %v", req.Index, err)
+	}
+
+	smtReader := merkle.NewSparseMerkleTreeReader(req.EndRevision, hasher, tx)
+	entries := make([]*trillian.MapLeafHistoryEntry, 0, len(writes))
+	for _, w := range writes {
+		if bytes.Equal(w.Leaf.LeafValue, value) {
+			continue
+		}
+		value = w.Leaf.LeafValue
+
+		root, err := getSignedMapRoot(ctx, tx, w.Revision)
+		if err != nil {
+			return nil, err
+		}
+		leaf := w.Leaf
+		absent := len(leaf.LeafValue) == 0
+		if absent {
+			// Empty leaf for proof of non-existence.
+			leafHash, err := hasher.HashLeaf(mapID, req.Index, nil)
+			if err != nil {
+				return nil, fmt.Errorf("HashLeaf(nil): %v", err)
+			}
+			leaf = trillian.MapLeaf{Index: req.Index, LeafHash: leafHash}
+		}
+		proof, err := smtReader.InclusionProof(ctx, w.Revision, req.Index)
+		if err != nil {
+			return nil, fmt.Errorf("could not get inclusion proof for leaf %x at revision %v: %v", req.Index, w.Revision, err)
+		}
+
+		entries = append(entries, &trillian.MapLeafHistoryEntry{
+			Revision: w.Revision,
+			LeafInclusion: &trillian.MapLeafInclusion{
+				Leaf:      &leaf,
+				Inclusion: proof,
+				Absent:    absent,
+			},
+			MapRoot: &root,
+		})
+	}
+	glog.V(1).Infof("%v: leaf %x changed %v times in revisions (%v, %v]", mapID, req.Index, len(entries), req.StartRevision, req.EndRevision)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit db transaction: %v", err)
+	}
+	return &trillian.GetMapLeafHistoryResponse{Entries: entries}, nil
+}
+
+// getSignedMapRoot returns the root of the map at revision. If there is no
+// root for revision, the error is NotFound if the revision has been garbage
+// collected, or OutOfRange if the map hasn't reached it yet.
+func getSignedMapRoot(ctx context.Context, tx storage.ReadOnlyMapTreeTX, revision int64) (trillian.SignedMapRoot, error) {
+	root, err := tx.GetSignedMapRoot(ctx, revision)
+	if status.Code(err) != codes.NotFound {
+		return root, err
+	}
+	latest, lerr := tx.LatestSignedMapRoot(ctx)
+	if lerr != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	var mapRoot types.MapRootV1
+	if err := mapRoot.UnmarshalBinary(latest.MapRoot); err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	if latestRev := int64(mapRoot.Revision); revision > latestRev {
+		return trillian.SignedMapRoot{}, status.Errorf(codes.OutOfRange, "map revision %d is after the latest revision %d", revision, latestRev)
+	}
+	return trillian.SignedMapRoot{}, status.Errorf(codes.NotFound, "map revision %d has been garbage collected", revision)
 }
 
 func checkIndexSize(index []byte, hasher hashers.MapHasher) error {
@@ -195,6 +352,9 @@ func (t *TrillianMapServer) SetLeaves(ctx context.Context, req *trillian.SetMapL
 		}
 		seen[k] = true
 	}
+	if req.Revision < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "map revision %d must be >= 0", req.Revision)
+	}
 
 	ctx, span := spanFor(ctx, "SetLeaves")
 	defer span.End()
@@ -205,69 +365,151 @@ func (t *TrillianMapServer) SetLeaves(ctx context.Context, req *trillian.SetMapL
 	}
 	ctx = trees.NewContext(ctx, tree)
 
+	release, err := t.mastership.AcquireWrite(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	var newRoot *trillian.SignedMapRoot
 	err = t.registry.MapStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
 		glog.V(2).Infof("%v: Writing at revision %v", mapID, tx.WriteRevision())
-		smtWriter, err := merkle.NewSparseMerkleTreeWriter(
-			ctx,
-			req.MapId,
-			tx.WriteRevision(),
-			hasher, func(ctx context.Context, f func(context.Context, storage.MapTreeTX) error) error {
-				return t.registry.MapStorage.ReadWriteTransaction(ctx, tree, f)
-			})
-		if err != nil {
-			return err
+		if req.Revision != 0 && tx.WriteRevision() != req.Revision {
+			// The storage layer guarantees that a concurrent write to the same
+			// revision fails the transaction, so this check can't be raced.
+			return status.Errorf(codes.FailedPrecondition, "can't write to revision %d, map is at revision %d", req.Revision, tx.WriteRevision()-1)
 		}
-
 		for _, l := range req.Leaves {
 			if err := checkIndexSize(l.Index, hasher); err != nil {
 				return err
 			}
-			if l.LeafValue == nil {
-				// Leaves are empty by default. Do not allow clients to store
-				// empty leaf values as this messes up the calculation of empty
-				// branches.
-				continue
-			}
-			leafHash, err := hasher.HashLeaf(mapID, l.Index, l.LeafValue)
-			if err != nil {
-				return fmt.Errorf("HashLeaf(): %v", err)
-			}
-			l.LeafHash = leafHash
-
-			if err = tx.Set(ctx, l.Index, *l); err != nil {
-				return err
-			}
-			if err = smtWriter.SetLeaves(ctx, []merkle.HashKeyValue{
-				{
-					HashedKey:   l.Index,
-					HashedValue: l.LeafHash,
-				},
-			}); err != nil {
-				return err
-			}
 		}
+		newRoot, err = writeMapLeaves(ctx, t.registry.MapStorage, tree, hasher, tx, req.Leaves, time.Now(), req.Metadata)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SetMapLeavesResponse{MapRoot: newRoot}, nil
+}
+
+// writeMapLeaves sets leaves in the revision of the map written by tx, and
+// stores a new root for the revision, with the given timestamp and metadata.
+// The leaves' indexes must be distinct, and of the right size for hasher.
+func writeMapLeaves(ctx context.Context, ms storage.MapStorage, tree *trillian.Tree, hasher hashers.MapHasher, tx storage.MapTreeTX,
+	leaves []*trillian.MapLeaf, ts time.Time, metadata []byte) (*trillian.SignedMapRoot, error) {
+	mapID := tree.TreeId
+	smtWriter, err := merkle.NewSparseMerkleTreeWriter(
+		ctx,
+		mapID,
+		tx.WriteRevision(),
+		hasher, func(ctx context.Context, f func(context.Context, storage.MapTreeTX) error) error {
+			return ms.ReadWriteTransaction(ctx, tree, f)
+		})
+	if err != nil {
+		return nil, err
+	}
 
-		rootHash, err := smtWriter.CalculateRoot()
+	for _, l := range leaves {
+		if l.LeafValue == nil {
+			// Leaves are empty by default. Do not allow clients to store
+			// empty leaf values as this messes up the calculation of empty
+			// branches.
+			continue
+		}
+		leafHash, err := hasher.HashLeaf(mapID, l.Index, l.LeafValue)
 		if err != nil {
-			return fmt.Errorf("CalculateRoot(): %v", err)
+			return nil, fmt.Errorf("HashLeaf(): %v", err)
 		}
+		l.LeafHash = leafHash
 
-		newRoot, err = t.makeSignedMapRoot(ctx, tree, time.Now(), rootHash, req.MapId, tx.WriteRevision(), req.Metadata)
-		if err != nil {
-			return fmt.Errorf("makeSignedMapRoot(): %v", err)
+		if err = tx.Set(ctx, l.Index, *l); err != nil {
+			return nil, err
 		}
+		if err = smtWriter.SetLeaves(ctx, []merkle.HashKeyValue{
+			{
+				HashedKey:   l.Index,
+				HashedValue: l.LeafHash,
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
 
-		// TODO(al): need an smtWriter.Rollback() or similar I think.
-		return tx.StoreSignedMapRoot(ctx, *newRoot)
-	})
+	rootHash, err := smtWriter.CalculateRoot()
+	if err != nil {
+		return nil, fmt.Errorf("CalculateRoot(): %v", err)
+	}
+
+	newRoot, err := makeSignedMapRoot(ctx, tree, ts, rootHash, mapID, tx.WriteRevision(), metadata)
 	if err != nil {
+		return nil, fmt.Errorf("makeSignedMapRoot(): %v", err)
+	}
+
+	// TODO(al): need an smtWriter.Rollback() or similar I think.
+	if err := tx.StoreSignedMapRoot(ctx, *newRoot); err != nil {
 		return nil, err
 	}
-	return &trillian.SetMapLeavesResponse{MapRoot: newRoot}, nil
+	return newRoot, nil
+}
+
+// QueueLeaves implements the QueueLeaves RPC method. It requires a MapStorage
+// which implements storage.MapLeafQueuer.
+func (t *TrillianMapServer) QueueLeaves(ctx context.Context, req *trillian.QueueMapLeavesRequest) (*trillian.QueueMapLeavesResponse, error) {
+	ctx, span := spanFor(ctx, "QueueLeaves")
+	defer span.End()
+	queuer, ok := t.registry.MapStorage.(storage.MapLeafQueuer)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "map storage %T doesn't support queueing leaves", t.registry.MapStorage)
+	}
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.MapId, optsMapWrite)
+	if err != nil {
+		return nil, err
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	seen := make(map[string]bool)
+	leaves := make([]*trillian.MapLeaf, 0, len(req.Leaves))
+	for _, l := range req.Leaves {
+		if err := checkIndexSize(l.Index, hasher); err != nil {
+			return nil, err
+		}
+		if seen[string(l.Index)] {
+			return nil, status.Errorf(codes.InvalidArgument, "index %x duplicated", l.Index)
+		}
+		seen[string(l.Index)] = true
+		// As in SetLeaves, empty values aren't stored.
+		if l.LeafValue != nil {
+			leaves = append(leaves, l)
+		}
+	}
+	if len(leaves) > 0 {
+		if err := queuer.QueueMapLeaves(ctx, tree, leaves, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return &trillian.QueueMapLeavesResponse{}, nil
 }
 
-func (t *TrillianMapServer) makeSignedMapRoot(ctx context.Context, tree *trillian.Tree, smrTs time.Time,
+// GetMapStatus implements the GetMapStatus RPC method.
+func (t *TrillianMapServer) GetMapStatus(ctx context.Context, req *trillian.GetMapStatusRequest) (*trillian.GetMapStatusResponse, error) {
+	ctx, span := spanFor(ctx, "GetMapStatus")
+	defer span.End()
+	if _, _, err := t.getTreeAndHasher(ctx, req.MapId, optsMapRead); err != nil {
+		return nil, err
+	}
+	isMaster, err := t.mastership.IsMaster(req.MapId)
+	if err != nil {
+		return nil, err
+	}
+	master, err := t.mastership.Master(ctx, req.MapId)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "could not determine master for map %v: %v", req.MapId, err)
+	}
+	return &trillian.GetMapStatusResponse{Master: master, IsMaster: isMaster}, nil
+}
+
+func makeSignedMapRoot(ctx context.Context, tree *trillian.Tree, smrTs time.Time,
 	rootHash []byte, mapID, revision int64, meta []byte) (*trillian.SignedMapRoot, error) {
 	smr := &types.MapRootV1{
 		RootHash:       rootHash,
@@ -333,7 +575,7 @@ func (t *TrillianMapServer) GetSignedMapRootByRevision(ctx context.Context, req
 	}
 	defer tx.Close()
 
-	r, err := tx.GetSignedMapRoot(ctx, req.Revision)
+	r, err := getSignedMapRoot(ctx, tx, req.Revision)
 	if err != nil {
 		return nil, err
 	}
@@ -348,6 +590,86 @@ func (t *TrillianMapServer) GetSignedMapRootByRevision(ctx context.Context, req
 	}, nil
 }
 
+// StreamLeaves implements the StreamLeaves RPC method. Leaves are sent in
+// batches of at most StreamMapLeavesBatchSize leaves, each of which is read in
+// its own snapshot so the stream doesn't hold a storage transaction open while
+// waiting for a slow client.
+func (t *TrillianMapServer) StreamLeaves(req *trillian.StreamMapLeavesRequest, stream trillian.TrillianMap_StreamLeavesServer) error {
+	ctx, span := spanFor(stream.Context(), "StreamLeaves")
+	defer span.End()
+	if req.Revision < 0 {
+		return status.Errorf(codes.InvalidArgument, "map revision %d must be >= 0", req.Revision)
+	}
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.MapId, optsMapRead)
+	if err != nil {
+		return err
+	}
+	ctx = trees.NewContext(ctx, tree)
+	if len(req.Cursor) > 0 {
+		if err := checkIndexSize(req.Cursor, hasher); err != nil {
+			return err
+		}
+	}
+
+	cursor := req.Cursor
+	for sent := false; ; sent = true {
+		r, err := t.getStreamMapLeavesBatch(ctx, tree, req.Revision, cursor)
+		if err != nil {
+			return err
+		}
+		// The first response is sent even if the map is empty, so the client
+		// always receives the map root.
+		if len(r.Leaves) > 0 || !sent {
+			if err := stream.Send(r); err != nil {
+				return err
+			}
+		}
+		if len(r.Leaves) < StreamMapLeavesBatchSize {
+			return nil
+		}
+		cursor = r.Leaves[len(r.Leaves)-1].Index
+	}
+}
+
+// getStreamMapLeavesBatch returns the next batch of non-empty leaves at
+// revision following cursor, along with the map root of that revision.
+func (t *TrillianMapServer) getStreamMapLeavesBatch(ctx context.Context, tree *trillian.Tree, revision int64, cursor []byte) (*trillian.StreamMapLeavesResponse, error) {
+	tx, err := t.registry.MapStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	root, err := getSignedMapRoot(ctx, tx, revision)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := tx.GetLeavesAfter(ctx, revision, cursor, StreamMapLeavesBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &trillian.StreamMapLeavesResponse{
+		Leaves:  make([]*trillian.MapLeaf, 0, len(leaves)),
+		MapRoot: &root,
+	}
+	// Storage must make progress, or the stream would never end.
+	prev := cursor
+	for i := range leaves {
+		if bytes.Compare(leaves[i].Index, prev) <= 0 {
+			return nil, status.Errorf(codes.Internal, "got leaf %x after %x, want ascending indexes", leaves[i].Index, prev)
+		}
+		prev = leaves[i].Index
+		r.Leaves = append(r.Leaves, &leaves[i])
+	}
+
+	if err := tx.Commit(); err != nil {
+		glog.Warningf("%v: Commit failed for StreamLeaves: %v", tree.TreeId, err)
+		return nil, err
+	}
+	return r, nil
+}
+
 func (t *TrillianMapServer) getTreeAndHasher(ctx context.Context, treeID int64, opts trees.GetOpts) (*trillian.Tree, hashers.MapHasher, error) {
 	tree, err := trees.GetTree(ctx, t.registry.AdminStorage, treeID, opts)
 	if err != nil {
@@ -395,7 +717,7 @@ func (t *TrillianMapServer) InitMap(ctx context.Context, req *trillian.InitMapRe
 
 		glog.V(2).Infof("%v: Need to init map root revision 0", mapID)
 		rootHash := hasher.HashEmpty(mapID, make([]byte, hasher.Size()), hasher.BitLen())
-		rev0Root, err = t.makeSignedMapRoot(ctx, tree, time.Now(), rootHash, mapID, 0 /*revision*/, nil /* metadata */)
+		rev0Root, err = makeSignedMapRoot(ctx, tree, time.Now(), rootHash, mapID, 0 /*revision*/, nil /* metadata */)
 		if err != nil {
 			return fmt.Errorf("makeSignedMapRoot(): %v", err)
 		}
@@ -15,8 +15,10 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -26,6 +28,7 @@ import (
 	"github.com/google/trillian/storage"
 	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -334,6 +337,192 @@ func TestGetSignedMapRootByRevision(t *testing.T) {
 	}
 }
 
+type fakeStreamMapLeavesServer struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*trillian.StreamMapLeavesResponse
+}
+
+func (s *fakeStreamMapLeavesServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStreamMapLeavesServer) Send(r *trillian.StreamMapLeavesResponse) error {
+	s.sent = append(s.sent, r)
+	return nil
+}
+
+func TestStreamMapLeaves(t *testing.T) {
+	defer func(size int) { StreamMapLeavesBatchSize = size }(StreamMapLeavesBatchSize)
+	StreamMapLeavesBatchSize = 2
+
+	mapRoot := trillian.SignedMapRoot{Signature: []byte("notempty")}
+	var leaves []trillian.MapLeaf
+	for i := 1; i <= 4; i++ {
+		index := bytes.Repeat([]byte{byte(i)}, 32)
+		leaves = append(leaves, trillian.MapLeaf{Index: index, LeafValue: []byte{byte(i)}})
+	}
+
+	// batch describes a snapshot read by StreamLeaves, and the GetLeavesAfter
+	// call expected in it, if any.
+	type batch struct {
+		after   []byte
+		leaves  []trillian.MapLeaf
+		rootErr error
+		getErr  error
+	}
+
+	for _, test := range []struct {
+		desc    string
+		req     trillian.StreamMapLeavesRequest
+		batches []batch
+		want    [][]trillian.MapLeaf
+		wantErr string
+	}{
+		{
+			desc: "all leaves",
+			req:  trillian.StreamMapLeavesRequest{Revision: 1},
+			batches: []batch{
+				{leaves: leaves[0:2]},
+				{after: leaves[1].Index, leaves: leaves[2:3]},
+			},
+			want: [][]trillian.MapLeaf{leaves[0:2], leaves[2:3]},
+		},
+		{
+			desc: "last batch full",
+			req:  trillian.StreamMapLeavesRequest{Revision: 1},
+			batches: []batch{
+				{leaves: leaves[0:2]},
+				{after: leaves[1].Index, leaves: leaves[2:4]},
+				{after: leaves[3].Index},
+			},
+			want: [][]trillian.MapLeaf{leaves[0:2], leaves[2:4]},
+		},
+		{
+			desc:    "empty map",
+			req:     trillian.StreamMapLeavesRequest{Revision: 1},
+			batches: []batch{{}},
+			want:    [][]trillian.MapLeaf{nil},
+		},
+		{
+			desc: "cursor",
+			req:  trillian.StreamMapLeavesRequest{Revision: 1, Cursor: leaves[1].Index},
+			batches: []batch{
+				{after: leaves[1].Index, leaves: leaves[2:3]},
+			},
+			want: [][]trillian.MapLeaf{leaves[2:3]},
+		},
+		{
+			desc:    "negative revision",
+			req:     trillian.StreamMapLeavesRequest{Revision: -1},
+			wantErr: "must be >= 0",
+		},
+		{
+			desc:    "bad cursor",
+			req:     trillian.StreamMapLeavesRequest{Revision: 1, Cursor: []byte("short")},
+			wantErr: "is not 32",
+		},
+		{
+			desc:    "unknown revision",
+			req:     trillian.StreamMapLeavesRequest{Revision: 5},
+			batches: []batch{{rootErr: errors.New("no map root for revision 5")}},
+			wantErr: "no map root",
+		},
+		{
+			desc:    "storage error",
+			req:     trillian.StreamMapLeavesRequest{Revision: 1},
+			batches: []batch{{getErr: errors.New("test error xyzzy")}},
+			wantErr: "test error xyzzy",
+		},
+		{
+			desc:    "leaves out of order",
+			req:     trillian.StreamMapLeavesRequest{Revision: 1},
+			batches: []batch{{leaves: []trillian.MapLeaf{leaves[1], leaves[0]}}},
+			wantErr: "want ascending indexes",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fakeStorage := storage.NewMockMapStorage(ctrl)
+			var calls []*gomock.Call
+			for _, b := range test.batches {
+				mockTX := storage.NewMockMapTreeTX(ctrl)
+				calls = append(calls, fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTX, nil))
+				mockTX.EXPECT().GetSignedMapRoot(gomock.Any(), test.req.Revision).Return(mapRoot, b.rootErr)
+				if b.rootErr == nil {
+					mockTX.EXPECT().GetLeavesAfter(gomock.Any(), test.req.Revision, b.after, StreamMapLeavesBatchSize).Return(b.leaves, b.getErr)
+				}
+				if b.rootErr == nil && b.getErr == nil && test.wantErr == "" {
+					mockTX.EXPECT().Commit().Return(nil)
+				}
+				mockTX.EXPECT().Close().Return(nil)
+			}
+			gomock.InOrder(calls...)
+
+			server := NewTrillianMapServer(extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl, 1, mapID1),
+				MapStorage:   fakeStorage,
+			})
+
+			test.req.MapId = mapID1
+			stream := &fakeStreamMapLeavesServer{ctx: context.Background()}
+			err := server.StreamLeaves(&test.req, stream)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("StreamLeaves()=%v, want err containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StreamLeaves()=%v, want nil", err)
+			}
+			if got, want := len(stream.sent), len(test.want); got != want {
+				t.Fatalf("StreamLeaves() sent %d responses, want %d", got, want)
+			}
+			for i, r := range stream.sent {
+				if !proto.Equal(r.MapRoot, &mapRoot) {
+					t.Errorf("StreamLeaves() response %d has root %v, want %v", i, r.MapRoot, mapRoot)
+				}
+				if got, want := len(r.Leaves), len(test.want[i]); got != want {
+					t.Errorf("StreamLeaves() response %d has %d leaves, want %d", i, got, want)
+					continue
+				}
+				for j, leaf := range r.Leaves {
+					if !proto.Equal(leaf, &test.want[i][j]) {
+						t.Errorf("StreamLeaves() response %d leaf %d = %v, want %v", i, j, leaf, test.want[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGetLeafHistory_InvalidRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	index := bytes.Repeat([]byte{1}, 32)
+	for _, test := range []struct {
+		desc string
+		req  *trillian.GetMapLeafHistoryRequest
+	}{
+		{desc: "negative start", req: &trillian.GetMapLeafHistoryRequest{MapId: mapID1, Index: index, StartRevision: -1, EndRevision: 1}},
+		{desc: "end before start", req: &trillian.GetMapLeafHistoryRequest{MapId: mapID1, Index: index, StartRevision: 2, EndRevision: 1}},
+		{desc: "short index", req: &trillian.GetMapLeafHistoryRequest{MapId: mapID1, Index: []byte("short"), EndRevision: 1}},
+	} {
+		server := NewTrillianMapServer(extension.Registry{
+			AdminStorage: fakeAdminStorageForMap(ctrl, 1, mapID1),
+			MapStorage:   storage.NewMockMapStorage(ctrl),
+		})
+		_, err := server.GetLeafHistory(context.Background(), test.req)
+		if got, want := status.Code(err), codes.InvalidArgument; got != want {
+			t.Errorf("%v: GetLeafHistory(): %v, want code %v", test.desc, err, want)
+		}
+	}
+}
+
 func fakeAdminStorageForMap(ctrl *gomock.Controller, times int, treeID int64) storage.AdminStorage {
 	tree := *stestonly.MapTree
 	tree.TreeId = treeID
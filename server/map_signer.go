@@ -0,0 +1,233 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util"
+)
+
+// DefaultMapSignerBatchSize is the suggested maximum number of queued leaves
+// written to each new map revision by a MapSigner.
+const DefaultMapSignerBatchSize = 1000
+
+var (
+	mapSignerMetricsOnce sync.Once
+	mapSigningRuns       monitoring.Counter
+	mapLeavesSigned      monitoring.Counter
+)
+
+// MapSignerOptions configures a MapSigner.
+type MapSignerOptions struct {
+	// BatchSize is the maximum number of queued leaves written to each new
+	// revision of a map.
+	BatchSize int
+	// RunInterval is the time between starting passes over the maps. If a pass
+	// takes longer than this interval to complete, the next pass starts
+	// immediately.
+	RunInterval time.Duration
+	// Mastership determines which maps this instance signs. If nil, this
+	// instance signs all maps.
+	Mastership *MapMastership
+	// TimeSource is used to timestamp new map roots.
+	TimeSource util.TimeSource
+}
+
+// MapSigner writes the leaves queued by the QueueLeaves RPC to the maps they
+// were queued for. Each pass dequeues up to BatchSize leaves for every map
+// this instance is master for, and writes them to a single new revision of the
+// map, signed by the map's key.
+//
+// It requires a MapStorage which implements storage.MapLeafQueuer.
+type MapSigner struct {
+	registry extension.Registry
+	opts     MapSignerOptions
+}
+
+// NewMapSigner returns a new MapSigner.
+func NewMapSigner(registry extension.Registry, opts MapSignerOptions) *MapSigner {
+	mapSignerMetricsOnce.Do(func() {
+		mf := registry.MetricFactory
+		if mf == nil {
+			mf = monitoring.InertMetricFactory{}
+		}
+		mapSigningRuns = mf.NewCounter("map_signing_runs", "Number of map signing runs", monitoring.TreeIDLabel, "success")
+		mapLeavesSigned = mf.NewCounter("map_leaves_signed", "Number of queued leaves written to maps", monitoring.TreeIDLabel)
+	})
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultMapSignerBatchSize
+	}
+	if opts.TimeSource == nil {
+		opts.TimeSource = util.SystemTimeSource{}
+	}
+	return &MapSigner{registry: registry, opts: opts}
+}
+
+// Run starts the map signer. It runs until ctx is cancelled.
+func (s *MapSigner) Run(ctx context.Context) {
+	glog.Infof("Map signer starting")
+loop:
+	for {
+		start := s.opts.TimeSource.Now()
+		count, err := s.RunOnce(ctx)
+		if err != nil {
+			glog.Errorf("MapSigner.Run: %v", err)
+		}
+		if count > 0 {
+			glog.V(1).Infof("MapSigner.Run: wrote %v queued leaves", count)
+		}
+
+		wait := s.opts.RunInterval - s.opts.TimeSource.Now().Sub(start)
+		if wait <= 0 {
+			wait = 0
+		}
+		if err := util.SleepContext(ctx, wait); err != nil {
+			break loop
+		}
+	}
+	glog.Infof("Map signer shutting down")
+}
+
+// RunOnce performs a single pass of the map signer, writing the leaves queued
+// for each map this instance is master for. Returns the number of leaves
+// written.
+//
+// It attempts to sign as many maps as possible, regardless of failures. If it
+// encounters any failures the resulting error is non-nil.
+func (s *MapSigner) RunOnce(ctx context.Context) (int, error) {
+	if _, ok := s.registry.MapStorage.(storage.MapLeafQueuer); !ok {
+		return 0, fmt.Errorf("map storage %T doesn't support queueing leaves", s.registry.MapStorage)
+	}
+
+	trees, err := storage.ListTrees(ctx, s.registry.AdminStorage, storage.ListTreesOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("error listing trees: %v", err)
+	}
+
+	count := 0
+	var errs []error
+	for _, tree := range trees {
+		if tree.TreeType != trillian.TreeType_MAP || tree.TreeState != trillian.TreeState_ACTIVE {
+			continue
+		}
+		id := strconv.FormatInt(tree.TreeId, 10)
+		release, isMaster, err := s.opts.Mastership.acquire(tree.TreeId)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error checking mastership of map %v: %v", id, err))
+			continue
+		}
+		if !isMaster {
+			continue
+		}
+		n, err := s.signMap(ctx, tree)
+		release()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error signing map %v: %v", id, err))
+			mapSigningRuns.Inc(id, "false")
+			continue
+		}
+		count += n
+		if n > 0 {
+			mapSigningRuns.Inc(id, "true")
+			mapLeavesSigned.Add(float64(n), id)
+		}
+	}
+
+	if len(errs) == 0 {
+		return count, nil
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("encountered errors signing maps:")
+	for _, err := range errs {
+		buf.WriteString("\n\t")
+		buf.WriteString(err.Error())
+	}
+	return count, errors.New(buf.String())
+}
+
+// signMap writes up to BatchSize of the leaves queued for tree to a new
+// revision of the map, which keeps the metadata of the previous revision. If
+// no leaves are queued no revision is written. Maps which haven't been
+// initialised aren't written to, as their first revision must be the empty
+// one written by InitMap.
+func (s *MapSigner) signMap(ctx context.Context, tree *trillian.Tree) (int, error) {
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create hasher for map %v: %v", tree.TreeId, err)
+	}
+
+	var count int
+	err = s.registry.MapStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		qtx, ok := tx.(storage.MapQueueTX)
+		if !ok {
+			return fmt.Errorf("map transaction %T doesn't support dequeueing leaves", tx)
+		}
+		latest, err := tx.LatestSignedMapRoot(ctx)
+		if err == storage.ErrTreeNeedsInit {
+			return fmt.Errorf("map %v has no initial revision", tree.TreeId)
+		} else if err != nil {
+			return err
+		}
+		var root types.MapRootV1
+		if err := root.UnmarshalBinary(latest.MapRoot); err != nil {
+			return err
+		}
+
+		now := s.opts.TimeSource.Now()
+		queued, err := qtx.DequeueMapLeaves(ctx, s.opts.BatchSize, now)
+		if err != nil {
+			return err
+		}
+		count = len(queued)
+		if count == 0 {
+			return nil
+		}
+
+		// Leaves queued later for the same index replace earlier ones.
+		pos := make(map[string]int)
+		leaves := make([]*trillian.MapLeaf, 0, len(queued))
+		for _, l := range queued {
+			if i, ok := pos[string(l.Index)]; ok {
+				leaves[i] = l
+				continue
+			}
+			pos[string(l.Index)] = len(leaves)
+			leaves = append(leaves, l)
+		}
+		// The metadata is set by the writer of the map, e.g. to checkpoint its
+		// progress, so it's carried forward rather than cleared.
+		_, err = writeMapLeaves(ctx, s.registry.MapStorage, tree, hasher, tx, leaves, now, root.Metadata)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
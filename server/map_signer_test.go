@@ -0,0 +1,286 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util"
+	"github.com/google/trillian/util/election"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// newQueueingMap returns a map server and registry backed by memory storage,
+// along with the ID of an initialised map.
+func newQueueingMap(ctx context.Context, t *testing.T) (*TrillianMapServer, extension.Registry, int64) {
+	t.Helper()
+	ls := memory.NewLogStorage(nil)
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ls),
+		MapStorage:   memory.NewMapStorage(ls),
+		QuotaManager: quota.Noop(),
+	}
+	tree, err := storage.CreateTree(ctx, registry.AdminStorage, stestonly.MapTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	server := NewTrillianMapServer(registry)
+	if _, err := server.InitMap(ctx, &trillian.InitMapRequest{MapId: tree.TreeId}); err != nil {
+		t.Fatalf("InitMap(): %v", err)
+	}
+	return server, registry, tree.TreeId
+}
+
+func latestMapRevision(ctx context.Context, t *testing.T, server *TrillianMapServer, mapID int64) uint64 {
+	t.Helper()
+	resp, err := server.GetSignedMapRoot(ctx, &trillian.GetSignedMapRootRequest{MapId: mapID})
+	if err != nil {
+		t.Fatalf("GetSignedMapRoot(): %v", err)
+	}
+	var root types.MapRootV1
+	if err := root.UnmarshalBinary(resp.MapRoot.MapRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	return root.Revision
+}
+
+func TestMapSigner_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	indexA := bytes.Repeat([]byte{0xa}, 32)
+	indexB := bytes.Repeat([]byte{0xb}, 32)
+	indexC := bytes.Repeat([]byte{0xc}, 32)
+
+	for _, test := range []struct {
+		desc      string
+		queued    [][]*trillian.MapLeaf
+		batchSize int
+		wantCount []int
+		wantRev   uint64
+		want      map[string]string
+	}{
+		{
+			desc:      "nothing queued",
+			wantCount: []int{0},
+			wantRev:   0,
+			want:      map[string]string{},
+		},
+		{
+			desc: "one batch",
+			queued: [][]*trillian.MapLeaf{
+				{{Index: indexA, LeafValue: []byte("A")}, {Index: indexB, LeafValue: []byte("B")}},
+				{{Index: indexC, LeafValue: []byte("C")}},
+			},
+			wantCount: []int{3, 0},
+			wantRev:   1,
+			want:      map[string]string{string(indexA): "A", string(indexB): "B", string(indexC): "C"},
+		},
+		{
+			desc: "last queued wins",
+			queued: [][]*trillian.MapLeaf{
+				{{Index: indexA, LeafValue: []byte("A1")}},
+				{{Index: indexA, LeafValue: []byte("A2")}, {Index: indexB, LeafValue: []byte("B")}},
+			},
+			wantCount: []int{3},
+			wantRev:   1,
+			want:      map[string]string{string(indexA): "A2", string(indexB): "B"},
+		},
+		{
+			desc: "batches",
+			queued: [][]*trillian.MapLeaf{
+				{{Index: indexA, LeafValue: []byte("A")}},
+				{{Index: indexB, LeafValue: []byte("B")}},
+				{{Index: indexC, LeafValue: []byte("C")}},
+			},
+			batchSize: 2,
+			wantCount: []int{2, 1, 0},
+			wantRev:   2,
+			want:      map[string]string{string(indexA): "A", string(indexB): "B", string(indexC): "C"},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			server, registry, mapID := newQueueingMap(ctx, t)
+			for _, leaves := range test.queued {
+				if _, err := server.QueueLeaves(ctx, &trillian.QueueMapLeavesRequest{MapId: mapID, Leaves: leaves}); err != nil {
+					t.Fatalf("QueueLeaves(): %v", err)
+				}
+			}
+
+			// Queued leaves aren't visible until the signer runs.
+			if got := latestMapRevision(ctx, t, server, mapID); got != 0 {
+				t.Errorf("latest revision before signing: %v, want 0", got)
+			}
+
+			signer := NewMapSigner(registry, MapSignerOptions{BatchSize: test.batchSize, TimeSource: util.SystemTimeSource{}})
+			for i, want := range test.wantCount {
+				count, err := signer.RunOnce(ctx)
+				if err != nil {
+					t.Fatalf("RunOnce() #%d: %v", i, err)
+				}
+				if count != want {
+					t.Errorf("RunOnce() #%d: %v leaves, want %v", i, count, want)
+				}
+			}
+
+			if got := latestMapRevision(ctx, t, server, mapID); got != test.wantRev {
+				t.Errorf("latest revision after signing: %v, want %v", got, test.wantRev)
+			}
+			resp, err := server.GetLeaves(ctx, &trillian.GetMapLeavesRequest{MapId: mapID, Index: [][]byte{indexA, indexB, indexC}})
+			if err != nil {
+				t.Fatalf("GetLeaves(): %v", err)
+			}
+			for _, incl := range resp.MapLeafInclusion {
+				got, want := string(incl.Leaf.LeafValue), test.want[string(incl.Leaf.Index)]
+				if got != want {
+					t.Errorf("GetLeaves(): leaf %x = %q, want %q", incl.Leaf.Index, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMapSigner_KeepsMetadata(t *testing.T) {
+	ctx := context.Background()
+	server, registry, mapID := newQueueingMap(ctx, t)
+	metadata := []byte("checkpoint")
+	if _, err := server.SetLeaves(ctx, &trillian.SetMapLeavesRequest{MapId: mapID, Metadata: metadata}); err != nil {
+		t.Fatalf("SetLeaves(): %v", err)
+	}
+	leaves := []*trillian.MapLeaf{{Index: bytes.Repeat([]byte{0xa}, 32), LeafValue: []byte("A")}}
+	if _, err := server.QueueLeaves(ctx, &trillian.QueueMapLeavesRequest{MapId: mapID, Leaves: leaves}); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+
+	signer := NewMapSigner(registry, MapSignerOptions{})
+	if _, err := signer.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce(): %v", err)
+	}
+
+	resp, err := server.GetSignedMapRoot(ctx, &trillian.GetSignedMapRootRequest{MapId: mapID})
+	if err != nil {
+		t.Fatalf("GetSignedMapRoot(): %v", err)
+	}
+	var root types.MapRootV1
+	if err := root.UnmarshalBinary(resp.MapRoot.MapRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if got, want := root.Revision, uint64(2); got != want {
+		t.Errorf("latest revision: %v, want %v", got, want)
+	}
+	if got, want := root.Metadata, metadata; !bytes.Equal(got, want) {
+		t.Errorf("latest root metadata: %q, want %q", got, want)
+	}
+}
+
+// queueingMapStorage is a FakeMapStorage which implements MapLeafQueuer.
+type queueingMapStorage struct {
+	*stestonly.FakeMapStorage
+}
+
+func (queueingMapStorage) QueueMapLeaves(context.Context, *trillian.Tree, []*trillian.MapLeaf, time.Time) error {
+	return nil
+}
+
+// queueingMapTX is a MapTreeTX which implements MapQueueTX, recording whether
+// leaves were dequeued.
+type queueingMapTX struct {
+	*storage.MockMapTreeTX
+	dequeued bool
+}
+
+func (tx *queueingMapTX) DequeueMapLeaves(context.Context, int, time.Time) ([]*trillian.MapLeaf, error) {
+	tx.dequeued = true
+	return nil, nil
+}
+
+func TestMapSigner_NotInitialised(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	admin := memory.NewAdminStorage(memory.NewLogStorage(nil))
+	if _, err := storage.CreateTree(ctx, admin, stestonly.MapTree); err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	tx := &queueingMapTX{MockMapTreeTX: storage.NewMockMapTreeTX(ctrl)}
+	tx.EXPECT().LatestSignedMapRoot(gomock.Any()).Return(trillian.SignedMapRoot{}, storage.ErrTreeNeedsInit)
+	tx.EXPECT().Close().Return(nil)
+	ms := queueingMapStorage{&stestonly.FakeMapStorage{TX: tx}}
+
+	registry := extension.Registry{AdminStorage: admin, MapStorage: ms}
+	signer := NewMapSigner(registry, MapSignerOptions{})
+	if count, err := signer.RunOnce(ctx); count != 0 || err == nil {
+		t.Errorf("RunOnce(): (%v, %v), want (0, error)", count, err)
+	}
+	if tx.dequeued {
+		t.Error("RunOnce() dequeued leaves for a map with no initial revision")
+	}
+}
+
+func TestMapSigner_NotMaster(t *testing.T) {
+	ctx := context.Background()
+	server, registry, mapID := newQueueingMap(ctx, t)
+	leaves := []*trillian.MapLeaf{{Index: bytes.Repeat([]byte{0xa}, 32), LeafValue: []byte("A")}}
+	if _, err := server.QueueLeaves(ctx, &trillian.QueueMapLeavesRequest{MapId: mapID, Leaves: leaves}); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+
+	// Another instance is master.
+	mastership := NewMapMastership(otherMasterFactory{}, election.RunnerConfig{}, nil)
+	defer mastership.Close()
+
+	signer := NewMapSigner(registry, MapSignerOptions{Mastership: mastership})
+	count, err := signer.RunOnce(ctx)
+	if count != 0 || err != nil {
+		t.Errorf("RunOnce(): (%v, %v), want (0, nil)", count, err)
+	}
+	if got := latestMapRevision(ctx, t, server, mapID); got != 0 {
+		t.Errorf("latest revision: %v, want 0", got)
+	}
+}
+
+func TestQueueLeaves_Errors(t *testing.T) {
+	ctx := context.Background()
+	server, _, mapID := newQueueingMap(ctx, t)
+	index := bytes.Repeat([]byte{0xa}, 32)
+
+	for _, test := range []struct {
+		desc     string
+		leaves   []*trillian.MapLeaf
+		wantCode codes.Code
+	}{
+		{desc: "short index", leaves: []*trillian.MapLeaf{{Index: []byte("short"), LeafValue: []byte("A")}}, wantCode: codes.InvalidArgument},
+		{desc: "duplicate index", leaves: []*trillian.MapLeaf{{Index: index, LeafValue: []byte("A")}, {Index: index, LeafValue: []byte("B")}}, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := server.QueueLeaves(ctx, &trillian.QueueMapLeavesRequest{MapId: mapID, Leaves: test.leaves})
+			if got := status.Code(err); got != test.wantCode {
+				t.Errorf("QueueLeaves(): %v, want %v", err, test.wantCode)
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ func init() {
 type memProvider struct {
 	mf monitoring.MetricFactory
 	ls storage.LogStorage
+	ms storage.MapStorage
 	as storage.AdminStorage
 }
 
@@ -38,6 +39,7 @@ func newMemoryStorageProvider(mf monitoring.MetricFactory) (StorageProvider, err
 	return &memProvider{
 		mf: mf,
 		ls: ls,
+		ms: memory.NewMapStorage(ls),
 		as: memory.NewAdminStorage(ls),
 	}, nil
 }
@@ -47,7 +49,7 @@ func (s *memProvider) LogStorage() storage.LogStorage {
 }
 
 func (s *memProvider) MapStorage() storage.MapStorage {
-	return nil
+	return s.ms
 }
 
 func (s *memProvider) AdminStorage() storage.AdminStorage {
@@ -0,0 +1,44 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/quota/postgresqm"
+)
+
+// QuotaPostgres represents the PostgreSQL quota implementation.
+const QuotaPostgres = "postgres"
+
+func init() {
+	if err := RegisterQuotaManager(QuotaPostgres, newPostgresQuotaManager); err != nil {
+		glog.Fatalf("Failed to register quota manager %v: %v", QuotaPostgres, err)
+	}
+}
+
+func newPostgresQuotaManager() (quota.Manager, error) {
+	if postgresStorageInstance == nil {
+		return nil, errors.New("quota_system=postgres requires storage_system=postgres")
+	}
+	qm := &postgresqm.QuotaManager{
+		DB:                 postgresStorageInstance.db,
+		MaxUnsequencedRows: *maxUnsequencedRows,
+	}
+	glog.Info("Using PostgreSQL QuotaManager")
+	return qm, nil
+}
@@ -35,8 +35,16 @@ import (
 type SequencerManager struct {
 	guardWindow  time.Duration
 	registry     extension.Registry
-	signers      map[int64]*tcrypto.Signer
+	signers      map[signerKey]*tcrypto.Signer
 	signersMutex sync.Mutex
+	// treeCache carries the compact Merkle tree of each log between passes.
+	treeCache *log.CompactTreeCache
+}
+
+// signerKey identifies a cached signer: that of the original key of a tree if
+// keyID is the tree ID, or that of one of its successor keys otherwise.
+type signerKey struct {
+	treeID, keyID int64
 }
 
 var seqOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
@@ -47,7 +55,8 @@ func NewSequencerManager(registry extension.Registry, gw time.Duration) *Sequenc
 	return &SequencerManager{
 		guardWindow: gw,
 		registry:    registry,
-		signers:     make(map[int64]*tcrypto.Signer),
+		signers:     make(map[signerKey]*tcrypto.Signer),
+		treeCache:   log.NewCompactTreeCache(),
 	}
 }
 
@@ -58,15 +67,18 @@ func (s *SequencerManager) Name() string {
 
 // ExecutePass performs sequencing for the specified Log.
 func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *LogOperationInfo) (int, error) {
-	// TODO(Martin2112): Honor the sequencing enabled in log parameters, needs an API change
-	// so deferring it
-
 	tree, err := trees.GetTree(ctx, s.registry.AdminStorage, logID, seqOpts)
 	if err != nil {
 		return 0, fmt.Errorf("error retrieving log %v: %v", logID, err)
 	}
 	ctx = trees.NewContext(ctx, tree)
 
+	cfg := tree.GetSequencerConfig()
+	if cfg.GetPaused() {
+		glog.V(1).Infof("%v: sequencing paused", logID)
+		return 0, nil
+	}
+
 	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
 	if err != nil {
 		return 0, fmt.Errorf("error getting hasher for log %v: %v", logID, err)
@@ -78,13 +90,36 @@ func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *L
 	}
 
 	sequencer := log.NewSequencer(hasher, info.TimeSource, s.registry.LogStorage, signer, s.registry.MetricFactory, s.registry.QuotaManager)
-
-	maxRootDuration, err := ptypes.Duration(tree.MaxRootDuration)
+	sequencer.SetCompactTreeCache(s.treeCache)
+	// The tree is read at the start of every pass, so a key added to it takes
+	// over from the next pass onwards.
+	sequencer.SetSuccessorSigners(func(ctx context.Context) ([]log.SuccessorSigner, error) {
+		return s.getSuccessorSigners(ctx, tree)
+	})
+
+	// The tree's sequencer config overrides the defaults this instance was
+	// started with.
+	batchSize := info.BatchSize
+	if cfg.GetBatchSize() > 0 {
+		batchSize = int(cfg.GetBatchSize())
+	}
+	guardWindow := s.guardWindow
+	if cfg.GetGuardWindow() != nil {
+		if guardWindow, err = ptypes.Duration(cfg.GuardWindow); err != nil {
+			glog.Warningf("%v: failed to parse sequencer_config.guard_window, using %v", logID, s.guardWindow)
+			guardWindow = s.guardWindow
+		}
+	}
+	rootDuration := tree.MaxRootDuration
+	if cfg.GetMaxRootDuration() != nil {
+		rootDuration = cfg.MaxRootDuration
+	}
+	maxRootDuration, err := ptypes.Duration(rootDuration)
 	if err != nil {
 		glog.Warning("failed to parse tree.MaxRootDuration, using zero")
 		maxRootDuration = 0
 	}
-	leaves, err := sequencer.IntegrateBatch(ctx, tree, info.BatchSize, s.guardWindow, maxRootDuration)
+	leaves, err := sequencer.IntegrateBatch(ctx, tree, batchSize, guardWindow, maxRootDuration)
 	if err != nil {
 		return 0, fmt.Errorf("failed to integrate batch for %v: %v", logID, err)
 	}
@@ -94,18 +129,43 @@ func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *L
 // getSigner returns a signer for the given tree.
 // Signers are cached, so only one will be created per tree.
 func (s *SequencerManager) getSigner(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
+	return s.cachedSigner(signerKey{tree.GetTreeId(), tree.GetTreeId()}, func() (*tcrypto.Signer, error) {
+		return trees.Signer(ctx, tree)
+	})
+}
+
+// getSuccessorSigners returns the signers of the successor keys in the
+// key_history of the given tree. Signers are cached, so only one will be
+// created per key.
+func (s *SequencerManager) getSuccessorSigners(ctx context.Context, tree *trillian.Tree) ([]log.SuccessorSigner, error) {
+	successors := make([]log.SuccessorSigner, 0, len(tree.KeyHistory))
+	for _, key := range tree.KeyHistory {
+		signer, err := s.cachedSigner(signerKey{tree.GetTreeId(), key.GetKeyId()}, func() (*tcrypto.Signer, error) {
+			return trees.KeySigner(ctx, tree, key)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("key %v: %v", key.GetKeyId(), err)
+		}
+		successors = append(successors, log.SuccessorSigner{StartTreeSize: uint64(key.GetStartTreeSize()), Signer: signer})
+	}
+	return successors, nil
+}
+
+// cachedSigner returns the signer cached for key, calling newSigner to create
+// it if there isn't one.
+func (s *SequencerManager) cachedSigner(key signerKey, newSigner func() (*tcrypto.Signer, error)) (*tcrypto.Signer, error) {
 	s.signersMutex.Lock()
 	defer s.signersMutex.Unlock()
 
-	if signer, ok := s.signers[tree.GetTreeId()]; ok {
+	if signer, ok := s.signers[key]; ok {
 		return signer, nil
 	}
 
-	signer, err := trees.Signer(ctx, tree)
+	signer, err := newSigner()
 	if err != nil {
 		return nil, err
 	}
 
-	s.signers[tree.GetTreeId()] = signer
+	s.signers[key] = signer
 	return signer, nil
 }
@@ -15,6 +15,7 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
@@ -27,16 +28,20 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/merkle/rfc6962"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util"
 
 	tcrypto "github.com/google/trillian/crypto"
+	ktestonly "github.com/google/trillian/crypto/keys/testonly"
 	stestonly "github.com/google/trillian/storage/testonly"
 )
 
@@ -269,6 +274,82 @@ func TestSequencerManagerSingleLogOneLeaf(t *testing.T) {
 	sm.ExecutePass(ctx, logID, createTestInfo(registry))
 }
 
+func TestSequencerManagerKeyHistory(t *testing.T) {
+	ctx := context.Background()
+	keys.RegisterHandler(&keyspb.PrivateKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return der.FromProto(pb.(*keyspb.PrivateKey))
+	})
+	defer keys.UnregisterHandler(&keyspb.PrivateKey{})
+
+	// Memory storage shares a lock between the admin and log storage of a
+	// tree, so this also checks that the tree isn't read while the log is
+	// being written.
+	ls := memory.NewLogStorage(nil)
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ls),
+		LogStorage:   ls,
+		QuotaManager: quota.Noop(),
+	}
+	tree, err := storage.CreateTree(ctx, registry.AdminStorage, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	// The log is initialised before the pass runs.
+	logServer := NewTrillianLogRPCServer(registry, util.NewFakeTimeSource(fakeTime.Add(-time.Second)))
+	if _, err := logServer.InitLog(ctx, &trillian.InitLogRequest{LogId: tree.TreeId}); err != nil {
+		t.Fatalf("InitLog(): %v", err)
+	}
+	if _, err := logServer.QueueLeaves(ctx, &trillian.QueueLeavesRequest{LogId: tree.TreeId, Leaves: []*trillian.LogLeaf{{LeafValue: []byte("leaf")}}}); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+
+	// A successor key takes over from the root of size 1.
+	successorKey, err := ptypes.MarshalAny(&keyspb.PrivateKey{
+		Der: ktestonly.MustMarshalPrivatePEMToDER(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass),
+	})
+	if err != nil {
+		t.Fatalf("MarshalAny(): %v", err)
+	}
+	const successorKeyID = 7
+	if _, err := storage.UpdateTree(ctx, registry.AdminStorage, tree.TreeId, func(tree *trillian.Tree) {
+		tree.KeyHistory = []*trillian.SigningKey{{
+			KeyId:         successorKeyID,
+			StartTreeSize: 1,
+			PrivateKey:    successorKey,
+			PublicKey:     &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)},
+		}}
+	}); err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+
+	sm := NewSequencerManager(registry, zeroDuration)
+	done := make(chan error, 1)
+	go func() {
+		_, err := sm.ExecutePass(ctx, tree.TreeId, createTestInfo(registry))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ExecutePass() returned err = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("ExecutePass() timed out")
+	}
+
+	resp, err := logServer.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId})
+	if err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(resp.SignedLogRoot.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if got, want := resp.SignedLogRoot.KeyHint, types.SerializeKeyHint(successorKeyID); root.TreeSize != 1 || !bytes.Equal(got, want) {
+		t.Errorf("ExecutePass() stored root of size %v with key hint %x, want size 1 with key hint %x", root.TreeSize, got, want)
+	}
+}
+
 func TestSequencerManagerGuardWindow(t *testing.T) {
 	ctx := context.Background()
 	mockCtrl := gomock.NewController(t)
@@ -309,6 +390,80 @@ func TestSequencerManagerGuardWindow(t *testing.T) {
 	sm.ExecutePass(ctx, logID, createTestInfo(registry))
 }
 
+func TestSequencerManagerSequencerConfig(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.SequencerConfig = &trillian.SequencerConfig{
+		BatchSize:   10,
+		GuardWindow: ptypes.DurationProto(3 * time.Second),
+	}
+	logID := tree.GetTreeId()
+	mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+	mockAdmin := &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTx}}
+	mockTx := storage.NewMockLogTreeTX(mockCtrl)
+	fakeStorage := &stestonly.FakeLogStorage{TX: mockTx}
+
+	var keyProto ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(tree.PrivateKey, &keyProto); err != nil {
+		t.Fatalf("Failed to unmarshal tree.PrivateKey: %v", err)
+	}
+
+	keys.RegisterHandler(fakeKeyProtoHandler(keyProto.Message, fixedGoSigner, nil))
+	defer keys.UnregisterHandler(keyProto.Message)
+
+	mockTx.EXPECT().Commit().Return(nil)
+	mockTx.EXPECT().Close().Return(nil)
+	mockTx.EXPECT().WriteRevision().AnyTimes().Return(writeRev)
+	mockTx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(*testSignedRoot0, nil)
+	// Expect the tree's batch size and guard window to override the manager's.
+	mockTx.EXPECT().DequeueLeaves(gomock.Any(), 10, fakeTime.Add(-time.Second*3)).Return([]*trillian.LogLeaf{}, nil)
+
+	mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(tree, nil)
+	mockAdminTx.EXPECT().Commit().Return(nil)
+	mockAdminTx.EXPECT().Close().Return(nil)
+
+	registry := extension.Registry{
+		AdminStorage: mockAdmin,
+		LogStorage:   fakeStorage,
+		QuotaManager: quota.Noop(),
+	}
+
+	sm := NewSequencerManager(registry, time.Second*5)
+	sm.ExecutePass(ctx, logID, createTestInfo(registry))
+}
+
+func TestSequencerManagerPaused(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.SequencerConfig = &trillian.SequencerConfig{Paused: true}
+	logID := tree.GetTreeId()
+	mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+	mockAdmin := &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTx}}
+	// No log storage calls are expected.
+	fakeStorage := &stestonly.FakeLogStorage{TX: storage.NewMockLogTreeTX(mockCtrl)}
+
+	mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(tree, nil)
+	mockAdminTx.EXPECT().Commit().Return(nil)
+	mockAdminTx.EXPECT().Close().Return(nil)
+
+	registry := extension.Registry{
+		AdminStorage: mockAdmin,
+		LogStorage:   fakeStorage,
+		QuotaManager: quota.Noop(),
+	}
+
+	sm := NewSequencerManager(registry, zeroDuration)
+	if got, err := sm.ExecutePass(ctx, logID, createTestInfo(registry)); got != 0 || err != nil {
+		t.Errorf("ExecutePass() = (%v, %v), want (0, nil)", got, err)
+	}
+}
+
 func createTestInfo(registry extension.Registry) *LogOperationInfo {
 	// Set sign interval to 100 years so it won't trigger a root expiry signing unless overridden
 	return &LogOperationInfo{
@@ -17,6 +17,8 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/golang/glog"
@@ -31,6 +33,9 @@ import (
 	"github.com/google/trillian/quota/etcd/quotaapi"
 	"github.com/google/trillian/quota/etcd/quotapb"
 	"github.com/google/trillian/server"
+	"github.com/google/trillian/server/interceptor"
+	"github.com/google/trillian/util"
+	"github.com/google/trillian/util/election"
 	"github.com/google/trillian/util/etcd"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"google.golang.org/grpc"
@@ -47,11 +52,13 @@ import (
 )
 
 var (
-	rpcEndpoint    = flag.String("rpc_endpoint", "localhost:8090", "Endpoint for RPC requests (host:port)")
-	httpEndpoint   = flag.String("http_endpoint", "localhost:8091", "Endpoint for HTTP metrics and REST requests on (host:port, empty means disabled)")
-	healthzTimeout = flag.Duration("healthz_timeout", time.Second*5, "Timeout used during healthz checks")
-	tlsCertFile    = flag.String("tls_cert_file", "", "Path to the TLS server certificate. If unset, the server will use unsecured connections.")
-	tlsKeyFile     = flag.String("tls_key_file", "", "Path to the TLS server key. If unset, the server will use unsecured connections.")
+	rpcEndpoint     = flag.String("rpc_endpoint", "localhost:8090", "Endpoint for RPC requests (host:port)")
+	httpEndpoint    = flag.String("http_endpoint", "localhost:8091", "Endpoint for HTTP metrics and REST requests on (host:port, empty means disabled). REST requests aren't served if --tls_client_ca_file is set")
+	healthzTimeout  = flag.Duration("healthz_timeout", time.Second*5, "Timeout used during healthz checks")
+	tlsCertFile     = flag.String("tls_cert_file", "", "Path to the TLS server certificate. If unset, the server will use unsecured connections.")
+	tlsKeyFile      = flag.String("tls_key_file", "", "Path to the TLS server key. If unset, the server will use unsecured connections.")
+	tlsClientCAFile = flag.String("tls_client_ca_file", "", "Path to the CA certificates used to verify client certificates. If set, clients must authenticate with mutual TLS.")
+	authzPolicyFile = flag.String("authz_policy_file", "", "Path to a JSON policy file which maps client certificate common names to the RPCs and trees they may access. Requires --tls_client_ca_file. If unset, all requests are allowed.")
 
 	quotaDryRun = flag.Bool("quota_dry_run", false, "If true no requests are blocked due to lack of tokens")
 
@@ -59,6 +66,21 @@ var (
 	treeDeleteThreshold      = flag.Duration("tree_delete_threshold", server.DefaultTreeDeleteThreshold, "Minimum period a tree has to remain deleted before being hard-deleted")
 	treeDeleteMinRunInterval = flag.Duration("tree_delete_min_run_interval", server.DefaultTreeDeleteMinInterval, "Minimum interval between tree garbage collection sweeps. Actual runs happen randomly between [minInterval,2*minInterval).")
 
+	mapGCKeepRevisions  = flag.Int64("map_gc_keep_revisions", 0, "If > 0, the number of most recent revisions of each map kept by map revision garbage collection")
+	mapGCKeepDuration   = flag.Duration("map_gc_keep_duration", 0, "If > 0, the age of the oldest revisions of each map kept by map revision garbage collection. Revisions kept by either this or map_gc_keep_revisions are kept. If neither is set, map revision garbage collection is disabled.")
+	mapGCMinRunInterval = flag.Duration("map_gc_min_run_interval", server.DefaultMapRevisionGCMinInterval, "Minimum interval between map revision garbage collection sweeps. Actual runs happen randomly between [minInterval,2*minInterval).")
+
+	mapSignerInterval   = flag.Duration("map_signer_interval", 0, "If > 0, the time between each pass of the map signer, which writes the leaves queued by QueueLeaves to new map revisions. Zero disables the map signer.")
+	mapSignerBatchSize  = flag.Int("map_signer_batch_size", server.DefaultMapSignerBatchSize, "Max number of queued leaves written to each new map revision")
+	forceMaster         = flag.Bool("force_master", false, "If true, assume master for all maps, rather than holding master elections in etcd")
+	instanceID          = flag.String("instance_id", "", "ID of this instance in map master elections, returned to clients writing to maps which another instance is master for. Set it to an address clients can reach this instance at to let them redirect writes. Defaults to <hostname>.<pid>.")
+	lockDir             = flag.String("lock_file_path", "/test/multimaster/map", "etcd lock file directory path")
+	preElectionPause    = flag.Duration("pre_election_pause", 1*time.Second, "Maximum time to wait before starting elections")
+	masterCheckInterval = flag.Duration("master_check_interval", 5*time.Second, "Interval between checking mastership still held")
+	masterHoldInterval  = flag.Duration("master_hold_interval", 60*time.Second, "Minimum interval to hold mastership for")
+	resignOdds          = flag.Int("resign_odds", 10, "Chance of resigning mastership after each check, the N in 1-in-N")
+	mapCheckInterval    = flag.Duration("map_check_interval", 10*time.Second, "Interval between checks for new, deleted and frozen maps, to start and end their master elections")
+
 	tracing          = flag.Bool("tracing", false, "If true opencensus Stackdriver tracing will be enabled. See https://opencensus.io/.")
 	tracingProjectID = flag.String("tracing_project_id", "", "project ID to pass to Stackdriver client. Can be empty for GCP, consult docs for other platforms.")
 	tracingPercent   = flag.Int("tracing_percent", 0, "Percent of requests to be traced. Zero is a special case to use the DefaultSampler")
@@ -102,6 +124,32 @@ func main() {
 		glog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	// Only the elected master for a map writes to it. Without etcd there's no
+	// election, so replicas must not share maps.
+	id := *instanceID
+	if id == "" {
+		hostname, _ := os.Hostname()
+		id = fmt.Sprintf("%s.%d", hostname, os.Getpid())
+	}
+	var electionFactory election.Factory
+	switch {
+	case *forceMaster:
+		glog.Warning("**** Acting as master for all maps ****")
+	case client != nil:
+		electionFactory = etcd.NewElectionFactory(id, client, *lockDir)
+	default:
+		glog.Warning("No --etcd_servers supplied, acting as master for all maps without election")
+	}
+
+	mastership := server.NewMapMastership(electionFactory, election.RunnerConfig{
+		PreElectionPause:    *preElectionPause,
+		MasterCheckInterval: *masterCheckInterval,
+		MasterHoldInterval:  *masterHoldInterval,
+		ResignOdds:          *resignOdds,
+		TimeSource:          util.SystemTimeSource{},
+	}, mf)
+	defer mastership.Close()
+
 	registry := extension.Registry{
 		AdminStorage:  sp.AdminStorage(),
 		MapStorage:    sp.MapStorage(),
@@ -112,16 +160,30 @@ func main() {
 		},
 	}
 
+	var authz interceptor.Authorizer
+	if *authzPolicyFile != "" {
+		if *tlsClientCAFile == "" {
+			glog.Exit("--authz_policy_file requires --tls_client_ca_file: the policy matches client certificates")
+		}
+		policy, err := interceptor.LoadPolicy(*authzPolicyFile)
+		if err != nil {
+			glog.Exitf("Failed to load authorization policy: %v", err)
+		}
+		authz = policy
+	}
+
 	m := server.Main{
-		RPCEndpoint:  *rpcEndpoint,
-		HTTPEndpoint: *httpEndpoint,
-		TLSCertFile:  *tlsCertFile,
-		TLSKeyFile:   *tlsKeyFile,
-		StatsPrefix:  "map",
-		ExtraOptions: options,
-		QuotaDryRun:  *quotaDryRun,
-		DBClose:      sp.Close,
-		Registry:     registry,
+		RPCEndpoint:     *rpcEndpoint,
+		HTTPEndpoint:    *httpEndpoint,
+		TLSCertFile:     *tlsCertFile,
+		TLSKeyFile:      *tlsKeyFile,
+		TLSClientCAFile: *tlsClientCAFile,
+		Authorizer:      authz,
+		StatsPrefix:     "map",
+		ExtraOptions:    options,
+		QuotaDryRun:     *quotaDryRun,
+		DBClose:         sp.Close,
+		Registry:        registry,
 		RegisterHandlerFn: func(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
 			if err := trillian.RegisterTrillianMapHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
 				return err
@@ -132,7 +194,7 @@ func main() {
 			return nil
 		},
 		RegisterServerFn: func(s *grpc.Server, registry extension.Registry) error {
-			mapServer := server.NewTrillianMapServer(registry)
+			mapServer := server.NewTrillianMapServerWithMastership(registry, mastership)
 			if err := mapServer.IsHealthy(); err != nil {
 				return err
 			}
@@ -151,9 +213,21 @@ func main() {
 		TreeGCEnabled:         *treeGCEnabled,
 		TreeDeleteThreshold:   *treeDeleteThreshold,
 		TreeDeleteMinInterval: *treeDeleteMinRunInterval,
+		MapRetention: server.MapRetentionPolicy{
+			KeepRevisions: *mapGCKeepRevisions,
+			KeepDuration:  *mapGCKeepDuration,
+		},
+		MapRevisionGCMinInterval: *mapGCMinRunInterval,
+		MapSigner: server.MapSignerOptions{
+			BatchSize:   *mapSignerBatchSize,
+			RunInterval: *mapSignerInterval,
+			Mastership:  mastership,
+			TimeSource:  util.SystemTimeSource{},
+		},
 	}
 
 	ctx := context.Background()
+	go mastership.Run(ctx, sp.AdminStorage(), *mapCheckInterval)
 	if err := m.Run(ctx); err != nil {
 		glog.Exitf("Server exited with error: %v", err)
 	}
@@ -42,6 +42,9 @@ func validateGetInclusionProofByHashRequest(req *trillian.GetInclusionProofByHas
 	if err := validateLeafHash(req.LeafHash); err != nil {
 		return status.Errorf(codes.InvalidArgument, "GetInclusionProofByHashRequest.LeafHash: %v", err)
 	}
+	if req.MaxResults < 0 {
+		return status.Errorf(codes.InvalidArgument, "GetInclusionProofByHashRequest.MaxResults: %v, want >= 0", req.MaxResults)
+	}
 	return nil
 }
 
@@ -54,6 +57,9 @@ func validateGetLeavesByHashRequest(req *trillian.GetLeavesByHashRequest) error
 			return status.Errorf(codes.InvalidArgument, "GetLeavesByHashRequest.LeafHash[%v]: %v", i, err)
 		}
 	}
+	if req.MaxResults < 0 {
+		return status.Errorf(codes.InvalidArgument, "GetLeavesByHashRequest.MaxResults: %v, want >= 0", req.MaxResults)
+	}
 	return nil
 }
 
@@ -79,6 +85,16 @@ func validateGetLeavesByRangeRequest(req *trillian.GetLeavesByRangeRequest) erro
 	return nil
 }
 
+func validateStreamLeavesRequest(req *trillian.StreamLeavesRequest) error {
+	if req.StartIndex < 0 {
+		return status.Errorf(codes.InvalidArgument, "StreamLeavesRequest.StartIndex: %v, want >= 0", req.StartIndex)
+	}
+	if req.Count < 0 {
+		return status.Errorf(codes.InvalidArgument, "StreamLeavesRequest.Count: %v, want >= 0", req.Count)
+	}
+	return nil
+}
+
 func validateGetConsistencyProofRequest(req *trillian.GetConsistencyProofRequest) error {
 	if req.FirstTreeSize <= 0 {
 		return status.Errorf(codes.InvalidArgument, "GetConsistencyProofRequest.FirstTreeSize: %v, want > 0", req.FirstTreeSize)
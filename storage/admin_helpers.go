@@ -41,12 +41,12 @@ func GetTree(ctx context.Context, admin AdminStorage, treeID int64) (*trillian.T
 // ListTrees reads trees from storage using a snapshot transaction.
 // It's a convenience wrapper around RunInAdminSnapshot and AdminReader's ListTrees.
 // See RunInAdminSnapshot if you need to perform more than one action per transaction.
-func ListTrees(ctx context.Context, admin AdminStorage, includeDeleted bool) ([]*trillian.Tree, error) {
+func ListTrees(ctx context.Context, admin AdminStorage, opts ListTreesOptions) ([]*trillian.Tree, error) {
 	ctx, span := spanFor(ctx, "ListTrees")
 	defer span.End()
 	var resp []*trillian.Tree
 	err := RunInAdminSnapshot(ctx, admin, func(tx ReadOnlyAdminTX) (err error) {
-		resp, err = tx.ListTrees(ctx, includeDeleted)
+		resp, err = tx.ListTrees(ctx, opts)
 		return
 	})
 	return resp, err
@@ -16,6 +16,7 @@ package storage
 
 import (
 	"context"
+	"sort"
 
 	"github.com/google/trillian"
 )
@@ -84,10 +85,58 @@ type AdminReader interface {
 	// so it should be used with caution in production code.
 	ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error)
 
-	// ListTrees returns all trees in storage.
+	// ListTrees returns the trees in storage which match opts, in order of
+	// tree ID.
 	// Note that there's no authorization restriction on the trees returned,
 	// so it should be used with caution in production code.
-	ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error)
+	ListTrees(ctx context.Context, opts ListTreesOptions) ([]*trillian.Tree, error)
+}
+
+// ListTreesOptions selects the trees returned by AdminReader.ListTrees.
+// The zero value selects all trees that aren't soft-deleted.
+type ListTreesOptions struct {
+	// IncludeDeleted includes soft-deleted trees.
+	IncludeDeleted bool
+	// Labels, if set, selects only the trees which have all of these labels,
+	// with the same values.
+	Labels map[string]string
+	// AfterTreeID, if non-zero, selects only the trees with greater IDs.
+	AfterTreeID int64
+	// PageSize, if positive, is the maximum number of trees returned.
+	PageSize int
+}
+
+// Matches returns whether tree is selected by opts, regardless of paging.
+func (opts ListTreesOptions) Matches(tree *trillian.Tree) bool {
+	if tree.Deleted && !opts.IncludeDeleted {
+		return false
+	}
+	if tree.TreeId <= opts.AfterTreeID {
+		return false
+	}
+	for k, v := range opts.Labels {
+		if got, ok := tree.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTrees returns the trees selected by opts, in order of tree ID. It's
+// meant for AdminStorage implementations which can't filter trees as they
+// read them.
+func FilterTrees(trees []*trillian.Tree, opts ListTreesOptions) []*trillian.Tree {
+	ret := []*trillian.Tree{}
+	for _, tree := range trees {
+		if opts.Matches(tree) {
+			ret = append(ret, tree)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].TreeId < ret[j].TreeId })
+	if opts.PageSize > 0 && len(ret) > opts.PageSize {
+		ret = ret[:opts.PageSize]
+	}
+	return ret
 }
 
 // AdminWriter provides a write-only interface for tree data.
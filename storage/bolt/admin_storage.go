@@ -0,0 +1,305 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewAdminStorage returns a BoltDB storage.AdminStorage implementation backed
+// by db, which should have been opened with OpenDB.
+func NewAdminStorage(db *bolt.DB) storage.AdminStorage {
+	return &boltAdminStorage{db}
+}
+
+// boltAdminStorage implements storage.AdminStorage
+type boltAdminStorage struct {
+	db *bolt.DB
+}
+
+func (s *boltAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	return s.beginInternal(ctx, false /* writable */)
+}
+
+func (s *boltAdminStorage) beginInternal(ctx context.Context, writable bool) (storage.AdminTX, error) {
+	tx, err := s.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTX{tx: tx}, nil
+}
+
+func (s *boltAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	tx, err := s.beginInternal(ctx, true /* writable */)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *boltAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return s.db.View(func(*bolt.Tx) error { return nil })
+}
+
+type adminTX struct {
+	tx *bolt.Tx
+
+	// mu guards reads/writes on closed, which happen only on
+	// Commit/Rollback/IsClosed/Close methods.
+	// We don't check closed on *all* methods (apart from the ones above),
+	// as we trust tx to keep tabs on its state (and consequently fail to do
+	// operations after closed).
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (t *adminTX) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if !t.tx.Writable() {
+		// Read-only BoltDB transactions can't be committed, only closed.
+		return t.tx.Rollback()
+	}
+	return t.tx.Commit()
+}
+
+func (t *adminTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return t.tx.Rollback()
+}
+
+func (t *adminTX) IsClosed() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.closed
+}
+
+func (t *adminTX) Close() error {
+	// Acquire and release read lock manually, without defer, as if the txn
+	// is not closed Rollback() will attempt to acquire the rw lock.
+	t.mu.RLock()
+	closed := t.closed
+	t.mu.RUnlock()
+	if !closed {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	v := t.tx.Bucket(treesBucket).Get(treeIDKey(treeID))
+	if v == nil {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	}
+	var tree trillian.Tree
+	if err := proto.Unmarshal(v, &tree); err != nil {
+		return nil, fmt.Errorf("error reading tree %v: %v", treeID, err)
+	}
+	return &tree, nil
+}
+
+func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	trees, err := t.ListTrees(ctx, storage.ListTreesOptions{IncludeDeleted: includeDeleted})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(trees))
+	for _, tree := range trees {
+		ids = append(ids, tree.TreeId)
+	}
+	return ids, nil
+}
+
+func (t *adminTX) ListTrees(ctx context.Context, opts storage.ListTreesOptions) ([]*trillian.Tree, error) {
+	trees := []*trillian.Tree{}
+	err := forEachTree(t.tx, func(tree *trillian.Tree) error {
+		if opts.Matches(tree) {
+			trees = append(trees, tree)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Trees are keyed by ID, so they're read in order; this only truncates
+	// the page.
+	return storage.FilterTrees(trees, opts), nil
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	id, err := storage.NewTreeID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	newTree := *tree
+	newTree.TreeId = id
+	newTree.CreateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build create time: %v", err)
+	}
+	newTree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+
+	if err := t.putTree(&newTree); err != nil {
+		return nil, err
+	}
+	if err := createTreeData(t.tx, newTree.TreeId); err != nil {
+		return nil, err
+	}
+	// Return the tree as stored, so callers see the same proto as later reads.
+	return t.GetTree(ctx, newTree.TreeId)
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	tree, err := t.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeUpdate := *tree
+	updateFunc(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, &beforeUpdate, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	// TODO(pavelkalinnikov): When switching TreeType from PREORDERED_LOG to LOG,
+	// ensure all entries in SequencedLeafData are integrated.
+
+	tree.UpdateTime, err = ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return t.GetTree(ctx, treeID)
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, true /* deleted */)
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, false /* deleted */)
+}
+
+// updateDeleted updates the Deleted and DeleteTime fields of the specified tree.
+func (t *adminTX) updateDeleted(ctx context.Context, treeID int64, deleted bool) (*trillian.Tree, error) {
+	tree, err := t.validateDeleted(ctx, treeID, !deleted)
+	if err != nil {
+		return nil, err
+	}
+	tree.Deleted = deleted
+	tree.DeleteTime = nil
+	if deleted {
+		if tree.DeleteTime, err = ptypes.TimestampProto(time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to build delete time: %v", err)
+		}
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return t.GetTree(ctx, treeID)
+}
+
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	if _, err := t.validateDeleted(ctx, treeID, true /* wantDeleted */); err != nil {
+		return err
+	}
+	if err := t.tx.Bucket(treeDataBucket).DeleteBucket(treeIDKey(treeID)); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	return t.tx.Bucket(treesBucket).Delete(treeIDKey(treeID))
+}
+
+// validateDeleted checks that the specified tree exists and has the wanted
+// deleted state, and returns it.
+func (t *adminTX) validateDeleted(ctx context.Context, treeID int64, wantDeleted bool) (*trillian.Tree, error) {
+	tree, err := t.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case wantDeleted && !tree.Deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v is not soft deleted", treeID)
+	case !wantDeleted && tree.Deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v already soft deleted", treeID)
+	}
+	return tree, nil
+}
+
+// putTree stores tree in the Trees bucket.
+func (t *adminTX) putTree(tree *trillian.Tree) error {
+	v, err := proto.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("could not marshal tree: %v", err)
+	}
+	return t.tx.Bucket(treesBucket).Put(treeIDKey(tree.TreeId), v)
+}
+
+// forEachTree calls f with each of the trees stored in tx.
+func forEachTree(tx *bolt.Tx, f func(*trillian.Tree) error) error {
+	return tx.Bucket(treesBucket).ForEach(func(_, v []byte) error {
+		var tree trillian.Tree
+		if err := proto.Unmarshal(v, &tree); err != nil {
+			return err
+		}
+		return f(&tree)
+	})
+}
+
+func validateStorageSettings(tree *trillian.Tree) error {
+	if tree.StorageSettings != nil {
+		return fmt.Errorf("storage_settings not supported, but got %v", tree.StorageSettings)
+	}
+	return nil
+}
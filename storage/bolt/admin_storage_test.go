@@ -0,0 +1,80 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBoltAdminStorage(t *testing.T) {
+	var dones []func()
+	defer func() {
+		for _, done := range dones {
+			done()
+		}
+	}()
+	tester := &testonly.AdminStorageTester{NewAdminStorage: func() storage.AdminStorage {
+		db, done := openTestDBOrDie(t)
+		dones = append(dones, done)
+		return NewAdminStorage(db)
+	}}
+	tester.RunAllTests(t)
+}
+
+func TestCreateTreeInvalidStates(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	s := NewAdminStorage(db)
+	ctx := context.Background()
+
+	states := []trillian.TreeState{trillian.TreeState_DRAINING, trillian.TreeState_FROZEN}
+
+	for _, state := range states {
+		inTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+		inTree.TreeState = state
+		if _, err := storage.CreateTree(ctx, s, inTree); err == nil {
+			t.Errorf("CreateTree() state: %v got: nil want: err", state)
+		}
+	}
+}
+
+func TestHardDeleteTreeRemovesData(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createTreeOrPanic(db, testonly.LogTree)
+	createFakeSignedLogRoot(db, tree, 0)
+
+	s := NewAdminStorage(db)
+	if _, err := storage.SoftDeleteTree(ctx, s, tree.TreeId); err != nil {
+		t.Fatalf("SoftDeleteTree() = (_, %v), want = (_, nil)", err)
+	}
+	if err := storage.HardDeleteTree(ctx, s, tree.TreeId); err != nil {
+		t.Fatalf("HardDeleteTree() = %v, want = nil", err)
+	}
+
+	_, err := NewLogStorage(db, nil).SnapshotForTree(ctx, tree)
+	if got, want := status.Code(err), codes.NotFound; got != want {
+		t.Errorf("SnapshotForTree() after hard delete = (_, %v), want code %v", err, want)
+	}
+}
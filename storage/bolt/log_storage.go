@@ -0,0 +1,716 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const logIDLabel = "logid"
+
+var (
+	defaultLogStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8}
+
+	once             sync.Once
+	queuedCounter    monitoring.Counter
+	queuedDupCounter monitoring.Counter
+	dequeuedCounter  monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	queuedCounter = mf.NewCounter("bolt_queued_leaves", "Number of leaves queued", logIDLabel)
+	queuedDupCounter = mf.NewCounter("bolt_queued_dup_leaves", "Number of duplicate leaves queued", logIDLabel)
+	dequeuedCounter = mf.NewCounter("bolt_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+}
+
+func labelForTX(t *logTreeTX) string {
+	return strconv.FormatInt(t.treeID, 10)
+}
+
+// unsequencedKey returns the key of a queued leaf in the Unsequenced bucket.
+// Entries are ordered by queue timestamp, then by LeafIdentityHash.
+func unsequencedKey(queueTimestampNanos int64, leafIdentityHash []byte) []byte {
+	return concat(int64Key(queueTimestampNanos), leafIdentityHash)
+}
+
+type boltLogStorage struct {
+	*boltTreeStorage
+	admin         storage.AdminStorage
+	metricFactory monitoring.MetricFactory
+}
+
+// NewLogStorage creates a storage.LogStorage instance backed by the given
+// BoltDB database, which should have been opened with OpenDB.
+// It assumes storage.AdminStorage is backed by the same database as well.
+func NewLogStorage(db *bolt.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &boltLogStorage{
+		admin:           NewAdminStorage(db),
+		boltTreeStorage: newTreeStorage(db),
+		metricFactory:   mf,
+	}
+}
+
+// readOnlyLogTX implements storage.ReadOnlyLogTX
+type readOnlyLogTX struct {
+	tx *bolt.Tx
+}
+
+func (m *boltLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	tx, err := m.db.Begin(false)
+	if err != nil {
+		glog.Warningf("Could not start ReadOnlyLogTX: %s", err)
+		return nil, err
+	}
+	return &readOnlyLogTX{tx}, nil
+}
+
+func (t *readOnlyLogTX) Commit() error {
+	return t.tx.Rollback()
+}
+
+func (t *readOnlyLogTX) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *readOnlyLogTX) Close() error {
+	if err := t.Rollback(); err != nil && err != bolt.ErrTxClosed {
+		glog.Warningf("Rollback error on Close(): %v", err)
+		return err
+	}
+	return nil
+}
+
+func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	ids := []int64{}
+	err := forEachTree(t.tx, func(tree *trillian.Tree) error {
+		// Include logs that are DRAINING in the active list as we're still
+		// integrating leaves into them.
+		switch {
+		case tree.Deleted:
+		case tree.TreeType != trillian.TreeType_LOG && tree.TreeType != trillian.TreeType_PREORDERED_LOG:
+		case tree.TreeState != trillian.TreeState_ACTIVE && tree.TreeState != trillian.TreeState_DRAINING:
+		default:
+			ids = append(ids, tree.TreeId)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func (t *readOnlyLogTX) GetUnsequencedCounts(ctx context.Context) (storage.CountByLogID, error) {
+	ret := make(map[int64]int64)
+	err := t.tx.Bucket(treeDataBucket).ForEach(func(k, _ []byte) error {
+		data := t.tx.Bucket(treeDataBucket).Bucket(k)
+		if data == nil {
+			return nil
+		}
+		if n := data.Bucket(unsequencedBucket).Stats().KeyN; n > 0 {
+			ret[keyInt64(k)] = int64(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (m *boltLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree, readonly bool) (*logTreeTX, error) {
+	once.Do(func() {
+		createMetrics(m.metricFactory)
+	})
+	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stCache := cache.NewLogSubtreeCache(defaultLogStrata, hasher)
+	ttx, err := m.beginTreeTX(ctx, tree, hasher.Size(), stCache, readonly)
+	if err != nil {
+		return nil, err
+	}
+
+	ltx := &logTreeTX{
+		treeTX: ttx,
+		ls:     m,
+	}
+	ltx.slr, err = ltx.fetchLatestRoot(ctx)
+	if err == storage.ErrTreeNeedsInit {
+		return ltx, err
+	} else if err != nil {
+		ttx.Rollback()
+		return nil, err
+	}
+
+	if err := ltx.root.UnmarshalBinary(ltx.slr.LogRoot); err != nil {
+		ttx.Rollback()
+		return nil, err
+	}
+
+	ltx.treeTX.writeRevision = int64(ltx.root.Revision) + 1
+	return ltx, nil
+}
+
+func (m *boltLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	tx, err := m.beginInternal(ctx, tree, false /* readonly */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *boltLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, false /* readonly */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if AddSequencedLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.AddSequencedLeaves(ctx, leaves, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *boltLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := m.beginInternal(ctx, tree, true /* readonly */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return nil, err
+	}
+	return tx, err
+}
+
+func (m *boltLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, false /* readonly */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if QueueLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing, err := tx.QueueLeaves(ctx, leaves, queueTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+	for i, e := range existing {
+		if e != nil {
+			ret[i] = &trillian.QueuedLogLeaf{
+				Leaf:   e,
+				Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", e.LeafIdentityHash).Proto(),
+			}
+			continue
+		}
+		ret[i] = &trillian.QueuedLogLeaf{Leaf: leaves[i]}
+	}
+	return ret, nil
+}
+
+type logTreeTX struct {
+	treeTX
+	ls   *boltLogStorage
+	root types.LogRootV1
+	slr  trillian.SignedLogRoot
+}
+
+func (t *logTreeTX) ReadRevision() int64 {
+	return int64(t.root.Revision)
+}
+
+func (t *logTreeTX) WriteRevision() int64 {
+	return t.treeTX.writeRevision
+}
+
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	var leaves []*trillian.LogLeaf
+	err := t.do(func() error {
+		var keys [][]byte
+		var err error
+		leaves, keys, err = t.queuedLeaves(limit, cutoffTime)
+		if err != nil {
+			return err
+		}
+
+		// The convention is that if leaf processing succeeds (by committing this tx)
+		// then the unsequenced entries for them are removed.
+		b := t.bucket(unsequencedBucket)
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return leaves, nil
+}
+
+// PeekQueuedLeaves implements storage.QueuePeeker.
+func (t *logTreeTX) PeekQueuedLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	var leaves []*trillian.LogLeaf
+	err := t.do(func() error {
+		var err error
+		leaves, _, err = t.queuedLeaves(limit, cutoffTime)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// queuedLeaves returns up to limit leaves queued no later than cutoffTime from
+// the head of the queue, and their keys in the unsequenced bucket. It must be
+// called from within t.do.
+func (t *logTreeTX) queuedLeaves(limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, [][]byte, error) {
+	leaves := make([]*trillian.LogLeaf, 0, limit)
+	var keys [][]byte
+	c := t.bucket(unsequencedBucket).Cursor()
+	for k, v := c.First(); k != nil && len(leaves) < limit; k, v = c.Next() {
+		queueTimestamp := keyInt64(k)
+		if queueTimestamp > cutoffTime.UnixNano() {
+			break
+		}
+		leafIDHash := k[8:]
+		if len(leafIDHash) != t.hashSizeBytes {
+			return nil, nil, errors.New("dequeued a leaf with incorrect hash size")
+		}
+		queueTimestampProto, err := ptypes.TimestampProto(time.Unix(0, queueTimestamp))
+		if err != nil {
+			return nil, nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		// Note: the LeafData and ExtraData being nil here is OK as this is only used by the
+		// sequencer. The sequencer only writes to the SequencedLeafData bucket and the client
+		// supplied data was already written to LeafData as part of queueing the leaf.
+		leaves = append(leaves, &trillian.LogLeaf{
+			LeafIdentityHash: append([]byte(nil), leafIDHash...),
+			MerkleLeafHash:   append([]byte(nil), v...),
+			QueueTimestamp:   queueTimestampProto,
+		})
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	return leaves, keys, nil
+}
+
+func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	// Don't accept batches if any of the leaves are invalid.
+	for _, leaf := range leaves {
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, fmt.Errorf("queued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
+		}
+		var err error
+		leaf.QueueTimestamp, err = ptypes.TimestampProto(queueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+	}
+	label := labelForTX(t)
+
+	existingLeaves := make([]*trillian.LogLeaf, len(leaves))
+	err := t.do(func() error {
+		leafData := t.bucket(leafDataBucket)
+		for i, leaf := range leaves {
+			if v := leafData.Get(leaf.LeafIdentityHash); v != nil {
+				var existing trillian.LogLeaf
+				if err := proto.Unmarshal(v, &existing); err != nil {
+					return fmt.Errorf("failed to retrieve existing leaf: %v", err)
+				}
+				existingLeaves[i] = &existing
+				queuedDupCounter.Inc(label)
+				continue
+			}
+			if err := putLeafData(leafData, leaf); err != nil {
+				glog.Warningf("Error inserting %d into LeafData: %s", i, err)
+				return err
+			}
+
+			// Create the work queue entry
+			key := unsequencedKey(queueTimestamp.UnixNano(), leaf.LeafIdentityHash)
+			if err := t.bucket(unsequencedBucket).Put(key, leaf.MerkleLeafHash); err != nil {
+				glog.Warningf("Error inserting into Unsequenced: %s", err)
+				return fmt.Errorf("Unsequenced: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	queuedCounter.Add(float64(len(leaves)), label)
+
+	return existingLeaves, nil
+}
+
+// putLeafData stores the client-supplied parts of leaf in the LeafData bucket
+// b, keyed by its LeafIdentityHash.
+func putLeafData(b *bolt.Bucket, leaf *trillian.LogLeaf) error {
+	v, err := proto.Marshal(&trillian.LogLeaf{
+		LeafIdentityHash: leaf.LeafIdentityHash,
+		MerkleLeafHash:   leaf.MerkleLeafHash,
+		LeafValue:        leaf.LeafValue,
+		ExtraData:        leaf.ExtraData,
+		QueueTimestamp:   leaf.QueueTimestamp,
+	})
+	if err != nil {
+		return err
+	}
+	return b.Put(leaf.LeafIdentityHash, v)
+}
+
+// putSequencedLeaf records that leaf has been assigned its LeafIndex, and
+// indexes it by MerkleLeafHash.
+func (t *logTreeTX) putSequencedLeaf(leaf *trillian.LogLeaf) error {
+	v, err := proto.Marshal(&trillian.LogLeaf{
+		LeafIdentityHash:   leaf.LeafIdentityHash,
+		MerkleLeafHash:     leaf.MerkleLeafHash,
+		LeafIndex:          leaf.LeafIndex,
+		IntegrateTimestamp: leaf.IntegrateTimestamp,
+	})
+	if err != nil {
+		return err
+	}
+	seq := int64Key(leaf.LeafIndex)
+	if err := t.bucket(sequencedLeafDataBucket).Put(seq, v); err != nil {
+		return err
+	}
+	return t.bucket(merkleLeafHashBucket).Put(concat(leaf.MerkleLeafHash, seq), []byte{})
+}
+
+func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	res := make([]*trillian.QueuedLogLeaf, len(leaves))
+	ok := status.New(codes.OK, "OK").Proto()
+
+	queueTimestamp, err := ptypes.TimestampProto(timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	// TODO(pavelkalinnikov): Update IntegrateTimestamp on integrating the leaf.
+	integrateTimestamp, err := ptypes.TimestampProto(time.Unix(0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.do(func() error {
+		leafData := t.bucket(leafDataBucket)
+		sequenced := t.bucket(sequencedLeafDataBucket)
+		for i, leaf := range leaves {
+			if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+				return status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+			}
+
+			res[i] = &trillian.QueuedLogLeaf{Status: ok}
+
+			// TODO(pavelkalinnikov): Support opting out from duplicates detection.
+			if leafData.Get(leaf.LeafIdentityHash) != nil {
+				res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIdentityHash").Proto()
+				continue
+			}
+			if sequenced.Get(int64Key(leaf.LeafIndex)) != nil {
+				res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
+				continue
+			}
+
+			stored := *leaf
+			stored.QueueTimestamp = queueTimestamp
+			stored.IntegrateTimestamp = integrateTimestamp
+			if err := putLeafData(leafData, &stored); err != nil {
+				glog.Errorf("Error inserting leaves[%d] into LeafData: %s", i, err)
+				return err
+			}
+			if err := t.putSequencedLeaf(&stored); err != nil {
+				glog.Errorf("Error inserting leaves[%d] into SequencedLeafData: %s", i, err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	return t.do(func() error {
+		for _, leaf := range leaves {
+			// This should fail on insert but catch it early
+			if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+				return errors.New("sequenced leaf has incorrect hash size")
+			}
+			if _, err := ptypes.Timestamp(leaf.IntegrateTimestamp); err != nil {
+				return fmt.Errorf("got invalid integrate timestamp: %v", err)
+			}
+			if t.bucket(sequencedLeafDataBucket).Get(int64Key(leaf.LeafIndex)) != nil {
+				return fmt.Errorf("leaf index %d is already sequenced", leaf.LeafIndex)
+			}
+			if err := t.putSequencedLeaf(leaf); err != nil {
+				glog.Warningf("Failed to update sequenced leaves: %s", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := t.do(func() error {
+		count = int64(t.bucket(sequencedLeafDataBucket).Stats().KeyN)
+		return nil
+	})
+	return count, err
+}
+
+// getSequencedLeaf returns the leaf at sequence number seq, or nil if there is
+// no such leaf. It must only be called from within a function passed to t.do.
+func (t *logTreeTX) getSequencedLeaf(seq int64) (*trillian.LogLeaf, error) {
+	v := t.bucket(sequencedLeafDataBucket).Get(int64Key(seq))
+	if v == nil {
+		return nil, nil
+	}
+	var sequenced trillian.LogLeaf
+	if err := proto.Unmarshal(v, &sequenced); err != nil {
+		return nil, err
+	}
+	v = t.bucket(leafDataBucket).Get(sequenced.LeafIdentityHash)
+	if v == nil {
+		return nil, fmt.Errorf("no LeafData for sequenced leaf %d", seq)
+	}
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(v, &leaf); err != nil {
+		return nil, err
+	}
+	leaf.MerkleLeafHash = sequenced.MerkleLeafHash
+	leaf.LeafIndex = sequenced.LeafIndex
+	leaf.IntegrateTimestamp = sequenced.IntegrateTimestamp
+	return &leaf, nil
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		for _, leaf := range leaves {
+			if leaf < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+			}
+			if leaf >= treeSize {
+				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+			}
+		}
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, len(leaves))
+	err := t.do(func() error {
+		for _, seq := range leaves {
+			leaf, err := t.getSequencedLeaf(seq)
+			if err != nil {
+				glog.Warningf("Failed to get leaves by idx: %s", err)
+				return err
+			}
+			if leaf != nil {
+				ret = append(ret, leaf)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if got, want := len(ret), len(leaves); got != want {
+		return nil, status.Errorf(codes.Internal, "len(ret): %d, want %d", got, want)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if start < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+	}
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		if treeSize <= 0 {
+			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+		} else if start >= treeSize {
+			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+		}
+		// Ensure no entries queried/returned beyond the tree.
+		if maxCount := treeSize - start; count > maxCount {
+			count = maxCount
+		}
+	}
+
+	var ret []*trillian.LogLeaf
+	err := t.do(func() error {
+		for seq := start; seq < start+count; seq++ {
+			leaf, err := t.getSequencedLeaf(seq)
+			if err != nil {
+				glog.Warningf("Failed to get leaves by range: %s", err)
+				return err
+			}
+			if leaf == nil {
+				if seq < int64(t.root.TreeSize) {
+					return fmt.Errorf("missing leaf at index %d", seq)
+				}
+				break
+			}
+			ret = append(ret, leaf)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool, start, limit int64) ([]*trillian.LogLeaf, error) {
+	var ret []*trillian.LogLeaf
+	err := t.do(func() error {
+		// The index keys hold the sequence numbers, so find the wanted ones
+		// before reading any leaves.
+		var seqs []int64
+		c := t.bucket(merkleLeafHashBucket).Cursor()
+		for _, hash := range leafHashes {
+			// The tree could include duplicates so we don't know how many results will be returned.
+			for k, _ := c.Seek(hash); k != nil && bytes.HasPrefix(k, hash) && len(k) == len(hash)+8; k, _ = c.Next() {
+				if seq := keyInt64(k[len(hash):]); seq >= start {
+					seqs = append(seqs, seq)
+				}
+			}
+		}
+		if orderBySequence || limit > 0 {
+			sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+		}
+		if limit > 0 && int64(len(seqs)) > limit {
+			seqs = seqs[:limit]
+		}
+
+		for _, seq := range seqs {
+			leaf, err := t.getSequencedLeaf(seq)
+			if err != nil {
+				return err
+			}
+			if leaf == nil {
+				return fmt.Errorf("LogID: %d no sequenced leaf for index %d", t.treeID, seq)
+			}
+			if got, want := len(leaf.MerkleLeafHash), t.hashSizeBytes; got != want {
+				return fmt.Errorf("LogID: %d Scanned leaf does not have hash length %d, got %d", t.treeID, want, got)
+			}
+			ret = append(ret, leaf)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (trillian.SignedLogRoot, error) {
+	return t.slr, nil
+}
+
+// fetchLatestRoot reads the latest SignedLogRoot from the DB and returns it.
+func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (trillian.SignedLogRoot, error) {
+	var slr trillian.SignedLogRoot
+	err := t.do(func() error {
+		_, v := t.bucket(treeHeadBucket).Cursor().Last()
+		if v == nil {
+			// It's possible there are no roots for this tree yet
+			return storage.ErrTreeNeedsInit
+		}
+		return proto.Unmarshal(v, &slr)
+	})
+	if err != nil {
+		return trillian.SignedLogRoot{}, err
+	}
+	return slr, nil
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root trillian.SignedLogRoot) error {
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.LogRoot); err != nil {
+		glog.Warningf("Failed to parse log root: %x %v", root.LogRoot, err)
+		return err
+	}
+
+	rootBytes, err := proto.Marshal(&root)
+	if err != nil {
+		return err
+	}
+	return t.do(func() error {
+		b := t.bucket(treeHeadBucket)
+		k := int64Key(int64(logRoot.TimestampNanos))
+		if b.Get(k) != nil {
+			return fmt.Errorf("tree head with timestamp %d already exists", logRoot.TimestampNanos)
+		}
+		if err := b.Put(k, rootBytes); err != nil {
+			glog.Warningf("Failed to store signed root: %s", err)
+			return err
+		}
+		return nil
+	})
+}
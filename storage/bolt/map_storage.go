@@ -0,0 +1,404 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var defaultMapStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}
+
+// mapTXKey is the context key under which ReadWriteTransaction stores the
+// transaction it passes to its MapTXFunc. Nested ReadWriteTransaction calls
+// for the same tree (as made by merkle.SparseMerkleTreeWriter) join that
+// transaction rather than starting their own, as BoltDB only allows a single
+// writable transaction at a time.
+type mapTXKey struct {
+	treeID int64
+}
+
+type boltMapStorage struct {
+	*boltTreeStorage
+	admin storage.AdminStorage
+}
+
+// NewMapStorage creates a storage.MapStorage instance backed by the given
+// BoltDB database, which should have been opened with OpenDB.
+// It assumes storage.AdminStorage is backed by the same database as well.
+func NewMapStorage(db *bolt.DB) storage.MapStorage {
+	return &boltMapStorage{
+		admin:           NewAdminStorage(db),
+		boltTreeStorage: newTreeStorage(db),
+	}
+}
+
+type readOnlyMapTX struct {
+	tx *bolt.Tx
+}
+
+func (m *boltMapStorage) Snapshot(ctx context.Context) (storage.ReadOnlyMapTX, error) {
+	tx, err := m.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyMapTX{tx}, nil
+}
+
+func (t *readOnlyMapTX) Commit() error {
+	return t.tx.Rollback()
+}
+
+func (t *readOnlyMapTX) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *readOnlyMapTX) Close() error {
+	if err := t.Rollback(); err != nil && err != bolt.ErrTxClosed {
+		glog.Warningf("Rollback error on Close(): %v", err)
+		return err
+	}
+	return nil
+}
+
+func (m *boltMapStorage) begin(ctx context.Context, tree *trillian.Tree, readonly bool) (*mapTreeTX, error) {
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stCache := cache.NewMapSubtreeCache(defaultMapStrata, tree.TreeId, hasher)
+	ttx, err := m.beginTreeTX(ctx, tree, hasher.Size(), stCache, readonly)
+	if err != nil {
+		return nil, err
+	}
+
+	mtx := &mapTreeTX{
+		treeTX:       ttx,
+		ms:           m,
+		readRevision: -1,
+	}
+
+	if readonly {
+		// readRevision will be set later, by the first
+		// GetSignedMapRoot/LatestSignedMapRoot operation.
+		return mtx, nil
+	}
+
+	// A read-write transaction needs to know the current revision
+	// so it can write at revision+1.
+	root, err := mtx.LatestSignedMapRoot(ctx)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		mtx.Rollback()
+		return nil, err
+	}
+	if err == storage.ErrTreeNeedsInit {
+		return mtx, err
+	}
+
+	var mr types.MapRootV1
+	if err := mr.UnmarshalBinary(root.MapRoot); err != nil {
+		mtx.Rollback()
+		return nil, err
+	}
+
+	mtx.readRevision = int64(mr.Revision)
+	mtx.treeTX.writeRevision = int64(mr.Revision) + 1
+	return mtx, nil
+}
+
+// join returns a new transaction which shares parent's underlying BoltDB
+// transaction and revisions, but has its own subtree cache. Committing it
+// flushes its cache to the shared transaction, but only parent may commit the
+// shared transaction itself.
+func (m *boltMapStorage) join(parent *mapTreeTX, tree *trillian.Tree) (*mapTreeTX, error) {
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stCache := cache.NewMapSubtreeCache(defaultMapStrata, tree.TreeId, hasher)
+	ttx, err := newTreeTX(parent.shared, tree, hasher.Size(), stCache)
+	if err != nil {
+		return nil, err
+	}
+	ttx.writeRevision = parent.writeRevision
+
+	return &mapTreeTX{
+		treeTX:       ttx,
+		ms:           m,
+		readRevision: parent.readRevision,
+	}, nil
+}
+
+func (m *boltMapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	tx, err := m.begin(ctx, tree, true /* readonly */)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (m *boltMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	if parent, ok := ctx.Value(mapTXKey{tree.TreeId}).(*mapTreeTX); ok {
+		tx, err := m.join(parent, tree)
+		if err != nil {
+			return err
+		}
+		defer tx.Close()
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	tx, err := m.begin(ctx, tree, false /* readonly */)
+	if tx != nil {
+		defer tx.Close()
+	}
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	if err := f(context.WithValue(ctx, mapTXKey{tree.TreeId}, tx), tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type mapTreeTX struct {
+	treeTX
+	ms           *boltMapStorage
+	readRevision int64
+}
+
+// mapLeafKey returns the key under which the value of keyHash at revision rev
+// is stored in the MapLeaf bucket.
+func mapLeafKey(keyHash []byte, rev int64) []byte {
+	return concat(keyHash, int64Key(rev))
+}
+
+func (m *mapTreeTX) ReadRevision() int64 {
+	return m.readRevision
+}
+
+func (m *mapTreeTX) WriteRevision() int64 {
+	return m.treeTX.writeRevision
+}
+
+func (m *mapTreeTX) Set(ctx context.Context, keyHash []byte, value trillian.MapLeaf) error {
+	flatValue, err := proto.Marshal(&value)
+	if err != nil {
+		return err
+	}
+	return m.do(func() error {
+		b := m.bucket(mapLeafBucket)
+		k := mapLeafKey(keyHash, m.writeRevision)
+		if b.Get(k) != nil {
+			return fmt.Errorf("key %x already set at revision %d", keyHash, m.writeRevision)
+		}
+		return b.Put(k, flatValue)
+	})
+}
+
+// Get returns a list of map leaves indicated by indexes.
+// If an index is not found, no corresponding entry is returned.
+// Each MapLeaf.Index is overwritten with the index the leaf was found at.
+func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) ([]trillian.MapLeaf, error) {
+	if revision < 0 {
+		revision = math.MaxInt64
+	}
+	ret := make([]trillian.MapLeaf, 0, len(indexes))
+	err := m.do(func() error {
+		b := m.bucket(mapLeafBucket)
+		for _, index := range indexes {
+			flatData := seekAtOrBelow(b, index, int64Key(revision))
+			if len(flatData) == 0 {
+				continue
+			}
+			var mapLeaf trillian.MapLeaf
+			if err := proto.Unmarshal(flatData, &mapLeaf); err != nil {
+				return err
+			}
+			mapLeaf.Index = append([]byte(nil), index...)
+			ret = append(ret, mapLeaf)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetLeavesAfter returns up to limit non-empty leaves at revision whose
+// indexes sort after keyHash, in ascending index order.
+// All of the indexes stored in a map are expected to be the same length.
+func (m *mapTreeTX) GetLeavesAfter(ctx context.Context, revision int64, keyHash []byte, limit int) ([]trillian.MapLeaf, error) {
+	if revision < 0 {
+		revision = math.MaxInt64
+	}
+	ret := make([]trillian.MapLeaf, 0)
+	err := m.do(func() error {
+		var curIndex, curValue []byte
+		flush := func() error {
+			if len(curValue) == 0 {
+				return nil
+			}
+			var mapLeaf trillian.MapLeaf
+			if err := proto.Unmarshal(curValue, &mapLeaf); err != nil {
+				return err
+			}
+			if len(mapLeaf.LeafValue) == 0 {
+				return nil
+			}
+			mapLeaf.Index = append([]byte(nil), curIndex...)
+			ret = append(ret, mapLeaf)
+			return nil
+		}
+
+		c := m.bucket(mapLeafBucket).Cursor()
+		k, v := c.First()
+		if len(keyHash) > 0 {
+			k, v = c.Seek(mapLeafKey(keyHash, math.MaxInt64))
+		}
+		for ; k != nil && len(ret) < limit; k, v = c.Next() {
+			index, rev := k[:len(k)-8], keyInt64(k[len(k)-8:])
+			if bytes.Compare(index, keyHash) <= 0 {
+				continue
+			}
+			if !bytes.Equal(index, curIndex) {
+				if err := flush(); err != nil {
+					return err
+				}
+				curIndex, curValue = index, nil
+			}
+			if rev <= revision {
+				curValue = v
+			}
+		}
+		if len(ret) < limit {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetLeafHistory returns the values written to keyHash at revisions between
+// fromRevision and toRevision inclusive, in ascending revision order.
+func (m *mapTreeTX) GetLeafHistory(ctx context.Context, keyHash []byte, fromRevision, toRevision int64) ([]storage.MapLeafRevision, error) {
+	ret := make([]storage.MapLeafRevision, 0)
+	err := m.do(func() error {
+		c := m.bucket(mapLeafBucket).Cursor()
+		for k, v := c.Seek(mapLeafKey(keyHash, fromRevision)); k != nil; k, v = c.Next() {
+			if len(k) != len(keyHash)+8 || !bytes.HasPrefix(k, keyHash) {
+				break
+			}
+			rev := keyInt64(k[len(keyHash):])
+			if rev > toRevision {
+				break
+			}
+			var mapLeaf trillian.MapLeaf
+			if err := proto.Unmarshal(v, &mapLeaf); err != nil {
+				return err
+			}
+			mapLeaf.Index = append([]byte(nil), keyHash...)
+			ret = append(ret, storage.MapLeafRevision{Revision: rev, Leaf: mapLeaf})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (trillian.SignedMapRoot, error) {
+	var root trillian.SignedMapRoot
+	err := m.do(func() error {
+		v := m.bucket(mapHeadBucket).Get(int64Key(revision))
+		if v == nil {
+			if revision == 0 {
+				return storage.ErrTreeNeedsInit
+			}
+			return status.Errorf(codes.NotFound, "no map root for revision %d", revision)
+		}
+		return proto.Unmarshal(v, &root)
+	})
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	m.readRevision = revision
+	return root, nil
+}
+
+func (m *mapTreeTX) LatestSignedMapRoot(ctx context.Context) (trillian.SignedMapRoot, error) {
+	var root trillian.SignedMapRoot
+	var revision int64
+	err := m.do(func() error {
+		k, v := m.bucket(mapHeadBucket).Cursor().Last()
+		// It's possible there are no roots for this tree yet
+		if k == nil {
+			return storage.ErrTreeNeedsInit
+		}
+		revision = keyInt64(k)
+		return proto.Unmarshal(v, &root)
+	})
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	m.readRevision = revision
+	return root, nil
+}
+
+func (m *mapTreeTX) StoreSignedMapRoot(ctx context.Context, root trillian.SignedMapRoot) error {
+	var r types.MapRootV1
+	if err := r.UnmarshalBinary(root.MapRoot); err != nil {
+		return err
+	}
+	rootBytes, err := proto.Marshal(&root)
+	if err != nil {
+		return err
+	}
+
+	return m.do(func() error {
+		b := m.bucket(mapHeadBucket)
+		k := int64Key(int64(r.Revision))
+		if b.Get(k) != nil {
+			return storage.ErrMapRevisionExists
+		}
+		if err := b.Put(k, rootBytes); err != nil {
+			glog.Warningf("Failed to store signed map root: %s", err)
+			return err
+		}
+		return nil
+	})
+}
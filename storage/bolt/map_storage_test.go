@@ -0,0 +1,731 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+	storageto "github.com/google/trillian/storage/testonly"
+)
+
+var fixedSigner = tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+
+func MustSignMapRoot(root *types.MapRootV1) *trillian.SignedMapRoot {
+	r, err := fixedSigner.SignMapRoot(root)
+	if err != nil {
+		panic(fmt.Sprintf("SignMapRoot(): %v", err))
+	}
+	return r
+}
+
+func TestBoltMapStorage_CheckDatabaseAccessible(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	s := NewMapStorage(db)
+	if err := s.CheckDatabaseAccessible(context.Background()); err != nil {
+		t.Errorf("CheckDatabaseAccessible() = %v, want = nil", err)
+	}
+}
+
+func TestMapSnapshot(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+
+	frozenMap := createInitializedMapForTests(ctx, t, db)
+	if _, err := updateTree(db, frozenMap.TreeId, func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_FROZEN
+	}); err != nil {
+		t.Fatalf("Error updating frozen tree: %v", err)
+	}
+
+	activeMap := createInitializedMapForTests(ctx, t, db)
+	logID := createTreeOrPanic(db, storageto.LogTree).TreeId
+
+	tests := []struct {
+		desc    string
+		tree    *trillian.Tree
+		wantErr bool
+	}{
+		{
+			desc: "activeMapSnapshot",
+			tree: activeMap,
+		},
+		{
+			desc: "frozenSnapshot",
+			tree: frozenMap,
+		},
+		{
+			desc:    "logSnapshot",
+			tree:    mapTree(logID),
+			wantErr: true,
+		},
+	}
+
+	s := NewMapStorage(db)
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			tx, err := s.SnapshotForTree(ctx, test.tree)
+			if err != nil {
+				t.Fatalf("SnapshotForTree()=_,%v; want _, nil", err)
+			}
+			defer tx.Close()
+
+			_, err = tx.LatestSignedMapRoot(ctx)
+			if gotErr := (err != nil); gotErr != test.wantErr {
+				t.Errorf("LatestSignedMapRoot()=_,%v; want _, err? %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				t.Errorf("Commit()=_,%v; want _,nil", err)
+			}
+		})
+	}
+}
+
+func TestMapSnapshotUnknownTree(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	s := NewMapStorage(db)
+
+	if _, err := s.SnapshotForTree(context.Background(), mapTree(-1)); err == nil {
+		t.Error("SnapshotForTree() for unknown tree = (_, nil), want (_, err)")
+	}
+}
+
+func TestMapReadWriteTransaction(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	activeMap := createInitializedMapForTests(ctx, t, db)
+	newMap := createTreeOrPanic(db, storageto.MapTree)
+
+	tests := []struct {
+		desc        string
+		tree        *trillian.Tree
+		wantRev     int64
+		wantTXRev   int64
+		wantRootErr string
+	}{
+		{
+			desc:        "uninitializedBegin",
+			tree:        newMap,
+			wantRev:     0,
+			wantTXRev:   -1,
+			wantRootErr: "needs initialising",
+		},
+		{
+			desc:      "activeMapBegin",
+			tree:      activeMap,
+			wantRev:   0,
+			wantTXRev: 1,
+		},
+	}
+
+	s := NewMapStorage(db)
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := s.ReadWriteTransaction(ctx, test.tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+				root, err := tx.LatestSignedMapRoot(ctx)
+				if err != nil {
+					if !strings.Contains(err.Error(), test.wantRootErr) {
+						t.Errorf("LatestSignedMapRoot() returned err = %v", err)
+					}
+					return nil
+				}
+				if len(test.wantRootErr) != 0 {
+					t.Fatalf("LatestSignedMapRoot() returned err = %v, want: nil", err)
+				}
+				var mapRoot types.MapRootV1
+				if err := mapRoot.UnmarshalBinary(root.MapRoot); err != nil {
+					t.Fatalf("UmarshalBinary(): %v", err)
+				}
+				if got, want := tx.WriteRevision(), test.wantTXRev; got != want {
+					t.Errorf("WriteRevision() = %v, want = %v", got, want)
+				}
+				if got, want := int64(mapRoot.Revision), test.wantRev; got != want {
+					t.Errorf("TreeRevision() = %v, want = %v", got, want)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ReadWriteTransaction() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+// TestMapNestedReadWriteTransaction checks that ReadWriteTransaction calls
+// made from within another ReadWriteTransaction for the same map, as
+// SparseMerkleTreeWriter does, don't deadlock and are committed along with it.
+func TestMapNestedReadWriteTransaction(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewMapHasher(): %v", err)
+	}
+
+	var leaves []merkle.HashKeyValue
+	for i := 0; i < 10; i++ {
+		key := sha256.Sum256([]byte(fmt.Sprintf("key-%d", i)))
+		value := sha256.Sum256([]byte(fmt.Sprintf("value-%d", i)))
+		leaves = append(leaves, merkle.HashKeyValue{HashedKey: key[:], HashedValue: value[:]})
+	}
+
+	var rev int64
+	var rootHash []byte
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		rev = tx.WriteRevision()
+		w, err := merkle.NewSparseMerkleTreeWriter(ctx, tree.TreeId, rev, hasher,
+			func(ctx context.Context, f func(context.Context, storage.MapTreeTX) error) error {
+				return s.ReadWriteTransaction(ctx, tree, f)
+			})
+		if err != nil {
+			t.Fatalf("NewSparseMerkleTreeWriter(): %v", err)
+		}
+		if err := w.SetLeaves(ctx, leaves); err != nil {
+			t.Fatalf("SetLeaves(): %v", err)
+		}
+		if rootHash, err = w.CalculateRoot(); err != nil {
+			t.Fatalf("CalculateRoot(): %v", err)
+		}
+		return tx.StoreSignedMapRoot(ctx, *MustSignMapRoot(&types.MapRootV1{
+			TimestampNanos: 98765,
+			Revision:       uint64(rev),
+			RootHash:       rootHash,
+		}))
+	})
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	got, err := merkle.NewSparseMerkleTreeReader(rev, hasher, tx).RootAtRevision(ctx, rev)
+	if err != nil {
+		t.Fatalf("RootAtRevision(%d): %v", rev, err)
+	}
+	if !bytes.Equal(got, rootHash) {
+		t.Errorf("RootAtRevision(%d) = %x, want %x", rev, got, rootHash)
+	}
+	commit(tx, t)
+}
+
+func TestMapRootUpdate(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	populatedMetadata := []byte("some metadata")
+
+	for _, tc := range []struct {
+		desc         string
+		root         *trillian.SignedMapRoot
+		wantMetadata []byte
+	}{
+		{
+			desc: "Initial root",
+			root: MustSignMapRoot(&types.MapRootV1{
+				TimestampNanos: 98765,
+				Revision:       5,
+				RootHash:       []byte(dummyHash),
+			}),
+		},
+		{
+			desc: "Root update",
+			root: MustSignMapRoot(&types.MapRootV1{
+				TimestampNanos: 98766,
+				Revision:       6,
+				RootHash:       []byte(dummyHash),
+			}),
+		},
+		{
+			desc: "Root with populated metadata",
+			root: MustSignMapRoot(&types.MapRootV1{
+				TimestampNanos: 98769,
+				Revision:       8,
+				RootHash:       []byte(dummyHash),
+				Metadata:       populatedMetadata,
+			}),
+			wantMetadata: populatedMetadata,
+		},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			if err := tx.StoreSignedMapRoot(ctx, *tc.root); err != nil {
+				t.Fatalf("%v: Failed to store signed map root: %v", tc.desc, err)
+			}
+			return nil
+		})
+
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			smr, err := tx.LatestSignedMapRoot(ctx)
+			if err != nil {
+				t.Fatalf("%v: Failed to read back new map root: %v", tc.desc, err)
+			}
+
+			var root types.MapRootV1
+			if err := root.UnmarshalBinary(smr.MapRoot); err != nil {
+				t.Fatalf("%v: UnmarshalBinary(): %v", tc.desc, err)
+			}
+
+			if got, want := root.Metadata, tc.wantMetadata; !bytes.Equal(got, want) {
+				t.Errorf("%v: LatestSignedMapRoot().Metadata = %x, want %x", tc.desc, got, want)
+			}
+			return nil
+		})
+	}
+}
+
+var keyHash = []byte([]byte("A Key Hash"))
+var mapLeaf = trillian.MapLeaf{
+	Index:     keyHash,
+	LeafHash:  []byte("A Hash"),
+	LeafValue: []byte("A Value"),
+	ExtraData: []byte("Some Extra Data"),
+}
+
+func TestMapSetGetRoundTrip(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	readRev := int64(1)
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if err := tx.Set(ctx, keyHash, mapLeaf); err != nil {
+			t.Fatalf("Failed to set %v to %v: %v", keyHash, mapLeaf, err)
+		}
+		return nil
+	})
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		readValues, err := tx.Get(ctx, readRev, [][]byte{keyHash})
+		if err != nil {
+			t.Fatalf("Failed to get %v:  %v", keyHash, err)
+		}
+		if got, want := len(readValues), 1; got != want {
+			t.Fatalf("Got %d values, expected %d", got, want)
+		}
+		if got, want := &readValues[0], &mapLeaf; !proto.Equal(got, want) {
+			t.Fatalf("Read back %v, but expected %v", got, want)
+		}
+		return nil
+	})
+}
+
+func TestMapSetSameKeyInSameRevisionFails(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if err := tx.Set(ctx, keyHash, mapLeaf); err != nil {
+			t.Fatalf("Failed to set %v to %v: %v", keyHash, mapLeaf, err)
+		}
+		return nil
+	})
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if err := tx.Set(ctx, keyHash, mapLeaf); err == nil {
+			t.Fatalf("Unexpectedly succeeded in setting %v to %v", keyHash, mapLeaf)
+		}
+		return nil
+	})
+}
+
+func TestMapGet0Results(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	for _, tc := range []struct {
+		index [][]byte
+	}{
+		{index: nil}, //empty list.
+		{index: [][]byte{[]byte("This doesn't exist.")}},
+	} {
+		t.Run(fmt.Sprintf("tx.Get(%s)", tc.index), func(t *testing.T) {
+			runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+				readValues, err := tx.Get(ctx, 1, tc.index)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, want := len(readValues), 0; got != want {
+					t.Fatalf("len = %d, want %d", got, want)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestMapSetGetMultipleRevisions(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	tests := []struct {
+		rev  int64
+		leaf trillian.MapLeaf
+	}{
+		{0, trillian.MapLeaf{Index: keyHash, LeafHash: []byte{0}, LeafValue: []byte{0}, ExtraData: []byte{0}}},
+		{1, trillian.MapLeaf{Index: keyHash, LeafHash: []byte{1}, LeafValue: []byte{1}, ExtraData: []byte{1}}},
+		{2, trillian.MapLeaf{Index: keyHash, LeafHash: []byte{2}, LeafValue: []byte{2}, ExtraData: []byte{2}}},
+		{3, trillian.MapLeaf{Index: keyHash, LeafHash: []byte{3}, LeafValue: []byte{3}, ExtraData: []byte{3}}},
+	}
+
+	for _, tc := range tests {
+		// Write the current test case.
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			mapTX := tx.(*mapTreeTX)
+			mapTX.treeTX.writeRevision = tc.rev
+			if err := tx.Set(ctx, keyHash, tc.leaf); err != nil {
+				t.Fatalf("Failed to set %v to %v: %v", keyHash, tc.leaf, err)
+			}
+			return nil
+		})
+
+		// Read at a point in time in the future. Expect to get the latest value.
+		// Read at each point in the past. Expect to get that exact point in history.
+		for i := int64(0); i < int64(len(tests)); i++ {
+			expectRev := i
+			if expectRev > tc.rev {
+				expectRev = tc.rev // For future revisions, expect the current value.
+			}
+
+			runMapTX(ctx, s, tree, t, func(ctx context.Context, tx2 storage.MapTreeTX) error {
+				readValues, err := tx2.Get(ctx, i, [][]byte{keyHash})
+				if err != nil {
+					t.Fatalf("At i %d failed to get %v:  %v", i, keyHash, err)
+				}
+				if got, want := len(readValues), 1; got != want {
+					t.Fatalf("At i %d got %d values, expected %d", i, got, want)
+				}
+				if got, want := &readValues[0], &tests[expectRev].leaf; !proto.Equal(got, want) {
+					t.Fatalf("At i %d read back %v, but expected %v", i, got, want)
+				}
+				return nil
+			})
+		}
+	}
+}
+
+func TestMapGetLeavesAfter(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	writes := []struct {
+		rev    int64
+		leaves map[string]string
+	}{
+		{rev: 1, leaves: map[string]string{"key2": "a", "key1": "b", "key3": "c"}},
+		// An empty value deletes key2.
+		{rev: 2, leaves: map[string]string{"key2": "", "key4": "d"}},
+	}
+	for _, w := range writes {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = w.rev
+			for k, v := range w.leaves {
+				if err := tx.Set(ctx, []byte(k), trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)}); err != nil {
+					t.Fatalf("Set(%s): %v", k, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, test := range []struct {
+		rev   int64
+		after string
+		limit int
+		want  []string
+	}{
+		{rev: 0, limit: 10},
+		{rev: 1, limit: 10, want: []string{"key1=b", "key2=a", "key3=c"}},
+		{rev: 1, after: "key1", limit: 10, want: []string{"key2=a", "key3=c"}},
+		{rev: 1, limit: 2, want: []string{"key1=b", "key2=a"}},
+		{rev: 1, after: "key3", limit: 10},
+		{rev: 2, limit: 10, want: []string{"key1=b", "key3=c", "key4=d"}},
+		{rev: 2, after: "key2", limit: 1, want: []string{"key3=c"}},
+		{rev: 2, limit: 0},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			leaves, err := tx.GetLeavesAfter(ctx, test.rev, []byte(test.after), test.limit)
+			if err != nil {
+				t.Fatalf("GetLeavesAfter(%d, %q, %d): %v", test.rev, test.after, test.limit, err)
+			}
+			var got []string
+			for _, l := range leaves {
+				got = append(got, fmt.Sprintf("%s=%s", l.Index, l.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeavesAfter(%d, %q, %d) = %v, want %v", test.rev, test.after, test.limit, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
+func TestMapGetLeafHistory(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	writes := []struct {
+		rev    int64
+		leaves map[string]string
+	}{
+		{rev: 1, leaves: map[string]string{"key1": "a", "key2": "x"}},
+		{rev: 2, leaves: map[string]string{"key1": "b"}},
+		{rev: 3, leaves: map[string]string{"key2": "y"}},
+		{rev: 4, leaves: map[string]string{"key1": "c"}},
+	}
+	for _, w := range writes {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = w.rev
+			for k, v := range w.leaves {
+				if err := tx.Set(ctx, []byte(k), trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)}); err != nil {
+					t.Fatalf("Set(%s): %v", k, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, test := range []struct {
+		key      string
+		from, to int64
+		want     []string
+	}{
+		{key: "key1", from: 0, to: 4, want: []string{"key1@1=a", "key1@2=b", "key1@4=c"}},
+		{key: "key1", from: 2, to: 3, want: []string{"key1@2=b"}},
+		{key: "key1", from: 3, to: 3},
+		{key: "key1", from: 3, to: 10, want: []string{"key1@4=c"}},
+		{key: "key2", from: 1, to: 4, want: []string{"key2@1=x", "key2@3=y"}},
+		{key: "key5", from: 0, to: 4},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			history, err := tx.GetLeafHistory(ctx, []byte(test.key), test.from, test.to)
+			if err != nil {
+				t.Fatalf("GetLeafHistory(%q, %d, %d): %v", test.key, test.from, test.to, err)
+			}
+			var got []string
+			for _, h := range history {
+				got = append(got, fmt.Sprintf("%s@%d=%s", h.Leaf.Index, h.Revision, h.Leaf.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeafHistory(%q, %d, %d) = %v, want %v", test.key, test.from, test.to, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
+func TestGetSignedMapRootNotExist(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	tree := createTreeOrPanic(db, storageto.MapTree) // Uninitialized: no revision 0 MapRoot exists.
+	s := NewMapStorage(db)
+
+	ctx := context.Background()
+	err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		_, err := tx.GetSignedMapRoot(ctx, 0)
+		if got, want := err, storage.ErrTreeNeedsInit; got != want {
+			t.Fatalf("GetSignedMapRoot: %v, want %v", got, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadWriteTransaction: %v", err)
+	}
+}
+
+func TestGetSignedMapRoot(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	revision := int64(5)
+	root := MustSignMapRoot(&types.MapRootV1{
+		TimestampNanos: 98765,
+		Revision:       uint64(revision),
+		RootHash:       []byte(dummyHash),
+	})
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if err := tx.StoreSignedMapRoot(ctx, *root); err != nil {
+			t.Fatalf("Failed to store signed root: %v", err)
+		}
+		return nil
+	})
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx2 storage.MapTreeTX) error {
+		root2, err := tx2.GetSignedMapRoot(ctx, revision)
+		if err != nil {
+			t.Fatalf("Failed to get back new map root: %v", err)
+		}
+		if !proto.Equal(root, &root2) {
+			t.Fatalf("Getting root round trip failed: <%#v> and: <%#v>", root, root2)
+		}
+		return nil
+	})
+}
+
+func TestLatestSignedMapRoot(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	root := MustSignMapRoot(&types.MapRootV1{
+		TimestampNanos: 98765,
+		Revision:       5,
+		RootHash:       []byte(dummyHash),
+	})
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if err := tx.StoreSignedMapRoot(ctx, *root); err != nil {
+			t.Fatalf("Failed to store signed root: %v", err)
+		}
+		return nil
+	})
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx2 storage.MapTreeTX) error {
+		root2, err := tx2.LatestSignedMapRoot(ctx)
+		if err != nil {
+			t.Fatalf("Failed to read back new map root: %v", err)
+		}
+		if !proto.Equal(root, &root2) {
+			t.Fatalf("Root round trip failed: <%#v> and: <%#v>", root, root2)
+		}
+		return nil
+	})
+}
+
+func TestDuplicateSignedMapRoot(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, db)
+	s := NewMapStorage(db)
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		root := MustSignMapRoot(&types.MapRootV1{
+			TimestampNanos: 98765,
+			Revision:       5,
+			RootHash:       []byte(dummyHash),
+		})
+		if err := tx.StoreSignedMapRoot(ctx, *root); err != nil {
+			t.Fatalf("Failed to store signed map root: %v", err)
+		}
+		// Shouldn't be able to do it again
+		if err := tx.StoreSignedMapRoot(ctx, *root); err != storage.ErrMapRevisionExists {
+			t.Fatalf("StoreSignedMapRoot() of duplicate root = %v, want %v", err, storage.ErrMapRevisionExists)
+		}
+		return nil
+	})
+}
+
+func TestReadOnlyMapTX_Rollback(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	s := NewMapStorage(db)
+	tx, err := s.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	// It's a bit hard to have a more meaningful test. This should suffice.
+	if err := tx.Rollback(); err != nil {
+		t.Errorf("Rollback() = (_, %v), want = (_, nil)", err)
+	}
+}
+
+func runMapTX(ctx context.Context, s storage.MapStorage, tree *trillian.Tree, t *testing.T, f storage.MapTXFunc) {
+	t.Helper()
+	if err := s.ReadWriteTransaction(ctx, tree, f); err != nil {
+		t.Fatalf("Failed to begin map tx: %v", err)
+	}
+}
+
+func createInitializedMapForTests(ctx context.Context, t *testing.T, db *bolt.DB) *trillian.Tree {
+	t.Helper()
+	tree := createTreeOrPanic(db, storageto.MapTree)
+
+	s := NewMapStorage(db)
+	signer := tcrypto.NewSigner(tree.TreeId, testonly.NewSignerWithFixedSig(nil, []byte("sig")), crypto.SHA256)
+	err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		initialRoot, _ := signer.SignMapRoot(&types.MapRootV1{
+			RootHash: []byte("rootHash"),
+			Revision: 0,
+		})
+
+		if err := tx.StoreSignedMapRoot(ctx, *initialRoot); err != nil {
+			t.Fatalf("Failed to StoreSignedMapRoot: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadWriteTransaction() = %v", err)
+	}
+
+	return tree
+}
+
+func mapTree(mapID int64) *trillian.Tree {
+	return &trillian.Tree{
+		TreeId:       mapID,
+		TreeType:     trillian.TreeType_MAP,
+		HashStrategy: trillian.HashStrategy_TEST_MAP_HASHER,
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+	storageto "github.com/google/trillian/storage/testonly"
+)
+
+func TestNodeRoundTrip(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	tree := createTreeOrPanic(db, storageto.LogTree)
+	s := NewLogStorage(db, nil)
+
+	const writeRevision = int64(100)
+	nodesToStore := createSomeNodes()
+	nodeIDsToRead := make([]storage.NodeID, len(nodesToStore))
+	for i := range nodesToStore {
+		nodeIDsToRead[i] = nodesToStore[i].NodeID
+	}
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		forceWriteRevision(writeRevision, tx)
+
+		// Need to read nodes before attempting to write
+		if _, err := tx.GetMerkleNodes(ctx, 99, nodeIDsToRead); err != nil {
+			t.Fatalf("Failed to read nodes: %s", err)
+		}
+		if err := tx.SetMerkleNodes(ctx, nodesToStore); err != nil {
+			t.Fatalf("Failed to store nodes: %s", err)
+		}
+		return nil
+	})
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		readNodes, err := tx.GetMerkleNodes(ctx, 100, nodeIDsToRead)
+		if err != nil {
+			t.Fatalf("Failed to retrieve nodes: %s", err)
+		}
+		if err := nodesAreEqual(readNodes, nodesToStore); err != nil {
+			t.Fatalf("Read back different nodes from the ones stored: %s", err)
+		}
+		return nil
+	})
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		// Nodes don't exist before the revision they were written at.
+		readNodes, err := tx.GetMerkleNodes(ctx, 99, nodeIDsToRead)
+		if err != nil {
+			t.Fatalf("Failed to retrieve nodes: %s", err)
+		}
+		if len(readNodes) != 0 {
+			t.Fatalf("Read back %d nodes at revision 99, want 0", len(readNodes))
+		}
+		return nil
+	})
+}
+
+// This test ensures that node writes cross subtree boundaries so this edge case in the subtree
+// cache gets exercised. Any tree size > 256 will do this.
+func TestLogNodeRoundTripMultiSubtree(t *testing.T) {
+	db, done := openTestDBOrDie(t)
+	defer done()
+	tree := createTreeOrPanic(db, storageto.LogTree)
+	s := NewLogStorage(db, nil)
+
+	const writeRevision = int64(100)
+	nodesToStore, err := createLogNodesForTreeAtSize(871, writeRevision)
+	if err != nil {
+		t.Fatalf("failed to create test tree: %v", err)
+	}
+	nodeIDsToRead := make([]storage.NodeID, len(nodesToStore))
+	for i := range nodesToStore {
+		nodeIDsToRead[i] = nodesToStore[i].NodeID
+	}
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		forceWriteRevision(writeRevision, tx)
+
+		// Need to read nodes before attempting to write
+		if _, err := tx.GetMerkleNodes(ctx, writeRevision-1, nodeIDsToRead); err != nil {
+			t.Fatalf("Failed to read nodes: %s", err)
+		}
+		if err := tx.SetMerkleNodes(ctx, nodesToStore); err != nil {
+			t.Fatalf("Failed to store nodes: %s", err)
+		}
+		return nil
+	})
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		readNodes, err := tx.GetMerkleNodes(ctx, 100, nodeIDsToRead)
+		if err != nil {
+			t.Fatalf("Failed to retrieve nodes: %s", err)
+		}
+		if err := nodesAreEqual(readNodes, nodesToStore); err != nil {
+			t.Fatalf("Read back different nodes from the ones stored: %s", err)
+		}
+		return nil
+	})
+}
+
+func TestOpenDBReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bolt")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trillian.db")
+
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("OpenDB() = (_, %v), want = (_, nil)", err)
+	}
+	tree := createTreeOrPanic(db, storageto.LogTree)
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	db, err = OpenDB(path)
+	if err != nil {
+		t.Fatalf("OpenDB() = (_, %v), want = (_, nil)", err)
+	}
+	defer db.Close()
+	if _, err := storage.GetTree(context.Background(), NewAdminStorage(db), tree.TreeId); err != nil {
+		t.Errorf("GetTree() after reopening = (_, %v), want = (_, nil)", err)
+	}
+}
+
+func forceWriteRevision(rev int64, tx storage.TreeTX) {
+	mtx, ok := tx.(*logTreeTX)
+	if !ok {
+		panic(nil)
+	}
+	mtx.treeTX.writeRevision = rev
+}
+
+func createSomeNodes() []storage.Node {
+	r := make([]storage.Node, 4)
+	for i := range r {
+		r[i].NodeID = storage.NewNodeIDWithPrefix(uint64(i), 8, 8, 8)
+		h := sha256.Sum256([]byte{byte(i)})
+		r[i].Hash = h[:]
+	}
+	return r
+}
+
+func createLogNodesForTreeAtSize(ts, rev int64) ([]storage.Node, error) {
+	tree := merkle.NewCompactMerkleTree(rfc6962.New(crypto.SHA256))
+	nodeMap := make(map[string]storage.Node)
+	for l := 0; l < int(ts); l++ {
+		// We're only interested in the side effects of adding leaves - the node updates
+		if _, _, err := tree.AddLeaf([]byte(fmt.Sprintf("Leaf %d", l)), func(depth int, index int64, hash []byte) error {
+			nID, err := storage.NewNodeIDForTreeCoords(int64(depth), index, 64)
+			if err != nil {
+				return fmt.Errorf("failed to create a nodeID for tree - should not happen d:%d i:%d",
+					depth, index)
+			}
+
+			nodeMap[nID.String()] = storage.Node{NodeID: nID, NodeRevision: rev, Hash: hash}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Unroll the map, which has deduped the updates for us and retained the latest
+	nodes := make([]storage.Node, 0, len(nodeMap))
+	for _, v := range nodeMap {
+		nodes = append(nodes, v)
+	}
+
+	return nodes, nil
+}
+
+func nodesAreEqual(lhs []storage.Node, rhs []storage.Node) error {
+	if ls, rs := len(lhs), len(rhs); ls != rs {
+		return fmt.Errorf("different number of nodes, %d vs %d", ls, rs)
+	}
+	for i := range lhs {
+		if l, r := lhs[i].NodeID.String(), rhs[i].NodeID.String(); l != r {
+			return fmt.Errorf("NodeIDs are not the same,\nlhs = %v,\nrhs = %v", l, r)
+		}
+		if l, r := lhs[i].Hash, rhs[i].Hash; !bytes.Equal(l, r) {
+			return fmt.Errorf("Hashes are not the same for %s,\nlhs = %v,\nrhs = %v", lhs[i].NodeID.CoordString(), l, r)
+		}
+	}
+	return nil
+}
+
+// openTestDBOrDie opens a new BoltDB in a temporary directory. The returned
+// func closes the database and removes the directory.
+func openTestDBOrDie(t *testing.T) (*bolt.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "bolt")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	db, err := OpenDB(filepath.Join(dir, "trillian.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func createFakeSignedLogRoot(db *bolt.DB, tree *trillian.Tree, treeSize uint64) {
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+
+	ctx := context.Background()
+	l := NewLogStorage(db, nil)
+	err := l.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		root, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: treeSize, RootHash: []byte{0}})
+		if err != nil {
+			return fmt.Errorf("Error creating new SignedLogRoot: %v", err)
+		}
+		if err := tx.StoreSignedLogRoot(ctx, *root); err != nil {
+			return fmt.Errorf("Error storing new SignedLogRoot: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(fmt.Sprintf("ReadWriteTransaction() = %v", err))
+	}
+}
+
+func createTreeOrPanic(db *bolt.DB, create *trillian.Tree) *trillian.Tree {
+	tree, err := storage.CreateTree(context.Background(), NewAdminStorage(db), create)
+	if err != nil {
+		panic(fmt.Sprintf("Error creating tree: %v", err))
+	}
+	return tree
+}
+
+// updateTree updates the specified tree using AdminStorage.
+func updateTree(db *bolt.DB, treeID int64, updateFn func(*trillian.Tree)) (*trillian.Tree, error) {
+	return storage.UpdateTree(context.Background(), NewAdminStorage(db), treeID, updateFn)
+}
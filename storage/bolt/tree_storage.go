@@ -0,0 +1,459 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bolt provides an embedded, pure Go implementation of Trillian's
+// log, map and admin storage, backed by a single BoltDB file.
+//
+// All trees share one database file. Tree metadata is stored in the Trees
+// bucket, and each tree's data lives in its own nested bucket of TreeData,
+// partitioned into sub-buckets which mirror the tables used by the MySQL
+// storage implementation. Merkle nodes are stored as subtrees, using the same
+// strata and caching (see the storage/cache package) as the other storage
+// implementations.
+//
+// BoltDB only allows a single writable transaction at a time, and holds an
+// exclusive lock on the database file, so this storage is only suitable for
+// a single Trillian process. It's mostly intended for small deployments,
+// testing and development.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/storagepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// treesBucket holds the serialized trillian.Tree protos, keyed by tree ID.
+	treesBucket = []byte("Trees")
+	// treeDataBucket holds one nested bucket per tree ID, which in turn holds
+	// the buckets below.
+	treeDataBucket = []byte("TreeData")
+
+	subtreeBucket           = []byte("Subtree")
+	treeHeadBucket          = []byte("TreeHead")
+	leafDataBucket          = []byte("LeafData")
+	sequencedLeafDataBucket = []byte("SequencedLeafData")
+	merkleLeafHashBucket    = []byte("MerkleLeafHash")
+	unsequencedBucket       = []byte("Unsequenced")
+	mapLeafBucket           = []byte("MapLeaf")
+	mapHeadBucket           = []byte("MapHead")
+
+	// perTreeBuckets lists the buckets created for each tree on creation.
+	perTreeBuckets = [][]byte{
+		subtreeBucket,
+		treeHeadBucket,
+		leafDataBucket,
+		sequencedLeafDataBucket,
+		merkleLeafHashBucket,
+		unsequencedBucket,
+		mapLeafBucket,
+		mapHeadBucket,
+	}
+)
+
+// OpenDB opens (creating it if necessary) the BoltDB database at path and
+// ensures the top-level buckets exist.
+func OpenDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		glog.Warningf("Could not open BoltDB %v: %v", path, err)
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{treesBucket, treeDataBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// int64Key encodes v as a big-endian byte slice, so that keys sort in the same
+// order as the (non-negative) values they encode.
+func int64Key(v int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
+
+// keyInt64 decodes a value encoded by int64Key from the first 8 bytes of b.
+func keyInt64(b []byte) int64 {
+	return int64(binary.BigEndian.Uint64(b[:8]))
+}
+
+// concat returns a new slice holding the concatenation of parts.
+func concat(parts ...[]byte) []byte {
+	var l int
+	for _, p := range parts {
+		l += len(p)
+	}
+	ret := make([]byte, 0, l)
+	for _, p := range parts {
+		ret = append(ret, p...)
+	}
+	return ret
+}
+
+// subtreeKeyPrefix returns the key prefix shared by all revisions of the
+// subtree with the given prefix. The prefix length is included so that the
+// revisions of a single subtree are contiguous and ordered.
+func subtreeKeyPrefix(prefix []byte) []byte {
+	return concat([]byte{byte(len(prefix))}, prefix)
+}
+
+// subtreeKey returns the key under which the subtree with the given prefix is
+// stored at revision rev.
+func subtreeKey(prefix []byte, rev int64) []byte {
+	return concat(subtreeKeyPrefix(prefix), int64Key(rev))
+}
+
+// seekAtOrBelow returns the value of the entry with the greatest key which has
+// the given prefix and is less than or equal to prefix+suffix, or nil if there
+// is none.
+func seekAtOrBelow(b *bolt.Bucket, prefix, suffix []byte) []byte {
+	want := concat(prefix, suffix)
+	c := b.Cursor()
+	k, v := c.Seek(want)
+	switch {
+	case k == nil:
+		k, v = c.Last()
+	case !bytes.Equal(k, want):
+		k, v = c.Prev()
+	}
+	if k == nil || !bytes.HasPrefix(k, prefix) {
+		return nil
+	}
+	return v
+}
+
+// treeIDKey returns the key used for treeID in the Trees and TreeData buckets.
+func treeIDKey(treeID int64) []byte {
+	return int64Key(treeID)
+}
+
+// boltTreeStorage is shared between the log and map storage implementations,
+// and contains functionality which is common to both.
+type boltTreeStorage struct {
+	db *bolt.DB
+}
+
+func newTreeStorage(db *bolt.DB) *boltTreeStorage {
+	return &boltTreeStorage{db: db}
+}
+
+// CheckDatabaseAccessible returns nil if a read transaction can be opened on
+// the database.
+func (m *boltTreeStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return m.db.View(func(*bolt.Tx) error { return nil })
+}
+
+func (m *boltTreeStorage) beginTreeTX(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache cache.SubtreeCache, readonly bool) (treeTX, error) {
+	tx, err := m.db.Begin(!readonly)
+	if err != nil {
+		glog.Warningf("Could not start tree TX: %s", err)
+		return treeTX{}, err
+	}
+	t, err := newTreeTX(&sharedTX{tx: tx}, tree, hashSizeBytes, subtreeCache)
+	if err != nil {
+		tx.Rollback()
+		return treeTX{}, err
+	}
+	t.owner = true
+	return t, nil
+}
+
+// newTreeTX returns a treeTX which operates on tree through shared.
+func newTreeTX(shared *sharedTX, tree *trillian.Tree, hashSizeBytes int, subtreeCache cache.SubtreeCache) (treeTX, error) {
+	var data *bolt.Bucket
+	if err := shared.do(func(tx *bolt.Tx) error {
+		data = tx.Bucket(treeDataBucket).Bucket(treeIDKey(tree.TreeId))
+		if data == nil {
+			return status.Errorf(codes.NotFound, "no data for tree %v", tree.TreeId)
+		}
+		return nil
+	}); err != nil {
+		return treeTX{}, err
+	}
+	return treeTX{
+		shared:        shared,
+		data:          data,
+		treeID:        tree.TreeId,
+		treeType:      tree.TreeType,
+		hashSizeBytes: hashSizeBytes,
+		subtreeCache:  subtreeCache,
+		writeRevision: -1,
+	}, nil
+}
+
+// errTXClosed is returned by operations on a sharedTX which has already been
+// committed or rolled back.
+var errTXClosed = errors.New("transaction is closed")
+
+// sharedTX is a BoltDB transaction which may be used by more than one treeTX,
+// possibly from different goroutines. BoltDB transactions are not safe for
+// concurrent use, so all access to tx must hold mu.
+type sharedTX struct {
+	mu sync.Mutex
+	// tx is nil once the transaction has been committed or rolled back.
+	tx *bolt.Tx
+}
+
+// do runs f with the underlying transaction while holding the lock on it.
+func (s *sharedTX) do(f func(*bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		return errTXClosed
+	}
+	return f(s.tx)
+}
+
+// commit commits the underlying transaction.
+func (s *sharedTX) commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		return errTXClosed
+	}
+	tx := s.tx
+	s.tx = nil
+	if !tx.Writable() {
+		// Read-only BoltDB transactions can't be committed, only closed.
+		return tx.Rollback()
+	}
+	return tx.Commit()
+}
+
+// rollback rolls back the underlying transaction, if it's still open.
+func (s *sharedTX) rollback() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		return nil
+	}
+	tx := s.tx
+	s.tx = nil
+	return tx.Rollback()
+}
+
+type treeTX struct {
+	closed bool
+	// owner is true if this treeTX is responsible for committing or rolling
+	// back the underlying BoltDB transaction.
+	owner         bool
+	shared        *sharedTX
+	data          *bolt.Bucket
+	treeID        int64
+	treeType      trillian.TreeType
+	hashSizeBytes int
+	subtreeCache  cache.SubtreeCache
+	writeRevision int64
+}
+
+// bucket returns the named bucket of the tree's data. It must only be called
+// from within a function passed to t.do.
+func (t *treeTX) bucket(name []byte) *bolt.Bucket {
+	return t.data.Bucket(name)
+}
+
+// do runs f while holding the lock on the shared BoltDB transaction.
+func (t *treeTX) do(f func() error) error {
+	return t.shared.do(func(*bolt.Tx) error { return f() })
+}
+
+func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID storage.NodeID) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []storage.NodeID{nodeID})
+	if err != nil {
+		return nil, err
+	}
+	switch len(s) {
+	case 0:
+		return nil, nil
+	case 1:
+		return s[0], nil
+	default:
+		return nil, fmt.Errorf("got %d subtrees, but expected 1", len(s))
+	}
+}
+
+func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []storage.NodeID) ([]*storagepb.SubtreeProto, error) {
+	if len(nodeIDs) == 0 {
+		return nil, nil
+	}
+
+	ret := make([]*storagepb.SubtreeProto, 0, len(nodeIDs))
+	err := t.do(func() error {
+		b := t.bucket(subtreeBucket)
+		for _, nodeID := range nodeIDs {
+			if nodeID.PrefixLenBits%8 != 0 {
+				return fmt.Errorf("invalid subtree ID - not multiple of 8: %d", nodeID.PrefixLenBits)
+			}
+			prefix := nodeID.Path[:nodeID.PrefixLenBits/8]
+
+			// Look for the subtree at or below treeRevision.
+			v := seekAtOrBelow(b, subtreeKeyPrefix(prefix), int64Key(treeRevision))
+			if v == nil {
+				continue
+			}
+			var subtree storagepb.SubtreeProto
+			if err := proto.Unmarshal(v, &subtree); err != nil {
+				glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
+				return err
+			}
+			if subtree.Prefix == nil {
+				subtree.Prefix = []byte{}
+			}
+			ret = append(ret, &subtree)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The InternalNodes cache is possibly nil here, but the SubtreeCache (which called
+	// this method) will re-populate it.
+	return ret, nil
+}
+
+func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.SubtreeProto) error {
+	if len(subtrees) == 0 {
+		glog.Warning("attempted to store 0 subtrees...")
+		return nil
+	}
+
+	return t.do(func() error {
+		b := t.bucket(subtreeBucket)
+		for _, s := range subtrees {
+			if s.Prefix == nil {
+				panic(fmt.Errorf("nil prefix on %v", s))
+			}
+			subtreeBytes, err := proto.Marshal(s)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(subtreeKey(s.Prefix, t.writeRevision), subtreeBytes); err != nil {
+				glog.Warningf("Failed to set merkle subtree: %s", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev.
+func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
+	return func(ids []storage.NodeID) ([]*storagepb.SubtreeProto, error) {
+		return t.getSubtrees(ctx, rev, ids)
+	}
+}
+
+// GetMerkleNodes returns the requests nodes at (or below) the passed in treeRevision.
+func (t *treeTX) GetMerkleNodes(ctx context.Context, treeRevision int64, nodeIDs []storage.NodeID) ([]storage.Node, error) {
+	return t.subtreeCache.GetNodes(nodeIDs, t.getSubtreesAtRev(ctx, treeRevision))
+}
+
+func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []storage.Node) error {
+	for _, n := range nodes {
+		err := t.subtreeCache.SetNodeHash(n.NodeID, n.Hash,
+			func(nID storage.NodeID) (*storagepb.SubtreeProto, error) {
+				return t.getSubtree(ctx, t.writeRevision, nID)
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *treeTX) Commit() error {
+	if t.writeRevision > -1 {
+		if err := t.subtreeCache.Flush(func(st []*storagepb.SubtreeProto) error {
+			return t.storeSubtrees(context.TODO(), st)
+		}); err != nil {
+			glog.Warningf("TX commit flush error: %v", err)
+			return err
+		}
+	}
+	t.closed = true
+	if !t.owner {
+		return nil
+	}
+	if err := t.shared.commit(); err != nil {
+		glog.Warningf("TX commit error: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) Rollback() error {
+	t.closed = true
+	if !t.owner {
+		return nil
+	}
+	if err := t.shared.rollback(); err != nil {
+		glog.Warningf("TX rollback error: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) Close() error {
+	if !t.closed {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) IsOpen() bool {
+	return !t.closed
+}
+
+// createTreeData creates the data buckets for a new tree in tx.
+func createTreeData(tx *bolt.Tx, treeID int64) error {
+	data, err := tx.Bucket(treeDataBucket).CreateBucket(treeIDKey(treeID))
+	if err != nil {
+		return err
+	}
+	for _, name := range perTreeBuckets {
+		if _, err := data.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -57,13 +57,18 @@ var (
 		trillian.HashStrategy_TEST_MAP_HASHER:       spannerpb.HashStrategy_TEST_MAP_HASHER,
 		trillian.HashStrategy_OBJECT_RFC6962_SHA256: spannerpb.HashStrategy_OBJECT_RFC6962_SHA256,
 		trillian.HashStrategy_CONIKS_SHA512_256:     spannerpb.HashStrategy_CONIKS_SHA512_256,
+		trillian.HashStrategy_RFC6962_SHA384:        spannerpb.HashStrategy_RFC_6962_SHA384,
+		trillian.HashStrategy_RFC6962_SHA512:        spannerpb.HashStrategy_RFC_6962_SHA512,
 	}
 	hashAlgMap = map[sigpb.DigitallySigned_HashAlgorithm]spannerpb.HashAlgorithm{
 		sigpb.DigitallySigned_SHA256: spannerpb.HashAlgorithm_SHA256,
+		sigpb.DigitallySigned_SHA384: spannerpb.HashAlgorithm_SHA384,
+		sigpb.DigitallySigned_SHA512: spannerpb.HashAlgorithm_SHA512,
 	}
 	signatureAlgMap = map[sigpb.DigitallySigned_SignatureAlgorithm]spannerpb.SignatureAlgorithm{
-		sigpb.DigitallySigned_RSA:   spannerpb.SignatureAlgorithm_RSA,
-		sigpb.DigitallySigned_ECDSA: spannerpb.SignatureAlgorithm_ECDSA,
+		sigpb.DigitallySigned_RSA:     spannerpb.SignatureAlgorithm_RSA,
+		sigpb.DigitallySigned_ECDSA:   spannerpb.SignatureAlgorithm_ECDSA,
+		sigpb.DigitallySigned_ED25519: spannerpb.SignatureAlgorithm_ED25519,
 	}
 
 	treeStateReverseMap    = reverseTreeStateMap(treeStateMap)
@@ -296,9 +301,9 @@ func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64
 	return ids, err
 }
 
-func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+func (t *adminTX) ListTrees(ctx context.Context, opts storage.ListTreesOptions) ([]*trillian.Tree, error) {
 	trees := []*trillian.Tree{}
-	err := t.readTrees(ctx, includeDeleted, false /* idOnly */, func(r *spanner.Row) error {
+	err := t.readTrees(ctx, opts.IncludeDeleted, false /* idOnly */, func(r *spanner.Row) error {
 		info := &spannerpb.TreeInfo{}
 		if err := r.Columns(info); err != nil {
 			return err
@@ -310,7 +315,10 @@ func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trilli
 		trees = append(trees, tree)
 		return nil
 	})
-	return trees, err
+	if err != nil {
+		return nil, err
+	}
+	return storage.FilterTrees(trees, opts), nil
 }
 
 func (t *adminTX) readTrees(ctx context.Context, includeDeleted, idOnly bool, f func(*spanner.Row) error) error {
@@ -379,6 +387,10 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 
 // newTreeInfo creates a new TreeInfo from a Tree. Meant to be used for new trees.
 func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.TreeInfo, error) {
+	if tree.SequencerConfig != nil {
+		return nil, status.Error(codes.InvalidArgument, "sequencer_config not supported")
+	}
+
 	ts, ok := treeStateMap[tree.TreeState]
 	if !ok {
 		return nil, status.Errorf(codes.Internal, "unexpected TreeState: %s", tree.TreeState)
@@ -423,6 +435,7 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 		PrivateKey:            tree.GetPrivateKey(),
 		PublicKeyDer:          tree.GetPublicKey().GetDer(),
 		MaxRootDurationMillis: int64(maxRootDuration / time.Millisecond),
+		Labels:                tree.Labels,
 	}
 
 	switch tree.TreeType {
@@ -498,6 +511,12 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	if !proto.Equal(beforeTree.StorageSettings, tree.StorageSettings) {
 		return nil, status.New(codes.InvalidArgument, "readonly field changed: storage_settings").Err()
 	}
+	if tree.SequencerConfig != nil {
+		return nil, status.Error(codes.InvalidArgument, "sequencer_config not supported")
+	}
+	if len(tree.KeyHistory) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "key_history not supported")
+	}
 
 	ts, ok := treeStateMap[tree.TreeState]
 	if !ok {
@@ -517,6 +536,7 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	info.UpdateTimeNanos = now.UnixNano()
 	info.MaxRootDurationMillis = int64(maxRootDuration / time.Millisecond)
 	info.PrivateKey = tree.PrivateKey
+	info.Labels = tree.Labels
 
 	if err := t.updateTreeInfo(ctx, info); err != nil {
 		return nil, err
@@ -648,6 +668,7 @@ func toTrillianTree(info *spannerpb.TreeInfo) (*trillian.Tree, error) {
 		PrivateKey:      info.PrivateKey,
 		PublicKey:       &keyspb.PublicKey{Der: info.PublicKeyDer},
 		MaxRootDuration: ptypes.DurationProto(time.Duration(info.MaxRootDurationMillis) * time.Millisecond),
+		Labels:          info.Labels,
 	}
 
 	ts, ok := treeStateReverseMap[info.TreeState]
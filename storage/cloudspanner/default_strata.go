@@ -20,4 +20,9 @@ var (
 	// Log trees are dense and so each individual stratum cannot over-commit on
 	// storage.
 	defLogStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8}
+
+	// defMapStrata is a suitable set of stratum sizes for Map trees.
+	// Map trees are sparse, so the bottom stratum covers the remainder of the
+	// 256 bit key space rather than being split into many sparse strata.
+	defMapStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}
 )
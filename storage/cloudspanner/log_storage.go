@@ -806,7 +806,9 @@ func (l *leafSlice) addRow(r *spanner.Row) error {
 // The entries in key are used in constructing a primary key (treeID, keyElem)
 // for the specified Spanner index.
 // If bySeq is true, the returned slice will be order by LogLeaf.LeafIndex.
-func (tx *logTX) getUsingIndex(ctx context.Context, idx string, keys [][]byte, bySeq bool) ([]*trillian.LogLeaf, error) {
+// Only leaves with a LeafIndex of at least start are returned. If limit is
+// greater than zero at most limit leaves are returned, ordered by LeafIndex.
+func (tx *logTX) getUsingIndex(ctx context.Context, idx string, keys [][]byte, bySeq bool, start, limit int64) ([]*trillian.LogLeaf, error) {
 	keySet := make([]spanner.KeySet, 0, len(keys))
 	for _, k := range keys {
 		keySet = append(keySet, spanner.Key{tx.treeID, k})
@@ -819,6 +821,21 @@ func (tx *logTX) getUsingIndex(ctx context.Context, idx string, keys [][]byte, b
 		return nil, err
 	}
 
+	// Drop the unwanted leaves before fetching their data.
+	wanted := leaves[:0]
+	for _, l := range leaves {
+		if l.LeafIndex >= start {
+			wanted = append(wanted, l)
+		}
+	}
+	leaves = wanted
+	if bySeq || limit > 0 {
+		sort.Sort(byIndex(leaves))
+	}
+	if limit > 0 && int64(len(leaves)) > limit {
+		leaves = leaves[:limit]
+	}
+
 	byHash := make(leavesByHash)
 	for i := range leaves {
 		k := string(leaves[i].LeafIdentityHash)
@@ -830,10 +847,6 @@ func (tx *logTX) getUsingIndex(ctx context.Context, idx string, keys [][]byte, b
 		return nil, err
 	}
 
-	if bySeq {
-		sort.Sort(byIndex(leaves))
-	}
-
 	return leaves, nil
 }
 
@@ -843,8 +856,8 @@ func (tx *logTX) getUsingIndex(ctx context.Context, idx string, keys [][]byte, b
 // TODO(al): Currently, this method does not populate the IntegrateTimestamp
 //   member of the returned leaves. We should convert this method to use SQL
 //   rather than denormalising IntegrateTimestampNanos into the index too.
-func (tx *logTX) GetLeavesByHash(ctx context.Context, hashes [][]byte, bySeq bool) ([]*trillian.LogLeaf, error) {
-	return tx.getUsingIndex(ctx, seqDataByMerkleHashIdx, hashes, bySeq)
+func (tx *logTX) GetLeavesByHash(ctx context.Context, hashes [][]byte, bySeq bool, start, limit int64) ([]*trillian.LogLeaf, error) {
+	return tx.getUsingIndex(ctx, seqDataByMerkleHashIdx, hashes, bySeq, start, limit)
 }
 
 // QueuedEntry represents a leaf which was dequeued.
@@ -0,0 +1,481 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"cloud.google.com/go/spanner"
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/cloudspanner/spannerpb"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	mapLeafDataTbl = "MapLeafData"
+	treeHeadsTbl   = "TreeHeads"
+
+	// Spanner DB columns:
+	colLeafIndex   = "LeafIndex"
+	colMapRevision = "MapRevision"
+	colLeafHash    = "LeafHash"
+)
+
+// MapStorageOptions are tuning, experiments and workarounds that can be used.
+type MapStorageOptions struct {
+	TreeStorageOptions
+}
+
+// mapTXKey is the context key under which ReadWriteTransaction stores the
+// mapTX it passes to its MapTXFunc. Nested ReadWriteTransaction calls for the
+// same tree (as made by merkle.SparseMerkleTreeWriter) join that transaction
+// rather than starting their own, so that all of the subtrees written for a
+// revision are committed atomically along with its SignedMapRoot.
+type mapTXKey struct {
+	treeID int64
+}
+
+// NewMapStorage initialises and returns a new MapStorage.
+func NewMapStorage(client *spanner.Client) storage.MapStorage {
+	return NewMapStorageWithOpts(client, MapStorageOptions{})
+}
+
+// NewMapStorageWithOpts initialises and returns a new MapStorage.
+// The opts parameter can be used to enable custom workarounds.
+func NewMapStorageWithOpts(client *spanner.Client, opts MapStorageOptions) storage.MapStorage {
+	return &mapStorage{
+		ts:   newTreeStorageWithOpts(client, opts.TreeStorageOptions),
+		opts: opts,
+	}
+}
+
+// mapStorage provides a Cloud Spanner backed trillian.MapStorage implementation.
+// See storage/map_storage.go for more details.
+type mapStorage struct {
+	// ts provides the merkle-tree level primitives which are built upon by this
+	// mapStorage.
+	ts *treeStorage
+
+	// Additional options applied to this mapStorage
+	opts MapStorageOptions
+}
+
+func (ms *mapStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return checkDatabaseAccessible(ctx, ms.ts.client)
+}
+
+func (ms *mapStorage) Snapshot(ctx context.Context) (storage.ReadOnlyMapTX, error) {
+	var staleness spanner.TimestampBound
+	if ms.opts.ReadOnlyStaleness > 0 {
+		staleness = spanner.ExactStaleness(ms.opts.ReadOnlyStaleness)
+	} else {
+		staleness = spanner.StrongRead()
+	}
+
+	snapshotTX := &snapshotTX{
+		client: ms.ts.client,
+		stx:    ms.ts.client.ReadOnlyTransaction().WithTimestampBound(staleness),
+	}
+	return &readOnlyMapTX{snapshotTX}, nil
+}
+
+func newMapCache(tree *trillian.Tree) (cache.SubtreeCache, error) {
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		return cache.SubtreeCache{}, err
+	}
+	return cache.NewMapSubtreeCache(defMapStrata, tree.TreeId, hasher), nil
+}
+
+func (ms *mapStorage) begin(ctx context.Context, tree *trillian.Tree, stx spanRead) (*mapTX, error) {
+	tx, err := ms.ts.begin(ctx, tree, newMapCache, stx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sanity check tx.config
+	if cfg, ok := tx.config.(*spannerpb.MapStorageConfig); !ok || cfg == nil {
+		return nil, fmt.Errorf("unexpected config type for MAP tree %v: %T", tx.treeID, tx.config)
+	}
+
+	return &mapTX{
+		treeTX:  tx,
+		ms:      ms,
+		readRev: -1,
+	}, nil
+}
+
+// join returns a new mapTX which shares parent's underlying Spanner
+// transaction and revisions, but has its own subtree cache.
+func (ms *mapStorage) join(ctx context.Context, parent *mapTX, tree *trillian.Tree) (*mapTX, error) {
+	if err := parent.getLatestRoot(ctx); err != nil {
+		return nil, err
+	}
+	c, err := newMapCache(tree)
+	if err != nil {
+		return nil, err
+	}
+	tx := &treeTX{
+		treeID:      parent.treeID,
+		ts:          parent.ts,
+		stx:         parent.stx,
+		config:      parent.config,
+		cache:       c,
+		_currentSTH: parent._currentSTH,
+		_writeRev:   parent._writeRev,
+	}
+	// The latest root has already been read by parent.
+	tx.getLatestRootOnce.Do(func() {})
+
+	return &mapTX{
+		treeTX:  tx,
+		ms:      ms,
+		readRev: parent.readRev,
+	}, nil
+}
+
+func (ms *mapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	if parent, ok := ctx.Value(mapTXKey{tree.TreeId}).(*mapTX); ok {
+		tx, err := ms.join(ctx, parent, tree)
+		if err != nil {
+			return err
+		}
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		// The subtree writes are buffered in parent's transaction, and will
+		// be applied when it commits.
+		return tx.flushSubtrees()
+	}
+
+	_, err := ms.ts.client.ReadWriteTransaction(ctx, func(ctx context.Context, stx *spanner.ReadWriteTransaction) error {
+		tx, err := ms.begin(ctx, tree, stx)
+		if err != nil {
+			return err
+		}
+		if err := f(context.WithValue(ctx, mapTXKey{tree.TreeId}, tx), tx); err != nil {
+			return err
+		}
+		return tx.flushSubtrees()
+	})
+	if spanner.ErrCode(err) == codes.AlreadyExists {
+		// The TreeHeads row for the new revision was inserted concurrently.
+		return storage.ErrMapRevisionExists
+	}
+	return err
+}
+
+func (ms *mapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	return ms.begin(ctx, tree, ms.ts.client.ReadOnlyTransaction())
+}
+
+// mapTX is a concrete implementation of the Trillian storage.MapTreeTX
+// interface.
+type mapTX struct {
+	// treeTX embeds the merkle-tree level transactional actions.
+	*treeTX
+
+	// ms is the mapStorage which begat this mapTX.
+	ms *mapStorage
+
+	// readRev is the revision of the SignedMapRoot most recently read by this
+	// transaction, or -1 if none has been read yet.
+	readRev int64
+}
+
+// ReadRevision returns the revision of the SignedMapRoot most recently read by
+// this transaction, or, if none has been read yet, that of the latest one.
+// It returns -1 if the map has not been initialised.
+func (tx *mapTX) ReadRevision() int64 {
+	if tx.readRev >= 0 {
+		return tx.readRev
+	}
+	sth, err := tx.currentSTH(context.TODO())
+	if err != nil {
+		return -1
+	}
+	return sth.TreeRevision
+}
+
+// WriteRevision returns the map revision at which any writes will be made, or
+// -1 if the map has not been initialised.
+func (tx *mapTX) WriteRevision() int64 {
+	rev, err := tx.writeRev(context.TODO())
+	if err != nil {
+		return -1
+	}
+	return rev
+}
+
+// signedMapRoot reassembles the SignedMapRoot stored as th.
+func signedMapRoot(th *spannerpb.TreeHead) (trillian.SignedMapRoot, error) {
+	// Put mapRoot back together. Fortunately MapRoot has a deterministic serialization.
+	mapRoot, err := (&types.MapRootV1{
+		RootHash:       th.RootHash,
+		TimestampNanos: uint64(th.TsNanos),
+		Revision:       uint64(th.TreeRevision),
+		Metadata:       th.Metadata,
+	}).MarshalBinary()
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	return trillian.SignedMapRoot{
+		MapRoot:   mapRoot,
+		Signature: th.Signature,
+	}, nil
+}
+
+// GetSignedMapRoot returns the SignedMapRoot for the specified revision.
+func (tx *mapTX) GetSignedMapRoot(ctx context.Context, revision int64) (trillian.SignedMapRoot, error) {
+	tx.mu.RLock()
+	defer tx.mu.RUnlock()
+	if tx.stx == nil {
+		return trillian.SignedMapRoot{}, ErrTransactionClosed
+	}
+
+	cols := []string{"TreeID", "TimestampNanos", "TreeSize", "RootHash", "RootSignature", "TreeRevision", "TreeMetadata"}
+	r, err := tx.stx.ReadRow(ctx, treeHeadsTbl, spanner.Key{tx.treeID, revision}, cols)
+	switch {
+	case spanner.ErrCode(err) == codes.NotFound && revision == 0:
+		return trillian.SignedMapRoot{}, storage.ErrTreeNeedsInit
+	case spanner.ErrCode(err) == codes.NotFound:
+		return trillian.SignedMapRoot{}, status.Errorf(codes.NotFound, "no map root for revision %d", revision)
+	case err != nil:
+		return trillian.SignedMapRoot{}, err
+	}
+
+	th := &spannerpb.TreeHead{}
+	if err := r.Columns(&th.TreeId, &th.TsNanos, &th.TreeSize, &th.RootHash, &th.Signature, &th.TreeRevision, &th.Metadata); err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	root, err := signedMapRoot(th)
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	tx.readRev = revision
+	return root, nil
+}
+
+// LatestSignedMapRoot returns the freshest SignedMapRoot for this map at the
+// time the transaction was started.
+func (tx *mapTX) LatestSignedMapRoot(ctx context.Context) (trillian.SignedMapRoot, error) {
+	tx.mu.RLock()
+	defer tx.mu.RUnlock()
+	if tx.stx == nil {
+		return trillian.SignedMapRoot{}, ErrTransactionClosed
+	}
+
+	currentSTH, err := tx.currentSTH(ctx)
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	root, err := signedMapRoot(currentSTH)
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	tx.readRev = currentSTH.TreeRevision
+	return root, nil
+}
+
+// StoreSignedMapRoot stores the provided root.
+// The commit of the transaction will fail if a root has already been stored
+// for the same revision.
+func (tx *mapTX) StoreSignedMapRoot(ctx context.Context, root trillian.SignedMapRoot) error {
+	tx.mu.RLock()
+	defer tx.mu.RUnlock()
+	stx, ok := tx.stx.(*spanner.ReadWriteTransaction)
+	if !ok {
+		return ErrWrongTXType
+	}
+
+	var mapRoot types.MapRootV1
+	if err := mapRoot.UnmarshalBinary(root.MapRoot); err != nil {
+		glog.Warningf("Failed to parse map root: %x %v", root.MapRoot, err)
+		return err
+	}
+
+	m := spanner.Insert(
+		treeHeadsTbl,
+		[]string{
+			"TreeID",
+			"TimestampNanos",
+			"TreeSize",
+			"RootHash",
+			"RootSignature",
+			"TreeRevision",
+			"TreeMetadata",
+		},
+		[]interface{}{
+			int64(tx.treeID),
+			int64(mapRoot.TimestampNanos),
+			int64(0), // Maps don't have a size.
+			mapRoot.RootHash,
+			root.Signature,
+			int64(mapRoot.Revision),
+			mapRoot.Metadata,
+		})
+	return stx.BufferWrite([]*spanner.Mutation{m})
+}
+
+// Set stores the provided leaf under keyHash at the write revision of this
+// transaction.
+func (tx *mapTX) Set(ctx context.Context, keyHash []byte, value trillian.MapLeaf) error {
+	tx.mu.RLock()
+	defer tx.mu.RUnlock()
+	stx, ok := tx.stx.(*spanner.ReadWriteTransaction)
+	if !ok {
+		return ErrWrongTXType
+	}
+
+	writeRev, err := tx.writeRev(ctx)
+	if err != nil {
+		return err
+	}
+
+	leafValue := value.LeafValue
+	if leafValue == nil {
+		// LeafValue is NOT NULL in the schema.
+		leafValue = []byte{}
+	}
+	m := spanner.Insert(
+		mapLeafDataTbl,
+		[]string{colTreeID, colLeafIndex, colMapRevision, colLeafHash, colLeafValue, colExtraData},
+		[]interface{}{tx.treeID, keyHash, writeRev, value.LeafHash, leafValue, value.ExtraData})
+	return stx.BufferWrite([]*spanner.Mutation{m})
+}
+
+// Get returns the most recent values at or below the specified revision for
+// the requested keys.
+// If a key is not found, no corresponding entry is returned.
+// Each MapLeaf.Index is set to the key the leaf was found at.
+func (tx *mapTX) Get(ctx context.Context, revision int64, keys [][]byte) ([]trillian.MapLeaf, error) {
+	tx.mu.RLock()
+	defer tx.mu.RUnlock()
+	if tx.stx == nil {
+		return nil, ErrTransactionClosed
+	}
+	if revision < 0 {
+		revision = math.MaxInt64
+	}
+
+	// Request the various keys in parallel.
+	// c will carry any retrieved leaves (or nil where the key was not found).
+	c := make(chan *trillian.MapLeaf, len(keys))
+	// errc will carry any errors encountered while reading from spanner,
+	// although we'll only return to the caller the first one (if indeed there
+	// are any).
+	errc := make(chan error, len(keys))
+
+	for _, k := range keys {
+		k := k
+		go func() {
+			l, err := tx.getLeaf(ctx, revision, k)
+			if err != nil {
+				errc <- err
+				return
+			}
+			c <- l
+		}()
+	}
+
+	ret := make([]trillian.MapLeaf, 0, len(keys))
+	for range keys {
+		select {
+		case err := <-errc:
+			return nil, err
+		case l := <-c:
+			if l != nil {
+				ret = append(ret, *l)
+			}
+		}
+	}
+	return ret, nil
+}
+
+// GetLeavesAfter is not yet implemented for CloudSpanner.
+func (tx *mapTX) GetLeavesAfter(ctx context.Context, revision int64, keyHash []byte, limit int) ([]trillian.MapLeaf, error) {
+	return nil, status.Errorf(codes.Unimplemented, "GetLeavesAfter is not implemented")
+}
+
+// GetLeafHistory returns the values written to key at revisions between
+// fromRevision and toRevision inclusive, in ascending revision order.
+func (tx *mapTX) GetLeafHistory(ctx context.Context, key []byte, fromRevision, toRevision int64) ([]storage.MapLeafRevision, error) {
+	stmt := spanner.NewStatement(
+		"SELECT t.MapRevision, t.LeafHash, t.LeafValue, t.ExtraData FROM MapLeafData t" +
+			"  WHERE t.TreeID = @tree_id" +
+			"  AND   t.LeafIndex = @leaf_index" +
+			"  AND   t.MapRevision >= @from_revision" +
+			"  AND   t.MapRevision <= @to_revision" +
+			"  ORDER BY t.MapRevision")
+	stmt.Params["tree_id"] = tx.treeID
+	stmt.Params["leaf_index"] = key
+	stmt.Params["from_revision"] = fromRevision
+	stmt.Params["to_revision"] = toRevision
+
+	ret := make([]storage.MapLeafRevision, 0)
+	rows := tx.stx.Query(ctx, stmt)
+	err := rows.Do(func(r *spanner.Row) error {
+		lr := storage.MapLeafRevision{Leaf: trillian.MapLeaf{Index: key}}
+		if err := r.Columns(&lr.Revision, &lr.Leaf.LeafHash, &lr.Leaf.LeafValue, &lr.Leaf.ExtraData); err != nil {
+			return err
+		}
+		ret = append(ret, lr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// getLeaf returns the most recent value of key at (or below) the requested
+// revision. If no such value exists it returns nil.
+func (tx *mapTX) getLeaf(ctx context.Context, revision int64, key []byte) (*trillian.MapLeaf, error) {
+	stmt := spanner.NewStatement(
+		"SELECT t.LeafHash, t.LeafValue, t.ExtraData FROM MapLeafData t" +
+			"  WHERE t.TreeID = @tree_id" +
+			"  AND   t.LeafIndex = @leaf_index" +
+			"  AND   t.MapRevision <= @revision" +
+			"  ORDER BY t.MapRevision DESC" +
+			"  LIMIT 1")
+	stmt.Params["tree_id"] = tx.treeID
+	stmt.Params["leaf_index"] = key
+	stmt.Params["revision"] = revision
+
+	var ret *trillian.MapLeaf
+	rows := tx.stx.Query(ctx, stmt)
+	err := rows.Do(func(r *spanner.Row) error {
+		l := &trillian.MapLeaf{Index: key}
+		if err := r.Columns(&l.LeafHash, &l.LeafValue, &l.ExtraData); err != nil {
+			return err
+		}
+		ret = l
+		return nil
+	})
+	return ret, err
+}
+
+// readOnlyMapTX implements storage.ReadOnlyMapTX.
+type readOnlyMapTX struct {
+	*snapshotTX
+}
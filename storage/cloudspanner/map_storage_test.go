@@ -0,0 +1,147 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+	"crypto"
+	"flag"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+	storageto "github.com/google/trillian/storage/testonly"
+
+	_ "github.com/google/trillian/merkle/maphasher"
+)
+
+// testDB is the URI of a database to run the tests against, which must have
+// been created with the schema in spanner.sdl. It may be served by the Cloud
+// Spanner emulator.
+var testDB = flag.String("cloudspanner_test_db", "", "CloudSpanner database URI to test against, e.g. projects/p/instances/i/databases/d")
+
+var fixedSigner = tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+
+func mustSignMapRoot(root *types.MapRootV1) *trillian.SignedMapRoot {
+	r, err := fixedSigner.SignMapRoot(root)
+	if err != nil {
+		panic(fmt.Sprintf("SignMapRoot(): %v", err))
+	}
+	return r
+}
+
+// openTestClientOrSkip returns a client for testDB, skipping the test if no
+// database was specified.
+func openTestClientOrSkip(ctx context.Context, t *testing.T) *spanner.Client {
+	t.Helper()
+	if *testDB == "" {
+		t.Skip("Skipping CloudSpanner test, --cloudspanner_test_db not set")
+	}
+	client, err := spanner.NewClient(ctx, *testDB)
+	if err != nil {
+		t.Fatalf("NewClient(%q): %v", *testDB, err)
+	}
+	return client
+}
+
+func TestMapStorage(t *testing.T) {
+	ctx := context.Background()
+	client := openTestClientOrSkip(ctx, t)
+	defer client.Close()
+
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(client), storageto.MapTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	s := NewMapStorage(client)
+	keyHash := []byte("A Key Hash")
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if _, err := tx.LatestSignedMapRoot(ctx); err != storage.ErrTreeNeedsInit {
+			t.Errorf("LatestSignedMapRoot() on new map = (_, %v), want = (_, %v)", err, storage.ErrTreeNeedsInit)
+		}
+		if got, want := tx.WriteRevision(), int64(-1); got != want {
+			t.Errorf("WriteRevision() on new map = %v, want = %v", got, want)
+		}
+		return tx.StoreSignedMapRoot(ctx, *mustSignMapRoot(&types.MapRootV1{Revision: 0}))
+	})
+
+	var roots []*trillian.SignedMapRoot
+	var leaves []trillian.MapLeaf
+	for rev := int64(1); rev <= 3; rev++ {
+		leaf := trillian.MapLeaf{Index: keyHash, LeafHash: []byte{byte(rev)}, LeafValue: []byte{byte(rev)}, ExtraData: []byte{byte(rev)}}
+		root := mustSignMapRoot(&types.MapRootV1{TimestampNanos: uint64(rev), Revision: uint64(rev), Metadata: []byte("meta")})
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			if got, want := tx.WriteRevision(), rev; got != want {
+				t.Errorf("WriteRevision() = %v, want = %v", got, want)
+			}
+			if err := tx.Set(ctx, keyHash, leaf); err != nil {
+				return err
+			}
+			return tx.StoreSignedMapRoot(ctx, *root)
+		})
+		roots = append(roots, root)
+		leaves = append(leaves, leaf)
+	}
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+
+	latest, err := tx.LatestSignedMapRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedMapRoot(): %v", err)
+	}
+	if want := roots[len(roots)-1]; !proto.Equal(&latest, want) {
+		t.Errorf("LatestSignedMapRoot() = %v, want %v", latest, want)
+	}
+	for i, want := range roots {
+		rev := int64(i + 1)
+		got, err := tx.GetSignedMapRoot(ctx, rev)
+		if err != nil {
+			t.Fatalf("GetSignedMapRoot(%d): %v", rev, err)
+		}
+		if !proto.Equal(&got, want) {
+			t.Errorf("GetSignedMapRoot(%d) = %v, want %v", rev, got, want)
+		}
+
+		values, err := tx.Get(ctx, rev, [][]byte{keyHash, []byte("This doesn't exist.")})
+		if err != nil {
+			t.Fatalf("Get(%d): %v", rev, err)
+		}
+		if got, want := len(values), 1; got != want {
+			t.Fatalf("Get(%d) returned %d leaves, want %d", rev, got, want)
+		}
+		if got, want := &values[0], &leaves[i]; !proto.Equal(got, want) {
+			t.Errorf("Get(%d) = %v, want %v", rev, got, want)
+		}
+	}
+}
+
+func runMapTX(ctx context.Context, s storage.MapStorage, tree *trillian.Tree, t *testing.T, f storage.MapTXFunc) {
+	t.Helper()
+	if err := s.ReadWriteTransaction(ctx, tree, f); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+}
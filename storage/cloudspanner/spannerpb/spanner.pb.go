@@ -94,6 +94,8 @@ const (
 	HashStrategy_TEST_MAP_HASHER       HashStrategy = 2
 	HashStrategy_OBJECT_RFC6962_SHA256 HashStrategy = 3
 	HashStrategy_CONIKS_SHA512_256     HashStrategy = 4
+	HashStrategy_RFC_6962_SHA384       HashStrategy = 5
+	HashStrategy_RFC_6962_SHA512       HashStrategy = 6
 )
 
 var HashStrategy_name = map[int32]string{
@@ -102,6 +104,8 @@ var HashStrategy_name = map[int32]string{
 	2: "TEST_MAP_HASHER",
 	3: "OBJECT_RFC6962_SHA256",
 	4: "CONIKS_SHA512_256",
+	5: "RFC_6962_SHA384",
+	6: "RFC_6962_SHA512",
 }
 var HashStrategy_value = map[string]int32{
 	"UNKNOWN_HASH_STRATEGY": 0,
@@ -109,6 +113,8 @@ var HashStrategy_value = map[string]int32{
 	"TEST_MAP_HASHER":       2,
 	"OBJECT_RFC6962_SHA256": 3,
 	"CONIKS_SHA512_256":     4,
+	"RFC_6962_SHA384":       5,
+	"RFC_6962_SHA512":       6,
 }
 
 func (x HashStrategy) String() string {
@@ -127,15 +133,23 @@ const (
 	HashAlgorithm_NONE HashAlgorithm = 0
 	// SHA256 is used.
 	HashAlgorithm_SHA256 HashAlgorithm = 4
+	// SHA384 is used.
+	HashAlgorithm_SHA384 HashAlgorithm = 5
+	// SHA512 is used.
+	HashAlgorithm_SHA512 HashAlgorithm = 6
 )
 
 var HashAlgorithm_name = map[int32]string{
 	0: "NONE",
 	4: "SHA256",
+	5: "SHA384",
+	6: "SHA512",
 }
 var HashAlgorithm_value = map[string]int32{
 	"NONE":   0,
 	"SHA256": 4,
+	"SHA384": 5,
+	"SHA512": 6,
 }
 
 func (x HashAlgorithm) String() string {
@@ -156,17 +170,21 @@ const (
 	SignatureAlgorithm_RSA SignatureAlgorithm = 1
 	// ECDSA signature scheme.
 	SignatureAlgorithm_ECDSA SignatureAlgorithm = 3
+	// Ed25519 signature scheme.
+	SignatureAlgorithm_ED25519 SignatureAlgorithm = 7
 )
 
 var SignatureAlgorithm_name = map[int32]string{
 	0: "ANONYMOUS",
 	1: "RSA",
 	3: "ECDSA",
+	7: "ED25519",
 }
 var SignatureAlgorithm_value = map[string]int32{
 	"ANONYMOUS": 0,
 	"RSA":       1,
 	"ECDSA":     3,
+	"ED25519":   7,
 }
 
 func (x SignatureAlgorithm) String() string {
@@ -267,6 +285,8 @@ type TreeInfo struct {
 	Deleted bool `protobuf:"varint,18,opt,name=deleted" json:"deleted,omitempty"`
 	// Time of tree deletion, if any.
 	DeleteTimeNanos int64 `protobuf:"varint,19,opt,name=delete_time_nanos,json=deleteTimeNanos" json:"delete_time_nanos,omitempty"`
+	// labels are the user-defined labels of the tree.
+	Labels map[string]string `protobuf:"bytes,20,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *TreeInfo) Reset()                    { *m = TreeInfo{} }
@@ -421,6 +441,13 @@ func (m *TreeInfo) GetDeleteTimeNanos() int64 {
 	return 0
 }
 
+func (m *TreeInfo) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*TreeInfo) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _TreeInfo_OneofMarshaler, _TreeInfo_OneofUnmarshaler, _TreeInfo_OneofSizer, []interface{}{
@@ -584,65 +611,71 @@ func init() {
 func init() { proto.RegisterFile("spanner.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 950 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x55, 0xed, 0x6e, 0x1a, 0x47,
-	0x14, 0xf5, 0x1a, 0x0c, 0xcb, 0x35, 0xd8, 0xe3, 0x71, 0xdc, 0xac, 0x93, 0x56, 0x42, 0x6e, 0x2b,
-	0x51, 0x54, 0x41, 0xeb, 0xc8, 0x8e, 0xa2, 0x54, 0xaa, 0xd6, 0x18, 0x07, 0x9b, 0xb0, 0x44, 0xb3,
-	0xeb, 0x56, 0xc9, 0x9f, 0xd5, 0xc0, 0x8e, 0x61, 0xe5, 0xfd, 0xea, 0xee, 0x6c, 0x14, 0xf2, 0xa3,
-	0x4f, 0xd0, 0x17, 0xed, 0x5b, 0x54, 0x33, 0xb3, 0x60, 0x8c, 0xd5, 0x7f, 0x33, 0xe7, 0x9e, 0x73,
-	0xaf, 0xe7, 0xfa, 0x9c, 0x05, 0x1a, 0x59, 0x42, 0xa3, 0x88, 0xa5, 0x9d, 0x24, 0x8d, 0x79, 0x8c,
-	0x6b, 0xc5, 0x35, 0x99, 0xbc, 0x38, 0x9e, 0xc5, 0xf1, 0x2c, 0x60, 0x5d, 0x59, 0x98, 0xe4, 0x77,
-	0x5d, 0x1a, 0x2d, 0x14, 0xeb, 0x24, 0x00, 0xf4, 0x3e, 0x9e, 0xd9, 0x3c, 0x4e, 0xe9, 0x8c, 0xf5,
-	0xe2, 0xe8, 0xce, 0x9f, 0xe1, 0x36, 0x1c, 0x44, 0x79, 0xe8, 0xe6, 0x51, 0xc6, 0xfe, 0x72, 0x27,
-	0xf9, 0xf4, 0x9e, 0xf1, 0xcc, 0xd0, 0x9a, 0x5a, 0xab, 0x44, 0xf6, 0xa3, 0x3c, 0xbc, 0x15, 0xf8,
-	0x85, 0x82, 0xf1, 0xcf, 0x80, 0x05, 0x37, 0x64, 0xe9, 0x7d, 0xc0, 0x56, 0xe4, 0x6d, 0x49, 0x46,
-	0x51, 0x1e, 0x8e, 0x64, 0xa1, 0x60, 0x9f, 0x60, 0x40, 0x23, 0x9a, 0x3c, 0x9a, 0x76, 0xf2, 0x4f,
-	0x15, 0x74, 0x27, 0x65, 0xec, 0x3a, 0xba, 0x8b, 0xf1, 0x73, 0xa8, 0xf2, 0x94, 0x31, 0xd7, 0xf7,
-	0x8a, 0x81, 0x15, 0x71, 0xbd, 0xf6, 0xf0, 0x11, 0x54, 0xee, 0xd9, 0x42, 0xe0, 0xaa, 0xf7, 0xce,
-	0x3d, 0x5b, 0x5c, 0x7b, 0x18, 0x43, 0x39, 0xa2, 0x21, 0x33, 0x4a, 0x4d, 0xad, 0x55, 0x23, 0xf2,
-	0x8c, 0x9b, 0xb0, 0xeb, 0xb1, 0x6c, 0x9a, 0xfa, 0x09, 0xf7, 0xe3, 0xc8, 0x28, 0xcb, 0xd2, 0x3a,
-	0x84, 0x7f, 0x81, 0x9a, 0x9c, 0xc2, 0x17, 0x09, 0x33, 0x76, 0x9a, 0x5a, 0x6b, 0xef, 0xf4, 0xb0,
-	0xb3, 0x5a, 0x57, 0x47, 0xfc, 0x35, 0xce, 0x22, 0x61, 0x44, 0xe7, 0xc5, 0x09, 0xbf, 0x02, 0x90,
-	0x8a, 0x8c, 0x53, 0xce, 0x0c, 0x5d, 0x4a, 0x9e, 0x6d, 0x48, 0x6c, 0x51, 0x23, 0xb2, 0xb3, 0x3c,
-	0xe2, 0xdf, 0xa0, 0x31, 0xa7, 0xd9, 0xdc, 0xcd, 0x78, 0x4a, 0x39, 0x9b, 0x2d, 0x8c, 0x9a, 0xd4,
-	0x3d, 0x5f, 0xd3, 0x0d, 0x68, 0x36, 0xb7, 0x8b, 0x32, 0xa9, 0xcf, 0xd7, 0x6e, 0xf8, 0x77, 0xd8,
-	0x93, 0x6a, 0x1a, 0xcc, 0xe2, 0xd4, 0xe7, 0xf3, 0xd0, 0x00, 0x29, 0x37, 0x36, 0xe4, 0xe6, 0xb2,
-	0x4e, 0xe4, 0xb4, 0xd5, 0x15, 0x5b, 0x70, 0x98, 0xf9, 0xb3, 0x88, 0xf2, 0x3c, 0x65, 0x6b, 0x5d,
-	0x76, 0x65, 0x97, 0xef, 0xd6, 0xba, 0xd8, 0x4b, 0xd6, 0x43, 0x2b, 0x9c, 0x3d, 0xc1, 0x84, 0x2d,
-	0xa6, 0x29, 0xa3, 0x9c, 0xb9, 0xdc, 0x0f, 0x99, 0x1b, 0xd1, 0x28, 0xce, 0x8c, 0x86, 0xb2, 0x85,
-	0x2a, 0x38, 0x7e, 0xc8, 0x2c, 0x01, 0x0b, 0x6e, 0x9e, 0x78, 0x1b, 0xdc, 0x3d, 0xc5, 0x55, 0x85,
-	0x07, 0xee, 0x19, 0xec, 0x26, 0xa9, 0xff, 0x59, 0x90, 0xef, 0xd9, 0xc2, 0xd8, 0x6f, 0x6a, 0xad,
-	0xdd, 0xd3, 0x67, 0x1d, 0xe5, 0xd9, 0xce, 0xd2, 0xb3, 0x1d, 0x33, 0x5a, 0x10, 0x28, 0x88, 0x43,
-	0xb6, 0xc0, 0x3f, 0xc0, 0x5e, 0x92, 0x4f, 0x02, 0x7f, 0x2a, 0x54, 0xae, 0xc7, 0x52, 0x03, 0x35,
-	0xb5, 0x56, 0x9d, 0xd4, 0x15, 0x3a, 0x64, 0x8b, 0x4b, 0x96, 0xe2, 0x21, 0xe0, 0x20, 0x9e, 0xb9,
-	0x99, 0xb2, 0x9c, 0x3b, 0x95, 0x9e, 0x33, 0x2a, 0x72, 0xc6, 0xcb, 0xb5, 0x1d, 0x6c, 0x86, 0x60,
-	0xb0, 0x45, 0x50, 0xb0, 0x19, 0x8c, 0x21, 0xe0, 0x90, 0x26, 0x9b, 0xcd, 0xaa, 0x4f, 0x9a, 0x6d,
-	0x7a, 0x5c, 0x34, 0x0b, 0x37, 0x30, 0xfc, 0x1a, 0x8c, 0x90, 0x7e, 0x71, 0xd3, 0x38, 0xe6, 0xae,
-	0x97, 0xa7, 0x54, 0x38, 0xd3, 0x0d, 0xfd, 0x20, 0xf0, 0x33, 0xe3, 0x40, 0x6e, 0xea, 0x28, 0xa4,
-	0x5f, 0x48, 0x1c, 0xf3, 0xcb, 0xa2, 0x3a, 0x92, 0x45, 0x6c, 0x40, 0xd5, 0x63, 0x01, 0xe3, 0xcc,
-	0x33, 0x70, 0x53, 0x6b, 0xe9, 0x64, 0x79, 0x15, 0x5b, 0x57, 0xc7, 0xf5, 0xad, 0x1f, 0xaa, 0xad,
-	0xab, 0xc2, 0x6a, 0xeb, 0x17, 0x08, 0xf6, 0x1e, 0xbf, 0xe3, 0xa6, 0xac, 0xd7, 0x51, 0xe3, 0xe4,
-	0x5f, 0x4d, 0xc5, 0x71, 0xc0, 0xa8, 0xf7, 0xff, 0x71, 0x3c, 0x06, 0x9d, 0x67, 0xc5, 0x00, 0x15,
-	0xc8, 0x2a, 0xcf, 0xd4, 0xbf, 0xf3, 0x65, 0x11, 0xae, 0xcc, 0xff, 0xaa, 0x72, 0x59, 0x52, 0x39,
-	0xb2, 0xfd, 0xaf, 0x4c, 0x14, 0xe5, 0x83, 0x85, 0x53, 0x65, 0x32, 0xeb, 0x44, 0x17, 0x80, 0x30,
-	0x32, 0xfe, 0x16, 0x6a, 0x2b, 0xdb, 0x49, 0xb3, 0xd7, 0xc9, 0x03, 0x80, 0xbf, 0x87, 0x86, 0xec,
-	0x9b, 0xb2, 0xcf, 0x7e, 0x26, 0x82, 0x5d, 0x91, 0xbd, 0xeb, 0x02, 0x24, 0x05, 0x86, 0x5f, 0x80,
-	0x1e, 0x32, 0x4e, 0x3d, 0xca, 0xa9, 0x4c, 0x5b, 0x9d, 0xac, 0xee, 0x37, 0x65, 0x7d, 0x07, 0x55,
-	0x6e, 0xca, 0xba, 0x8e, 0x6a, 0x37, 0x65, 0xbd, 0x8a, 0xf4, 0xf6, 0x5b, 0xa8, 0xad, 0x82, 0x8b,
-	0xbf, 0x01, 0x7c, 0x6b, 0x0d, 0xad, 0xf1, 0x9f, 0x96, 0xeb, 0x90, 0x7e, 0xdf, 0xb5, 0x1d, 0xd3,
-	0xe9, 0xa3, 0x2d, 0x0c, 0x50, 0x31, 0x7b, 0xce, 0xf5, 0x1f, 0x7d, 0xa4, 0x89, 0xf3, 0x15, 0x19,
-	0x7f, 0xea, 0x5b, 0x68, 0xbb, 0xfd, 0x93, 0xda, 0x93, 0xfc, 0x3c, 0xec, 0x42, 0xb5, 0xd0, 0xa2,
-	0x2d, 0x5c, 0x85, 0xd2, 0xfb, 0xf1, 0x3b, 0xa4, 0x89, 0xc3, 0xc8, 0xfc, 0x80, 0xb6, 0xdb, 0x7f,
-	0x43, 0x7d, 0x3d, 0xe8, 0xf8, 0x18, 0x8e, 0x96, 0xa3, 0x06, 0xa6, 0x3d, 0x70, 0x6d, 0x87, 0x98,
-	0x4e, 0xff, 0xdd, 0x47, 0xb4, 0x85, 0xeb, 0xa0, 0x93, 0xab, 0x9e, 0x7b, 0xfe, 0xe6, 0xfc, 0x14,
-	0x69, 0xf8, 0x10, 0xf6, 0x9d, 0xbe, 0xed, 0xb8, 0x23, 0xf3, 0x83, 0x64, 0xf6, 0x09, 0xda, 0x16,
-	0xea, 0xf1, 0xc5, 0x4d, 0xbf, 0xe7, 0xb8, 0xe4, 0xaa, 0x27, 0x88, 0xae, 0x3d, 0x30, 0x4f, 0xcf,
-	0xce, 0x51, 0x09, 0x1f, 0xc1, 0x41, 0x6f, 0x6c, 0x5d, 0x0f, 0x6d, 0x01, 0x9d, 0xfd, 0x7a, 0xea,
-	0x0a, 0xb8, 0xdc, 0xfe, 0x11, 0x1a, 0x8f, 0xbe, 0x14, 0x58, 0x87, 0xb2, 0x35, 0xb6, 0x8a, 0xd7,
-	0x15, 0xea, 0x72, 0xfb, 0x35, 0xe0, 0xa7, 0x9f, 0x02, 0xdc, 0x80, 0x9a, 0x69, 0x8d, 0xad, 0x8f,
-	0xa3, 0xf1, 0xad, 0xad, 0x5e, 0x47, 0x6c, 0x13, 0x69, 0xb8, 0x06, 0x3b, 0xfd, 0xde, 0xa5, 0x6d,
-	0xa2, 0xd2, 0xc5, 0xdb, 0x4f, 0x6f, 0x66, 0x3e, 0x9f, 0xe7, 0x93, 0xce, 0x34, 0x0e, 0xbb, 0xc5,
-	0x8f, 0x0d, 0x4f, 0x85, 0x5d, 0x69, 0xd4, 0x2d, 0x6c, 0xd6, 0x9d, 0x06, 0x71, 0xee, 0x15, 0x21,
-	0xe9, 0xae, 0xc2, 0x32, 0xa9, 0xc8, 0x84, 0xbf, 0xfa, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x4d, 0xcf,
-	0xfb, 0xcd, 0xbf, 0x06, 0x00, 0x00,
+	// 1041 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x55, 0x6d, 0x73, 0xda, 0x46,
+	0x10, 0xb6, 0xcc, 0x9b, 0x58, 0xc0, 0xb9, 0x9c, 0xed, 0x46, 0x49, 0xda, 0x29, 0xe3, 0xf6, 0x03,
+	0xf5, 0x74, 0xa0, 0x21, 0x25, 0x89, 0x9b, 0x76, 0x3a, 0x32, 0x56, 0x82, 0xed, 0x00, 0x99, 0x93,
+	0xd2, 0x4e, 0xf2, 0x45, 0x73, 0xa0, 0x33, 0x68, 0xac, 0x17, 0x2a, 0x9d, 0x3c, 0x21, 0x9f, 0xfa,
+	0x73, 0xfa, 0xd7, 0xfa, 0x2f, 0x3a, 0x77, 0x27, 0x30, 0xc6, 0xd3, 0x6f, 0xbb, 0xcf, 0x3e, 0xfb,
+	0x9c, 0xb5, 0xde, 0x67, 0x81, 0x46, 0xba, 0xa0, 0x51, 0xc4, 0x92, 0xf6, 0x22, 0x89, 0x79, 0x8c,
+	0xab, 0x79, 0xba, 0x98, 0x3c, 0x79, 0x3c, 0x8b, 0xe3, 0x59, 0xc0, 0x3a, 0xb2, 0x30, 0xc9, 0xae,
+	0x3a, 0x34, 0x5a, 0x2a, 0xd6, 0x51, 0x00, 0xe8, 0x5d, 0x3c, 0xb3, 0x79, 0x9c, 0xd0, 0x19, 0xeb,
+	0xc7, 0xd1, 0x95, 0x3f, 0xc3, 0xc7, 0xf0, 0x30, 0xca, 0x42, 0x37, 0x8b, 0x52, 0xf6, 0x97, 0x3b,
+	0xc9, 0xa6, 0xd7, 0x8c, 0xa7, 0x86, 0xd6, 0xd4, 0x5a, 0x05, 0xf2, 0x20, 0xca, 0xc2, 0x0f, 0x02,
+	0x3f, 0x55, 0x30, 0xfe, 0x11, 0xb0, 0xe0, 0x86, 0x2c, 0xb9, 0x0e, 0xd8, 0x9a, 0xbc, 0x2b, 0xc9,
+	0x28, 0xca, 0xc2, 0xa1, 0x2c, 0xe4, 0xec, 0x23, 0x0c, 0x68, 0x48, 0x17, 0x77, 0x5e, 0x3b, 0xfa,
+	0x5b, 0x07, 0xdd, 0x49, 0x18, 0x3b, 0x8f, 0xae, 0x62, 0xfc, 0x08, 0x2a, 0x3c, 0x61, 0xcc, 0xf5,
+	0xbd, 0xfc, 0xc1, 0xb2, 0x48, 0xcf, 0x3d, 0x7c, 0x08, 0xe5, 0x6b, 0xb6, 0x14, 0xb8, 0xd2, 0x2e,
+	0x5d, 0xb3, 0xe5, 0xb9, 0x87, 0x31, 0x14, 0x23, 0x1a, 0x32, 0xa3, 0xd0, 0xd4, 0x5a, 0x55, 0x22,
+	0x63, 0xdc, 0x84, 0x9a, 0xc7, 0xd2, 0x69, 0xe2, 0x2f, 0xb8, 0x1f, 0x47, 0x46, 0x51, 0x96, 0x36,
+	0x21, 0xfc, 0x13, 0x54, 0xe5, 0x2b, 0x7c, 0xb9, 0x60, 0x46, 0xa9, 0xa9, 0xb5, 0xf6, 0xba, 0xfb,
+	0xed, 0xf5, 0xb8, 0xda, 0xe2, 0xaf, 0x71, 0x96, 0x0b, 0x46, 0x74, 0x9e, 0x47, 0xf8, 0x39, 0x80,
+	0xec, 0x48, 0x39, 0xe5, 0xcc, 0xd0, 0x65, 0xcb, 0xc1, 0x56, 0x8b, 0x2d, 0x6a, 0xa4, 0xca, 0x57,
+	0x21, 0xfe, 0x15, 0x1a, 0x73, 0x9a, 0xce, 0xdd, 0x94, 0x27, 0x94, 0xb3, 0xd9, 0xd2, 0xa8, 0xca,
+	0xbe, 0x47, 0x1b, 0x7d, 0x03, 0x9a, 0xce, 0xed, 0xbc, 0x4c, 0xea, 0xf3, 0x8d, 0x0c, 0xff, 0x0e,
+	0x7b, 0xb2, 0x9b, 0x06, 0xb3, 0x38, 0xf1, 0xf9, 0x3c, 0x34, 0x40, 0xb6, 0x1b, 0x5b, 0xed, 0xe6,
+	0xaa, 0x4e, 0x1a, 0xf3, 0xcd, 0x14, 0x8f, 0x60, 0x3f, 0xf5, 0x67, 0x11, 0xe5, 0x59, 0xc2, 0x36,
+	0x54, 0x6a, 0x52, 0xe5, 0x9b, 0x0d, 0x15, 0x7b, 0xc5, 0xba, 0x95, 0xc2, 0xe9, 0x3d, 0x4c, 0xac,
+	0xc5, 0x34, 0x61, 0x94, 0x33, 0x97, 0xfb, 0x21, 0x73, 0x23, 0x1a, 0xc5, 0xa9, 0xd1, 0x50, 0x6b,
+	0xa1, 0x0a, 0x8e, 0x1f, 0xb2, 0x91, 0x80, 0x05, 0x37, 0x5b, 0x78, 0x5b, 0xdc, 0x3d, 0xc5, 0x55,
+	0x85, 0x5b, 0x6e, 0x0f, 0x6a, 0x8b, 0xc4, 0xbf, 0x11, 0xe4, 0x6b, 0xb6, 0x34, 0x1e, 0x34, 0xb5,
+	0x56, 0xad, 0x7b, 0xd0, 0x56, 0x3b, 0xdb, 0x5e, 0xed, 0x6c, 0xdb, 0x8c, 0x96, 0x04, 0x72, 0xe2,
+	0x25, 0x5b, 0xe2, 0xef, 0x61, 0x6f, 0x91, 0x4d, 0x02, 0x7f, 0x2a, 0xba, 0x5c, 0x8f, 0x25, 0x06,
+	0x6a, 0x6a, 0xad, 0x3a, 0xa9, 0x2b, 0xf4, 0x92, 0x2d, 0xcf, 0x58, 0x82, 0x2f, 0x01, 0x07, 0xf1,
+	0xcc, 0x4d, 0xd5, 0xca, 0xb9, 0x53, 0xb9, 0x73, 0x46, 0x59, 0xbe, 0xf1, 0x74, 0x63, 0x06, 0xdb,
+	0x26, 0x18, 0xec, 0x10, 0x14, 0x6c, 0x61, 0x42, 0x2c, 0xa4, 0x8b, 0x6d, 0xb1, 0xca, 0x3d, 0xb1,
+	0xed, 0x1d, 0x17, 0x62, 0xe1, 0x16, 0x86, 0x5f, 0x82, 0x11, 0xd2, 0xcf, 0x6e, 0x12, 0xc7, 0xdc,
+	0xf5, 0xb2, 0x84, 0x8a, 0xcd, 0x74, 0x43, 0x3f, 0x08, 0xfc, 0xd4, 0x78, 0x28, 0x27, 0x75, 0x18,
+	0xd2, 0xcf, 0x24, 0x8e, 0xf9, 0x59, 0x5e, 0x1d, 0xca, 0x22, 0x36, 0xa0, 0xe2, 0xb1, 0x80, 0x71,
+	0xe6, 0x19, 0xb8, 0xa9, 0xb5, 0x74, 0xb2, 0x4a, 0xc5, 0xd4, 0x55, 0xb8, 0x39, 0xf5, 0x7d, 0x35,
+	0x75, 0x55, 0xb8, 0x9d, 0xfa, 0x4b, 0x28, 0x07, 0x74, 0xc2, 0x82, 0xd4, 0x38, 0x68, 0x16, 0x5a,
+	0xb5, 0xee, 0xb7, 0x5b, 0xdb, 0x2c, 0xec, 0xd8, 0x7e, 0x27, 0x19, 0x56, 0xc4, 0x93, 0x25, 0xc9,
+	0xe9, 0x4f, 0x4e, 0xa0, 0xb6, 0x01, 0x63, 0x04, 0x05, 0xf1, 0x5f, 0xd3, 0xa4, 0xcb, 0x44, 0x88,
+	0x0f, 0xa0, 0x74, 0x43, 0x83, 0x8c, 0x49, 0xa7, 0x56, 0x89, 0x4a, 0x7e, 0xd9, 0x7d, 0xa5, 0x9d,
+	0x22, 0xd8, 0xbb, 0x3b, 0xbb, 0x8b, 0xa2, 0x5e, 0x47, 0x8d, 0xa3, 0x7f, 0x35, 0x75, 0x02, 0x06,
+	0x8c, 0x7a, 0xff, 0x7f, 0x02, 0x1e, 0x83, 0xce, 0xd3, 0xfc, 0xa3, 0xd4, 0x11, 0xa8, 0xf0, 0x54,
+	0x7d, 0xcc, 0xd3, 0xdc, 0xd0, 0xa9, 0xff, 0x45, 0xdd, 0x82, 0x82, 0xf2, 0xae, 0xed, 0x7f, 0x61,
+	0xa2, 0x28, 0x87, 0x2c, 0xdc, 0x21, 0xaf, 0x41, 0x9d, 0xe8, 0x02, 0x10, 0xe6, 0xc1, 0x5f, 0x43,
+	0x75, 0xbd, 0xea, 0xd2, 0x60, 0x75, 0x72, 0x0b, 0xe0, 0xef, 0xa0, 0x21, 0x75, 0x13, 0x76, 0xe3,
+	0xa7, 0xe2, 0x98, 0x94, 0xa5, 0x76, 0x5d, 0x80, 0x24, 0xc7, 0xf0, 0x13, 0xd0, 0x43, 0xc6, 0xa9,
+	0x47, 0x39, 0x95, 0x0e, 0xaf, 0x93, 0x75, 0x7e, 0x51, 0xd4, 0x4b, 0xa8, 0x7c, 0x51, 0xd4, 0x75,
+	0x54, 0xbd, 0x28, 0xea, 0x15, 0xa4, 0x1f, 0xbf, 0x86, 0xea, 0xfa, 0x58, 0xe0, 0xaf, 0x00, 0x7f,
+	0x18, 0x5d, 0x8e, 0xc6, 0x7f, 0x8e, 0x5c, 0x87, 0x58, 0x96, 0x6b, 0x3b, 0xa6, 0x63, 0xa1, 0x1d,
+	0x0c, 0x50, 0x36, 0xfb, 0xce, 0xf9, 0x1f, 0x16, 0xd2, 0x44, 0xfc, 0x86, 0x8c, 0x3f, 0x59, 0x23,
+	0xb4, 0x7b, 0xfc, 0x83, 0x9a, 0x93, 0x3c, 0x49, 0x35, 0xa8, 0xe4, 0xbd, 0x68, 0x07, 0x57, 0xa0,
+	0xf0, 0x6e, 0xfc, 0x16, 0x69, 0x22, 0x18, 0x9a, 0xef, 0xd1, 0xee, 0xf1, 0x3f, 0x1a, 0xd4, 0x37,
+	0xaf, 0x0b, 0x7e, 0x0c, 0x87, 0xab, 0xb7, 0x06, 0xa6, 0x3d, 0x70, 0x6d, 0x87, 0x98, 0x8e, 0xf5,
+	0xf6, 0x23, 0xda, 0xc1, 0x75, 0xd0, 0xc9, 0x9b, 0xbe, 0xfb, 0xe2, 0xe4, 0x45, 0x17, 0x69, 0x78,
+	0x1f, 0x1e, 0x38, 0x96, 0xed, 0xb8, 0x43, 0xf3, 0xbd, 0x64, 0x5a, 0x04, 0xed, 0x8a, 0xee, 0xf1,
+	0xe9, 0x85, 0xd5, 0x77, 0x5c, 0xf2, 0xa6, 0x2f, 0x88, 0xae, 0x3d, 0x30, 0xbb, 0xbd, 0x17, 0xa8,
+	0x80, 0x0f, 0xe1, 0x61, 0x7f, 0x3c, 0x3a, 0xbf, 0xb4, 0x05, 0xd4, 0x7b, 0xd6, 0x75, 0x05, 0x5c,
+	0x14, 0x32, 0x2b, 0x51, 0x51, 0x78, 0xfe, 0xea, 0x67, 0x54, 0xda, 0x06, 0x7b, 0xcf, 0xba, 0xa8,
+	0x7c, 0xfc, 0x1b, 0x34, 0xee, 0x1c, 0x32, 0xac, 0x43, 0x71, 0x34, 0x1e, 0xe5, 0x83, 0xc8, 0xdf,
+	0x29, 0xe6, 0xb1, 0xd2, 0x51, 0xb1, 0x6a, 0x3f, 0x03, 0x7c, 0xff, 0x82, 0xe1, 0x06, 0x54, 0xcd,
+	0xd1, 0x78, 0xf4, 0x71, 0x38, 0xfe, 0x60, 0xab, 0x01, 0x11, 0xdb, 0x44, 0x1a, 0xae, 0x42, 0xc9,
+	0xea, 0x9f, 0xd9, 0x26, 0x2a, 0x88, 0x09, 0x5a, 0x67, 0xdd, 0x5e, 0xef, 0xd9, 0x09, 0xaa, 0x9c,
+	0xbe, 0xfe, 0x74, 0x32, 0xf3, 0xf9, 0x3c, 0x9b, 0xb4, 0xa7, 0x71, 0xd8, 0xc9, 0x7f, 0x30, 0x79,
+	0x22, 0x2c, 0x47, 0xa3, 0x4e, 0xbe, 0xb6, 0x9d, 0x69, 0x10, 0x67, 0x5e, 0x6e, 0x94, 0xce, 0xda,
+	0x30, 0x93, 0xb2, 0xbc, 0x52, 0xcf, 0xff, 0x1b, 0x00, 0x8c, 0xe6, 0xd9, 0xdd, 0x83, 0x07, 0x00,
+	0x00,
 }
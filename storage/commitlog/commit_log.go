@@ -0,0 +1,97 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package commitlog provides a LogStorage implementation which uses an
+// append-only commit log as the source of truth for a log's leaves and signed
+// roots, and a regular LogStorage as a disposable serving database that is
+// built from the commit log.
+//
+// See docs/storage/commit_log/commit_log_based_storage_design.md for the
+// design.
+package commitlog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Log is a durable, ordered and immutable log of entries, split into named
+// topics. Entries in a topic are identified by their offset, which is assigned
+// when they're appended and starts at zero.
+// Implementations must be safe for concurrent use.
+type Log interface {
+	// Append adds data to the end of topic and returns the offset it was stored
+	// at. Concurrent appends may be interleaved, so callers which need an entry
+	// to be stored at a particular offset must check the returned value.
+	Append(ctx context.Context, topic string, data []byte) (int64, error)
+	// Read returns up to max entries of topic, starting at offset. Fewer
+	// entries, possibly none, are returned if the end of the topic is reached.
+	Read(ctx context.Context, topic string, offset int64, max int) ([][]byte, error)
+	// Size returns the number of entries in topic.
+	Size(ctx context.Context, topic string) (int64, error)
+	// Close releases any resources held by the Log.
+	Close() error
+}
+
+// memoryLog is an in-process Log implementation.
+type memoryLog struct {
+	mu     sync.RWMutex
+	topics map[string][][]byte
+}
+
+// NewMemoryLog creates a Log which keeps its entries in memory. It's intended
+// for tests and for sharing a commit log between storage instances in a
+// single process.
+func NewMemoryLog() Log {
+	return &memoryLog{topics: make(map[string][][]byte)}
+}
+
+func (m *memoryLog) Append(ctx context.Context, topic string, data []byte) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.topics[topic] = append(m.topics[topic], append([]byte(nil), data...))
+	return int64(len(m.topics[topic]) - 1), nil
+}
+
+func (m *memoryLog) Read(ctx context.Context, topic string, offset int64, max int) ([][]byte, error) {
+	if offset < 0 || max < 0 {
+		return nil, fmt.Errorf("invalid read of %d entries at offset %d", max, offset)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := m.topics[topic]
+	if offset >= int64(len(entries)) {
+		return nil, nil
+	}
+	end := offset + int64(max)
+	if end > int64(len(entries)) {
+		end = int64(len(entries))
+	}
+	ret := make([][]byte, 0, end-offset)
+	for _, e := range entries[offset:end] {
+		ret = append(ret, append([]byte(nil), e...))
+	}
+	return ret, nil
+}
+
+func (m *memoryLog) Size(ctx context.Context, topic string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.topics[topic])), nil
+}
+
+func (m *memoryLog) Close() error {
+	return nil
+}
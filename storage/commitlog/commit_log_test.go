@@ -0,0 +1,174 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTempFileLog(t *testing.T) (Log, string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "commitlog")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	l, err := NewFileLog(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("NewFileLog(): %v", err)
+	}
+	return l, dir
+}
+
+func TestLogImplementations(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		newLog func(t *testing.T) (Log, func())
+	}{
+		{
+			desc: "memory",
+			newLog: func(t *testing.T) (Log, func()) {
+				return NewMemoryLog(), func() {}
+			},
+		},
+		{
+			desc: "file",
+			newLog: func(t *testing.T) (Log, func()) {
+				l, dir := newTempFileLog(t)
+				return l, func() { os.RemoveAll(dir) }
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			l, cleanup := test.newLog(t)
+			defer cleanup()
+			defer l.Close()
+			testLog(t, l)
+		})
+	}
+}
+
+func testLog(t *testing.T, l Log) {
+	ctx := context.Background()
+
+	if size, err := l.Size(ctx, "a"); err != nil || size != 0 {
+		t.Errorf("Size(empty) = %v, %v, want 0, nil", size, err)
+	}
+	if got, err := l.Read(ctx, "a", 0, 10); err != nil || len(got) != 0 {
+		t.Errorf("Read(empty) = %v, %v, want no entries", got, err)
+	}
+
+	var want [][]byte
+	for i := 0; i < 5; i++ {
+		data := []byte(fmt.Sprintf("entry-%d", i))
+		offset, err := l.Append(ctx, "a", data)
+		if err != nil {
+			t.Fatalf("Append(%d): %v", i, err)
+		}
+		if got, want := offset, int64(i); got != want {
+			t.Errorf("Append(%d) = %v, want %v", i, got, want)
+		}
+		want = append(want, data)
+	}
+	// Topics are independent.
+	if offset, err := l.Append(ctx, "b", []byte{}); err != nil || offset != 0 {
+		t.Errorf("Append(b) = %v, %v, want 0, nil", offset, err)
+	}
+
+	if size, err := l.Size(ctx, "a"); err != nil || size != 5 {
+		t.Errorf("Size(a) = %v, %v, want 5, nil", size, err)
+	}
+
+	for _, test := range []struct {
+		offset int64
+		max    int
+		want   [][]byte
+	}{
+		{offset: 0, max: 5, want: want},
+		{offset: 0, max: 100, want: want},
+		{offset: 1, max: 2, want: want[1:3]},
+		{offset: 4, max: 2, want: want[4:]},
+		{offset: 5, max: 2},
+		{offset: 2, max: 0},
+	} {
+		got, err := l.Read(ctx, "a", test.offset, test.max)
+		if err != nil {
+			t.Errorf("Read(%d, %d): %v", test.offset, test.max, err)
+			continue
+		}
+		if len(got) != len(test.want) || (len(got) > 0 && !reflect.DeepEqual(got, test.want)) {
+			t.Errorf("Read(%d, %d) = %q, want %q", test.offset, test.max, got, test.want)
+		}
+	}
+
+	if _, err := l.Read(ctx, "a", -1, 1); err == nil {
+		t.Error("Read(-1) succeeded, want error")
+	}
+}
+
+func TestFileLogReopen(t *testing.T) {
+	ctx := context.Background()
+	l, dir := newTempFileLog(t)
+	defer os.RemoveAll(dir)
+
+	for _, data := range []string{"one", "two", "three"} {
+		if _, err := l.Append(ctx, "1/leaves", []byte(data)); err != nil {
+			t.Fatalf("Append(%q): %v", data, err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	// Simulate a crash part way through appending an entry.
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Glob() = %v, %v, want one file", files, err)
+	}
+	f, err := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile(): %v", err)
+	}
+	if _, err := f.Write([]byte{0, 0, 0, 10, 'x'}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	f.Close()
+
+	l, err = NewFileLog(dir)
+	if err != nil {
+		t.Fatalf("NewFileLog(): %v", err)
+	}
+	defer l.Close()
+	if size, err := l.Size(ctx, "1/leaves"); err != nil || size != 3 {
+		t.Fatalf("Size() = %v, %v, want 3, nil", size, err)
+	}
+	if offset, err := l.Append(ctx, "1/leaves", []byte("four")); err != nil || offset != 3 {
+		t.Fatalf("Append() = %v, %v, want 3, nil", offset, err)
+	}
+	got, err := l.Read(ctx, "1/leaves", 0, 10)
+	if err != nil {
+		t.Fatalf("Read(): %v", err)
+	}
+	want := [][]byte{[]byte("one"), []byte("two"), []byte("three"), []byte("four")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// maxEntrySize bounds the size of a single entry in a file-backed Log.
+const maxEntrySize = 64 << 20
+
+// fileLog is a Log implementation which stores each topic in a file of
+// length-prefixed entries.
+type fileLog struct {
+	dir string
+
+	mu     sync.Mutex
+	topics map[string]*fileTopic
+}
+
+// fileTopic is an open topic file, along with the position of each of its
+// entries.
+type fileTopic struct {
+	f         *os.File
+	positions []int64
+	end       int64
+}
+
+// NewFileLog creates a Log which stores its topics as files in dir, creating
+// the directory if needed. Entries are synced to disk before Append returns.
+//
+// The files are not locked, so at most one process may append to a directory
+// at a time.
+func NewFileLog(dir string) (Log, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &fileLog{dir: dir, topics: make(map[string]*fileTopic)}, nil
+}
+
+// topic returns the open file for the named topic, opening it and indexing
+// its entries on first use. It must be called with l.mu held.
+func (l *fileLog) topic(name string) (*fileTopic, error) {
+	if t, ok := l.topics[name]; ok {
+		return t, nil
+	}
+	path := filepath.Join(l.dir, url.PathEscape(name)+".log")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	t := &fileTopic{f: f}
+	if err := t.index(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to index %s: %v", path, err)
+	}
+	l.topics[name] = t
+	return t, nil
+}
+
+// index records the position of every entry in the topic file. A trailing
+// partial entry, left by a crash during Append, is truncated.
+func (t *fileTopic) index() error {
+	info, err := t.f.Stat()
+	if err != nil {
+		return err
+	}
+	var hdr [4]byte
+	for t.end < info.Size() {
+		if _, err := t.f.ReadAt(hdr[:], t.end); err != nil && err != io.EOF {
+			return err
+		}
+		next := t.end + int64(len(hdr)) + int64(binary.BigEndian.Uint32(hdr[:]))
+		if next > info.Size() {
+			break
+		}
+		t.positions = append(t.positions, t.end)
+		t.end = next
+	}
+	if t.end != info.Size() {
+		glog.Warningf("%s: truncating partial entry at %d", t.f.Name(), t.end)
+		return t.f.Truncate(t.end)
+	}
+	return nil
+}
+
+func (l *fileLog) Append(ctx context.Context, topic string, data []byte) (int64, error) {
+	if len(data) > maxEntrySize {
+		return 0, fmt.Errorf("entry of %d bytes exceeds maximum of %d", len(data), maxEntrySize)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, err := l.topic(topic)
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	if _, err := t.f.WriteAt(buf, t.end); err != nil {
+		// Don't leave a partial entry behind for the next Append to follow.
+		t.f.Truncate(t.end)
+		return 0, err
+	}
+	if err := t.f.Sync(); err != nil {
+		t.f.Truncate(t.end)
+		return 0, err
+	}
+	t.positions = append(t.positions, t.end)
+	t.end += int64(len(buf))
+	return int64(len(t.positions) - 1), nil
+}
+
+func (l *fileLog) Read(ctx context.Context, topic string, offset int64, max int) ([][]byte, error) {
+	if offset < 0 || max < 0 {
+		return nil, fmt.Errorf("invalid read of %d entries at offset %d", max, offset)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, err := l.topic(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret [][]byte
+	for i := offset; i < int64(len(t.positions)) && len(ret) < max; i++ {
+		end := t.end
+		if i+1 < int64(len(t.positions)) {
+			end = t.positions[i+1]
+		}
+		buf := make([]byte, end-t.positions[i])
+		if _, err := t.f.ReadAt(buf, t.positions[i]); err != nil {
+			return nil, err
+		}
+		ret = append(ret, buf[4:])
+	}
+	return ret, nil
+}
+
+func (l *fileLog) Size(ctx context.Context, topic string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, err := l.topic(topic)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(t.positions)), nil
+}
+
+func (l *fileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	for name, t := range l.topics {
+		if err := t.f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(l.topics, name)
+	}
+	return firstErr
+}
@@ -0,0 +1,733 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	logIDLabel = "logid"
+
+	// readBatchSize is the maximum number of entries read from the commit log
+	// at a time.
+	readBatchSize = 1000
+
+	// maxTreeDepth matches the depth of log trees used by the sequencer.
+	maxTreeDepth = 64
+)
+
+var (
+	once             sync.Once
+	queuedCounter    monitoring.Counter
+	dequeuedCounter  monitoring.Counter
+	replayedCounter  monitoring.Counter
+	skippedCounter   monitoring.Counter
+	rootsSizeGauge   monitoring.Gauge
+	servingSizeGauge monitoring.Gauge
+
+	// farFuture is used as the dequeue cutoff when copying leaves into the
+	// serving database, where everything queued must be dequeued.
+	farFuture = time.Unix(0, math.MaxInt64)
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	queuedCounter = mf.NewCounter("commitlog_queued_leaves", "Number of leaves appended to the commit log", logIDLabel)
+	dequeuedCounter = mf.NewCounter("commitlog_dequeued_leaves", "Number of leaves dequeued from the commit log", logIDLabel)
+	replayedCounter = mf.NewCounter("commitlog_replayed_roots", "Number of roots copied from the commit log into the serving database", logIDLabel)
+	skippedCounter = mf.NewCounter("commitlog_skipped_roots", "Number of roots ignored because they were not stored at their expected offset", logIDLabel)
+	rootsSizeGauge = mf.NewGauge("commitlog_roots", "Number of valid roots in the commit log", logIDLabel)
+	servingSizeGauge = mf.NewGauge("commitlog_serving_tree_size", "Size of the tree held by the serving database", logIDLabel)
+}
+
+func label(treeID int64) string {
+	return strconv.FormatInt(treeID, 10)
+}
+
+// leavesTopic is the commit log topic holding a tree's leaves. The offset of
+// each leaf in the topic is its position in the tree.
+func leavesTopic(treeID int64) string {
+	return fmt.Sprintf("%d/leaves", treeID)
+}
+
+// rootsTopic is the commit log topic holding a tree's signed roots.
+func rootsTopic(treeID int64) string {
+	return fmt.Sprintf("%d/roots", treeID)
+}
+
+// rootEntry is the format of the entries in a tree's roots topic.
+type rootEntry struct {
+	// ExpectedOffset is the offset the writer expected the entry to be stored
+	// at. Entries stored elsewhere lost a race with another writer and are
+	// ignored.
+	ExpectedOffset int64 `json:"off"`
+	// Root is a serialized trillian.SignedLogRoot.
+	Root []byte `json:"root"`
+}
+
+// rootIndex tracks the valid entries of a tree's roots topic.
+type rootIndex struct {
+	// next is the offset of the first entry which hasn't been scanned.
+	next int64
+	// offsets holds the offset of the root for each tree revision.
+	offsets []int64
+	// last is the most recent valid root.
+	last types.LogRootV1
+}
+
+// indexState is a point-in-time summary of a rootIndex.
+type indexState struct {
+	// roots is the number of valid roots, and so the revision of the next one.
+	roots int64
+	// next is the offset the next root is expected to be stored at.
+	next int64
+	// last is the most recent valid root. It's only set if roots > 0.
+	last types.LogRootV1
+}
+
+type commitLogStorage struct {
+	cl      Log
+	serving storage.LogStorage
+
+	mu      sync.Mutex
+	indexes map[int64]*rootIndex
+}
+
+// NewLogStorage creates a LogStorage which uses cl as the source of truth for
+// the leaves and signed roots of its trees, and serves them from serving.
+//
+// Leaves are sequenced by appending them to the commit log. Signed roots are
+// appended to the commit log before they're written to the serving database,
+// and only if the writer was up to date with the log, so concurrent writers
+// can't fork a tree. Before each transaction the serving database is brought
+// up to date by replaying any roots it's missing, which means that it can be
+// dropped and rebuilt from the commit log at any time.
+//
+// Trees must exist in the serving database, i.e. have been created by its
+// AdminStorage. PREORDERED_LOG trees aren't supported.
+func NewLogStorage(cl Log, serving storage.LogStorage, mf monitoring.MetricFactory) storage.LogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	once.Do(func() {
+		createMetrics(mf)
+	})
+	return &commitLogStorage{
+		cl:      cl,
+		serving: serving,
+		indexes: make(map[int64]*rootIndex),
+	}
+}
+
+func (s *commitLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return s.serving.CheckDatabaseAccessible(ctx)
+}
+
+func (s *commitLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	tx, err := s.serving.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyLogTX{ReadOnlyLogTX: tx, s: s}, nil
+}
+
+func (s *commitLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	// Reads can still be served if the commit log is unavailable, they just
+	// won't include the latest roots.
+	if err := s.catchUp(ctx, tree); err != nil {
+		glog.Warningf("%v: failed to update serving database from commit log: %v", tree.TreeId, err)
+	}
+	return s.serving.SnapshotForTree(ctx, tree)
+}
+
+func (s *commitLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	if err := s.catchUp(ctx, tree); err != nil {
+		return err
+	}
+	return s.serving.ReadWriteTransaction(ctx, tree, func(ctx context.Context, dbTX storage.LogTreeTX) error {
+		slr, err := dbTX.LatestSignedLogRoot(ctx)
+		if err != nil && err != storage.ErrTreeNeedsInit {
+			return err
+		}
+		rev, root, err := s.checkServingRoot(ctx, tree.TreeId, slr)
+		if err != nil {
+			return err
+		}
+		tx := &logTreeTX{
+			LogTreeTX: dbTX,
+			s:         s,
+			tree:      tree,
+			rev:       rev,
+			treeSize:  int64(root.TreeSize),
+		}
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return tx.flush(ctx)
+	})
+}
+
+func (s *commitLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	if err := s.appendLeaves(ctx, tree, leaves, queueTimestamp); err != nil {
+		return nil, err
+	}
+	// No deduping in this storage!
+	ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+	for i, leaf := range leaves {
+		ret[i] = &trillian.QueuedLogLeaf{Leaf: leaf}
+	}
+	return ret, nil
+}
+
+func (s *commitLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	return nil, status.Errorf(codes.Unimplemented, "AddSequencedLeaves is not implemented")
+}
+
+// appendLeaves adds leaves to the tree's leaves topic, which sequences them.
+// The append can't be undone, so leaves are queued even if the transaction
+// they're queued in is rolled back.
+func (s *commitLogStorage) appendLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) error {
+	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return err
+	}
+	// Don't accept batches if any of the leaves are invalid.
+	for _, leaf := range leaves {
+		if len(leaf.LeafIdentityHash) != hasher.Size() {
+			return fmt.Errorf("queued leaf must have a leaf ID hash of length %d", hasher.Size())
+		}
+	}
+	queueTimestampProto, err := ptypes.TimestampProto(queueTimestamp)
+	if err != nil {
+		return fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+
+	topic := leavesTopic(tree.TreeId)
+	for _, leaf := range leaves {
+		leaf := proto.Clone(leaf).(*trillian.LogLeaf)
+		leaf.QueueTimestamp = queueTimestampProto
+		data, err := proto.Marshal(leaf)
+		if err != nil {
+			return err
+		}
+		if _, err := s.cl.Append(ctx, topic, data); err != nil {
+			return err
+		}
+	}
+	queuedCounter.Add(float64(len(leaves)), label(tree.TreeId))
+	return nil
+}
+
+// readLeaves returns up to count leaves from the tree's leaves topic, starting
+// at the given index.
+func (s *commitLogStorage) readLeaves(ctx context.Context, treeID, start int64, count int) ([]*trillian.LogLeaf, error) {
+	leaves := make([]*trillian.LogLeaf, 0, count)
+	for len(leaves) < count {
+		n := count - len(leaves)
+		if n > readBatchSize {
+			n = readBatchSize
+		}
+		entries, err := s.cl.Read(ctx, leavesTopic(treeID), start+int64(len(leaves)), n)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			var leaf trillian.LogLeaf
+			if err := proto.Unmarshal(e, &leaf); err != nil {
+				return nil, fmt.Errorf("failed to parse leaf %d: %v", start+int64(len(leaves)), err)
+			}
+			leaf.LeafIndex = start + int64(len(leaves))
+			leaves = append(leaves, &leaf)
+		}
+		if len(entries) < n {
+			break
+		}
+	}
+	return leaves, nil
+}
+
+// refresh scans any new entries in the tree's roots topic, and returns the
+// current state of its index.
+func (s *commitLogStorage) refresh(ctx context.Context, treeID int64) (indexState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, ok := s.indexes[treeID]
+	if !ok {
+		idx = &rootIndex{}
+		s.indexes[treeID] = idx
+	}
+
+	for {
+		entries, err := s.cl.Read(ctx, rootsTopic(treeID), idx.next, readBatchSize)
+		if err != nil {
+			return indexState{}, err
+		}
+		for _, e := range entries {
+			if err := idx.add(treeID, e); err != nil {
+				return indexState{}, err
+			}
+		}
+		if len(entries) < readBatchSize {
+			break
+		}
+	}
+	rootsSizeGauge.Set(float64(len(idx.offsets)), label(treeID))
+	return indexState{roots: int64(len(idx.offsets)), next: idx.next, last: idx.last}, nil
+}
+
+// add indexes data, the entry stored at offset idx.next of the roots topic.
+// Entries which weren't stored at their expected offset are skipped.
+func (idx *rootIndex) add(treeID int64, data []byte) error {
+	offset := idx.next
+	var e rootEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return fmt.Errorf("%v: failed to parse roots entry %d: %v", treeID, offset, err)
+	}
+	if e.ExpectedOffset != offset {
+		glog.Warningf("%v: skipping root at offset %d which was written for offset %d", treeID, offset, e.ExpectedOffset)
+		skippedCounter.Inc(label(treeID))
+		idx.next++
+		return nil
+	}
+	_, root, err := parseRoot(e.Root)
+	if err != nil {
+		return fmt.Errorf("%v: failed to parse root at offset %d: %v", treeID, offset, err)
+	}
+	if got, want := root.Revision, uint64(len(idx.offsets)); got != want {
+		return fmt.Errorf("%v: root at offset %d has revision %d, want %d", treeID, offset, got, want)
+	}
+	if len(idx.offsets) > 0 && (root.TimestampNanos < idx.last.TimestampNanos || root.TreeSize < idx.last.TreeSize) {
+		return fmt.Errorf("%v: root at offset %d is older than its predecessor", treeID, offset)
+	}
+	idx.offsets = append(idx.offsets, offset)
+	idx.last = root
+	idx.next++
+	return nil
+}
+
+// readRoot returns the root of the tree with the given revision from the
+// commit log. The revision must have been indexed by refresh.
+func (s *commitLogStorage) readRoot(ctx context.Context, treeID, revision int64) (trillian.SignedLogRoot, types.LogRootV1, error) {
+	s.mu.Lock()
+	offset := s.indexes[treeID].offsets[revision]
+	s.mu.Unlock()
+
+	entries, err := s.cl.Read(ctx, rootsTopic(treeID), offset, 1)
+	if err != nil {
+		return trillian.SignedLogRoot{}, types.LogRootV1{}, err
+	}
+	if len(entries) != 1 {
+		return trillian.SignedLogRoot{}, types.LogRootV1{}, fmt.Errorf("%v: root at offset %d missing from commit log", treeID, offset)
+	}
+	var e rootEntry
+	if err := json.Unmarshal(entries[0], &e); err != nil {
+		return trillian.SignedLogRoot{}, types.LogRootV1{}, err
+	}
+	return parseRoot(e.Root)
+}
+
+func parseRoot(data []byte) (trillian.SignedLogRoot, types.LogRootV1, error) {
+	var slr trillian.SignedLogRoot
+	var root types.LogRootV1
+	if err := proto.Unmarshal(data, &slr); err != nil {
+		return trillian.SignedLogRoot{}, types.LogRootV1{}, err
+	}
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return trillian.SignedLogRoot{}, types.LogRootV1{}, err
+	}
+	return slr, root, nil
+}
+
+// checkServingRoot verifies that slr, the latest root in the serving database,
+// is the root with the same revision in the commit log. It returns the
+// revision of slr, which is -1 if the serving database has no root yet.
+func (s *commitLogStorage) checkServingRoot(ctx context.Context, treeID int64, slr trillian.SignedLogRoot) (int64, types.LogRootV1, error) {
+	state, err := s.refresh(ctx, treeID)
+	if err != nil {
+		return 0, types.LogRootV1{}, err
+	}
+	if slr.LogRoot == nil {
+		return -1, types.LogRootV1{}, nil
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return 0, types.LogRootV1{}, err
+	}
+	rev := int64(root.Revision)
+	if rev >= state.roots {
+		return 0, types.LogRootV1{}, fmt.Errorf("%v: serving database has revision %d, ahead of the commit log", treeID, rev)
+	}
+	logSLR, _, err := s.readRoot(ctx, treeID, rev)
+	if err != nil {
+		return 0, types.LogRootV1{}, err
+	}
+	if !bytes.Equal(logSLR.LogRoot, slr.LogRoot) {
+		return 0, types.LogRootV1{}, fmt.Errorf("%v: serving database has a different root than the commit log at revision %d", treeID, rev)
+	}
+	servingSizeGauge.Set(float64(root.TreeSize), label(treeID))
+	return rev, root, nil
+}
+
+// catchUp brings the serving database up to date with the tree's roots topic.
+func (s *commitLogStorage) catchUp(ctx context.Context, tree *trillian.Tree) error {
+	for {
+		behind, err := s.servingBehind(ctx, tree)
+		if err != nil || !behind {
+			return err
+		}
+		if err := s.serving.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			return s.replayNext(ctx, tree, tx)
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// servingBehind returns whether the commit log has roots which aren't in the
+// serving database yet.
+func (s *commitLogStorage) servingBehind(ctx context.Context, tree *trillian.Tree) (bool, error) {
+	state, err := s.refresh(ctx, tree.TreeId)
+	if err != nil {
+		return false, err
+	}
+	if state.roots == 0 {
+		return false, nil
+	}
+
+	tx, err := s.serving.SnapshotForTree(ctx, tree)
+	if err == storage.ErrTreeNeedsInit {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	if slr.LogRoot == nil {
+		return true, nil
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return false, err
+	}
+	return root.Revision < state.last.Revision, nil
+}
+
+// replayNext copies the root following the latest one in the serving
+// database from the commit log, along with the leaves it covers. The Merkle
+// tree is recomputed from the leaves, and must match the root.
+func (s *commitLogStorage) replayNext(ctx context.Context, tree *trillian.Tree, tx storage.LogTreeTX) error {
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	rev, current, err := s.checkServingRoot(ctx, tree.TreeId, slr)
+	if err != nil {
+		return err
+	}
+	state, err := s.refresh(ctx, tree.TreeId)
+	if err != nil {
+		return err
+	}
+	if rev+1 >= state.roots {
+		// Someone else caught up first.
+		return nil
+	}
+	nextSLR, next, err := s.readRoot(ctx, tree.TreeId, rev+1)
+	if err != nil {
+		return err
+	}
+
+	count := int64(next.TreeSize) - int64(current.TreeSize)
+	leaves, err := s.readLeaves(ctx, tree.TreeId, int64(current.TreeSize), int(count))
+	if err != nil {
+		return err
+	}
+	if int64(len(leaves)) != count {
+		return fmt.Errorf("%v: commit log has %d leaves from index %d, root at revision %d needs %d", tree.TreeId, len(leaves), current.TreeSize, next.Revision, count)
+	}
+	integrateTimestamp, err := ptypes.TimestampProto(time.Unix(0, int64(next.TimestampNanos)))
+	if err != nil {
+		return err
+	}
+	for _, leaf := range leaves {
+		leaf.IntegrateTimestamp = integrateTimestamp
+	}
+
+	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return err
+	}
+	mt, err := compactTreeAtRoot(ctx, hasher, &current, tx)
+	if err != nil {
+		return err
+	}
+	nodes, err := addLeaves(mt, leaves)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(mt.CurrentRoot(), next.RootHash) {
+		return fmt.Errorf("%v: computed root hash %x at revision %d, commit log has %x", tree.TreeId, mt.CurrentRoot(), next.Revision, next.RootHash)
+	}
+
+	if len(leaves) > 0 {
+		if got, want := tx.WriteRevision(), int64(next.Revision); got != want {
+			return fmt.Errorf("%v: got writeRevision of %v, but expected %v", tree.TreeId, got, want)
+		}
+		if err := storeLeaves(ctx, tx, leaves); err != nil {
+			return err
+		}
+		for i := range nodes {
+			nodes[i].NodeRevision = int64(next.Revision)
+		}
+		if err := tx.SetMerkleNodes(ctx, nodes); err != nil {
+			return err
+		}
+	}
+	if err := tx.StoreSignedLogRoot(ctx, nextSLR); err != nil {
+		return err
+	}
+	replayedCounter.Inc(label(tree.TreeId))
+	glog.V(1).Infof("%v: replayed root at revision %d, size %d", tree.TreeId, next.Revision, next.TreeSize)
+	return nil
+}
+
+// compactTreeAtRoot loads the compact Merkle tree for root from tx.
+func compactTreeAtRoot(ctx context.Context, hasher hashers.LogHasher, root *types.LogRootV1, tx storage.TreeTX) (*merkle.CompactMerkleTree, error) {
+	if root.TreeSize == 0 {
+		return merkle.NewCompactMerkleTree(hasher), nil
+	}
+	return merkle.NewCompactMerkleTreeWithState(hasher, int64(root.TreeSize), func(depth int, index int64) ([]byte, error) {
+		nodeID, err := storage.NewNodeIDForTreeCoords(int64(depth), index, maxTreeDepth)
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := tx.GetMerkleNodes(ctx, int64(root.Revision), []storage.NodeID{nodeID})
+		if err != nil {
+			return nil, err
+		}
+		if len(nodes) != 1 {
+			return nil, fmt.Errorf("did not retrieve one node while loading CompactMerkleTree, got %#v for ID %v@%v", nodes, nodeID.String(), root.Revision)
+		}
+		return nodes[0].Hash, nil
+	}, root.RootHash)
+}
+
+// addLeaves adds leaves to mt, and returns the Merkle tree nodes which were
+// created or updated.
+func addLeaves(mt *merkle.CompactMerkleTree, leaves []*trillian.LogLeaf) ([]storage.Node, error) {
+	nodeMap := make(map[string]storage.Node)
+	for _, leaf := range leaves {
+		seq, err := mt.AddLeafHash(leaf.MerkleLeafHash, func(depth int, index int64, hash []byte) error {
+			nodeID, err := storage.NewNodeIDForTreeCoords(int64(depth), index, maxTreeDepth)
+			if err != nil {
+				return err
+			}
+			nodeMap[nodeID.String()] = storage.Node{NodeID: nodeID, Hash: hash}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if leaf.LeafIndex != seq {
+			return nil, fmt.Errorf("got invalid leaf index: %v, want: %v", leaf.LeafIndex, seq)
+		}
+		leafNodeID, err := storage.NewNodeIDForTreeCoords(0, seq, maxTreeDepth)
+		if err != nil {
+			return nil, err
+		}
+		nodeMap[leafNodeID.String()] = storage.Node{NodeID: leafNodeID, Hash: leaf.MerkleLeafHash}
+	}
+
+	nodes := make([]storage.Node, 0, len(nodeMap))
+	for _, node := range nodeMap {
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// storeLeaves writes sequenced leaves to the serving database. It goes via
+// the database's queue so that any LogStorage can be used for serving.
+func storeLeaves(ctx context.Context, tx storage.LogTreeTX, leaves []*trillian.LogLeaf) error {
+	for _, leaf := range leaves {
+		queueTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+		if err != nil {
+			return fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		if _, err := tx.QueueLeaves(ctx, []*trillian.LogLeaf{leaf}, queueTimestamp); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.DequeueLeaves(ctx, len(leaves), farFuture); err != nil {
+		return err
+	}
+	return tx.UpdateSequencedLeaves(ctx, leaves)
+}
+
+// logTreeTX is a LogTreeTX which sequences leaves using the commit log, and
+// writes everything else to the serving database. Its root is buffered and
+// only written, to the commit log and then to the serving database, once the
+// transaction's function has succeeded.
+type logTreeTX struct {
+	storage.LogTreeTX
+	s    *commitLogStorage
+	tree *trillian.Tree
+	// rev and treeSize describe the serving database's root at the start of
+	// the transaction. rev is -1 if there's no root.
+	rev      int64
+	treeSize int64
+	root     *trillian.SignedLogRoot
+}
+
+func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	if err := t.s.appendLeaves(ctx, t.tree, leaves, queueTimestamp); err != nil {
+		return nil, err
+	}
+	return make([]*trillian.LogLeaf, len(leaves)), nil
+}
+
+// DequeueLeaves returns leaves from the commit log which follow the current
+// tree, stopping at the first one queued after cutoffTime.
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.s.readLeaves(ctx, t.tree.TreeId, t.treeSize, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, leaf := range leaves {
+		queueTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		if queueTimestamp.After(cutoffTime) {
+			leaves = leaves[:i]
+			break
+		}
+	}
+	dequeuedCounter.Add(float64(len(leaves)), label(t.tree.TreeId))
+	return leaves, nil
+}
+
+func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	return nil, status.Errorf(codes.Unimplemented, "AddSequencedLeaves is not implemented")
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	// The commit log has already sequenced the leaves, so they must keep the
+	// positions it gave them.
+	for i, leaf := range leaves {
+		if got, want := leaf.LeafIndex, t.treeSize+int64(i); got != want {
+			return fmt.Errorf("leaf has index %d, but it's at %d in the commit log", got, want)
+		}
+	}
+	return storeLeaves(ctx, t.LogTreeTX, leaves)
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root trillian.SignedLogRoot) error {
+	t.root = &root
+	return nil
+}
+
+// flush writes the buffered root, if any, to the commit log and then to the
+// serving database. The root is only appended if the serving database is up
+// to date with the commit log, and it's only valid if nobody else appended a
+// root concurrently.
+func (t *logTreeTX) flush(ctx context.Context) error {
+	if t.root == nil {
+		return nil
+	}
+	treeID := t.tree.TreeId
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(t.root.LogRoot); err != nil {
+		return err
+	}
+	state, err := t.s.refresh(ctx, treeID)
+	if err != nil {
+		return err
+	}
+	if state.roots != t.rev+1 {
+		return fmt.Errorf("%v: commit log has moved on to revision %d, not storing root for revision %d", treeID, state.roots-1, root.Revision)
+	}
+	if got, want := root.Revision, uint64(state.roots); got != want {
+		return fmt.Errorf("%v: root has revision %d, want %d", treeID, got, want)
+	}
+
+	data, err := proto.Marshal(t.root)
+	if err != nil {
+		return err
+	}
+	entry, err := json.Marshal(rootEntry{ExpectedOffset: state.next, Root: data})
+	if err != nil {
+		return err
+	}
+	offset, err := t.s.cl.Append(ctx, rootsTopic(treeID), entry)
+	if err != nil {
+		return err
+	}
+	if offset != state.next {
+		return fmt.Errorf("%v: root stored at offset %d rather than %d, another writer is active", treeID, offset, state.next)
+	}
+	return t.LogTreeTX.StoreSignedLogRoot(ctx, *t.root)
+}
+
+// readOnlyLogTX counts unsequenced leaves using the commit log, and delegates
+// everything else to the serving database.
+type readOnlyLogTX struct {
+	storage.ReadOnlyLogTX
+	s *commitLogStorage
+}
+
+func (t *readOnlyLogTX) GetUnsequencedCounts(ctx context.Context) (storage.CountByLogID, error) {
+	ids, err := t.GetActiveLogIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(storage.CountByLogID)
+	for _, id := range ids {
+		size, err := t.s.cl.Size(ctx, leavesTopic(id))
+		if err != nil {
+			return nil, err
+		}
+		state, err := t.s.refresh(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		ret[id] = size - int64(state.last.TreeSize)
+	}
+	return ret, nil
+}
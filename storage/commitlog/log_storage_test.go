@@ -0,0 +1,333 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util"
+
+	tcrypto "github.com/google/trillian/crypto"
+	ttestonly "github.com/google/trillian/testonly"
+
+	_ "github.com/google/trillian/merkle/rfc6962" // Register the log hasher
+)
+
+// replica is a commit log storage instance with its own serving database.
+type replica struct {
+	ls        storage.LogStorage
+	tree      *trillian.Tree
+	sequencer *log.Sequencer
+}
+
+// newReplica creates a replica serving tree from cl. If tree is nil a new tree
+// is created, otherwise the serving database is created empty with the same
+// tree in it.
+func newReplica(ctx context.Context, t *testing.T, cl Log, tree *trillian.Tree) *replica {
+	t.Helper()
+	serving := memory.NewLogStorage(nil)
+	if tree == nil {
+		tree = testonly.LogTree
+	}
+	tree, err := storage.CreateTree(ctx, memory.NewAdminStorage(serving), tree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewLogHasher(): %v", err)
+	}
+	ls := NewLogStorage(cl, serving, nil)
+	signer := tcrypto.NewSigner(tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+	return &replica{
+		ls:        ls,
+		tree:      tree,
+		sequencer: log.NewSequencer(hasher, util.SystemTimeSource{}, ls, signer, nil, quota.Noop()),
+	}
+}
+
+// initLog stores the first, empty, root of the tree.
+func (r *replica) initLog(ctx context.Context, t *testing.T) {
+	t.Helper()
+	hasher, err := hashers.NewLogHasher(r.tree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewLogHasher(): %v", err)
+	}
+	signer := tcrypto.NewSigner(r.tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+	root, err := signer.SignLogRoot(&types.LogRootV1{
+		RootHash:       hasher.EmptyRoot(),
+		TimestampNanos: uint64(time.Now().UnixNano()),
+	})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	if err := r.ls.ReadWriteTransaction(ctx, r.tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, *root)
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(StoreSignedLogRoot): %v", err)
+	}
+}
+
+// queue adds count leaves to the tree, named after their position in the
+// batch starting at first.
+func (r *replica) queue(ctx context.Context, t *testing.T, first, count int) {
+	t.Helper()
+	hasher, err := hashers.NewLogHasher(r.tree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewLogHasher(): %v", err)
+	}
+	leaves := make([]*trillian.LogLeaf, 0, count)
+	for i := first; i < first+count; i++ {
+		value := []byte(fmt.Sprintf("leaf-%d", i))
+		merkleHash, err := hasher.HashLeaf(value)
+		if err != nil {
+			t.Fatalf("HashLeaf(): %v", err)
+		}
+		identityHash := sha256.Sum256(value)
+		leaves = append(leaves, &trillian.LogLeaf{
+			LeafValue:        value,
+			LeafIdentityHash: identityHash[:],
+			MerkleLeafHash:   merkleHash,
+		})
+	}
+	if _, err := r.ls.QueueLeaves(ctx, r.tree, leaves, time.Now()); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+}
+
+// integrate sequences up to limit leaves and returns the number sequenced.
+func (r *replica) integrate(ctx context.Context, t *testing.T, limit int) int {
+	t.Helper()
+	n, err := r.sequencer.IntegrateBatch(ctx, r.tree, limit, 0, 0)
+	if err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	return n
+}
+
+// latestRoot returns the tree's latest root and its leaves.
+func (r *replica) latestRoot(ctx context.Context, t *testing.T) (trillian.SignedLogRoot, []*trillian.LogLeaf) {
+	t.Helper()
+	tx, err := r.ls.SnapshotForTree(ctx, r.tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	leaves, err := tx.GetLeavesByRange(ctx, 0, int64(root.TreeSize))
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit(): %v", err)
+	}
+	return slr, leaves
+}
+
+func checkLeaves(t *testing.T, leaves []*trillian.LogLeaf, want int) {
+	t.Helper()
+	if len(leaves) != want {
+		t.Fatalf("got %d leaves, want %d", len(leaves), want)
+	}
+	for i, leaf := range leaves {
+		if got, want := string(leaf.LeafValue), fmt.Sprintf("leaf-%d", i); leaf.LeafIndex != int64(i) || got != want {
+			t.Errorf("leaves[%d] = %q at index %d, want %q", i, got, leaf.LeafIndex, want)
+		}
+	}
+}
+
+func TestLogStorage_QueueAndIntegrate(t *testing.T) {
+	ctx := context.Background()
+	cl := NewMemoryLog()
+	r := newReplica(ctx, t, cl, nil)
+	r.initLog(ctx, t)
+
+	r.queue(ctx, t, 0, 10)
+	if size, err := cl.Size(ctx, leavesTopic(r.tree.TreeId)); err != nil || size != 10 {
+		t.Errorf("leaves topic Size() = %v, %v, want 10, nil", size, err)
+	}
+	checkUnsequenced(ctx, t, r, 10)
+
+	if got, want := r.integrate(ctx, t, 4), 4; got != want {
+		t.Errorf("IntegrateBatch() = %v, want %v", got, want)
+	}
+	if got, want := r.integrate(ctx, t, 100), 6; got != want {
+		t.Errorf("IntegrateBatch() = %v, want %v", got, want)
+	}
+	checkUnsequenced(ctx, t, r, 0)
+
+	_, leaves := r.latestRoot(ctx, t)
+	checkLeaves(t, leaves, 10)
+
+	// Roots for revisions 0, 1 and 2 are in the commit log.
+	if size, err := cl.Size(ctx, rootsTopic(r.tree.TreeId)); err != nil || size != 3 {
+		t.Errorf("roots topic Size() = %v, %v, want 3, nil", size, err)
+	}
+}
+
+func checkUnsequenced(ctx context.Context, t *testing.T, r *replica, want int64) {
+	t.Helper()
+	tx, err := r.ls.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot(): %v", err)
+	}
+	defer tx.Close()
+	counts, err := tx.GetUnsequencedCounts(ctx)
+	if err != nil {
+		t.Fatalf("GetUnsequencedCounts(): %v", err)
+	}
+	if got := counts[r.tree.TreeId]; got != want {
+		t.Errorf("GetUnsequencedCounts()[%v] = %v, want %v", r.tree.TreeId, got, want)
+	}
+}
+
+func TestLogStorage_RebuildServingDatabase(t *testing.T) {
+	ctx := context.Background()
+	cl := NewMemoryLog()
+	r1 := newReplica(ctx, t, cl, nil)
+	r1.initLog(ctx, t)
+	for i := 0; i < 3; i++ {
+		r1.queue(ctx, t, i*7, 7)
+		r1.integrate(ctx, t, 100)
+	}
+	wantRoot, _ := r1.latestRoot(ctx, t)
+
+	// A new replica with an empty serving database rebuilds it from the commit
+	// log, including the Merkle tree.
+	r2 := newReplica(ctx, t, cl, r1.tree)
+	gotRoot, leaves := r2.latestRoot(ctx, t)
+	if !proto.Equal(&gotRoot, &wantRoot) {
+		t.Errorf("rebuilt replica has root %v, want %v", gotRoot, wantRoot)
+	}
+	checkLeaves(t, leaves, 21)
+
+	// The rebuilt replica can carry on sequencing, and the original catches up.
+	r2.queue(ctx, t, 21, 5)
+	if got, want := r2.integrate(ctx, t, 100), 5; got != want {
+		t.Errorf("IntegrateBatch() = %v, want %v", got, want)
+	}
+	wantRoot, _ = r2.latestRoot(ctx, t)
+	gotRoot, leaves = r1.latestRoot(ctx, t)
+	if !proto.Equal(&gotRoot, &wantRoot) {
+		t.Errorf("original replica has root %v, want %v", gotRoot, wantRoot)
+	}
+	checkLeaves(t, leaves, 26)
+}
+
+func TestLogStorage_ConcurrentWriters(t *testing.T) {
+	ctx := context.Background()
+	cl := NewMemoryLog()
+	r1 := newReplica(ctx, t, cl, nil)
+	r1.initLog(ctx, t)
+	r2 := newReplica(ctx, t, cl, r1.tree)
+	r1.queue(ctx, t, 0, 5)
+
+	// r2 sequences while r1 is part way through doing the same, so r1's root
+	// must not be written.
+	r1.integrate(ctx, t, 100)
+	r1.queue(ctx, t, 5, 5)
+	err := r1.ls.ReadWriteTransaction(ctx, r1.tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if got, want := r2.integrate(ctx, t, 100), 5; got != want {
+			t.Errorf("IntegrateBatch() = %v, want %v", got, want)
+		}
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return err
+		}
+		root.Revision++
+		root.TimestampNanos++
+		signer := tcrypto.NewSigner(r1.tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+		newRoot, err := signer.SignLogRoot(&root)
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, *newRoot)
+	})
+	if err == nil || !strings.Contains(err.Error(), "moved on") {
+		t.Errorf("ReadWriteTransaction() = %v, want error about the commit log moving on", err)
+	}
+
+	// A root appended at an unexpected offset, e.g. by a writer which lost a
+	// race, is ignored.
+	rogue, err := json.Marshal(rootEntry{ExpectedOffset: 1, Root: []byte("rogue")})
+	if err != nil {
+		t.Fatalf("Marshal(): %v", err)
+	}
+	if _, err := cl.Append(ctx, rootsTopic(r1.tree.TreeId), rogue); err != nil {
+		t.Fatalf("Append(): %v", err)
+	}
+
+	// Both replicas agree on the tree, and can continue sequencing.
+	if got, want := r1.integrate(ctx, t, 100), 0; got != want {
+		t.Errorf("IntegrateBatch() = %v, want %v", got, want)
+	}
+	r1Root, leaves := r1.latestRoot(ctx, t)
+	r2Root, _ := r2.latestRoot(ctx, t)
+	if !bytes.Equal(r1Root.LogRoot, r2Root.LogRoot) {
+		t.Errorf("replicas have different roots: %v and %v", r1Root, r2Root)
+	}
+	checkLeaves(t, leaves, 10)
+
+	r1.queue(ctx, t, 10, 1)
+	if got, want := r1.integrate(ctx, t, 100), 1; got != want {
+		t.Errorf("IntegrateBatch() = %v, want %v", got, want)
+	}
+	_, leaves = r2.latestRoot(ctx, t)
+	checkLeaves(t, leaves, 11)
+}
+
+func TestLogStorage_ServingDatabaseAhead(t *testing.T) {
+	ctx := context.Background()
+	r1 := newReplica(ctx, t, NewMemoryLog(), nil)
+	r1.initLog(ctx, t)
+
+	// Pointing a serving database at a different commit log is detected.
+	r2 := &replica{ls: NewLogStorage(NewMemoryLog(), r1.ls.(*commitLogStorage).serving, nil), tree: r1.tree}
+	r2.queue(ctx, t, 0, 1)
+	err := r2.ls.ReadWriteTransaction(ctx, r2.tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "ahead of the commit log") {
+		t.Errorf("ReadWriteTransaction() = %v, want error about the serving database", err)
+	}
+}
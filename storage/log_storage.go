@@ -59,7 +59,11 @@ type ReadOnlyLogTreeTX interface {
 	// tree permits duplicate leaves callers must be prepared to handle multiple results with the
 	// same hash but different sequence numbers. If orderBySequence is true then the returned data
 	// will be in ascending sequence number order.
-	GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error)
+	// Only leaves with a sequence number of at least start are returned. If limit is greater than
+	// zero at most limit leaves are returned, being those with the lowest sequence numbers, in
+	// ascending sequence number order whatever the value of orderBySequence. This allows callers
+	// to page through the leaves for hashes with many duplicates.
+	GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool, start, limit int64) ([]*trillian.LogLeaf, error)
 	// LatestSignedLogRoot returns the most recent SignedLogRoot, if any.
 	LatestSignedLogRoot(ctx context.Context) (trillian.SignedLogRoot, error)
 }
@@ -98,6 +102,15 @@ type LogTreeTX interface {
 	UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error
 }
 
+// QueuePeeker is an optional interface of LogTreeTX implementations which can
+// read the queue without dequeuing from it. The sequencer uses it to start
+// hashing the next batch of a log before the current batch is committed.
+type QueuePeeker interface {
+	// PeekQueuedLeaves returns the leaves that DequeueLeaves would return if
+	// it were called now, without removing them from the queue.
+	PeekQueuedLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error)
+}
+
 // ReadOnlyLogStorage represents a narrowed read-only view into a LogStorage.
 type ReadOnlyLogStorage interface {
 	DatabaseChecker
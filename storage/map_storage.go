@@ -16,10 +16,25 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ErrMapRevisionExists is returned when storing a SignedMapRoot for a revision
+// which already has one, e.g. because a concurrent transaction advanced the map
+// first.
+var ErrMapRevisionExists = status.Error(codes.FailedPrecondition, "map revision already exists")
+
+// MapLeafRevision is a value written to a map leaf, along with the revision of
+// the map at which it was written.
+type MapLeafRevision struct {
+	Revision int64
+	Leaf     trillian.MapLeaf
+}
+
 // ReadOnlyMapTX provides a read-only view into log data.
 // A ReadOnlyMapTX, unlike ReadOnlyMapTreeTX, is not tied to a particular tree.
 type ReadOnlyMapTX interface {
@@ -52,6 +67,16 @@ type ReadOnlyMapTreeTX interface {
 	// exist.  i.e. requesting a set of unknown keys would result in a
 	// zero-length array being returned.
 	Get(ctx context.Context, revision int64, keyHashes [][]byte) ([]trillian.MapLeaf, error)
+	// GetLeavesAfter returns up to limit of the non-empty leaves at the
+	// specified revision whose key hashes sort strictly after the given key
+	// hash, in ascending key hash order.
+	// Passing an empty keyHash starts from the first leaf in the map.
+	GetLeavesAfter(ctx context.Context, revision int64, keyHash []byte, limit int) ([]trillian.MapLeaf, error)
+	// GetLeafHistory returns the values written to the leaf with the given key
+	// hash at revisions between fromRevision and toRevision inclusive, in
+	// ascending revision order.
+	// Each MapLeaf.Index is set to keyHash.
+	GetLeafHistory(ctx context.Context, keyHash []byte, fromRevision, toRevision int64) ([]MapLeafRevision, error)
 }
 
 // MapTreeTX is the transactional interface for reading/modifying a Map.
@@ -63,7 +88,9 @@ type MapTreeTX interface {
 	ReadOnlyMapTreeTX
 	TreeWriter
 
-	// StoreSignedMapRoot stores root.
+	// StoreSignedMapRoot stores root. It fails with ErrMapRevisionExists,
+	// possibly only when the transaction commits, if there is already a root
+	// for the same revision.
 	StoreSignedMapRoot(ctx context.Context, root trillian.SignedMapRoot) error
 	// Set sets key to leaf
 	Set(ctx context.Context, keyHash []byte, value trillian.MapLeaf) error
@@ -83,6 +110,33 @@ type ReadOnlyMapStorage interface {
 	SnapshotForTree(ctx context.Context, tree *trillian.Tree) (ReadOnlyMapTreeTX, error)
 }
 
+// MapRevisionPruner is implemented by MapStorage implementations which can
+// garbage collect the data of superseded map revisions.
+type MapRevisionPruner interface {
+	// PruneMapRevisions deletes the roots of the revisions of the map before
+	// revision, along with the leaf and subtree data only needed to read them.
+	// Reads at revision, or any later revision, are unaffected.
+	PruneMapRevisions(ctx context.Context, tree *trillian.Tree, revision int64) error
+}
+
+// MapLeafQueuer is implemented by MapStorage implementations which can queue
+// map leaves to be written to a later revision of the map, as a batch. The
+// MapTreeTXs of such a MapStorage implement MapQueueTX.
+type MapLeafQueuer interface {
+	// QueueMapLeaves adds leaves to the queue of the map at queueTimestamp.
+	// Queuing a leaf for an index which is already queued at the same
+	// timestamp replaces it.
+	QueueMapLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error
+}
+
+// MapQueueTX is implemented by the MapTreeTXs of MapLeafQueuers.
+type MapQueueTX interface {
+	// DequeueMapLeaves removes up to limit of the leaves queued at or before
+	// cutoffTime from the queue of the map, and returns them in the order they
+	// were queued. The leaves are only removed if the transaction commits.
+	DequeueMapLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.MapLeaf, error)
+}
+
 // MapTXFunc is the func signature for passing into ReadWriteTransaction.
 type MapTXFunc func(context.Context, MapTreeTX) error
 
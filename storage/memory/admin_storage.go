@@ -21,9 +21,12 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // NewAdminStorage returns a storage.AdminStorage implementation backed by
@@ -111,7 +114,7 @@ func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, er
 	if tree == nil {
 		return nil, fmt.Errorf("no such treeID %d", treeID)
 	}
-	return tree.meta, nil
+	return proto.Clone(tree.meta).(*trillian.Tree), nil
 }
 
 func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
@@ -125,15 +128,15 @@ func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64
 	return ret, nil
 }
 
-func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+func (t *adminTX) ListTrees(ctx context.Context, opts storage.ListTreesOptions) ([]*trillian.Tree, error) {
 	t.ms.mu.RLock()
 	defer t.ms.mu.RUnlock()
 
 	var ret []*trillian.Tree
 	for _, v := range t.ms.trees {
-		ret = append(ret, v.meta)
+		ret = append(ret, proto.Clone(v.meta).(*trillian.Tree))
 	}
-	return ret, nil
+	return storage.FilterTrees(ret, opts), nil
 }
 
 func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.Tree, error) {
@@ -144,9 +147,14 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 		return nil, err
 	}
 
-	id, err := storage.NewTreeID()
-	if err != nil {
-		return nil, err
+	// Callers holding a tree's data elsewhere (e.g. a replica being rebuilt
+	// from a commit log) may ask for the tree to be created with its ID.
+	var err error
+	id := tr.TreeId
+	if id == 0 {
+		if id, err = storage.NewTreeID(); err != nil {
+			return nil, err
+		}
 	}
 
 	now := time.Now()
@@ -164,6 +172,9 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 
 	t.ms.mu.Lock()
 	defer t.ms.mu.Unlock()
+	if _, ok := t.ms.trees[id]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+	}
 	t.ms.trees[id] = newTree(meta)
 
 	glog.V(1).Infof("trees: %v", t.ms.trees)
@@ -176,10 +187,11 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	mTree.mu.Lock()
 	defer mTree.mu.Unlock()
 
-	tree := mTree.meta
-	beforeUpdate := *tree
+	// Update a copy of the tree, so that it's left untouched if the update is
+	// invalid, and callers can't modify the stored tree.
+	tree := proto.Clone(mTree.meta).(*trillian.Tree)
 	updateFunc(tree)
-	if err := storage.ValidateTreeForUpdate(ctx, &beforeUpdate, tree); err != nil {
+	if err := storage.ValidateTreeForUpdate(ctx, mTree.meta, tree); err != nil {
 		return nil, err
 	}
 	if err := validateStorageSettings(tree); err != nil {
@@ -191,7 +203,8 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	if err != nil {
 		return nil, err
 	}
-	return tree, nil
+	mTree.meta = tree
+	return proto.Clone(tree).(*trillian.Tree), nil
 }
 
 func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
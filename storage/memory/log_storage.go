@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -189,6 +190,10 @@ func (m *memoryLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillia
 func (m *memoryLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
 	tx, err := m.beginInternal(ctx, tree, true /* readonly */)
 	if err != nil {
+		if tx != nil {
+			// Release the tree's lock, which is held even if it needs init.
+			tx.Close()
+		}
 		return nil, err
 	}
 	return tx.(storage.ReadOnlyLogTreeTX), err
@@ -238,6 +243,19 @@ func (t *logTreeTX) WriteRevision() int64 {
 }
 
 func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	leaves := t.queuedLeaves(limit)
+	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return leaves, nil
+}
+
+// PeekQueuedLeaves implements storage.QueuePeeker.
+func (t *logTreeTX) PeekQueuedLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	return t.queuedLeaves(limit), nil
+}
+
+// queuedLeaves returns up to limit leaves from the head of the queue. They're
+// only removed from the queue once they've been sequenced.
+func (t *logTreeTX) queuedLeaves(limit int) []*trillian.LogLeaf {
 	leaves := make([]*trillian.LogLeaf, 0, limit)
 
 	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
@@ -247,9 +265,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 		leaves = append(leaves, e.Value.(*trillian.LogLeaf))
 		e = e.Next()
 	}
-
-	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
-	return leaves, nil
+	return leaves
 }
 
 func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
@@ -305,16 +321,19 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 	return ret, nil
 }
 
-func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool, start, limit int64) ([]*trillian.LogLeaf, error) {
 	m := t.tx.Get(hashToSeqKey(t.treeID)).(*kv).v.(map[string][]int64)
 
 	ret := make([]*trillian.LogLeaf, 0, len(leafHashes))
-	for hash := range leafHashes {
+	for _, hash := range leafHashes {
 		seq, ok := m[string(hash)]
 		if !ok {
 			continue
 		}
 		for _, s := range seq {
+			if s < start {
+				continue
+			}
 			l := t.tx.Get(seqLeafKey(t.treeID, s))
 			if l == nil {
 				continue
@@ -322,6 +341,12 @@ func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, or
 			ret = append(ret, l.(*kv).v.(*trillian.LogLeaf))
 		}
 	}
+	if orderBySequence || limit > 0 {
+		sort.Slice(ret, func(i, j int) bool { return ret[i].LeafIndex < ret[j].LeafIndex })
+	}
+	if limit > 0 && int64(len(ret)) > limit {
+		ret = ret[:limit]
+	}
 	return ret, nil
 }
 
@@ -0,0 +1,633 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/btree"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var defaultMapStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}
+
+// mapTXKey is the context key under which ReadWriteTransaction stores the
+// transaction it passes to its MapTXFunc. Nested ReadWriteTransaction calls
+// for the same tree (as made by merkle.SparseMerkleTreeWriter) join that
+// transaction rather than starting their own, as the outer transaction holds
+// the tree's write lock until it completes.
+type mapTXKey struct {
+	treeID int64
+}
+
+// mapLeafPrefix returns the prefix of all keys under which values of keyHash
+// are stored.
+func mapLeafPrefix(treeID int64, keyHash []byte) string {
+	return fmt.Sprintf("/%d/mapleaf/%x/", treeID, keyHash)
+}
+
+// mapLeafKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the MapLeaf stored for keyHash at the
+// given revision.
+func mapLeafKey(treeID int64, keyHash []byte, rev int64) btree.Item {
+	return &kv{k: fmt.Sprintf("%s%020d", mapLeafPrefix(treeID, keyHash), rev)}
+}
+
+// mapRootKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the SignedMapRoot with the given revision.
+func mapRootKey(treeID, rev int64) btree.Item {
+	return &kv{k: fmt.Sprintf("/%d/smr/%020d", treeID, rev)}
+}
+
+// mapQueuePrefix returns the prefix of all keys under which leaves queued for
+// the map are stored.
+func mapQueuePrefix(treeID int64) string {
+	return fmt.Sprintf("/%d/mapqueue/", treeID)
+}
+
+// mapQueueKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the MapLeaf queued for keyHash at the
+// given timestamp, so keys sort in queue order.
+func mapQueueKey(treeID int64, ts time.Time, keyHash []byte) btree.Item {
+	return &kv{k: fmt.Sprintf("%s%020d/%x", mapQueuePrefix(treeID), ts.UnixNano(), keyHash)}
+}
+
+type memoryMapStorage struct {
+	*memoryTreeStorage
+}
+
+// NewMapStorage creates an in-memory MapStorage instance.
+// The returned storage shares its trees with ls, which must have been created
+// by NewLogStorage, so trees created through NewAdminStorage(ls) are visible
+// to it.
+func NewMapStorage(ls storage.LogStorage) storage.MapStorage {
+	return &memoryMapStorage{ls.(*memoryLogStorage).memoryTreeStorage}
+}
+
+func (m *memoryMapStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return nil
+}
+
+type readOnlyMapTX struct{}
+
+func (m *memoryMapStorage) Snapshot(ctx context.Context) (storage.ReadOnlyMapTX, error) {
+	return &readOnlyMapTX{}, nil
+}
+
+func (t *readOnlyMapTX) Commit() error {
+	return nil
+}
+
+func (t *readOnlyMapTX) Rollback() error {
+	return nil
+}
+
+func (t *readOnlyMapTX) Close() error {
+	return nil
+}
+
+func (m *memoryMapStorage) begin(ctx context.Context, tree *trillian.Tree, readonly bool) (*mapTreeTX, error) {
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stCache := cache.NewMapSubtreeCache(defaultMapStrata, tree.TreeId, hasher)
+	ttx, err := m.memoryTreeStorage.beginTreeTX(ctx, tree.TreeId, hasher.Size(), stCache, readonly)
+	if err != nil {
+		return nil, err
+	}
+
+	mtx := &mapTreeTX{
+		treeTX:       ttx,
+		ms:           m,
+		readRevision: -1,
+		mu:           &sync.Mutex{},
+	}
+
+	if readonly {
+		// readRevision will be set later, by the first
+		// GetSignedMapRoot/LatestSignedMapRoot operation.
+		return mtx, nil
+	}
+
+	// A read-write transaction needs to know the current revision
+	// so it can write at revision+1.
+	root, err := mtx.LatestSignedMapRoot(ctx)
+	if err == storage.ErrTreeNeedsInit {
+		return mtx, err
+	} else if err != nil {
+		mtx.Rollback()
+		return nil, err
+	}
+
+	var mr types.MapRootV1
+	if err := mr.UnmarshalBinary(root.MapRoot); err != nil {
+		mtx.Rollback()
+		return nil, err
+	}
+
+	mtx.readRevision = int64(mr.Revision)
+	mtx.treeTX.writeRevision = int64(mr.Revision) + 1
+	return mtx, nil
+}
+
+// join returns a new transaction which shares parent's BTree, lock and
+// revisions, but has its own subtree cache. Committing it flushes its cache
+// to the shared BTree, but only parent may publish the BTree to the tree.
+func (m *memoryMapStorage) join(parent *mapTreeTX, tree *trillian.Tree) (*mapTreeTX, error) {
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	ttx := parent.treeTX
+	ttx.subtreeCache = cache.NewMapSubtreeCache(defaultMapStrata, tree.TreeId, hasher)
+	ttx.closed = false
+	ttx.unlock = func() {}
+
+	return &mapTreeTX{
+		treeTX:       ttx,
+		ms:           m,
+		readRevision: parent.readRevision,
+		mu:           parent.mu,
+		joined:       true,
+	}, nil
+}
+
+func (m *memoryMapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	tx, err := m.begin(ctx, tree, true /* readonly */)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (m *memoryMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	if parent, ok := ctx.Value(mapTXKey{tree.TreeId}).(*mapTreeTX); ok {
+		tx, err := m.join(parent, tree)
+		if err != nil {
+			return err
+		}
+		defer tx.Close()
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	tx, err := m.begin(ctx, tree, false /* readonly */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	defer tx.Close()
+	if err := f(context.WithValue(ctx, mapTXKey{tree.TreeId}, tx), tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PruneMapRevisions implements storage.MapRevisionPruner.
+func (m *memoryMapStorage) PruneMapRevisions(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	tx, err := m.begin(ctx, tree, false /* readonly */)
+	if err == storage.ErrTreeNeedsInit {
+		// Nothing to prune.
+		tx.Close()
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer tx.Close()
+	tx.prune(revision)
+	return tx.Commit()
+}
+
+// QueueMapLeaves implements storage.MapLeafQueuer.
+func (m *memoryMapStorage) QueueMapLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	tx, err := m.begin(ctx, tree, false /* readonly */)
+	if err != nil {
+		if tx != nil {
+			tx.Close()
+		}
+		return err
+	}
+	defer tx.Close()
+
+	tx.mu.Lock()
+	for _, l := range leaves {
+		k := mapQueueKey(tree.TreeId, queueTimestamp, l.Index)
+		k.(*kv).v = proto.Clone(l).(*trillian.MapLeaf)
+		tx.tx.ReplaceOrInsert(k)
+	}
+	tx.mu.Unlock()
+	return tx.Commit()
+}
+
+type mapTreeTX struct {
+	treeTX
+	ms           *memoryMapStorage
+	readRevision int64
+
+	// mu serialises access to the underlying BTree, which is shared with any
+	// transactions joined to this one and used by them concurrently.
+	mu *sync.Mutex
+	// joined is true if this transaction was created by join.
+	joined bool
+}
+
+func (t *mapTreeTX) ReadRevision() int64 {
+	return t.readRevision
+}
+
+func (t *mapTreeTX) WriteRevision() int64 {
+	return t.treeTX.writeRevision
+}
+
+func (t *mapTreeTX) GetMerkleNodes(ctx context.Context, treeRevision int64, nodeIDs []storage.NodeID) ([]storage.Node, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.treeTX.GetMerkleNodes(ctx, treeRevision, nodeIDs)
+}
+
+func (t *mapTreeTX) SetMerkleNodes(ctx context.Context, nodes []storage.Node) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.treeTX.SetMerkleNodes(ctx, nodes)
+}
+
+func (t *mapTreeTX) Set(ctx context.Context, keyHash []byte, value trillian.MapLeaf) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := mapLeafKey(t.treeID, keyHash, t.writeRevision)
+	if t.tx.Has(k) {
+		return fmt.Errorf("key %x already set at revision %d", keyHash, t.writeRevision)
+	}
+	// Store a copy so the caller can't modify the stored leaf.
+	k.(*kv).v = proto.Clone(&value).(*trillian.MapLeaf)
+	t.tx.ReplaceOrInsert(k)
+	return nil
+}
+
+// Get returns a list of map leaves indicated by indexes.
+// If an index is not found, no corresponding entry is returned.
+// Each MapLeaf.Index is overwritten with the index the leaf was found at.
+func (t *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) ([]trillian.MapLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if revision < 0 {
+		revision = math.MaxInt64
+	}
+	ret := make([]trillian.MapLeaf, 0, len(indexes))
+	for _, index := range indexes {
+		prefix := mapLeafPrefix(t.treeID, index)
+		var leaf *trillian.MapLeaf
+		// Find the most recent value at or below revision.
+		t.tx.DescendLessOrEqual(mapLeafKey(t.treeID, index, revision), func(i btree.Item) bool {
+			if e := i.(*kv); strings.HasPrefix(e.k, prefix) {
+				leaf = e.v.(*trillian.MapLeaf)
+			}
+			return false
+		})
+		if leaf == nil {
+			continue
+		}
+		mapLeaf := *proto.Clone(leaf).(*trillian.MapLeaf)
+		mapLeaf.Index = append([]byte(nil), index...)
+		ret = append(ret, mapLeaf)
+	}
+	return ret, nil
+}
+
+// GetLeavesAfter returns up to limit non-empty leaves at revision whose
+// indexes sort after keyHash, in ascending index order.
+func (t *mapTreeTX) GetLeavesAfter(ctx context.Context, revision int64, keyHash []byte, limit int) ([]trillian.MapLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if revision < 0 {
+		revision = math.MaxInt64
+	}
+	// Hex encoding preserves the byte ordering of the indexes, and "~" sorts
+	// after all of the revisions stored under keyHash.
+	prefix := fmt.Sprintf("/%d/mapleaf/", t.treeID)
+	start := prefix
+	if len(keyHash) > 0 {
+		start = mapLeafPrefix(t.treeID, keyHash) + "~"
+	}
+
+	ret := make([]trillian.MapLeaf, 0)
+	var curIndex string
+	var curLeaf *trillian.MapLeaf
+	flush := func() error {
+		if curLeaf == nil || len(curLeaf.LeafValue) == 0 {
+			return nil
+		}
+		index, err := hex.DecodeString(curIndex)
+		if err != nil {
+			return err
+		}
+		mapLeaf := *proto.Clone(curLeaf).(*trillian.MapLeaf)
+		mapLeaf.Index = index
+		ret = append(ret, mapLeaf)
+		return nil
+	}
+
+	var err error
+	t.tx.AscendGreaterOrEqual(&kv{k: start}, func(i btree.Item) bool {
+		e := i.(*kv)
+		if !strings.HasPrefix(e.k, prefix) || len(ret) >= limit {
+			return false
+		}
+		sep := strings.LastIndex(e.k, "/")
+		index := e.k[len(prefix):sep]
+		var rev int64
+		if rev, err = strconv.ParseInt(e.k[sep+1:], 10, 64); err != nil {
+			return false
+		}
+		if index != curIndex {
+			if err = flush(); err != nil {
+				return false
+			}
+			curIndex, curLeaf = index, nil
+		}
+		if rev <= revision {
+			curLeaf = e.v.(*trillian.MapLeaf)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) < limit {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// GetLeafHistory returns the values written to keyHash at revisions between
+// fromRevision and toRevision inclusive, in ascending revision order.
+func (t *mapTreeTX) GetLeafHistory(ctx context.Context, keyHash []byte, fromRevision, toRevision int64) ([]storage.MapLeafRevision, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prefix := mapLeafPrefix(t.treeID, keyHash)
+	ret := make([]storage.MapLeafRevision, 0)
+	var err error
+	t.tx.AscendGreaterOrEqual(mapLeafKey(t.treeID, keyHash, fromRevision), func(i btree.Item) bool {
+		e := i.(*kv)
+		if !strings.HasPrefix(e.k, prefix) {
+			return false
+		}
+		var rev int64
+		if rev, err = strconv.ParseInt(e.k[len(prefix):], 10, 64); err != nil || rev > toRevision {
+			return false
+		}
+		mapLeaf := *proto.Clone(e.v.(*trillian.MapLeaf)).(*trillian.MapLeaf)
+		mapLeaf.Index = append([]byte(nil), keyHash...)
+		ret = append(ret, storage.MapLeafRevision{Revision: rev, Leaf: mapLeaf})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// DequeueMapLeaves implements storage.MapQueueTX.
+func (t *mapTreeTX) DequeueMapLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.MapLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// "~" sorts after all of the key hashes queued at cutoffTime.
+	end := &kv{k: fmt.Sprintf("%s%020d/~", mapQueuePrefix(t.treeID), cutoffTime.UnixNano())}
+	var items []btree.Item
+	leaves := make([]*trillian.MapLeaf, 0, limit)
+	t.tx.AscendRange(&kv{k: mapQueuePrefix(t.treeID)}, end, func(i btree.Item) bool {
+		if len(leaves) >= limit {
+			return false
+		}
+		items = append(items, i)
+		leaves = append(leaves, proto.Clone(i.(*kv).v.(*trillian.MapLeaf)).(*trillian.MapLeaf))
+		return true
+	})
+	for _, i := range items {
+		t.tx.Delete(i)
+	}
+	return leaves, nil
+}
+
+func (t *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (trillian.SignedMapRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := t.tx.Get(mapRootKey(t.treeID, revision))
+	if r == nil {
+		if !t.hasMapRoots() {
+			return trillian.SignedMapRoot{}, storage.ErrTreeNeedsInit
+		}
+		return trillian.SignedMapRoot{}, status.Errorf(codes.NotFound, "no map root for revision %d", revision)
+	}
+	t.readRevision = revision
+	return *r.(*kv).v.(*trillian.SignedMapRoot), nil
+}
+
+// hasMapRoots returns whether any roots have been stored for the map.
+func (t *mapTreeTX) hasMapRoots() bool {
+	prefix := fmt.Sprintf("/%d/smr/", t.treeID)
+	found := false
+	t.tx.AscendGreaterOrEqual(&kv{k: prefix}, func(i btree.Item) bool {
+		found = strings.HasPrefix(i.(*kv).k, prefix)
+		return false
+	})
+	return found
+}
+
+// prune deletes the map's roots for revisions before revision, and all leaf
+// and subtree values superseded by revision.
+func (t *mapTreeTX) prune(revision int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var del []btree.Item
+	t.tx.AscendRange(&kv{k: fmt.Sprintf("/%d/smr/", t.treeID)}, mapRootKey(t.treeID, revision), func(i btree.Item) bool {
+		del = append(del, i)
+		return true
+	})
+
+	// The latest value at or below revision is kept for each key, everything
+	// older is superseded. Leaf keys are ordered by revision, subtree keys
+	// are not.
+	leafPrefix := fmt.Sprintf("/%d/mapleaf/", t.treeID)
+	var prev btree.Item
+	var prevIndex string
+	t.tx.AscendGreaterOrEqual(&kv{k: leafPrefix}, func(i btree.Item) bool {
+		k := i.(*kv).k
+		if !strings.HasPrefix(k, leafPrefix) {
+			return false
+		}
+		sep := strings.LastIndex(k, "/")
+		if rev, err := strconv.ParseInt(k[sep+1:], 10, 64); err != nil || rev > revision {
+			return true
+		}
+		if prev != nil && k[:sep] == prevIndex {
+			del = append(del, prev)
+		}
+		prev, prevIndex = i, k[:sep]
+		return true
+	})
+
+	type subtreeRev struct {
+		rev  int64
+		item btree.Item
+	}
+	subtrees := make(map[string][]subtreeRev)
+	subtreePrefix := fmt.Sprintf("/%d/subtree/", t.treeID)
+	t.tx.AscendGreaterOrEqual(&kv{k: subtreePrefix}, func(i btree.Item) bool {
+		k := i.(*kv).k
+		if !strings.HasPrefix(k, subtreePrefix) {
+			return false
+		}
+		sep := strings.LastIndex(k, "/")
+		if rev, err := strconv.ParseInt(k[sep+1:], 10, 64); err == nil && rev <= revision {
+			subtrees[k[:sep]] = append(subtrees[k[:sep]], subtreeRev{rev: rev, item: i})
+		}
+		return true
+	})
+	for _, revs := range subtrees {
+		var latest int64
+		for _, r := range revs {
+			if r.rev > latest {
+				latest = r.rev
+			}
+		}
+		for _, r := range revs {
+			if r.rev < latest {
+				del = append(del, r.item)
+			}
+		}
+	}
+
+	for _, i := range del {
+		t.tx.Delete(i)
+	}
+}
+
+func (t *mapTreeTX) LatestSignedMapRoot(ctx context.Context) (trillian.SignedMapRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var root *trillian.SignedMapRoot
+	var revision int64
+	prefix := fmt.Sprintf("/%d/smr/", t.treeID)
+	t.tx.DescendLessOrEqual(mapRootKey(t.treeID, math.MaxInt64), func(i btree.Item) bool {
+		e := i.(*kv)
+		if !strings.HasPrefix(e.k, prefix) {
+			return false
+		}
+		if _, err := fmt.Sscanf(strings.TrimPrefix(e.k, prefix), "%d", &revision); err != nil {
+			glog.Warningf("Failed to parse map root key %q: %v", e.k, err)
+			return false
+		}
+		root = e.v.(*trillian.SignedMapRoot)
+		return false
+	})
+	// It's possible there are no roots for this tree yet
+	if root == nil {
+		return trillian.SignedMapRoot{}, storage.ErrTreeNeedsInit
+	}
+	t.readRevision = revision
+	return *root, nil
+}
+
+func (t *mapTreeTX) StoreSignedMapRoot(ctx context.Context, root trillian.SignedMapRoot) error {
+	var r types.MapRootV1
+	if err := r.UnmarshalBinary(root.MapRoot); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := mapRootKey(t.treeID, int64(r.Revision))
+	if t.tx.Has(k) {
+		return storage.ErrMapRevisionExists
+	}
+	k.(*kv).v = &root
+	t.tx.ReplaceOrInsert(k)
+	return nil
+}
+
+func (t *mapTreeTX) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.joined {
+		return t.treeTX.Commit()
+	}
+	// A joined transaction only flushes its subtree cache; the parent
+	// publishes the shared BTree when it commits.
+	if t.writeRevision > -1 {
+		if err := t.subtreeCache.Flush(func(st []*storagepb.SubtreeProto) error {
+			return t.storeSubtrees(context.TODO(), st)
+		}); err != nil {
+			glog.Warningf("TX commit flush error: %v", err)
+			return err
+		}
+	}
+	t.closed = true
+	return nil
+}
+
+func (t *mapTreeTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.joined {
+		t.closed = true
+		return nil
+	}
+	return t.treeTX.Rollback()
+}
+
+func (t *mapTreeTX) Close() error {
+	if t.IsOpen() {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,444 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+	storageto "github.com/google/trillian/storage/testonly"
+
+	_ "github.com/google/trillian/merkle/maphasher"
+)
+
+var fixedSigner = tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+
+func mustSignMapRoot(root *types.MapRootV1) *trillian.SignedMapRoot {
+	r, err := fixedSigner.SignMapRoot(root)
+	if err != nil {
+		panic(fmt.Sprintf("SignMapRoot(): %v", err))
+	}
+	return r
+}
+
+func TestMapSnapshotUnknownTree(t *testing.T) {
+	s := NewMapStorage(NewLogStorage(nil))
+	if _, err := s.SnapshotForTree(context.Background(), mapTree(12345)); err == nil {
+		t.Error("SnapshotForTree() for unknown tree = (_, nil), want err")
+	}
+}
+
+func TestMapReadWriteTransactionUninitialized(t *testing.T) {
+	ctx := context.Background()
+	ls := NewLogStorage(nil)
+	tree := createTreeOrPanic(ls, storageto.MapTree)
+	s := NewMapStorage(ls)
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if _, err := tx.LatestSignedMapRoot(ctx); err != storage.ErrTreeNeedsInit {
+			t.Errorf("LatestSignedMapRoot() = (_, %v), want = (_, %v)", err, storage.ErrTreeNeedsInit)
+		}
+		if _, err := tx.GetSignedMapRoot(ctx, 0); err != storage.ErrTreeNeedsInit {
+			t.Errorf("GetSignedMapRoot(0) = (_, %v), want = (_, %v)", err, storage.ErrTreeNeedsInit)
+		}
+		if got, want := tx.WriteRevision(), int64(-1); got != want {
+			t.Errorf("WriteRevision() = %v, want = %v", got, want)
+		}
+		return nil
+	})
+}
+
+func TestMapSignedMapRoots(t *testing.T) {
+	ctx := context.Background()
+	ls := NewLogStorage(nil)
+	tree := createInitializedMapForTests(ctx, t, ls)
+	s := NewMapStorage(ls)
+
+	var roots []*trillian.SignedMapRoot
+	for rev := uint64(1); rev <= 3; rev++ {
+		root := mustSignMapRoot(&types.MapRootV1{TimestampNanos: 98765 + rev, Revision: rev, RootHash: []byte("rootHash")})
+		roots = append(roots, root)
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			if got, want := tx.WriteRevision(), int64(rev); got != want {
+				t.Errorf("WriteRevision() = %v, want = %v", got, want)
+			}
+			if err := tx.StoreSignedMapRoot(ctx, *root); err != nil {
+				t.Fatalf("StoreSignedMapRoot(): %v", err)
+			}
+			// Shouldn't be able to do it again.
+			if err := tx.StoreSignedMapRoot(ctx, *root); err != storage.ErrMapRevisionExists {
+				t.Fatalf("StoreSignedMapRoot() of duplicate root = %v, want %v", err, storage.ErrMapRevisionExists)
+			}
+			return nil
+		})
+	}
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	latest, err := tx.LatestSignedMapRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedMapRoot(): %v", err)
+	}
+	if want := roots[len(roots)-1]; !proto.Equal(&latest, want) {
+		t.Errorf("LatestSignedMapRoot() = %v, want %v", latest, want)
+	}
+	for i, want := range roots {
+		rev := int64(i + 1)
+		got, err := tx.GetSignedMapRoot(ctx, rev)
+		if err != nil {
+			t.Fatalf("GetSignedMapRoot(%d): %v", rev, err)
+		}
+		if !proto.Equal(&got, want) {
+			t.Errorf("GetSignedMapRoot(%d) = %v, want %v", rev, got, want)
+		}
+	}
+	if _, err := tx.GetSignedMapRoot(ctx, 10); err == nil {
+		t.Error("GetSignedMapRoot(10) = (_, nil), want err")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Commit(): %v", err)
+	}
+}
+
+func TestMapSetGetMultipleRevisions(t *testing.T) {
+	ctx := context.Background()
+	ls := NewLogStorage(nil)
+	tree := createInitializedMapForTests(ctx, t, ls)
+	s := NewMapStorage(ls)
+	keyHash := []byte("A Key Hash")
+
+	tests := []struct {
+		rev  int64
+		leaf trillian.MapLeaf
+	}{
+		{1, trillian.MapLeaf{Index: keyHash, LeafHash: []byte{1}, LeafValue: []byte{1}, ExtraData: []byte{1}}},
+		{2, trillian.MapLeaf{Index: keyHash, LeafHash: []byte{2}, LeafValue: []byte{2}, ExtraData: []byte{2}}},
+		{3, trillian.MapLeaf{Index: keyHash, LeafHash: []byte{3}, LeafValue: []byte{3}, ExtraData: []byte{3}}},
+	}
+
+	for _, tc := range tests {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			if err := tx.Set(ctx, keyHash, tc.leaf); err != nil {
+				t.Fatalf("Set(%v): %v", tc.leaf, err)
+			}
+			if err := tx.Set(ctx, keyHash, tc.leaf); err == nil {
+				t.Fatalf("Set(%v) twice in revision %d succeeded", tc.leaf, tc.rev)
+			}
+			return tx.StoreSignedMapRoot(ctx, *mustSignMapRoot(&types.MapRootV1{Revision: uint64(tc.rev)}))
+		})
+	}
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		for rev := int64(-1); rev <= 5; rev++ {
+			got, err := tx.Get(ctx, rev, [][]byte{keyHash, []byte("This doesn't exist.")})
+			if err != nil {
+				t.Fatalf("Get(%d): %v", rev, err)
+			}
+			var want []trillian.MapLeaf
+			switch {
+			case rev == 0:
+			case rev < 0 || rev > 3:
+				want = append(want, tests[2].leaf)
+			default:
+				want = append(want, tests[rev-1].leaf)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("Get(%d) returned %d leaves, want %d", rev, len(got), len(want))
+			}
+			for i := range got {
+				if !proto.Equal(&got[i], &want[i]) {
+					t.Errorf("Get(%d)[%d] = %v, want %v", rev, i, got[i], want[i])
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func TestMapGetLeavesAfter(t *testing.T) {
+	ctx := context.Background()
+	ls := NewLogStorage(nil)
+	tree := createInitializedMapForTests(ctx, t, ls)
+	s := NewMapStorage(ls)
+
+	writes := []struct {
+		rev    int64
+		leaves map[string]string
+	}{
+		{rev: 1, leaves: map[string]string{"key2": "a", "key1": "b", "key3": "c"}},
+		// An empty value deletes key2.
+		{rev: 2, leaves: map[string]string{"key2": "", "key4": "d"}},
+	}
+	for _, w := range writes {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			for k, v := range w.leaves {
+				if err := tx.Set(ctx, []byte(k), trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)}); err != nil {
+					t.Fatalf("Set(%s): %v", k, err)
+				}
+			}
+			return tx.StoreSignedMapRoot(ctx, *mustSignMapRoot(&types.MapRootV1{Revision: uint64(w.rev)}))
+		})
+	}
+
+	for _, test := range []struct {
+		rev   int64
+		after string
+		limit int
+		want  []string
+	}{
+		{rev: 0, limit: 10},
+		{rev: 1, limit: 10, want: []string{"key1=b", "key2=a", "key3=c"}},
+		{rev: 1, after: "key1", limit: 10, want: []string{"key2=a", "key3=c"}},
+		{rev: 1, limit: 2, want: []string{"key1=b", "key2=a"}},
+		{rev: 1, after: "key3", limit: 10},
+		{rev: 2, limit: 10, want: []string{"key1=b", "key3=c", "key4=d"}},
+		{rev: 2, after: "key2", limit: 1, want: []string{"key3=c"}},
+		{rev: 2, limit: 0},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			leaves, err := tx.GetLeavesAfter(ctx, test.rev, []byte(test.after), test.limit)
+			if err != nil {
+				t.Fatalf("GetLeavesAfter(%d, %q, %d): %v", test.rev, test.after, test.limit, err)
+			}
+			var got []string
+			for _, l := range leaves {
+				got = append(got, fmt.Sprintf("%s=%s", l.Index, l.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeavesAfter(%d, %q, %d) = %v, want %v", test.rev, test.after, test.limit, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
+func TestMapGetLeafHistory(t *testing.T) {
+	ctx := context.Background()
+	ls := NewLogStorage(nil)
+	tree := createInitializedMapForTests(ctx, t, ls)
+	s := NewMapStorage(ls)
+
+	writes := []struct {
+		rev    int64
+		leaves map[string]string
+	}{
+		{rev: 1, leaves: map[string]string{"key1": "a", "key2": "x"}},
+		{rev: 2, leaves: map[string]string{"key1": "b"}},
+		{rev: 3, leaves: map[string]string{"key2": "y"}},
+		{rev: 4, leaves: map[string]string{"key1": "c"}},
+	}
+	for _, w := range writes {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			for k, v := range w.leaves {
+				if err := tx.Set(ctx, []byte(k), trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)}); err != nil {
+					t.Fatalf("Set(%s): %v", k, err)
+				}
+			}
+			return tx.StoreSignedMapRoot(ctx, *mustSignMapRoot(&types.MapRootV1{Revision: uint64(w.rev)}))
+		})
+	}
+
+	for _, test := range []struct {
+		key      string
+		from, to int64
+		want     []string
+	}{
+		{key: "key1", from: 0, to: 4, want: []string{"key1@1=a", "key1@2=b", "key1@4=c"}},
+		{key: "key1", from: 2, to: 3, want: []string{"key1@2=b"}},
+		{key: "key1", from: 3, to: 3},
+		{key: "key1", from: 3, to: 10, want: []string{"key1@4=c"}},
+		{key: "key2", from: 1, to: 4, want: []string{"key2@1=x", "key2@3=y"}},
+		{key: "key5", from: 0, to: 4},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			history, err := tx.GetLeafHistory(ctx, []byte(test.key), test.from, test.to)
+			if err != nil {
+				t.Fatalf("GetLeafHistory(%q, %d, %d): %v", test.key, test.from, test.to, err)
+			}
+			var got []string
+			for _, h := range history {
+				got = append(got, fmt.Sprintf("%s@%d=%s", h.Leaf.Index, h.Revision, h.Leaf.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeafHistory(%q, %d, %d) = %v, want %v", test.key, test.from, test.to, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
+func TestQueueMapLeaves(t *testing.T) {
+	ctx := context.Background()
+	ls := NewLogStorage(nil)
+	tree := createInitializedMapForTests(ctx, t, ls)
+	s := NewMapStorage(ls)
+
+	queuer := s.(storage.MapLeafQueuer)
+	base := time.Unix(1000, 0)
+	for _, q := range []struct {
+		ts     time.Duration
+		leaves map[string]string
+	}{
+		{ts: 0, leaves: map[string]string{"key1": "a", "key2": "x"}},
+		{ts: time.Second, leaves: map[string]string{"key1": "b"}},
+		{ts: 2 * time.Second, leaves: map[string]string{"key3": "z"}},
+	} {
+		var leaves []*trillian.MapLeaf
+		for k, v := range q.leaves {
+			leaves = append(leaves, &trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)})
+		}
+		if err := queuer.QueueMapLeaves(ctx, tree, leaves, base.Add(q.ts)); err != nil {
+			t.Fatalf("QueueMapLeaves(): %v", err)
+		}
+	}
+
+	for _, test := range []struct {
+		limit  int
+		cutoff time.Duration
+		want   []string
+	}{
+		{limit: 10, cutoff: -time.Second},
+		{limit: 2, cutoff: time.Second, want: []string{"key1=a", "key2=x"}},
+		{limit: 10, cutoff: time.Second, want: []string{"key1=b"}},
+		{limit: 10, cutoff: time.Hour, want: []string{"key3=z"}},
+		{limit: 10, cutoff: time.Hour},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			leaves, err := tx.(storage.MapQueueTX).DequeueMapLeaves(ctx, test.limit, base.Add(test.cutoff))
+			if err != nil {
+				t.Fatalf("DequeueMapLeaves(%d, %v): %v", test.limit, test.cutoff, err)
+			}
+			var got []string
+			for _, l := range leaves {
+				got = append(got, fmt.Sprintf("%s=%s", l.Index, l.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DequeueMapLeaves(%d, %v) = %v, want %v", test.limit, test.cutoff, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
+// TestMapNestedReadWriteTransaction checks that ReadWriteTransaction calls
+// made from within another ReadWriteTransaction for the same map, as
+// SparseMerkleTreeWriter does, don't deadlock and are committed along with it.
+func TestMapNestedReadWriteTransaction(t *testing.T) {
+	ctx := context.Background()
+	ls := NewLogStorage(nil)
+	tree := createInitializedMapForTests(ctx, t, ls)
+	s := NewMapStorage(ls)
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewMapHasher(): %v", err)
+	}
+
+	var leaves []merkle.HashKeyValue
+	for i := 0; i < 10; i++ {
+		key := sha256.Sum256([]byte(fmt.Sprintf("key-%d", i)))
+		value := sha256.Sum256([]byte(fmt.Sprintf("value-%d", i)))
+		leaves = append(leaves, merkle.HashKeyValue{HashedKey: key[:], HashedValue: value[:]})
+	}
+
+	var rev int64
+	var rootHash []byte
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		rev = tx.WriteRevision()
+		w, err := merkle.NewSparseMerkleTreeWriter(ctx, tree.TreeId, rev, hasher,
+			func(ctx context.Context, f func(context.Context, storage.MapTreeTX) error) error {
+				return s.ReadWriteTransaction(ctx, tree, f)
+			})
+		if err != nil {
+			t.Fatalf("NewSparseMerkleTreeWriter(): %v", err)
+		}
+		if err := w.SetLeaves(ctx, leaves); err != nil {
+			t.Fatalf("SetLeaves(): %v", err)
+		}
+		if rootHash, err = w.CalculateRoot(); err != nil {
+			t.Fatalf("CalculateRoot(): %v", err)
+		}
+		return tx.StoreSignedMapRoot(ctx, *mustSignMapRoot(&types.MapRootV1{
+			TimestampNanos: 98765,
+			Revision:       uint64(rev),
+			RootHash:       rootHash,
+		}))
+	})
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	got, err := merkle.NewSparseMerkleTreeReader(rev, hasher, tx).RootAtRevision(ctx, rev)
+	if err != nil {
+		t.Fatalf("RootAtRevision(%d): %v", rev, err)
+	}
+	if !bytes.Equal(got, rootHash) {
+		t.Errorf("RootAtRevision(%d) = %x, want %x", rev, got, rootHash)
+	}
+}
+
+func runMapTX(ctx context.Context, s storage.MapStorage, tree *trillian.Tree, t *testing.T, f storage.MapTXFunc) {
+	t.Helper()
+	if err := s.ReadWriteTransaction(ctx, tree, f); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+}
+
+func createTreeOrPanic(ls storage.LogStorage, create *trillian.Tree) *trillian.Tree {
+	tree, err := storage.CreateTree(context.Background(), NewAdminStorage(ls), create)
+	if err != nil {
+		panic(fmt.Sprintf("Error creating tree: %v", err))
+	}
+	return tree
+}
+
+func createInitializedMapForTests(ctx context.Context, t *testing.T, ls storage.LogStorage) *trillian.Tree {
+	t.Helper()
+	tree := createTreeOrPanic(ls, storageto.MapTree)
+	runMapTX(ctx, NewMapStorage(ls), tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		return tx.StoreSignedMapRoot(ctx, *mustSignMapRoot(&types.MapRootV1{
+			RootHash: []byte("rootHash"),
+			Revision: 0,
+		}))
+	})
+	return tree
+}
+
+func mapTree(mapID int64) *trillian.Tree {
+	return &trillian.Tree{
+		TreeId:       mapID,
+		TreeType:     trillian.TreeType_MAP,
+		HashStrategy: trillian.HashStrategy_TEST_MAP_HASHER,
+	}
+}
@@ -77,8 +77,8 @@ func (t *tree) RUnlock() {
 	t.mu.RUnlock()
 }
 
-// memoryTreeStorage is shared between the memoryLog and (forthcoming) memoryMap-
-// Storage implementations, and contains functionality which is common to both,
+// memoryTreeStorage is shared between the memoryLog and memoryMapStorage
+// implementations, and contains functionality which is common to both,
 type memoryTreeStorage struct {
 	// mu only protects access to the trees map.
 	mu    sync.RWMutex
@@ -128,6 +128,9 @@ func newTree(t trillian.Tree) *tree {
 
 func (m *memoryTreeStorage) beginTreeTX(ctx context.Context, treeID int64, hashSizeBytes int, cache cache.SubtreeCache, readonly bool) (treeTX, error) {
 	tree := m.getTree(treeID)
+	if tree == nil {
+		return treeTX{}, fmt.Errorf("no such treeID %d", treeID)
+	}
 	// Lock the tree for the duration of the TX.
 	// It will be unlocked by a call to Commit or Rollback.
 	var unlock func()
@@ -198,6 +201,9 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 			// Return a copy of the proto to protect against the caller modifying the stored one.
 			p := s.(*kv).v.(*storagepb.SubtreeProto)
 			v := proto.Clone(p).(*storagepb.SubtreeProto)
+			if v.Prefix == nil {
+				v.Prefix = []byte{}
+			}
 			ret = append(ret, v)
 			break
 		}
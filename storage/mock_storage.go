@@ -183,7 +183,7 @@ func (mr *MockAdminTXMockRecorder) ListTreeIDs(arg0, arg1 interface{}) *gomock.C
 }
 
 // ListTrees mocks base method
-func (m *MockAdminTX) ListTrees(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
+func (m *MockAdminTX) ListTrees(arg0 context.Context, arg1 ListTreesOptions) ([]*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "ListTrees", arg0, arg1)
 	ret0, _ := ret[0].([]*trillian.Tree)
 	ret1, _ := ret[1].(error)
@@ -419,16 +419,16 @@ func (mr *MockLogTreeTXMockRecorder) DequeueLeaves(arg0, arg1, arg2 interface{})
 }
 
 // GetLeavesByHash mocks base method
-func (m *MockLogTreeTX) GetLeavesByHash(arg0 context.Context, arg1 [][]byte, arg2 bool) ([]*trillian.LogLeaf, error) {
-	ret := m.ctrl.Call(m, "GetLeavesByHash", arg0, arg1, arg2)
+func (m *MockLogTreeTX) GetLeavesByHash(arg0 context.Context, arg1 [][]byte, arg2 bool, arg3, arg4 int64) ([]*trillian.LogLeaf, error) {
+	ret := m.ctrl.Call(m, "GetLeavesByHash", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].([]*trillian.LogLeaf)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetLeavesByHash indicates an expected call of GetLeavesByHash
-func (mr *MockLogTreeTXMockRecorder) GetLeavesByHash(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByHash", reflect.TypeOf((*MockLogTreeTX)(nil).GetLeavesByHash), arg0, arg1, arg2)
+func (mr *MockLogTreeTXMockRecorder) GetLeavesByHash(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByHash", reflect.TypeOf((*MockLogTreeTX)(nil).GetLeavesByHash), arg0, arg1, arg2, arg3, arg4)
 }
 
 // GetLeavesByIndex mocks base method
@@ -726,6 +726,32 @@ func (mr *MockMapTreeTXMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMapTreeTX)(nil).Get), arg0, arg1, arg2)
 }
 
+// GetLeafHistory mocks base method
+func (m *MockMapTreeTX) GetLeafHistory(arg0 context.Context, arg1 []byte, arg2, arg3 int64) ([]MapLeafRevision, error) {
+	ret := m.ctrl.Call(m, "GetLeafHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]MapLeafRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeafHistory indicates an expected call of GetLeafHistory
+func (mr *MockMapTreeTXMockRecorder) GetLeafHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeafHistory", reflect.TypeOf((*MockMapTreeTX)(nil).GetLeafHistory), arg0, arg1, arg2, arg3)
+}
+
+// GetLeavesAfter mocks base method
+func (m *MockMapTreeTX) GetLeavesAfter(arg0 context.Context, arg1 int64, arg2 []byte, arg3 int) ([]trillian.MapLeaf, error) {
+	ret := m.ctrl.Call(m, "GetLeavesAfter", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]trillian.MapLeaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeavesAfter indicates an expected call of GetLeavesAfter
+func (mr *MockMapTreeTXMockRecorder) GetLeavesAfter(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesAfter", reflect.TypeOf((*MockMapTreeTX)(nil).GetLeavesAfter), arg0, arg1, arg2, arg3)
+}
+
 // GetMerkleNodes mocks base method
 func (m *MockMapTreeTX) GetMerkleNodes(arg0 context.Context, arg1 int64, arg2 []NodeID) ([]Node, error) {
 	ret := m.ctrl.Call(m, "GetMerkleNodes", arg0, arg1, arg2)
@@ -935,7 +961,7 @@ func (mr *MockReadOnlyAdminTXMockRecorder) ListTreeIDs(arg0, arg1 interface{}) *
 }
 
 // ListTrees mocks base method
-func (m *MockReadOnlyAdminTX) ListTrees(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
+func (m *MockReadOnlyAdminTX) ListTrees(arg0 context.Context, arg1 ListTreesOptions) ([]*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "ListTrees", arg0, arg1)
 	ret0, _ := ret[0].([]*trillian.Tree)
 	ret1, _ := ret[1].(error)
@@ -1092,16 +1118,16 @@ func (mr *MockReadOnlyLogTreeTXMockRecorder) Commit() *gomock.Call {
 }
 
 // GetLeavesByHash mocks base method
-func (m *MockReadOnlyLogTreeTX) GetLeavesByHash(arg0 context.Context, arg1 [][]byte, arg2 bool) ([]*trillian.LogLeaf, error) {
-	ret := m.ctrl.Call(m, "GetLeavesByHash", arg0, arg1, arg2)
+func (m *MockReadOnlyLogTreeTX) GetLeavesByHash(arg0 context.Context, arg1 [][]byte, arg2 bool, arg3, arg4 int64) ([]*trillian.LogLeaf, error) {
+	ret := m.ctrl.Call(m, "GetLeavesByHash", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].([]*trillian.LogLeaf)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetLeavesByHash indicates an expected call of GetLeavesByHash
-func (mr *MockReadOnlyLogTreeTXMockRecorder) GetLeavesByHash(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByHash", reflect.TypeOf((*MockReadOnlyLogTreeTX)(nil).GetLeavesByHash), arg0, arg1, arg2)
+func (mr *MockReadOnlyLogTreeTXMockRecorder) GetLeavesByHash(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByHash", reflect.TypeOf((*MockReadOnlyLogTreeTX)(nil).GetLeavesByHash), arg0, arg1, arg2, arg3, arg4)
 }
 
 // GetLeavesByIndex mocks base method
@@ -1265,6 +1291,32 @@ func (mr *MockReadOnlyMapTreeTXMockRecorder) Get(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReadOnlyMapTreeTX)(nil).Get), arg0, arg1, arg2)
 }
 
+// GetLeafHistory mocks base method
+func (m *MockReadOnlyMapTreeTX) GetLeafHistory(arg0 context.Context, arg1 []byte, arg2, arg3 int64) ([]MapLeafRevision, error) {
+	ret := m.ctrl.Call(m, "GetLeafHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]MapLeafRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeafHistory indicates an expected call of GetLeafHistory
+func (mr *MockReadOnlyMapTreeTXMockRecorder) GetLeafHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeafHistory", reflect.TypeOf((*MockReadOnlyMapTreeTX)(nil).GetLeafHistory), arg0, arg1, arg2, arg3)
+}
+
+// GetLeavesAfter mocks base method
+func (m *MockReadOnlyMapTreeTX) GetLeavesAfter(arg0 context.Context, arg1 int64, arg2 []byte, arg3 int) ([]trillian.MapLeaf, error) {
+	ret := m.ctrl.Call(m, "GetLeavesAfter", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]trillian.MapLeaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeavesAfter indicates an expected call of GetLeavesAfter
+func (mr *MockReadOnlyMapTreeTXMockRecorder) GetLeavesAfter(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesAfter", reflect.TypeOf((*MockReadOnlyMapTreeTX)(nil).GetLeavesAfter), arg0, arg1, arg2, arg3)
+}
+
 // GetMerkleNodes mocks base method
 func (m *MockReadOnlyMapTreeTX) GetMerkleNodes(arg0 context.Context, arg1 int64, arg2 []NodeID) ([]Node, error) {
 	ret := m.ctrl.Call(m, "GetMerkleNodes", arg0, arg1, arg2)
@@ -18,6 +18,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,7 +38,8 @@ import (
 const (
 	defaultSequenceIntervalSeconds = 60
 
-	nonDeletedWhere = " WHERE (Deleted IS NULL OR Deleted = 'false')"
+	nonDeletedCond  = "(Deleted IS NULL OR Deleted = 'false')"
+	nonDeletedWhere = " WHERE " + nonDeletedCond
 
 	selectTreeIDs           = "SELECT TreeId FROM Trees"
 	selectNonDeletedTreeIDs = selectTreeIDs + nonDeletedWhere
@@ -57,14 +60,22 @@ const (
 			PublicKey,
 			MaxRootDurationMillis,
 			Deleted,
-			DeleteTimeMillis
+			DeleteTimeMillis,
+			SequencerConfig
 		FROM Trees`
-	selectNonDeletedTrees = selectTrees + nonDeletedWhere
-	selectTreeByID        = selectTrees + " WHERE TreeId = ?"
+	selectTreeByID = selectTrees + " WHERE TreeId = ?"
 
 	updateTreeSQL = `UPDATE Trees
-		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?
+		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?, SequencerConfig = ?
 		WHERE TreeId = ?`
+
+	selectTreeLabels = "SELECT TreeId, LabelKey, LabelValue FROM TreeLabels"
+	insertTreeLabel  = "INSERT INTO TreeLabels(TreeId, LabelKey, LabelValue) VALUES(?, ?, ?)"
+	deleteTreeLabels = "DELETE FROM TreeLabels WHERE TreeId = ?"
+
+	selectTreeKeys = "SELECT TreeId, KeyId, StartTreeSize, PrivateKey, PublicKey FROM TreeKeys"
+	insertTreeKey  = "INSERT INTO TreeKeys(TreeId, KeyId, StartTreeSize, PrivateKey, PublicKey) VALUES(?, ?, ?, ?, ?)"
+	deleteTreeKeys = "DELETE FROM TreeKeys WHERE TreeId = ?"
 )
 
 // NewAdminStorage returns a MySQL storage.AdminStorage implementation backed by DB.
@@ -169,6 +180,12 @@ func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, er
 	case err != nil:
 		return nil, fmt.Errorf("error reading tree %v: %v", treeID, err)
 	}
+	if err := t.readLabels(ctx, []*trillian.Tree{tree}); err != nil {
+		return nil, fmt.Errorf("error reading labels of tree %v: %v", treeID, err)
+	}
+	if err := t.readKeys(ctx, []*trillian.Tree{tree}); err != nil {
+		return nil, fmt.Errorf("error reading keys of tree %v: %v", treeID, err)
+	}
 	return tree, nil
 }
 
@@ -185,7 +202,7 @@ func readTree(row row) (*trillian.Tree, error) {
 	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm string
 	var createMillis, updateMillis, maxRootDurationMillis int64
 	var displayName, description sql.NullString
-	var privateKey, publicKey []byte
+	var privateKey, publicKey, sequencerConfig []byte
 	var deleted sql.NullBool
 	var deleteMillis sql.NullInt64
 	err := row.Scan(
@@ -204,6 +221,7 @@ func readTree(row row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&sequencerConfig,
 	)
 	if err != nil {
 		return nil, err
@@ -268,6 +286,13 @@ func readTree(row row) (*trillian.Tree, error) {
 	}
 	tree.PublicKey = &keyspb.PublicKey{Der: publicKey}
 
+	if len(sequencerConfig) > 0 {
+		tree.SequencerConfig = &trillian.SequencerConfig{}
+		if err := proto.Unmarshal(sequencerConfig, tree.SequencerConfig); err != nil {
+			return nil, fmt.Errorf("could not unmarshal SequencerConfig: %v", err)
+		}
+	}
+
 	tree.Deleted = deleted.Valid && deleted.Bool
 	if tree.Deleted && deleteMillis.Valid {
 		tree.DeleteTime, err = ptypes.TimestampProto(fromMillisSinceEpoch(deleteMillis.Int64))
@@ -317,20 +342,14 @@ func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64
 	return treeIDs, nil
 }
 
-func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
-	var query string
-	if includeDeleted {
-		query = selectTrees
-	} else {
-		query = selectNonDeletedTrees
-	}
-
+func (t *adminTX) ListTrees(ctx context.Context, opts storage.ListTreesOptions) ([]*trillian.Tree, error) {
+	query, args := listTreesQuery(opts)
 	stmt, err := t.tx.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
-	rows, err := stmt.QueryContext(ctx)
+	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -343,9 +362,192 @@ func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trilli
 		}
 		trees = append(trees, tree)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := t.readLabels(ctx, trees); err != nil {
+		return nil, fmt.Errorf("error reading tree labels: %v", err)
+	}
+	if err := t.readKeys(ctx, trees); err != nil {
+		return nil, fmt.Errorf("error reading tree keys: %v", err)
+	}
 	return trees, nil
 }
 
+// listTreesQuery returns the query, and its arguments, which selects the
+// trees matching opts.
+func listTreesQuery(opts storage.ListTreesOptions) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	arg := func(arg interface{}) string {
+		args = append(args, arg)
+		return "?"
+	}
+	if !opts.IncludeDeleted {
+		conds = append(conds, nonDeletedCond)
+	}
+	if opts.AfterTreeID != 0 {
+		conds = append(conds, "TreeId > "+arg(opts.AfterTreeID))
+	}
+	keys := make([]string, 0, len(opts.Labels))
+	for k := range opts.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		conds = append(conds, fmt.Sprintf(
+			"TreeId IN (SELECT TreeId FROM TreeLabels WHERE LabelKey = %s AND LabelValue = %s)", arg(k), arg(opts.Labels[k])))
+	}
+
+	query := selectTrees
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY TreeId"
+	if opts.PageSize > 0 {
+		query += " LIMIT " + arg(opts.PageSize)
+	}
+	return query, args
+}
+
+// maxTreesPerQuery is the number of trees whose labels or keys are read by a
+// single query, which keeps its "TreeId IN (...)" condition well within the
+// database's limit on placeholders.
+var maxTreesPerQuery = 1000
+
+// byTreeID returns trees indexed by ID, along with the condition, and its
+// arguments, which selects their rows.
+func byTreeID(trees []*trillian.Tree) (map[int64]*trillian.Tree, string, []interface{}) {
+	byID := make(map[int64]*trillian.Tree, len(trees))
+	placeholders := make([]string, 0, len(trees))
+	args := make([]interface{}, 0, len(trees))
+	for _, tree := range trees {
+		byID[tree.TreeId] = tree
+		args = append(args, tree.TreeId)
+		placeholders = append(placeholders, "?")
+	}
+	return byID, "TreeId IN (" + strings.Join(placeholders, ", ") + ")", args
+}
+
+// readLabels reads the labels of trees from storage.
+func (t *adminTX) readLabels(ctx context.Context, trees []*trillian.Tree) error {
+	if len(trees) == 0 {
+		return nil
+	}
+	if len(trees) > maxTreesPerQuery {
+		if err := t.readLabels(ctx, trees[:maxTreesPerQuery]); err != nil {
+			return err
+		}
+		return t.readLabels(ctx, trees[maxTreesPerQuery:])
+	}
+	byID, cond, args := byTreeID(trees)
+	rows, err := t.tx.QueryContext(ctx, selectTreeLabels+" WHERE "+cond, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var treeID int64
+		var key, value string
+		if err := rows.Scan(&treeID, &key, &value); err != nil {
+			return err
+		}
+		tree, ok := byID[treeID]
+		if !ok {
+			continue
+		}
+		if tree.Labels == nil {
+			tree.Labels = make(map[string]string)
+		}
+		tree.Labels[key] = value
+	}
+	return rows.Err()
+}
+
+// readKeys reads the successor keys of trees from storage.
+func (t *adminTX) readKeys(ctx context.Context, trees []*trillian.Tree) error {
+	if len(trees) == 0 {
+		return nil
+	}
+	if len(trees) > maxTreesPerQuery {
+		if err := t.readKeys(ctx, trees[:maxTreesPerQuery]); err != nil {
+			return err
+		}
+		return t.readKeys(ctx, trees[maxTreesPerQuery:])
+	}
+	byID, cond, args := byTreeID(trees)
+	rows, err := t.tx.QueryContext(ctx, selectTreeKeys+" WHERE "+cond+" ORDER BY TreeId, StartTreeSize", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var treeID int64
+		var privateKey, publicKey []byte
+		key := &trillian.SigningKey{}
+		if err := rows.Scan(&treeID, &key.KeyId, &key.StartTreeSize, &privateKey, &publicKey); err != nil {
+			return err
+		}
+		tree, ok := byID[treeID]
+		if !ok {
+			continue
+		}
+		key.PrivateKey = &any.Any{}
+		if err := proto.Unmarshal(privateKey, key.PrivateKey); err != nil {
+			return fmt.Errorf("could not unmarshal PrivateKey of key %v: %v", key.KeyId, err)
+		}
+		key.PublicKey = &keyspb.PublicKey{Der: publicKey}
+		tree.KeyHistory = append(tree.KeyHistory, key)
+	}
+	return rows.Err()
+}
+
+// writeKeys replaces the successor keys of the tree treeID with keys.
+func (t *adminTX) writeKeys(ctx context.Context, treeID int64, keys []*trillian.SigningKey) error {
+	if _, err := t.tx.ExecContext(ctx, deleteTreeKeys, treeID); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	stmt, err := t.tx.PrepareContext(ctx, insertTreeKey)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, key := range keys {
+		privateKey, err := proto.Marshal(key.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("could not marshal PrivateKey of key %v: %v", key.KeyId, err)
+		}
+		if _, err := stmt.ExecContext(ctx, treeID, key.KeyId, key.StartTreeSize, privateKey, key.PublicKey.GetDer()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLabels replaces the labels of the tree treeID with labels.
+func (t *adminTX) writeLabels(ctx context.Context, treeID int64, labels map[string]string) error {
+	if _, err := t.tx.ExecContext(ctx, deleteTreeLabels, treeID); err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	stmt, err := t.tx.PrepareContext(ctx, insertTreeLabel)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for k, v := range labels {
+		if _, err := stmt.ExecContext(ctx, treeID, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
 	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
 		return nil, err
@@ -393,8 +595,9 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 			UpdateTimeMillis,
 			PrivateKey,
 			PublicKey,
-			MaxRootDurationMillis)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+			MaxRootDurationMillis,
+			SequencerConfig)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return nil, err
 	}
@@ -404,6 +607,10 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
 	}
+	sequencerConfig, err := marshalSequencerConfig(newTree.SequencerConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	_, err = insertTreeStmt.ExecContext(
 		ctx,
@@ -420,6 +627,7 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		privateKey,
 		newTree.PublicKey.GetDer(),
 		rootDuration/time.Millisecond,
+		sequencerConfig,
 	)
 	if err != nil {
 		return nil, err
@@ -457,6 +665,10 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		return nil, err
 	}
 
+	if err := t.writeLabels(ctx, newTree.TreeId, newTree.Labels); err != nil {
+		return nil, fmt.Errorf("failed to write labels: %v", err)
+	}
+
 	return &newTree, nil
 }
 
@@ -494,6 +706,10 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
 	}
+	sequencerConfig, err := marshalSequencerConfig(tree.SequencerConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	stmt, err := t.tx.PrepareContext(ctx, updateTreeSQL)
 	if err != nil {
@@ -510,9 +726,16 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		nowMillis,
 		rootDuration/time.Millisecond,
 		privateKey,
+		sequencerConfig,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
+	if err := t.writeLabels(ctx, tree.TreeId, tree.Labels); err != nil {
+		return nil, fmt.Errorf("failed to write labels: %v", err)
+	}
+	if err := t.writeKeys(ctx, tree.TreeId, tree.KeyHistory); err != nil {
+		return nil, fmt.Errorf("failed to write keys: %v", err)
+	}
 
 	return tree, nil
 }
@@ -587,3 +810,15 @@ func validateStorageSettings(tree *trillian.Tree) error {
 	}
 	return nil
 }
+
+// marshalSequencerConfig returns the serialized cfg, or nil if cfg is nil.
+func marshalSequencerConfig(cfg *trillian.SequencerConfig) ([]byte, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	b, err := proto.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal SequencerConfig: %v", err)
+	}
+	return b, nil
+}
@@ -126,7 +126,7 @@ func TestAdminTX_TreeWithNulls(t *testing.T) {
 		{
 			desc: "ListTrees",
 			fn: func(ctx context.Context, tx storage.AdminTX) error {
-				trees, err := tx.ListTrees(ctx, false /* includeDeleted */)
+				trees, err := tx.ListTrees(ctx, storage.ListTreesOptions{})
 				if err != nil {
 					return err
 				}
@@ -218,6 +218,40 @@ func TestAdminTX_HardDeleteTree(t *testing.T) {
 	}
 }
 
+func TestAdminTX_ListTreesReadsLabelsInChunks(t *testing.T) {
+	cleanTestDB(DB)
+	s := NewAdminStorage(DB)
+	ctx := context.Background()
+
+	defer func(n int) { maxTreesPerQuery = n }(maxTreesPerQuery)
+	maxTreesPerQuery = 2
+
+	wantLabels := make(map[int64]string)
+	for i := 0; i < 5; i++ {
+		n := fmt.Sprint(i)
+		tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+		tree.Labels = map[string]string{"n": n}
+		tree, err := storage.CreateTree(ctx, s, tree)
+		if err != nil {
+			t.Fatalf("CreateTree() returned err = %v", err)
+		}
+		wantLabels[tree.TreeId] = n
+	}
+
+	trees, err := storage.ListTrees(ctx, s, storage.ListTreesOptions{})
+	if err != nil {
+		t.Fatalf("ListTrees() returned err = %v", err)
+	}
+	if got, want := len(trees), len(wantLabels); got != want {
+		t.Fatalf("ListTrees() returned %v trees, want %v", got, want)
+	}
+	for _, tree := range trees {
+		if got, want := tree.Labels["n"], wantLabels[tree.TreeId]; got != want {
+			t.Errorf("ListTrees() returned tree %v with label n = %q, want %q", tree.TreeId, got, want)
+		}
+	}
+}
+
 func TestCheckDatabaseAccessible_Fails(t *testing.T) {
 	// Pass in a closed database to provoke a failure.
 	db := openTestDBOrDie()
@@ -69,7 +69,8 @@ const (
 	selectLeavesByMerkleHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
 			FROM LeafData l,SequencedLeafData s
 			WHERE l.LeafIdentityHash = s.LeafIdentityHash
-			AND s.MerkleLeafHash IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
+			AND s.MerkleLeafHash IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId
+			AND s.SequenceNumber >= ?`
 	// TODO(drysdale): rework the code so the dummy hash isn't needed (e.g. this assumes hash size is 32)
 	dummyMerkleLeafHash = "00000000000000000000000000000000"
 	// This statement returns a dummy Merkle leaf hash value (which must be
@@ -82,6 +83,8 @@ const (
 	// Same as above except with leaves ordered by sequence so we only incur this cost when necessary
 	orderBySequenceNumberSQL                     = " ORDER BY s.SequenceNumber"
 	selectLeavesByMerkleHashOrderedBySequenceSQL = selectLeavesByMerkleHashSQL + orderBySequenceNumberSQL
+	// Same as above with a limit on the number of leaves returned, for paging
+	selectLeavesByMerkleHashPageSQL = selectLeavesByMerkleHashOrderedBySequenceSQL + " LIMIT ?"
 
 	// Error code returned by driver when inserting a duplicate row
 	errNumDuplicate = 1062
@@ -158,7 +161,10 @@ func (m *mySQLLogStorage) getLeavesByIndexStmt(ctx context.Context, num int) (*s
 	return m.getStmt(ctx, selectLeavesByIndexSQL, num, "?", "?")
 }
 
-func (m *mySQLLogStorage) getLeavesByMerkleHashStmt(ctx context.Context, num int, orderBySequence bool) (*sql.Stmt, error) {
+func (m *mySQLLogStorage) getLeavesByMerkleHashStmt(ctx context.Context, num int, orderBySequence, limited bool) (*sql.Stmt, error) {
+	if limited {
+		return m.getStmt(ctx, selectLeavesByMerkleHashPageSQL, num, "?", "?")
+	}
 	if orderBySequence {
 		return m.getStmt(ctx, selectLeavesByMerkleHashOrderedBySequenceSQL, num, "?", "?")
 	}
@@ -339,10 +345,46 @@ func (t *logTreeTX) WriteRevision() int64 {
 
 func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
 	start := time.Now()
+	leaves, dq, err := t.selectQueuedLeaves(ctx, limit, cutoffTime)
+	if err != nil {
+		return nil, err
+	}
+	label := labelForTX(t)
+	selectDuration := time.Since(start)
+	observe(dequeueSelectLatency, selectDuration, label)
+
+	// The convention is that if leaf processing succeeds (by committing this tx)
+	// then the unsequenced entries for them are removed
+	if len(leaves) > 0 {
+		err = t.removeSequencedLeaves(ctx, dq)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	totalDuration := time.Since(start)
+	removeDuration := totalDuration - selectDuration
+	observe(dequeueRemoveLatency, removeDuration, label)
+	observe(dequeueLatency, totalDuration, label)
+	dequeuedCounter.Add(float64(len(leaves)), label)
+
+	return leaves, nil
+}
+
+// PeekQueuedLeaves implements storage.QueuePeeker.
+func (t *logTreeTX) PeekQueuedLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	leaves, _, err := t.selectQueuedLeaves(ctx, limit, cutoffTime)
+	return leaves, err
+}
+
+// selectQueuedLeaves reads up to limit leaves from the head of the queue,
+// along with the information needed to remove them from it.
+func (t *logTreeTX) selectQueuedLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, []dequeuedLeaf, error) {
 	stx, err := t.tx.PrepareContext(ctx, selectQueuedLeavesSQL)
 	if err != nil {
 		glog.Warningf("Failed to prepare dequeue select: %s", err)
-		return nil, err
+		return nil, nil, err
 	}
 	defer stx.Close()
 
@@ -351,7 +393,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	rows, err := stx.QueryContext(ctx, t.treeID, cutoffTime.UnixNano(), limit)
 	if err != nil {
 		glog.Warningf("Failed to select rows for work: %s", err)
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -359,11 +401,11 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 		leaf, dqInfo, err := t.dequeueLeaf(rows)
 		if err != nil {
 			glog.Warningf("Error dequeuing leaf: %v", err)
-			return nil, err
+			return nil, nil, err
 		}
 
 		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
-			return nil, errors.New("dequeued a leaf with incorrect hash size")
+			return nil, nil, errors.New("dequeued a leaf with incorrect hash size")
 		}
 
 		leaves = append(leaves, leaf)
@@ -371,29 +413,9 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	}
 
 	if rows.Err() != nil {
-		return nil, rows.Err()
-	}
-	label := labelForTX(t)
-	selectDuration := time.Since(start)
-	observe(dequeueSelectLatency, selectDuration, label)
-
-	// The convention is that if leaf processing succeeds (by committing this tx)
-	// then the unsequenced entries for them are removed
-	if len(leaves) > 0 {
-		err = t.removeSequencedLeaves(ctx, dq)
+		return nil, nil, rows.Err()
 	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	totalDuration := time.Since(start)
-	removeDuration := totalDuration - selectDuration
-	observe(dequeueRemoveLatency, removeDuration, label)
-	observe(dequeueLatency, totalDuration, label)
-	dequeuedCounter.Add(float64(len(leaves)), label)
-
-	return leaves, nil
+	return leaves, dq, nil
 }
 
 // sortLeavesForInsert returns a slice containing the passed in leaves sorted
@@ -739,13 +761,17 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 	return ret, nil
 }
 
-func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
-	tmpl, err := t.ls.getLeavesByMerkleHashStmt(ctx, len(leafHashes), orderBySequence)
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool, start, limit int64) ([]*trillian.LogLeaf, error) {
+	tmpl, err := t.ls.getLeavesByMerkleHashStmt(ctx, len(leafHashes), orderBySequence, limit > 0)
 	if err != nil {
 		return nil, err
 	}
 
-	return t.getLeavesByHashInternal(ctx, leafHashes, tmpl, "merkle")
+	args := []interface{}{start}
+	if limit > 0 {
+		args = append(args, limit)
+	}
+	return t.getLeavesByHashInternal(ctx, leafHashes, tmpl, "merkle", args...)
 }
 
 // getLeafDataByIdentityHash retrieves leaf data by LeafIdentityHash, returned
@@ -825,7 +851,9 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root trillian.Signed
 	return checkResultOkAndRowCountIs(res, err, 1)
 }
 
-func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, tmpl *sql.Stmt, desc string) ([]*trillian.LogLeaf, error) {
+// getLeavesByHashInternal runs tmpl with the leaf hashes and tree ID as
+// arguments, followed by any extra arguments the statement needs.
+func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, tmpl *sql.Stmt, desc string, extra ...interface{}) ([]*trillian.LogLeaf, error) {
 	stx := t.tx.StmtContext(ctx, tmpl)
 	defer stx.Close()
 
@@ -834,6 +862,7 @@ func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]
 		args = append(args, interface{}([]byte(hash)))
 	}
 	args = append(args, interface{}(t.treeID))
+	args = append(args, extra...)
 	rows, err := stx.QueryContext(ctx, args...)
 	if err != nil {
 		glog.Warningf("Query() %s hash = %v", desc, err)
@@ -42,7 +42,7 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
-var allTables = []string{"Unsequenced", "TreeHead", "SequencedLeafData", "LeafData", "Subtree", "TreeControl", "Trees", "MapLeaf", "MapHead"}
+var allTables = []string{"Unsequenced", "MapLeafQueue", "TreeHead", "SequencedLeafData", "LeafData", "Subtree", "TreeControl", "Trees", "MapLeaf", "MapHead"}
 
 // Must be 32 bytes to match sha256 length if it was a real hash
 var dummyHash = []byte("hashxxxxhashxxxxhashxxxxhashxxxx")
@@ -616,6 +616,64 @@ func TestDequeueLeavesTwoBatches(t *testing.T) {
 	}
 }
 
+func TestPeekQueuedLeaves(t *testing.T) {
+	cleanTestDB(DB)
+	tree := createTreeOrPanic(DB, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+	{
+		runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+			leaves := createTestLeaves(leavesToInsert, 20)
+			if _, err := tx.QueueLeaves(ctx, leaves, fakeDequeueCutoffTime); err != nil {
+				t.Fatalf("Failed to queue leaves: %v", err)
+			}
+			return nil
+		})
+	}
+
+	leavesToDequeue := 3
+	var peeked []*trillian.LogLeaf
+	{
+		// Peeking after a dequeue should see the rest of the queue, and leave it
+		// in place.
+		runLogTX(s, tree, t, func(ctx context.Context, tx2 storage.LogTreeTX) error {
+			dequeued, err := tx2.DequeueLeaves(ctx, leavesToDequeue, fakeDequeueCutoffTime)
+			if err != nil {
+				t.Fatalf("Failed to dequeue leaves: %v", err)
+			}
+			for i := 0; i < 2; i++ {
+				peeked, err = tx2.(storage.QueuePeeker).PeekQueuedLeaves(ctx, 99, fakeDequeueCutoffTime)
+				if err != nil {
+					t.Fatalf("Failed to peek leaves: %v", err)
+				}
+				if got, want := len(peeked), leavesToInsert-leavesToDequeue; got != want {
+					t.Fatalf("Peeked %d leaves but expected to get %d", got, want)
+				}
+			}
+			ensureAllLeavesDistinct(append(dequeued, peeked...), t)
+			return nil
+		})
+	}
+
+	{
+		// The peeked leaves are the ones dequeued next.
+		runLogTX(s, tree, t, func(ctx context.Context, tx3 storage.LogTreeTX) error {
+			leaves3, err := tx3.DequeueLeaves(ctx, 99, fakeDequeueCutoffTime)
+			if err != nil {
+				t.Fatalf("Failed to dequeue leaves: %v", err)
+			}
+			if len(leaves3) != len(peeked) {
+				t.Fatalf("Dequeued %d leaves but expected to get %d", len(leaves3), len(peeked))
+			}
+			for i, leaf := range leaves3 {
+				if !bytes.Equal(leaf.LeafIdentityHash, peeked[i].LeafIdentityHash) {
+					t.Errorf("Dequeued leaf %d with LeafIdentityHash %x, but peeked %x", i, leaf.LeafIdentityHash, peeked[i].LeafIdentityHash)
+				}
+			}
+			return nil
+		})
+	}
+}
+
 // Queues leaves and attempts to dequeue before the guard cutoff allows it. This should
 // return nothing. Then retry with an inclusive guard cutoff and ensure the leaves
 // are returned.
@@ -731,7 +789,7 @@ func TestGetLeavesByHashNotPresent(t *testing.T) {
 
 	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
 		hashes := [][]byte{[]byte("thisdoesn'texist")}
-		leaves, err := tx.GetLeavesByHash(ctx, hashes, false)
+		leaves, err := tx.GetLeavesByHash(ctx, hashes, false, 0, 0)
 		if err != nil {
 			t.Fatalf("Error getting leaves by hash: %v", err)
 		}
@@ -755,7 +813,7 @@ func TestGetLeavesByHash(t *testing.T) {
 
 	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
 		hashes := [][]byte{dummyHash}
-		leaves, err := tx.GetLeavesByHash(ctx, hashes, false)
+		leaves, err := tx.GetLeavesByHash(ctx, hashes, false, 0, 0)
 		if err != nil {
 			t.Fatalf("Unexpected error getting leaf by hash: %v", err)
 		}
@@ -767,6 +825,45 @@ func TestGetLeavesByHash(t *testing.T) {
 	})
 }
 
+func TestGetLeavesByHashPaging(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	tree := createTreeOrPanic(DB, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	// Two leaves with the same Merkle hash, plus one with a different hash
+	// which sorts before it.
+	createFakeLeaf(ctx, DB, tree.TreeId, dummyRawHash, dummyHash, []byte("a"), someExtraData, 5, t)
+	createFakeLeaf(ctx, DB, tree.TreeId, dummyRawHash2, dummyHash, []byte("b"), someExtraData, 3, t)
+	createFakeLeaf(ctx, DB, tree.TreeId, dummyHash3, dummyHash2, []byte("c"), someExtraData, 4, t)
+
+	for _, test := range []struct {
+		start, limit int64
+		want         []int64
+	}{
+		{start: 0, limit: 2, want: []int64{3, 4}},
+		{start: 4, limit: 2, want: []int64{4, 5}},
+		{start: 5, limit: 2, want: []int64{5}},
+		{start: 6, limit: 2, want: []int64{}},
+	} {
+		runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+			// Limited results are in sequence order even if not asked for.
+			leaves, err := tx.GetLeavesByHash(ctx, [][]byte{dummyHash, dummyHash2}, false, test.start, test.limit)
+			if err != nil {
+				t.Fatalf("GetLeavesByHash(%d, %d) = (_, %v), want (_, nil)", test.start, test.limit, err)
+			}
+			got := make([]int64, len(leaves))
+			for i, leaf := range leaves {
+				got[i] = leaf.LeafIndex
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeavesByHash(%d, %d) indices = %v, want %v", test.start, test.limit, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
 func TestGetLeafDataByIdentityHash(t *testing.T) {
 	ctx := context.Background()
 
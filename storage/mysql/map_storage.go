@@ -17,6 +17,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/hashers"
@@ -26,6 +27,8 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -51,6 +54,60 @@ const (
  ON t1.TreeId=t2.TreeId
  AND t1.KeyHash=t2.KeyHash
  AND t1.MapRevision=t2.maxrev`
+	selectMapLeavesAfterSQL = `
+ SELECT t1.KeyHash, t1.MapRevision, t1.LeafValue
+ FROM MapLeaf t1
+ INNER JOIN
+ (
+	SELECT TreeId, KeyHash, MAX(MapRevision) as maxrev
+	FROM MapLeaf t0
+	WHERE t0.TreeId = ? AND t0.KeyHash > ? AND t0.MapRevision <= ?
+	GROUP BY t0.TreeId, t0.KeyHash
+ ) t2
+ ON t1.TreeId=t2.TreeId
+ AND t1.KeyHash=t2.KeyHash
+ AND t1.MapRevision=t2.maxrev
+ WHERE LENGTH(t1.LeafValue) > 0
+ ORDER BY t1.KeyHash
+ LIMIT ?`
+	selectMapRootExistsSQL       = `SELECT MapRevision FROM MapHead WHERE TreeId = ? LIMIT 1`
+	deleteMapHeadsBeforeSQL      = `DELETE FROM MapHead WHERE TreeId = ? AND MapRevision < ?`
+	deleteSupersededMapLeavesSQL = `
+ DELETE t1 FROM MapLeaf t1
+ INNER JOIN
+ (
+	SELECT KeyHash, MAX(MapRevision) AS maxrev
+	FROM MapLeaf
+	WHERE TreeId = ? AND MapRevision <= ?
+	GROUP BY KeyHash
+ ) t2
+ ON t1.KeyHash = t2.KeyHash
+ WHERE t1.TreeId = ? AND t1.MapRevision < t2.maxrev`
+	deleteSupersededSubtreesSQL = `
+ DELETE t1 FROM Subtree t1
+ INNER JOIN
+ (
+	SELECT SubtreeId, MAX(SubtreeRevision) AS maxrev
+	FROM Subtree
+	WHERE TreeId = ? AND SubtreeRevision <= ?
+	GROUP BY SubtreeId
+ ) t2
+ ON t1.SubtreeId = t2.SubtreeId
+ WHERE t1.TreeId = ? AND t1.SubtreeRevision < t2.maxrev`
+	selectMapLeafHistorySQL = `
+ SELECT MapRevision, LeafValue
+ FROM MapLeaf
+ WHERE TreeId = ? AND KeyHash = ? AND MapRevision >= ? AND MapRevision <= ?
+ ORDER BY MapRevision`
+	insertMapLeafQueueSQL    = `REPLACE INTO MapLeafQueue(TreeId, QueueTimestampNanos, KeyHash, LeafValue) VALUES (?, ?, ?, ?)`
+	selectQueuedMapLeavesSQL = `
+ SELECT QueueTimestampNanos, KeyHash, LeafValue
+ FROM MapLeafQueue
+ WHERE TreeId = ? AND QueueTimestampNanos <= ?
+ ORDER BY QueueTimestampNanos, KeyHash
+ LIMIT ?
+ FOR UPDATE`
+	deleteQueuedMapLeafSQL = `DELETE FROM MapLeafQueue WHERE TreeId = ? AND QueueTimestampNanos = ? AND KeyHash = ?`
 )
 
 var defaultMapStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}
@@ -155,6 +212,62 @@ func (m *mySQLMapStorage) ReadWriteTransaction(ctx context.Context, tree *trilli
 	return tx.Commit()
 }
 
+// PruneMapRevisions implements storage.MapRevisionPruner.
+func (m *mySQLMapStorage) PruneMapRevisions(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Roots go first, so that revisions are never readable without their data.
+	for _, del := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{sql: deleteMapHeadsBeforeSQL, args: []interface{}{tree.TreeId, revision}},
+		{sql: deleteSupersededMapLeavesSQL, args: []interface{}{tree.TreeId, revision, tree.TreeId}},
+		{sql: deleteSupersededSubtreesSQL, args: []interface{}{tree.TreeId, revision, tree.TreeId}},
+	} {
+		res, err := tx.ExecContext(ctx, del.sql, del.args...)
+		if err != nil {
+			glog.Warningf("Failed to prune map %v before revision %v: %s", tree.TreeId, revision, err)
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			glog.V(2).Infof("%v: pruned %v rows before revision %v", tree.TreeId, n, revision)
+		}
+	}
+	return tx.Commit()
+}
+
+// QueueMapLeaves implements storage.MapLeafQueuer.
+func (m *mySQLMapStorage) QueueMapLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertMapLeafQueueSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, l := range leaves {
+		flatValue, err := proto.Marshal(l)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, tree.TreeId, queueTimestamp.UnixNano(), l.Index, flatValue); err != nil {
+			glog.Warningf("Failed to queue leaf %x for map %v: %s", l.Index, tree.TreeId, err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 type mapTreeTX struct {
 	treeTX
 	ms           *mySQLMapStorage
@@ -247,6 +360,125 @@ func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) (
 	return ret, nil
 }
 
+func (m *mapTreeTX) GetLeavesAfter(ctx context.Context, revision int64, keyHash []byte, limit int) ([]trillian.MapLeaf, error) {
+	if limit <= 0 {
+		return []trillian.MapLeaf{}, nil
+	}
+	if keyHash == nil {
+		// A NULL key hash would match no rows.
+		keyHash = []byte{}
+	}
+	stmt, err := m.tx.PrepareContext(ctx, selectMapLeavesAfterSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, m.treeID, keyHash, revision, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]trillian.MapLeaf, 0, limit)
+	for rows.Next() {
+		var mapKeyHash []byte
+		var mapRevision int64
+		var flatData []byte
+		if err := rows.Scan(&mapKeyHash, &mapRevision, &flatData); err != nil {
+			return nil, err
+		}
+		var mapLeaf trillian.MapLeaf
+		if err := proto.Unmarshal(flatData, &mapLeaf); err != nil {
+			return nil, err
+		}
+		mapLeaf.Index = mapKeyHash
+		ret = append(ret, mapLeaf)
+	}
+	return ret, rows.Err()
+}
+
+func (m *mapTreeTX) GetLeafHistory(ctx context.Context, keyHash []byte, fromRevision, toRevision int64) ([]storage.MapLeafRevision, error) {
+	stmt, err := m.tx.PrepareContext(ctx, selectMapLeafHistorySQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, m.treeID, keyHash, fromRevision, toRevision)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]storage.MapLeafRevision, 0)
+	for rows.Next() {
+		var mapRevision int64
+		var flatData []byte
+		if err := rows.Scan(&mapRevision, &flatData); err != nil {
+			return nil, err
+		}
+		var mapLeaf trillian.MapLeaf
+		if err := proto.Unmarshal(flatData, &mapLeaf); err != nil {
+			return nil, err
+		}
+		mapLeaf.Index = append([]byte(nil), keyHash...)
+		ret = append(ret, storage.MapLeafRevision{Revision: mapRevision, Leaf: mapLeaf})
+	}
+	return ret, rows.Err()
+}
+
+// DequeueMapLeaves implements storage.MapQueueTX.
+func (m *mapTreeTX) DequeueMapLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.MapLeaf, error) {
+	stmt, err := m.tx.PrepareContext(ctx, selectQueuedMapLeavesSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, m.treeID, cutoffTime.UnixNano(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type queueKey struct {
+		timestamp int64
+		keyHash   []byte
+	}
+	var keys []queueKey
+	leaves := make([]*trillian.MapLeaf, 0, limit)
+	for rows.Next() {
+		var k queueKey
+		var flatData []byte
+		if err := rows.Scan(&k.timestamp, &k.keyHash, &flatData); err != nil {
+			return nil, err
+		}
+		var mapLeaf trillian.MapLeaf
+		if err := proto.Unmarshal(flatData, &mapLeaf); err != nil {
+			return nil, err
+		}
+		mapLeaf.Index = k.keyHash
+		keys = append(keys, k)
+		leaves = append(leaves, &mapLeaf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	del, err := m.tx.PrepareContext(ctx, deleteQueuedMapLeafSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer del.Close()
+	for _, k := range keys {
+		if _, err := del.ExecContext(ctx, m.treeID, k.timestamp, k.keyHash); err != nil {
+			return nil, err
+		}
+	}
+	return leaves, nil
+}
+
 func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (trillian.SignedMapRoot, error) {
 	var timestamp, mapRevision int64
 	var rootHash, rootSignatureBytes []byte
@@ -260,16 +492,34 @@ func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (trill
 
 	err = stmt.QueryRowContext(ctx, m.treeID, revision).Scan(
 		&timestamp, &rootHash, &mapRevision, &rootSignatureBytes, &mapperMetaBytes)
-	if err != nil {
-		if revision == 0 {
-			return trillian.SignedMapRoot{}, storage.ErrTreeNeedsInit
-		}
+	if err == sql.ErrNoRows {
+		return trillian.SignedMapRoot{}, m.missingMapRootErr(ctx, revision)
+	} else if err != nil {
 		return trillian.SignedMapRoot{}, err
 	}
 	m.readRevision = mapRevision
 	return m.signedMapRoot(timestamp, mapRevision, rootHash, rootSignatureBytes, mapperMetaBytes)
 }
 
+// missingMapRootErr returns the error for a revision of the map which has no
+// root: ErrTreeNeedsInit if the map has no roots at all, or a NotFound error
+// if the revision hasn't been written yet or has been pruned.
+func (m *mapTreeTX) missingMapRootErr(ctx context.Context, revision int64) error {
+	stmt, err := m.tx.PrepareContext(ctx, selectMapRootExistsSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var mapRevision int64
+	if err := stmt.QueryRowContext(ctx, m.treeID).Scan(&mapRevision); err == sql.ErrNoRows {
+		return storage.ErrTreeNeedsInit
+	} else if err != nil {
+		return err
+	}
+	return status.Errorf(codes.NotFound, "no map root for revision %d", revision)
+}
+
 func (m *mapTreeTX) LatestSignedMapRoot(ctx context.Context) (trillian.SignedMapRoot, error) {
 	var timestamp, mapRevision int64
 	var rootHash, rootSignatureBytes []byte
@@ -325,6 +575,9 @@ func (m *mapTreeTX) StoreSignedMapRoot(ctx context.Context, root trillian.Signed
 
 	// TODO(al): store transactionLogHead too
 	res, err := stmt.ExecContext(ctx, m.treeID, r.TimestampNanos, r.RootHash, r.Revision, root.Signature, r.Metadata)
+	if isDuplicateErr(err) {
+		return storage.ErrMapRevisionExists
+	}
 
 	if err != nil {
 		glog.Warningf("Failed to store signed map root: %s", err)
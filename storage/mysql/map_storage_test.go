@@ -20,8 +20,11 @@ import (
 	"crypto"
 	"database/sql"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/google/trillian"
@@ -31,6 +34,8 @@ import (
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
 	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	tcrypto "github.com/google/trillian/crypto"
 	storageto "github.com/google/trillian/storage/testonly"
@@ -421,6 +426,247 @@ func TestMapSetGetMultipleRevisions(t *testing.T) {
 	}
 }
 
+func TestMapGetLeavesAfter(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, DB)
+	s := NewMapStorage(DB)
+
+	writes := []struct {
+		rev    int64
+		leaves map[string]string
+	}{
+		{rev: 1, leaves: map[string]string{"key2": "a", "key1": "b", "key3": "c"}},
+		// An empty value deletes key2.
+		{rev: 2, leaves: map[string]string{"key2": "", "key4": "d"}},
+	}
+	for _, w := range writes {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = w.rev
+			for k, v := range w.leaves {
+				if err := tx.Set(ctx, []byte(k), trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)}); err != nil {
+					t.Fatalf("Set(%s): %v", k, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, test := range []struct {
+		rev   int64
+		after string
+		limit int
+		want  []string
+	}{
+		{rev: 0, limit: 10},
+		{rev: 1, limit: 10, want: []string{"key1=b", "key2=a", "key3=c"}},
+		{rev: 1, after: "key1", limit: 10, want: []string{"key2=a", "key3=c"}},
+		{rev: 1, limit: 2, want: []string{"key1=b", "key2=a"}},
+		{rev: 1, after: "key3", limit: 10},
+		{rev: 2, limit: 10, want: []string{"key1=b", "key3=c", "key4=d"}},
+		{rev: 2, after: "key2", limit: 1, want: []string{"key3=c"}},
+		{rev: 2, limit: 0},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			leaves, err := tx.GetLeavesAfter(ctx, test.rev, []byte(test.after), test.limit)
+			if err != nil {
+				t.Fatalf("GetLeavesAfter(%d, %q, %d): %v", test.rev, test.after, test.limit, err)
+			}
+			var got []string
+			for _, l := range leaves {
+				got = append(got, fmt.Sprintf("%s=%s", l.Index, l.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeavesAfter(%d, %q, %d) = %v, want %v", test.rev, test.after, test.limit, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
+func TestMapGetLeafHistory(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, DB)
+	s := NewMapStorage(DB)
+
+	writes := []struct {
+		rev    int64
+		leaves map[string]string
+	}{
+		{rev: 1, leaves: map[string]string{"key1": "a", "key2": "x"}},
+		{rev: 2, leaves: map[string]string{"key1": "b"}},
+		{rev: 3, leaves: map[string]string{"key2": "y"}},
+		{rev: 4, leaves: map[string]string{"key1": "c"}},
+	}
+	for _, w := range writes {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = w.rev
+			for k, v := range w.leaves {
+				if err := tx.Set(ctx, []byte(k), trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)}); err != nil {
+					t.Fatalf("Set(%s): %v", k, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, test := range []struct {
+		key      string
+		from, to int64
+		want     []string
+	}{
+		{key: "key1", from: 0, to: 4, want: []string{"key1@1=a", "key1@2=b", "key1@4=c"}},
+		{key: "key1", from: 2, to: 3, want: []string{"key1@2=b"}},
+		{key: "key1", from: 3, to: 3},
+		{key: "key1", from: 3, to: 10, want: []string{"key1@4=c"}},
+		{key: "key2", from: 1, to: 4, want: []string{"key2@1=x", "key2@3=y"}},
+		{key: "key5", from: 0, to: 4},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			history, err := tx.GetLeafHistory(ctx, []byte(test.key), test.from, test.to)
+			if err != nil {
+				t.Fatalf("GetLeafHistory(%q, %d, %d): %v", test.key, test.from, test.to, err)
+			}
+			var got []string
+			for _, h := range history {
+				got = append(got, fmt.Sprintf("%s@%d=%s", h.Leaf.Index, h.Revision, h.Leaf.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetLeafHistory(%q, %d, %d) = %v, want %v", test.key, test.from, test.to, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
+func TestPruneMapRevisions(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, DB)
+	s := NewMapStorage(DB)
+
+	writes := []struct {
+		rev    int64
+		leaves map[string]string
+	}{
+		{rev: 1, leaves: map[string]string{"key1": "a", "key2": "x"}},
+		{rev: 2, leaves: map[string]string{"key1": "b"}},
+		{rev: 3, leaves: map[string]string{"key2": "y"}},
+		{rev: 4, leaves: map[string]string{"key1": "c"}},
+	}
+	for _, w := range writes {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = w.rev
+			for k, v := range w.leaves {
+				if err := tx.Set(ctx, []byte(k), trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)}); err != nil {
+					t.Fatalf("Set(%s): %v", k, err)
+				}
+			}
+			root := MustSignMapRoot(&types.MapRootV1{Revision: uint64(w.rev), RootHash: []byte(dummyHash)})
+			return tx.StoreSignedMapRoot(ctx, *root)
+		})
+	}
+
+	if err := s.(storage.MapRevisionPruner).PruneMapRevisions(ctx, tree, 3); err != nil {
+		t.Fatalf("PruneMapRevisions(): %v", err)
+	}
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		for rev := int64(0); rev <= 4; rev++ {
+			want := codes.OK
+			if rev < 3 {
+				want = codes.NotFound
+			}
+			if _, err := tx.GetSignedMapRoot(ctx, rev); status.Code(err) != want {
+				t.Errorf("GetSignedMapRoot(%d): %v, want code %v", rev, err, want)
+			}
+		}
+		for _, test := range []struct {
+			rev  int64
+			want []string
+		}{
+			{rev: 3, want: []string{"key1=b", "key2=y"}},
+			{rev: 4, want: []string{"key1=c", "key2=y"}},
+		} {
+			leaves, err := tx.Get(ctx, test.rev, [][]byte{[]byte("key1"), []byte("key2")})
+			if err != nil {
+				t.Fatalf("Get(%d): %v", test.rev, err)
+			}
+			var got []string
+			for _, l := range leaves {
+				got = append(got, fmt.Sprintf("%s=%s", l.Index, l.LeafValue))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Get(%d) = %v, want %v", test.rev, got, test.want)
+			}
+		}
+		return nil
+	})
+}
+
+func TestQueueMapLeaves(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	ctx := context.Background()
+	cleanTestDB(DB)
+	tree := createInitializedMapForTests(ctx, t, DB)
+	s := NewMapStorage(DB)
+
+	queuer := s.(storage.MapLeafQueuer)
+	base := time.Unix(1000, 0)
+	for _, q := range []struct {
+		ts     time.Duration
+		leaves map[string]string
+	}{
+		{ts: 0, leaves: map[string]string{"key1": "a", "key2": "x"}},
+		{ts: time.Second, leaves: map[string]string{"key1": "b"}},
+		{ts: 2 * time.Second, leaves: map[string]string{"key3": "z"}},
+	} {
+		var leaves []*trillian.MapLeaf
+		for k, v := range q.leaves {
+			leaves = append(leaves, &trillian.MapLeaf{Index: []byte(k), LeafValue: []byte(v)})
+		}
+		if err := queuer.QueueMapLeaves(ctx, tree, leaves, base.Add(q.ts)); err != nil {
+			t.Fatalf("QueueMapLeaves(): %v", err)
+		}
+	}
+
+	for _, test := range []struct {
+		limit  int
+		cutoff time.Duration
+		want   []string
+	}{
+		{limit: 10, cutoff: -time.Second},
+		{limit: 2, cutoff: time.Second, want: []string{"key1=a", "key2=x"}},
+		{limit: 10, cutoff: time.Second, want: []string{"key1=b"}},
+		{limit: 10, cutoff: time.Hour, want: []string{"key3=z"}},
+		{limit: 10, cutoff: time.Hour},
+	} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			leaves, err := tx.(storage.MapQueueTX).DequeueMapLeaves(ctx, test.limit, base.Add(test.cutoff))
+			if err != nil {
+				t.Fatalf("DequeueMapLeaves(%d, %v): %v", test.limit, test.cutoff, err)
+			}
+			var got []string
+			for _, l := range leaves {
+				got = append(got, fmt.Sprintf("%s=%s", l.Index, l.LeafValue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DequeueMapLeaves(%d, %v) = %v, want %v", test.limit, test.cutoff, got, test.want)
+			}
+			return nil
+		})
+	}
+}
+
 func TestGetSignedMapRootNotExist(t *testing.T) {
 	testdb.SkipIfNoMySQL(t)
 
@@ -552,8 +798,8 @@ func TestDuplicateSignedMapRoot(t *testing.T) {
 			t.Fatalf("Failed to store signed map root: %v", err)
 		}
 		// Shouldn't be able to do it again
-		if err := tx.StoreSignedMapRoot(ctx, *root); err == nil {
-			t.Fatal("Allowed duplicate signed map root")
+		if err := tx.StoreSignedMapRoot(ctx, *root); err != storage.ErrMapRevisionExists {
+			t.Fatalf("StoreSignedMapRoot() of duplicate root = %v, want %v", err, storage.ErrMapRevisionExists)
 		}
 		return nil
 	})
@@ -10,9 +10,9 @@ CREATE TABLE IF NOT EXISTS Trees(
   TreeId                BIGINT NOT NULL,
   TreeState             ENUM('ACTIVE', 'FROZEN', 'DRAINING') NOT NULL,
   TreeType              ENUM('LOG', 'MAP', 'PREORDERED_LOG') NOT NULL,
-  HashStrategy          ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256') NOT NULL,
-  HashAlgorithm         ENUM('SHA256') NOT NULL,
-  SignatureAlgorithm    ENUM('ECDSA', 'RSA') NOT NULL,
+  HashStrategy          ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'RFC6962_SHA384', 'RFC6962_SHA512') NOT NULL,
+  HashAlgorithm         ENUM('SHA256', 'SHA384', 'SHA512') NOT NULL,
+  SignatureAlgorithm    ENUM('ECDSA', 'RSA', 'ED25519') NOT NULL,
   DisplayName           VARCHAR(20),
   Description           VARCHAR(200),
   CreateTimeMillis      BIGINT NOT NULL,
@@ -22,6 +22,7 @@ CREATE TABLE IF NOT EXISTS Trees(
   PublicKey             MEDIUMBLOB NOT NULL,
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  SequencerConfig       MEDIUMBLOB,
   PRIMARY KEY(TreeId)
 );
 
@@ -36,6 +37,27 @@ CREATE TABLE IF NOT EXISTS TreeControl(
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
 
+-- Labels attached to trees, which ListTrees can select trees by.
+CREATE TABLE IF NOT EXISTS TreeLabels(
+  TreeId                BIGINT NOT NULL,
+  LabelKey              VARCHAR(63) NOT NULL,
+  LabelValue            VARCHAR(255) NOT NULL,
+  PRIMARY KEY(TreeId, LabelKey),
+  INDEX TreeLabelsByLabel(LabelKey, LabelValue),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Keys which succeed the original key of a tree in signing its log roots.
+CREATE TABLE IF NOT EXISTS TreeKeys(
+  TreeId                BIGINT NOT NULL,
+  KeyId                 BIGINT NOT NULL,
+  StartTreeSize         BIGINT NOT NULL,
+  PrivateKey            MEDIUMBLOB NOT NULL,
+  PublicKey             MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(TreeId, KeyId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS Subtree(
   TreeId               BIGINT NOT NULL,
   SubtreeId            VARBINARY(255) NOT NULL,
@@ -161,3 +183,15 @@ CREATE TABLE IF NOT EXISTS MapHead(
 
 CREATE UNIQUE INDEX MapHeadRevisionIdx
   ON MapHead(TreeId, MapRevision);
+
+-- A map leaf queued by QueueLeaves has a row in this table until a map signer
+-- writes it to a revision of the map.
+CREATE TABLE IF NOT EXISTS MapLeafQueue(
+  TreeId               BIGINT NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  KeyHash              VARBINARY(255) NOT NULL,
+  -- This is the serialized MapLeaf.
+  LeafValue            LONGBLOB NOT NULL,
+  PRIMARY KEY(TreeId, QueueTimestampNanos, KeyHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
@@ -0,0 +1,128 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify provides a LogStorage decorator which lets callers subscribe
+// to the SignedLogRoots stored through it.
+package notify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+)
+
+// subscriptionBuffer is the number of roots buffered for each subscriber.
+// Once a subscriber's buffer is full the oldest root is dropped in favour of
+// the newest, so slow subscribers skip roots rather than block writers.
+const subscriptionBuffer = 16
+
+// LogStorage is a storage.LogStorage which publishes every SignedLogRoot
+// stored with StoreSignedLogRoot to the subscribers of its tree, once the
+// transaction storing it has committed.
+//
+// Only roots written through this LogStorage are published, so roots stored by
+// other processes sharing the underlying storage will not be seen.
+type LogStorage struct {
+	storage.LogStorage
+
+	mu   sync.Mutex
+	subs map[int64]map[chan trillian.SignedLogRoot]bool
+}
+
+// NewLogStorage returns a LogStorage which wraps ls.
+func NewLogStorage(ls storage.LogStorage) *LogStorage {
+	return &LogStorage{
+		LogStorage: ls,
+		subs:       make(map[int64]map[chan trillian.SignedLogRoot]bool),
+	}
+}
+
+// Subscribe returns a channel which receives the roots stored for treeID from
+// now on, in the order they were stored. The returned function must be called
+// to cancel the subscription once the caller is done with it, after which the
+// channel is not written to.
+func (s *LogStorage) Subscribe(treeID int64) (<-chan trillian.SignedLogRoot, func()) {
+	ch := make(chan trillian.SignedLogRoot, subscriptionBuffer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs[treeID] == nil {
+		s.subs[treeID] = make(map[chan trillian.SignedLogRoot]bool)
+	}
+	s.subs[treeID][ch] = true
+
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs[treeID], ch)
+		if len(s.subs[treeID]) == 0 {
+			delete(s.subs, treeID)
+		}
+	}
+}
+
+// ReadWriteTransaction runs f in a transaction of the wrapped LogStorage, and
+// publishes the roots stored by f if the transaction commits.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	var roots []trillian.SignedLogRoot
+	err := s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		// f may be retried, so only keep the roots from the last attempt.
+		roots = nil
+		return f(ctx, &logTreeTX{LogTreeTX: tx, roots: &roots})
+	})
+	if err != nil {
+		return err
+	}
+	for _, root := range roots {
+		s.publish(tree.TreeId, root)
+	}
+	return nil
+}
+
+func (s *LogStorage) publish(treeID int64, root trillian.SignedLogRoot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs[treeID] {
+		for {
+			select {
+			case ch <- root:
+			default:
+				// Make room by dropping the oldest root. The subscriber may have
+				// received it in the meantime, so retry rather than assume.
+				select {
+				case <-ch:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+// logTreeTX records the roots stored in a transaction.
+type logTreeTX struct {
+	storage.LogTreeTX
+	roots *[]trillian.SignedLogRoot
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root trillian.SignedLogRoot) error {
+	if err := t.LogTreeTX.StoreSignedLogRoot(ctx, root); err != nil {
+		return err
+	}
+	*t.roots = append(*t.roots, root)
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+)
+
+func newTestStorage(ctx context.Context, t *testing.T) (*LogStorage, *trillian.Tree) {
+	t.Helper()
+	ms := memory.NewLogStorage(nil)
+	tree, err := storage.CreateTree(ctx, memory.NewAdminStorage(ms), testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	return NewLogStorage(ms), tree
+}
+
+func rootAt(t *testing.T, ts uint64) trillian.SignedLogRoot {
+	t.Helper()
+	logRoot, err := (&types.LogRootV1{TimestampNanos: ts, Revision: ts}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	return trillian.SignedLogRoot{LogRoot: logRoot, TimestampNanos: int64(ts), TreeRevision: int64(ts)}
+}
+
+func storeRoot(ctx context.Context, s *LogStorage, tree *trillian.Tree, root trillian.SignedLogRoot, txErr error) error {
+	return s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if err := tx.StoreSignedLogRoot(ctx, root); err != nil {
+			return err
+		}
+		return txErr
+	})
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	s, tree := newTestStorage(ctx, t)
+
+	ch, cancel := s.Subscribe(tree.TreeId)
+	defer cancel()
+	other, cancelOther := s.Subscribe(tree.TreeId + 1)
+	defer cancelOther()
+
+	if err := storeRoot(ctx, s, tree, rootAt(t, 1), nil); err != nil {
+		t.Fatalf("storeRoot(1): %v", err)
+	}
+	if err := storeRoot(ctx, s, tree, rootAt(t, 2), errors.New("rollback")); err == nil {
+		t.Fatal("storeRoot(2) succeeded, want error")
+	}
+	if err := storeRoot(ctx, s, tree, rootAt(t, 3), nil); err != nil {
+		t.Fatalf("storeRoot(3): %v", err)
+	}
+
+	// The root of the rolled back transaction must not be published.
+	for _, want := range []int64{1, 3} {
+		select {
+		case root := <-ch:
+			if got := root.TreeRevision; got != want {
+				t.Errorf("received root with revision %d, want %d", got, want)
+			}
+		default:
+			t.Fatalf("no root received, want revision %d", want)
+		}
+	}
+	select {
+	case root := <-ch:
+		t.Errorf("received unexpected root %v", root)
+	case root := <-other:
+		t.Errorf("received root %v for another tree", root)
+	default:
+	}
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	ctx := context.Background()
+	s, tree := newTestStorage(ctx, t)
+
+	ch, cancel := s.Subscribe(tree.TreeId)
+	cancel()
+	if err := storeRoot(ctx, s, tree, rootAt(t, 1), nil); err != nil {
+		t.Fatalf("storeRoot(): %v", err)
+	}
+	select {
+	case root := <-ch:
+		t.Errorf("received root %v after cancel", root)
+	default:
+	}
+	if got := len(s.subs); got != 0 {
+		t.Errorf("len(subs) = %d after cancel, want 0", got)
+	}
+}
+
+func TestSubscribeSlowSubscriber(t *testing.T) {
+	ctx := context.Background()
+	s, tree := newTestStorage(ctx, t)
+
+	ch, cancel := s.Subscribe(tree.TreeId)
+	defer cancel()
+
+	const n = subscriptionBuffer + 5
+	for i := uint64(1); i <= n; i++ {
+		if err := storeRoot(ctx, s, tree, rootAt(t, i), nil); err != nil {
+			t.Fatalf("storeRoot(%d): %v", i, err)
+		}
+	}
+
+	// The oldest roots are dropped, the newest are kept in order.
+	for want := int64(n - subscriptionBuffer + 1); want <= n; want++ {
+		root := <-ch
+		if got := root.TreeRevision; got != want {
+			t.Errorf("received root with revision %d, want %d", got, want)
+		}
+	}
+}
@@ -0,0 +1,811 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+	spb "github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSequenceIntervalSeconds = 60
+
+	nonDeletedCond  = "(Deleted IS NULL OR Deleted = 'false')"
+	nonDeletedWhere = " WHERE " + nonDeletedCond
+
+	selectTreeIDs           = "SELECT TreeId FROM Trees"
+	selectNonDeletedTreeIDs = selectTreeIDs + nonDeletedWhere
+
+	selectTrees = `
+		SELECT
+			TreeId,
+			TreeState,
+			TreeType,
+			HashStrategy,
+			HashAlgorithm,
+			SignatureAlgorithm,
+			DisplayName,
+			Description,
+			CreateTimeMillis,
+			UpdateTimeMillis,
+			PrivateKey,
+			PublicKey,
+			MaxRootDurationMillis,
+			Deleted,
+			DeleteTimeMillis,
+			SequencerConfig
+		FROM Trees`
+	selectTreeByID = selectTrees + " WHERE TreeId = $1"
+
+	updateTreeSQL = `UPDATE Trees
+		SET TreeState = $1, TreeType = $2, DisplayName = $3, Description = $4, UpdateTimeMillis = $5, MaxRootDurationMillis = $6, PrivateKey = $7, SequencerConfig = $8
+		WHERE TreeId = $9`
+
+	selectTreeLabels = "SELECT TreeId, LabelKey, LabelValue FROM TreeLabels"
+	insertTreeLabel  = "INSERT INTO TreeLabels(TreeId, LabelKey, LabelValue) VALUES($1, $2, $3)"
+	deleteTreeLabels = "DELETE FROM TreeLabels WHERE TreeId = $1"
+
+	selectTreeKeys = "SELECT TreeId, KeyId, StartTreeSize, PrivateKey, PublicKey FROM TreeKeys"
+	insertTreeKey  = "INSERT INTO TreeKeys(TreeId, KeyId, StartTreeSize, PrivateKey, PublicKey) VALUES($1, $2, $3, $4, $5)"
+	deleteTreeKeys = "DELETE FROM TreeKeys WHERE TreeId = $1"
+)
+
+// NewAdminStorage returns a PostgreSQL storage.AdminStorage implementation backed by DB.
+func NewAdminStorage(db *sql.DB) storage.AdminStorage {
+	return &postgresAdminStorage{db}
+}
+
+// postgresAdminStorage implements storage.AdminStorage
+type postgresAdminStorage struct {
+	db *sql.DB
+}
+
+func (s *postgresAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	return s.beginInternal(ctx)
+}
+
+func (s *postgresAdminStorage) beginInternal(ctx context.Context) (storage.AdminTX, error) {
+	tx, err := s.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTX{tx: tx}, nil
+}
+
+func (s *postgresAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	tx, err := s.beginInternal(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *postgresAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+type adminTX struct {
+	tx *sql.Tx
+
+	// mu guards *direct* reads/writes on closed, which happen only on
+	// Commit/Rollback/IsClosed/Close methods.
+	// We don't check closed on *all* methods (apart from the ones above),
+	// as we trust tx to keep tabs on its state (and consequently fail to do
+	// queries after closed).
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (t *adminTX) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return t.tx.Commit()
+}
+
+func (t *adminTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return t.tx.Rollback()
+}
+
+func (t *adminTX) IsClosed() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.closed
+}
+
+func (t *adminTX) Close() error {
+	// Acquire and release read lock manually, without defer, as if the txn
+	// is not closed Rollback() will attempt to acquire the rw lock.
+	t.mu.RLock()
+	closed := t.closed
+	t.mu.RUnlock()
+	if !closed {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	stmt, err := t.tx.PrepareContext(ctx, selectTreeByID)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	// GetTree is an entry point for most RPCs, let's provide somewhat nicer error messages.
+	tree, err := readTree(stmt.QueryRowContext(ctx, treeID))
+	switch {
+	case err == sql.ErrNoRows:
+		// ErrNoRows doesn't provide useful information, so we don't forward it.
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return nil, fmt.Errorf("error reading tree %v: %v", treeID, err)
+	}
+	if err := t.readLabels(ctx, []*trillian.Tree{tree}); err != nil {
+		return nil, fmt.Errorf("error reading labels of tree %v: %v", treeID, err)
+	}
+	if err := t.readKeys(ctx, []*trillian.Tree{tree}); err != nil {
+		return nil, fmt.Errorf("error reading keys of tree %v: %v", treeID, err)
+	}
+	return tree, nil
+}
+
+// There's no common interface between sql.Row and sql.Rows(!), so we have to
+// define one.
+type row interface {
+	Scan(dest ...interface{}) error
+}
+
+func readTree(row row) (*trillian.Tree, error) {
+	tree := &trillian.Tree{}
+
+	// Enums and Datetimes need an extra conversion step
+	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm string
+	var createMillis, updateMillis, maxRootDurationMillis int64
+	var displayName, description sql.NullString
+	var privateKey, publicKey, sequencerConfig []byte
+	var deleted sql.NullBool
+	var deleteMillis sql.NullInt64
+	err := row.Scan(
+		&tree.TreeId,
+		&treeState,
+		&treeType,
+		&hashStrategy,
+		&hashAlgorithm,
+		&signatureAlgorithm,
+		&displayName,
+		&description,
+		&createMillis,
+		&updateMillis,
+		&privateKey,
+		&publicKey,
+		&maxRootDurationMillis,
+		&deleted,
+		&deleteMillis,
+		&sequencerConfig,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	setNullStringIfValid(displayName, &tree.DisplayName)
+	setNullStringIfValid(description, &tree.Description)
+
+	// Convert all things!
+	if ts, ok := trillian.TreeState_value[treeState]; ok {
+		tree.TreeState = trillian.TreeState(ts)
+	} else {
+		return nil, fmt.Errorf("unknown TreeState: %v", treeState)
+	}
+	if tt, ok := trillian.TreeType_value[treeType]; ok {
+		tree.TreeType = trillian.TreeType(tt)
+	} else {
+		return nil, fmt.Errorf("unknown TreeType: %v", treeType)
+	}
+	if hs, ok := trillian.HashStrategy_value[hashStrategy]; ok {
+		tree.HashStrategy = trillian.HashStrategy(hs)
+	} else {
+		return nil, fmt.Errorf("unknown HashStrategy: %v", hashStrategy)
+	}
+	if ha, ok := spb.DigitallySigned_HashAlgorithm_value[hashAlgorithm]; ok {
+		tree.HashAlgorithm = spb.DigitallySigned_HashAlgorithm(ha)
+	} else {
+		return nil, fmt.Errorf("unknown HashAlgorithm: %v", hashAlgorithm)
+	}
+	if sa, ok := spb.DigitallySigned_SignatureAlgorithm_value[signatureAlgorithm]; ok {
+		tree.SignatureAlgorithm = spb.DigitallySigned_SignatureAlgorithm(sa)
+	} else {
+		return nil, fmt.Errorf("unknown SignatureAlgorithm: %v", signatureAlgorithm)
+	}
+
+	// Let's make sure we didn't mismatch any of the casts above
+	ok := tree.TreeState.String() == treeState
+	ok = ok && tree.TreeType.String() == treeType
+	ok = ok && tree.HashStrategy.String() == hashStrategy
+	ok = ok && tree.HashAlgorithm.String() == hashAlgorithm
+	ok = ok && tree.SignatureAlgorithm.String() == signatureAlgorithm
+	if !ok {
+		return nil, fmt.Errorf(
+			"mismatched enum: tree = %v, enums = [%v, %v, %v, %v, %v]",
+			tree,
+			treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm)
+	}
+
+	tree.CreateTime, err = ptypes.TimestampProto(fromMillisSinceEpoch(createMillis))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse create time: %v", err)
+	}
+	tree.UpdateTime, err = ptypes.TimestampProto(fromMillisSinceEpoch(updateMillis))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse update time: %v", err)
+	}
+	tree.MaxRootDuration = ptypes.DurationProto(time.Duration(maxRootDurationMillis * int64(time.Millisecond)))
+
+	tree.PrivateKey = &any.Any{}
+	if err := proto.Unmarshal(privateKey, tree.PrivateKey); err != nil {
+		return nil, fmt.Errorf("could not unmarshal PrivateKey: %v", err)
+	}
+	tree.PublicKey = &keyspb.PublicKey{Der: publicKey}
+
+	if len(sequencerConfig) > 0 {
+		tree.SequencerConfig = &trillian.SequencerConfig{}
+		if err := proto.Unmarshal(sequencerConfig, tree.SequencerConfig); err != nil {
+			return nil, fmt.Errorf("could not unmarshal SequencerConfig: %v", err)
+		}
+	}
+
+	tree.Deleted = deleted.Valid && deleted.Bool
+	if tree.Deleted && deleteMillis.Valid {
+		tree.DeleteTime, err = ptypes.TimestampProto(fromMillisSinceEpoch(deleteMillis.Int64))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse delete time: %v", err)
+		}
+	}
+
+	return tree, nil
+}
+
+// setNullStringIfValid assigns src to dest if src is Valid.
+func setNullStringIfValid(src sql.NullString, dest *string) {
+	if src.Valid {
+		*dest = src.String
+	}
+}
+
+func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	var query string
+	if includeDeleted {
+		query = selectTreeIDs
+	} else {
+		query = selectNonDeletedTreeIDs
+	}
+
+	stmt, err := t.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	treeIDs := []int64{}
+	var treeID int64
+	for rows.Next() {
+		if err := rows.Scan(&treeID); err != nil {
+			return nil, err
+		}
+		treeIDs = append(treeIDs, treeID)
+	}
+	return treeIDs, nil
+}
+
+func (t *adminTX) ListTrees(ctx context.Context, opts storage.ListTreesOptions) ([]*trillian.Tree, error) {
+	query, args := listTreesQuery(opts)
+	stmt, err := t.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	trees := []*trillian.Tree{}
+	for rows.Next() {
+		tree, err := readTree(rows)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := t.readLabels(ctx, trees); err != nil {
+		return nil, fmt.Errorf("error reading tree labels: %v", err)
+	}
+	if err := t.readKeys(ctx, trees); err != nil {
+		return nil, fmt.Errorf("error reading tree keys: %v", err)
+	}
+	return trees, nil
+}
+
+// listTreesQuery returns the query, and its arguments, which selects the
+// trees matching opts.
+func listTreesQuery(opts storage.ListTreesOptions) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	arg := func(arg interface{}) string {
+		args = append(args, arg)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if !opts.IncludeDeleted {
+		conds = append(conds, nonDeletedCond)
+	}
+	if opts.AfterTreeID != 0 {
+		conds = append(conds, "TreeId > "+arg(opts.AfterTreeID))
+	}
+	keys := make([]string, 0, len(opts.Labels))
+	for k := range opts.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		conds = append(conds, fmt.Sprintf(
+			"TreeId IN (SELECT TreeId FROM TreeLabels WHERE LabelKey = %s AND LabelValue = %s)", arg(k), arg(opts.Labels[k])))
+	}
+
+	query := selectTrees
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY TreeId"
+	if opts.PageSize > 0 {
+		query += " LIMIT " + arg(opts.PageSize)
+	}
+	return query, args
+}
+
+// maxTreesPerQuery is the number of trees whose labels or keys are read by a
+// single query, which keeps its "TreeId IN (...)" condition well within the
+// database's limit on placeholders.
+var maxTreesPerQuery = 1000
+
+// byTreeID returns trees indexed by ID, along with the condition, and its
+// arguments, which selects their rows.
+func byTreeID(trees []*trillian.Tree) (map[int64]*trillian.Tree, string, []interface{}) {
+	byID := make(map[int64]*trillian.Tree, len(trees))
+	placeholders := make([]string, 0, len(trees))
+	args := make([]interface{}, 0, len(trees))
+	for _, tree := range trees {
+		byID[tree.TreeId] = tree
+		args = append(args, tree.TreeId)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	return byID, "TreeId IN (" + strings.Join(placeholders, ", ") + ")", args
+}
+
+// readLabels reads the labels of trees from storage.
+func (t *adminTX) readLabels(ctx context.Context, trees []*trillian.Tree) error {
+	if len(trees) == 0 {
+		return nil
+	}
+	if len(trees) > maxTreesPerQuery {
+		if err := t.readLabels(ctx, trees[:maxTreesPerQuery]); err != nil {
+			return err
+		}
+		return t.readLabels(ctx, trees[maxTreesPerQuery:])
+	}
+	byID, cond, args := byTreeID(trees)
+	rows, err := t.tx.QueryContext(ctx, selectTreeLabels+" WHERE "+cond, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var treeID int64
+		var key, value string
+		if err := rows.Scan(&treeID, &key, &value); err != nil {
+			return err
+		}
+		tree, ok := byID[treeID]
+		if !ok {
+			continue
+		}
+		if tree.Labels == nil {
+			tree.Labels = make(map[string]string)
+		}
+		tree.Labels[key] = value
+	}
+	return rows.Err()
+}
+
+// readKeys reads the successor keys of trees from storage.
+func (t *adminTX) readKeys(ctx context.Context, trees []*trillian.Tree) error {
+	if len(trees) == 0 {
+		return nil
+	}
+	if len(trees) > maxTreesPerQuery {
+		if err := t.readKeys(ctx, trees[:maxTreesPerQuery]); err != nil {
+			return err
+		}
+		return t.readKeys(ctx, trees[maxTreesPerQuery:])
+	}
+	byID, cond, args := byTreeID(trees)
+	rows, err := t.tx.QueryContext(ctx, selectTreeKeys+" WHERE "+cond+" ORDER BY TreeId, StartTreeSize", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var treeID int64
+		var privateKey, publicKey []byte
+		key := &trillian.SigningKey{}
+		if err := rows.Scan(&treeID, &key.KeyId, &key.StartTreeSize, &privateKey, &publicKey); err != nil {
+			return err
+		}
+		tree, ok := byID[treeID]
+		if !ok {
+			continue
+		}
+		key.PrivateKey = &any.Any{}
+		if err := proto.Unmarshal(privateKey, key.PrivateKey); err != nil {
+			return fmt.Errorf("could not unmarshal PrivateKey of key %v: %v", key.KeyId, err)
+		}
+		key.PublicKey = &keyspb.PublicKey{Der: publicKey}
+		tree.KeyHistory = append(tree.KeyHistory, key)
+	}
+	return rows.Err()
+}
+
+// writeKeys replaces the successor keys of the tree treeID with keys.
+func (t *adminTX) writeKeys(ctx context.Context, treeID int64, keys []*trillian.SigningKey) error {
+	if _, err := t.tx.ExecContext(ctx, deleteTreeKeys, treeID); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	stmt, err := t.tx.PrepareContext(ctx, insertTreeKey)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, key := range keys {
+		privateKey, err := proto.Marshal(key.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("could not marshal PrivateKey of key %v: %v", key.KeyId, err)
+		}
+		if _, err := stmt.ExecContext(ctx, treeID, key.KeyId, key.StartTreeSize, privateKey, key.PublicKey.GetDer()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLabels replaces the labels of the tree treeID with labels.
+func (t *adminTX) writeLabels(ctx context.Context, treeID int64, labels map[string]string) error {
+	if _, err := t.tx.ExecContext(ctx, deleteTreeLabels, treeID); err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	stmt, err := t.tx.PrepareContext(ctx, insertTreeLabel)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for k, v := range labels {
+		if _, err := stmt.ExecContext(ctx, treeID, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	id, err := storage.NewTreeID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the time truncated-to-millis throughout, as that's what's stored.
+	nowMillis := toMillisSinceEpoch(time.Now())
+	now := fromMillisSinceEpoch(nowMillis)
+
+	newTree := *tree
+	newTree.TreeId = id
+	newTree.CreateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build create time: %v", err)
+	}
+	newTree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	rootDuration, err := ptypes.Duration(newTree.MaxRootDuration)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse MaxRootDuration: %v", err)
+	}
+
+	insertTreeStmt, err := t.tx.PrepareContext(
+		ctx,
+		`INSERT INTO Trees(
+			TreeId,
+			TreeState,
+			TreeType,
+			HashStrategy,
+			HashAlgorithm,
+			SignatureAlgorithm,
+			DisplayName,
+			Description,
+			CreateTimeMillis,
+			UpdateTimeMillis,
+			PrivateKey,
+			PublicKey,
+			MaxRootDurationMillis,
+			SequencerConfig)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`)
+	if err != nil {
+		return nil, err
+	}
+	defer insertTreeStmt.Close()
+
+	privateKey, err := proto.Marshal(newTree.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
+	}
+	sequencerConfig, err := marshalSequencerConfig(newTree.SequencerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = insertTreeStmt.ExecContext(
+		ctx,
+		newTree.TreeId,
+		newTree.TreeState.String(),
+		newTree.TreeType.String(),
+		newTree.HashStrategy.String(),
+		newTree.HashAlgorithm.String(),
+		newTree.SignatureAlgorithm.String(),
+		newTree.DisplayName,
+		newTree.Description,
+		nowMillis,
+		nowMillis,
+		privateKey,
+		newTree.PublicKey.GetDer(),
+		rootDuration/time.Millisecond,
+		sequencerConfig,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	insertControlStmt, err := t.tx.PrepareContext(
+		ctx,
+		`INSERT INTO TreeControl(
+			TreeId,
+			SigningEnabled,
+			SequencingEnabled,
+			SequenceIntervalSeconds)
+		VALUES($1, $2, $3, $4)`)
+	if err != nil {
+		return nil, err
+	}
+	defer insertControlStmt.Close()
+	_, err = insertControlStmt.ExecContext(
+		ctx,
+		newTree.TreeId,
+		true, /* SigningEnabled */
+		true, /* SequencingEnabled */
+		defaultSequenceIntervalSeconds,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.writeLabels(ctx, newTree.TreeId, newTree.Labels); err != nil {
+		return nil, fmt.Errorf("failed to write labels: %v", err)
+	}
+
+	return &newTree, nil
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	tree, err := t.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeUpdate := *tree
+	updateFunc(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, &beforeUpdate, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	// TODO(pavelkalinnikov): When switching TreeType from PREORDERED_LOG to LOG,
+	// ensure all entries in SequencedLeafData are integrated.
+
+	// Use the time truncated-to-millis throughout, as that's what's stored.
+	nowMillis := toMillisSinceEpoch(time.Now())
+	now := fromMillisSinceEpoch(nowMillis)
+	tree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	rootDuration, err := ptypes.Duration(tree.MaxRootDuration)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse MaxRootDuration: %v", err)
+	}
+
+	privateKey, err := proto.Marshal(tree.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
+	}
+	sequencerConfig, err := marshalSequencerConfig(tree.SequencerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := t.tx.PrepareContext(ctx, updateTreeSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(
+		ctx,
+		tree.TreeState.String(),
+		tree.TreeType.String(),
+		tree.DisplayName,
+		tree.Description,
+		nowMillis,
+		rootDuration/time.Millisecond,
+		privateKey,
+		sequencerConfig,
+		tree.TreeId); err != nil {
+		return nil, err
+	}
+	if err := t.writeLabels(ctx, tree.TreeId, tree.Labels); err != nil {
+		return nil, fmt.Errorf("failed to write labels: %v", err)
+	}
+	if err := t.writeKeys(ctx, tree.TreeId, tree.KeyHistory); err != nil {
+		return nil, fmt.Errorf("failed to write keys: %v", err)
+	}
+
+	return tree, nil
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, true /* deleted */, toMillisSinceEpoch(time.Now()) /* deleteTimeMillis */)
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, false /* deleted */, nil /* deleteTimeMillis */)
+}
+
+// updateDeleted updates the Deleted and DeleteTimeMillis fields of the specified tree.
+// deleteTimeMillis must be either an int64 (in millis since epoch) or nil.
+func (t *adminTX) updateDeleted(ctx context.Context, treeID int64, deleted bool, deleteTimeMillis interface{}) (*trillian.Tree, error) {
+	if err := validateDeleted(ctx, t.tx, treeID, !deleted); err != nil {
+		return nil, err
+	}
+	if _, err := t.tx.ExecContext(
+		ctx,
+		"UPDATE Trees SET Deleted = $1, DeleteTimeMillis = $2 WHERE TreeId = $3",
+		deleted, deleteTimeMillis, treeID); err != nil {
+		return nil, err
+	}
+	return t.GetTree(ctx, treeID)
+}
+
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	if err := validateDeleted(ctx, t.tx, treeID, true /* wantDeleted */); err != nil {
+		return err
+	}
+
+	_, err := t.tx.ExecContext(ctx, "DELETE FROM Trees WHERE TreeId = $1", treeID)
+	return err
+}
+
+func validateDeleted(ctx context.Context, tx *sql.Tx, treeID int64, wantDeleted bool) error {
+	var nullDeleted sql.NullBool
+	switch err := tx.QueryRowContext(ctx, "SELECT Deleted FROM Trees WHERE TreeId = $1", treeID).Scan(&nullDeleted); {
+	case err == sql.ErrNoRows:
+		return status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return err
+	}
+
+	switch deleted := nullDeleted.Valid && nullDeleted.Bool; {
+	case wantDeleted && !deleted:
+		return status.Errorf(codes.FailedPrecondition, "tree %v is not soft deleted", treeID)
+	case !wantDeleted && deleted:
+		return status.Errorf(codes.FailedPrecondition, "tree %v already soft deleted", treeID)
+	}
+	return nil
+}
+
+func toMillisSinceEpoch(t time.Time) int64 {
+	return t.UnixNano() / 1000000
+}
+
+func fromMillisSinceEpoch(ts int64) time.Time {
+	secs := int64(ts / 1000)
+	msecs := int64(ts % 1000)
+	return time.Unix(secs, msecs*1000000)
+}
+
+func validateStorageSettings(tree *trillian.Tree) error {
+	if tree.StorageSettings != nil {
+		return fmt.Errorf("storage_settings not supported, but got %v", tree.StorageSettings)
+	}
+	return nil
+}
+
+// marshalSequencerConfig returns the serialized cfg, or nil if cfg is nil.
+func marshalSequencerConfig(cfg *trillian.SequencerConfig) ([]byte, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	b, err := proto.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal SequencerConfig: %v", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,572 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/types"
+	"github.com/lib/pq"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	insertMapHeadSQL = `INSERT INTO MapHead(TreeId, MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData)
+	VALUES($1, $2, $3, $4, $5, $6)` + onConflictDoNothingSQL
+	selectLatestSignedMapRootSQL = `SELECT MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData
+		 FROM MapHead WHERE TreeId=$1
+		 ORDER BY MapHeadTimestamp DESC LIMIT 1`
+	selectGetSignedMapRootSQL = `SELECT MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData
+		 FROM MapHead WHERE TreeId=$1 AND MapRevision=$2`
+	insertMapLeafSQL = `INSERT INTO MapLeaf(TreeId, KeyHash, MapRevision, LeafValue) VALUES ($1, $2, $3, $4)` + onConflictDoNothingSQL
+	selectMapLeafSQL = `
+ SELECT t1.KeyHash, t1.MapRevision, t1.LeafValue
+ FROM MapLeaf t1
+ INNER JOIN
+ (
+	SELECT TreeId, KeyHash, MAX(MapRevision) as maxrev
+	FROM MapLeaf t0
+	WHERE t0.KeyHash = ANY($1) AND
+	      t0.TreeId = $2 AND t0.MapRevision <= $3
+	GROUP BY t0.TreeId, t0.KeyHash
+ ) t2
+ ON t1.TreeId=t2.TreeId
+ AND t1.KeyHash=t2.KeyHash
+ AND t1.MapRevision=t2.maxrev`
+	selectMapLeavesAfterSQL = `
+ SELECT t1.KeyHash, t1.MapRevision, t1.LeafValue
+ FROM MapLeaf t1
+ INNER JOIN
+ (
+	SELECT TreeId, KeyHash, MAX(MapRevision) as maxrev
+	FROM MapLeaf t0
+	WHERE t0.TreeId = $1 AND t0.KeyHash > $2 AND t0.MapRevision <= $3
+	GROUP BY t0.TreeId, t0.KeyHash
+ ) t2
+ ON t1.TreeId=t2.TreeId
+ AND t1.KeyHash=t2.KeyHash
+ AND t1.MapRevision=t2.maxrev
+ WHERE LENGTH(t1.LeafValue) > 0
+ ORDER BY t1.KeyHash
+ LIMIT $4`
+	selectMapRootExistsSQL       = `SELECT MapRevision FROM MapHead WHERE TreeId = $1 LIMIT 1`
+	deleteMapHeadsBeforeSQL      = `DELETE FROM MapHead WHERE TreeId = $1 AND MapRevision < $2`
+	deleteSupersededMapLeavesSQL = `
+ DELETE FROM MapLeaf t1
+ USING
+ (
+	SELECT KeyHash, MAX(MapRevision) AS maxrev
+	FROM MapLeaf
+	WHERE TreeId = $1 AND MapRevision <= $2
+	GROUP BY KeyHash
+ ) t2
+ WHERE t1.TreeId = $1 AND t1.KeyHash = t2.KeyHash AND t1.MapRevision < t2.maxrev`
+	deleteSupersededSubtreesSQL = `
+ DELETE FROM Subtree t1
+ USING
+ (
+	SELECT SubtreeId, MAX(SubtreeRevision) AS maxrev
+	FROM Subtree
+	WHERE TreeId = $1 AND SubtreeRevision <= $2
+	GROUP BY SubtreeId
+ ) t2
+ WHERE t1.TreeId = $1 AND t1.SubtreeId = t2.SubtreeId AND t1.SubtreeRevision < t2.maxrev`
+	selectMapLeafHistorySQL = `
+ SELECT MapRevision, LeafValue
+ FROM MapLeaf
+ WHERE TreeId = $1 AND KeyHash = $2 AND MapRevision >= $3 AND MapRevision <= $4
+ ORDER BY MapRevision`
+	insertMapLeafQueueSQL = `INSERT INTO MapLeafQueue(TreeId, QueueTimestampNanos, KeyHash, LeafValue) VALUES ($1, $2, $3, $4)
+ ON CONFLICT (TreeId, QueueTimestampNanos, KeyHash) DO UPDATE SET LeafValue = EXCLUDED.LeafValue`
+	selectQueuedMapLeavesSQL = `
+ SELECT QueueTimestampNanos, KeyHash, LeafValue
+ FROM MapLeafQueue
+ WHERE TreeId = $1 AND QueueTimestampNanos <= $2
+ ORDER BY QueueTimestampNanos, KeyHash
+ LIMIT $3
+ FOR UPDATE`
+	deleteQueuedMapLeafSQL = `DELETE FROM MapLeafQueue WHERE TreeId = $1 AND QueueTimestampNanos = $2 AND KeyHash = $3`
+)
+
+var defaultMapStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}
+
+type postgresMapStorage struct {
+	*postgresTreeStorage
+	admin storage.AdminStorage
+}
+
+// NewMapStorage creates a storage.MapStorage instance for the specified PostgreSQL database.
+// It assumes storage.AdminStorage is backed by the same PostgreSQL database as well.
+func NewMapStorage(db *sql.DB) storage.MapStorage {
+	return &postgresMapStorage{
+		admin:               NewAdminStorage(db),
+		postgresTreeStorage: newTreeStorage(db),
+	}
+}
+
+func (m *postgresMapStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
+type readOnlyMapTX struct {
+	*sql.Tx
+}
+
+func (m *postgresMapStorage) Snapshot(ctx context.Context) (storage.ReadOnlyMapTX, error) {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyMapTX{tx}, nil
+}
+
+func (t *readOnlyMapTX) Close() error {
+	if err := t.Rollback(); err != nil && err != sql.ErrTxDone {
+		glog.Warningf("Rollback error on Close(): %v", err)
+		return err
+	}
+	return nil
+}
+
+func (m *postgresMapStorage) begin(ctx context.Context, tree *trillian.Tree, readonly bool) (storage.MapTreeTX, error) {
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stCache := cache.NewMapSubtreeCache(defaultMapStrata, tree.TreeId, hasher)
+	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
+	if err != nil {
+		return nil, err
+	}
+
+	mtx := &mapTreeTX{
+		treeTX:       ttx,
+		ms:           m,
+		readRevision: -1,
+	}
+
+	if readonly {
+		// readRevision will be set later, by the first
+		// GetSignedMapRoot/LatestSignedMapRoot operation.
+		return mtx, nil
+	}
+
+	// A read-write transaction needs to know the current revision
+	// so it can write at revision+1.
+	root, err := mtx.LatestSignedMapRoot(ctx)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		mtx.Rollback()
+		return nil, err
+	}
+	if err == storage.ErrTreeNeedsInit {
+		return mtx, err
+	}
+
+	var mr types.MapRootV1
+	if err := mr.UnmarshalBinary(root.MapRoot); err != nil {
+		mtx.Rollback()
+		return nil, err
+	}
+
+	mtx.readRevision = int64(mr.Revision)
+	mtx.treeTX.writeRevision = int64(mr.Revision) + 1
+	return mtx, nil
+}
+
+func (m *postgresMapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	return m.begin(ctx, tree, true /* readonly */)
+}
+
+func (m *postgresMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	tx, err := m.begin(ctx, tree, false /* readonly */)
+	if tx != nil {
+		defer tx.Close()
+	}
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PruneMapRevisions implements storage.MapRevisionPruner.
+func (m *postgresMapStorage) PruneMapRevisions(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Roots go first, so that revisions are never readable without their data.
+	for _, del := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{sql: deleteMapHeadsBeforeSQL, args: []interface{}{tree.TreeId, revision}},
+		{sql: deleteSupersededMapLeavesSQL, args: []interface{}{tree.TreeId, revision}},
+		{sql: deleteSupersededSubtreesSQL, args: []interface{}{tree.TreeId, revision}},
+	} {
+		res, err := tx.ExecContext(ctx, del.sql, del.args...)
+		if err != nil {
+			glog.Warningf("Failed to prune map %v before revision %v: %s", tree.TreeId, revision, err)
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			glog.V(2).Infof("%v: pruned %v rows before revision %v", tree.TreeId, n, revision)
+		}
+	}
+	return tx.Commit()
+}
+
+// QueueMapLeaves implements storage.MapLeafQueuer.
+func (m *postgresMapStorage) QueueMapLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertMapLeafQueueSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, l := range leaves {
+		flatValue, err := proto.Marshal(l)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, tree.TreeId, queueTimestamp.UnixNano(), l.Index, flatValue); err != nil {
+			glog.Warningf("Failed to queue leaf %x for map %v: %s", l.Index, tree.TreeId, err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+type mapTreeTX struct {
+	treeTX
+	ms           *postgresMapStorage
+	readRevision int64
+}
+
+func (m *mapTreeTX) ReadRevision() int64 {
+	return int64(m.readRevision)
+}
+
+func (m *mapTreeTX) WriteRevision() int64 {
+	return m.treeTX.writeRevision
+}
+
+func (m *mapTreeTX) Set(ctx context.Context, keyHash []byte, value trillian.MapLeaf) error {
+	// TODO(al): consider storing some sort of value which represents the group of keys being set in this Tx.
+	//           That way, if this attempt partially fails (i.e. because some subset of the in-the-future Merkle
+	//           nodes do get written), we can enforce that future map update attempts are a complete replay of
+	//           the failed set.
+	flatValue, err := proto.Marshal(&value)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := m.tx.PrepareContext(ctx, insertMapLeafSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, m.treeID, keyHash, m.writeRevision, flatValue)
+	return checkResultOkAndRowCountIs(res, err, 1)
+}
+
+// Get returns a list of map leaves indicated by indexes.
+// If an index is not found, no corresponding entry is returned.
+// Each MapLeaf.Index is overwritten with the index the leaf was found at.
+func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) ([]trillian.MapLeaf, error) {
+	// If no indexes are requested, return an empty set.
+	if len(indexes) == 0 {
+		return []trillian.MapLeaf{}, nil
+	}
+	rows, err := m.tx.QueryContext(ctx, selectMapLeafSQL, pq.ByteaArray(indexes), m.treeID, revision)
+	// It's possible there are no values for any of these keys yet
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]trillian.MapLeaf, 0, len(indexes))
+	for rows.Next() {
+		var mapKeyHash []byte
+		var mapRevision int64
+		var flatData []byte
+		err = rows.Scan(&mapKeyHash, &mapRevision, &flatData)
+		if err != nil {
+			return nil, err
+		}
+		if len(flatData) == 0 {
+			continue
+		}
+		var mapLeaf trillian.MapLeaf
+		err = proto.Unmarshal(flatData, &mapLeaf)
+		if err != nil {
+			return nil, err
+		}
+		mapLeaf.Index = mapKeyHash
+		ret = append(ret, mapLeaf)
+	}
+	return ret, rows.Err()
+}
+
+func (m *mapTreeTX) GetLeavesAfter(ctx context.Context, revision int64, keyHash []byte, limit int) ([]trillian.MapLeaf, error) {
+	if limit <= 0 {
+		return []trillian.MapLeaf{}, nil
+	}
+	if keyHash == nil {
+		// A NULL key hash would match no rows.
+		keyHash = []byte{}
+	}
+	stmt, err := m.tx.PrepareContext(ctx, selectMapLeavesAfterSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, m.treeID, keyHash, revision, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]trillian.MapLeaf, 0, limit)
+	for rows.Next() {
+		var mapKeyHash []byte
+		var mapRevision int64
+		var flatData []byte
+		if err := rows.Scan(&mapKeyHash, &mapRevision, &flatData); err != nil {
+			return nil, err
+		}
+		var mapLeaf trillian.MapLeaf
+		if err := proto.Unmarshal(flatData, &mapLeaf); err != nil {
+			return nil, err
+		}
+		mapLeaf.Index = mapKeyHash
+		ret = append(ret, mapLeaf)
+	}
+	return ret, rows.Err()
+}
+
+func (m *mapTreeTX) GetLeafHistory(ctx context.Context, keyHash []byte, fromRevision, toRevision int64) ([]storage.MapLeafRevision, error) {
+	stmt, err := m.tx.PrepareContext(ctx, selectMapLeafHistorySQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, m.treeID, keyHash, fromRevision, toRevision)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]storage.MapLeafRevision, 0)
+	for rows.Next() {
+		var mapRevision int64
+		var flatData []byte
+		if err := rows.Scan(&mapRevision, &flatData); err != nil {
+			return nil, err
+		}
+		var mapLeaf trillian.MapLeaf
+		if err := proto.Unmarshal(flatData, &mapLeaf); err != nil {
+			return nil, err
+		}
+		mapLeaf.Index = append([]byte(nil), keyHash...)
+		ret = append(ret, storage.MapLeafRevision{Revision: mapRevision, Leaf: mapLeaf})
+	}
+	return ret, rows.Err()
+}
+
+// DequeueMapLeaves implements storage.MapQueueTX.
+func (m *mapTreeTX) DequeueMapLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.MapLeaf, error) {
+	stmt, err := m.tx.PrepareContext(ctx, selectQueuedMapLeavesSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, m.treeID, cutoffTime.UnixNano(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type queueKey struct {
+		timestamp int64
+		keyHash   []byte
+	}
+	var keys []queueKey
+	leaves := make([]*trillian.MapLeaf, 0, limit)
+	for rows.Next() {
+		var k queueKey
+		var flatData []byte
+		if err := rows.Scan(&k.timestamp, &k.keyHash, &flatData); err != nil {
+			return nil, err
+		}
+		var mapLeaf trillian.MapLeaf
+		if err := proto.Unmarshal(flatData, &mapLeaf); err != nil {
+			return nil, err
+		}
+		mapLeaf.Index = k.keyHash
+		keys = append(keys, k)
+		leaves = append(leaves, &mapLeaf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	del, err := m.tx.PrepareContext(ctx, deleteQueuedMapLeafSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer del.Close()
+	for _, k := range keys {
+		if _, err := del.ExecContext(ctx, m.treeID, k.timestamp, k.keyHash); err != nil {
+			return nil, err
+		}
+	}
+	return leaves, nil
+}
+
+func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (trillian.SignedMapRoot, error) {
+	var timestamp, mapRevision int64
+	var rootHash, rootSignatureBytes []byte
+	var mapperMetaBytes []byte
+
+	stmt, err := m.tx.PrepareContext(ctx, selectGetSignedMapRootSQL)
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, m.treeID, revision).Scan(
+		&timestamp, &rootHash, &mapRevision, &rootSignatureBytes, &mapperMetaBytes)
+	if err == sql.ErrNoRows {
+		return trillian.SignedMapRoot{}, m.missingMapRootErr(ctx, revision)
+	} else if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	m.readRevision = mapRevision
+	return m.signedMapRoot(timestamp, mapRevision, rootHash, rootSignatureBytes, mapperMetaBytes)
+}
+
+// missingMapRootErr returns the error for a revision of the map which has no
+// root: ErrTreeNeedsInit if the map has no roots at all, or a NotFound error
+// if the revision hasn't been written yet or has been pruned.
+func (m *mapTreeTX) missingMapRootErr(ctx context.Context, revision int64) error {
+	stmt, err := m.tx.PrepareContext(ctx, selectMapRootExistsSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var mapRevision int64
+	if err := stmt.QueryRowContext(ctx, m.treeID).Scan(&mapRevision); err == sql.ErrNoRows {
+		return storage.ErrTreeNeedsInit
+	} else if err != nil {
+		return err
+	}
+	return status.Errorf(codes.NotFound, "no map root for revision %d", revision)
+}
+
+func (m *mapTreeTX) LatestSignedMapRoot(ctx context.Context) (trillian.SignedMapRoot, error) {
+	var timestamp, mapRevision int64
+	var rootHash, rootSignatureBytes []byte
+	var mapperMetaBytes []byte
+
+	stmt, err := m.tx.PrepareContext(ctx, selectLatestSignedMapRootSQL)
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, m.treeID).Scan(
+		&timestamp, &rootHash, &mapRevision, &rootSignatureBytes, &mapperMetaBytes)
+
+	// It's possible there are no roots for this tree yet
+	if err == sql.ErrNoRows {
+		return trillian.SignedMapRoot{}, storage.ErrTreeNeedsInit
+	} else if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+	m.readRevision = mapRevision
+	return m.signedMapRoot(timestamp, mapRevision, rootHash, rootSignatureBytes, mapperMetaBytes)
+}
+
+func (m *mapTreeTX) signedMapRoot(timestamp, mapRevision int64, rootHash, rootSignature, mapperMeta []byte) (trillian.SignedMapRoot, error) {
+	mapRoot, err := (&types.MapRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(timestamp),
+		Revision:       uint64(mapRevision),
+		Metadata:       mapperMeta,
+	}).MarshalBinary()
+	if err != nil {
+		return trillian.SignedMapRoot{}, err
+	}
+
+	return trillian.SignedMapRoot{
+		MapRoot:   mapRoot,
+		Signature: rootSignature,
+	}, nil
+}
+
+func (m *mapTreeTX) StoreSignedMapRoot(ctx context.Context, root trillian.SignedMapRoot) error {
+	var r types.MapRootV1
+	if err := r.UnmarshalBinary(root.MapRoot); err != nil {
+		return err
+	}
+
+	stmt, err := m.tx.PrepareContext(ctx, insertMapHeadSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	// TODO(al): store transactionLogHead too
+	res, err := stmt.ExecContext(ctx, m.treeID, r.TimestampNanos, r.RootHash, r.Revision, root.Signature, r.Metadata)
+	if err != nil {
+		glog.Warningf("Failed to store signed map root: %s", err)
+		return err
+	}
+	// Inserting a duplicate root does nothing, see onConflictDoNothingSQL.
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return storage.ErrMapRevisionExists
+	}
+
+	return checkResultOkAndRowCountIs(res, err, 1)
+}
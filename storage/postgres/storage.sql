@@ -0,0 +1,195 @@
+-- PostgreSQL version of the tree schema
+
+-- ---------------------------------------------
+-- Tree stuff here
+-- ---------------------------------------------
+
+-- Tree parameters should not be changed after creation. Doing so can
+-- render the data in the tree unusable or inconsistent.
+CREATE TABLE IF NOT EXISTS Trees(
+  TreeId                BIGINT NOT NULL,
+  TreeState             VARCHAR(16) NOT NULL CHECK (TreeState IN ('ACTIVE', 'FROZEN', 'DRAINING')),
+  TreeType              VARCHAR(16) NOT NULL CHECK (TreeType IN ('LOG', 'MAP', 'PREORDERED_LOG')),
+  HashStrategy          VARCHAR(32) NOT NULL CHECK (HashStrategy IN ('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'RFC6962_SHA384', 'RFC6962_SHA512')),
+  HashAlgorithm         VARCHAR(16) NOT NULL CHECK (HashAlgorithm IN ('SHA256', 'SHA384', 'SHA512')),
+  SignatureAlgorithm    VARCHAR(16) NOT NULL CHECK (SignatureAlgorithm IN ('ECDSA', 'RSA', 'ED25519')),
+  DisplayName           VARCHAR(20),
+  Description           VARCHAR(200),
+  CreateTimeMillis      BIGINT NOT NULL,
+  UpdateTimeMillis      BIGINT NOT NULL,
+  MaxRootDurationMillis BIGINT NOT NULL,
+  PrivateKey            BYTEA NOT NULL,
+  PublicKey             BYTEA NOT NULL,
+  Deleted               BOOLEAN,
+  DeleteTimeMillis      BIGINT,
+  SequencerConfig       BYTEA,
+  PRIMARY KEY(TreeId)
+);
+
+-- This table contains tree parameters that can be changed at runtime such as for
+-- administrative purposes.
+CREATE TABLE IF NOT EXISTS TreeControl(
+  TreeId                  BIGINT NOT NULL,
+  SigningEnabled          BOOLEAN NOT NULL,
+  SequencingEnabled       BOOLEAN NOT NULL,
+  SequenceIntervalSeconds INTEGER NOT NULL,
+  PRIMARY KEY(TreeId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Labels attached to trees, which ListTrees can select trees by.
+CREATE TABLE IF NOT EXISTS TreeLabels(
+  TreeId                BIGINT NOT NULL,
+  LabelKey              VARCHAR(63) NOT NULL,
+  LabelValue            VARCHAR(255) NOT NULL,
+  PRIMARY KEY(TreeId, LabelKey),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS TreeLabelsByLabel ON TreeLabels(LabelKey, LabelValue);
+
+-- Keys which succeed the original key of a tree in signing its log roots.
+CREATE TABLE IF NOT EXISTS TreeKeys(
+  TreeId                BIGINT NOT NULL,
+  KeyId                 BIGINT NOT NULL,
+  StartTreeSize         BIGINT NOT NULL,
+  PrivateKey            BYTEA NOT NULL,
+  PublicKey             BYTEA NOT NULL,
+  PRIMARY KEY(TreeId, KeyId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS Subtree(
+  TreeId               BIGINT NOT NULL,
+  SubtreeId            BYTEA NOT NULL,
+  Nodes                BYTEA NOT NULL,
+  SubtreeRevision      INTEGER NOT NULL,
+  PRIMARY KEY(TreeId, SubtreeId, SubtreeRevision),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- The TreeRevisionIdx is used to enforce that there is only one STH at any
+-- tree revision
+CREATE TABLE IF NOT EXISTS TreeHead(
+  TreeId               BIGINT NOT NULL,
+  TreeHeadTimestamp    BIGINT,
+  TreeSize             BIGINT,
+  RootHash             BYTEA NOT NULL,
+  RootSignature        BYTEA NOT NULL,
+  TreeRevision         BIGINT,
+  PRIMARY KEY(TreeId, TreeHeadTimestamp),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS TreeHeadRevisionIdx
+  ON TreeHead(TreeId, TreeRevision);
+
+-- ---------------------------------------------
+-- Log specific stuff here
+-- ---------------------------------------------
+
+-- A leaf that has not been sequenced has a row in this table. If duplicate leaves
+-- are allowed they will all reference this row.
+CREATE TABLE IF NOT EXISTS LeafData(
+  TreeId               BIGINT NOT NULL,
+  -- This is a personality specific has of some subset of the leaf data.
+  -- It's only purpose is to allow Trillian to identify duplicate entries in
+  -- the context of the personality.
+  LeafIdentityHash     BYTEA NOT NULL,
+  -- This is the data stored in the leaf for example in CT it contains a DER encoded
+  -- X.509 certificate but is application dependent
+  LeafValue            BYTEA NOT NULL,
+  -- This is extra data that the application can associate with the leaf should it wish to.
+  -- This data is not included in signing and hashing.
+  ExtraData            BYTEA,
+  -- The timestamp from when this leaf data was first queued for inclusion.
+  QueueTimestampNanos  BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- When a leaf is sequenced a row is added to this table. If logs allow duplicates then
+-- multiple rows will exist with different sequence numbers. The signed timestamp
+-- will be communicated via the unsequenced table as this might need to be unique, depending
+-- on the log parameters and we can't insert into this table until we have the sequence number
+-- which is not available at the time we queue the entry. We need both hashes because the
+-- LeafData table is keyed by the raw data hash.
+CREATE TABLE IF NOT EXISTS SequencedLeafData(
+  TreeId               BIGINT NOT NULL,
+  SequenceNumber       BIGINT NOT NULL CHECK (SequenceNumber >= 0),
+  -- This is a personality specific has of some subset of the leaf data.
+  -- It's only purpose is to allow Trillian to identify duplicate entries in
+  -- the context of the personality.
+  LeafIdentityHash     BYTEA NOT NULL,
+  -- This is a MerkleLeafHash as defined by the treehasher that the log uses. For example for
+  -- CT this hash will include the leaf prefix byte as well as the leaf data.
+  MerkleLeafHash       BYTEA NOT NULL,
+  IntegrateTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, SequenceNumber),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE,
+  FOREIGN KEY(TreeId, LeafIdentityHash) REFERENCES LeafData(TreeId, LeafIdentityHash) ON DELETE CASCADE
+);
+
+-- Unlike MySQL, PostgreSQL doesn't implicitly index the referencing side of a
+-- foreign key, so leaf lookups by hash need their own indexes.
+CREATE INDEX IF NOT EXISTS SequencedLeafMerkleIdx
+  ON SequencedLeafData(TreeId, MerkleLeafHash);
+CREATE INDEX IF NOT EXISTS SequencedLeafIdentityIdx
+  ON SequencedLeafData(TreeId, LeafIdentityHash);
+
+CREATE TABLE IF NOT EXISTS Unsequenced(
+  TreeId               BIGINT NOT NULL,
+  -- The bucket field is to allow the use of time based ring bucketed schemes if desired. If
+  -- unused this should be set to zero for all entries.
+  Bucket               INTEGER NOT NULL,
+  -- This is a personality specific hash of some subset of the leaf data.
+  -- It's only purpose is to allow Trillian to identify duplicate entries in
+  -- the context of the personality.
+  LeafIdentityHash     BYTEA NOT NULL,
+  -- This is a MerkleLeafHash as defined by the treehasher that the log uses. For example for
+  -- CT this hash will include the leaf prefix byte as well as the leaf data.
+  MerkleLeafHash       BYTEA NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
+);
+
+
+-- ---------------------------------------------
+-- Map specific stuff here
+-- ---------------------------------------------
+
+CREATE TABLE IF NOT EXISTS MapLeaf(
+  TreeId                BIGINT NOT NULL,
+  KeyHash               BYTEA NOT NULL,
+  MapRevision           BIGINT NOT NULL,
+  LeafValue             BYTEA NOT NULL,
+  PRIMARY KEY(TreeId, KeyHash, MapRevision),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+
+CREATE TABLE IF NOT EXISTS MapHead(
+  TreeId               BIGINT NOT NULL,
+  MapHeadTimestamp     BIGINT,
+  RootHash             BYTEA NOT NULL,
+  MapRevision          BIGINT,
+  RootSignature        BYTEA NOT NULL,
+  MapperData           BYTEA,
+  PRIMARY KEY(TreeId, MapHeadTimestamp),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS MapHeadRevisionIdx
+  ON MapHead(TreeId, MapRevision);
+
+-- A map leaf queued by QueueLeaves has a row in this table until a map signer
+-- writes it to a revision of the map.
+CREATE TABLE IF NOT EXISTS MapLeafQueue(
+  TreeId               BIGINT NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  KeyHash              BYTEA NOT NULL,
+  -- This is the serialized MapLeaf.
+  LeafValue            BYTEA NOT NULL,
+  PRIMARY KEY(TreeId, QueueTimestampNanos, KeyHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
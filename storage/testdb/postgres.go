@@ -0,0 +1,108 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/testonly"
+
+	_ "github.com/lib/pq" // postgres driver
+)
+
+var (
+	trillianPostgresSQL = testonly.RelativeToPackage("../postgres/storage.sql")
+	// postgresDataSource connects to a local PostgreSQL as the postgres user.
+	// Unset connection parameters are taken from the usual PG* environment
+	// variables (PGHOST, PGPORT, PGPASSWORD etc.) by the driver.
+	postgresDataSource = "user=postgres sslmode=disable"
+)
+
+// PostgreSQLAvailable indicates whether a default PostgreSQL database is available.
+func PostgreSQLAvailable() bool {
+	db, err := sql.Open("postgres", postgresDataSource)
+	if err != nil {
+		return false
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		return false
+	}
+	return true
+}
+
+// newEmptyPostgresDB creates a new, empty PostgreSQL database.
+func newEmptyPostgresDB(ctx context.Context) (*sql.DB, error) {
+	db, err := sql.Open("postgres", postgresDataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a randomly-named database and then connect using the new name.
+	name := fmt.Sprintf("trl_%v", time.Now().UnixNano())
+
+	stmt := fmt.Sprintf("CREATE DATABASE %v", name)
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error running statement %q: %v", stmt, err)
+	}
+
+	db.Close()
+	db, err = sql.Open("postgres", fmt.Sprintf("%v dbname=%v", postgresDataSource, name))
+	if err != nil {
+		return nil, err
+	}
+
+	return db, db.Ping()
+}
+
+// NewTrillianPostgresDB creates an empty PostgreSQL database with the Trillian
+// schema. The database name is randomly generated.
+func NewTrillianPostgresDB(ctx context.Context) (*sql.DB, error) {
+	db, err := newEmptyPostgresDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlBytes, err := ioutil.ReadFile(trillianPostgresSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range strings.Split(sanitize(string(sqlBytes)), ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("error running statement %q: %v", stmt, err)
+		}
+	}
+	return db, nil
+}
+
+// SkipIfNoPostgreSQL is a test helper that skips tests that require a local PostgreSQL.
+func SkipIfNoPostgreSQL(t *testing.T) {
+	t.Helper()
+	if !PostgreSQLAvailable() {
+		t.Skip("Skipping test as PostgreSQL not available")
+	}
+}
@@ -173,6 +173,7 @@ func (tester *AdminStorageTester) RunAllTests(t *testing.T) {
 	t.Run("TestCreateTree", tester.TestCreateTree)
 	t.Run("TestUpdateTree", tester.TestUpdateTree)
 	t.Run("TestListTrees", tester.TestListTrees)
+	t.Run("TestListTreesFiltered", tester.TestListTreesFiltered)
 	t.Run("TestSoftDeleteTree", tester.TestSoftDeleteTree)
 	t.Run("TestSoftDeleteTreeErrors", tester.TestSoftDeleteTreeErrors)
 	t.Run("TestHardDeleteTree", tester.TestHardDeleteTree)
@@ -197,6 +198,13 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 	validTreeWithoutOptionals.DisplayName = ""
 	validTreeWithoutOptionals.Description = ""
 
+	validTreeWithSequencerConfig := *LogTree
+	validTreeWithSequencerConfig.SequencerConfig = &trillian.SequencerConfig{
+		BatchSize:   50,
+		GuardWindow: ptypes.DurationProto(5 * time.Second),
+		Priority:    10,
+	}
+
 	tests := []struct {
 		desc    string
 		tree    *trillian.Tree
@@ -223,6 +231,10 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 			desc: "validTreeWithoutOptionals",
 			tree: &validTreeWithoutOptionals,
 		},
+		{
+			desc: "validTreeWithSequencerConfig",
+			tree: &validTreeWithSequencerConfig,
+		},
 	}
 
 	ctx := context.Background()
@@ -299,6 +311,35 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 	validLogWithoutOptionals := referenceLog
 	validLogWithoutOptionalsFunc(&validLogWithoutOptionals)
 
+	sequencerConfigLog := referenceLog
+	sequencerConfigLog.SequencerConfig = &trillian.SequencerConfig{
+		Paused:          true,
+		BatchSize:       10,
+		MaxRootDuration: ptypes.DurationProto(time.Minute),
+	}
+	sequencerConfigFunc := func(tree *trillian.Tree) {
+		tree.SequencerConfig = sequencerConfigLog.SequencerConfig
+	}
+
+	labelsLog := referenceLog
+	labelsLog.Labels = map[string]string{"env": "test", "shard": "2019"}
+	labelsFunc := func(tree *trillian.Tree) {
+		tree.Labels = labelsLog.Labels
+	}
+
+	keyHistoryLog := referenceLog
+	keyHistoryLog.KeyHistory = []*trillian.SigningKey{{
+		KeyId: 12345,
+		PrivateKey: testonly.MustMarshalAny(t, &keyspb.PrivateKey{
+			Der: ktestonly.MustMarshalPrivatePEMToDER(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass),
+		}),
+		PublicKey:     &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)},
+		StartTreeSize: 10,
+	}}
+	keyHistoryFunc := func(tree *trillian.Tree) {
+		tree.KeyHistory = keyHistoryLog.KeyHistory
+	}
+
 	invalidLogFunc := func(tree *trillian.Tree) {
 		tree.TreeState = trillian.TreeState_UNKNOWN_TREE_STATE
 	}
@@ -355,6 +396,24 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 			updateFunc: validLogWithoutOptionalsFunc,
 			want:       &validLogWithoutOptionals,
 		},
+		{
+			desc:       "sequencerConfig",
+			create:     &referenceLog,
+			updateFunc: sequencerConfigFunc,
+			want:       &sequencerConfigLog,
+		},
+		{
+			desc:       "labels",
+			create:     &referenceLog,
+			updateFunc: labelsFunc,
+			want:       &labelsLog,
+		},
+		{
+			desc:       "keyHistory",
+			create:     &referenceLog,
+			updateFunc: keyHistoryFunc,
+			want:       &keyHistoryLog,
+		},
 		{
 			desc:       "invalidLog",
 			create:     &referenceLog,
@@ -450,7 +509,7 @@ func (tester *AdminStorageTester) TestListTrees(t *testing.T) {
 			if err := runListTreeIDsTest(ctx, tx, includeDeleted, wantTrees); err != nil {
 				t.Errorf("%v: %v", desc, err)
 			}
-			if err := runListTreesTest(ctx, tx, includeDeleted, wantTrees); err != nil {
+			if err := runListTreesTest(ctx, tx, storage.ListTreesOptions{IncludeDeleted: includeDeleted}, wantTrees); err != nil {
 				t.Errorf("%v: %v", desc, err)
 			}
 			// Always return nil, as we're reporting errors independently above.
@@ -493,8 +552,69 @@ func runListTreeIDsTest(ctx context.Context, tx storage.ReadOnlyAdminTX, include
 	return nil
 }
 
-func runListTreesTest(ctx context.Context, tx storage.ReadOnlyAdminTX, includeDeleted bool, wantTrees []*trillian.Tree) error {
-	got, err := tx.ListTrees(ctx, includeDeleted)
+// TestListTreesFiltered tests ListTrees with label selectors and paging.
+func (tester *AdminStorageTester) TestListTreesFiltered(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	shard2018 := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Labels: map[string]string{"app": "ct", "shard": "2018"}}, t.Fatalf)
+	shard2019 := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Labels: map[string]string{"app": "ct", "shard": "2019"}}, t.Fatalf)
+	deleted2019 := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Labels: map[string]string{"app": "ct", "shard": "2019"}, Deleted: true}, t.Fatalf)
+	unlabelled := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+
+	all := []*trillian.Tree{shard2018, shard2019, deleted2019, unlabelled}
+	sort.Slice(all, func(i, j int) bool { return all[i].TreeId < all[j].TreeId })
+
+	tests := []struct {
+		desc      string
+		opts      storage.ListTreesOptions
+		wantTrees []*trillian.Tree
+	}{
+		{
+			desc:      "oneLabel",
+			opts:      storage.ListTreesOptions{Labels: map[string]string{"app": "ct"}},
+			wantTrees: []*trillian.Tree{shard2018, shard2019},
+		},
+		{
+			desc:      "twoLabels",
+			opts:      storage.ListTreesOptions{Labels: map[string]string{"app": "ct", "shard": "2019"}},
+			wantTrees: []*trillian.Tree{shard2019},
+		},
+		{
+			desc:      "twoLabelsDeleted",
+			opts:      storage.ListTreesOptions{IncludeDeleted: true, Labels: map[string]string{"app": "ct", "shard": "2019"}},
+			wantTrees: []*trillian.Tree{shard2019, deleted2019},
+		},
+		{
+			desc: "noMatch",
+			opts: storage.ListTreesOptions{Labels: map[string]string{"app": "other"}},
+		},
+		{
+			desc:      "firstPage",
+			opts:      storage.ListTreesOptions{IncludeDeleted: true, PageSize: 3},
+			wantTrees: all[:3],
+		},
+		{
+			desc:      "lastPage",
+			opts:      storage.ListTreesOptions{IncludeDeleted: true, PageSize: 3, AfterTreeID: all[2].TreeId},
+			wantTrees: all[3:],
+		},
+		{
+			desc: "afterLast",
+			opts: storage.ListTreesOptions{IncludeDeleted: true, AfterTreeID: all[3].TreeId},
+		},
+	}
+	for _, test := range tests {
+		if err := storage.RunInAdminSnapshot(ctx, s, func(tx storage.ReadOnlyAdminTX) error {
+			return runListTreesTest(ctx, tx, test.opts, test.wantTrees)
+		}); err != nil {
+			t.Errorf("%v: %v", test.desc, err)
+		}
+	}
+}
+
+func runListTreesTest(ctx context.Context, tx storage.ReadOnlyAdminTX, opts storage.ListTreesOptions, wantTrees []*trillian.Tree) error {
+	got, err := tx.ListTrees(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("ListTrees() returned err = %v", err)
 	}
@@ -503,8 +623,9 @@ func runListTreesTest(ctx context.Context, tx storage.ReadOnlyAdminTX, includeDe
 		return fmt.Errorf("ListTrees() returned %v trees, want = %v", len(got), len(wantTrees))
 	}
 
-	want := wantTrees
-	sort.Slice(got, func(i, j int) bool { return got[i].TreeId < got[j].TreeId })
+	// Trees must be returned in order of tree ID.
+	want := make([]*trillian.Tree, len(wantTrees))
+	copy(want, wantTrees)
 	sort.Slice(want, func(i, j int) bool { return want[i].TreeId < want[j].TreeId })
 
 	for i, wantTree := range want {
@@ -745,6 +866,7 @@ func assertStoredTree(ctx context.Context, s storage.AdminStorage, want *trillia
 
 type spec struct {
 	Tree            *trillian.Tree
+	Labels          map[string]string
 	Frozen, Deleted bool
 }
 
@@ -761,6 +883,9 @@ func makeTreeOrFail(ctx context.Context, s storage.AdminStorage, spec spec, fail
 // makeTree creates a tree and updates it to Frozen and/or Deleted, according to "spec".
 func makeTree(ctx context.Context, s storage.AdminStorage, spec spec) (*trillian.Tree, error) {
 	tree := proto.Clone(spec.Tree).(*trillian.Tree)
+	if spec.Labels != nil {
+		tree.Labels = spec.Labels
+	}
 
 	var err error
 	tree, err = storage.CreateTree(ctx, s, tree)
@@ -17,22 +17,37 @@ package storage
 import (
 	"bytes"
 	"context"
+	"fmt"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/crypto/sigpb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
 )
 
 const (
 	maxDisplayNameLength = 20
 	maxDescriptionLength = 200
+	maxLabelKeyLength    = 63
+	maxLabelValueLength  = 255
 )
 
+// supportedHashAlgorithms are the hash algorithms trees may be signed with.
+var supportedHashAlgorithms = map[sigpb.DigitallySigned_HashAlgorithm]bool{
+	sigpb.DigitallySigned_SHA256: true,
+	sigpb.DigitallySigned_SHA384: true,
+	sigpb.DigitallySigned_SHA512: true,
+}
+
 // ValidateTreeForCreation returns nil if tree is valid for insertion, error
 // otherwise.
 // See the documentation on trillian.Tree for reference on which values are
@@ -47,7 +62,7 @@ func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
 		return status.Errorf(codes.InvalidArgument, "invalid tree_type: %s", tree.TreeType)
 	case tree.HashStrategy == trillian.HashStrategy_UNKNOWN_HASH_STRATEGY:
 		return status.Errorf(codes.InvalidArgument, "invalid hash_strategy: %s", tree.HashStrategy)
-	case tree.HashAlgorithm == sigpb.DigitallySigned_NONE:
+	case !supportedHashAlgorithms[tree.HashAlgorithm]:
 		return status.Errorf(codes.InvalidArgument, "invalid hash_algorithm: %s", tree.HashAlgorithm)
 	case tree.SignatureAlgorithm == sigpb.DigitallySigned_ANONYMOUS:
 		return status.Errorf(codes.InvalidArgument, "invalid signature_algorithm: %s", tree.SignatureAlgorithm)
@@ -59,9 +74,11 @@ func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
 		return status.Errorf(codes.InvalidArgument, "invalid deleted: %v", tree.Deleted)
 	case tree.DeleteTime != nil:
 		return status.Errorf(codes.InvalidArgument, "invalid delete_time: %+v (must be nil)", tree.DeleteTime)
+	case len(tree.KeyHistory) > 0:
+		return status.Error(codes.InvalidArgument, "key_history must be empty, successor keys are added by updates")
 	}
 
-	return validateMutableTreeFields(ctx, tree)
+	return validateMutableTreeFields(ctx, nil, tree)
 }
 
 // validateTreeTypeUpdate returns nil iff oldTree.TreeType can be updated to
@@ -117,10 +134,23 @@ func ValidateTreeForUpdate(ctx context.Context, storedTree, newTree *trillian.Tr
 	case !proto.Equal(storedTree.DeleteTime, newTree.DeleteTime):
 		return status.Error(codes.InvalidArgument, "readonly field changed: delete_time")
 	}
-	return validateMutableTreeFields(ctx, newTree)
+
+	// Successor keys may be added, but not changed or removed.
+	if len(newTree.KeyHistory) < len(storedTree.KeyHistory) {
+		return status.Error(codes.InvalidArgument, "key_history entries can't be removed")
+	}
+	for i, key := range storedTree.KeyHistory {
+		if !proto.Equal(key, newTree.KeyHistory[i]) {
+			return status.Errorf(codes.InvalidArgument, "readonly field changed: key_history[%v]", i)
+		}
+	}
+	return validateMutableTreeFields(ctx, storedTree, newTree)
 }
 
-func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
+// validateMutableTreeFields checks the fields of tree which may be changed by
+// updates. storedTree is the tree being updated, or nil if tree is being
+// created.
+func validateMutableTreeFields(ctx context.Context, storedTree, tree *trillian.Tree) error {
 	switch {
 	case tree.TreeState == trillian.TreeState_UNKNOWN_TREE_STATE:
 		return status.Errorf(codes.InvalidArgument, "invalid tree_state: %v", tree.TreeState)
@@ -135,6 +165,13 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 		return status.Errorf(codes.InvalidArgument, "max_root_duration negative: %v", tree.MaxRootDuration)
 	}
 
+	if err := validateSequencerConfig(tree); err != nil {
+		return err
+	}
+	if err := validateLabels(tree.Labels); err != nil {
+		return err
+	}
+
 	// Implementations may vary, so let's assume storage_settings is mutable.
 	// Other than checking that it's a valid Any there isn't much to do at this layer, though.
 	if tree.StorageSettings != nil {
@@ -144,23 +181,112 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 		}
 	}
 
+	// Only new keys need to be of the type of signature_algorithm: trees
+	// created before that was checked must remain updatable.
+	newKey := storedTree == nil || !proto.Equal(storedTree.PrivateKey, tree.PrivateKey)
+	if err := validateKeyPair(ctx, "", tree.SignatureAlgorithm, newKey, tree.PrivateKey, tree.PublicKey); err != nil {
+		return err
+	}
+	return validateKeyHistory(ctx, tree, len(storedTree.GetKeyHistory()))
+}
+
+// validateKeyPair checks that privateKey can be obtained and matches
+// publicKey, and, if checkAlgorithm is set, that it's a key for sigAlgorithm.
+// prefix is prepended to the names of the fields in errors.
+func validateKeyPair(ctx context.Context, prefix string, sigAlgorithm sigpb.DigitallySigned_SignatureAlgorithm, checkAlgorithm bool, privateKey *any.Any, publicKey *keyspb.PublicKey) error {
 	var privateKeyProto ptypes.DynamicAny
-	if err := ptypes.UnmarshalAny(tree.PrivateKey, &privateKeyProto); err != nil {
-		return status.Errorf(codes.InvalidArgument, "invalid private_key: %v", err)
+	if err := ptypes.UnmarshalAny(privateKey, &privateKeyProto); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid %vprivate_key: %v", prefix, err)
 	}
 
-	// Check that the private key can be obtained and matches the public key.
-	privateKey, err := keys.NewSigner(ctx, privateKeyProto.Message)
+	signer, err := keys.NewSigner(ctx, privateKeyProto.Message)
 	if err != nil {
-		return status.Errorf(codes.InvalidArgument, "invalid private_key: %v", err)
+		return status.Errorf(codes.InvalidArgument, "invalid %vprivate_key: %v", prefix, err)
 	}
-	publicKeyDER, err := der.MarshalPublicKey(privateKey.Public())
+	if got := tcrypto.SignatureAlgorithm(signer.Public()); checkAlgorithm && got != sigAlgorithm {
+		return status.Errorf(codes.InvalidArgument, "%vprivate_key is a %v key, want a %v key for signature_algorithm", prefix, got, sigAlgorithm)
+	}
+	publicKeyDER, err := der.MarshalPublicKey(signer.Public())
 	if err != nil {
-		return status.Errorf(codes.InvalidArgument, "invalid private_key: %v", err)
+		return status.Errorf(codes.InvalidArgument, "invalid %vprivate_key: %v", prefix, err)
+	}
+	if !bytes.Equal(publicKeyDER, publicKey.GetDer()) {
+		return status.Errorf(codes.InvalidArgument, "%[1]vprivate_key and %[1]vpublic_key are not a matching pair", prefix)
+	}
+	return nil
+}
+
+// validateKeyHistory checks the key_history of tree, of which the first
+// storedKeys entries are already stored.
+func validateKeyHistory(ctx context.Context, tree *trillian.Tree, storedKeys int) error {
+	if len(tree.KeyHistory) == 0 {
+		return nil
 	}
-	if !bytes.Equal(publicKeyDER, tree.PublicKey.GetDer()) {
-		return status.Errorf(codes.InvalidArgument, "private_key and public_key are not a matching pair")
+	if tree.TreeType == trillian.TreeType_MAP {
+		return status.Errorf(codes.InvalidArgument, "key_history not supported by %v trees", tree.TreeType)
 	}
+	ids := map[int64]bool{tree.TreeId: true}
+	var prevStart int64
+	for i, key := range tree.KeyHistory {
+		switch {
+		case key.KeyId <= 0 || ids[key.KeyId]:
+			return status.Errorf(codes.InvalidArgument, "invalid key_history[%v].key_id: %v", i, key.KeyId)
+		case key.StartTreeSize <= prevStart:
+			return status.Errorf(codes.InvalidArgument, "key_history[%v].start_tree_size must be greater than %v, got %v", i, prevStart, key.StartTreeSize)
+		case key.PrivateKey == nil:
+			return status.Errorf(codes.InvalidArgument, "a key_history[%v].private_key is required", i)
+		case key.PublicKey == nil:
+			return status.Errorf(codes.InvalidArgument, "a key_history[%v].public_key is required", i)
+		}
+		if err := validateKeyPair(ctx, fmt.Sprintf("key_history[%v].", i), tree.SignatureAlgorithm, i >= storedKeys, key.PrivateKey, key.PublicKey); err != nil {
+			return err
+		}
+		ids[key.KeyId] = true
+		prevStart = key.StartTreeSize
+	}
+	return nil
+}
 
+func validateSequencerConfig(tree *trillian.Tree) error {
+	cfg := tree.SequencerConfig
+	if cfg == nil {
+		return nil
+	}
+	if tree.TreeType == trillian.TreeType_MAP {
+		return status.Errorf(codes.InvalidArgument, "sequencer_config not supported by %v trees", tree.TreeType)
+	}
+	if cfg.BatchSize < 0 {
+		return status.Errorf(codes.InvalidArgument, "sequencer_config.batch_size negative: %v", cfg.BatchSize)
+	}
+	for name, d := range map[string]*duration.Duration{
+		"guard_window":      cfg.GuardWindow,
+		"max_root_duration": cfg.MaxRootDuration,
+	} {
+		if d == nil {
+			continue
+		}
+		if duration, err := ptypes.Duration(d); err != nil {
+			return status.Errorf(codes.InvalidArgument, "sequencer_config.%v malformed: %v", name, d)
+		} else if duration < 0 {
+			return status.Errorf(codes.InvalidArgument, "sequencer_config.%v negative: %v", name, d)
+		}
+	}
+	return nil
+}
+
+func validateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if k == "" || len(k) > maxLabelKeyLength {
+			return status.Errorf(codes.InvalidArgument, "invalid label key %q, length must be between 1 and %v", k, maxLabelKeyLength)
+		}
+		for _, c := range k {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+				return status.Errorf(codes.InvalidArgument, "invalid label key %q, must only contain [a-z0-9._-]", k)
+			}
+		}
+		if len(v) > maxLabelValueLength {
+			return status.Errorf(codes.InvalidArgument, "label %q too big, max value length is %v", k, maxLabelValueLength)
+		}
+	}
 	return nil
 }
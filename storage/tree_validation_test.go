@@ -16,6 +16,7 @@ package storage
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,9 +77,34 @@ func TestValidateTreeForCreation(t *testing.T) {
 	invalidHashAlgorithm := newTree()
 	invalidHashAlgorithm.HashAlgorithm = sigpb.DigitallySigned_NONE
 
+	unsupportedHashAlgorithm := newTree()
+	unsupportedHashAlgorithm.HashAlgorithm = sigpb.DigitallySigned_HashAlgorithm(2) // SHA224
+
+	sha384 := newTree()
+	sha384.HashStrategy = trillian.HashStrategy_RFC6962_SHA384
+	sha384.HashAlgorithm = sigpb.DigitallySigned_SHA384
+
+	sha512 := newTree()
+	sha512.HashStrategy = trillian.HashStrategy_RFC6962_SHA512
+	sha512.HashAlgorithm = sigpb.DigitallySigned_SHA512
+
 	invalidSignatureAlgorithm := newTree()
 	invalidSignatureAlgorithm.SignatureAlgorithm = sigpb.DigitallySigned_ANONYMOUS
 
+	mismatchedSignatureAlgorithm := newTree()
+	mismatchedSignatureAlgorithm.SignatureAlgorithm = sigpb.DigitallySigned_RSA
+
+	ed25519Key, err := ptypes.MarshalAny(&keyspb.PrivateKey{
+		Der: ktestonly.MustMarshalPrivatePEMToDER(testonly.DemoEd25519PrivateKey, ""),
+	})
+	if err != nil {
+		t.Fatalf("Error marshaling proto: %v", err)
+	}
+	validEd25519 := newTree()
+	validEd25519.SignatureAlgorithm = sigpb.DigitallySigned_ED25519
+	validEd25519.PrivateKey = ed25519Key
+	validEd25519.PublicKey = &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoEd25519PublicKey)}
+
 	invalidDisplayName := newTree()
 	invalidDisplayName.DisplayName = "A Very Long Display Name That Clearly Won't Fit But At Least Mentions Llamas Somewhere"
 
@@ -120,12 +146,48 @@ func TestValidateTreeForCreation(t *testing.T) {
 	invalidRootDuration := newTree()
 	invalidRootDuration.MaxRootDuration = ptypes.DurationProto(-1 * time.Second)
 
+	validSequencerConfig := newTree()
+	validSequencerConfig.SequencerConfig = &trillian.SequencerConfig{
+		BatchSize:       10,
+		GuardWindow:     ptypes.DurationProto(time.Second),
+		MaxRootDuration: ptypes.DurationProto(time.Hour),
+		Priority:        -1,
+	}
+
+	invalidSequencerBatchSize := newTree()
+	invalidSequencerBatchSize.SequencerConfig = &trillian.SequencerConfig{BatchSize: -1}
+
+	invalidSequencerGuardWindow := newTree()
+	invalidSequencerGuardWindow.SequencerConfig = &trillian.SequencerConfig{GuardWindow: ptypes.DurationProto(-1 * time.Second)}
+
+	invalidSequencerRootDuration := newTree()
+	invalidSequencerRootDuration.SequencerConfig = &trillian.SequencerConfig{MaxRootDuration: ptypes.DurationProto(-1 * time.Second)}
+
+	mapSequencerConfig := newTree()
+	mapSequencerConfig.TreeType = trillian.TreeType_MAP
+	mapSequencerConfig.SequencerConfig = &trillian.SequencerConfig{}
+
+	validLabels := newTree()
+	validLabels.Labels = map[string]string{"app": "ct", "shard.year": "2019", "env_name-1": ""}
+
+	emptyLabelKey := newTree()
+	emptyLabelKey.Labels = map[string]string{"": "ct"}
+
+	invalidLabelKey := newTree()
+	invalidLabelKey.Labels = map[string]string{"App": "ct"}
+
+	longLabelValue := newTree()
+	longLabelValue.Labels = map[string]string{"app": strings.Repeat("a", 256)}
+
 	deletedTree := newTree()
 	deletedTree.Deleted = true
 
 	deleteTimeTree := newTree()
 	deleteTimeTree.DeleteTime = ptypes.TimestampNow()
 
+	keyHistoryTree := newTree()
+	keyHistoryTree.KeyHistory = []*trillian.SigningKey{newSuccessorKey(12345, 10)}
+
 	tests := []struct {
 		desc    string
 		tree    *trillian.Tree
@@ -169,11 +231,33 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    invalidHashAlgorithm,
 			wantErr: true,
 		},
+		{
+			desc:    "unsupportedHashAlgorithm",
+			tree:    unsupportedHashAlgorithm,
+			wantErr: true,
+		},
+		{
+			desc: "sha384",
+			tree: sha384,
+		},
+		{
+			desc: "sha512",
+			tree: sha512,
+		},
 		{
 			desc:    "invalidSignatureAlgorithm",
 			tree:    invalidSignatureAlgorithm,
 			wantErr: true,
 		},
+		{
+			desc:    "mismatchedSignatureAlgorithm",
+			tree:    mismatchedSignatureAlgorithm,
+			wantErr: true,
+		},
+		{
+			desc: "validEd25519",
+			tree: validEd25519,
+		},
 		{
 			desc:    "invalidDisplayName",
 			tree:    invalidDisplayName,
@@ -228,6 +312,49 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    invalidRootDuration,
 			wantErr: true,
 		},
+		{
+			desc: "validSequencerConfig",
+			tree: validSequencerConfig,
+		},
+		{
+			desc:    "invalidSequencerBatchSize",
+			tree:    invalidSequencerBatchSize,
+			wantErr: true,
+		},
+		{
+			desc:    "invalidSequencerGuardWindow",
+			tree:    invalidSequencerGuardWindow,
+			wantErr: true,
+		},
+		{
+			desc:    "invalidSequencerRootDuration",
+			tree:    invalidSequencerRootDuration,
+			wantErr: true,
+		},
+		{
+			desc:    "mapSequencerConfig",
+			tree:    mapSequencerConfig,
+			wantErr: true,
+		},
+		{
+			desc: "validLabels",
+			tree: validLabels,
+		},
+		{
+			desc:    "emptyLabelKey",
+			tree:    emptyLabelKey,
+			wantErr: true,
+		},
+		{
+			desc:    "invalidLabelKey",
+			tree:    invalidLabelKey,
+			wantErr: true,
+		},
+		{
+			desc:    "longLabelValue",
+			tree:    longLabelValue,
+			wantErr: true,
+		},
 		{
 			desc:    "deletedTree",
 			tree:    deletedTree,
@@ -238,6 +365,11 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    deleteTimeTree,
 			wantErr: true,
 		},
+		{
+			desc:    "keyHistory",
+			tree:    keyHistoryTree,
+			wantErr: true,
+		},
 	}
 	for _, test := range tests {
 		err := ValidateTreeForCreation(ctx, test.tree)
@@ -254,11 +386,13 @@ func TestValidateTreeForUpdate(t *testing.T) {
 	ctx := context.Background()
 
 	tests := []struct {
-		desc      string
-		treeState trillian.TreeState
-		treeType  trillian.TreeType
-		updatefn  func(*trillian.Tree)
-		wantErr   bool
+		desc       string
+		treeState  trillian.TreeState
+		treeType   trillian.TreeType
+		sigAlg     sigpb.DigitallySigned_SignatureAlgorithm
+		keyHistory []*trillian.SigningKey
+		updatefn   func(*trillian.Tree)
+		wantErr    bool
 	}{
 		{
 			desc: "valid",
@@ -303,6 +437,19 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "validSequencerConfig",
+			updatefn: func(tree *trillian.Tree) {
+				tree.SequencerConfig = &trillian.SequencerConfig{Paused: true, BatchSize: 5}
+			},
+		},
+		{
+			desc: "invalidSequencerConfig",
+			updatefn: func(tree *trillian.Tree) {
+				tree.SequencerConfig = &trillian.SequencerConfig{BatchSize: -5}
+			},
+			wantErr: true,
+		},
 		{
 			desc: "differentPrivateKeyProtoButSameKeyMaterial",
 			updatefn: func(tree *trillian.Tree) {
@@ -346,7 +493,100 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "successorKey",
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = append(tree.KeyHistory, newSuccessorKey(12345, 10))
+			},
+		},
+		{
+			desc:       "secondSuccessorKey",
+			keyHistory: []*trillian.SigningKey{newSuccessorKey(12345, 10)},
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = append(tree.KeyHistory, newSuccessorKey(67890, 20))
+			},
+		},
+		{
+			desc:     "successorKeyOnMap",
+			treeType: trillian.TreeType_MAP,
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = append(tree.KeyHistory, newSuccessorKey(12345, 10))
+			},
+			wantErr: true,
+		},
+		{
+			desc:       "successorKeyIDReused",
+			keyHistory: []*trillian.SigningKey{newSuccessorKey(12345, 10)},
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = append(tree.KeyHistory, newSuccessorKey(12345, 20))
+			},
+			wantErr: true,
+		},
+		{
+			desc: "successorKeyZeroStart",
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = append(tree.KeyHistory, newSuccessorKey(12345, 0))
+			},
+			wantErr: true,
+		},
+		{
+			desc:       "successorKeyStartNotIncreasing",
+			keyHistory: []*trillian.SigningKey{newSuccessorKey(12345, 10)},
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = append(tree.KeyHistory, newSuccessorKey(67890, 10))
+			},
+			wantErr: true,
+		},
+		{
+			desc: "successorKeyMismatchedPublicKey",
+			updatefn: func(tree *trillian.Tree) {
+				key := newSuccessorKey(12345, 10)
+				key.PublicKey = tree.PublicKey
+				tree.KeyHistory = append(tree.KeyHistory, key)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "successorKeyNoPrivateKey",
+			updatefn: func(tree *trillian.Tree) {
+				key := newSuccessorKey(12345, 10)
+				key.PrivateKey = nil
+				tree.KeyHistory = append(tree.KeyHistory, key)
+			},
+			wantErr: true,
+		},
 		// Changes on readonly fields
+		{
+			desc:       "successorKeyRemoved",
+			keyHistory: []*trillian.SigningKey{newSuccessorKey(12345, 10)},
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = nil
+			},
+			wantErr: true,
+		},
+		{
+			// Trees stored before keys were checked against the
+			// signature_algorithm may have keys of another type.
+			desc:     "storedKeyOfOtherAlgorithm",
+			sigAlg:   sigpb.DigitallySigned_RSA,
+			updatefn: func(tree *trillian.Tree) { tree.DisplayName = "Renamed Tree" },
+		},
+		{
+			desc:   "newSuccessorKeyOfOtherAlgorithm",
+			sigAlg: sigpb.DigitallySigned_RSA,
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = []*trillian.SigningKey{newSuccessorKey(12345, 10)}
+			},
+			wantErr: true,
+		},
+		{
+			desc:       "successorKeyChanged",
+			keyHistory: []*trillian.SigningKey{newSuccessorKey(12345, 10)},
+			updatefn: func(tree *trillian.Tree) {
+				tree.KeyHistory = []*trillian.SigningKey{newSuccessorKey(12345, 11)}
+			},
+			wantErr: true,
+		},
 		{
 			desc: "TreeId",
 			updatefn: func(tree *trillian.Tree) {
@@ -441,6 +681,10 @@ func TestValidateTreeForUpdate(t *testing.T) {
 		if test.treeState != trillian.TreeState_UNKNOWN_TREE_STATE {
 			tree.TreeState = test.treeState
 		}
+		if test.sigAlg != sigpb.DigitallySigned_ANONYMOUS {
+			tree.SignatureAlgorithm = test.sigAlg
+		}
+		tree.KeyHistory = test.keyHistory
 
 		baseTree := *tree
 		test.updatefn(tree)
@@ -480,3 +724,20 @@ func newTree() *trillian.Tree {
 		MaxRootDuration: ptypes.DurationProto(1000 * time.Millisecond),
 	}
 }
+
+// newSuccessorKey returns a valid key_history entry for tests.
+func newSuccessorKey(keyID, startTreeSize int64) *trillian.SigningKey {
+	privateKey, err := ptypes.MarshalAny(&keyspb.PrivateKey{
+		Der: ktestonly.MustMarshalPrivatePEMToDER(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return &trillian.SigningKey{
+		KeyId:         keyID,
+		PrivateKey:    privateKey,
+		PublicKey:     &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)},
+		StartTreeSize: startTreeSize,
+	}
+}
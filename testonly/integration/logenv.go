@@ -36,6 +36,7 @@ import (
 	"github.com/google/trillian/server/admin"
 	"github.com/google/trillian/server/interceptor"
 	"github.com/google/trillian/storage/mysql"
+	"github.com/google/trillian/storage/notify"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/util"
 
@@ -113,8 +114,12 @@ func NewLogEnvWithRegistry(ctx context.Context, numSequencers int, registry exte
 
 // NewLogEnvWithRegistryAndGRPCOptions works the same way as NewLogEnv, but allows callers to also set additional grpc.ServerOption and grpc.DialOption values.
 func NewLogEnvWithRegistryAndGRPCOptions(ctx context.Context, numSequencers int, registry extension.Registry, serverOpts []grpc.ServerOption, clientOpts []grpc.DialOption) (*LogEnv, error) {
+	// The sequencer and log server share storage, so the log server can push
+	// the roots stored by the sequencer to WatchSignedLogRoots callers.
+	registry.LogStorage = notify.NewLogStorage(registry.LogStorage)
+
 	// Create the GRPC Server.
-	serverOpts = append(serverOpts, grpc.UnaryInterceptor(interceptor.ErrorWrapper))
+	serverOpts = append(serverOpts, grpc.UnaryInterceptor(interceptor.ErrorWrapper), grpc.StreamInterceptor(interceptor.StreamErrorWrapper))
 	grpcServer := grpc.NewServer(serverOpts...)
 
 	// Setup the Admin Server.
@@ -215,3 +215,27 @@ func (m *MockTrillianLogServer) QueueLeaves(arg0 context.Context, arg1 *trillian
 func (mr *MockTrillianLogServerMockRecorder) QueueLeaves(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueLeaves", reflect.TypeOf((*MockTrillianLogServer)(nil).QueueLeaves), arg0, arg1)
 }
+
+// StreamLeaves mocks base method
+func (m *MockTrillianLogServer) StreamLeaves(arg0 *trillian.StreamLeavesRequest, arg1 trillian.TrillianLog_StreamLeavesServer) error {
+	ret := m.ctrl.Call(m, "StreamLeaves", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamLeaves indicates an expected call of StreamLeaves
+func (mr *MockTrillianLogServerMockRecorder) StreamLeaves(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamLeaves", reflect.TypeOf((*MockTrillianLogServer)(nil).StreamLeaves), arg0, arg1)
+}
+
+// WatchSignedLogRoots mocks base method
+func (m *MockTrillianLogServer) WatchSignedLogRoots(arg0 *trillian.WatchSignedLogRootsRequest, arg1 trillian.TrillianLog_WatchSignedLogRootsServer) error {
+	ret := m.ctrl.Call(m, "WatchSignedLogRoots", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchSignedLogRoots indicates an expected call of WatchSignedLogRoots
+func (mr *MockTrillianLogServerMockRecorder) WatchSignedLogRoots(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchSignedLogRoots", reflect.TypeOf((*MockTrillianLogServer)(nil).WatchSignedLogRoots), arg0, arg1)
+}
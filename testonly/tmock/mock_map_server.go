@@ -34,6 +34,19 @@ func (m *MockTrillianMapServer) EXPECT() *MockTrillianMapServerMockRecorder {
 	return m.recorder
 }
 
+// GetLeafHistory mocks base method
+func (m *MockTrillianMapServer) GetLeafHistory(arg0 context.Context, arg1 *trillian.GetMapLeafHistoryRequest) (*trillian.GetMapLeafHistoryResponse, error) {
+	ret := m.ctrl.Call(m, "GetLeafHistory", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetMapLeafHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeafHistory indicates an expected call of GetLeafHistory
+func (mr *MockTrillianMapServerMockRecorder) GetLeafHistory(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeafHistory", reflect.TypeOf((*MockTrillianMapServer)(nil).GetLeafHistory), arg0, arg1)
+}
+
 // GetLeaves mocks base method
 func (m *MockTrillianMapServer) GetLeaves(arg0 context.Context, arg1 *trillian.GetMapLeavesRequest) (*trillian.GetMapLeavesResponse, error) {
 	ret := m.ctrl.Call(m, "GetLeaves", arg0, arg1)
@@ -60,6 +73,19 @@ func (mr *MockTrillianMapServerMockRecorder) GetLeavesByRevision(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByRevision", reflect.TypeOf((*MockTrillianMapServer)(nil).GetLeavesByRevision), arg0, arg1)
 }
 
+// GetMapStatus mocks base method
+func (m *MockTrillianMapServer) GetMapStatus(arg0 context.Context, arg1 *trillian.GetMapStatusRequest) (*trillian.GetMapStatusResponse, error) {
+	ret := m.ctrl.Call(m, "GetMapStatus", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetMapStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMapStatus indicates an expected call of GetMapStatus
+func (mr *MockTrillianMapServerMockRecorder) GetMapStatus(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMapStatus", reflect.TypeOf((*MockTrillianMapServer)(nil).GetMapStatus), arg0, arg1)
+}
+
 // GetSignedMapRoot mocks base method
 func (m *MockTrillianMapServer) GetSignedMapRoot(arg0 context.Context, arg1 *trillian.GetSignedMapRootRequest) (*trillian.GetSignedMapRootResponse, error) {
 	ret := m.ctrl.Call(m, "GetSignedMapRoot", arg0, arg1)
@@ -99,6 +125,19 @@ func (mr *MockTrillianMapServerMockRecorder) InitMap(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitMap", reflect.TypeOf((*MockTrillianMapServer)(nil).InitMap), arg0, arg1)
 }
 
+// QueueLeaves mocks base method
+func (m *MockTrillianMapServer) QueueLeaves(arg0 context.Context, arg1 *trillian.QueueMapLeavesRequest) (*trillian.QueueMapLeavesResponse, error) {
+	ret := m.ctrl.Call(m, "QueueLeaves", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.QueueMapLeavesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueueLeaves indicates an expected call of QueueLeaves
+func (mr *MockTrillianMapServerMockRecorder) QueueLeaves(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueLeaves", reflect.TypeOf((*MockTrillianMapServer)(nil).QueueLeaves), arg0, arg1)
+}
+
 // SetLeaves mocks base method
 func (m *MockTrillianMapServer) SetLeaves(arg0 context.Context, arg1 *trillian.SetMapLeavesRequest) (*trillian.SetMapLeavesResponse, error) {
 	ret := m.ctrl.Call(m, "SetLeaves", arg0, arg1)
@@ -111,3 +150,15 @@ func (m *MockTrillianMapServer) SetLeaves(arg0 context.Context, arg1 *trillian.S
 func (mr *MockTrillianMapServerMockRecorder) SetLeaves(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLeaves", reflect.TypeOf((*MockTrillianMapServer)(nil).SetLeaves), arg0, arg1)
 }
+
+// StreamLeaves mocks base method
+func (m *MockTrillianMapServer) StreamLeaves(arg0 *trillian.StreamMapLeavesRequest, arg1 trillian.TrillianMap_StreamLeavesServer) error {
+	ret := m.ctrl.Call(m, "StreamLeaves", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamLeaves indicates an expected call of StreamLeaves
+func (mr *MockTrillianMapServerMockRecorder) StreamLeaves(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamLeaves", reflect.TypeOf((*MockTrillianMapServer)(nil).StreamLeaves), arg0, arg1)
+}
@@ -19,9 +19,12 @@ package trees
 import (
 	"context"
 	"crypto"
+	_ "crypto/sha256" // For the SHA256 hash algorithm.
+	_ "crypto/sha512" // For the SHA384 and SHA512 hash algorithms.
 	"fmt"
 
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/crypto/sigpb"
@@ -182,6 +185,10 @@ func Hash(tree *trillian.Tree) (crypto.Hash, error) {
 	switch tree.HashAlgorithm {
 	case sigpb.DigitallySigned_SHA256:
 		return crypto.SHA256, nil
+	case sigpb.DigitallySigned_SHA384:
+		return crypto.SHA384, nil
+	case sigpb.DigitallySigned_SHA512:
+		return crypto.SHA512, nil
 	}
 	// There's no nil-like value for crypto.Hash, something has to be returned.
 	return crypto.SHA256, fmt.Errorf("unexpected hash algorithm: %s", tree.HashAlgorithm)
@@ -189,6 +196,18 @@ func Hash(tree *trillian.Tree) (crypto.Hash, error) {
 
 // Signer returns a Trillian crypto.Signer configured by the tree.
 func Signer(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
+	return newSigner(ctx, tree, tree.GetTreeId(), tree.PrivateKey, "tree.PrivateKey")
+}
+
+// KeySigner returns a Trillian crypto.Signer for key, one of the successor keys
+// in the key_history of the tree. Its signatures have the key ID as key hint.
+func KeySigner(ctx context.Context, tree *trillian.Tree, key *trillian.SigningKey) (*tcrypto.Signer, error) {
+	return newSigner(ctx, tree, key.GetKeyId(), key.GetPrivateKey(), "key.PrivateKey")
+}
+
+// newSigner returns a Trillian crypto.Signer for privateKey, one of the keys of
+// the tree, which sets keyID as the key hint. field names privateKey in errors.
+func newSigner(ctx context.Context, tree *trillian.Tree, keyID int64, privateKey *any.Any, field string) (*tcrypto.Signer, error) {
 	if tree.SignatureAlgorithm == sigpb.DigitallySigned_ANONYMOUS {
 		return nil, fmt.Errorf("signature algorithm not supported: %s", tree.SignatureAlgorithm)
 	}
@@ -199,8 +218,8 @@ func Signer(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
 	}
 
 	var keyProto ptypes.DynamicAny
-	if err := ptypes.UnmarshalAny(tree.PrivateKey, &keyProto); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tree.PrivateKey: %v", err)
+	if err := ptypes.UnmarshalAny(privateKey, &keyProto); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %v: %v", field, err)
 	}
 
 	signer, err := keys.NewSigner(ctx, keyProto.Message)
@@ -212,7 +231,7 @@ func Signer(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
 		return nil, fmt.Errorf("%s signature not supported by signer of type %T", tree.SignatureAlgorithm, signer)
 	}
 
-	return tcrypto.NewSigner(tree.GetTreeId(), signer, hash), nil
+	return tcrypto.NewSigner(keyID, signer, hash), nil
 }
 
 func spanFor(ctx context.Context, name string) (context.Context, *trace.Span) {
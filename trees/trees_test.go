@@ -335,6 +335,8 @@ func TestHash(t *testing.T) {
 	}{
 		{hashAlgo: sigpb.DigitallySigned_NONE, wantErr: true},
 		{hashAlgo: sigpb.DigitallySigned_SHA256, wantHash: crypto.SHA256},
+		{hashAlgo: sigpb.DigitallySigned_SHA384, wantHash: crypto.SHA384},
+		{hashAlgo: sigpb.DigitallySigned_SHA512, wantHash: crypto.SHA512},
 	}
 
 	for _, test := range tests {
@@ -83,6 +83,12 @@ const (
 	HashStrategy_OBJECT_RFC6962_SHA256 HashStrategy = 3
 	// The CONIKS sparse tree hasher with SHA512_256 as the hash algorithm.
 	HashStrategy_CONIKS_SHA512_256 HashStrategy = 4
+	// Certificate Transparency strategy, as for RFC6962_SHA256, but with
+	// SHA384 as the hash algorithm.
+	HashStrategy_RFC6962_SHA384 HashStrategy = 5
+	// Certificate Transparency strategy, as for RFC6962_SHA256, but with
+	// SHA512 as the hash algorithm.
+	HashStrategy_RFC6962_SHA512 HashStrategy = 6
 )
 
 var HashStrategy_name = map[int32]string{
@@ -91,6 +97,8 @@ var HashStrategy_name = map[int32]string{
 	2: "TEST_MAP_HASHER",
 	3: "OBJECT_RFC6962_SHA256",
 	4: "CONIKS_SHA512_256",
+	5: "RFC6962_SHA384",
+	6: "RFC6962_SHA512",
 }
 var HashStrategy_value = map[string]int32{
 	"UNKNOWN_HASH_STRATEGY": 0,
@@ -98,6 +106,8 @@ var HashStrategy_value = map[string]int32{
 	"TEST_MAP_HASHER":       2,
 	"OBJECT_RFC6962_SHA256": 3,
 	"CONIKS_SHA512_256":     4,
+	"RFC6962_SHA384":        5,
+	"RFC6962_SHA512":        6,
 }
 
 func (x HashStrategy) String() string {
@@ -225,7 +235,8 @@ type Tree struct {
 	// Private keys are write-only: they're never returned by RPCs.
 	// The private_key message can be changed after a tree is created, but the
 	// underlying key must remain the same - this is to enable migrating a key
-	// from one provider to another.
+	// from one provider to another. To sign a log with a different key, add a
+	// successor key to key_history instead.
 	PrivateKey *google_protobuf2.Any `protobuf:"bytes,12,opt,name=private_key,json=privateKey" json:"private_key,omitempty"`
 	// Storage-specific settings.
 	// Varies according to the storage implementation backing Trillian.
@@ -250,6 +261,25 @@ type Tree struct {
 	// Time of tree deletion, if any.
 	// Readonly.
 	DeleteTime *google_protobuf1.Timestamp `protobuf:"bytes,20,opt,name=delete_time,json=deleteTime" json:"delete_time,omitempty"`
+	// Settings for sequencing the tree, which override the defaults of the log
+	// signer. Only used by LOG and PREORDERED_LOG trees.
+	// Optional.
+	SequencerConfig *SequencerConfig `protobuf:"bytes,21,opt,name=sequencer_config,json=sequencerConfig" json:"sequencer_config,omitempty"`
+	// Labels attached to the tree, e.g. to group the shards of a log. ListTrees
+	// can filter trees by their labels.
+	// Keys are at most 63 characters of lowercase letters, digits, '-', '_' and
+	// '.', and values are at most 255 characters long.
+	// Optional.
+	Labels map[string]string `protobuf:"bytes,22,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Keys which succeed the original key of the tree (private_key and
+	// public_key) in signing its log roots, ordered by start_tree_size. Each key
+	// signs the roots from its start_tree_size up to the start_tree_size of the
+	// next key, and the original key signs the roots smaller than the first
+	// start_tree_size.
+	// Keys may be appended by UpdateTree, but not changed or removed. Only used
+	// by LOG and PREORDERED_LOG trees.
+	// Optional.
+	KeyHistory []*SigningKey `protobuf:"bytes,23,rep,name=key_history,json=keyHistory" json:"key_history,omitempty"`
 }
 
 func (m *Tree) Reset()                    { *m = Tree{} }
@@ -369,6 +399,27 @@ func (m *Tree) GetDeleteTime() *google_protobuf1.Timestamp {
 	return nil
 }
 
+func (m *Tree) GetSequencerConfig() *SequencerConfig {
+	if m != nil {
+		return m.SequencerConfig
+	}
+	return nil
+}
+
+func (m *Tree) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Tree) GetKeyHistory() []*SigningKey {
+	if m != nil {
+		return m.KeyHistory
+	}
+	return nil
+}
+
 type SignedEntryTimestamp struct {
 	TimestampNanos int64                  `protobuf:"varint,1,opt,name=timestamp_nanos,json=timestampNanos" json:"timestamp_nanos,omitempty"`
 	LogId          int64                  `protobuf:"varint,2,opt,name=log_id,json=logId" json:"log_id,omitempty"`
@@ -538,11 +589,127 @@ func (m *SignedMapRoot) GetSignature() []byte {
 	return nil
 }
 
+// SequencerConfig holds the settings used to sequence a single log. Unset
+// fields fall back to the defaults of the log signer.
+type SequencerConfig struct {
+	// If true, the log isn't sequenced. Leaves may still be queued while it's
+	// paused, and are sequenced once it's resumed.
+	Paused bool `protobuf:"varint,1,opt,name=paused" json:"paused,omitempty"`
+	// Maximum number of leaves sequenced in each pass.
+	// If zero, the log signer's batch size is used.
+	BatchSize int32 `protobuf:"varint,2,opt,name=batch_size,json=batchSize" json:"batch_size,omitempty"`
+	// Leaves queued more recently than the guard window aren't sequenced yet.
+	// If unset, the log signer's guard window is used.
+	GuardWindow *google_protobuf3.Duration `protobuf:"bytes,3,opt,name=guard_window,json=guardWindow" json:"guard_window,omitempty"`
+	// Interval after which a new signed root is produced even if there have been
+	// no submissions. If unset, the tree's max_root_duration is used.
+	MaxRootDuration *google_protobuf3.Duration `protobuf:"bytes,4,opt,name=max_root_duration,json=maxRootDuration" json:"max_root_duration,omitempty"`
+	// Logs with a higher priority are sequenced before logs with a lower
+	// priority in each pass of the log signer.
+	Priority int32 `protobuf:"varint,5,opt,name=priority" json:"priority,omitempty"`
+}
+
+func (m *SequencerConfig) Reset()                    { *m = SequencerConfig{} }
+func (m *SequencerConfig) String() string            { return proto.CompactTextString(m) }
+func (*SequencerConfig) ProtoMessage()               {}
+func (*SequencerConfig) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
+
+func (m *SequencerConfig) GetPaused() bool {
+	if m != nil {
+		return m.Paused
+	}
+	return false
+}
+
+func (m *SequencerConfig) GetBatchSize() int32 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+func (m *SequencerConfig) GetGuardWindow() *google_protobuf3.Duration {
+	if m != nil {
+		return m.GuardWindow
+	}
+	return nil
+}
+
+func (m *SequencerConfig) GetMaxRootDuration() *google_protobuf3.Duration {
+	if m != nil {
+		return m.MaxRootDuration
+	}
+	return nil
+}
+
+func (m *SequencerConfig) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+// SigningKey is a key which succeeds the original key of a log in signing its
+// roots, from a given tree size onwards.
+type SigningKey struct {
+	// ID of the key, unique within the tree. Roots signed by the key have its
+	// ID, encoded as a big-endian 64-bit integer, as their key_hint (the roots
+	// signed by the original key have the tree ID).
+	// Assigned by the server. Readonly.
+	KeyId int64 `protobuf:"varint,1,opt,name=key_id,json=keyId" json:"key_id,omitempty"`
+	// Private key used for signing, as in Tree.private_key.
+	// Write-only.
+	PrivateKey *google_protobuf2.Any `protobuf:"bytes,2,opt,name=private_key,json=privateKey" json:"private_key,omitempty"`
+	// Public key which verifies the roots signed by the key.
+	// Derived from private_key by the server. Readonly.
+	PublicKey *keyspb.PublicKey `protobuf:"bytes,3,opt,name=public_key,json=publicKey" json:"public_key,omitempty"`
+	// Size of the smallest tree whose roots are signed by the key. Must be
+	// greater than the size of the log when the key is added, so that no root
+	// of that size has been signed yet.
+	// Readonly.
+	StartTreeSize int64 `protobuf:"varint,4,opt,name=start_tree_size,json=startTreeSize" json:"start_tree_size,omitempty"`
+}
+
+func (m *SigningKey) Reset()                    { *m = SigningKey{} }
+func (m *SigningKey) String() string            { return proto.CompactTextString(m) }
+func (*SigningKey) ProtoMessage()               {}
+func (*SigningKey) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+
+func (m *SigningKey) GetKeyId() int64 {
+	if m != nil {
+		return m.KeyId
+	}
+	return 0
+}
+
+func (m *SigningKey) GetPrivateKey() *google_protobuf2.Any {
+	if m != nil {
+		return m.PrivateKey
+	}
+	return nil
+}
+
+func (m *SigningKey) GetPublicKey() *keyspb.PublicKey {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *SigningKey) GetStartTreeSize() int64 {
+	if m != nil {
+		return m.StartTreeSize
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Tree)(nil), "trillian.Tree")
 	proto.RegisterType((*SignedEntryTimestamp)(nil), "trillian.SignedEntryTimestamp")
 	proto.RegisterType((*SignedLogRoot)(nil), "trillian.SignedLogRoot")
 	proto.RegisterType((*SignedMapRoot)(nil), "trillian.SignedMapRoot")
+	proto.RegisterType((*SequencerConfig)(nil), "trillian.SequencerConfig")
+	proto.RegisterType((*SigningKey)(nil), "trillian.SigningKey")
 	proto.RegisterEnum("trillian.LogRootFormat", LogRootFormat_name, LogRootFormat_value)
 	proto.RegisterEnum("trillian.MapRootFormat", MapRootFormat_name, MapRootFormat_value)
 	proto.RegisterEnum("trillian.HashStrategy", HashStrategy_name, HashStrategy_value)
@@ -553,74 +720,90 @@ func init() {
 func init() { proto.RegisterFile("trillian.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 1097 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xdb, 0x6f, 0xdb, 0x36,
-	0x17, 0xaf, 0x6c, 0xc5, 0x96, 0xe9, 0x4b, 0x18, 0xa6, 0x17, 0xd9, 0xfd, 0xf0, 0xd5, 0xcb, 0x06,
-	0x2c, 0x2b, 0x06, 0x67, 0xf5, 0xd6, 0x02, 0x43, 0x1f, 0x06, 0x35, 0x56, 0x62, 0x3b, 0x89, 0x6d,
-	0x50, 0x5a, 0x87, 0xe6, 0x85, 0x90, 0x6d, 0x4e, 0x16, 0xa2, 0x1b, 0x24, 0xba, 0xa8, 0xfa, 0x3c,
-	0x60, 0x18, 0xb6, 0x3f, 0x7a, 0x20, 0x45, 0xd9, 0x89, 0xb3, 0xae, 0x7b, 0x49, 0x78, 0xce, 0xef,
-	0xc2, 0x23, 0xf2, 0x1c, 0xc9, 0xa0, 0xc5, 0x12, 0xcf, 0xf7, 0x3d, 0x27, 0xec, 0xc5, 0x49, 0xc4,
-	0x22, 0xa4, 0x15, 0x71, 0xa7, 0xb3, 0x48, 0xb2, 0x98, 0x45, 0x27, 0x37, 0x34, 0x4b, 0xe3, 0xb9,
-	0xfc, 0x97, 0xb3, 0x3a, 0xba, 0xc4, 0x52, 0xcf, 0x8d, 0xe7, 0xf9, 0x5f, 0x89, 0xb4, 0xdd, 0x28,
-	0x72, 0x7d, 0x7a, 0x22, 0xa2, 0xf9, 0xfa, 0xd7, 0x13, 0x27, 0xcc, 0x24, 0xf4, 0xff, 0x5d, 0x68,
-	0xb9, 0x4e, 0x1c, 0xe6, 0x45, 0x72, 0xeb, 0xce, 0xb3, 0x5d, 0x9c, 0x79, 0x01, 0x4d, 0x99, 0x13,
-	0xc4, 0x39, 0xe1, 0xe8, 0x8f, 0x2a, 0x50, 0xed, 0x84, 0x52, 0xf4, 0x04, 0x54, 0x59, 0x42, 0x29,
-	0xf1, 0x96, 0xba, 0xd2, 0x55, 0x8e, 0xcb, 0xb8, 0xc2, 0xc3, 0xd1, 0x12, 0xf5, 0x01, 0x10, 0x40,
-	0xca, 0x1c, 0x46, 0xf5, 0x52, 0x57, 0x39, 0x6e, 0xf5, 0x0f, 0x7b, 0x9b, 0x47, 0xe4, 0x62, 0x8b,
-	0x43, 0xb8, 0xc6, 0x8a, 0x25, 0x3a, 0x01, 0x22, 0x20, 0x2c, 0x8b, 0xa9, 0x5e, 0x16, 0x12, 0x74,
-	0x57, 0x62, 0x67, 0x31, 0xc5, 0x1a, 0x93, 0x2b, 0xf4, 0x1a, 0x34, 0x57, 0x4e, 0xba, 0x22, 0x29,
-	0x4b, 0x1c, 0x46, 0xdd, 0x4c, 0x57, 0x85, 0xe8, 0xf1, 0x56, 0x34, 0x74, 0xd2, 0x95, 0x25, 0x51,
-	0xdc, 0x58, 0xdd, 0x8a, 0xd0, 0x05, 0x68, 0x09, 0xb1, 0xe3, 0xbb, 0x51, 0xe2, 0xb1, 0x55, 0xa0,
-	0xef, 0x09, 0xf5, 0x57, 0xbd, 0xfc, 0x14, 0x07, 0x9e, 0xeb, 0x31, 0xc7, 0xf7, 0x33, 0xcb, 0x73,
-	0x43, 0xba, 0x14, 0x56, 0x46, 0xc1, 0xc5, 0x62, 0xe3, 0x4d, 0x88, 0xae, 0xc1, 0x61, 0xea, 0xb9,
-	0xa1, 0xc3, 0xd6, 0x09, 0xbd, 0xe5, 0x58, 0x11, 0x8e, 0xdf, 0x7c, 0xc2, 0xd1, 0x2a, 0x14, 0x5b,
-	0x5b, 0x94, 0xde, 0xcb, 0xa1, 0x2f, 0x40, 0x63, 0xe9, 0xa5, 0xb1, 0xef, 0x64, 0x24, 0x74, 0x02,
-	0xaa, 0x6b, 0x5d, 0xe5, 0xb8, 0x86, 0xeb, 0x32, 0x37, 0x71, 0x02, 0x8a, 0xba, 0xa0, 0xbe, 0xa4,
-	0xe9, 0x22, 0xf1, 0x62, 0x7e, 0x8b, 0x7a, 0x4d, 0x32, 0xb6, 0x29, 0xf4, 0x12, 0xd4, 0xe3, 0xc4,
-	0x7b, 0xef, 0x30, 0x4a, 0x6e, 0x68, 0xa6, 0x37, 0xba, 0xca, 0x71, 0xbd, 0xff, 0xb0, 0x97, 0x5f,
-	0x74, 0xaf, 0xb8, 0xe8, 0x9e, 0x11, 0x66, 0x18, 0x48, 0xe2, 0x05, 0xcd, 0xd0, 0x4f, 0x00, 0xa6,
-	0x2c, 0x4a, 0x1c, 0x97, 0x92, 0x94, 0x32, 0xe6, 0x85, 0x6e, 0xaa, 0x37, 0xff, 0x45, 0xbb, 0x2f,
-	0xd9, 0x96, 0x24, 0xa3, 0xef, 0x00, 0x88, 0xd7, 0x73, 0xdf, 0x5b, 0x88, 0x6d, 0x5b, 0x42, 0x7a,
-	0xd0, 0x93, 0x2d, 0x3c, 0x13, 0xc8, 0x05, 0xcd, 0x70, 0x2d, 0x2e, 0x96, 0xc8, 0x04, 0x07, 0x81,
-	0xf3, 0x81, 0x24, 0x51, 0xc4, 0x48, 0xd1, 0x97, 0xfa, 0xbe, 0x10, 0xb6, 0xef, 0xed, 0x39, 0x90,
-	0x04, 0xbc, 0x1f, 0x38, 0x1f, 0x70, 0x14, 0xb1, 0x22, 0x81, 0x5e, 0x83, 0xfa, 0x22, 0xa1, 0xfc,
-	0x79, 0x79, 0xf3, 0xea, 0x50, 0x18, 0x74, 0xee, 0x19, 0xd8, 0x45, 0x67, 0x63, 0x90, 0xd3, 0x79,
-	0x82, 0x8b, 0xd7, 0xf1, 0x72, 0x23, 0x3e, 0xf8, 0xbc, 0x38, 0xa7, 0x0b, 0xb1, 0x0e, 0xaa, 0x4b,
-	0xea, 0x53, 0x46, 0x97, 0xfa, 0x61, 0x57, 0x39, 0xd6, 0x70, 0x11, 0x72, 0xdb, 0x7c, 0x99, 0xdb,
-	0x3e, 0xfc, 0xbc, 0x6d, 0x4e, 0xe7, 0x89, 0xb1, 0xaa, 0x21, 0x78, 0x38, 0x56, 0xb5, 0x2a, 0xd4,
-	0xc6, 0xaa, 0x06, 0x60, 0x7d, 0xac, 0x6a, 0x75, 0xd8, 0x38, 0xfa, 0x4b, 0x01, 0x0f, 0xf3, 0x86,
-	0x32, 0x43, 0x96, 0x64, 0x1b, 0x31, 0xfa, 0x1a, 0xec, 0x6f, 0xe6, 0x96, 0x84, 0x4e, 0x18, 0xa5,
-	0x72, 0x46, 0x5b, 0x9b, 0xf4, 0x84, 0x67, 0xd1, 0x23, 0x50, 0xf1, 0x23, 0x97, 0xcf, 0x70, 0x49,
-	0xe0, 0x7b, 0x7e, 0xe4, 0x8e, 0x96, 0xe8, 0x07, 0x50, 0xdb, 0x74, 0xa3, 0x18, 0xc7, 0x7a, 0xff,
-	0xf1, 0x3f, 0x77, 0x32, 0xde, 0x12, 0x8f, 0x7e, 0x2f, 0x81, 0x66, 0x9e, 0xbd, 0x8c, 0x5c, 0x7e,
-	0x23, 0xff, 0xbd, 0x8e, 0xa7, 0xa0, 0x26, 0x6e, 0x9d, 0x8f, 0x96, 0x28, 0xa5, 0x81, 0x35, 0x9e,
-	0xe0, 0x93, 0xc7, 0xc1, 0xfc, 0x85, 0xe2, 0x7d, 0xcc, 0xab, 0x29, 0xe7, 0x2f, 0x02, 0xcb, 0xfb,
-	0x48, 0xd1, 0x97, 0xa0, 0x29, 0xc0, 0x84, 0xbe, 0xf7, 0x52, 0xde, 0x2f, 0x15, 0x41, 0x68, 0xf0,
-	0x24, 0x96, 0x39, 0xd4, 0x06, 0xda, 0x0d, 0xcd, 0xc8, 0xca, 0x0b, 0x99, 0x5e, 0x15, 0xee, 0xd5,
-	0x1b, 0x9a, 0x0d, 0xbd, 0x90, 0x71, 0x88, 0x9f, 0x00, 0xdf, 0x4c, 0x8c, 0x57, 0x03, 0x57, 0x7d,
-	0x59, 0xfd, 0xb7, 0x00, 0x15, 0x10, 0xd9, 0x1e, 0x47, 0x4d, 0x90, 0xa0, 0x24, 0x6d, 0x06, 0x79,
-	0xac, 0x6a, 0x2a, 0xdc, 0x1b, 0xab, 0xda, 0x1e, 0xac, 0x1c, 0x25, 0xc5, 0x41, 0x5c, 0x39, 0xb1,
-	0xb0, 0x6a, 0x03, 0x2d, 0x70, 0xe2, 0x7c, 0x97, 0xdc, 0xa0, 0x1a, 0x48, 0xe8, 0x7f, 0xb7, 0xcf,
-	0x5a, 0x15, 0xd8, 0x36, 0x31, 0x56, 0x35, 0x05, 0x96, 0xc6, 0xaa, 0x56, 0x82, 0xe5, 0xb1, 0xaa,
-	0x95, 0xa1, 0x9a, 0xef, 0x30, 0x56, 0xb5, 0x0a, 0xac, 0x6e, 0x5a, 0x42, 0x83, 0xb5, 0xe7, 0x03,
-	0xd0, 0x94, 0xc7, 0x7e, 0x16, 0x25, 0x81, 0xc3, 0xd0, 0x53, 0xf0, 0xe4, 0x72, 0x7a, 0x4e, 0xf0,
-	0x74, 0x6a, 0x93, 0xb3, 0x29, 0xbe, 0x32, 0x6c, 0xf2, 0xf3, 0xe4, 0x62, 0x32, 0xfd, 0x65, 0x02,
-	0x1f, 0xa0, 0xc7, 0x00, 0xed, 0x82, 0x6f, 0x5f, 0x40, 0x85, 0xbb, 0xc8, 0x9a, 0xb7, 0x2e, 0x57,
-	0xc6, 0xec, 0xd3, 0x2e, 0xbb, 0xa0, 0x70, 0xf9, 0x4d, 0x01, 0x8d, 0xdb, 0xef, 0x5f, 0xd4, 0x06,
-	0x8f, 0xa4, 0x8a, 0x0c, 0x0d, 0x6b, 0x48, 0x2c, 0x1b, 0x1b, 0xb6, 0x79, 0xfe, 0x0e, 0x3e, 0x40,
-	0x08, 0xb4, 0xf0, 0xd9, 0xe9, 0xab, 0x1f, 0x5f, 0xf5, 0x89, 0x35, 0x34, 0xfa, 0x2f, 0x5f, 0x41,
-	0x05, 0x1d, 0x82, 0x7d, 0xdb, 0xb4, 0x6c, 0xc2, 0xcd, 0x39, 0xdf, 0xc4, 0xb0, 0xc4, 0x3d, 0xa6,
-	0x6f, 0xc6, 0xe6, 0xa9, 0x4d, 0x76, 0xf8, 0x65, 0xf4, 0x08, 0x1c, 0x9c, 0x4e, 0x27, 0xa3, 0x0b,
-	0x8b, 0xa7, 0x5e, 0xbe, 0xe8, 0x13, 0x9e, 0x56, 0x9f, 0xff, 0xa9, 0x80, 0xda, 0xe6, 0x73, 0xc3,
-	0x8b, 0x2d, 0x6a, 0xb0, 0xb1, 0x69, 0x12, 0xcb, 0x36, 0x6c, 0x13, 0x3e, 0x40, 0x00, 0x54, 0x8c,
-	0x53, 0x7b, 0xf4, 0xd6, 0x84, 0x0a, 0x5f, 0x9f, 0xe1, 0xe9, 0xb5, 0x39, 0x81, 0x25, 0xf4, 0x0c,
-	0x3c, 0x19, 0x98, 0x33, 0x6c, 0x9e, 0x1a, 0xb6, 0x39, 0x20, 0xd6, 0xf4, 0xcc, 0x26, 0x03, 0xf3,
-	0xd2, 0xb4, 0xcd, 0x01, 0x2c, 0x77, 0x4a, 0x9a, 0xb2, 0x43, 0x18, 0x1a, 0x78, 0xb0, 0x21, 0xa8,
-	0x82, 0xd0, 0x00, 0xda, 0x00, 0x1b, 0xa3, 0xc9, 0x68, 0x72, 0x0e, 0xf7, 0x9e, 0x9f, 0x03, 0xad,
-	0xf8, 0x90, 0xf1, 0x82, 0xef, 0xd4, 0x62, 0xbf, 0x9b, 0xf1, 0x52, 0xaa, 0xa0, 0x7c, 0x39, 0x3d,
-	0x87, 0x0a, 0x5f, 0x5c, 0x19, 0x33, 0x58, 0xe2, 0xa7, 0x33, 0xc3, 0xe6, 0x14, 0x0f, 0x4c, 0x6c,
-	0x0e, 0x08, 0x07, 0xcb, 0x6f, 0x86, 0xa0, 0xbd, 0x88, 0x82, 0xe2, 0xdd, 0x71, 0xf7, 0xb7, 0xc3,
-	0x9b, 0xa6, 0x2d, 0xe3, 0x19, 0x0f, 0x67, 0xca, 0x75, 0xc7, 0xf5, 0xd8, 0x6a, 0x3d, 0xef, 0x2d,
-	0xa2, 0xe0, 0x44, 0x7e, 0xdc, 0x0b, 0xc9, 0xbc, 0x22, 0x34, 0xdf, 0xff, 0x1d, 0x00, 0x00, 0xff,
-	0xff, 0x19, 0x4e, 0xf0, 0x51, 0x81, 0x08, 0x00, 0x00,
+	// 1358 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0x5d, 0x73, 0xda, 0x46,
+	0x17, 0x8e, 0x40, 0x80, 0x38, 0x80, 0x59, 0xaf, 0xbf, 0x64, 0xf2, 0xbe, 0x6f, 0xfc, 0xba, 0x9d,
+	0xd6, 0xcd, 0x74, 0x70, 0x43, 0xe2, 0x4c, 0xd2, 0x74, 0xa6, 0x43, 0x8c, 0xfc, 0x81, 0x6d, 0x60,
+	0x56, 0x6a, 0x32, 0xc9, 0x8d, 0x46, 0xc0, 0x46, 0x68, 0x2c, 0x24, 0x55, 0x5a, 0x92, 0x28, 0x7f,
+	0xa0, 0x17, 0xed, 0x4f, 0xe9, 0x65, 0xff, 0x54, 0x7f, 0x40, 0xef, 0x3b, 0xbb, 0x92, 0x00, 0x93,
+	0xa4, 0x49, 0x6f, 0x60, 0xcf, 0x79, 0x9e, 0xf3, 0xec, 0xee, 0xd9, 0xb3, 0x47, 0x0b, 0x6b, 0x2c,
+	0x74, 0x5c, 0xd7, 0xb1, 0xbc, 0x66, 0x10, 0xfa, 0xcc, 0xc7, 0x4a, 0x66, 0x37, 0x1a, 0xa3, 0x30,
+	0x0e, 0x98, 0x7f, 0x78, 0x4d, 0xe3, 0x28, 0x18, 0xa6, 0x7f, 0x09, 0xab, 0xa1, 0xa6, 0x58, 0xe4,
+	0xd8, 0xc1, 0x30, 0xf9, 0x4d, 0x91, 0x5d, 0xdb, 0xf7, 0x6d, 0x97, 0x1e, 0x0a, 0x6b, 0x38, 0x7b,
+	0x75, 0x68, 0x79, 0x71, 0x0a, 0xfd, 0x6f, 0x15, 0x1a, 0xcf, 0x42, 0x8b, 0x39, 0x7e, 0x3a, 0x75,
+	0xe3, 0xce, 0x2a, 0xce, 0x9c, 0x29, 0x8d, 0x98, 0x35, 0x0d, 0x12, 0xc2, 0xfe, 0x5f, 0x0a, 0xc8,
+	0x46, 0x48, 0x29, 0xde, 0x81, 0x12, 0x0b, 0x29, 0x35, 0x9d, 0xb1, 0x2a, 0xed, 0x49, 0x07, 0x79,
+	0x52, 0xe4, 0xe6, 0xf9, 0x18, 0xb7, 0x00, 0x04, 0x10, 0x31, 0x8b, 0x51, 0x35, 0xb7, 0x27, 0x1d,
+	0xac, 0xb5, 0x36, 0x9a, 0xf3, 0x2d, 0xf2, 0x60, 0x9d, 0x43, 0xa4, 0xcc, 0xb2, 0x21, 0x3e, 0x04,
+	0x61, 0x98, 0x2c, 0x0e, 0xa8, 0x9a, 0x17, 0x21, 0xf8, 0x66, 0x88, 0x11, 0x07, 0x94, 0x28, 0x2c,
+	0x1d, 0xe1, 0x27, 0x50, 0x9b, 0x58, 0xd1, 0xc4, 0x8c, 0x58, 0x68, 0x31, 0x6a, 0xc7, 0xaa, 0x2c,
+	0x82, 0xb6, 0x17, 0x41, 0x67, 0x56, 0x34, 0xd1, 0x53, 0x94, 0x54, 0x27, 0x4b, 0x16, 0xbe, 0x80,
+	0x35, 0x11, 0x6c, 0xb9, 0xb6, 0x1f, 0x3a, 0x6c, 0x32, 0x55, 0x0b, 0x22, 0xfa, 0xcb, 0x66, 0x92,
+	0xc5, 0x8e, 0x63, 0x3b, 0xcc, 0x72, 0xdd, 0x58, 0x77, 0x6c, 0x8f, 0x8e, 0x85, 0x54, 0x3b, 0xe3,
+	0x92, 0xda, 0x64, 0xd9, 0xc4, 0x2f, 0x61, 0x23, 0x72, 0x6c, 0xcf, 0x62, 0xb3, 0x90, 0x2e, 0x29,
+	0x16, 0x85, 0xe2, 0x37, 0x1f, 0x51, 0xd4, 0xb3, 0x88, 0x85, 0x2c, 0x8e, 0xde, 0xf3, 0xe1, 0xff,
+	0x43, 0x75, 0xec, 0x44, 0x81, 0x6b, 0xc5, 0xa6, 0x67, 0x4d, 0xa9, 0xaa, 0xec, 0x49, 0x07, 0x65,
+	0x52, 0x49, 0x7d, 0x3d, 0x6b, 0x4a, 0xf1, 0x1e, 0x54, 0xc6, 0x34, 0x1a, 0x85, 0x4e, 0xc0, 0x4f,
+	0x51, 0x2d, 0xa7, 0x8c, 0x85, 0x0b, 0x1f, 0x41, 0x25, 0x08, 0x9d, 0xd7, 0x16, 0xa3, 0xe6, 0x35,
+	0x8d, 0xd5, 0xea, 0x9e, 0x74, 0x50, 0x69, 0x6d, 0x36, 0x93, 0x83, 0x6e, 0x66, 0x07, 0xdd, 0x6c,
+	0x7b, 0x31, 0x81, 0x94, 0x78, 0x41, 0x63, 0xfc, 0x23, 0xa0, 0x88, 0xf9, 0xa1, 0x65, 0x53, 0x33,
+	0xa2, 0x8c, 0x39, 0x9e, 0x1d, 0xa9, 0xb5, 0x7f, 0x88, 0xad, 0xa7, 0x6c, 0x3d, 0x25, 0xe3, 0xef,
+	0x00, 0x82, 0xd9, 0xd0, 0x75, 0x46, 0x62, 0xda, 0x35, 0x11, 0xba, 0xde, 0x4c, 0x4b, 0x78, 0x20,
+	0x90, 0x0b, 0x1a, 0x93, 0x72, 0x90, 0x0d, 0xb1, 0x06, 0xeb, 0x53, 0xeb, 0xad, 0x19, 0xfa, 0x3e,
+	0x33, 0xb3, 0xba, 0x54, 0xeb, 0x22, 0x70, 0xf7, 0xbd, 0x39, 0x3b, 0x29, 0x81, 0xd4, 0xa7, 0xd6,
+	0x5b, 0xe2, 0xfb, 0x2c, 0x73, 0xe0, 0x27, 0x50, 0x19, 0x85, 0x94, 0xef, 0x97, 0x17, 0xaf, 0x8a,
+	0x84, 0x40, 0xe3, 0x3d, 0x01, 0x23, 0xab, 0x6c, 0x02, 0x09, 0x9d, 0x3b, 0x78, 0xf0, 0x2c, 0x18,
+	0xcf, 0x83, 0xd7, 0x3f, 0x1d, 0x9c, 0xd0, 0x45, 0xb0, 0x0a, 0xa5, 0x31, 0x75, 0x29, 0xa3, 0x63,
+	0x75, 0x63, 0x4f, 0x3a, 0x50, 0x48, 0x66, 0x72, 0xd9, 0x64, 0x98, 0xc8, 0x6e, 0x7e, 0x5a, 0x36,
+	0xa1, 0x0b, 0xd9, 0x0e, 0xa0, 0x88, 0xfe, 0x3c, 0xa3, 0xde, 0x88, 0x86, 0xe6, 0xc8, 0xf7, 0x5e,
+	0x39, 0xb6, 0xba, 0x95, 0xa6, 0x65, 0x5e, 0xef, 0x7a, 0xc6, 0x38, 0x16, 0x04, 0x52, 0x8f, 0x6e,
+	0x3a, 0x70, 0x0b, 0x8a, 0xae, 0x35, 0xa4, 0x6e, 0xa4, 0x6e, 0xef, 0xe5, 0xc5, 0xec, 0x37, 0x2e,
+	0x58, 0xf3, 0x52, 0x80, 0x9a, 0xc7, 0xc2, 0x98, 0xa4, 0x4c, 0x5e, 0x3b, 0xd7, 0x34, 0x36, 0x27,
+	0x0e, 0x3f, 0xdc, 0x58, 0xdd, 0x11, 0x81, 0x9b, 0x4b, 0x93, 0x3a, 0xb6, 0xe7, 0x78, 0x36, 0x3f,
+	0x47, 0xb8, 0xa6, 0xf1, 0x59, 0xc2, 0x6b, 0x3c, 0x86, 0xca, 0x92, 0x1a, 0x46, 0x90, 0xe7, 0x25,
+	0x20, 0x89, 0xda, 0xe4, 0x43, 0xbc, 0x09, 0x85, 0xd7, 0x96, 0x3b, 0x4b, 0xda, 0x43, 0x99, 0x24,
+	0xc6, 0xf7, 0xb9, 0x47, 0x52, 0x57, 0x56, 0x30, 0xda, 0xe8, 0xca, 0x4a, 0x09, 0x29, 0x5d, 0x59,
+	0x01, 0x54, 0xe9, 0xca, 0x4a, 0x05, 0x55, 0xf7, 0x7f, 0x93, 0x60, 0x33, 0xb9, 0x3c, 0x42, 0x73,
+	0x9e, 0x28, 0xfc, 0x35, 0xd4, 0xe7, 0x3d, 0xca, 0xf4, 0x2c, 0xcf, 0x8f, 0xd2, 0x7e, 0xb4, 0x36,
+	0x77, 0xf7, 0xb8, 0x17, 0x6f, 0x41, 0xd1, 0xf5, 0x6d, 0xde, 0xaf, 0x72, 0x02, 0x2f, 0xb8, 0xbe,
+	0x7d, 0x3e, 0xc6, 0x0f, 0xa0, 0x3c, 0xbf, 0x79, 0xa2, 0xf5, 0x54, 0x5a, 0xdb, 0x1f, 0xbe, 0xb5,
+	0x64, 0x41, 0xdc, 0xff, 0x25, 0x07, 0xb5, 0xc4, 0x7b, 0xe9, 0xdb, 0xbc, 0xfa, 0x3e, 0x7f, 0x1d,
+	0xb7, 0xa1, 0x2c, 0x2a, 0x9c, 0xb7, 0x11, 0xb1, 0x94, 0x2a, 0x51, 0xb8, 0x83, 0x77, 0x19, 0x0e,
+	0x26, 0xcd, 0xd3, 0x79, 0x97, 0xac, 0x26, 0x9f, 0x34, 0x3d, 0xdd, 0x79, 0x47, 0xf1, 0x17, 0x50,
+	0x13, 0x60, 0x48, 0x5f, 0x3b, 0x11, 0xbf, 0x1b, 0x45, 0x41, 0xa8, 0x72, 0x27, 0x49, 0x7d, 0x78,
+	0x17, 0x94, 0xe4, 0xc8, 0x3c, 0xa6, 0x96, 0x84, 0x7a, 0x49, 0x9c, 0x8c, 0xc7, 0x38, 0xc4, 0x33,
+	0xc0, 0x27, 0x13, 0xad, 0xa4, 0x4a, 0x4a, 0x6e, 0xba, 0xfa, 0x6f, 0x01, 0x67, 0x90, 0xb9, 0x48,
+	0x47, 0x59, 0x90, 0x50, 0x4a, 0x9a, 0x37, 0xad, 0xae, 0xac, 0xc8, 0xa8, 0xd0, 0x95, 0x95, 0x02,
+	0x2a, 0xee, 0x87, 0x59, 0x22, 0xae, 0xac, 0x40, 0x48, 0xed, 0x82, 0x32, 0xb5, 0x82, 0x64, 0x96,
+	0x44, 0xa0, 0x34, 0x4d, 0xa1, 0xff, 0x2c, 0xe7, 0x5a, 0x16, 0xd8, 0xc2, 0xd1, 0x95, 0x15, 0x09,
+	0xe5, 0xba, 0xb2, 0x92, 0x43, 0xf9, 0xae, 0xac, 0xe4, 0x91, 0x9c, 0xcc, 0xd0, 0x95, 0x95, 0x22,
+	0x2a, 0xcd, 0x4b, 0x42, 0x41, 0xe5, 0xfd, 0x3f, 0x25, 0xa8, 0xaf, 0xd4, 0x3b, 0xde, 0x86, 0x62,
+	0x60, 0xcd, 0x22, 0x9a, 0x7c, 0x8e, 0x14, 0x92, 0x5a, 0xf8, 0xbf, 0x00, 0x43, 0x8b, 0x8d, 0x26,
+	0x49, 0x4a, 0x79, 0xbe, 0x0b, 0xa4, 0x2c, 0x3c, 0x22, 0xa7, 0x3f, 0x40, 0xd5, 0x9e, 0x59, 0xe1,
+	0xd8, 0x7c, 0xe3, 0x78, 0x63, 0xff, 0x8d, 0x9a, 0xff, 0x54, 0xbb, 0xa9, 0x08, 0xfa, 0x73, 0xc1,
+	0xfe, 0x70, 0xc7, 0x92, 0xff, 0x75, 0xc7, 0x6a, 0x80, 0x12, 0x84, 0x0e, 0x6f, 0xfa, 0xb1, 0xf8,
+	0x14, 0x15, 0xc8, 0xdc, 0xde, 0xff, 0x43, 0x02, 0x58, 0x5c, 0x33, 0x5e, 0xc5, 0xfc, 0x78, 0xe7,
+	0x5f, 0xdd, 0xc2, 0x35, 0x8d, 0xcf, 0xc7, 0xab, 0x4d, 0x3e, 0xf7, 0x99, 0x4d, 0xfe, 0x66, 0x8f,
+	0xce, 0x7f, 0x46, 0x8f, 0xfe, 0x0a, 0xea, 0x11, 0xb3, 0x42, 0x66, 0x2e, 0xca, 0x54, 0x16, 0x0b,
+	0xa9, 0x09, 0xb7, 0x91, 0xd6, 0xea, 0xdd, 0x0e, 0xd4, 0xd2, 0x9b, 0x71, 0xe2, 0x87, 0x53, 0x8b,
+	0xe1, 0xdb, 0xb0, 0x73, 0xd9, 0x3f, 0x35, 0x49, 0xbf, 0x6f, 0x98, 0x27, 0x7d, 0x72, 0xd5, 0x36,
+	0xcc, 0x9f, 0x7a, 0x17, 0xbd, 0xfe, 0xf3, 0x1e, 0xba, 0x85, 0xb7, 0x01, 0xaf, 0x82, 0xcf, 0xee,
+	0x21, 0x89, 0xab, 0xa4, 0x65, 0xb5, 0x50, 0xb9, 0x6a, 0x0f, 0x3e, 0xae, 0xb2, 0x0a, 0x0a, 0x95,
+	0xdf, 0x25, 0xa8, 0x2e, 0x3f, 0x07, 0xf0, 0x2e, 0x6c, 0xa5, 0x51, 0xe6, 0x59, 0x5b, 0x3f, 0x33,
+	0x75, 0x83, 0xb4, 0x0d, 0xed, 0xf4, 0x05, 0xba, 0x85, 0x31, 0xac, 0x91, 0x93, 0xe3, 0x87, 0x8f,
+	0x1f, 0xb6, 0x4c, 0xfd, 0xac, 0xdd, 0x3a, 0x7a, 0x88, 0x24, 0xbc, 0x01, 0x75, 0x43, 0xd3, 0x0d,
+	0x93, 0x8b, 0x73, 0xbe, 0x46, 0x50, 0x8e, 0x6b, 0xf4, 0x9f, 0x76, 0xb5, 0x63, 0xc3, 0x5c, 0xe1,
+	0xe7, 0xf1, 0x16, 0xac, 0x1f, 0xf7, 0x7b, 0xe7, 0x17, 0x3a, 0x77, 0x1d, 0xdd, 0x6b, 0x99, 0xdc,
+	0x2d, 0xaf, 0x48, 0xdf, 0x7f, 0xf4, 0x00, 0x15, 0x56, 0x7c, 0x47, 0xf7, 0x5a, 0xa8, 0x78, 0xf7,
+	0x57, 0x09, 0xca, 0xf3, 0x57, 0x12, 0xdf, 0x54, 0xb6, 0x56, 0x83, 0x68, 0x9a, 0xa9, 0x1b, 0x6d,
+	0x43, 0x43, 0xb7, 0x30, 0x40, 0xb1, 0x7d, 0x6c, 0x9c, 0x3f, 0xd3, 0x90, 0xc4, 0xc7, 0x27, 0xa4,
+	0xff, 0x52, 0xeb, 0xa1, 0x1c, 0xbe, 0x03, 0x3b, 0x1d, 0x6d, 0x40, 0xb4, 0xe3, 0xb6, 0xa1, 0x75,
+	0x4c, 0xbd, 0x7f, 0x62, 0x98, 0x1d, 0xed, 0x52, 0x33, 0xb4, 0x0e, 0xca, 0x37, 0x72, 0x8a, 0xb4,
+	0x42, 0x38, 0x6b, 0x93, 0xce, 0x9c, 0x20, 0x0b, 0x42, 0x15, 0x94, 0x0e, 0x69, 0x9f, 0xf7, 0xce,
+	0x7b, 0xa7, 0xa8, 0x70, 0xf7, 0x14, 0x94, 0xec, 0xfd, 0xc5, 0x37, 0x76, 0x63, 0x2d, 0xc6, 0x8b,
+	0x01, 0x5f, 0x4a, 0x09, 0xf2, 0x97, 0xfd, 0x53, 0x24, 0xf1, 0xc1, 0x55, 0x7b, 0x80, 0x72, 0x7c,
+	0x5b, 0x03, 0xa2, 0xf5, 0x49, 0x47, 0x23, 0x5a, 0xc7, 0xe4, 0x60, 0xfe, 0xe9, 0x19, 0xec, 0x8e,
+	0xfc, 0x69, 0x56, 0x92, 0x37, 0x9f, 0xbc, 0x4f, 0x6b, 0x46, 0x6a, 0x0f, 0xb8, 0x39, 0x90, 0x5e,
+	0x36, 0x6c, 0x87, 0x4d, 0x66, 0xc3, 0xe6, 0xc8, 0x9f, 0x1e, 0xa6, 0x6f, 0xd2, 0x2c, 0x64, 0x58,
+	0x14, 0x31, 0xf7, 0xff, 0x1e, 0x00, 0x62, 0x46, 0xa9, 0x65, 0x38, 0x0b, 0x00, 0x00,
 }
@@ -25,6 +25,16 @@ var _ = math.Inf
 type ListTreesRequest struct {
 	// If true, deleted trees are included in the response.
 	ShowDeleted bool `protobuf:"varint,1,opt,name=show_deleted,json=showDeleted" json:"show_deleted,omitempty"`
+	// If set, only trees which have all of these labels, with the same values,
+	// are returned.
+	LabelSelector map[string]string `protobuf:"bytes,2,rep,name=label_selector,json=labelSelector" json:"label_selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Maximum number of trees to return. If zero, all matching trees are
+	// returned.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	// The next_page_token of a previous response, to list the trees following
+	// the ones it returned. The other request fields must not change between
+	// pages.
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
 }
 
 func (m *ListTreesRequest) Reset()                    { *m = ListTreesRequest{} }
@@ -39,12 +49,36 @@ func (m *ListTreesRequest) GetShowDeleted() bool {
 	return false
 }
 
+func (m *ListTreesRequest) GetLabelSelector() map[string]string {
+	if m != nil {
+		return m.LabelSelector
+	}
+	return nil
+}
+
+func (m *ListTreesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListTreesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 // ListTrees response.
-// No pagination is provided, all trees the requester has access to are
-// returned.
+// Trees are returned in order of tree ID. Only the trees the requester has
+// access to are returned, so pages may be shorter than requested.
 type ListTreesResponse struct {
 	// Trees matching the list request filters.
 	Tree []*Tree `protobuf:"bytes,1,rep,name=tree" json:"tree,omitempty"`
+	// If set, there may be more matching trees, which can be listed by passing
+	// this token in the page_token field of another request.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *ListTreesResponse) Reset()                    { *m = ListTreesResponse{} }
@@ -59,6 +93,13 @@ func (m *ListTreesResponse) GetTree() []*Tree {
 	return nil
 }
 
+func (m *ListTreesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 // GetTree request.
 type GetTreeRequest struct {
 	// ID of the tree to retrieve.
@@ -447,40 +488,48 @@ var _TrillianAdmin_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("trillian_admin_api.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 548 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xe1, 0x6a, 0x13, 0x4d,
-	0x14, 0xfd, 0xb6, 0x2d, 0x6d, 0xbe, 0x9b, 0x1a, 0xcc, 0x94, 0x62, 0xba, 0x56, 0x8c, 0xa3, 0x42,
-	0x8d, 0xb2, 0x6b, 0x23, 0x22, 0x54, 0xfc, 0xd1, 0x2a, 0x15, 0x41, 0x21, 0xac, 0x29, 0x82, 0x20,
-	0xcb, 0x66, 0xf7, 0x26, 0x1d, 0x93, 0xec, 0xac, 0x3b, 0x13, 0x25, 0x88, 0x7f, 0x7c, 0x05, 0x5f,
-	0xc6, 0xf7, 0xf0, 0x15, 0x7c, 0x10, 0x99, 0xd9, 0xd9, 0xee, 0xa6, 0xdb, 0x48, 0xf1, 0xd7, 0xee,
-	0xdc, 0x73, 0xef, 0x3d, 0x77, 0xce, 0xb9, 0x0c, 0xb4, 0x64, 0xca, 0x26, 0x13, 0x16, 0xc4, 0x7e,
-	0x10, 0x4d, 0x59, 0xec, 0x07, 0x09, 0x73, 0x92, 0x94, 0x4b, 0x4e, 0x6a, 0x39, 0x62, 0x37, 0xf2,
-	0xbf, 0x0c, 0xb1, 0xed, 0x30, 0x9d, 0x27, 0x92, 0xbb, 0x63, 0x9c, 0x8b, 0x64, 0x60, 0x3e, 0x06,
-	0xdb, 0x1d, 0x71, 0x3e, 0x9a, 0xa0, 0x1b, 0x24, 0xcc, 0x0d, 0xe2, 0x98, 0xcb, 0x40, 0x32, 0x1e,
-	0x0b, 0x83, 0xb6, 0x0d, 0xaa, 0x4f, 0x83, 0xd9, 0xd0, 0x1d, 0x32, 0x9c, 0x44, 0xfe, 0x34, 0x10,
-	0xe3, 0x2c, 0x83, 0x3e, 0x86, 0xab, 0xaf, 0x99, 0x90, 0xfd, 0x14, 0x51, 0x78, 0xf8, 0x69, 0x86,
-	0x42, 0x92, 0x5b, 0xb0, 0x29, 0x4e, 0xf9, 0x17, 0x3f, 0xc2, 0x09, 0x4a, 0x8c, 0x5a, 0x56, 0xdb,
-	0xda, 0xab, 0x79, 0x75, 0x15, 0x7b, 0x91, 0x85, 0xe8, 0x13, 0x68, 0x96, 0xca, 0x44, 0xc2, 0x63,
-	0x81, 0x84, 0xc2, 0x9a, 0x4c, 0x11, 0x5b, 0x56, 0x7b, 0x75, 0xaf, 0xde, 0x6d, 0x38, 0x67, 0xd7,
-	0x50, 0x69, 0x9e, 0xc6, 0xe8, 0x3d, 0x68, 0xbc, 0x44, 0x5d, 0x97, 0xb3, 0x5d, 0x83, 0x0d, 0x85,
-	0xf8, 0x2c, 0x23, 0x5a, 0xf5, 0xd6, 0xd5, 0xf1, 0x55, 0x44, 0x19, 0x34, 0x9f, 0xa7, 0x18, 0x48,
-	0x2c, 0x67, 0x17, 0x1c, 0xd6, 0x32, 0x0e, 0xf2, 0x10, 0x6a, 0x63, 0x9c, 0xfb, 0x22, 0xc1, 0xb0,
-	0xb5, 0xa2, 0xf3, 0xb6, 0x1d, 0x23, 0xda, 0xdb, 0x04, 0x43, 0x36, 0x64, 0xa1, 0x56, 0xc9, 0xdb,
-	0x18, 0xe3, 0x5c, 0x45, 0xa8, 0x84, 0xe6, 0x49, 0x12, 0xfd, 0x03, 0xd5, 0x53, 0xa8, 0xcf, 0x74,
-	0xa1, 0xd6, 0xd4, 0xb0, 0xd9, 0x4e, 0x26, 0xbb, 0x93, 0xcb, 0xee, 0x1c, 0x2b, 0xd9, 0xdf, 0x04,
-	0x62, 0xec, 0x41, 0x96, 0xae, 0xfe, 0xe9, 0x03, 0x68, 0x66, 0x7a, 0x5e, 0x4a, 0x0e, 0x07, 0xb6,
-	0x4e, 0xe2, 0xe8, 0xd2, 0xf9, 0xdd, 0x9f, 0x6b, 0x70, 0xa5, 0x6f, 0x46, 0x3e, 0x54, 0xbb, 0x46,
-	0x8e, 0xe1, 0xff, 0x33, 0xd3, 0x88, 0x5d, 0xdc, 0xe7, 0xfc, 0x02, 0xd8, 0xd7, 0x2f, 0xc4, 0x32,
-	0x97, 0xe9, 0x7f, 0xe4, 0x1d, 0x6c, 0x18, 0x0f, 0x49, 0xab, 0xc8, 0x5c, 0xb4, 0xd5, 0x3e, 0xa7,
-	0x17, 0xa5, 0xdf, 0x7f, 0xfd, 0xfe, 0xb1, 0xb2, 0x4b, 0x6c, 0xf7, 0xf3, 0xfe, 0x00, 0x65, 0xb0,
-	0xef, 0xaa, 0x39, 0x85, 0xfb, 0xd5, 0x4c, 0xff, 0xac, 0xf3, 0x8d, 0xf4, 0x01, 0x0a, 0xc7, 0x49,
-	0x69, 0x8a, 0xca, 0x1e, 0x54, 0xda, 0xef, 0xe8, 0xf6, 0x5b, 0xb4, 0xb1, 0xd8, 0xfe, 0xc0, 0xea,
-	0x10, 0x04, 0x28, 0xcc, 0x2d, 0x77, 0xad, 0x58, 0x5e, 0xe9, 0xda, 0xd1, 0x5d, 0xef, 0x74, 0x6f,
-	0x5e, 0x34, 0xb4, 0x53, 0x4c, 0xae, 0x68, 0x3e, 0x00, 0x14, 0x6e, 0x96, 0x69, 0x2a, 0x1e, 0x2f,
-	0xd3, 0xa6, 0xf3, 0x37, 0x6d, 0x3e, 0xc2, 0x66, 0xd9, 0x7e, 0x72, 0xa3, 0x74, 0x8f, 0xea, 0x5a,
-	0x54, 0x28, 0xee, 0x6b, 0x8a, 0xbb, 0x9d, 0xdb, 0xcb, 0x29, 0x0e, 0x66, 0xa6, 0xcf, 0x51, 0x0f,
-	0x76, 0x42, 0x3e, 0xcd, 0xb7, 0x78, 0xf1, 0x35, 0x3a, 0xda, 0x5e, 0x58, 0xaa, 0xc3, 0x84, 0xf5,
-	0x54, 0xb8, 0x67, 0xbd, 0xb7, 0x47, 0x4c, 0x9e, 0xce, 0x06, 0x4e, 0xc8, 0xa7, 0xae, 0x79, 0x77,
-	0xf2, 0xd2, 0xc1, 0xba, 0xae, 0x7d, 0xf4, 0x27, 0x00, 0x00, 0xff, 0xff, 0x58, 0xc1, 0xa5, 0x85,
-	0xff, 0x04, 0x00, 0x00,
+	// 673 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xdd, 0x6e, 0xd3, 0x4a,
+	0x10, 0x3e, 0x4e, 0xfa, 0x93, 0x4c, 0xda, 0x9c, 0x66, 0x7b, 0xaa, 0xe3, 0xba, 0xad, 0x4e, 0x8e,
+	0xf9, 0x51, 0x08, 0x60, 0xd3, 0x70, 0x83, 0x8a, 0x90, 0x68, 0x81, 0x22, 0xa4, 0x22, 0x45, 0x6e,
+	0x2a, 0x24, 0x24, 0x64, 0x39, 0xf1, 0x34, 0x5d, 0xe2, 0xd8, 0xc6, 0xbb, 0x29, 0xa4, 0x88, 0x1b,
+	0xee, 0xb8, 0xe6, 0x65, 0x78, 0x0f, 0x5e, 0x81, 0x07, 0x41, 0xbb, 0xb6, 0x6b, 0xa7, 0x69, 0xa0,
+	0xe2, 0x2a, 0xbb, 0x33, 0xb3, 0xdf, 0x7c, 0x33, 0xdf, 0x17, 0x83, 0xca, 0x23, 0xea, 0x79, 0xd4,
+	0xf1, 0x6d, 0xc7, 0x1d, 0x52, 0xdf, 0x76, 0x42, 0x6a, 0x84, 0x51, 0xc0, 0x03, 0x52, 0x4a, 0x33,
+	0x5a, 0x35, 0x3d, 0xc5, 0x19, 0x4d, 0xeb, 0x45, 0xe3, 0x90, 0x07, 0xe6, 0x00, 0xc7, 0x2c, 0xec,
+	0x26, 0x3f, 0x49, 0x6e, 0xb3, 0x1f, 0x04, 0x7d, 0x0f, 0x4d, 0x27, 0xa4, 0xa6, 0xe3, 0xfb, 0x01,
+	0x77, 0x38, 0x0d, 0x7c, 0x96, 0x64, 0xeb, 0x49, 0x56, 0xde, 0xba, 0xa3, 0x63, 0xf3, 0x98, 0xa2,
+	0xe7, 0xda, 0x43, 0x87, 0x0d, 0xe2, 0x0a, 0xfd, 0x4b, 0x01, 0x56, 0x0e, 0x28, 0xe3, 0x9d, 0x08,
+	0x91, 0x59, 0xf8, 0x6e, 0x84, 0x8c, 0x93, 0xff, 0x61, 0x89, 0x9d, 0x04, 0xef, 0x6d, 0x17, 0x3d,
+	0xe4, 0xe8, 0xaa, 0x4a, 0x5d, 0x69, 0x94, 0xac, 0x8a, 0x88, 0x3d, 0x8d, 0x43, 0xa4, 0x03, 0x55,
+	0xcf, 0xe9, 0xa2, 0x67, 0x33, 0xf4, 0xb0, 0xc7, 0x83, 0x48, 0x2d, 0xd4, 0x8b, 0x8d, 0x4a, 0xeb,
+	0xae, 0x71, 0x4e, 0xfe, 0x22, 0xac, 0x71, 0x20, 0x1e, 0x1c, 0x26, 0xf5, 0xcf, 0x7c, 0x1e, 0x8d,
+	0xad, 0x65, 0x2f, 0x1f, 0x23, 0x1b, 0x50, 0x0e, 0x9d, 0x3e, 0xda, 0x8c, 0x9e, 0xa1, 0x5a, 0xac,
+	0x2b, 0x8d, 0x79, 0xab, 0x24, 0x02, 0x87, 0xf4, 0x0c, 0xc9, 0x16, 0x80, 0x4c, 0xf2, 0x60, 0x80,
+	0xbe, 0x3a, 0x57, 0x57, 0x1a, 0x65, 0x4b, 0x96, 0x77, 0x44, 0x40, 0x7b, 0x0c, 0x64, 0xba, 0x01,
+	0x59, 0x81, 0xe2, 0x00, 0xc7, 0x72, 0x82, 0xb2, 0x25, 0x8e, 0xe4, 0x1f, 0x98, 0x3f, 0x75, 0xbc,
+	0x11, 0xaa, 0x05, 0x19, 0x8b, 0x2f, 0x3b, 0x85, 0x07, 0x8a, 0x6e, 0x43, 0x2d, 0xc7, 0x99, 0x85,
+	0x81, 0xcf, 0x90, 0xe8, 0x30, 0xc7, 0x23, 0x44, 0x55, 0x91, 0xe3, 0x55, 0xb3, 0xf1, 0x44, 0x99,
+	0x25, 0x73, 0xe4, 0x26, 0xfc, 0xed, 0xe3, 0x07, 0x6e, 0xe7, 0xe8, 0xc5, 0xe0, 0xcb, 0x22, 0xdc,
+	0x4e, 0x29, 0xea, 0xb7, 0xa0, 0xfa, 0x1c, 0x25, 0x7e, 0xba, 0xe9, 0x7f, 0x61, 0x51, 0x20, 0xd8,
+	0x34, 0x5e, 0x72, 0xd1, 0x5a, 0x10, 0xd7, 0x17, 0xae, 0x4e, 0xa1, 0xf6, 0x24, 0x42, 0x87, 0x63,
+	0xbe, 0x3a, 0xe3, 0xa2, 0xcc, 0xe4, 0x72, 0x0f, 0x4a, 0x03, 0x1c, 0xdb, 0x2c, 0xc4, 0x9e, 0x24,
+	0x51, 0x69, 0xad, 0x19, 0x89, 0x63, 0x0e, 0x43, 0xec, 0xd1, 0x63, 0xda, 0x93, 0x16, 0xb1, 0x16,
+	0x07, 0x38, 0x16, 0x11, 0x9d, 0x43, 0xed, 0x28, 0x74, 0xff, 0xa0, 0xd5, 0x43, 0xa8, 0x8c, 0xe4,
+	0x43, 0x69, 0xa8, 0xa4, 0x9b, 0x66, 0xc4, 0x9e, 0x33, 0x52, 0xcf, 0x19, 0xfb, 0xc2, 0x73, 0x2f,
+	0x1d, 0x36, 0xb0, 0x20, 0x2e, 0x17, 0x67, 0xfd, 0x0e, 0xd4, 0x62, 0x2f, 0x5d, 0x69, 0x1d, 0x06,
+	0xac, 0x1e, 0xf9, 0xee, 0x95, 0xeb, 0x5b, 0xdf, 0xe6, 0x60, 0xb9, 0x93, 0x50, 0xde, 0x15, 0x7f,
+	0x34, 0xb2, 0x0f, 0xe5, 0x73, 0x71, 0x89, 0x36, 0xdb, 0xa5, 0xda, 0xc6, 0xa5, 0xb9, 0xd8, 0x0d,
+	0xfa, 0x5f, 0xe4, 0x15, 0x2c, 0x26, 0x1a, 0x12, 0x35, 0xab, 0x9c, 0x94, 0x55, 0xbb, 0xb0, 0x2f,
+	0x5d, 0xff, 0xfc, 0xfd, 0xc7, 0xd7, 0xc2, 0x26, 0xd1, 0xcc, 0xd3, 0xed, 0x2e, 0x72, 0x67, 0xdb,
+	0x14, 0x3c, 0x99, 0xf9, 0x31, 0x61, 0xff, 0xa8, 0xf9, 0x89, 0x74, 0x00, 0x32, 0xc5, 0x49, 0x8e,
+	0xc5, 0x94, 0x0f, 0xa6, 0xe0, 0xd7, 0x25, 0xfc, 0xaa, 0x5e, 0x9d, 0x84, 0xdf, 0x51, 0x9a, 0x04,
+	0x01, 0x32, 0x71, 0xf3, 0xa8, 0x53, 0x92, 0x4f, 0xa1, 0x36, 0x25, 0xea, 0xf5, 0xd6, 0x7f, 0x97,
+	0x91, 0x36, 0x32, 0xe6, 0xa2, 0xcd, 0x1b, 0x80, 0x4c, 0xcd, 0x7c, 0x9b, 0x29, 0x8d, 0x67, 0xed,
+	0xa6, 0xf9, 0xab, 0xdd, 0xbc, 0x85, 0xa5, 0xbc, 0xfc, 0x64, 0x2b, 0x37, 0x87, 0xef, 0xfe, 0xb6,
+	0xc5, 0x6d, 0xd9, 0xe2, 0x46, 0xf3, 0xda, 0xec, 0x16, 0x3b, 0xa3, 0x04, 0x67, 0xaf, 0x0d, 0xeb,
+	0xbd, 0x60, 0x98, 0xba, 0x78, 0xf2, 0x53, 0xbc, 0xb7, 0x36, 0x61, 0xaa, 0xdd, 0x90, 0xb6, 0x45,
+	0xb8, 0xad, 0xbc, 0xd6, 0xfa, 0x94, 0x9f, 0x8c, 0xba, 0x46, 0x2f, 0x18, 0x9a, 0xc9, 0x47, 0x37,
+	0x7d, 0xda, 0x5d, 0x90, 0x6f, 0xef, 0xff, 0x1c, 0x00, 0x32, 0x21, 0xa2, 0x4e, 0xfc, 0x05, 0x00,
+	0x00,
 }
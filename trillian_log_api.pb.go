@@ -40,6 +40,10 @@ It has these top-level messages:
 	GetLeavesByRangeResponse
 	GetLeavesByHashRequest
 	GetLeavesByHashResponse
+	StreamLeavesRequest
+	StreamLeavesResponse
+	WatchSignedLogRootsRequest
+	WatchSignedLogRootsResponse
 	QueuedLogLeaf
 	LogLeaf
 	Proof
@@ -55,6 +59,15 @@ It has these top-level messages:
 	GetSignedMapRootResponse
 	InitMapRequest
 	InitMapResponse
+	StreamMapLeavesRequest
+	StreamMapLeavesResponse
+	GetMapLeafHistoryRequest
+	MapLeafHistoryEntry
+	GetMapLeafHistoryResponse
+	QueueMapLeavesRequest
+	QueueMapLeavesResponse
+	GetMapStatusRequest
+	GetMapStatusResponse
 	ListTreesRequest
 	ListTreesResponse
 	GetTreeRequest
@@ -66,6 +79,8 @@ It has these top-level messages:
 	SignedEntryTimestamp
 	SignedLogRoot
 	SignedMapRoot
+	SequencerConfig
+	SigningKey
 */
 package trillian
 
@@ -290,6 +305,12 @@ type GetInclusionProofByHashRequest struct {
 	TreeSize        int64     `protobuf:"varint,3,opt,name=tree_size,json=treeSize" json:"tree_size,omitempty"`
 	OrderBySequence bool      `protobuf:"varint,4,opt,name=order_by_sequence,json=orderBySequence" json:"order_by_sequence,omitempty"`
 	ChargeTo        *ChargeTo `protobuf:"bytes,5,opt,name=charge_to,json=chargeTo" json:"charge_to,omitempty"`
+	// The maximum number of proofs to return. If zero, or larger than the
+	// server's limit, the server's limit is used.
+	MaxResults int64 `protobuf:"varint,6,opt,name=max_results,json=maxResults" json:"max_results,omitempty"`
+	// The next_page_token of a previous response, to continue listing proofs
+	// from where it stopped. Empty to start from the first leaf.
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
 }
 
 func (m *GetInclusionProofByHashRequest) Reset()                    { *m = GetInclusionProofByHashRequest{} }
@@ -332,12 +353,30 @@ func (m *GetInclusionProofByHashRequest) GetChargeTo() *ChargeTo {
 	return nil
 }
 
+func (m *GetInclusionProofByHashRequest) GetMaxResults() int64 {
+	if m != nil {
+		return m.MaxResults
+	}
+	return 0
+}
+
+func (m *GetInclusionProofByHashRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type GetInclusionProofByHashResponse struct {
 	// Logs can potentially contain leaves with duplicate hashes so it's possible
-	// for this to return multiple proofs.
+	// for this to return multiple proofs. They are for leaves in ascending
+	// sequence number order.
 	// TODO(gbelvin) only return one proof.
 	Proof         []*Proof       `protobuf:"bytes,2,rep,name=proof" json:"proof,omitempty"`
 	SignedLogRoot *SignedLogRoot `protobuf:"bytes,3,opt,name=signed_log_root,json=signedLogRoot" json:"signed_log_root,omitempty"`
+	// Set if there may be more proofs, in which case it can be passed as the
+	// page_token of another request to get them.
+	NextPageToken string `protobuf:"bytes,4,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *GetInclusionProofByHashResponse) Reset()                    { *m = GetInclusionProofByHashResponse{} }
@@ -359,6 +398,13 @@ func (m *GetInclusionProofByHashResponse) GetSignedLogRoot() *SignedLogRoot {
 	return nil
 }
 
+func (m *GetInclusionProofByHashResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type GetConsistencyProofRequest struct {
 	LogId          int64     `protobuf:"varint,1,opt,name=log_id,json=logId" json:"log_id,omitempty"`
 	FirstTreeSize  int64     `protobuf:"varint,2,opt,name=first_tree_size,json=firstTreeSize" json:"first_tree_size,omitempty"`
@@ -843,6 +889,12 @@ type GetLeavesByHashRequest struct {
 	LeafHash        [][]byte  `protobuf:"bytes,2,rep,name=leaf_hash,json=leafHash,proto3" json:"leaf_hash,omitempty"`
 	OrderBySequence bool      `protobuf:"varint,3,opt,name=order_by_sequence,json=orderBySequence" json:"order_by_sequence,omitempty"`
 	ChargeTo        *ChargeTo `protobuf:"bytes,5,opt,name=charge_to,json=chargeTo" json:"charge_to,omitempty"`
+	// The maximum number of leaves to return. If zero, or larger than the
+	// server's limit, the server's limit is used.
+	MaxResults int64 `protobuf:"varint,6,opt,name=max_results,json=maxResults" json:"max_results,omitempty"`
+	// The next_page_token of a previous response, to continue listing leaves
+	// from where it stopped. Empty to start from the first leaf.
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
 }
 
 func (m *GetLeavesByHashRequest) Reset()                    { *m = GetLeavesByHashRequest{} }
@@ -878,10 +930,28 @@ func (m *GetLeavesByHashRequest) GetChargeTo() *ChargeTo {
 	return nil
 }
 
+func (m *GetLeavesByHashRequest) GetMaxResults() int64 {
+	if m != nil {
+		return m.MaxResults
+	}
+	return 0
+}
+
+func (m *GetLeavesByHashRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type GetLeavesByHashResponse struct {
 	// TODO(gbelvin) reply with error codes. Reuse QueuedLogLeaf?
+	// Leaves are returned in ascending sequence number order.
 	Leaves        []*LogLeaf     `protobuf:"bytes,2,rep,name=leaves" json:"leaves,omitempty"`
 	SignedLogRoot *SignedLogRoot `protobuf:"bytes,3,opt,name=signed_log_root,json=signedLogRoot" json:"signed_log_root,omitempty"`
+	// Set if there may be more leaves, in which case it can be passed as the
+	// page_token of another request to get them.
+	NextPageToken string `protobuf:"bytes,4,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *GetLeavesByHashResponse) Reset()                    { *m = GetLeavesByHashResponse{} }
@@ -903,6 +973,133 @@ func (m *GetLeavesByHashResponse) GetSignedLogRoot() *SignedLogRoot {
 	return nil
 }
 
+func (m *GetLeavesByHashResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+type StreamLeavesRequest struct {
+	LogId      int64 `protobuf:"varint,1,opt,name=log_id,json=logId" json:"log_id,omitempty"`
+	StartIndex int64 `protobuf:"varint,2,opt,name=start_index,json=startIndex" json:"start_index,omitempty"`
+	// The maximum number of leaves to stream, or zero for no limit.
+	Count int64 `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
+	// If set, the stream is kept open once the end of the tree has been reached
+	// and leaves are sent as they are integrated, until `count` leaves have been
+	// sent or the client cancels the call.
+	Follow   bool      `protobuf:"varint,4,opt,name=follow" json:"follow,omitempty"`
+	ChargeTo *ChargeTo `protobuf:"bytes,5,opt,name=charge_to,json=chargeTo" json:"charge_to,omitempty"`
+}
+
+func (m *StreamLeavesRequest) Reset()                    { *m = StreamLeavesRequest{} }
+func (m *StreamLeavesRequest) String() string            { return proto.CompactTextString(m) }
+func (*StreamLeavesRequest) ProtoMessage()               {}
+func (*StreamLeavesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *StreamLeavesRequest) GetLogId() int64 {
+	if m != nil {
+		return m.LogId
+	}
+	return 0
+}
+
+func (m *StreamLeavesRequest) GetStartIndex() int64 {
+	if m != nil {
+		return m.StartIndex
+	}
+	return 0
+}
+
+func (m *StreamLeavesRequest) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *StreamLeavesRequest) GetFollow() bool {
+	if m != nil {
+		return m.Follow
+	}
+	return false
+}
+
+func (m *StreamLeavesRequest) GetChargeTo() *ChargeTo {
+	if m != nil {
+		return m.ChargeTo
+	}
+	return nil
+}
+
+type StreamLeavesResponse struct {
+	// The next batch of log leaves, contiguous with those previously sent on the
+	// stream.
+	Leaves []*LogLeaf `protobuf:"bytes,1,rep,name=leaves" json:"leaves,omitempty"`
+	// The signed log root of the tree the leaves were read from. All the leaves
+	// sent so far are covered by it.
+	SignedLogRoot *SignedLogRoot `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot" json:"signed_log_root,omitempty"`
+}
+
+func (m *StreamLeavesResponse) Reset()                    { *m = StreamLeavesResponse{} }
+func (m *StreamLeavesResponse) String() string            { return proto.CompactTextString(m) }
+func (*StreamLeavesResponse) ProtoMessage()               {}
+func (*StreamLeavesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *StreamLeavesResponse) GetLeaves() []*LogLeaf {
+	if m != nil {
+		return m.Leaves
+	}
+	return nil
+}
+
+func (m *StreamLeavesResponse) GetSignedLogRoot() *SignedLogRoot {
+	if m != nil {
+		return m.SignedLogRoot
+	}
+	return nil
+}
+
+type WatchSignedLogRootsRequest struct {
+	LogId    int64     `protobuf:"varint,1,opt,name=log_id,json=logId" json:"log_id,omitempty"`
+	ChargeTo *ChargeTo `protobuf:"bytes,2,opt,name=charge_to,json=chargeTo" json:"charge_to,omitempty"`
+}
+
+func (m *WatchSignedLogRootsRequest) Reset()                    { *m = WatchSignedLogRootsRequest{} }
+func (m *WatchSignedLogRootsRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchSignedLogRootsRequest) ProtoMessage()               {}
+func (*WatchSignedLogRootsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *WatchSignedLogRootsRequest) GetLogId() int64 {
+	if m != nil {
+		return m.LogId
+	}
+	return 0
+}
+
+func (m *WatchSignedLogRootsRequest) GetChargeTo() *ChargeTo {
+	if m != nil {
+		return m.ChargeTo
+	}
+	return nil
+}
+
+type WatchSignedLogRootsResponse struct {
+	SignedLogRoot *SignedLogRoot `protobuf:"bytes,1,opt,name=signed_log_root,json=signedLogRoot" json:"signed_log_root,omitempty"`
+}
+
+func (m *WatchSignedLogRootsResponse) Reset()                    { *m = WatchSignedLogRootsResponse{} }
+func (m *WatchSignedLogRootsResponse) String() string            { return proto.CompactTextString(m) }
+func (*WatchSignedLogRootsResponse) ProtoMessage()               {}
+func (*WatchSignedLogRootsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *WatchSignedLogRootsResponse) GetSignedLogRoot() *SignedLogRoot {
+	if m != nil {
+		return m.SignedLogRoot
+	}
+	return nil
+}
+
 // A result of submitting an entry to the log. Output only.
 // TODO(pavelkalinnikov): Consider renaming it to AddLogLeafResult or the like.
 type QueuedLogLeaf struct {
@@ -924,7 +1121,7 @@ type QueuedLogLeaf struct {
 func (m *QueuedLogLeaf) Reset()                    { *m = QueuedLogLeaf{} }
 func (m *QueuedLogLeaf) String() string            { return proto.CompactTextString(m) }
 func (*QueuedLogLeaf) ProtoMessage()               {}
-func (*QueuedLogLeaf) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+func (*QueuedLogLeaf) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
 func (m *QueuedLogLeaf) GetLeaf() *LogLeaf {
 	if m != nil {
@@ -988,7 +1185,7 @@ type LogLeaf struct {
 func (m *LogLeaf) Reset()                    { *m = LogLeaf{} }
 func (m *LogLeaf) String() string            { return proto.CompactTextString(m) }
 func (*LogLeaf) ProtoMessage()               {}
-func (*LogLeaf) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+func (*LogLeaf) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
 
 func (m *LogLeaf) GetMerkleLeafHash() []byte {
 	if m != nil {
@@ -1048,7 +1245,7 @@ type Proof struct {
 func (m *Proof) Reset()                    { *m = Proof{} }
 func (m *Proof) String() string            { return proto.CompactTextString(m) }
 func (*Proof) ProtoMessage()               {}
-func (*Proof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+func (*Proof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
 
 func (m *Proof) GetLeafIndex() int64 {
 	if m != nil {
@@ -1094,6 +1291,10 @@ func init() {
 	proto.RegisterType((*GetLeavesByRangeResponse)(nil), "trillian.GetLeavesByRangeResponse")
 	proto.RegisterType((*GetLeavesByHashRequest)(nil), "trillian.GetLeavesByHashRequest")
 	proto.RegisterType((*GetLeavesByHashResponse)(nil), "trillian.GetLeavesByHashResponse")
+	proto.RegisterType((*StreamLeavesRequest)(nil), "trillian.StreamLeavesRequest")
+	proto.RegisterType((*StreamLeavesResponse)(nil), "trillian.StreamLeavesResponse")
+	proto.RegisterType((*WatchSignedLogRootsRequest)(nil), "trillian.WatchSignedLogRootsRequest")
+	proto.RegisterType((*WatchSignedLogRootsResponse)(nil), "trillian.WatchSignedLogRootsResponse")
 	proto.RegisterType((*QueuedLogLeaf)(nil), "trillian.QueuedLogLeaf")
 	proto.RegisterType((*LogLeaf)(nil), "trillian.LogLeaf")
 	proto.RegisterType((*Proof)(nil), "trillian.Proof")
@@ -1149,6 +1350,16 @@ type TrillianLogClient interface {
 	GetLeavesByRange(ctx context.Context, in *GetLeavesByRangeRequest, opts ...grpc.CallOption) (*GetLeavesByRangeResponse, error)
 	// Returns a batch of leaves by their `merkle_leaf_hash` values.
 	GetLeavesByHash(ctx context.Context, in *GetLeavesByHashRequest, opts ...grpc.CallOption) (*GetLeavesByHashResponse, error)
+	// Streams leaves in a sequential range, in order, starting at the requested
+	// index. Unlike GetLeavesByRange the range isn't truncated by the server;
+	// leaves are sent in batches until `count` leaves have been sent or, unless
+	// `follow` is set, the end of the tree is reached.
+	StreamLeaves(ctx context.Context, in *StreamLeavesRequest, opts ...grpc.CallOption) (TrillianLog_StreamLeavesClient, error)
+	// Streams the signed log roots of a given tree. The latest signed log root
+	// is sent first, followed by every newer root as it is stored by the log
+	// signer, until the client cancels the call. Roots may be skipped if the
+	// client falls behind, but each root sent is newer than the previous one.
+	WatchSignedLogRoots(ctx context.Context, in *WatchSignedLogRootsRequest, opts ...grpc.CallOption) (TrillianLog_WatchSignedLogRootsClient, error)
 }
 
 type trillianLogClient struct {
@@ -1285,6 +1496,70 @@ func (c *trillianLogClient) GetLeavesByHash(ctx context.Context, in *GetLeavesBy
 	return out, nil
 }
 
+func (c *trillianLogClient) StreamLeaves(ctx context.Context, in *StreamLeavesRequest, opts ...grpc.CallOption) (TrillianLog_StreamLeavesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TrillianLog_serviceDesc.Streams[0], c.cc, "/trillian.TrillianLog/StreamLeaves", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &trillianLogStreamLeavesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TrillianLog_StreamLeavesClient interface {
+	Recv() (*StreamLeavesResponse, error)
+	grpc.ClientStream
+}
+
+type trillianLogStreamLeavesClient struct {
+	grpc.ClientStream
+}
+
+func (x *trillianLogStreamLeavesClient) Recv() (*StreamLeavesResponse, error) {
+	m := new(StreamLeavesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *trillianLogClient) WatchSignedLogRoots(ctx context.Context, in *WatchSignedLogRootsRequest, opts ...grpc.CallOption) (TrillianLog_WatchSignedLogRootsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TrillianLog_serviceDesc.Streams[1], c.cc, "/trillian.TrillianLog/WatchSignedLogRoots", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &trillianLogWatchSignedLogRootsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TrillianLog_WatchSignedLogRootsClient interface {
+	Recv() (*WatchSignedLogRootsResponse, error)
+	grpc.ClientStream
+}
+
+type trillianLogWatchSignedLogRootsClient struct {
+	grpc.ClientStream
+}
+
+func (x *trillianLogWatchSignedLogRootsClient) Recv() (*WatchSignedLogRootsResponse, error) {
+	m := new(WatchSignedLogRootsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for TrillianLog service
 
 type TrillianLogServer interface {
@@ -1327,6 +1602,16 @@ type TrillianLogServer interface {
 	GetLeavesByRange(context.Context, *GetLeavesByRangeRequest) (*GetLeavesByRangeResponse, error)
 	// Returns a batch of leaves by their `merkle_leaf_hash` values.
 	GetLeavesByHash(context.Context, *GetLeavesByHashRequest) (*GetLeavesByHashResponse, error)
+	// Streams leaves in a sequential range, in order, starting at the requested
+	// index. Unlike GetLeavesByRange the range isn't truncated by the server;
+	// leaves are sent in batches until `count` leaves have been sent or, unless
+	// `follow` is set, the end of the tree is reached.
+	StreamLeaves(*StreamLeavesRequest, TrillianLog_StreamLeavesServer) error
+	// Streams the signed log roots of a given tree. The latest signed log root
+	// is sent first, followed by every newer root as it is stored by the log
+	// signer, until the client cancels the call. Roots may be skipped if the
+	// client falls behind, but each root sent is newer than the previous one.
+	WatchSignedLogRoots(*WatchSignedLogRootsRequest, TrillianLog_WatchSignedLogRootsServer) error
 }
 
 func RegisterTrillianLogServer(s *grpc.Server, srv TrillianLogServer) {
@@ -1585,6 +1870,48 @@ func _TrillianLog_GetLeavesByHash_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianLog_StreamLeaves_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLeavesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TrillianLogServer).StreamLeaves(m, &trillianLogStreamLeavesServer{stream})
+}
+
+type TrillianLog_StreamLeavesServer interface {
+	Send(*StreamLeavesResponse) error
+	grpc.ServerStream
+}
+
+type trillianLogStreamLeavesServer struct {
+	grpc.ServerStream
+}
+
+func (x *trillianLogStreamLeavesServer) Send(m *StreamLeavesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TrillianLog_WatchSignedLogRoots_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSignedLogRootsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TrillianLogServer).WatchSignedLogRoots(m, &trillianLogWatchSignedLogRootsServer{stream})
+}
+
+type TrillianLog_WatchSignedLogRootsServer interface {
+	Send(*WatchSignedLogRootsResponse) error
+	grpc.ServerStream
+}
+
+type trillianLogWatchSignedLogRootsServer struct {
+	grpc.ServerStream
+}
+
+func (x *trillianLogWatchSignedLogRootsServer) Send(m *WatchSignedLogRootsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _TrillianLog_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "trillian.TrillianLog",
 	HandlerType: (*TrillianLogServer)(nil),
@@ -1646,108 +1973,129 @@ var _TrillianLog_serviceDesc = grpc.ServiceDesc{
 			Handler:    _TrillianLog_GetLeavesByHash_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLeaves",
+			Handler:       _TrillianLog_StreamLeaves_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSignedLogRoots",
+			Handler:       _TrillianLog_WatchSignedLogRoots_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "trillian_log_api.proto",
 }
 
 func init() { proto.RegisterFile("trillian_log_api.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1526 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x58, 0x5f, 0x6f, 0x1b, 0x45,
-	0x10, 0xef, 0xc5, 0x89, 0xe3, 0x4c, 0x9a, 0x38, 0xd9, 0xd0, 0xc6, 0xb9, 0x34, 0x6d, 0x7a, 0x69,
-	0x5a, 0x37, 0x94, 0x1c, 0x29, 0x42, 0xa0, 0xa8, 0x02, 0x35, 0x29, 0x0a, 0xa1, 0x06, 0xca, 0x25,
-	0x42, 0x15, 0x3c, 0x9c, 0xce, 0xe7, 0xcd, 0xe5, 0x84, 0x73, 0xeb, 0xde, 0xad, 0xa3, 0xba, 0x55,
-	0x25, 0xfe, 0xa8, 0xfc, 0x79, 0x80, 0x17, 0x78, 0xe8, 0x0b, 0x7f, 0xde, 0x50, 0xbf, 0x00, 0x1f,
-	0x03, 0x21, 0xf1, 0x15, 0x78, 0xe0, 0x63, 0xa0, 0xdb, 0xdd, 0xf3, 0xfd, 0xf1, 0xdd, 0xd9, 0x2e,
-	0x6d, 0xe1, 0xcd, 0x37, 0x3b, 0x3b, 0xf3, 0xfb, 0xcd, 0xee, 0xcc, 0xce, 0x18, 0x4e, 0x53, 0xd7,
-	0x6e, 0x36, 0x6d, 0xc3, 0xd1, 0x9b, 0xc4, 0xd2, 0x8d, 0x96, 0xbd, 0xde, 0x72, 0x09, 0x25, 0xa8,
-	0x14, 0xc8, 0xe5, 0x33, 0x16, 0x21, 0x56, 0x13, 0xab, 0x46, 0xcb, 0x56, 0x0d, 0xc7, 0x21, 0xd4,
-	0xa0, 0x36, 0x71, 0x3c, 0xae, 0x27, 0x9f, 0x13, 0xab, 0xec, 0xab, 0xde, 0x3e, 0x50, 0xa9, 0x7d,
-	0x84, 0x3d, 0x6a, 0x1c, 0xb5, 0x84, 0xc2, 0xbc, 0x50, 0x70, 0x5b, 0xa6, 0xea, 0x51, 0x83, 0xb6,
-	0x83, 0x9d, 0xd3, 0x81, 0x07, 0xfe, 0xad, 0x9c, 0x85, 0xd2, 0xf6, 0xa1, 0xe1, 0x5a, 0x78, 0x9f,
-	0x20, 0x04, 0xa3, 0x6d, 0x0f, 0xbb, 0x15, 0x69, 0xb9, 0x50, 0x9d, 0xd0, 0xd8, 0x6f, 0xe5, 0x33,
-	0x09, 0x66, 0x3e, 0x68, 0xe3, 0x36, 0xae, 0x61, 0xe3, 0x40, 0xc3, 0x77, 0xda, 0xd8, 0xa3, 0xe8,
-	0x14, 0x14, 0x7d, 0xdc, 0x76, 0xa3, 0x22, 0x2d, 0x4b, 0xd5, 0x82, 0x36, 0xd6, 0x24, 0xd6, 0x6e,
-	0x03, 0xad, 0xc2, 0x68, 0x13, 0x1b, 0x07, 0x95, 0x91, 0x65, 0xa9, 0x3a, 0x79, 0x75, 0x76, 0xbd,
-	0xeb, 0xaa, 0x46, 0x2c, 0xb6, 0x9d, 0x2d, 0x23, 0x15, 0x26, 0x4c, 0xe6, 0x52, 0xa7, 0xa4, 0x52,
-	0x60, 0xba, 0x28, 0xd4, 0x0d, 0xd0, 0x68, 0x25, 0x53, 0xfc, 0x52, 0xde, 0x85, 0xd9, 0x08, 0x04,
-	0xaf, 0x45, 0x1c, 0x0f, 0xa3, 0xd7, 0x61, 0xf2, 0x8e, 0x2f, 0x6c, 0xe8, 0x11, 0x9f, 0xf3, 0xa1,
-	0x1d, 0xb6, 0xa3, 0x11, 0x78, 0x06, 0xae, 0xeb, 0xff, 0x56, 0xbe, 0x96, 0x60, 0xfe, 0x7a, 0xa3,
-	0xb1, 0xe7, 0x93, 0x71, 0x4c, 0x2e, 0xfc, 0x8f, 0x98, 0xdd, 0x84, 0x4a, 0x2f, 0x12, 0x41, 0x50,
-	0x85, 0xa2, 0x8b, 0xbd, 0x76, 0x93, 0xf6, 0xe3, 0x26, 0xd4, 0x94, 0x9f, 0x24, 0xa8, 0xec, 0x60,
-	0xba, 0xeb, 0x98, 0xcd, 0xb6, 0x67, 0x13, 0xe7, 0x96, 0x4b, 0x48, 0x3f, 0x62, 0x4b, 0x00, 0x3e,
-	0x72, 0xdd, 0x76, 0x1a, 0xf8, 0x2e, 0x73, 0x54, 0xd0, 0x26, 0x7c, 0xc9, 0xae, 0x2f, 0x40, 0x8b,
-	0x30, 0x41, 0x5d, 0x8c, 0x75, 0xcf, 0xbe, 0x87, 0x19, 0xa1, 0x82, 0x56, 0xf2, 0x05, 0x7b, 0xf6,
-	0x3d, 0x1c, 0x67, 0x3b, 0x3a, 0x00, 0xdb, 0x2f, 0x24, 0x58, 0x48, 0x01, 0x28, 0xf8, 0xae, 0xc2,
-	0x58, 0xcb, 0x17, 0x08, 0xba, 0xe5, 0xd0, 0x14, 0xd7, 0xe3, 0xab, 0xe8, 0x4d, 0x28, 0x7b, 0xb6,
-	0xe5, 0xf8, 0xe7, 0x4e, 0x2c, 0xdd, 0x25, 0x84, 0x8a, 0x48, 0x47, 0xe2, 0xb3, 0xc7, 0x14, 0x6a,
-	0xc4, 0xd2, 0x08, 0xa1, 0xda, 0x94, 0x17, 0xfd, 0x54, 0x7e, 0x97, 0xe0, 0x6c, 0x0f, 0x8a, 0xad,
-	0xce, 0xdb, 0x86, 0x77, 0xd8, 0x27, 0x58, 0x8b, 0xc0, 0x42, 0xa3, 0x1f, 0x1a, 0xde, 0x21, 0x43,
-	0x79, 0x52, 0x2b, 0xf9, 0x02, 0x7f, 0x6b, 0x7e, 0xa8, 0xd6, 0x60, 0x96, 0xb8, 0x0d, 0xec, 0xea,
-	0xf5, 0x8e, 0xee, 0x89, 0xd3, 0x66, 0x21, 0x2b, 0x69, 0x65, 0xb6, 0xb0, 0xd5, 0x09, 0x2e, 0x41,
-	0x3c, 0xac, 0x63, 0x03, 0x84, 0xf5, 0x1b, 0x09, 0xce, 0x65, 0x12, 0xea, 0x0d, 0x6e, 0xe1, 0x59,
-	0x06, 0xf7, 0x37, 0x09, 0xe4, 0x1d, 0x4c, 0xb7, 0x89, 0xe3, 0xd9, 0x1e, 0xc5, 0x8e, 0xd9, 0x19,
-	0xe4, 0x16, 0x5e, 0x84, 0xf2, 0x81, 0xed, 0x7a, 0x54, 0x0f, 0x23, 0xc8, 0xaf, 0xe2, 0x14, 0x13,
-	0xef, 0x07, 0x61, 0xac, 0xc2, 0x8c, 0x87, 0x4d, 0xe2, 0x34, 0xf4, 0x64, 0xa8, 0xa7, 0xb9, 0x7c,
-	0xff, 0x89, 0xef, 0xe6, 0x43, 0x09, 0x16, 0x53, 0x81, 0x3f, 0xe7, 0xdb, 0x69, 0xc1, 0xd2, 0x0e,
-	0xa6, 0x35, 0x83, 0x62, 0x8f, 0xc6, 0x15, 0xf3, 0x43, 0x18, 0x23, 0x3c, 0x32, 0x00, 0x61, 0x83,
-	0x65, 0x41, 0xaa, 0x23, 0x41, 0x39, 0x85, 0xcb, 0xc8, 0x50, 0x5c, 0x0e, 0xe0, 0xcc, 0x0e, 0xa6,
-	0xb1, 0xea, 0xb6, 0x4d, 0xda, 0xce, 0x53, 0xa7, 0xf2, 0x06, 0x8b, 0x59, 0x9a, 0x1f, 0xc1, 0x24,
-	0xa8, 0x72, 0xa6, 0x2f, 0x8d, 0x56, 0x39, 0xa6, 0xa6, 0xfc, 0x28, 0xc1, 0xfc, 0x0e, 0xa6, 0x6f,
-	0x39, 0xd4, 0xed, 0x5c, 0x77, 0x1a, 0xff, 0xbb, 0xba, 0xf9, 0x98, 0x17, 0xf6, 0x04, 0xbe, 0xe1,
-	0x2e, 0x66, 0xf0, 0x82, 0x15, 0xf2, 0x5f, 0xb0, 0x94, 0x33, 0x1f, 0x1d, 0xea, 0xcc, 0x6f, 0xc3,
-	0xf4, 0xae, 0x63, 0x53, 0xff, 0xf3, 0x29, 0x9f, 0xf2, 0x0d, 0x28, 0x77, 0x2d, 0x0b, 0xee, 0x1b,
-	0x30, 0x6e, 0xba, 0xd8, 0xa0, 0x98, 0xdb, 0xce, 0x41, 0x19, 0xe8, 0x29, 0x5f, 0x49, 0x80, 0x82,
-	0x66, 0xe2, 0x18, 0x7b, 0x7d, 0x40, 0x5e, 0x86, 0x62, 0x93, 0xe9, 0x89, 0xba, 0x99, 0x12, 0x37,
-	0xa1, 0x30, 0xfc, 0xdb, 0xbf, 0x07, 0x73, 0x31, 0x20, 0x82, 0xd3, 0x35, 0x98, 0x0a, 0xfb, 0x9a,
-	0xd0, 0x73, 0xe6, 0xeb, 0x7f, 0xb2, 0xdb, 0xd9, 0x1c, 0x63, 0x4f, 0xf9, 0x4e, 0x82, 0x85, 0x44,
-	0x47, 0xf1, 0xec, 0x58, 0x0e, 0x72, 0x77, 0xdf, 0x07, 0x39, 0x0d, 0x4f, 0x78, 0x80, 0xbc, 0x79,
-	0xe9, 0x4b, 0x33, 0xd0, 0x53, 0x3e, 0xe5, 0xc9, 0xca, 0x0d, 0x6d, 0x75, 0x58, 0xbe, 0x0d, 0x99,
-	0xac, 0x85, 0x78, 0xb2, 0x0e, 0xfd, 0xe0, 0x7e, 0xc9, 0xf3, 0x31, 0x01, 0x41, 0x50, 0x1a, 0x22,
-	0x98, 0xff, 0xfa, 0xb1, 0x78, 0x14, 0x8f, 0x85, 0x66, 0x38, 0x16, 0xee, 0x13, 0x8b, 0x73, 0x30,
-	0xe9, 0x51, 0xc3, 0xa5, 0xb1, 0xca, 0x05, 0x4c, 0xc4, 0xa3, 0xf1, 0x02, 0x8c, 0xf1, 0x32, 0xc9,
-	0xcb, 0x16, 0xff, 0x18, 0xfe, 0xdc, 0x13, 0x31, 0x12, 0xd0, 0x7a, 0x62, 0x24, 0x3d, 0x41, 0x8c,
-	0x86, 0x7b, 0x84, 0x1e, 0x4b, 0x70, 0x3a, 0x02, 0x64, 0xf8, 0x36, 0xaf, 0x10, 0x6b, 0xf3, 0x52,
-	0x3b, 0xb9, 0xc2, 0x53, 0xea, 0xe4, 0x1e, 0xc6, 0xcf, 0x33, 0xd6, 0xc1, 0x3d, 0xcf, 0x7b, 0x55,
-	0x87, 0xa9, 0x58, 0xf6, 0x75, 0x5f, 0x0f, 0x29, 0xff, 0xf5, 0x58, 0x83, 0x22, 0x1f, 0x36, 0xbb,
-	0x05, 0x9d, 0x8f, 0xa1, 0xeb, 0x6e, 0xcb, 0x5c, 0xdf, 0x63, 0x2b, 0x9a, 0xd0, 0x50, 0xfe, 0x18,
-	0x81, 0xf1, 0xc0, 0x7c, 0x15, 0x66, 0x8e, 0xb0, 0xfb, 0x49, 0x13, 0xeb, 0x61, 0xe0, 0x25, 0xd6,
-	0x5f, 0x4f, 0x73, 0x79, 0x2d, 0x08, 0x7f, 0x90, 0xca, 0xc7, 0x46, 0xb3, 0x8d, 0x45, 0x0f, 0xce,
-	0x4e, 0xeb, 0x43, 0x5f, 0xe0, 0x2f, 0xe3, 0xbb, 0xd4, 0x35, 0xf4, 0x86, 0x41, 0x0d, 0x46, 0xfa,
-	0xa4, 0x36, 0xc1, 0x24, 0x37, 0x0c, 0x6a, 0x24, 0x0a, 0xc1, 0x68, 0xf2, 0xd5, 0xbe, 0x02, 0x88,
-	0x2f, 0x37, 0xb0, 0x43, 0x6d, 0xda, 0xe1, 0x40, 0xc6, 0x98, 0x95, 0x19, 0xa6, 0x26, 0x16, 0x18,
-	0x94, 0x6d, 0x28, 0xb3, 0xd2, 0xab, 0x77, 0x67, 0xef, 0x4a, 0x91, 0xb1, 0x96, 0x03, 0xd6, 0xc1,
-	0x74, 0xbe, 0xbe, 0x1f, 0x68, 0x68, 0xd3, 0x6c, 0x4b, 0xf7, 0x1b, 0xdd, 0x84, 0x39, 0xdb, 0xa1,
-	0xd8, 0x72, 0x0d, 0x1a, 0x35, 0x34, 0xde, 0xd7, 0x10, 0xea, 0x6e, 0xeb, 0xca, 0x94, 0x1b, 0x30,
-	0xc6, 0xde, 0xfc, 0x04, 0x4f, 0x29, 0xc9, 0xf3, 0x34, 0x14, 0x7d, 0x66, 0xd8, 0xab, 0x14, 0xd8,
-	0xed, 0x16, 0x5f, 0xef, 0x8c, 0x96, 0x46, 0x66, 0x0a, 0x57, 0xff, 0x9e, 0x82, 0xc9, 0x7d, 0x71,
-	0xbe, 0x35, 0x62, 0x21, 0x07, 0x26, 0xba, 0xd3, 0x37, 0x92, 0x13, 0xf5, 0x39, 0x32, 0x3b, 0xcb,
-	0x8b, 0xa9, 0x6b, 0xfc, 0xfa, 0x2a, 0xd5, 0xcf, 0xff, 0xfc, 0xeb, 0xfb, 0x11, 0x45, 0x59, 0x52,
-	0x8f, 0x37, 0xea, 0x98, 0x1a, 0x1b, 0x6a, 0x93, 0x58, 0x9e, 0x7a, 0x9f, 0x27, 0xe0, 0x03, 0x95,
-	0x5f, 0xdd, 0x4d, 0x69, 0x0d, 0x7d, 0x2b, 0xc1, 0x4c, 0x72, 0x28, 0x46, 0xe7, 0x43, 0xdb, 0x19,
-	0xa3, 0xbb, 0xac, 0xe4, 0xa9, 0x08, 0x14, 0x57, 0x19, 0x8a, 0x2b, 0xca, 0xa5, 0x7c, 0x14, 0x41,
-	0x62, 0x37, 0x7c, 0x3c, 0xbf, 0x48, 0x30, 0xdb, 0x33, 0x5e, 0xa1, 0x88, 0xb7, 0xac, 0x99, 0x5b,
-	0x5e, 0xc9, 0xd5, 0x11, 0x90, 0xb6, 0x18, 0xa4, 0x6b, 0x68, 0x33, 0x17, 0x92, 0x7a, 0x3f, 0x3c,
-	0xd0, 0x07, 0x9b, 0x76, 0x60, 0x4a, 0xe7, 0xcd, 0xdd, 0xaf, 0xbc, 0x6e, 0xa4, 0x4d, 0x80, 0xa8,
-	0x9a, 0x03, 0x22, 0x56, 0x0e, 0xe5, 0xcb, 0x03, 0x68, 0x0a, 0xd0, 0xaf, 0x31, 0xd0, 0x1b, 0x48,
-	0xcd, 0x8f, 0x63, 0x88, 0xb3, 0xce, 0x93, 0x09, 0xfd, 0x20, 0xc1, 0x5c, 0xca, 0x98, 0x85, 0x2e,
-	0xc4, 0x7c, 0x67, 0x8c, 0x8f, 0xf2, 0x6a, 0x1f, 0x2d, 0x81, 0xee, 0x65, 0x86, 0x6e, 0x0d, 0x55,
-	0xd3, 0xd1, 0x6d, 0x9a, 0xe1, 0x46, 0x11, 0xc0, 0x47, 0xe2, 0x91, 0xe8, 0x9d, 0x86, 0xd0, 0xa5,
-	0x98, 0xcf, 0xec, 0xc1, 0x4c, 0xae, 0xf6, 0x57, 0x14, 0xf8, 0x5e, 0x64, 0xf8, 0x56, 0xd1, 0x4a,
-	0x46, 0xf4, 0xfc, 0x8a, 0xed, 0x6d, 0x36, 0x99, 0x05, 0xf4, 0xb3, 0x04, 0xa7, 0x52, 0xa7, 0x1b,
-	0x74, 0x31, 0xe6, 0x30, 0x73, 0xcc, 0x92, 0x2f, 0xf5, 0xd5, 0x13, 0xb8, 0x5e, 0x65, 0xb8, 0x54,
-	0xf4, 0xd2, 0x80, 0xd9, 0xc1, 0xe7, 0x29, 0x96, 0xb0, 0xc9, 0xf1, 0x24, 0x9a, 0xb0, 0x19, 0xa3,
-	0x95, 0xac, 0xe4, 0xa9, 0xc4, 0x13, 0x16, 0xad, 0x0d, 0x9e, 0x1d, 0xc8, 0x84, 0x71, 0x31, 0x28,
-	0xa0, 0x4a, 0xe8, 0x22, 0x3e, 0x95, 0xc8, 0x0b, 0x29, 0x2b, 0xc2, 0xe7, 0x0a, 0xf3, 0xb9, 0xa4,
-	0x2c, 0x66, 0x5c, 0x1f, 0xdb, 0xb1, 0x29, 0xaa, 0xc1, 0x64, 0xa4, 0x7b, 0x47, 0x67, 0x7a, 0x6b,
-	0x5f, 0xd8, 0x77, 0xcb, 0x4b, 0x19, 0xab, 0xc2, 0xe1, 0x09, 0x64, 0x00, 0xea, 0xed, 0x92, 0xd1,
-	0x4a, 0x66, 0x45, 0x8b, 0xd8, 0xbe, 0x90, 0xaf, 0xd4, 0x75, 0xf1, 0x31, 0x3b, 0xa4, 0x58, 0xcf,
-	0x9a, 0x38, 0xa4, 0xb4, 0x96, 0x3a, 0x71, 0x48, 0xa9, 0x2d, 0x6f, 0x8f, 0x71, 0xd6, 0xec, 0x65,
-	0x18, 0x8f, 0xf6, 0xa8, 0x19, 0xc6, 0x63, 0xbd, 0xa2, 0x72, 0x02, 0xdd, 0x86, 0x72, 0xa2, 0x29,
-	0x42, 0xcb, 0xa9, 0x1b, 0xa3, 0xc5, 0xec, 0x7c, 0x8e, 0x46, 0x60, 0x79, 0xeb, 0x3d, 0x58, 0x30,
-	0xc9, 0x51, 0xf0, 0xca, 0xc6, 0xff, 0x19, 0xdf, 0x9a, 0x8b, 0x3c, 0x82, 0xd7, 0x5b, 0xf6, 0x2d,
-	0x5f, 0x78, 0x4b, 0xfa, 0x48, 0xb6, 0x6c, 0x7a, 0xd8, 0xae, 0xaf, 0x9b, 0xe4, 0x48, 0x15, 0x7f,
-	0xb2, 0x07, 0x1b, 0xeb, 0x45, 0xb6, 0xf3, 0x95, 0x7f, 0x02, 0x00, 0x00, 0xff, 0xff, 0x37, 0x16,
-	0xa6, 0xd6, 0xdf, 0x17, 0x00, 0x00,
+	// 1685 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x59, 0xdd, 0x6f, 0x1b, 0x45,
+	0x10, 0xe7, 0xe2, 0xc4, 0x89, 0x27, 0x1f, 0x4e, 0x36, 0x6d, 0xe2, 0x5c, 0x9a, 0x8f, 0x5e, 0x9a,
+	0xd6, 0x0d, 0x25, 0x6e, 0x82, 0x10, 0x28, 0xaa, 0x40, 0x4d, 0x8a, 0x42, 0x68, 0x80, 0xf4, 0x12,
+	0x41, 0x05, 0x12, 0xa7, 0x8d, 0xbd, 0x71, 0x4e, 0xb5, 0x6f, 0xdd, 0xbb, 0x75, 0x48, 0x5a, 0x55,
+	0x82, 0x22, 0x3e, 0x5e, 0xe8, 0x0b, 0x3c, 0xf4, 0x85, 0x0f, 0x89, 0x07, 0x84, 0x04, 0x0f, 0xbc,
+	0xf0, 0x77, 0x20, 0xf1, 0x2f, 0xf0, 0xc2, 0x7f, 0x81, 0x6e, 0x77, 0xcf, 0xbe, 0x3b, 0xdf, 0x9d,
+	0xed, 0xd2, 0xaf, 0x37, 0xdf, 0xec, 0xec, 0xcc, 0x6f, 0x66, 0x76, 0x66, 0x67, 0xd6, 0x30, 0xc1,
+	0x6c, 0xb3, 0x52, 0x31, 0xb1, 0x65, 0x54, 0x68, 0xd9, 0xc0, 0x35, 0x73, 0xb9, 0x66, 0x53, 0x46,
+	0xd1, 0x80, 0x47, 0x57, 0xcf, 0x94, 0x29, 0x2d, 0x57, 0x48, 0x01, 0xd7, 0xcc, 0x02, 0xb6, 0x2c,
+	0xca, 0x30, 0x33, 0xa9, 0xe5, 0x08, 0x3e, 0x75, 0x4e, 0xae, 0xf2, 0xaf, 0xfd, 0xfa, 0x41, 0x81,
+	0x99, 0x55, 0xe2, 0x30, 0x5c, 0xad, 0x49, 0x86, 0x49, 0xc9, 0x60, 0xd7, 0x8a, 0x05, 0x87, 0x61,
+	0x56, 0xf7, 0x76, 0x8e, 0x78, 0x1a, 0xc4, 0xb7, 0x36, 0x0b, 0x03, 0x1b, 0x87, 0xd8, 0x2e, 0x93,
+	0x3d, 0x8a, 0x10, 0xf4, 0xd6, 0x1d, 0x62, 0xe7, 0x94, 0xf9, 0x54, 0x3e, 0xa3, 0xf3, 0xdf, 0xda,
+	0x67, 0x0a, 0x8c, 0xde, 0xa8, 0x93, 0x3a, 0xd9, 0x26, 0xf8, 0x40, 0x27, 0xb7, 0xeb, 0xc4, 0x61,
+	0xe8, 0x34, 0xa4, 0x5d, 0xdc, 0x66, 0x29, 0xa7, 0xcc, 0x2b, 0xf9, 0x94, 0xde, 0x57, 0xa1, 0xe5,
+	0xad, 0x12, 0x5a, 0x84, 0xde, 0x0a, 0xc1, 0x07, 0xb9, 0x9e, 0x79, 0x25, 0x3f, 0xb8, 0x3a, 0xb6,
+	0xdc, 0x50, 0xb5, 0x4d, 0xcb, 0x7c, 0x3b, 0x5f, 0x46, 0x05, 0xc8, 0x14, 0xb9, 0x4a, 0x83, 0xd1,
+	0x5c, 0x8a, 0xf3, 0xa2, 0x26, 0xaf, 0x87, 0x46, 0x1f, 0x28, 0xca, 0x5f, 0xda, 0x3b, 0x30, 0xe6,
+	0x83, 0xe0, 0xd4, 0xa8, 0xe5, 0x10, 0xf4, 0x1a, 0x0c, 0xde, 0x76, 0x89, 0x25, 0xc3, 0xa7, 0x73,
+	0xb2, 0x29, 0x87, 0xef, 0x28, 0x79, 0x9a, 0x41, 0xf0, 0xba, 0xbf, 0xb5, 0xaf, 0x15, 0x98, 0xbc,
+	0x5a, 0x2a, 0xed, 0xba, 0xc6, 0x58, 0x45, 0x52, 0x7a, 0x86, 0x96, 0x5d, 0x87, 0x5c, 0x2b, 0x12,
+	0x69, 0x60, 0x01, 0xd2, 0x36, 0x71, 0xea, 0x15, 0xd6, 0xce, 0x36, 0xc9, 0xa6, 0xfd, 0xa0, 0x40,
+	0x6e, 0x93, 0xb0, 0x2d, 0xab, 0x58, 0xa9, 0x3b, 0x26, 0xb5, 0x76, 0x6c, 0x4a, 0xdb, 0x19, 0x36,
+	0x03, 0xe0, 0x22, 0x37, 0x4c, 0xab, 0x44, 0x8e, 0xb9, 0xa2, 0x94, 0x9e, 0x71, 0x29, 0x5b, 0x2e,
+	0x01, 0x4d, 0x43, 0x86, 0xd9, 0x84, 0x18, 0x8e, 0x79, 0x87, 0x70, 0x83, 0x52, 0xfa, 0x80, 0x4b,
+	0xd8, 0x35, 0xef, 0x90, 0xa0, 0xb5, 0xbd, 0x1d, 0x58, 0xfb, 0xb9, 0x02, 0x53, 0x11, 0x00, 0xa5,
+	0xbd, 0x8b, 0xd0, 0x57, 0x73, 0x09, 0xd2, 0xdc, 0x6c, 0x53, 0x94, 0xe0, 0x13, 0xab, 0xe8, 0x0d,
+	0xc8, 0x3a, 0x66, 0xd9, 0x72, 0xe3, 0x4e, 0xcb, 0x86, 0x4d, 0x29, 0xcb, 0xa5, 0xc2, 0xfe, 0xd9,
+	0xe5, 0x0c, 0xdb, 0xb4, 0xac, 0x53, 0xca, 0xf4, 0x61, 0xc7, 0xff, 0xa9, 0x3d, 0xe8, 0x81, 0xd9,
+	0x16, 0x14, 0xeb, 0x27, 0x6f, 0x61, 0xe7, 0xb0, 0x8d, 0xb3, 0xa6, 0x81, 0xbb, 0xc6, 0x38, 0xc4,
+	0xce, 0x21, 0x47, 0x39, 0xa4, 0x0f, 0xb8, 0x04, 0x77, 0x6b, 0xb2, 0xab, 0x96, 0x60, 0x8c, 0xda,
+	0x25, 0x62, 0x1b, 0xfb, 0x27, 0x86, 0x23, 0xa3, 0xcd, 0x5d, 0x36, 0xa0, 0x67, 0xf9, 0xc2, 0xfa,
+	0x89, 0x77, 0x08, 0x82, 0x6e, 0xed, 0x6b, 0xef, 0x56, 0x34, 0x07, 0x83, 0x55, 0x7c, 0x6c, 0x88,
+	0x53, 0xe0, 0xe4, 0xd2, 0x5c, 0x37, 0x54, 0xf1, 0xb1, 0x2e, 0x28, 0x6e, 0x90, 0x6b, 0x98, 0xcb,
+	0xbb, 0x45, 0xac, 0x5c, 0xff, 0xbc, 0x92, 0xcf, 0xe8, 0x19, 0x97, 0xb2, 0xe7, 0x12, 0xb4, 0x3f,
+	0x14, 0x98, 0x8b, 0x75, 0x48, 0x6b, 0x70, 0x52, 0x4f, 0x30, 0x38, 0xe8, 0x3c, 0x64, 0x2d, 0x72,
+	0xcc, 0x0c, 0x1f, 0xde, 0x5e, 0x8e, 0x77, 0xd8, 0x25, 0xef, 0x34, 0x30, 0xff, 0xa9, 0x80, 0xba,
+	0x49, 0xd8, 0x06, 0xb5, 0x1c, 0xd3, 0x61, 0xc4, 0x2a, 0x9e, 0x74, 0x72, 0xda, 0xcf, 0x43, 0xf6,
+	0xc0, 0xb4, 0x1d, 0x66, 0x34, 0x23, 0x25, 0x8e, 0xfc, 0x30, 0x27, 0xef, 0x79, 0xe1, 0xca, 0xc3,
+	0xa8, 0x43, 0x8a, 0xd4, 0x2a, 0x19, 0xe1, 0x90, 0x8e, 0x08, 0xfa, 0xde, 0x23, 0xe7, 0xc0, 0x17,
+	0x0a, 0x4c, 0x47, 0x02, 0x7f, 0xca, 0x59, 0x50, 0x86, 0x99, 0x4d, 0xc2, 0xb6, 0x31, 0x23, 0x0e,
+	0x0b, 0x32, 0x26, 0xbb, 0x30, 0x60, 0x70, 0x4f, 0x07, 0x06, 0x63, 0x98, 0x8d, 0x53, 0x24, 0x4d,
+	0x8e, 0xb0, 0xa5, 0xa7, 0x2b, 0x5b, 0x0e, 0xe0, 0xcc, 0x26, 0x61, 0x81, 0x2a, 0xba, 0x41, 0xeb,
+	0xd6, 0x63, 0x37, 0xe5, 0x75, 0x98, 0x89, 0xd1, 0x23, 0x2d, 0xf1, 0xaa, 0x69, 0xd1, 0xa5, 0xfa,
+	0xab, 0x29, 0x67, 0xd3, 0xbe, 0x57, 0x60, 0x72, 0x93, 0xb0, 0x37, 0x2d, 0x66, 0x9f, 0x5c, 0xb5,
+	0x4a, 0xcf, 0x5d, 0x7d, 0xfe, 0x55, 0x5c, 0x20, 0x21, 0x7c, 0xdd, 0x1d, 0x4c, 0xef, 0xa6, 0x4c,
+	0x25, 0xdf, 0x94, 0x11, 0x31, 0xef, 0xed, 0x2a, 0xe6, 0x37, 0x61, 0x64, 0xcb, 0x32, 0x99, 0xfb,
+	0xf9, 0x98, 0xa3, 0x7c, 0x0d, 0xb2, 0x0d, 0xc9, 0xd2, 0xf6, 0x15, 0xe8, 0x2f, 0xda, 0x04, 0x33,
+	0x22, 0x64, 0x27, 0xa0, 0xf4, 0xf8, 0xb4, 0xaf, 0x14, 0x40, 0x5e, 0xd3, 0x72, 0x44, 0x9c, 0x36,
+	0x20, 0x2f, 0x42, 0xba, 0xc2, 0xf9, 0x64, 0x7d, 0x8d, 0xf0, 0x9b, 0x64, 0xe8, 0xbe, 0xc7, 0xd8,
+	0x85, 0xf1, 0x00, 0x10, 0x69, 0xd3, 0x15, 0x18, 0x6e, 0xf6, 0x4f, 0x4d, 0xcd, 0xb1, 0x5d, 0xc6,
+	0x50, 0xa3, 0x83, 0x3a, 0x22, 0x8e, 0xf6, 0x40, 0x81, 0xa9, 0x50, 0xe7, 0xf2, 0xe4, 0xac, 0xec,
+	0xe4, 0xec, 0xbe, 0x07, 0x6a, 0x14, 0x9e, 0x66, 0x00, 0xbd, 0xeb, 0xb1, 0x8d, 0x99, 0x1e, 0x9f,
+	0xf6, 0xa9, 0x48, 0x56, 0x21, 0x68, 0xfd, 0x84, 0xe7, 0x5b, 0x97, 0xc9, 0x9a, 0x0a, 0x26, 0x6b,
+	0xb7, 0x17, 0xbb, 0xf6, 0xa5, 0xc8, 0xc7, 0x10, 0x04, 0x69, 0x52, 0x17, 0xce, 0xfc, 0xdf, 0x97,
+	0xc5, 0xc3, 0xa0, 0x2f, 0x74, 0x6c, 0x95, 0x49, 0x1b, 0x5f, 0xcc, 0xc1, 0xa0, 0xc3, 0xb0, 0xcd,
+	0x02, 0x95, 0x0b, 0x38, 0x49, 0x78, 0xe3, 0x14, 0xf4, 0x89, 0x32, 0x29, 0xca, 0x96, 0xf8, 0xe8,
+	0x3e, 0xee, 0x21, 0x1f, 0x49, 0x68, 0x2d, 0x3e, 0x52, 0x1e, 0xc1, 0x47, 0xdd, 0x5d, 0x42, 0xff,
+	0x2a, 0x30, 0xe1, 0x03, 0xd2, 0x7d, 0x3b, 0x99, 0x0a, 0xb4, 0x93, 0x91, 0x1d, 0x63, 0xea, 0x39,
+	0xe9, 0x18, 0x7f, 0x0f, 0x9e, 0x87, 0x40, 0xa7, 0xf8, 0x14, 0xcf, 0x65, 0xc7, 0xdd, 0xe2, 0x6f,
+	0x0a, 0x8c, 0xef, 0x32, 0x9b, 0xe0, 0x6a, 0x47, 0x75, 0xea, 0x11, 0xcf, 0xee, 0x04, 0xa4, 0x0f,
+	0x68, 0xa5, 0x42, 0x3f, 0x91, 0x9d, 0xbd, 0xfc, 0xea, 0x3e, 0xef, 0xef, 0x2b, 0x70, 0x2a, 0x08,
+	0xf7, 0x19, 0x9c, 0xe7, 0x12, 0xa8, 0x1f, 0x60, 0x56, 0x3c, 0x0c, 0x30, 0x39, 0x8f, 0xfb, 0xb2,
+	0xfd, 0x18, 0xa6, 0x23, 0xb5, 0xc4, 0xb7, 0x86, 0x4a, 0x57, 0x56, 0xec, 0xc3, 0x70, 0xa0, 0xbe,
+	0x37, 0xfa, 0x13, 0x25, 0xb9, 0x3f, 0x59, 0x82, 0xb4, 0x78, 0x36, 0x69, 0x58, 0x21, 0x1e, 0x54,
+	0x96, 0xed, 0x5a, 0x71, 0x79, 0x97, 0xaf, 0xe8, 0x92, 0x43, 0xfb, 0xab, 0x07, 0xfa, 0x3d, 0xf1,
+	0x79, 0x18, 0xad, 0x12, 0xfb, 0x56, 0x85, 0x18, 0xcd, 0xd4, 0x56, 0xf8, 0xa4, 0x38, 0x22, 0xe8,
+	0xdb, 0x5e, 0x82, 0x7b, 0x97, 0xc5, 0x11, 0xae, 0xd4, 0x89, 0x9c, 0x26, 0x79, 0x3d, 0x78, 0xdf,
+	0x25, 0xb8, 0xcb, 0xe4, 0x98, 0xd9, 0xd8, 0x28, 0x61, 0x86, 0xf9, 0x39, 0x1b, 0xd2, 0x33, 0x9c,
+	0x72, 0x0d, 0x33, 0x1c, 0xba, 0x6a, 0x7a, 0xc3, 0x7d, 0xe1, 0x25, 0x40, 0x62, 0xb9, 0x44, 0x2c,
+	0x66, 0xb2, 0x13, 0x01, 0xa4, 0x8f, 0x4b, 0x19, 0xe5, 0x6c, 0x72, 0x81, 0x43, 0xd9, 0x80, 0x2c,
+	0xbf, 0xdc, 0x8d, 0xc6, 0x2b, 0x12, 0x2f, 0x09, 0x83, 0xab, 0xaa, 0x67, 0xb5, 0xf7, 0xce, 0xb4,
+	0xbc, 0xe7, 0x71, 0xe8, 0x23, 0x7c, 0x4b, 0xe3, 0x1b, 0x5d, 0x87, 0x71, 0xd3, 0x62, 0xa4, 0x6c,
+	0x63, 0xe6, 0x17, 0xd4, 0xdf, 0x56, 0x10, 0x6a, 0x6c, 0x6b, 0xd0, 0xb4, 0x6b, 0xd0, 0xc7, 0xbb,
+	0xca, 0x90, 0x9d, 0x4a, 0xd8, 0xce, 0x09, 0x48, 0xbb, 0x96, 0x11, 0x27, 0x97, 0xe2, 0xf5, 0x53,
+	0x7e, 0xbd, 0xdd, 0x3b, 0xd0, 0x33, 0x9a, 0x5a, 0xfd, 0x39, 0x0b, 0x83, 0x7b, 0x32, 0xbe, 0xdb,
+	0xb4, 0x8c, 0x2c, 0xc8, 0x34, 0xde, 0x91, 0x90, 0x1a, 0xea, 0x00, 0x7c, 0xaf, 0x40, 0xea, 0x74,
+	0xe4, 0x9a, 0x38, 0x93, 0x5a, 0xfe, 0xfe, 0xdf, 0xff, 0x7c, 0xdb, 0xa3, 0x69, 0x33, 0x85, 0xa3,
+	0x95, 0x7d, 0xc2, 0xf0, 0x4a, 0xa1, 0x42, 0xcb, 0x4e, 0xe1, 0xae, 0xc8, 0x87, 0x7b, 0x05, 0x91,
+	0x80, 0x6b, 0xca, 0x12, 0xfa, 0x46, 0x81, 0xd1, 0xf0, 0xf3, 0x0e, 0x3a, 0xdb, 0x94, 0x1d, 0xf3,
+	0x08, 0xa5, 0x6a, 0x49, 0x2c, 0x12, 0xc5, 0x2a, 0x47, 0x71, 0x49, 0xbb, 0x90, 0x8c, 0xc2, 0xbb,
+	0x3a, 0x4a, 0x2e, 0x9e, 0x9f, 0x14, 0x18, 0x6b, 0x19, 0xf4, 0x91, 0x4f, 0x5b, 0xdc, 0xeb, 0x91,
+	0xba, 0x90, 0xc8, 0x23, 0x21, 0xad, 0x73, 0x48, 0x57, 0xd0, 0x5a, 0x22, 0xa4, 0xc2, 0xdd, 0x66,
+	0x40, 0xef, 0xad, 0x99, 0x9e, 0x28, 0x43, 0x8c, 0x0f, 0xbf, 0x88, 0x9b, 0x25, 0xea, 0x2d, 0x02,
+	0xe5, 0x13, 0x40, 0x04, 0x2e, 0x5c, 0xf5, 0x62, 0x07, 0x9c, 0x12, 0xf4, 0xab, 0x1c, 0xf4, 0x0a,
+	0x2a, 0x24, 0xfb, 0xb1, 0x89, 0x73, 0x5f, 0x24, 0x13, 0xfa, 0x4e, 0x81, 0xf1, 0x88, 0x41, 0x1e,
+	0x9d, 0x0b, 0xe8, 0x8e, 0x79, 0xa0, 0x50, 0x17, 0xdb, 0x70, 0x49, 0x74, 0x97, 0x39, 0xba, 0x25,
+	0x94, 0x8f, 0x46, 0xb7, 0x56, 0x6c, 0x6e, 0x94, 0x0e, 0x7c, 0x28, 0xdb, 0x90, 0xd6, 0x79, 0x1b,
+	0x5d, 0x08, 0xe8, 0x8c, 0x1f, 0xfd, 0xd5, 0x7c, 0x7b, 0x46, 0x89, 0xef, 0x45, 0x8e, 0x6f, 0x11,
+	0x2d, 0xc4, 0x78, 0xcf, 0xad, 0xd8, 0xce, 0x5a, 0x85, 0x4b, 0x40, 0x3f, 0x2a, 0x70, 0x3a, 0x72,
+	0x7e, 0x46, 0xe7, 0x03, 0x0a, 0x63, 0x07, 0x79, 0xf5, 0x42, 0x5b, 0x3e, 0x89, 0xeb, 0x15, 0x8e,
+	0xab, 0x80, 0x5e, 0xea, 0x30, 0x3b, 0xc4, 0xc4, 0xce, 0x13, 0x36, 0x3c, 0x00, 0xfb, 0x13, 0x36,
+	0x66, 0x78, 0x57, 0xb5, 0x24, 0x96, 0x60, 0xc2, 0xa2, 0xa5, 0xce, 0xb3, 0x03, 0x15, 0xa1, 0x5f,
+	0x8e, 0xa2, 0x28, 0xd7, 0x54, 0x11, 0x9c, 0x7b, 0xd5, 0xa9, 0x88, 0x15, 0xa9, 0x73, 0x81, 0xeb,
+	0x9c, 0xd1, 0xa6, 0x63, 0x8e, 0x8f, 0x69, 0x99, 0x0c, 0x6d, 0xc3, 0xa0, 0x6f, 0x3e, 0x44, 0x67,
+	0x5a, 0x6b, 0x5f, 0xb3, 0x63, 0x52, 0x67, 0x62, 0x56, 0xa5, 0xc2, 0x17, 0x10, 0x06, 0xd4, 0x3a,
+	0x87, 0xa1, 0x85, 0xd8, 0x8a, 0xe6, 0x93, 0x7d, 0x2e, 0x99, 0xa9, 0xa1, 0xe2, 0x23, 0x1e, 0xa4,
+	0xc0, 0x54, 0x14, 0x0a, 0x52, 0xd4, 0xd0, 0xa6, 0x6a, 0x49, 0x2c, 0x31, 0xc2, 0xf9, 0x38, 0x11,
+	0x23, 0xdc, 0x3f, 0x05, 0xa9, 0x5a, 0x12, 0x4b, 0x43, 0xf8, 0x4d, 0xc8, 0x86, 0xda, 0x66, 0x34,
+	0x1f, 0xb9, 0xd1, 0x5f, 0xcc, 0xce, 0x26, 0x70, 0x34, 0x24, 0xdf, 0x80, 0x21, 0x7f, 0xc7, 0x88,
+	0x7c, 0x71, 0x8a, 0x68, 0x7c, 0xd5, 0xd9, 0xb8, 0x65, 0x4f, 0xe0, 0x65, 0x05, 0x1d, 0xc0, 0x78,
+	0x44, 0x6b, 0xe6, 0xaf, 0x6f, 0xf1, 0xfd, 0xa1, 0xba, 0xd8, 0x86, 0xab, 0xa9, 0x67, 0xfd, 0x5d,
+	0x98, 0x2a, 0xd2, 0xaa, 0xd7, 0x20, 0x04, 0xff, 0x9e, 0x5a, 0x1f, 0xf7, 0xdd, 0xdf, 0x57, 0x6b,
+	0xe6, 0x8e, 0x4b, 0xdc, 0x51, 0x3e, 0x54, 0xcb, 0x26, 0x3b, 0xac, 0xef, 0x2f, 0x17, 0x69, 0xb5,
+	0x20, 0x36, 0x16, 0xbc, 0x8d, 0xfb, 0x69, 0xbe, 0xf3, 0xe5, 0xff, 0x06, 0x00, 0xda, 0x8b, 0x55,
+	0x41, 0x64, 0x1b, 0x00, 0x00,
 }
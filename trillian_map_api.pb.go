@@ -24,8 +24,8 @@ type MapLeaf struct {
 	// All indexes for a given Map must contain a constant number of bits.
 	// These are not numeric indices. Note that this is typically derived using a
 	// hash and thus the length of all indices in the map will match the number
-	// of bits in the hash function. Map entries do not have a well defined
-	// ordering and it's not possible to sequentially iterate over them.
+	// of bits in the hash function. Map entries are ordered by index, and the
+	// non-empty entries at a revision can be iterated over with StreamLeaves.
 	Index []byte `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
 	// leaf_hash is the tree hash of leaf_value.  This does not need to be set
 	// on SetMapLeavesRequest; the server will fill it in.
@@ -72,6 +72,10 @@ func (m *MapLeaf) GetExtraData() []byte {
 type MapLeafInclusion struct {
 	Leaf      *MapLeaf `protobuf:"bytes,1,opt,name=leaf" json:"leaf,omitempty"`
 	Inclusion [][]byte `protobuf:"bytes,2,rep,name=inclusion,proto3" json:"inclusion,omitempty"`
+	// absent is set if there is no value at the leaf's index, in which case
+	// leaf has an empty leaf_value and inclusion is a proof that the index is
+	// not present in the map.
+	Absent bool `protobuf:"varint,3,opt,name=absent" json:"absent,omitempty"`
 }
 
 func (m *MapLeafInclusion) Reset()                    { *m = MapLeafInclusion{} }
@@ -93,9 +97,21 @@ func (m *MapLeafInclusion) GetInclusion() [][]byte {
 	return nil
 }
 
+func (m *MapLeafInclusion) GetAbsent() bool {
+	if m != nil {
+		return m.Absent
+	}
+	return false
+}
+
 type GetMapLeavesRequest struct {
 	MapId int64    `protobuf:"varint,1,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
 	Index [][]byte `protobuf:"bytes,2,rep,name=index,proto3" json:"index,omitempty"`
+	// If batch_proof is set, the inclusion proofs of the leaves are returned
+	// as a single batch_inclusion proof in the response, in which nodes shared
+	// by the proofs are only included once, instead of in each
+	// map_leaf_inclusion. The indexes must then be distinct.
+	BatchProof bool `protobuf:"varint,4,opt,name=batch_proof,json=batchProof" json:"batch_proof,omitempty"`
 }
 
 func (m *GetMapLeavesRequest) Reset()                    { *m = GetMapLeavesRequest{} }
@@ -117,6 +133,13 @@ func (m *GetMapLeavesRequest) GetIndex() [][]byte {
 	return nil
 }
 
+func (m *GetMapLeavesRequest) GetBatchProof() bool {
+	if m != nil {
+		return m.BatchProof
+	}
+	return false
+}
+
 // This message replaces the current implementation of GetMapLeavesRequest
 // with the difference that revision must be >=0.
 type GetMapLeavesByRevisionRequest struct {
@@ -124,6 +147,8 @@ type GetMapLeavesByRevisionRequest struct {
 	Index [][]byte `protobuf:"bytes,2,rep,name=index,proto3" json:"index,omitempty"`
 	// revision >= 0.
 	Revision int64 `protobuf:"varint,3,opt,name=revision" json:"revision,omitempty"`
+	// See GetMapLeavesRequest.batch_proof.
+	BatchProof bool `protobuf:"varint,4,opt,name=batch_proof,json=batchProof" json:"batch_proof,omitempty"`
 }
 
 func (m *GetMapLeavesByRevisionRequest) Reset()                    { *m = GetMapLeavesByRevisionRequest{} }
@@ -152,9 +177,20 @@ func (m *GetMapLeavesByRevisionRequest) GetRevision() int64 {
 	return 0
 }
 
+func (m *GetMapLeavesByRevisionRequest) GetBatchProof() bool {
+	if m != nil {
+		return m.BatchProof
+	}
+	return false
+}
+
 type GetMapLeavesResponse struct {
 	MapLeafInclusion []*MapLeafInclusion `protobuf:"bytes,2,rep,name=map_leaf_inclusion,json=mapLeafInclusion" json:"map_leaf_inclusion,omitempty"`
 	MapRoot          *SignedMapRoot      `protobuf:"bytes,3,opt,name=map_root,json=mapRoot" json:"map_root,omitempty"`
+	// batch_inclusion is set if the request's batch_proof was, in which case it
+	// is the batch inclusion proof of all the leaves in map_leaf_inclusion,
+	// whose inclusion fields are empty.
+	BatchInclusion [][]byte `protobuf:"bytes,4,rep,name=batch_inclusion,json=batchInclusion,proto3" json:"batch_inclusion,omitempty"`
 }
 
 func (m *GetMapLeavesResponse) Reset()                    { *m = GetMapLeavesResponse{} }
@@ -176,10 +212,23 @@ func (m *GetMapLeavesResponse) GetMapRoot() *SignedMapRoot {
 	return nil
 }
 
+func (m *GetMapLeavesResponse) GetBatchInclusion() [][]byte {
+	if m != nil {
+		return m.BatchInclusion
+	}
+	return nil
+}
+
 type SetMapLeavesRequest struct {
 	MapId    int64      `protobuf:"varint,1,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
 	Leaves   []*MapLeaf `protobuf:"bytes,2,rep,name=leaves" json:"leaves,omitempty"`
 	Metadata []byte     `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// revision, if non-zero, is the revision the new map root must have. This
+	// lets a client make sure the map hasn't changed since it read revision-1: if
+	// it has been written to since, or revision is otherwise not the map's next
+	// revision, the request fails with FAILED_PRECONDITION and nothing is written.
+	// If zero, the leaves are written at the map's next revision.
+	Revision int64 `protobuf:"varint,6,opt,name=revision" json:"revision,omitempty"`
 }
 
 func (m *SetMapLeavesRequest) Reset()                    { *m = SetMapLeavesRequest{} }
@@ -208,6 +257,13 @@ func (m *SetMapLeavesRequest) GetMetadata() []byte {
 	return nil
 }
 
+func (m *SetMapLeavesRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
 type SetMapLeavesResponse struct {
 	MapRoot *SignedMapRoot `protobuf:"bytes,2,opt,name=map_root,json=mapRoot" json:"map_root,omitempty"`
 }
@@ -314,6 +370,248 @@ func (m *InitMapResponse) GetCreated() *SignedMapRoot {
 	return nil
 }
 
+type StreamMapLeavesRequest struct {
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
+	// revision >= 0.
+	Revision int64 `protobuf:"varint,2,opt,name=revision" json:"revision,omitempty"`
+	// cursor is the index of the last leaf received by an earlier stream, which
+	// is resumed from the leaf following it. If empty, the stream starts from
+	// the first leaf in the map.
+	Cursor []byte `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+}
+
+func (m *StreamMapLeavesRequest) Reset()                    { *m = StreamMapLeavesRequest{} }
+func (m *StreamMapLeavesRequest) String() string            { return proto.CompactTextString(m) }
+func (*StreamMapLeavesRequest) ProtoMessage()               {}
+func (*StreamMapLeavesRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{12} }
+
+func (m *StreamMapLeavesRequest) GetMapId() int64 {
+	if m != nil {
+		return m.MapId
+	}
+	return 0
+}
+
+func (m *StreamMapLeavesRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *StreamMapLeavesRequest) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+type StreamMapLeavesResponse struct {
+	// leaves holds the next batch of non-empty leaves at the requested
+	// revision, in ascending index order.
+	Leaves []*MapLeaf `protobuf:"bytes,1,rep,name=leaves" json:"leaves,omitempty"`
+	// map_root is the root of the map at the requested revision.
+	MapRoot *SignedMapRoot `protobuf:"bytes,2,opt,name=map_root,json=mapRoot" json:"map_root,omitempty"`
+}
+
+func (m *StreamMapLeavesResponse) Reset()                    { *m = StreamMapLeavesResponse{} }
+func (m *StreamMapLeavesResponse) String() string            { return proto.CompactTextString(m) }
+func (*StreamMapLeavesResponse) ProtoMessage()               {}
+func (*StreamMapLeavesResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{13} }
+
+func (m *StreamMapLeavesResponse) GetLeaves() []*MapLeaf {
+	if m != nil {
+		return m.Leaves
+	}
+	return nil
+}
+
+func (m *StreamMapLeavesResponse) GetMapRoot() *SignedMapRoot {
+	if m != nil {
+		return m.MapRoot
+	}
+	return nil
+}
+
+type GetMapLeafHistoryRequest struct {
+	MapId int64  `protobuf:"varint,1,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
+	Index []byte `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Changes to the leaf after start_revision are returned.
+	// start_revision >= 0.
+	StartRevision int64 `protobuf:"varint,3,opt,name=start_revision,json=startRevision" json:"start_revision,omitempty"`
+	// Changes up to and including end_revision are returned.
+	// end_revision >= start_revision.
+	EndRevision int64 `protobuf:"varint,4,opt,name=end_revision,json=endRevision" json:"end_revision,omitempty"`
+}
+
+func (m *GetMapLeafHistoryRequest) Reset()                    { *m = GetMapLeafHistoryRequest{} }
+func (m *GetMapLeafHistoryRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetMapLeafHistoryRequest) ProtoMessage()               {}
+func (*GetMapLeafHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{14} }
+
+func (m *GetMapLeafHistoryRequest) GetMapId() int64 {
+	if m != nil {
+		return m.MapId
+	}
+	return 0
+}
+
+func (m *GetMapLeafHistoryRequest) GetIndex() []byte {
+	if m != nil {
+		return m.Index
+	}
+	return nil
+}
+
+func (m *GetMapLeafHistoryRequest) GetStartRevision() int64 {
+	if m != nil {
+		return m.StartRevision
+	}
+	return 0
+}
+
+func (m *GetMapLeafHistoryRequest) GetEndRevision() int64 {
+	if m != nil {
+		return m.EndRevision
+	}
+	return 0
+}
+
+// MapLeafHistoryEntry holds the value of a map leaf at a revision at which it
+// changed.
+type MapLeafHistoryEntry struct {
+	// revision is the map revision at which the leaf took this value.
+	Revision int64 `protobuf:"varint,1,opt,name=revision" json:"revision,omitempty"`
+	// leaf_inclusion holds the leaf and its inclusion proof at revision.
+	LeafInclusion *MapLeafInclusion `protobuf:"bytes,2,opt,name=leaf_inclusion,json=leafInclusion" json:"leaf_inclusion,omitempty"`
+	// map_root is the root of the map at revision.
+	MapRoot *SignedMapRoot `protobuf:"bytes,3,opt,name=map_root,json=mapRoot" json:"map_root,omitempty"`
+}
+
+func (m *MapLeafHistoryEntry) Reset()                    { *m = MapLeafHistoryEntry{} }
+func (m *MapLeafHistoryEntry) String() string            { return proto.CompactTextString(m) }
+func (*MapLeafHistoryEntry) ProtoMessage()               {}
+func (*MapLeafHistoryEntry) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{15} }
+
+func (m *MapLeafHistoryEntry) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *MapLeafHistoryEntry) GetLeafInclusion() *MapLeafInclusion {
+	if m != nil {
+		return m.LeafInclusion
+	}
+	return nil
+}
+
+func (m *MapLeafHistoryEntry) GetMapRoot() *SignedMapRoot {
+	if m != nil {
+		return m.MapRoot
+	}
+	return nil
+}
+
+type GetMapLeafHistoryResponse struct {
+	// entries holds an entry for each revision after start_revision, up to and
+	// including end_revision, at which the value of the leaf changed, in
+	// ascending revision order.
+	Entries []*MapLeafHistoryEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+}
+
+func (m *GetMapLeafHistoryResponse) Reset()                    { *m = GetMapLeafHistoryResponse{} }
+func (m *GetMapLeafHistoryResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetMapLeafHistoryResponse) ProtoMessage()               {}
+func (*GetMapLeafHistoryResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{16} }
+
+func (m *GetMapLeafHistoryResponse) GetEntries() []*MapLeafHistoryEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type QueueMapLeavesRequest struct {
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
+	// leaves are the values to write. As in SetMapLeavesRequest, a leaf with a
+	// nil value is ignored, and each index may only appear once.
+	Leaves []*MapLeaf `protobuf:"bytes,2,rep,name=leaves" json:"leaves,omitempty"`
+}
+
+func (m *QueueMapLeavesRequest) Reset()                    { *m = QueueMapLeavesRequest{} }
+func (m *QueueMapLeavesRequest) String() string            { return proto.CompactTextString(m) }
+func (*QueueMapLeavesRequest) ProtoMessage()               {}
+func (*QueueMapLeavesRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{17} }
+
+func (m *QueueMapLeavesRequest) GetMapId() int64 {
+	if m != nil {
+		return m.MapId
+	}
+	return 0
+}
+
+func (m *QueueMapLeavesRequest) GetLeaves() []*MapLeaf {
+	if m != nil {
+		return m.Leaves
+	}
+	return nil
+}
+
+type QueueMapLeavesResponse struct {
+}
+
+func (m *QueueMapLeavesResponse) Reset()                    { *m = QueueMapLeavesResponse{} }
+func (m *QueueMapLeavesResponse) String() string            { return proto.CompactTextString(m) }
+func (*QueueMapLeavesResponse) ProtoMessage()               {}
+func (*QueueMapLeavesResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{18} }
+
+type GetMapStatusRequest struct {
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
+}
+
+func (m *GetMapStatusRequest) Reset()                    { *m = GetMapStatusRequest{} }
+func (m *GetMapStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetMapStatusRequest) ProtoMessage()               {}
+func (*GetMapStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{19} }
+
+func (m *GetMapStatusRequest) GetMapId() int64 {
+	if m != nil {
+		return m.MapId
+	}
+	return 0
+}
+
+type GetMapStatusResponse struct {
+	// master is the ID of the instance which is master for the map, and so
+	// accepts writes to it. It's empty if the server doesn't hold master
+	// elections, in which case every instance accepts writes.
+	Master string `protobuf:"bytes,1,opt,name=master" json:"master,omitempty"`
+	// is_master is true if the instance serving the request is master for the
+	// map.
+	IsMaster bool `protobuf:"varint,2,opt,name=is_master,json=isMaster" json:"is_master,omitempty"`
+}
+
+func (m *GetMapStatusResponse) Reset()                    { *m = GetMapStatusResponse{} }
+func (m *GetMapStatusResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetMapStatusResponse) ProtoMessage()               {}
+func (*GetMapStatusResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{20} }
+
+func (m *GetMapStatusResponse) GetMaster() string {
+	if m != nil {
+		return m.Master
+	}
+	return ""
+}
+
+func (m *GetMapStatusResponse) GetIsMaster() bool {
+	if m != nil {
+		return m.IsMaster
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*MapLeaf)(nil), "trillian.MapLeaf")
 	proto.RegisterType((*MapLeafInclusion)(nil), "trillian.MapLeafInclusion")
@@ -327,6 +625,15 @@ func init() {
 	proto.RegisterType((*GetSignedMapRootResponse)(nil), "trillian.GetSignedMapRootResponse")
 	proto.RegisterType((*InitMapRequest)(nil), "trillian.InitMapRequest")
 	proto.RegisterType((*InitMapResponse)(nil), "trillian.InitMapResponse")
+	proto.RegisterType((*StreamMapLeavesRequest)(nil), "trillian.StreamMapLeavesRequest")
+	proto.RegisterType((*StreamMapLeavesResponse)(nil), "trillian.StreamMapLeavesResponse")
+	proto.RegisterType((*GetMapLeafHistoryRequest)(nil), "trillian.GetMapLeafHistoryRequest")
+	proto.RegisterType((*MapLeafHistoryEntry)(nil), "trillian.MapLeafHistoryEntry")
+	proto.RegisterType((*GetMapLeafHistoryResponse)(nil), "trillian.GetMapLeafHistoryResponse")
+	proto.RegisterType((*QueueMapLeavesRequest)(nil), "trillian.QueueMapLeavesRequest")
+	proto.RegisterType((*QueueMapLeavesResponse)(nil), "trillian.QueueMapLeavesResponse")
+	proto.RegisterType((*GetMapStatusRequest)(nil), "trillian.GetMapStatusRequest")
+	proto.RegisterType((*GetMapStatusResponse)(nil), "trillian.GetMapStatusResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -351,6 +658,22 @@ type TrillianMapClient interface {
 	GetSignedMapRoot(ctx context.Context, in *GetSignedMapRootRequest, opts ...grpc.CallOption) (*GetSignedMapRootResponse, error)
 	GetSignedMapRootByRevision(ctx context.Context, in *GetSignedMapRootByRevisionRequest, opts ...grpc.CallOption) (*GetSignedMapRootResponse, error)
 	InitMap(ctx context.Context, in *InitMapRequest, opts ...grpc.CallOption) (*InitMapResponse, error)
+	// StreamLeaves streams all of the non-empty leaves at the requested revision
+	// in ascending index order, without inclusion proofs. The stream ends once
+	// the last leaf has been sent.
+	StreamLeaves(ctx context.Context, in *StreamMapLeavesRequest, opts ...grpc.CallOption) (TrillianMap_StreamLeavesClient, error)
+	// GetLeafHistory returns the value of a leaf, with an inclusion proof, at
+	// each revision in a range at which the value changed.
+	GetLeafHistory(ctx context.Context, in *GetMapLeafHistoryRequest, opts ...grpc.CallOption) (*GetMapLeafHistoryResponse, error)
+	// QueueLeaves queues values for the provided leaves, which a map signer
+	// writes to a later revision of the map, batched with other queued values.
+	// It returns once the values are queued, without waiting for them to be
+	// written. A value queued later replaces an earlier one for the same
+	// index if both are written to the same revision.
+	QueueLeaves(ctx context.Context, in *QueueMapLeavesRequest, opts ...grpc.CallOption) (*QueueMapLeavesResponse, error)
+	// GetMapStatus returns which instance is master for a map, and so accepts
+	// writes to it.
+	GetMapStatus(ctx context.Context, in *GetMapStatusRequest, opts ...grpc.CallOption) (*GetMapStatusResponse, error)
 }
 
 type trillianMapClient struct {
@@ -415,6 +738,65 @@ func (c *trillianMapClient) InitMap(ctx context.Context, in *InitMapRequest, opt
 	return out, nil
 }
 
+func (c *trillianMapClient) StreamLeaves(ctx context.Context, in *StreamMapLeavesRequest, opts ...grpc.CallOption) (TrillianMap_StreamLeavesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TrillianMap_serviceDesc.Streams[0], c.cc, "/trillian.TrillianMap/StreamLeaves", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &trillianMapStreamLeavesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TrillianMap_StreamLeavesClient interface {
+	Recv() (*StreamMapLeavesResponse, error)
+	grpc.ClientStream
+}
+
+type trillianMapStreamLeavesClient struct {
+	grpc.ClientStream
+}
+
+func (x *trillianMapStreamLeavesClient) Recv() (*StreamMapLeavesResponse, error) {
+	m := new(StreamMapLeavesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *trillianMapClient) GetLeafHistory(ctx context.Context, in *GetMapLeafHistoryRequest, opts ...grpc.CallOption) (*GetMapLeafHistoryResponse, error) {
+	out := new(GetMapLeafHistoryResponse)
+	err := grpc.Invoke(ctx, "/trillian.TrillianMap/GetLeafHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianMapClient) QueueLeaves(ctx context.Context, in *QueueMapLeavesRequest, opts ...grpc.CallOption) (*QueueMapLeavesResponse, error) {
+	out := new(QueueMapLeavesResponse)
+	err := grpc.Invoke(ctx, "/trillian.TrillianMap/QueueLeaves", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianMapClient) GetMapStatus(ctx context.Context, in *GetMapStatusRequest, opts ...grpc.CallOption) (*GetMapStatusResponse, error) {
+	out := new(GetMapStatusResponse)
+	err := grpc.Invoke(ctx, "/trillian.TrillianMap/GetMapStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for TrillianMap service
 
 type TrillianMapServer interface {
@@ -429,6 +811,22 @@ type TrillianMapServer interface {
 	GetSignedMapRoot(context.Context, *GetSignedMapRootRequest) (*GetSignedMapRootResponse, error)
 	GetSignedMapRootByRevision(context.Context, *GetSignedMapRootByRevisionRequest) (*GetSignedMapRootResponse, error)
 	InitMap(context.Context, *InitMapRequest) (*InitMapResponse, error)
+	// StreamLeaves streams all of the non-empty leaves at the requested revision
+	// in ascending index order, without inclusion proofs. The stream ends once
+	// the last leaf has been sent.
+	StreamLeaves(*StreamMapLeavesRequest, TrillianMap_StreamLeavesServer) error
+	// GetLeafHistory returns the value of a leaf, with an inclusion proof, at
+	// each revision in a range at which the value changed.
+	GetLeafHistory(context.Context, *GetMapLeafHistoryRequest) (*GetMapLeafHistoryResponse, error)
+	// QueueLeaves queues values for the provided leaves, which a map signer
+	// writes to a later revision of the map, batched with other queued values.
+	// It returns once the values are queued, without waiting for them to be
+	// written. A value queued later replaces an earlier one for the same
+	// index if both are written to the same revision.
+	QueueLeaves(context.Context, *QueueMapLeavesRequest) (*QueueMapLeavesResponse, error)
+	// GetMapStatus returns which instance is master for a map, and so accepts
+	// writes to it.
+	GetMapStatus(context.Context, *GetMapStatusRequest) (*GetMapStatusResponse, error)
 }
 
 func RegisterTrillianMapServer(s *grpc.Server, srv TrillianMapServer) {
@@ -543,6 +941,81 @@ func _TrillianMap_InitMap_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianMap_StreamLeaves_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamMapLeavesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TrillianMapServer).StreamLeaves(m, &trillianMapStreamLeavesServer{stream})
+}
+
+type TrillianMap_StreamLeavesServer interface {
+	Send(*StreamMapLeavesResponse) error
+	grpc.ServerStream
+}
+
+type trillianMapStreamLeavesServer struct {
+	grpc.ServerStream
+}
+
+func (x *trillianMapStreamLeavesServer) Send(m *StreamMapLeavesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TrillianMap_GetLeafHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMapLeafHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).GetLeafHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/GetLeafHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).GetLeafHistory(ctx, req.(*GetMapLeafHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianMap_QueueLeaves_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueMapLeavesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).QueueLeaves(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/QueueLeaves",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).QueueLeaves(ctx, req.(*QueueMapLeavesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianMap_GetMapStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMapStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).GetMapStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/GetMapStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).GetMapStatus(ctx, req.(*GetMapStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TrillianMap_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "trillian.TrillianMap",
 	HandlerType: (*TrillianMapServer)(nil),
@@ -571,57 +1044,96 @@ var _TrillianMap_serviceDesc = grpc.ServiceDesc{
 			MethodName: "InitMap",
 			Handler:    _TrillianMap_InitMap_Handler,
 		},
+		{
+			MethodName: "GetLeafHistory",
+			Handler:    _TrillianMap_GetLeafHistory_Handler,
+		},
+		{
+			MethodName: "QueueLeaves",
+			Handler:    _TrillianMap_QueueLeaves_Handler,
+		},
+		{
+			MethodName: "GetMapStatus",
+			Handler:    _TrillianMap_GetMapStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLeaves",
+			Handler:       _TrillianMap_StreamLeaves_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "trillian_map_api.proto",
 }
 
 func init() { proto.RegisterFile("trillian_map_api.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 693 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xdd, 0x4e, 0xdb, 0x4c,
-	0x10, 0xfd, 0xf2, 0x47, 0x92, 0xc9, 0x27, 0x9a, 0x2e, 0xb4, 0x18, 0x43, 0x2a, 0x30, 0x42, 0x14,
-	0x21, 0xc5, 0x25, 0xbd, 0xe3, 0xae, 0x08, 0x89, 0x1f, 0x01, 0x42, 0x4e, 0x45, 0xa5, 0xde, 0xa4,
-	0x43, 0xb2, 0x24, 0x2b, 0xd9, 0x5e, 0x37, 0xde, 0x44, 0xb4, 0x08, 0x55, 0xea, 0x45, 0x5f, 0xa0,
-	0xbd, 0xea, 0x45, 0x5f, 0xaa, 0xaf, 0xd0, 0x07, 0xa9, 0x76, 0xd7, 0xf9, 0x77, 0xd2, 0xa8, 0xbd,
-	0xf3, 0xee, 0x99, 0x99, 0x73, 0xe6, 0xcc, 0xac, 0x0c, 0x4f, 0x45, 0x9b, 0xb9, 0x2e, 0x43, 0xbf,
-	0xe6, 0x61, 0x50, 0xc3, 0x80, 0x95, 0x83, 0x36, 0x17, 0x9c, 0xe4, 0x7a, 0xf7, 0xe6, 0x62, 0xef,
-	0x4b, 0x23, 0xe6, 0x7a, 0x93, 0xf3, 0xa6, 0x4b, 0x6d, 0x0c, 0x98, 0x8d, 0xbe, 0xcf, 0x05, 0x0a,
-	0xc6, 0xfd, 0x50, 0xa3, 0xd6, 0x47, 0xc8, 0x5e, 0x60, 0x70, 0x4e, 0xf1, 0x96, 0x2c, 0x43, 0x86,
-	0xf9, 0x0d, 0x7a, 0x67, 0x24, 0x36, 0x12, 0xcf, 0xff, 0x77, 0xf4, 0x81, 0xac, 0x41, 0xde, 0xa5,
-	0x78, 0x5b, 0x6b, 0x61, 0xd8, 0x32, 0x92, 0x0a, 0xc9, 0xc9, 0x8b, 0x13, 0x0c, 0x5b, 0xa4, 0x04,
-	0xa0, 0xc0, 0x2e, 0xba, 0x1d, 0x6a, 0xa4, 0x14, 0xaa, 0xc2, 0xaf, 0xe5, 0x85, 0x84, 0xe9, 0x9d,
-	0x68, 0x63, 0xad, 0x81, 0x02, 0x8d, 0xb4, 0x86, 0xd5, 0xcd, 0x11, 0x0a, 0xb4, 0xde, 0x40, 0x31,
-	0xe2, 0x3e, 0xf5, 0xeb, 0x6e, 0x27, 0x64, 0xdc, 0x27, 0xdb, 0x90, 0x96, 0xf9, 0x4a, 0x43, 0xa1,
-	0xf2, 0xb8, 0xdc, 0x6f, 0x26, 0x8a, 0x74, 0x14, 0x4c, 0xd6, 0x21, 0xcf, 0x7a, 0x39, 0x46, 0x72,
-	0x23, 0x25, 0x0b, 0xf7, 0x2f, 0xac, 0x13, 0x58, 0x3a, 0xa6, 0x42, 0x67, 0x74, 0x69, 0xe8, 0xd0,
-	0xf7, 0x1d, 0x1a, 0x0a, 0xf2, 0x04, 0x16, 0xa4, 0x69, 0xac, 0xa1, 0xaa, 0xa7, 0x9c, 0x8c, 0x87,
-	0xc1, 0x69, 0x63, 0xd0, 0xb7, 0xae, 0xa3, 0x0f, 0x67, 0xe9, 0x5c, 0xaa, 0x98, 0xb6, 0x5a, 0x50,
-	0x1a, 0xae, 0x74, 0xf8, 0xc1, 0xa1, 0x5d, 0x26, 0x39, 0xfe, 0xa6, 0x26, 0x31, 0x21, 0xd7, 0x8e,
-	0xf2, 0x95, 0x59, 0x29, 0xa7, 0x7f, 0xb6, 0xbe, 0x25, 0x60, 0x79, 0x54, 0x74, 0x18, 0x70, 0x3f,
-	0xa4, 0xe4, 0x04, 0x88, 0x64, 0x50, 0x3e, 0x8f, 0xf6, 0x5c, 0xa8, 0x98, 0x13, 0xfe, 0xf4, 0x9d,
-	0x74, 0x8a, 0xde, 0xb8, 0xb7, 0x15, 0xc8, 0xc9, 0x4a, 0x6d, 0xce, 0x85, 0xa2, 0x2f, 0x54, 0x56,
-	0x06, 0xf9, 0x55, 0xd6, 0xf4, 0x69, 0xe3, 0x02, 0x03, 0x87, 0x73, 0xe1, 0x64, 0x3d, 0xfd, 0x61,
-	0x7d, 0x82, 0xa5, 0xea, 0xfc, 0x56, 0xee, 0xc2, 0x82, 0xab, 0xe2, 0x22, 0x7d, 0x31, 0xf3, 0x8b,
-	0x02, 0xa4, 0x17, 0x1e, 0x15, 0xa8, 0x36, 0x23, 0xa3, 0xd7, 0xaa, 0x77, 0xd6, 0xde, 0x9f, 0xa5,
-	0x73, 0xe9, 0x62, 0xc6, 0x3a, 0x83, 0xe5, 0x6a, 0x9c, 0x2d, 0xc3, 0xcd, 0x24, 0xe7, 0x6c, 0xe6,
-	0x05, 0xac, 0x1c, 0x53, 0x31, 0x0a, 0xce, 0x6c, 0xc8, 0xba, 0x86, 0xcd, 0xf1, 0x8c, 0xb9, 0x77,
-	0x60, 0x78, 0xda, 0xc9, 0xb1, 0x69, 0x5f, 0x82, 0x31, 0xa9, 0xe4, 0x1f, 0x3a, 0xdb, 0x81, 0xc5,
-	0x53, 0x9f, 0x49, 0x9b, 0xfe, 0xd0, 0xd0, 0x11, 0x3c, 0xea, 0x07, 0x46, 0x7c, 0xfb, 0x90, 0xad,
-	0xb7, 0x29, 0x0a, 0xda, 0x88, 0x5e, 0xdd, 0x74, 0xba, 0x28, 0xae, 0xf2, 0x3d, 0x03, 0x85, 0xd7,
-	0x51, 0xcc, 0x05, 0x06, 0xe4, 0x1c, 0xf2, 0xc7, 0x54, 0xe8, 0x09, 0x91, 0xd2, 0x20, 0x3d, 0xe6,
-	0x15, 0x9a, 0xcf, 0xa6, 0xc1, 0x5a, 0x8e, 0xf5, 0x1f, 0x79, 0xa7, 0x9e, 0xef, 0xf8, 0x8b, 0x23,
-	0x3b, 0xf1, 0x89, 0x13, 0xf3, 0x98, 0x83, 0xe1, 0x1c, 0xf2, 0xd5, 0x38, 0xbd, 0xd5, 0xd9, 0x7a,
-	0xab, 0xf1, 0xd5, 0xbe, 0x24, 0xa0, 0x38, 0x3e, 0x4d, 0xb2, 0x39, 0x22, 0x22, 0x6e, 0xe7, 0x4c,
-	0x6b, 0x56, 0x48, 0x54, 0x7d, 0xef, 0xf3, 0xcf, 0x5f, 0x5f, 0x93, 0xdb, 0x64, 0xcb, 0xee, 0xee,
-	0xdf, 0x50, 0x81, 0xfb, 0xb6, 0x87, 0x41, 0x68, 0xdf, 0xeb, 0xd9, 0x3e, 0xd8, 0x72, 0x4b, 0xc2,
-	0x03, 0x17, 0x85, 0x9c, 0xf9, 0x8f, 0x04, 0x98, 0xd3, 0xd7, 0x95, 0xec, 0x4d, 0xe7, 0x9b, 0x34,
-	0x71, 0x1e, 0x71, 0xb6, 0x12, 0xb7, 0x4b, 0x76, 0x66, 0x89, 0xb3, 0xef, 0x7b, 0x5b, 0xff, 0x40,
-	0xea, 0x90, 0x8d, 0xb6, 0x8f, 0x18, 0x83, 0xfa, 0xa3, 0x9b, 0x6b, 0xae, 0xc6, 0x20, 0x11, 0xe1,
-	0x96, 0x22, 0x2c, 0x59, 0x6b, 0xf1, 0x84, 0x07, 0xcc, 0x67, 0xe2, 0xf0, 0x12, 0x56, 0xeb, 0xdc,
-	0x2b, 0xeb, 0xdf, 0x5e, 0x79, 0xf4, 0x6f, 0x78, 0xb8, 0x34, 0xb4, 0xb6, 0xaf, 0x02, 0x76, 0x25,
-	0x2f, 0xaf, 0x12, 0x6f, 0xcd, 0x26, 0x13, 0xad, 0xce, 0x4d, 0xb9, 0xce, 0x3d, 0x3b, 0xfa, 0x5f,
-	0xf6, 0x12, 0x6f, 0x16, 0x54, 0xe6, 0xcb, 0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x2d, 0xb0, 0x61,
-	0x82, 0x7b, 0x07, 0x00, 0x00,
+	// 1034 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0xcd, 0x6e, 0xdb, 0x46,
+	0x10, 0x2e, 0x25, 0x59, 0x3f, 0x23, 0x47, 0x51, 0xd7, 0x8e, 0x2c, 0x33, 0x71, 0x62, 0xaf, 0x61,
+	0xb8, 0x41, 0x0a, 0x29, 0x56, 0x0f, 0x05, 0x72, 0x8b, 0x91, 0xc2, 0x3f, 0xb5, 0x53, 0x97, 0x0a,
+	0x52, 0xb4, 0x3d, 0xa8, 0x2b, 0x69, 0x6d, 0x2d, 0x20, 0x72, 0x59, 0x72, 0x65, 0xd8, 0x0d, 0x72,
+	0xe9, 0xa1, 0x40, 0x8f, 0x45, 0x8f, 0x05, 0x7a, 0xee, 0x4b, 0xf4, 0x29, 0xfa, 0x0a, 0x7d, 0x85,
+	0xde, 0x8b, 0x5d, 0x2e, 0x29, 0x52, 0xa2, 0x7e, 0x90, 0xe4, 0xa6, 0x9d, 0x99, 0x9d, 0xf9, 0xe6,
+	0x9b, 0xe1, 0xb7, 0x10, 0xd4, 0x84, 0xc7, 0x86, 0x43, 0x46, 0x9c, 0x8e, 0x4d, 0xdc, 0x0e, 0x71,
+	0x59, 0xc3, 0xf5, 0xb8, 0xe0, 0xa8, 0x18, 0xda, 0xcd, 0x4a, 0xf8, 0x2b, 0xf0, 0x98, 0x0f, 0xae,
+	0x38, 0xbf, 0x1a, 0xd2, 0x26, 0x71, 0x59, 0x93, 0x38, 0x0e, 0x17, 0x44, 0x30, 0xee, 0xf8, 0x81,
+	0x17, 0xff, 0x04, 0x85, 0x73, 0xe2, 0x9e, 0x51, 0x72, 0x89, 0xd6, 0x61, 0x85, 0x39, 0x7d, 0x7a,
+	0x53, 0x37, 0xb6, 0x8d, 0x4f, 0x56, 0xad, 0xe0, 0x80, 0xee, 0x43, 0x69, 0x48, 0xc9, 0x65, 0x67,
+	0x40, 0xfc, 0x41, 0x3d, 0xa3, 0x3c, 0x45, 0x69, 0x38, 0x26, 0xfe, 0x00, 0x6d, 0x01, 0x28, 0xe7,
+	0x35, 0x19, 0x8e, 0x68, 0x3d, 0xab, 0xbc, 0x2a, 0xfc, 0xb5, 0x34, 0x48, 0x37, 0xbd, 0x11, 0x1e,
+	0xe9, 0xf4, 0x89, 0x20, 0xf5, 0x5c, 0xe0, 0x56, 0x96, 0x17, 0x44, 0x10, 0xcc, 0xa1, 0xaa, 0x6b,
+	0x9f, 0x38, 0xbd, 0xe1, 0xc8, 0x67, 0xdc, 0x41, 0x7b, 0x90, 0x93, 0xf7, 0x15, 0x86, 0x72, 0xeb,
+	0xe3, 0x46, 0xd4, 0x8c, 0x8e, 0xb4, 0x94, 0x1b, 0x3d, 0x80, 0x12, 0x0b, 0xef, 0xd4, 0x33, 0xdb,
+	0x59, 0x99, 0x38, 0x32, 0xa0, 0x1a, 0xe4, 0x49, 0xd7, 0xa7, 0x8e, 0x50, 0x90, 0x8a, 0x96, 0x3e,
+	0x61, 0x06, 0x6b, 0x47, 0x54, 0x04, 0x99, 0xae, 0xa9, 0x6f, 0xd1, 0x1f, 0x47, 0xd4, 0x17, 0xe8,
+	0x1e, 0xe4, 0x25, 0x99, 0xac, 0xaf, 0xaa, 0x66, 0xad, 0x15, 0x9b, 0xb8, 0x27, 0xfd, 0x31, 0x1f,
+	0x41, 0x7e, 0xcd, 0xc7, 0x23, 0x28, 0x77, 0x89, 0xe8, 0x0d, 0x3a, 0xae, 0xc7, 0xf9, 0xa5, 0x6a,
+	0xaa, 0x68, 0x81, 0x32, 0x5d, 0x48, 0xcb, 0x69, 0xae, 0x98, 0xad, 0xe6, 0xf0, 0xaf, 0x06, 0x6c,
+	0xc5, 0x6b, 0x1d, 0xde, 0x5a, 0xf4, 0x9a, 0x49, 0x74, 0xef, 0x54, 0xd5, 0x84, 0xa2, 0xa7, 0xef,
+	0xab, 0x9e, 0xb2, 0x56, 0x74, 0x5e, 0x88, 0x08, 0xff, 0x6d, 0xc0, 0x7a, 0xb2, 0x6f, 0xdf, 0xe5,
+	0x8e, 0x4f, 0xd1, 0x31, 0x20, 0x09, 0x41, 0x8d, 0x30, 0x49, 0x67, 0xb9, 0x65, 0x4e, 0x51, 0x1f,
+	0x0d, 0xc9, 0xaa, 0xda, 0x93, 0x63, 0x6b, 0x41, 0x51, 0x66, 0xf2, 0x38, 0x0f, 0x38, 0x2f, 0xb7,
+	0x36, 0xc6, 0xf7, 0xdb, 0xec, 0xca, 0xa1, 0xfd, 0x73, 0xe2, 0x5a, 0x9c, 0x0b, 0xab, 0x60, 0x07,
+	0x3f, 0xd0, 0x3e, 0xdc, 0x0d, 0x70, 0x8f, 0x4b, 0xe7, 0x54, 0xcf, 0x15, 0x65, 0x8e, 0x92, 0xe3,
+	0x3f, 0x0c, 0x58, 0x6b, 0x2f, 0x3f, 0xb7, 0xc7, 0x90, 0x1f, 0xaa, 0x38, 0xdd, 0x49, 0xca, 0x12,
+	0xe9, 0x00, 0x49, 0xab, 0x4d, 0x05, 0x51, 0xeb, 0xb9, 0x12, 0xec, 0x76, 0x78, 0x4e, 0x50, 0x9e,
+	0x4f, 0x52, 0x1e, 0xcc, 0xf8, 0x34, 0x57, 0xcc, 0x55, 0x57, 0xf0, 0x29, 0xac, 0xb7, 0xd3, 0xc8,
+	0x8d, 0x53, 0x92, 0x59, 0x8e, 0x12, 0xfc, 0x14, 0x36, 0x8e, 0xa8, 0x48, 0x3a, 0xe7, 0x36, 0x8b,
+	0x5f, 0xc3, 0xce, 0xe4, 0x8d, 0xa5, 0x57, 0x2d, 0xde, 0x61, 0x26, 0xd9, 0x21, 0x7e, 0x09, 0xf5,
+	0x69, 0x24, 0xef, 0xd1, 0xd9, 0x3e, 0x54, 0x4e, 0x1c, 0x26, 0x69, 0x5a, 0xd0, 0xd0, 0x0b, 0xb8,
+	0x1b, 0x05, 0xea, 0x7a, 0x07, 0x50, 0xe8, 0x79, 0x94, 0x08, 0xda, 0xaf, 0x1b, 0x0b, 0xca, 0xe9,
+	0x38, 0xdc, 0x83, 0x5a, 0x5b, 0x78, 0x94, 0xd8, 0xcb, 0x2e, 0xcd, 0x1c, 0x2e, 0xa4, 0x9c, 0xf4,
+	0x46, 0x9e, 0xcf, 0x3d, 0xad, 0x70, 0xfa, 0x84, 0x6f, 0x60, 0x63, 0xaa, 0x88, 0x86, 0x3c, 0xde,
+	0x41, 0x63, 0xd1, 0x0e, 0xbe, 0x0b, 0x9b, 0xbf, 0x19, 0x6a, 0x3c, 0x3a, 0xd5, 0x31, 0xf3, 0x05,
+	0xf7, 0x6e, 0x97, 0x17, 0x96, 0x98, 0xbc, 0xef, 0x41, 0xc5, 0x17, 0xc4, 0x13, 0x9d, 0x09, 0x79,
+	0xb9, 0xa3, 0xac, 0xe1, 0x22, 0xa1, 0x1d, 0x58, 0xa5, 0x4e, 0x7f, 0x1c, 0x94, 0x53, 0x41, 0x65,
+	0xea, 0xf4, 0xc3, 0x10, 0xfc, 0x97, 0x01, 0x6b, 0x49, 0x40, 0x5f, 0x38, 0xc2, 0xbb, 0x4d, 0x30,
+	0x6b, 0x4c, 0x30, 0xfb, 0x1c, 0x2a, 0x53, 0xe2, 0x63, 0x2c, 0x10, 0x9f, 0x3b, 0xc3, 0xf7, 0x55,
+	0x1e, 0xfc, 0x0a, 0x36, 0x53, 0xd8, 0xd3, 0xa3, 0xfb, 0x1c, 0x0a, 0xd4, 0x11, 0x1e, 0x8b, 0x66,
+	0xb7, 0x35, 0x05, 0x26, 0xde, 0x9f, 0x15, 0x46, 0xe3, 0x6f, 0xe1, 0xde, 0xd7, 0x23, 0x3a, 0xa2,
+	0x1f, 0x5e, 0xa7, 0x70, 0x1d, 0x6a, 0x93, 0xa9, 0x03, 0xb4, 0xf8, 0xd3, 0xf0, 0x49, 0x6b, 0x0b,
+	0x22, 0x46, 0x0b, 0x4a, 0xe2, 0x2f, 0x61, 0x3d, 0x19, 0xad, 0x7b, 0xae, 0xc9, 0x70, 0x5f, 0x50,
+	0x4f, 0x85, 0x97, 0x2c, 0x7d, 0x92, 0x8f, 0x3f, 0xf3, 0x3b, 0xda, 0x95, 0x51, 0x0f, 0x4b, 0x91,
+	0xf9, 0xe7, 0xea, 0xdc, 0xfa, 0xaf, 0x00, 0xe5, 0x57, 0x1a, 0xf1, 0x39, 0x71, 0xd1, 0x19, 0x94,
+	0x8e, 0xa8, 0x08, 0xf0, 0xa1, 0x18, 0x69, 0x29, 0x4f, 0xae, 0xf9, 0x70, 0x96, 0x5b, 0xb7, 0xf5,
+	0x11, 0xfa, 0x41, 0x35, 0x36, 0xf9, 0x78, 0xa2, 0xfd, 0xf4, 0x8b, 0x53, 0x9a, 0xb7, 0x44, 0x85,
+	0x33, 0x28, 0xb5, 0xd3, 0xf0, 0xb6, 0xe7, 0xe3, 0x6d, 0xa7, 0x67, 0xfb, 0xc5, 0x80, 0xea, 0xa4,
+	0x62, 0xa2, 0x9d, 0x04, 0x88, 0x34, 0x5d, 0x37, 0xf1, 0xbc, 0x10, 0x9d, 0xfd, 0xc9, 0xcf, 0xff,
+	0xfc, 0xfb, 0x7b, 0x66, 0x0f, 0xed, 0x36, 0xaf, 0x0f, 0xba, 0x54, 0x90, 0x83, 0xa6, 0x4d, 0x5c,
+	0xbf, 0xf9, 0x26, 0x18, 0xf1, 0xdb, 0xa6, 0x5c, 0x7e, 0xff, 0xd9, 0x90, 0x08, 0x39, 0xfa, 0x3f,
+	0x0d, 0x30, 0x67, 0x3f, 0x09, 0xe8, 0xc9, 0xec, 0x7a, 0xd3, 0x24, 0x2e, 0x03, 0xae, 0xa9, 0xc0,
+	0x3d, 0x46, 0xfb, 0xf3, 0xc0, 0x35, 0xdf, 0x84, 0xdf, 0xfc, 0x5b, 0xd4, 0x83, 0x82, 0x56, 0x78,
+	0x54, 0x1f, 0xe7, 0x4f, 0xbe, 0x0e, 0xe6, 0x66, 0x8a, 0x47, 0x17, 0xdc, 0x55, 0x05, 0xb7, 0xf0,
+	0xfd, 0xf4, 0x82, 0xcf, 0x98, 0xc3, 0x04, 0xfa, 0x06, 0x56, 0x03, 0x6d, 0xd6, 0xf3, 0xdd, 0x8e,
+	0x0d, 0x30, 0xf5, 0x61, 0x30, 0x77, 0xe6, 0x44, 0x84, 0x53, 0x7e, 0x6a, 0xa0, 0xef, 0xa1, 0x12,
+	0xec, 0x65, 0xa8, 0x02, 0x08, 0xa7, 0x6c, 0xda, 0x84, 0x26, 0x9b, 0xbb, 0x73, 0x63, 0xa2, 0x25,
+	0xb2, 0xa0, 0xac, 0xbe, 0x73, 0x0d, 0xfa, 0xd1, 0xf8, 0x56, 0xaa, 0xb2, 0x98, 0xdb, 0xb3, 0x03,
+	0xa2, 0x9c, 0x5f, 0xc1, 0x6a, 0xfc, 0x9b, 0x9f, 0xfe, 0x32, 0x13, 0xca, 0x61, 0x3e, 0x9c, 0xe5,
+	0x0e, 0x13, 0x1e, 0xbe, 0x84, 0xcd, 0x1e, 0xb7, 0x1b, 0xc1, 0xdf, 0x8a, 0x46, 0xf2, 0xdf, 0xc6,
+	0xe1, 0x5a, 0x4c, 0x11, 0x9e, 0xbb, 0xec, 0x42, 0x1a, 0x2f, 0x8c, 0xef, 0xcc, 0x2b, 0x26, 0x06,
+	0xa3, 0x6e, 0xa3, 0xc7, 0xed, 0xa6, 0xfe, 0x3f, 0x12, 0x5e, 0xec, 0xe6, 0xd5, 0xcd, 0xcf, 0xfe,
+	0x1f, 0x00, 0x6f, 0xe1, 0xff, 0xe8, 0xdb, 0x0c, 0x00, 0x00,
 }
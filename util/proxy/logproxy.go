@@ -17,6 +17,7 @@ package proxy
 
 import (
 	"context"
+	"io"
 
 	"github.com/google/trillian"
 )
@@ -94,6 +95,48 @@ func (p *Log) GetLeavesByHash(ctx context.Context, in *trillian.GetLeavesByHashR
 	return p.c.GetLeavesByHash(ctx, in)
 }
 
+// StreamLeaves forwards the RPC, relaying each response from the
+// TrillianLogClient's stream.
+func (p *Log) StreamLeaves(in *trillian.StreamLeavesRequest, stream trillian.TrillianLog_StreamLeavesServer) error {
+	c, err := p.c.StreamLeaves(stream.Context(), in)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := c.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// WatchSignedLogRoots forwards the RPC, relaying each response from the
+// TrillianLogClient's stream.
+func (p *Log) WatchSignedLogRoots(in *trillian.WatchSignedLogRootsRequest, stream trillian.TrillianLog_WatchSignedLogRootsServer) error {
+	c, err := p.c.WatchSignedLogRoots(stream.Context(), in)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := c.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
 // GetEntryAndProof forwards the RPC.
 func (p *Log) GetEntryAndProof(ctx context.Context, in *trillian.GetEntryAndProofRequest) (*trillian.GetEntryAndProofResponse, error) {
 	return p.c.GetEntryAndProof(ctx, in)